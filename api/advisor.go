@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// UsageStats are the read and write patterns of a feature, as observed by an instance since the feature was bound.
+type UsageStats struct {
+	Since time.Time `json:"since"`
+	// Reads is the number of reads of the feature, and Hits is the number of reads that found a value.
+	Reads uint64 `json:"reads"`
+	Hits  uint64 `json:"hits"`
+	// Expired is the number of reads that found a value older than the staleness, and therefore ignored it.
+	Expired uint64 `json:"expired"`
+	// PreviousReads is the number of reads of previous versions of the value (see KeepPrevious).
+	PreviousReads   uint64  `json:"previous_reads,omitempty"`
+	Writes          uint64  `json:"writes"`
+	WritesPerSecond float64 `json:"writes_per_second"`
+	// ReadAge is the age of the values when they were read, by percentile (i.e. "p50", "p90" and "p99").
+	ReadAge map[string]string `json:"read_age,omitempty"`
+	// PreviousReadAge is the age of the previous versions of the values when they were read, by percentile.
+	PreviousReadAge map[string]string `json:"previous_read_age,omitempty"`
+}
+
+// StalenessSettings are the time-related settings of a feature that the StalenessAdvisor can tune.
+// Retention is the time previous versions of the value are kept (see KeepPrevious.Over).
+type StalenessSettings struct {
+	Freshness string `json:"freshness"`
+	Staleness string `json:"staleness"`
+	Retention string `json:"retention,omitempty"`
+}
+
+// StalenessRecommendation is the recommended settings of a feature, based on its actual usage.
+type StalenessRecommendation struct {
+	FQN         string            `json:"fqn"`
+	Current     StalenessSettings `json:"current"`
+	Recommended StalenessSettings `json:"recommended"`
+	// Reasons explains the recommendations, or why the settings can't be tuned.
+	Reasons []string   `json:"reasons,omitempty"`
+	Usage   UsageStats `json:"usage"`
+}
+
+// StalenessAdvisor is implemented by engines that can recommend the freshness, staleness and retention of features
+// by their observed read and write patterns.
+type StalenessAdvisor interface {
+	// StalenessRecommendations returns the recommendations for the given feature, or for all the bound features if
+	// the fqn is empty.
+	StalenessRecommendations(ctx context.Context, fqn string) ([]StalenessRecommendation, error)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrAliasNotFound is returned when an alternate ID isn't mapped to an entity.
+var ErrAliasNotFound = fmt.Errorf("alias not found")
+
+// EntityAlias maps an alternate ID of an entity (i.e. the hash of a user's email, or the ID of their device) to the
+// canonical ID of the entity.
+type EntityAlias struct {
+	// AliasType is the type of the alternate ID (i.e. `email_hash`).
+	AliasType string `json:"alias_type"`
+	// AliasID is the alternate ID.
+	AliasID string `json:"alias_id"`
+	// EntityType is the key of the entity (i.e. `user_id`).
+	EntityType string `json:"entity_type"`
+	// EntityID is the canonical ID of the entity.
+	EntityID string `json:"entity_id"`
+}
+
+// Validate checks that the alias is complete, and that it maps to another type of ID.
+func (a EntityAlias) Validate() error {
+	if a.AliasType == "" || a.AliasID == "" || a.EntityType == "" || a.EntityID == "" {
+		return fmt.Errorf("`alias_type`, `alias_id`, `entity_type` and `entity_id` are required")
+	}
+	if a.AliasType == a.EntityType {
+		return fmt.Errorf("the alias type must be different from the entity type %s", a.EntityType)
+	}
+	return nil
+}
+
+// AliasStore is implemented by States (and Engines) that can store the mappings of alternate IDs to the canonical IDs
+// of the entities.
+// Engines resolve the alternate IDs when the values are read and written: a key of the feature that is missing from
+// the keys is resolved by the other keys, when they're alternate IDs of it (i.e. `email_hash` of a feature that is
+// keyed by `user_id`).
+type AliasStore interface {
+	// SetAlias maps the alternate ID to the canonical ID of the entity, replacing its previous mapping.
+	SetAlias(ctx context.Context, alias EntityAlias) error
+	// GetAlias returns the mapping of the alternate ID, or ErrAliasNotFound if it isn't mapped.
+	GetAlias(ctx context.Context, aliasType, aliasID string) (EntityAlias, error)
+	// DeleteAlias removes the mapping of the alternate ID.
+	DeleteAlias(ctx context.Context, aliasType, aliasID string) error
+}
@@ -0,0 +1,44 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// AuditRecord is an entry of the audit log of the feature reads and writes.
+type AuditRecord struct {
+	// Method is the method of the request (i.e. `Get`, `Set`).
+	Method string `json:"method"`
+	FQN    string `json:"fqn"`
+	// EntityHash is a hash of the keys of the request, so the requests of an entity can be correlated without
+	// disclosing its identifiers.
+	EntityHash string `json:"entity_hash"`
+	// PII and Classification are the privacy tags of the feature (see FeatureDescriptor.PrivacyTags).
+	PII            bool           `json:"pii,omitempty"`
+	Classification Classification `json:"classification,omitempty"`
+	// Caller is the identity of the caller (see WithCaller), or empty if it's unknown.
+	Caller    string    `json:"caller,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AuditSink ships the audit records to their destination (i.e. a log file or an object storage).
+type AuditSink interface {
+	WriteAudit(ctx context.Context, records []AuditRecord) error
+}
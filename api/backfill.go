@@ -0,0 +1,35 @@
+/*
+ * Copyright (c) 2022 RaptorML authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// BackfillRowFn is called for each row of a BackfillSource. The cursor identifies the position of the row in the
+// source, so the backfill can be resumed after it.
+type BackfillRowFn func(row map[string]any, ts time.Time, cursor string) error
+
+// BackfillSource is a bounded source of historical rows, that is replayed by a Backfill.
+type BackfillSource interface {
+	// Read calls fn for each row of the source, in order, starting after the given cursor (or from the beginning if
+	// the cursor is empty). It stops at the first error that is returned by fn.
+	Read(ctx context.Context, cursor string, fn BackfillRowFn) error
+	// Close releases the resources of the source.
+	Close() error
+}
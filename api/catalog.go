@@ -0,0 +1,169 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+)
+
+// DescriptionAnnotation is the annotation that describes a resource (i.e. set by the LabSDK). It's used as the
+// description of features that don't have one in their spec.
+const DescriptionAnnotation = "a8r.io/description"
+
+// FeatureLink is a named reference to an external resource about a feature (i.e. a dashboard, docs or a runbook).
+type FeatureLink struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+func catalogFromManifest(fd *FeatureDescriptor, in *manifests.Feature) error {
+	fd.Owner = strings.TrimSpace(in.Spec.Owner)
+	fd.Description = strings.TrimSpace(in.Spec.Description)
+	if fd.Description == "" {
+		fd.Description = strings.TrimSpace(in.GetAnnotations()[DescriptionAnnotation])
+	}
+
+	seen := make(map[string]bool, len(in.Spec.Tags))
+	for _, t := range in.Spec.Tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			return fmt.Errorf("tags must not be empty")
+		}
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		fd.Tags = append(fd.Tags, t)
+	}
+
+	for _, l := range in.Spec.Links {
+		u, err := url.Parse(l.URL)
+		if l.Name == "" || err != nil || !u.IsAbs() || u.Host == "" {
+			return fmt.Errorf("invalid link `%s`: links must have a name and an absolute URL", l.Name)
+		}
+		fd.Links = append(fd.Links, FeatureLink{Name: l.Name, URL: l.URL})
+	}
+	return nil
+}
+
+// CatalogQuery filters the features of the catalog. The zero value matches all the features.
+type CatalogQuery struct {
+	// Search matches the features that contain all of its words in their FQN, description, owner or tags.
+	Search string
+	// Namespace matches the features of the namespace.
+	Namespace string
+	// Owner matches the features of the owner.
+	Owner string
+	// Entity matches the features of the entity (see FeatureDescriptor.Entity).
+	Entity string
+	// Tags matches the features that are tagged with all of them.
+	Tags []string
+}
+
+// Match checks if the feature matches the query. The comparisons are case-insensitive.
+func (q CatalogQuery) Match(fd FeatureDescriptor) bool {
+	if q.Namespace != "" {
+		ns, _, _, _, _, err := ParseSelector(fd.FQN)
+		if err != nil || !strings.EqualFold(ns, q.Namespace) {
+			return false
+		}
+	}
+	if q.Owner != "" && !strings.EqualFold(fd.Owner, q.Owner) {
+		return false
+	}
+	if q.Entity != "" && !strings.EqualFold(fd.Entity, q.Entity) {
+		// Allow omitting the namespace of the entity.
+		if _, name, _, _, _, err := ParseSelector(fd.Entity); err != nil || !strings.EqualFold(name, q.Entity) {
+			return false
+		}
+	}
+	for _, t := range q.Tags {
+		if !containsFold(fd.Tags, strings.TrimSpace(t)) {
+			return false
+		}
+	}
+
+	text := strings.ToLower(strings.Join(append([]string{fd.FQN, fd.Description, fd.Owner}, fd.Tags...), " "))
+	for _, w := range strings.Fields(strings.ToLower(q.Search)) {
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(s []string, v string) bool {
+	for _, x := range s {
+		if strings.EqualFold(x, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// CatalogEntry describes a feature in the catalog, so teams can discover existing features instead of re-creating
+// them.
+type CatalogEntry struct {
+	FQN         string        `json:"fqn"`
+	Owner       string        `json:"owner,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Tags        []string      `json:"tags,omitempty"`
+	Links       []FeatureLink `json:"links,omitempty"`
+	Primitive   string        `json:"primitive"`
+	Aggr        []string      `json:"aggr,omitempty"`
+	Keys        []string      `json:"keys,omitempty"`
+	Entity      string        `json:"entity,omitempty"`
+	Unit        string        `json:"unit,omitempty"`
+	Builder     string        `json:"builder"`
+	DataSource  string        `json:"data_source,omitempty"`
+	Freshness   string        `json:"freshness"`
+	Staleness   string        `json:"staleness"`
+}
+
+// CatalogEntryFor returns the catalog entry of the feature.
+func CatalogEntryFor(fd FeatureDescriptor) CatalogEntry {
+	ce := CatalogEntry{
+		FQN:         fd.FQN,
+		Owner:       fd.Owner,
+		Description: fd.Description,
+		Tags:        fd.Tags,
+		Links:       fd.Links,
+		Primitive:   fd.Primitive.String(),
+		Keys:        fd.Keys,
+		Entity:      fd.Entity,
+		Unit:        fd.Unit,
+		Builder:     fd.Builder,
+		DataSource:  fd.DataSource,
+		Freshness:   fd.Freshness.String(),
+		Staleness:   fd.Staleness.String(),
+	}
+	for _, a := range fd.Aggr {
+		ce.Aggr = append(ce.Aggr, a.String())
+	}
+	return ce
+}
+
+// FeatureCatalog is implemented by engines that can list their features, so they can be discovered.
+type FeatureCatalog interface {
+	// Catalog returns the entries of the features that match the query, sorted by their FQN.
+	Catalog(ctx context.Context, q CatalogQuery) []CatalogEntry
+}
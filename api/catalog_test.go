@@ -0,0 +1,89 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+)
+
+func TestFeatureDescriptorFromManifest_Catalog(t *testing.T) {
+	in := windowedFeature("int")
+	in.Annotations = map[string]string{DescriptionAnnotation: "The categories the user clicked"}
+	in.Spec.Owner = "growth@raptor.ml"
+	in.Spec.Tags = []string{"Clicks", " engagement", "clicks"}
+	in.Spec.Links = []manifests.FeatureLink{{Name: "dashboard", URL: "https://grafana.raptor.ml/d/clicks"}}
+
+	fd, err := FeatureDescriptorFromManifest(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fd.Description != "The categories the user clicked" {
+		t.Errorf("expected the description to default to the annotation, got %q", fd.Description)
+	}
+	if !reflect.DeepEqual(fd.Tags, []string{"clicks", "engagement"}) {
+		t.Errorf("expected normalized tags, got %v", fd.Tags)
+	}
+
+	in.Spec.Description = "Top clicked categories"
+	if fd, err = FeatureDescriptorFromManifest(in); err != nil || fd.Description != "Top clicked categories" {
+		t.Errorf("expected the spec description to take precedence, got %q (%v)", fd.Description, err)
+	}
+
+	in.Spec.Links = []manifests.FeatureLink{{Name: "docs", URL: "docs/clicks"}}
+	if _, err := FeatureDescriptorFromManifest(in); err == nil {
+		t.Errorf("expected an error for a relative link")
+	}
+}
+
+func TestCatalogQuery_Match(t *testing.T) {
+	fd := FeatureDescriptor{
+		FQN:         "payments.refund_rate",
+		Owner:       "risk-team",
+		Description: "The rate of refunded transactions in the last week",
+		Tags:        []string{"fraud", "payments"},
+		Entity:      "payments.merchant",
+	}
+	tests := []struct {
+		name  string
+		query CatalogQuery
+		want  bool
+	}{
+		{name: "empty", query: CatalogQuery{}, want: true},
+		{name: "search description", query: CatalogQuery{Search: "Refunded week"}, want: true},
+		{name: "search fqn and tag", query: CatalogQuery{Search: "refund_rate fraud"}, want: true},
+		{name: "search mismatch", query: CatalogQuery{Search: "refund chargeback"}},
+		{name: "namespace", query: CatalogQuery{Namespace: "payments"}, want: true},
+		{name: "other namespace", query: CatalogQuery{Namespace: "default"}},
+		{name: "owner", query: CatalogQuery{Owner: "Risk-Team"}, want: true},
+		{name: "other owner", query: CatalogQuery{Owner: "growth"}},
+		{name: "entity", query: CatalogQuery{Entity: "merchant"}, want: true},
+		{name: "entity fqn", query: CatalogQuery{Entity: "payments.merchant"}, want: true},
+		{name: "other entity", query: CatalogQuery{Entity: "user"}},
+		{name: "all tags", query: CatalogQuery{Tags: []string{"fraud", "Payments"}}, want: true},
+		{name: "missing tag", query: CatalogQuery{Tags: []string{"fraud", "marketing"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.Match(fd); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
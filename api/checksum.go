@@ -0,0 +1,69 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+)
+
+// ErrChecksumMismatch is returned when the checksum of a feature doesn't match the checksum that was stored in its
+// status, i.e. when the spec was corrupted or was updated partially.
+var ErrChecksumMismatch = fmt.Errorf("feature checksum mismatch")
+
+// ChecksumReporter is implemented by engines that can report the checksums of their bound features.
+type ChecksumReporter interface {
+	// FeatureChecksums returns the checksums of the bound features by their FQN.
+	FeatureChecksums() map[string]string
+}
+
+// FeatureChecksum returns a content hash of the FeatureDescriptor of the Feature and of its spec, which includes the
+// builder's program. It's used to verify that all the replicas bind the same version of the Feature.
+func FeatureChecksum(in *manifests.Feature) (string, error) {
+	// the status is excluded, since the dependencies are derived from the program
+	in = &manifests.Feature{ObjectMeta: in.ObjectMeta, Spec: *in.Spec.DeepCopy()}
+	fd, err := FeatureDescriptorFromManifest(in)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	if err := enc.Encode(fd); err != nil {
+		return "", fmt.Errorf("failed to encode FeatureDescriptor: %w", err)
+	}
+	if err := enc.Encode(in.Spec); err != nil {
+		return "", fmt.Errorf("failed to encode Feature spec: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyFeatureChecksum returns the checksum of the Feature, and verifies it against the checksum that was stored in
+// its status (if any).
+func VerifyFeatureChecksum(in *manifests.Feature) (string, error) {
+	sum, err := FeatureChecksum(in)
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate checksum: %w", err)
+	}
+	if in.Status.Checksum != "" && in.Status.Checksum != sum {
+		return sum, fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, in.Status.Checksum, sum)
+	}
+	return sum, nil
+}
@@ -22,21 +22,29 @@ import (
 	"strconv"
 )
 
+// FQNRegExp matches selectors of the default naming scheme.
+//
+// Deprecated: the naming scheme is configurable. Use ParseSelector or ValidateFQN instead.
 var FQNRegExp = regexp.MustCompile(`(?si)^((?P<namespace>[a-z0-9]+(?:_[a-z0-9]+)*)\.)?(?P<name>[a-z0-9]+(?:_[a-z0-9]+)*)(\+(?P<aggrFn>([a-z]+_*[a-z]+)))?(@-(?P<version>([0-9]+)))?(\[(?P<encoding>([a-z]+_*[a-z]+))])?$`)
 
+// ParseSelector parses a selector by the configured naming scheme.
 func ParseSelector(fqn string) (namespace, name string, aggrFn AggrFn, version uint, encoding string, err error) {
-	if !FQNRegExp.MatchString(fqn) {
+	match := selectorRegExp.FindStringSubmatch(fqn)
+	if match == nil {
 		return "", "", AggrFnUnknown, 0, "", fmt.Errorf("invalid FQN: %s", fqn)
 	}
-
-	match := FQNRegExp.FindStringSubmatch(fqn)
 	parsedFQN := make(map[string]string)
-	for i, name := range FQNRegExp.SubexpNames() {
+	for i, name := range selectorRegExp.SubexpNames() {
 		if i != 0 && name != "" {
 			parsedFQN[name] = match[i]
 		}
 	}
 
+	parsedFQN["namespace"], parsedFQN["name"], err = fqnScheme.Split(parsedFQN["fqn"])
+	if err != nil {
+		return "", "", AggrFnUnknown, 0, "", err
+	}
+
 	var ver = 0
 	if parsedFQN["version"] != "" {
 		ver, err = strconv.Atoi(parsedFQN["version"])
@@ -65,7 +73,7 @@ func NormalizeFQN(fqn, defaultNamespace string) (string, error) {
 	if namespace == "" {
 		namespace = defaultNamespace
 	}
-	return fmt.Sprintf("%s.%s", namespace, name), nil
+	return fqnScheme.Join(namespace, name), nil
 }
 
 // NormalizeSelector returns a selector with the default namespace if not specified
@@ -89,5 +97,5 @@ func NormalizeSelector(selector, defaultNamespace string) (string, error) {
 	if enc != "" {
 		other = fmt.Sprintf("%s[%s]", other, enc)
 	}
-	return fmt.Sprintf("%s.%s%s", ns, name, other), nil
+	return fqnScheme.Join(ns, name) + other, nil
 }
@@ -0,0 +1,265 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+type conformanceSpec struct {
+	Cases []struct {
+		Name    string `json:"name"`
+		Feature struct {
+			Primitive string   `json:"primitive"`
+			Freshness string   `json:"freshness"`
+			Staleness string   `json:"staleness"`
+			Slide     string   `json:"slide"`
+			Aggr      []string `json:"aggr"`
+		} `json:"feature"`
+		Steps []conformanceStep `json:"steps"`
+	} `json:"cases"`
+}
+
+type conformanceStep struct {
+	Op     string    `json:"op"`
+	At     time.Time `json:"at"`
+	Value  any       `json:"value"`
+	Expect any       `json:"expect"`
+	Error  bool      `json:"error"`
+}
+
+// conformanceState is a reference of the state semantics of the Redis plugin, built from the same bucketing and
+// merging functions.
+type conformanceState struct {
+	fd      FeatureDescriptor
+	value   any
+	buckets map[string]WindowResultMap
+}
+
+func (s *conformanceState) write(op string, value any, ts time.Time) error {
+	primitive := s.fd.Primitive
+	if op != "set" && !primitive.Scalar() {
+		primitive = primitive.Singular()
+	}
+	val, err := FromJSONValue(value, primitive)
+	if err != nil {
+		return err
+	}
+
+	switch op {
+	case "set":
+		if s.fd.ValidWindow() {
+			return s.windowAdd(val, ts)
+		}
+		if !s.fd.Primitive.Scalar() {
+			s.value = append([]any{}, reflectItems(val)...)
+			return nil
+		}
+		s.value = val
+	case "update":
+		if s.fd.ValidWindow() {
+			return s.windowAdd(val, ts)
+		}
+		if s.fd.Primitive.Scalar() {
+			s.value = val
+			return nil
+		}
+		return s.write("append", value, ts)
+	case "append":
+		if s.fd.ValidWindow() {
+			return fmt.Errorf("cannot append a windowed feature")
+		}
+		if s.fd.Primitive.Scalar() {
+			return fmt.Errorf("`Append` only supports slices and arrays")
+		}
+		list, _ := s.value.([]any)
+		s.value = append(list, val)
+	case "incr":
+		if s.fd.ValidWindow() {
+			return fmt.Errorf("cannot increment to a windowed feature")
+		}
+		if !s.fd.Primitive.Scalar() {
+			return fmt.Errorf("`Incr` only supports scalars")
+		}
+		switch v := val.(type) {
+		case int:
+			cur, _ := s.value.(int)
+			s.value = cur + v
+		case float64:
+			cur, _ := s.value.(float64)
+			s.value = cur + v
+		default:
+			return fmt.Errorf("`Incr` only supports scalar numeric values")
+		}
+	default:
+		return fmt.Errorf("unknown operation %s", op)
+	}
+	return nil
+}
+
+func (s *conformanceState) windowAdd(value any, ts time.Time) error {
+	var val float64
+	switch v := value.(type) {
+	case int:
+		val = float64(v)
+	case float64:
+		val = v
+	default:
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+
+	bucket := BucketName(ts, s.fd.Freshness)
+	b, ok := s.buckets[bucket]
+	if !ok {
+		b = make(WindowResultMap)
+		s.buckets[bucket] = b
+	}
+	for _, fn := range s.fd.BucketFields() {
+		cur, exists := b[fn]
+		switch fn {
+		case AggrFnSum:
+			b[fn] += val
+		case AggrFnCount:
+			b[fn]++
+		case AggrFnMin:
+			if !exists || val < cur {
+				b[fn] = val
+			}
+		case AggrFnMax:
+			if !exists || val > cur {
+				b[fn] = val
+			}
+		}
+	}
+	return nil
+}
+
+func (s *conformanceState) get(at time.Time) (any, error) {
+	if !s.fd.ValidWindow() {
+		if !s.fd.Primitive.Scalar() {
+			return NormalizeAny(s.value)
+		}
+		return s.value, nil
+	}
+
+	res := make(WindowResultMap)
+	for _, b := range s.fd.AliveWindowBucketsAt(at) {
+		if data, ok := s.buckets[b]; ok {
+			MergeWindowResults(res, data, s.fd.Aggr)
+		}
+	}
+	ret := make(map[string]float64)
+	for _, fn := range s.fd.Aggr {
+		if v, ok := res[fn]; ok {
+			ret[fn.String()] = v
+		}
+	}
+	return ret, nil
+}
+
+func reflectItems(v any) []any {
+	rv := reflect.ValueOf(v)
+	ret := make([]any, rv.Len())
+	for i := range ret {
+		ret[i] = rv.Index(i).Interface()
+	}
+	return ret
+}
+
+func TestStateConformance(t *testing.T) {
+	f, err := os.Open("testdata/state-conformance.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	spec := conformanceSpec{}
+	if err := utilyaml.NewYAMLOrJSONDecoder(f, 4096).Decode(&spec); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range spec.Cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var err error
+			fd := FeatureDescriptor{Primitive: StringToPrimitiveType(c.Feature.Primitive)}
+			for _, d := range []struct {
+				s   string
+				dur *time.Duration
+			}{{c.Feature.Freshness, &fd.Freshness}, {c.Feature.Staleness, &fd.Staleness}, {c.Feature.Slide, &fd.WindowSlide}} {
+				if d.s == "" {
+					continue
+				}
+				if *d.dur, err = time.ParseDuration(d.s); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if fd.Aggr, err = StringsToAggrFns(c.Feature.Aggr); err != nil {
+				t.Fatal(err)
+			}
+
+			s := &conformanceState{fd: fd, buckets: make(map[string]WindowResultMap)}
+			for i, step := range c.Steps {
+				switch step.Op {
+				case "buckets":
+					want := make([]string, 0)
+					for _, b := range step.Expect.([]any) {
+						want = append(want, b.(string))
+					}
+					if got := fd.AliveWindowBucketsAt(step.At); !reflect.DeepEqual(got, want) {
+						t.Errorf("step #%d: expected the buckets %v, got %v", i, want, got)
+					}
+				case "get":
+					got, err := s.get(step.At)
+					if err != nil {
+						t.Fatalf("step #%d: %s", i, err)
+					}
+					if !reflect.DeepEqual(got, expectedValue(t, fd, step.Expect)) {
+						t.Errorf("step #%d: expected %v, got %v", i, step.Expect, got)
+					}
+				default:
+					err := s.write(step.Op, step.Value, step.At)
+					if step.Error && err == nil {
+						t.Errorf("step #%d: expected %s of %v to fail", i, step.Op, step.Value)
+					} else if !step.Error && err != nil {
+						t.Errorf("step #%d: %s", i, err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func expectedValue(t *testing.T, fd FeatureDescriptor, expect any) any {
+	t.Helper()
+	if fd.ValidWindow() {
+		ret := make(map[string]float64)
+		for k, v := range expect.(map[string]any) {
+			ret[k] = v.(float64)
+		}
+		return ret
+	}
+	v, err := FromJSONValue(expect, fd.Primitive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
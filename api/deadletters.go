@@ -0,0 +1,68 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ErrDeadLetterNotFound is returned when a dead-letter is not found in the dead-letter queue.
+var ErrDeadLetterNotFound = fmt.Errorf("dead-letter not found")
+
+// DeadLetter is an event that failed to be computed into a feature-value.
+type DeadLetter struct {
+	// ID is the identifier of the dead-letter in the queue (set by the DeadLetterQueue).
+	ID         string `json:"id,omitempty"`
+	FQN        string `json:"fqn"`
+	DataSource string `json:"data_source,omitempty"`
+	Keys       Keys   `json:"keys,omitempty"`
+	// Row is the raw event, as it was given to the feature's builder.
+	Row map[string]any `json:"row,omitempty"`
+	// Value is the computed value that failed to be written (nil if the computation itself has failed).
+	Value     any       `json:"value,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Error     string    `json:"error"`
+	FailedAt  time.Time `json:"failed_at"`
+	// Notification is the kind of the historian notification (`collect` or `write`) that exhausted its retries. Its
+	// fields are kept in the Row. It's empty for the events that failed to be computed.
+	Notification string `json:"notification,omitempty"`
+}
+
+// DeadLetterQueue captures the events that failed to be computed, so they're not lost.
+type DeadLetterQueue interface {
+	SendDeadLetter(ctx context.Context, dl DeadLetter) error
+}
+
+// DeadLetterStore is implemented by DeadLetterQueues that can list and remove their dead-letters, so they can be
+// replayed.
+type DeadLetterStore interface {
+	// DeadLetters returns the latest dead-letters of the feature (or of all the features if fqn is empty).
+	DeadLetters(ctx context.Context, fqn string, limit int) ([]DeadLetter, error)
+	// DeadLetter returns a dead-letter by its ID, or ErrDeadLetterNotFound.
+	DeadLetter(ctx context.Context, id string) (DeadLetter, error)
+	// RemoveDeadLetter removes a dead-letter from the queue.
+	RemoveDeadLetter(ctx context.Context, id string) error
+}
+
+// DeadLetterReplayer is implemented by engines that can replay the dead-letters.
+type DeadLetterReplayer interface {
+	DeadLetterStore
+	// ReplayDeadLetter computes the dead-letter again, and removes it from the queue if it succeeds.
+	ReplayDeadLetter(ctx context.Context, id string) error
+}
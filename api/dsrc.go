@@ -28,6 +28,12 @@ type DataSource struct {
 	FQN    string                 `json:"fqn"`
 	Kind   string                 `json:"kind"`
 	Config manifests.ParsedConfig `json:"config"`
+	// Mapping is the compiled mapping of the DataSource (nil if not defined)
+	Mapping *Mapping `json:"-"`
+	// TimestampPolicy is the default timestamp policy of the DataSource's features (nil if not defined)
+	TimestampPolicy *TimestampPolicy `json:"timestamp_policy,omitempty"`
+	// configVars are the config definitions of the manifest, used to tell which values are originated from secrets
+	configVars []manifests.ConfigVar
 	// todo Schema
 }
 
@@ -38,9 +44,34 @@ func DataSourceFromManifest(ctx context.Context, src *manifests.DataSource, r cl
 		return DataSource{}, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	mapping, err := MappingFromManifest(src.Spec.Mapping)
+	if err != nil {
+		return DataSource{}, fmt.Errorf("failed to parse mapping: %w", err)
+	}
+
+	tsPolicy, err := TimestampPolicyFromManifest(src.Spec.TimestampPolicy)
+	if err != nil {
+		return DataSource{}, fmt.Errorf("failed to parse timestamp policy: %w", err)
+	}
+
 	return DataSource{
-		FQN:    src.FQN(),
-		Kind:   src.Spec.Kind,
-		Config: pc,
+		FQN:             src.FQN(),
+		Kind:            src.Spec.Kind,
+		Config:          pc,
+		Mapping:         mapping,
+		TimestampPolicy: tsPolicy,
+		configVars:      src.Spec.Config,
 	}, nil
 }
+
+// DecodedConfig returns the config of the DataSource with the values that are originated from secrets decoded.
+func (ds DataSource) DecodedConfig() (manifests.ParsedConfig, error) {
+	cfg := make(manifests.ParsedConfig, len(ds.Config))
+	for k, v := range ds.Config {
+		cfg[k] = v
+	}
+	if err := cfg.DecodeSecrets(ds.configVars); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
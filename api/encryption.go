@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+)
+
+// Encryption defines the field-level encryption of the values of a feature. The values are envelope-encrypted: each
+// value is encrypted by a data key, which is wrapped by the KeyID key-encryption key of the Provider's KeyManager.
+type Encryption struct {
+	Provider string `json:"provider"`
+	KeyID    string `json:"key_id"`
+}
+
+// EncryptionFromManifest parses the encryption of a Feature. It returns nil if the feature isn't encrypted.
+func EncryptionFromManifest(in *manifests.FeatureEncryption) (*Encryption, error) {
+	if in == nil {
+		return nil, nil
+	}
+	if in.Provider == "" || in.KeyID == "" {
+		return nil, fmt.Errorf("the encryption `provider` and `keyId` are required")
+	}
+	return &Encryption{Provider: in.Provider, KeyID: in.KeyID}, nil
+}
+
+// SealedValue is an encrypted value of an encrypted feature (see FeatureDescriptor.Encryption).
+// The values of encrypted features are stored sealed, and are served sealed by the engine, so they're decrypted only
+// by the accessor for the callers that are allowed to (see ValueDecrypter).
+type SealedValue string
+
+// KeyManager wraps and unwraps the data keys of the encrypted values by a key-encryption key that it manages
+// (i.e. a KMS or a Key Vault), so the data keys can be stored alongside the values.
+type KeyManager interface {
+	WrapKey(ctx context.Context, keyID string, key []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// ValueDecrypter is implemented by engines that seal the values of encrypted features.
+type ValueDecrypter interface {
+	// DecryptValue decrypts the value of an encrypted feature for the given keys. Values that aren't sealed (i.e. a
+	// fallback default) are returned as is.
+	DecryptValue(ctx context.Context, fd FeatureDescriptor, keys Keys, val Value) (Value, error)
+}
+
+// DecryptValue decrypts the value of an encrypted feature by the engine, so it can be consumed within the Core (i.e.
+// as an input of a Model). The value is returned as is if the feature isn't encrypted.
+func DecryptValue(ctx context.Context, engine Engine, fd FeatureDescriptor, keys Keys, val Value) (Value, error) {
+	d, ok := engine.(ValueDecrypter)
+	if fd.Encryption == nil || !ok {
+		return val, nil
+	}
+	return d.DecryptValue(ctx, fd, keys, val)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"regexp"
+	"strconv"
+)
+
+var uuidRegExp = regexp.MustCompile(`(?i)^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// Entity is the key schema of the features that are keyed by an entity (i.e. a user or an account).
+type Entity struct {
+	FQN  string
+	Keys []EntityKey
+}
+
+// EntityKey is a key field of an Entity, and the format of its IDs.
+type EntityKey struct {
+	Name    string
+	Format  manifests.EntityKeyFormat
+	Pattern *regexp.Regexp
+}
+
+// EntityFromManifest returns an Entity from a manifests.Entity
+func EntityFromManifest(in *manifests.Entity) (*Entity, error) {
+	if len(in.Spec.Keys) == 0 {
+		return nil, fmt.Errorf("the entity must have at least one key")
+	}
+	ent := &Entity{FQN: in.FQN(), Keys: make([]EntityKey, len(in.Spec.Keys))}
+	seen := make(map[string]bool, len(in.Spec.Keys))
+	for i, k := range in.Spec.Keys {
+		if k.Name == "" {
+			return nil, fmt.Errorf("the keys of the entity must have a name")
+		}
+		if seen[k.Name] {
+			return nil, fmt.Errorf("the key %s of the entity is duplicated", k.Name)
+		}
+		seen[k.Name] = true
+
+		key := EntityKey{Name: k.Name, Format: k.Format}
+		switch k.Format {
+		case "":
+			key.Format = manifests.EntityKeyFormatString
+		case manifests.EntityKeyFormatString, manifests.EntityKeyFormatInteger, manifests.EntityKeyFormatUUID:
+		default:
+			return nil, fmt.Errorf("unsupported format %s of the key %s", k.Format, k.Name)
+		}
+		if k.Pattern != "" {
+			var err error
+			// the IDs must match the whole pattern
+			if key.Pattern, err = regexp.Compile("^(?:" + k.Pattern + ")$"); err != nil {
+				return nil, fmt.Errorf("invalid pattern of the key %s: %w", k.Name, err)
+			}
+		}
+		ent.Keys[i] = key
+	}
+	return ent, nil
+}
+
+// KeyNames returns the names of the keys of the entity.
+func (e *Entity) KeyNames() []string {
+	ret := make([]string, len(e.Keys))
+	for i, k := range e.Keys {
+		ret[i] = k.Name
+	}
+	return ret
+}
+
+// ValidateKeys checks that the keys have an ID for each of the entity's keys, and that the IDs match their formats.
+func (e *Entity) ValidateKeys(keys Keys) error {
+	for _, k := range e.Keys {
+		id, ok := keys[k.Name]
+		if !ok || id == "" {
+			return fmt.Errorf("%w: missing the key %s of entity %s", ErrInvalidEntityID, k.Name, e.FQN)
+		}
+		if err := k.validate(id); err != nil {
+			return fmt.Errorf("%w: the key %s of entity %s %s", ErrInvalidEntityID, k.Name, e.FQN, err)
+		}
+	}
+	return nil
+}
+
+func (k EntityKey) validate(id string) error {
+	switch k.Format {
+	case manifests.EntityKeyFormatInteger:
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			return fmt.Errorf("must be an integer, got %q", id)
+		}
+	case manifests.EntityKeyFormatUUID:
+		if !uuidRegExp.MatchString(id) {
+			return fmt.Errorf("must be a UUID, got %q", id)
+		}
+	}
+	if k.Pattern != nil && !k.Pattern.MatchString(id) {
+		return fmt.Errorf("must match the pattern %s, got %q", k.Pattern, id)
+	}
+	return nil
+}
+
+// EntityManager is implemented by Engines that validate the entity IDs of the features that are keyed by an entity.
+type EntityManager interface {
+	// BindEntity registers (or updates) the key schema of the entity.
+	BindEntity(ent *Entity)
+	// UnbindEntity removes the key schema of the entity, so the IDs of its features are no longer validated.
+	UnbindEntity(fqn string)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"errors"
+	"testing"
+
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEntity_ValidateKeys(t *testing.T) {
+	ent, err := EntityFromManifest(&manifests.Entity{
+		ObjectMeta: metav1.ObjectMeta{Name: "session", Namespace: "default"},
+		Spec: manifests.EntitySpec{
+			Keys: []manifests.EntityKey{
+				{Name: "user_id", Format: manifests.EntityKeyFormatInteger},
+				{Name: "session_id", Format: manifests.EntityKeyFormatUUID},
+				{Name: "region", Pattern: "eu|us"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	valid := Keys{"user_id": "42", "session_id": "123e4567-e89b-12d3-a456-426614174000", "region": "eu"}
+	tests := []struct {
+		name    string
+		key     string
+		id      string
+		wantErr bool
+	}{
+		{name: "valid"},
+		{name: "missing key", key: "user_id", id: "", wantErr: true},
+		{name: "non-integer", key: "user_id", id: "u-42", wantErr: true},
+		{name: "invalid uuid", key: "session_id", id: "123e4567", wantErr: true},
+		{name: "partial pattern match", key: "region", id: "europe", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := Keys{}
+			for k, v := range valid {
+				keys[k] = v
+			}
+			if tt.key != "" {
+				keys[tt.key] = tt.id
+			}
+			err := ent.ValidateKeys(keys)
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidEntityID) {
+				t.Fatalf("expected error %v, got %v", ErrInvalidEntityID, err)
+			}
+		})
+	}
+}
+
+func TestEntityFromManifest_Invalid(t *testing.T) {
+	for name, keys := range map[string][]manifests.EntityKey{
+		"no keys":         nil,
+		"duplicated keys": {{Name: "user_id"}, {Name: "user_id"}},
+		"invalid pattern": {{Name: "user_id", Pattern: "("}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := EntityFromManifest(&manifests.Entity{Spec: manifests.EntitySpec{Keys: keys}})
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
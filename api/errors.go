@@ -27,6 +27,27 @@ var ErrUnsupportedAggrError = fmt.Errorf("unsupported aggr")
 // ErrFeatureNotFound is returned when a feature is not found in the Core's engine manager.
 var ErrFeatureNotFound = fmt.Errorf("feature not found")
 
+// ErrPermissionDenied is returned when the caller isn't allowed to access a feature.
+var ErrPermissionDenied = fmt.Errorf("permission denied")
+
+// ErrQuotaExceeded is returned when a write is rejected since the tenant of the feature exceeded its quota.
+var ErrQuotaExceeded = fmt.Errorf("quota exceeded")
+
+// ErrInvalidEntityID is returned when the ID of a key doesn't match the format of the key of the feature's entity.
+var ErrInvalidEntityID = fmt.Errorf("invalid entity ID")
+
+// ErrWriteConditionFailed is returned when a conditional write isn't applied, since its condition isn't met (i.e. the
+// stored value is newer than the written one).
+var ErrWriteConditionFailed = fmt.Errorf("write condition failed")
+
+// ErrTransactionsUnsupported is returned when features are written in a transaction, but the engine (or its state
+// provider) doesn't support transactions.
+var ErrTransactionsUnsupported = fmt.Errorf("transactions are not supported")
+
+// ErrTransactionSpansEntities is returned when the writes of a transaction are of more than one entity, since they
+// can't be applied atomically (i.e. they're stored in different shards or Redis Cluster slots).
+var ErrTransactionSpansEntities = fmt.Errorf("the writes of a transaction must be of a single entity")
+
 // ErrFeatureAlreadyExists is returned when a feature is already registered in the Core's engine manager.
 var ErrFeatureAlreadyExists = fmt.Errorf("feature already exists")
 
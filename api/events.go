@@ -0,0 +1,148 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// EventType is the type of lifecycle event that is published to the EventBus.
+type EventType string
+
+const (
+	// EventFeatureBound is published after a feature is bound to the engine.
+	EventFeatureBound EventType = "feature.bound"
+	// EventFeatureUnbound is published after a feature is unbound from the engine.
+	EventFeatureUnbound EventType = "feature.unbound"
+	// EventWindowFinalized is published when a window bucket is dead, and its data will not change anymore.
+	EventWindowFinalized EventType = "window.finalized"
+	// EventProviderReconnected is published when a provider (i.e. the state) is reachable again after a failure.
+	EventProviderReconnected EventType = "provider.reconnected"
+	// EventBackfillCompleted is published when a batch of historical data was ingested.
+	EventBackfillCompleted EventType = "backfill.completed"
+	// EventLateEventDropped is published when an event of a windowed feature arrived after its allowed lateness.
+	EventLateEventDropped EventType = "event.late"
+	// EventValidationViolated is published when a written value violated the validations of its feature.
+	EventValidationViolated EventType = "value.invalid"
+)
+
+// Event is a lifecycle event of the engine.
+type Event interface {
+	EventType() EventType
+}
+
+// FeatureBoundEvent is published after a feature is bound to the engine.
+type FeatureBoundEvent struct {
+	FeatureDescriptor FeatureDescriptor
+}
+
+func (FeatureBoundEvent) EventType() EventType { return EventFeatureBound }
+
+// FeatureUnboundEvent is published after a feature is unbound from the engine.
+type FeatureUnboundEvent struct {
+	FQN string
+}
+
+func (FeatureUnboundEvent) EventType() EventType { return EventFeatureUnbound }
+
+// WindowFinalizedEvent is published when a window bucket of an entity is dead, and its data will not change anymore.
+type WindowFinalizedEvent struct {
+	FQN         string
+	Bucket      string
+	EncodedKeys string
+	Data        WindowResultMap
+}
+
+func (WindowFinalizedEvent) EventType() EventType { return EventWindowFinalized }
+
+// ProviderReconnectedEvent is published when a provider is reachable again after a connectivity failure.
+type ProviderReconnectedEvent struct {
+	// Provider is the name of the provider. i.e. `redis`
+	Provider string
+	// Downtime is the time since the first failure.
+	Downtime time.Duration
+}
+
+func (ProviderReconnectedEvent) EventType() EventType { return EventProviderReconnected }
+
+// BackfillCompletedEvent is published when a batch of historical data (i.e. a file of a `batch` DataSource, or a
+// Backfill) was ingested.
+type BackfillCompletedEvent struct {
+	DataSource string
+	// Source is the identifier of the ingested batch. i.e. the object key of the file
+	Source string
+	Rows   int
+}
+
+func (BackfillCompletedEvent) EventType() EventType { return EventBackfillCompleted }
+
+// LateEventDroppedEvent is published when an event of a windowed feature arrived after its allowed lateness, and
+// was dropped. It can be used as a dead-letter stream of the late events.
+type LateEventDroppedEvent struct {
+	FQN         string
+	EncodedKeys string
+	Value       Value
+	// Watermark is the watermark of the feature's DataSource when the event arrived.
+	Watermark time.Time
+}
+
+func (LateEventDroppedEvent) EventType() EventType { return EventLateEventDropped }
+
+// ValidationViolatedEvent is published when a written value violated the validations of its feature.
+type ValidationViolatedEvent struct {
+	FQN         string
+	EncodedKeys string
+	Value       Value
+	Violations  []ValidationRule
+	// Action is the action that was taken (see Validations.OnViolation).
+	Action ViolationAction
+}
+
+func (ValidationViolatedEvent) EventType() EventType { return EventValidationViolated }
+
+// EventHandler handles the events it's subscribed to.
+// Handlers are called synchronously by the publisher, so they must not block.
+type EventHandler func(ctx context.Context, ev Event)
+
+// EventBus is a typed, in-process bus of lifecycle events, which allows plugins and embedders to extend the engine
+// (i.e. custom cache invalidation) without patching its internals.
+// It's implemented by Engines that support it.
+type EventBus interface {
+	// Subscribe subscribes the handler to the given event types (or to all the events, if none is given).
+	// It returns a function that unsubscribes the handler.
+	Subscribe(handler EventHandler, types ...EventType) (unsubscribe func())
+	// Publish publishes the event to the subscribed handlers.
+	Publish(ctx context.Context, ev Event)
+}
+
+// EventBusAware is implemented by providers (i.e. States) that publish events, so the engine can hand them its EventBus.
+type EventBusAware interface {
+	SetEventBus(bus EventBus)
+}
+
+// SubscribeTo subscribes a typed handler to the events of type E. i.e.
+//
+//	api.SubscribeTo(bus, func(ctx context.Context, ev api.FeatureUnboundEvent) { cache.Invalidate(ev.FQN) })
+func SubscribeTo[E Event](bus EventBus, fn func(ctx context.Context, ev E)) (unsubscribe func()) {
+	var zero E
+	return bus.Subscribe(func(ctx context.Context, ev Event) {
+		if e, ok := ev.(E); ok {
+			fn(ctx, e)
+		}
+	}, zero.EventType())
+}
@@ -0,0 +1,71 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"time"
+)
+
+// Fallback defines the value that is returned when there is no fresh value for the entity: the last known value
+// for LastKnown after it went stale, and then the Default (nil for none).
+// Fallback values are marked by Value.Fallback.
+type Fallback struct {
+	Default   any           `json:"default,omitempty"`
+	LastKnown time.Duration `json:"last_known,omitempty"`
+}
+
+// FallbackFromManifest parses the fallback of a Feature of the given primitive. It returns nil if no fallback was
+// defined.
+func FallbackFromManifest(in *manifests.Fallback, primitive PrimitiveType) (*Fallback, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	ret := &Fallback{}
+	if in.LastKnown != nil {
+		if in.LastKnown.Duration < 0 {
+			return nil, fmt.Errorf("the fallback `lastKnown` must not be negative")
+		}
+		ret.LastKnown = in.LastKnown.Duration
+	}
+	if in.Default != nil && len(in.Default.Raw) > 0 {
+		var v any
+		if err := json.Unmarshal(in.Default.Raw, &v); err != nil {
+			return nil, fmt.Errorf("failed to parse the fallback `default`: %w", err)
+		}
+		if v != nil {
+			d, err := FromJSONValue(v, primitive)
+			if err != nil {
+				return nil, fmt.Errorf("invalid fallback `default`: %w", err)
+			}
+			ret.Default = d
+		}
+	}
+	return ret, nil
+}
+
+// ValueTTL returns the time a value of the feature is kept in the state: its staleness, extended by the time the
+// last known value is used as a fallback.
+func (fd FeatureDescriptor) ValueTTL() time.Duration {
+	if fd.Staleness <= 0 || fd.Fallback == nil {
+		return fd.Staleness
+	}
+	return fd.Staleness + fd.Fallback.LastKnown
+}
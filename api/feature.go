@@ -25,27 +25,80 @@ import (
 
 const ModelBuilder = "model"
 const SourcelessBuilder = "sourceless"
+const SQLBuilder = "sql"
+const CELBuilder = "cel"
+const WasmBuilder = "wasm"
+const RemoteBuilder = "remote"
+const AggregationBuilder = "aggregation"
+
+// OnDemandBuilder features are computed on request, from the request context that is sent by the caller (see
+// WithRequestContext) and from other features. Their values are never stored.
+const OnDemandBuilder = "ondemand"
 
 // FeatureDescriptor is describing a feature definition for an internal use of the Core.
 type FeatureDescriptor struct {
-	FQN          string        `json:"FQN"`
-	Primitive    PrimitiveType `json:"primitive"`
-	Aggr         []AggrFn      `json:"aggr"`
-	Freshness    time.Duration `json:"freshness"`
-	Staleness    time.Duration `json:"staleness"`
-	Timeout      time.Duration `json:"timeout"`
-	KeepPrevious *KeepPrevious `json:"keep_previous"`
-	Keys         []string      `json:"keys"`
-	Builder      string        `json:"builder"`
-	RuntimeEnv   string        `json:"runtimeEnv"`
-	DataSource   string        `json:"data_source"`
-	Dependencies []string      `json:"dependencies"`
+	FQN                    string                 `json:"FQN"`
+	Primitive              PrimitiveType          `json:"primitive"`
+	Aggr                   []AggrFn               `json:"aggr"`
+	Freshness              time.Duration          `json:"freshness"`
+	Staleness              time.Duration          `json:"staleness"`
+	WindowSlide            time.Duration          `json:"window_slide,omitempty"`
+	SessionGap             time.Duration          `json:"session_gap,omitempty"`
+	TopK                   int                    `json:"top_k,omitempty"`
+	HalfLife               time.Duration          `json:"half_life,omitempty"`
+	AllowedLateness        time.Duration          `json:"allowed_lateness,omitempty"`
+	ExecutionTimeout       time.Duration          `json:"execution_timeout,omitempty"`
+	CircuitBreaker         int                    `json:"circuit_breaker,omitempty"`
+	Timeout                time.Duration          `json:"timeout"`
+	KeepPrevious           *KeepPrevious          `json:"keep_previous"`
+	Keys                   []string               `json:"keys"`
+	Entity                 string                 `json:"entity,omitempty"`
+	Builder                string                 `json:"builder"`
+	RuntimeEnv             string                 `json:"runtimeEnv"`
+	DataSource             string                 `json:"data_source"`
+	Dependencies           []string               `json:"dependencies"`
+	TimestampNormalization TimestampNormalization `json:"timestamp_normalization"`
+	TimestampPolicy        *TimestampPolicy       `json:"timestamp_policy,omitempty"`
+	Unit                   string                 `json:"unit,omitempty"`
+	SkipHistorical         bool                   `json:"skip_historical,omitempty"`
+	WriteSampling          *WriteSampling         `json:"write_sampling,omitempty"`
+	WriteBatching          *WriteBatching         `json:"write_batching,omitempty"`
+	ListPolicy             *ListPolicy            `json:"list_policy,omitempty"`
+	AsyncWrites            bool                   `json:"async_writes,omitempty"`
+	Validations            *Validations           `json:"validations,omitempty"`
+	Fallback               *Fallback              `json:"fallback,omitempty"`
+	Encryption             *Encryption            `json:"encryption,omitempty"`
+	PII                    bool                   `json:"pii,omitempty"`
+	Classification         Classification         `json:"classification,omitempty"`
+	Masking                Masking                `json:"masking,omitempty"`
+	Owner                  string                 `json:"owner,omitempty"`
+	Description            string                 `json:"description,omitempty"`
+	Tags                   []string               `json:"tags,omitempty"`
+	Links                  []FeatureLink          `json:"links,omitempty"`
 }
 type KeepPrevious struct {
 	Versions uint
 	Over     time.Duration
 }
 
+// WriteSampling limits the writes of the feature-values of each entity to one per Interval.
+// When KeepFirst is set, the first value of the interval is written and the rest are dropped. Otherwise, the latest
+// value of the interval is written at its end.
+type WriteSampling struct {
+	Interval  time.Duration
+	KeepFirst bool
+}
+
+// DefaultWriteBatchSize is the default maximal number of entities in a write batch.
+const DefaultWriteBatchSize = 1000
+
+// WriteBatching buffers the writes of the feature-values for up to Window, and flushes them in batches of up to
+// MaxSize entities. The writes of each entity within the window are coalesced to a single write.
+type WriteBatching struct {
+	Window  time.Duration
+	MaxSize int
+}
+
 // ValidWindow checks if the feature have aggregation enabled, and if it is valid
 func (fd FeatureDescriptor) ValidWindow() bool {
 	if fd.Freshness < 1 {
@@ -57,11 +110,33 @@ func (fd FeatureDescriptor) ValidWindow() bool {
 	if len(fd.Aggr) == 0 {
 		return false
 	}
+	if fd.WindowSlide < 0 || fd.SessionGap < 0 || fd.HalfLife < 0 {
+		return false
+	}
+	if len(fd.Aggr) == 1 && fd.Aggr[0] == AggrFnTopK {
+		return fd.Primitive == PrimitiveTypeStringList
+	}
 	if !(fd.Primitive == PrimitiveTypeInteger || fd.Primitive == PrimitiveTypeFloat) {
 		return false
 	}
 	return true
 }
+
+// Virtual checks if the feature is computed on request (see OnDemandBuilder), and therefore has no stored values.
+func (fd FeatureDescriptor) Virtual() bool {
+	return fd.Builder == OnDemandBuilder
+}
+
+func countSet(vals ...bool) int {
+	n := 0
+	for _, v := range vals {
+		if v {
+			n++
+		}
+	}
+	return n
+}
+
 func aggrsToStrings(a []manifests.AggrFn) []string {
 	var res []string
 	for _, v := range a {
@@ -80,29 +155,67 @@ func FeatureDescriptorFromManifest(in *manifests.Feature) (*FeatureDescriptor, e
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse aggregation functions: %w", err)
 	}
-	if len(aggr) > 0 && primitive != PrimitiveTypeInteger && primitive != PrimitiveTypeFloat {
+	// `top_k` counts the most frequent values, so it's aggregating strings into a list
+	topK := len(aggr) == 1 && aggr[0] == AggrFnTopK && primitive == PrimitiveTypeStringList
+	if len(aggr) > 0 && !topK && primitive != PrimitiveTypeInteger && primitive != PrimitiveTypeFloat {
 		return nil, fmt.Errorf("%w with Aggregation: %s", ErrUnsupportedPrimitiveError, in.Spec.Primitive)
 	}
 	if in.Spec.Builder.AggrGranularity.Milliseconds() > 0 && len(aggr) > 0 {
 		in.Spec.Freshness = in.Spec.Builder.AggrGranularity
 	}
 
+	tsNormalization, err := StringToTimestampNormalization(in.Spec.TimestampNormalization)
+	if err != nil {
+		return nil, err
+	}
+	tsPolicy, err := TimestampPolicyFromManifest(in.Spec.TimestampPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if tsPolicy != nil && tsPolicy.Field != nil && in.Spec.DataSource == nil {
+		return nil, fmt.Errorf("the timestamp policy `field` can be used only with a DataSource")
+	}
+
+	if in.Spec.Unit != "" && primitive.Singular() != PrimitiveTypeInteger && primitive.Singular() != PrimitiveTypeFloat {
+		return nil, fmt.Errorf("%w with Unit: %s", ErrUnsupportedPrimitiveError, in.Spec.Primitive)
+	}
+
+	b := in.Spec.Builder
+	if (b.Field != "" || b.SQL != "" || b.CEL != "") && b.Code == "" && in.Spec.DataSource == nil {
+		return nil, fmt.Errorf("builder `field`, `sql` and `cel` can be used only with a DataSource")
+	}
+	if n := countSet(b.Code != "", b.SQL != "", b.CEL != "", b.Wasm != nil); n > 1 {
+		return nil, fmt.Errorf("builder `code`, `sql`, `cel` and `wasm` are mutually exclusive")
+	}
+
 	deps := make([]string, len(in.Status.Dependencies))
 	for i, dep := range in.Status.Dependencies {
 		deps[i] = dep.FQN()
 	}
 
 	fd := &FeatureDescriptor{
-		FQN:          in.FQN(),
-		Primitive:    primitive,
-		Aggr:         aggr,
-		Freshness:    in.Spec.Freshness.Duration,
-		Staleness:    in.Spec.Staleness.Duration,
-		Timeout:      in.Spec.Timeout.Duration,
-		Keys:         in.Spec.Keys,
-		RuntimeEnv:   in.Spec.Builder.Runtime,
-		Builder:      strings.ToLower(in.Spec.Builder.Kind),
-		Dependencies: deps,
+		FQN:                    in.FQN(),
+		Primitive:              primitive,
+		Aggr:                   aggr,
+		Freshness:              in.Spec.Freshness.Duration,
+		Staleness:              in.Spec.Staleness.Duration,
+		WindowSlide:            in.Spec.Builder.AggrSlide.Duration,
+		SessionGap:             in.Spec.Builder.AggrSessionGap.Duration,
+		TopK:                   in.Spec.Builder.AggrTopK,
+		HalfLife:               in.Spec.Builder.AggrHalfLife.Duration,
+		AllowedLateness:        in.Spec.Builder.AllowedLateness.Duration,
+		ExecutionTimeout:       in.Spec.Builder.ExecutionTimeout.Duration,
+		CircuitBreaker:         in.Spec.Builder.CircuitBreaker,
+		Timeout:                in.Spec.Timeout.Duration,
+		Keys:                   in.Spec.Keys,
+		RuntimeEnv:             in.Spec.Builder.Runtime,
+		Builder:                strings.ToLower(in.Spec.Builder.Kind),
+		Dependencies:           deps,
+		TimestampNormalization: tsNormalization,
+		TimestampPolicy:        tsPolicy,
+		Unit:                   NormalizeUnit(in.Spec.Unit),
+		SkipHistorical:         in.Spec.Historical != nil && !*in.Spec.Historical,
+		AsyncWrites:            strings.ToLower(in.Spec.WriteMode) == "async",
 	}
 	if in.Spec.KeepPrevious != nil {
 		fd.KeepPrevious = &KeepPrevious{
@@ -110,15 +223,171 @@ func FeatureDescriptorFromManifest(in *manifests.Feature) (*FeatureDescriptor, e
 			Over:     in.Spec.KeepPrevious.Over.Duration,
 		}
 	}
+	if in.Spec.WriteSampling != nil {
+		fd.WriteSampling = &WriteSampling{
+			Interval:  in.Spec.WriteSampling.Interval.Duration,
+			KeepFirst: strings.ToLower(in.Spec.WriteSampling.Keep) == "first",
+		}
+	}
+	if in.Spec.WriteBatching != nil {
+		fd.WriteBatching = &WriteBatching{
+			Window:  in.Spec.WriteBatching.Window.Duration,
+			MaxSize: in.Spec.WriteBatching.MaxSize,
+		}
+		if fd.WriteBatching.MaxSize == 0 {
+			fd.WriteBatching.MaxSize = DefaultWriteBatchSize
+		}
+	}
+	if in.Spec.Validations != nil {
+		fd.Validations, err = ValidationsFromManifest(in.Spec.Validations, primitive)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fd.ListPolicy, err = ListPolicyFromManifest(in.Spec.ListPolicy, primitive)
+	if err != nil {
+		return nil, err
+	}
+	fd.Fallback, err = FallbackFromManifest(in.Spec.Fallback, primitive)
+	if err != nil {
+		return nil, err
+	}
+	fd.Encryption, err = EncryptionFromManifest(in.Spec.Encryption)
+	if err != nil {
+		return nil, err
+	}
+	if err := privacyFromManifest(fd, in.Spec.PII, in.Spec.Classification, in.Spec.Masking); err != nil {
+		return nil, err
+	}
+	if err := catalogFromManifest(fd, in); err != nil {
+		return nil, err
+	}
 	if in.Spec.DataSource != nil {
 		fd.DataSource = in.Spec.DataSource.FQN()
 	}
+	if in.Spec.Entity != "" {
+		fd.Entity = manifests.FQNFormatter(in.GetNamespace(), in.Spec.Entity)
+	}
 	if fd.Builder == "" {
 		fd.Builder = SourcelessBuilder
 	}
+	if fd.Virtual() {
+		if b.Code == "" {
+			return nil, fmt.Errorf("`%s` features must have a `code` program", OnDemandBuilder)
+		}
+		if fd.DataSource != "" || len(fd.Aggr) > 0 || fd.KeepPrevious != nil || fd.WriteSampling != nil ||
+			fd.WriteBatching != nil || fd.AsyncWrites || fd.Validations != nil || fd.ListPolicy != nil {
+			return nil, fmt.Errorf("`%s` features are computed on request, so they can't have a DataSource, "+
+				"aggregations, `keepPrevious`, `writeSampling`, `writeBatching`, async `writeMode`, `validations` "+
+				"or `listPolicy`", OnDemandBuilder)
+		}
+		if fd.Fallback != nil && fd.Fallback.LastKnown > 0 {
+			return nil, fmt.Errorf("`%s` features are computed on request, so they have no last known value", OnDemandBuilder)
+		}
+	}
 
 	if len(fd.Aggr) > 0 && !fd.ValidWindow() {
 		return nil, fmt.Errorf("invalid feature specification for windowed feature")
 	}
+	if fd.Fallback != nil && fd.ValidWindow() {
+		return nil, fmt.Errorf("`fallback` can't be used with windowed features, since a window always has a result")
+	}
+	if fd.Encryption != nil {
+		if fd.ValidWindow() {
+			return nil, fmt.Errorf("`encryption` can't be used with windowed features, since their values are aggregated")
+		}
+		if fd.DataSource == "" {
+			return nil, fmt.Errorf("`encryption` can be used only with features that have a DataSource, since the " +
+				"values of other features are not stored")
+		}
+	}
+	if fd.WriteSampling != nil {
+		if fd.WriteSampling.Interval <= 0 {
+			return nil, fmt.Errorf("the `writeSampling` interval must be positive")
+		}
+		if fd.ValidWindow() {
+			return nil, fmt.Errorf("`writeSampling` can't be used with windowed features, since every value is aggregated")
+		}
+	}
+	if fd.WriteBatching != nil {
+		if fd.WriteBatching.Window <= 0 || fd.WriteBatching.MaxSize < 1 {
+			return nil, fmt.Errorf("the `writeBatching` window and max size must be positive")
+		}
+		if fd.ValidWindow() {
+			return nil, fmt.Errorf("`writeBatching` can't be used with windowed features")
+		}
+	}
+	if fd.ListPolicy != nil && fd.ValidWindow() {
+		return nil, fmt.Errorf("`listPolicy` can't be used with windowed features, since their values are aggregated")
+	}
+	if fd.AsyncWrites && fd.ValidWindow() {
+		return nil, fmt.Errorf("the async `writeMode` can't be used with windowed features")
+	}
+	if in.Spec.Warmup != nil && fd.ValidWindow() {
+		return nil, fmt.Errorf("`warmup` can't be used with windowed features, since their buckets aren't loaded")
+	}
+	if fd.WindowSlide > 0 {
+		if !fd.ValidWindow() {
+			return nil, fmt.Errorf("`aggrSlide` can be used only with windowed features")
+		}
+		if fd.WindowSlide%fd.Freshness != 0 || fd.Staleness%fd.WindowSlide != 0 {
+			return nil, fmt.Errorf("`aggrSlide` must be a multiple of the granularity, and the staleness must be a multiple of `aggrSlide`")
+		}
+	}
+	if fd.SessionGap > 0 {
+		if !fd.ValidWindow() {
+			return nil, fmt.Errorf("`aggrSessionGap` can be used only with windowed features")
+		}
+		if fd.WindowSlide > 0 {
+			return nil, fmt.Errorf("`aggrSessionGap` and `aggrSlide` are mutually exclusive")
+		}
+		if fd.SessionGap > fd.Staleness {
+			return nil, fmt.Errorf("the staleness must be at least `aggrSessionGap`")
+		}
+	}
+	if fd.AllowedLateness > 0 && fd.ValidWindow() {
+		// a late event must land in a bucket that is still alive
+		if max := fd.Staleness + DeadGracePeriod - fd.Freshness; fd.AllowedLateness > max {
+			return nil, fmt.Errorf("`allowedLateness` must be at most %s for this feature", max)
+		}
+	}
+	if fd.ExecutionTimeout < 0 {
+		return nil, fmt.Errorf("`executionTimeout` must be positive")
+	}
+	if fd.CircuitBreaker < 0 {
+		return nil, fmt.Errorf("`circuitBreaker` must be positive")
+	}
+	if fd.HalfLife > 0 {
+		if !fd.ValidWindow() {
+			return nil, fmt.Errorf("`aggrHalfLife` can be used only with windowed features")
+		}
+		if fd.WindowSlide > 0 || fd.SessionGap > 0 {
+			return nil, fmt.Errorf("`aggrHalfLife` can't be used with `aggrSlide` or `aggrSessionGap`")
+		}
+		for _, fn := range fd.Aggr {
+			if fn != AggrFnSum && fn != AggrFnCount && fn != AggrFnAvg {
+				return nil, fmt.Errorf("the `%s` aggregation can't be used with decayed windows (`aggrHalfLife`)", fn)
+			}
+		}
+	}
+	for _, fn := range fd.Aggr {
+		if fn == AggrFnDuration && fd.SessionGap == 0 {
+			return nil, fmt.Errorf("the `duration` aggregation can be used only with session windows (`aggrSessionGap`)")
+		}
+		if _, ok := fn.Quantile(); ok && fd.SessionGap > 0 {
+			return nil, fmt.Errorf("the `%s` aggregation can't be used with session windows", fn)
+		}
+		if fn == AggrFnTopK {
+			if len(fd.Aggr) > 1 {
+				return nil, fmt.Errorf("the `top_k` aggregation can't be combined with other aggregations")
+			}
+			if fd.SessionGap > 0 {
+				return nil, fmt.Errorf("the `top_k` aggregation can't be used with session windows")
+			}
+		}
+	}
+	if fd.TopKWindow() && fd.TopK == 0 {
+		fd.TopK = DefaultTopK
+	}
 	return fd, nil
 }
@@ -0,0 +1,194 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// windowedFeature returns a windowed feature of a DataSource, like the `top-categories` sample.
+func windowedFeature(primitive string, aggr ...manifests.AggrFn) *manifests.Feature {
+	return &manifests.Feature{
+		ObjectMeta: metav1.ObjectMeta{Name: "top-categories", Namespace: "default"},
+		Spec: manifests.FeatureSpec{
+			Primitive:  manifests.PrimitiveType(primitive),
+			Freshness:  metav1.Duration{Duration: time.Hour},
+			Staleness:  metav1.Duration{Duration: 168 * time.Hour},
+			Keys:       []string{"user_id"},
+			DataSource: &manifests.ResourceReference{Name: "clicks", Namespace: "default"},
+			Builder: manifests.FeatureBuilder{
+				Field:           "category",
+				AggrGranularity: metav1.Duration{Duration: time.Hour},
+				AggrTopK:        5,
+				Aggr:            aggr,
+			},
+		},
+	}
+}
+
+func TestFeatureDescriptorFromManifest_Aggregations(t *testing.T) {
+	tests := []struct {
+		name      string
+		primitive string
+		aggr      []manifests.AggrFn
+		wantErr   error
+	}{
+		{name: "top_k of strings", primitive: "[]string", aggr: []manifests.AggrFn{"top_k"}},
+		{name: "sum of integers", primitive: "int", aggr: []manifests.AggrFn{"sum", "count"}},
+		{name: "sum of strings", primitive: "string", aggr: []manifests.AggrFn{"sum"}, wantErr: ErrUnsupportedPrimitiveError},
+		{name: "top_k of integers", primitive: "int", aggr: []manifests.AggrFn{"top_k"}, wantErr: errAny},
+		{name: "top_k with sum", primitive: "[]string", aggr: []manifests.AggrFn{"top_k", "sum"}, wantErr: ErrUnsupportedPrimitiveError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fd, err := FeatureDescriptorFromManifest(windowedFeature(tt.primitive, tt.aggr...))
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr == errAny && err == nil, tt.wantErr != nil && tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !fd.ValidWindow() {
+				t.Fatalf("expected a valid window")
+			}
+		})
+	}
+}
+
+func TestFeatureDescriptorFromManifest_TopK(t *testing.T) {
+	fd, err := FeatureDescriptorFromManifest(windowedFeature("[]string", "top_k"))
+	if err != nil {
+		t.Fatalf("failed to bind a `top_k` feature: %v", err)
+	}
+	if !fd.TopKWindow() {
+		t.Errorf("expected a top_k window")
+	}
+	if fd.TopK != 5 {
+		t.Errorf("expected TopK of 5, got %d", fd.TopK)
+	}
+}
+
+func TestFeatureDescriptorFromManifest_Encryption(t *testing.T) {
+	enc := &manifests.FeatureEncryption{Provider: "vault", KeyID: "pii"}
+	tests := []struct {
+		name    string
+		mutate  func(in *manifests.Feature)
+		wantErr bool
+	}{
+		{name: "encrypted", mutate: func(in *manifests.Feature) {}},
+		{name: "windowed", mutate: func(in *manifests.Feature) { in.Spec.Builder.Aggr = []manifests.AggrFn{"sum"} }, wantErr: true},
+		{name: "without a DataSource", mutate: func(in *manifests.Feature) { in.Spec.DataSource = nil }, wantErr: true},
+		{name: "without a key", mutate: func(in *manifests.Feature) { in.Spec.Encryption = &manifests.FeatureEncryption{Provider: "vault"} }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := windowedFeature("int")
+			in.Spec.Builder.AggrGranularity = metav1.Duration{}
+			in.Spec.Encryption = enc
+			tt.mutate(in)
+			fd, err := FeatureDescriptorFromManifest(in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err == nil && (fd.Encryption == nil || fd.Encryption.KeyID != "pii") {
+				t.Errorf("expected the encryption to be parsed, got %+v", fd.Encryption)
+			}
+		})
+	}
+}
+
+func TestFeatureDescriptorFromManifest_WriteBatching(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(in *manifests.Feature)
+		size    int
+		wantErr bool
+	}{
+		{name: "default size", mutate: func(in *manifests.Feature) {}, size: DefaultWriteBatchSize},
+		{name: "custom size", mutate: func(in *manifests.Feature) { in.Spec.WriteBatching.MaxSize = 50 }, size: 50},
+		{name: "windowed", mutate: func(in *manifests.Feature) { in.Spec.Builder.Aggr = []manifests.AggrFn{"sum"} }, wantErr: true},
+		{name: "without a window", mutate: func(in *manifests.Feature) { in.Spec.WriteBatching.Window = metav1.Duration{} }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := windowedFeature("int")
+			in.Spec.Builder.AggrGranularity = metav1.Duration{}
+			in.Spec.WriteBatching = &manifests.WriteBatching{Window: metav1.Duration{Duration: 50 * time.Millisecond}}
+			tt.mutate(in)
+			fd, err := FeatureDescriptorFromManifest(in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err == nil && (fd.WriteBatching == nil || fd.WriteBatching.MaxSize != tt.size) {
+				t.Errorf("expected a batch size of %d, got %+v", tt.size, fd.WriteBatching)
+			}
+		})
+	}
+}
+
+func TestFeatureDescriptorFromManifest_Privacy(t *testing.T) {
+	tests := []struct {
+		name        string
+		mutate      func(in *manifests.Feature)
+		wantMasking Masking
+		wantErr     bool
+	}{
+		{name: "untagged", mutate: func(in *manifests.Feature) {}},
+		{name: "pii", mutate: func(in *manifests.Feature) { in.Spec.PII = true }, wantMasking: MaskingHash},
+		{name: "confidential", mutate: func(in *manifests.Feature) {
+			in.Spec.Classification = "Confidential"
+			in.Spec.Masking = "redact"
+		}, wantMasking: MaskingRedact},
+		{name: "internal", mutate: func(in *manifests.Feature) { in.Spec.Classification = "internal" }},
+		{name: "masking of an insensitive feature", mutate: func(in *manifests.Feature) { in.Spec.Masking = "hash" }, wantErr: true},
+		{name: "unknown classification", mutate: func(in *manifests.Feature) { in.Spec.Classification = "secret" }, wantErr: true},
+		{name: "unknown masking", mutate: func(in *manifests.Feature) {
+			in.Spec.PII = true
+			in.Spec.Masking = "shuffle"
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := windowedFeature("int")
+			tt.mutate(in)
+			fd, err := FeatureDescriptorFromManifest(in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if fd.Masking != tt.wantMasking {
+				t.Errorf("got masking %q, want %q", fd.Masking, tt.wantMasking)
+			}
+			if fd.Sensitive() != (tt.wantMasking != "") {
+				t.Errorf("got sensitive %v", fd.Sensitive())
+			}
+		})
+	}
+}
+
+// errAny matches any error.
+var errAny = errors.New("any error")
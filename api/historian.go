@@ -19,6 +19,7 @@ package api
 import (
 	"context"
 	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"time"
 )
 
 type Notification interface {
@@ -35,6 +36,9 @@ type WriteNotification struct {
 	Bucket       string `json:"bucket,omitempty"`
 	ActiveBucket bool   `json:"active_bucket,omitempty"`
 	Value        *Value `json:"value,omitempty"`
+	// Tombstone marks that the values of the entity were deleted at the Value's timestamp (see EntityDeleter). The
+	// Value of tombstones is nil, so it ends the validity of the previous value.
+	Tombstone bool `json:"tombstone,omitempty"`
 }
 
 // Notifier is the interface to be implemented by plugins that want to provide a Queue implementation
@@ -44,6 +48,11 @@ type Notifier[T Notification] interface {
 	Subscribe(context.Context) (<-chan T, error)
 }
 
+// BatchNotifier is an optional interface of a Notifier that can send several notifications in a single operation.
+type BatchNotifier[T Notification] interface {
+	NotifyBatch(context.Context, []T) error
+}
+
 type HistoricalWriter interface {
 	Commit(context.Context, WriteNotification) error
 	Flush(ctx context.Context, fqn string) error
@@ -51,3 +60,39 @@ type HistoricalWriter interface {
 	Close(ctx context.Context) error
 	BindFeature(fd *FeatureDescriptor, model *manifests.ModelSpec, getter FeatureDescriptorGetter) error
 }
+
+// HistoricalExporter is an optional interface of a HistoricalWriter that can export the historical records of a
+// Feature or a Model (i.e. to generate a training set).
+type HistoricalExporter interface {
+	// Export writes the records between `since` and `until` to the destination (i.e. `s3://bucket/dataset.parquet`).
+	Export(ctx context.Context, fqn string, since, until time.Time, dest string) error
+}
+
+// HistoricalCompactor is an optional interface of a HistoricalWriter that merges the small files of its output, so
+// they're queried efficiently.
+type HistoricalCompactor interface {
+	Compact(ctx context.Context) error
+}
+
+// HistoricalPruner is an optional interface of a HistoricalWriter that can remove the historical records of a Feature
+// that were written before a given time (see the Feature's retention).
+type HistoricalPruner interface {
+	// Prune removes the records of the feature that were written before `before`, and reports what was removed. When
+	// dryRun is set, it only reports what would be removed.
+	Prune(ctx context.Context, fqn string, before time.Time, dryRun bool) (PruneReport, error)
+}
+
+// PruneReport reports the historical data that was removed by a HistoricalPruner.
+type PruneReport struct {
+	Partitions int
+	Files      int
+	Bytes      int64
+}
+
+// HistoricalLatestReader is an optional interface of a HistoricalWriter that can read the latest historical value of
+// each entity of a Feature (i.e. to warm up the state from a backfilled feature).
+type HistoricalLatestReader interface {
+	// LatestValues calls fn with the encoded keys and the latest value of each entity of the (non-windowed) feature
+	// that was written at or after `since`. Entities whose latest record is a tombstone are skipped.
+	LatestValues(ctx context.Context, fqn string, since time.Time, fn func(encodedKeys string, val Value) error) error
+}
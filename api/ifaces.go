@@ -27,8 +27,20 @@ import (
 	"time"
 )
 
+// Keys are the IDs of the entity, by the names of the keys (i.e. `user_id`). Features that are keyed by multiple join
+// keys (i.e. `user_id` and `merchant_id`) are keyed by a composite of all of them.
 type Keys map[string]string
 
+// keySeparator separates the parts of composite keys in their encoded form.
+const keySeparator = ";"
+
+// The separator (and the escape character) are percent-encoded in the key parts, so the IDs can contain any character,
+// and the encoded form of IDs without them is kept as is.
+var (
+	keyPartEscaper   = strings.NewReplacer("%", "%25", keySeparator, "%3B")
+	keyPartUnescaper = strings.NewReplacer("%25", "%", "%3B", keySeparator, "%3b", keySeparator)
+)
+
 func (k *Keys) String() string {
 	vals := url.Values{}
 	for k, v := range *k {
@@ -36,23 +48,24 @@ func (k *Keys) String() string {
 	}
 	return vals.Encode()
 }
+
+// Encode returns the canonical encoding of the keys of the feature: the (escaped) IDs of its keys, by the order of the
+// feature's keys, separated by `;`.
 func (k *Keys) Encode(fd FeatureDescriptor) (string, error) {
-	var ret []string
-	for _, key := range fd.Keys {
+	ret := make([]string, len(fd.Keys))
+	for i, key := range fd.Keys {
 		val, ok := (*k)[key]
 		if !ok {
 			return "", fmt.Errorf("missing key %q", key)
 		}
-		if n := strings.IndexByte(val, ';'); n != -1 {
-			return "", fmt.Errorf("key %q contains invalid character `;`", key)
-		}
-		ret = append(ret, val)
+		ret[i] = keyPartEscaper.Replace(val)
 	}
-	return strings.Join(ret, ";"), nil
+	return strings.Join(ret, keySeparator), nil
 }
 
+// Decode sets the keys of the feature from their canonical encoding (see Encode).
 func (k *Keys) Decode(encodedKeys string, fd FeatureDescriptor) error {
-	vals := strings.Split(encodedKeys, ";")
+	vals := DecodeKeyParts(encodedKeys)
 	if len(vals) != len(fd.Keys) {
 		return fmt.Errorf("expected %d keys, got %d", len(fd.Keys), len(vals))
 	}
@@ -62,6 +75,15 @@ func (k *Keys) Decode(encodedKeys string, fd FeatureDescriptor) error {
 	return nil
 }
 
+// DecodeKeyParts returns the IDs of the parts of encoded keys, by the order of the feature's keys.
+func DecodeKeyParts(encodedKeys string) []string {
+	vals := strings.Split(encodedKeys, keySeparator)
+	for i, v := range vals {
+		vals[i] = keyPartUnescaper.Replace(v)
+	}
+	return vals
+}
+
 // Engine is the main engine of the Core
 // It is responsible for the low-level operation for the features against the feature store
 type Engine interface {
@@ -104,6 +126,19 @@ type FeatureManager interface {
 	HasFeature(FQN string) bool
 }
 
+// BindErrorReporter is implemented by FeatureManagers that can report why a Feature failed to be bound.
+type BindErrorReporter interface {
+	// BindError returns the error of the latest binding of the feature, or nil if it succeeded.
+	BindError(FQN string) error
+}
+
+// CircuitBreakerReporter is implemented by FeatureManagers that guard the programs of the Features with circuit
+// breakers.
+type CircuitBreakerReporter interface {
+	// CircuitOpen returns the error that opened the circuit breaker of the feature, or nil if it's closed.
+	CircuitOpen(FQN string) error
+}
+
 // DataSourceManager is managing DataSource(s) within Core
 // It is responsible for maintaining the DataSource(s) in an internal store
 type DataSourceManager interface {
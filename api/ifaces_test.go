@@ -0,0 +1,61 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeys_EncodeComposite(t *testing.T) {
+	fd := FeatureDescriptor{FQN: "default.spend", Keys: []string{"user_id", "merchant_id"}}
+	tests := []struct {
+		name    string
+		keys    Keys
+		encoded string
+	}{
+		{name: "plain", keys: Keys{"user_id": "1", "merchant_id": "m-2"}, encoded: "1;m-2"},
+		{name: "separator", keys: Keys{"user_id": "a;b", "merchant_id": "c"}, encoded: "a%3Bb;c"},
+		{name: "escape character", keys: Keys{"user_id": "100%", "merchant_id": "%3B"}, encoded: "100%25;%253B"},
+		{name: "empty part", keys: Keys{"user_id": "", "merchant_id": "2"}, encoded: ";2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.keys.Encode(fd)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if encoded != tt.encoded {
+				t.Fatalf("expected %q, got %q", tt.encoded, encoded)
+			}
+			decoded := Keys{}
+			if err := decoded.Decode(encoded, fd); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.keys) {
+				t.Fatalf("expected %v, got %v", tt.keys, decoded)
+			}
+		})
+	}
+
+	if _, err := (&Keys{"user_id": "1"}).Encode(fd); err == nil {
+		t.Fatalf("expected an error for a missing key part")
+	}
+	if err := (&Keys{}).Decode("1;2;3", fd); err == nil {
+		t.Fatalf("expected an error for a mismatched number of key parts")
+	}
+}
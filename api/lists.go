@@ -0,0 +1,62 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+)
+
+// ListPolicy bounds the values of a list feature, and defines how the appended elements are merged into them. It's
+// enforced by the State on every write of the list.
+type ListPolicy struct {
+	// MaxLength is the maximal number of elements of the list, of which the latest are kept (0 for unbounded).
+	MaxLength int `json:"max_length,omitempty"`
+	// Dedup removes the previous occurrences of an appended element.
+	Dedup bool `json:"dedup,omitempty"`
+	// UpsertKeyDelimiter separates the key of the elements from their value, for lists with upsert semantics (empty
+	// for none). An appended element replaces the elements with the same key.
+	UpsertKeyDelimiter string `json:"upsert_key_delimiter,omitempty"`
+}
+
+// ListPolicyFromManifest parses the list policy of a Feature of the given primitive. It returns nil if no policy was
+// defined.
+func ListPolicyFromManifest(in *manifests.ListPolicy, primitive PrimitiveType) (*ListPolicy, error) {
+	if in == nil {
+		return nil, nil
+	}
+	if primitive.Scalar() {
+		return nil, fmt.Errorf("`listPolicy` can be used only with list features")
+	}
+	if in.MaxLength < 0 {
+		return nil, fmt.Errorf("the `listPolicy` max length must not be negative")
+	}
+	if in.UpsertKeyDelimiter != "" {
+		if primitive != PrimitiveTypeStringList {
+			return nil, fmt.Errorf("the `listPolicy` upsert key delimiter can be used only with lists of strings")
+		}
+		if in.Dedup {
+			return nil, fmt.Errorf("the `listPolicy` `dedup` and `upsertKeyDelimiter` are mutually exclusive, since " +
+				"upserts already keep every key once")
+		}
+	}
+	return &ListPolicy{
+		MaxLength:          in.MaxLength,
+		Dedup:              in.Dedup,
+		UpsertKeyDelimiter: in.UpsertKeyDelimiter,
+	}, nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+)
+
+func TestListPolicyFromManifest(t *testing.T) {
+	tests := []struct {
+		name      string
+		primitive PrimitiveType
+		policy    manifests.ListPolicy
+		wantErr   bool
+	}{
+		{name: "bounded", primitive: PrimitiveTypeIntegerList, policy: manifests.ListPolicy{MaxLength: 10, Dedup: true}},
+		{name: "upsert", primitive: PrimitiveTypeStringList, policy: manifests.ListPolicy{UpsertKeyDelimiter: ":"}},
+		{name: "scalar", primitive: PrimitiveTypeString, policy: manifests.ListPolicy{MaxLength: 10}, wantErr: true},
+		{name: "negative length", primitive: PrimitiveTypeStringList, policy: manifests.ListPolicy{MaxLength: -1}, wantErr: true},
+		{name: "upsert of integers", primitive: PrimitiveTypeIntegerList, policy: manifests.ListPolicy{UpsertKeyDelimiter: ":"}, wantErr: true},
+		{name: "upsert with dedup", primitive: PrimitiveTypeStringList, policy: manifests.ListPolicy{Dedup: true, UpsertKeyDelimiter: ":"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lp, err := ListPolicyFromManifest(&tt.policy, tt.primitive)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if lp.MaxLength != tt.policy.MaxLength || lp.Dedup != tt.policy.Dedup || lp.UpsertKeyDelimiter != tt.policy.UpsertKeyDelimiter {
+				t.Fatalf("unexpected policy %+v", lp)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONPath is a compiled JSONPath expression.
+//
+// The supported syntax is a subset of JSONPath:
+//   - `$` is the root of the row
+//   - `.field` or `['field']` selects a field
+//   - `[n]` selects the n-th element of a list (negative indexes select from the end)
+//   - `[*]` selects all the elements of a list
+type JSONPath struct {
+	expr  string
+	steps []pathStep
+}
+
+type pathStep struct {
+	field    string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// CompileJSONPath compiles a JSONPath expression.
+func CompileJSONPath(expr string) (*JSONPath, error) {
+	p := &JSONPath{expr: expr}
+	s := strings.TrimSpace(expr)
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("invalid JSONPath `%s`: must start with `$`", expr)
+	}
+	s = s[1:]
+
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end == -1 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSONPath `%s`: empty field name", expr)
+			}
+			if s[:end] == "*" {
+				p.steps = append(p.steps, pathStep{wildcard: true})
+			} else {
+				p.steps = append(p.steps, pathStep{field: s[:end]})
+			}
+			s = s[end:]
+		case '[':
+			end := strings.Index(s, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("invalid JSONPath `%s`: missing `]`", expr)
+			}
+			sel := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+			switch {
+			case sel == "*":
+				p.steps = append(p.steps, pathStep{wildcard: true})
+			case len(sel) >= 2 && (sel[0] == '\'' || sel[0] == '"') && sel[len(sel)-1] == sel[0]:
+				p.steps = append(p.steps, pathStep{field: sel[1 : len(sel)-1]})
+			default:
+				i, err := strconv.Atoi(sel)
+				if err != nil {
+					return nil, fmt.Errorf("invalid JSONPath `%s`: unsupported selector `[%s]`", expr, sel)
+				}
+				p.steps = append(p.steps, pathStep{index: i, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("invalid JSONPath `%s`: unexpected `%c`", expr, s[0])
+		}
+	}
+	return p, nil
+}
+
+// String returns the original expression.
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// Get evaluates the expression on the row. It returns nil if the path doesn't exist.
+func (p *JSONPath) Get(row map[string]any) any {
+	return getPath(row, p.steps)
+}
+
+func getPath(val any, steps []pathStep) any {
+	if len(steps) == 0 {
+		return val
+	}
+	// nested objects might have been flattened to JSON strings by the decoders
+	if s, ok := val.(string); ok && len(s) > 0 && (s[0] == '{' || s[0] == '[') {
+		var v any
+		if err := json.Unmarshal([]byte(s), &v); err == nil {
+			val = v
+		}
+	}
+
+	step := steps[0]
+	switch {
+	case step.wildcard:
+		var items []any
+		switch v := val.(type) {
+		case []any:
+			items = v
+		case map[string]any:
+			for _, item := range v {
+				items = append(items, item)
+			}
+		default:
+			return nil
+		}
+		var ret []any
+		for _, item := range items {
+			if r := getPath(item, steps[1:]); r != nil {
+				ret = append(ret, r)
+			}
+		}
+		if len(ret) == 0 {
+			return nil
+		}
+		return ret
+	case step.isIndex:
+		l, ok := val.([]any)
+		if !ok {
+			return nil
+		}
+		i := step.index
+		if i < 0 {
+			i += len(l)
+		}
+		if i < 0 || i >= len(l) {
+			return nil
+		}
+		return getPath(l[i], steps[1:])
+	default:
+		m, ok := val.(map[string]any)
+		if !ok {
+			return nil
+		}
+		return getPath(m[step.field], steps[1:])
+	}
+}
+
+// Mapping is a compiled DataSource mapping. It extracts the keys, timestamp and fields of the rows declaratively.
+type Mapping struct {
+	Keys      map[string]*JSONPath
+	Timestamp *JSONPath
+	Fields    map[string]*JSONPath
+}
+
+// MappingFromManifest compiles the mapping of a DataSource. It returns nil if no mapping was defined.
+func MappingFromManifest(in *manifests.DataSourceMapping) (*Mapping, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	m := &Mapping{
+		Keys:   make(map[string]*JSONPath, len(in.Keys)),
+		Fields: make(map[string]*JSONPath, len(in.Fields)),
+	}
+	for k, expr := range in.Keys {
+		p, err := CompileJSONPath(expr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile mapping for key `%s`: %w", k, err)
+		}
+		m.Keys[k] = p
+	}
+	for f, expr := range in.Fields {
+		p, err := CompileJSONPath(expr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile mapping for field `%s`: %w", f, err)
+		}
+		m.Fields[f] = p
+	}
+	if in.Timestamp != "" {
+		p, err := CompileJSONPath(in.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile mapping for timestamp: %w", err)
+		}
+		m.Timestamp = p
+	}
+	return m, nil
+}
+
+// Apply extracts the mapped values from the row. The mapped keys and fields are added to the row (overriding existing
+// fields with the same name). If a timestamp is mapped and can be parsed, it is returned instead of the given ts.
+func (m *Mapping) Apply(row map[string]any, ts time.Time) (map[string]any, time.Time) {
+	if m == nil {
+		return row, ts
+	}
+
+	ret := make(map[string]any, len(row)+len(m.Keys)+len(m.Fields))
+	for k, v := range row {
+		ret[k] = v
+	}
+	for f, p := range m.Fields {
+		if v := NormalizeValue(p.Get(row)); v != nil {
+			ret[f] = v
+		}
+	}
+	for k, p := range m.Keys {
+		if v := p.Get(row); v != nil {
+			ret[k] = fmt.Sprint(NormalizeValue(v))
+		}
+	}
+	if m.Timestamp != nil {
+		if t, err := ParseTimestamp(m.Timestamp.Get(row)); err == nil {
+			ts = t
+		}
+	}
+	return ret, ts
+}
@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+)
+
+const mappedRow = `{
+	"user": {"id": 7, "name": "John"},
+	"items": [{"sku": "a", "price": 1.5}, {"sku": "b", "price": 2}],
+	"payload": "{\"id\": \"p1\"}",
+	"at": "2022-10-01T12:00:00Z",
+	"odd key": true
+}`
+
+func decodedRow(t *testing.T) map[string]any {
+	var row map[string]any
+	if err := json.Unmarshal([]byte(mappedRow), &row); err != nil {
+		t.Fatalf("failed to decode the row: %v", err)
+	}
+	return row
+}
+
+func TestJSONPath_Get(t *testing.T) {
+	row := decodedRow(t)
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{expr: "$.user.name", want: "John"},
+		{expr: "$['user']['name']", want: "John"},
+		{expr: `$["odd key"]`, want: true},
+		{expr: "$.items[0].sku", want: "a"},
+		{expr: "$.items[-1].sku", want: "b"},
+		{expr: "$.items[*].sku", want: []any{"a", "b"}},
+		{expr: "$.items[*].missing"},
+		{expr: "$.items[2]"},
+		{expr: "$.items[-3]"},
+		{expr: "$.items.sku"},
+		{expr: "$.user[0]"},
+		{expr: "$.user.name.first"},
+		{expr: "$.missing.field"},
+		{expr: "$.payload.id", want: "p1"},
+		{expr: " $.at ", want: "2022-10-01T12:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p, err := CompileJSONPath(tt.expr)
+			if err != nil {
+				t.Fatalf("failed to compile: %v", err)
+			}
+			if got := p.Get(row); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileJSONPath_Errors(t *testing.T) {
+	for _, expr := range []string{"", "user", "$user", "$.", "$..a", "$.a.", "$[0", "$[abc]", "$[]", "$[']", "$['a]"} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := CompileJSONPath(expr); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestMapping_Apply(t *testing.T) {
+	m, err := MappingFromManifest(&manifests.DataSourceMapping{
+		Keys:      map[string]string{"user_id": "$.user.id", "missing": "$.missing"},
+		Timestamp: "$.at",
+		Fields:    map[string]string{"skus": "$.items[*].sku", "prices": "$.items[*].price", "user": "$.user"},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile the mapping: %v", err)
+	}
+
+	row := decodedRow(t)
+	now := time.Now()
+	got, ts := m.Apply(row, now)
+	if want := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC); !ts.Equal(want) {
+		t.Errorf("expected the mapped timestamp %s, got %s", want, ts)
+	}
+	want := map[string]any{
+		"user_id": "7",
+		"skus":    []string{"a", "b"},
+		"prices":  "[1.5 2]",
+		"user":    `{"id":7,"name":"John"}`,
+	}
+	for k, v := range want {
+		if !reflect.DeepEqual(got[k], v) {
+			t.Errorf("%s: got %#v, want %#v", k, got[k], v)
+		}
+	}
+	if _, ok := got["missing"]; ok {
+		t.Errorf("expected unmapped keys to be omitted")
+	}
+	if !reflect.DeepEqual(got["items"], row["items"]) {
+		t.Errorf("expected the original fields to be kept")
+	}
+
+	row["at"] = "not a timestamp"
+	if _, ts := m.Apply(row, now); !ts.Equal(now) {
+		t.Errorf("expected the given timestamp when the mapped one is invalid, got %s", ts)
+	}
+
+	var nilMapping *Mapping
+	if got, ts := nilMapping.Apply(row, now); !reflect.DeepEqual(got, row) || !ts.Equal(now) {
+		t.Errorf("expected a nil mapping to return the row as is")
+	}
+}
+
+func TestMappingFromManifest_Errors(t *testing.T) {
+	for name, in := range map[string]*manifests.DataSourceMapping{
+		"key":       {Keys: map[string]string{"id": "id"}},
+		"field":     {Fields: map[string]string{"f": "$["}},
+		"timestamp": {Timestamp: "$."},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := MappingFromManifest(in); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
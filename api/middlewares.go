@@ -51,8 +51,63 @@ const (
 
 	// ContextKeySelector is a key to store the requested Feature Selector.
 	ContextKeySelector
+
+	// ContextKeyEventID is a key to store the idempotency key of the write (i.e. the event's UUID, or its offset in the
+	// source), so replays of the same event are written only once.
+	ContextKeyEventID
+
+	// ContextKeyHistoricalOnly is a key to store the flag that indicates that the write should only be recorded in the
+	// historical storage, rather than in the online store (i.e. for backfills).
+	ContextKeyHistoricalOnly
+
+	// ContextKeyRequestContext is a key to store the request context that is sent by the caller of a Get, which is
+	// used to compute OnDemandBuilder features.
+	ContextKeyRequestContext
+
+	// ContextKeyCaller is a key to store the identity of the caller of the request, which is recorded in the audit log.
+	ContextKeyCaller
+
+	// ContextKeyWriteCondition is a key to store the condition of a conditional write (see WithWriteCondition).
+	ContextKeyWriteCondition
 )
 
+// WithWriteCondition makes a Set (or an Update of a scalar feature) conditional: it is applied only if the condition
+// is met by the stored value, and fails with ErrWriteConditionFailed otherwise.
+func WithWriteCondition(ctx context.Context, cond WriteCondition) context.Context {
+	return context.WithValue(ctx, ContextKeyWriteCondition, cond)
+}
+
+// WriteConditionFromContext returns the condition of the write, or nil if the write is unconditional.
+func WriteConditionFromContext(ctx context.Context) *WriteCondition {
+	if cond, ok := ctx.Value(ContextKeyWriteCondition).(WriteCondition); ok {
+		return &cond
+	}
+	return nil
+}
+
+// WithRequestContext attaches the request context (i.e. the current cart of the user) to a Get, so OnDemandBuilder
+// features can be computed from it.
+func WithRequestContext(ctx context.Context, rc map[string]any) context.Context {
+	return context.WithValue(ctx, ContextKeyRequestContext, rc)
+}
+
+// RequestContextFromContext returns the request context of the Get, or nil if none was sent.
+func RequestContextFromContext(ctx context.Context) map[string]any {
+	rc, _ := ctx.Value(ContextKeyRequestContext).(map[string]any)
+	return rc
+}
+
+// WithCaller attaches the identity of the caller (i.e. the service account or the address of the client) to a request.
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, ContextKeyCaller, caller)
+}
+
+// CallerFromContext returns the identity of the caller of the request, or an empty string if it's unknown.
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(ContextKeyCaller).(string)
+	return caller
+}
+
 // LoggerFromContext returns the logger from the context.
 // If not found it returns a discarded logger.
 func LoggerFromContext(ctx context.Context) logr.Logger {
@@ -22,6 +22,7 @@ type ModelDescriptor struct {
 	Features        []string               `json:"features"`
 	KeyFeature      string                 `json:"keyFeature,omitempty"`
 	Keys            []string               `json:"keys"`
+	Units           map[string]string      `json:"units,omitempty"`
 	ModelFramework  string                 `json:"modelFramework"`
 	ModelServer     string                 `json:"modelServer"`
 	InferenceConfig manifests.ParsedConfig `json:"inferenceConfig"`
@@ -0,0 +1,55 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// ValueDistribution is the distribution of the sampled values of a feature in a time window.
+type ValueDistribution struct {
+	From  time.Time `json:"from"`
+	Count uint64    `json:"count"`
+	Nulls uint64    `json:"nulls"`
+	// Mean, StdDev, Min, Max and Quantiles describe the numeric values.
+	Mean      float64            `json:"mean,omitempty"`
+	StdDev    float64            `json:"stddev,omitempty"`
+	Min       float64            `json:"min,omitempty"`
+	Max       float64            `json:"max,omitempty"`
+	Quantiles map[string]float64 `json:"quantiles,omitempty"`
+	// Categories counts the non-numeric values (i.e. strings and booleans).
+	Categories map[string]uint64 `json:"categories,omitempty"`
+}
+
+// ValueStats are the distributions of the values of a feature in the current and in the previous time windows.
+type ValueStats struct {
+	FQN      string             `json:"fqn"`
+	Current  ValueDistribution  `json:"current"`
+	Previous *ValueDistribution `json:"previous,omitempty"`
+	// Drift is the Population Stability Index (PSI) of the current window relative to the previous one.
+	// As a rule of thumb, a PSI above 0.1 indicates a moderate change, and above 0.25 a significant one.
+	Drift *float64 `json:"drift,omitempty"`
+}
+
+// ValueMonitor is implemented by engines that monitor the distribution of the features' values, so data drifts and
+// broken upstreams can be detected.
+type ValueMonitor interface {
+	// ValueStats returns the distribution of the values of the given feature, or of all the monitored features if
+	// the fqn is empty.
+	ValueStats(ctx context.Context, fqn string) ([]ValueStats, error)
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) 2022 RaptorML authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"fmt"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"regexp"
+	"strings"
+)
+
+// FQNScheme constructs and validates the fully qualified names (FQNs) of features.
+type FQNScheme interface {
+	// FQN returns the FQN of a resource by its Kubernetes namespace and name.
+	FQN(namespace, name string) string
+	// Join returns the FQN of a namespace and a name that are already in the form of the scheme.
+	Join(namespace, name string) string
+	// Split validates an FQN and splits it to its namespace and name.
+	// The namespace is empty if the FQN isn't qualified.
+	Split(fqn string) (namespace, name string, err error)
+}
+
+// NamingConfig configures the naming scheme of the features' FQNs.
+type NamingConfig struct {
+	// Separator separates the namespace from the name. Defaults to ".".
+	Separator string
+	// Charset is the set of characters that are allowed in the namespace and the name, in the form of the content of
+	// a regular expression character class (i.e. `a-z0-9_`). Defaults to lowercase alphanumerics separated by
+	// underscores.
+	Charset string
+	// MaxLength is the maximal length of an FQN. Zero means unlimited.
+	MaxLength int
+	// Prefix is prepended to the namespaces, i.e. to embed a domain or an owner code in the features' names.
+	Prefix string
+	// Legacy accepts FQNs of the default scheme (`<namespace>.<name>`), and translates them to the configured scheme,
+	// so existing clients and selectors keep working after the scheme is changed.
+	// Notice that values that were stored under the legacy FQNs are not migrated.
+	Legacy bool
+}
+
+const defaultSegment = `[a-z0-9]+(?:_[a-z0-9]+)*`
+
+var legacyFQNRegExp = regexp.MustCompile(`(?si)^(?:(` + defaultSegment + `)\.)?(` + defaultSegment + `)$`)
+
+// selectorRegExp separates the FQN of a selector from its modifiers (aggregation function, version and encoding).
+var selectorRegExp = regexp.MustCompile(`(?si)^(?P<fqn>.+?)(\+(?P<aggrFn>([a-z]+_*[a-z]+)))?(@-(?P<version>([0-9]+)))?(\[(?P<encoding>([a-z]+_*[a-z]+))])?$`)
+
+type namingScheme struct {
+	NamingConfig
+	charset *regexp.Regexp
+	fqn     *regexp.Regexp
+}
+
+// NewFQNScheme creates an FQNScheme by the given config.
+func NewFQNScheme(cfg NamingConfig) (FQNScheme, error) {
+	if cfg.Separator == "" {
+		cfg.Separator = "."
+	}
+	if strings.ContainsAny(cfg.Separator, "+@[]") {
+		return nil, fmt.Errorf("the FQN separator %q must not contain selector characters (`+`, `@`, `[`, `]`)", cfg.Separator)
+	}
+
+	segment := defaultSegment
+	var charset *regexp.Regexp
+	if cfg.Charset != "" {
+		if strings.ContainsAny(cfg.Charset, "+@[]") {
+			return nil, fmt.Errorf("the FQN charset %q must not contain selector characters (`+`, `@`, `[`, `]`)", cfg.Charset)
+		}
+		segment = fmt.Sprintf("[%s]+", cfg.Charset)
+		var err error
+		charset, err = regexp.Compile(fmt.Sprintf("[^%s]", cfg.Charset))
+		if err != nil {
+			return nil, fmt.Errorf("invalid FQN charset %q: %w", cfg.Charset, err)
+		}
+	}
+	if cfg.Prefix != "" && !regexp.MustCompile("^"+segment+"$").MatchString(cfg.Prefix+"x") {
+		return nil, fmt.Errorf("the FQN prefix %q contains characters that are not allowed", cfg.Prefix)
+	}
+
+	fqn, err := regexp.Compile(fmt.Sprintf("(?si)^(?:(%s)%s)?(%s)$", segment, regexp.QuoteMeta(cfg.Separator), segment))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FQN naming scheme: %w", err)
+	}
+
+	return &namingScheme{
+		NamingConfig: cfg,
+		charset:      charset,
+		fqn:          fqn,
+	}, nil
+}
+
+// FQN implements FQNScheme. Dashes, and characters that are not allowed by a custom charset, are replaced with
+// underscores.
+func (s *namingScheme) FQN(namespace, name string) string {
+	return s.Join(s.Prefix+s.sanitize(namespace), s.sanitize(name))
+}
+
+func (s *namingScheme) sanitize(str string) string {
+	if s.charset == nil {
+		return strings.Replace(str, "-", "_", -1)
+	}
+	return s.charset.ReplaceAllString(str, "_")
+}
+
+// Join implements FQNScheme
+func (s *namingScheme) Join(namespace, name string) string {
+	return namespace + s.Separator + name
+}
+
+// Split implements FQNScheme
+func (s *namingScheme) Split(fqn string) (namespace, name string, err error) {
+	if s.MaxLength > 0 && len(fqn) > s.MaxLength {
+		return "", "", fmt.Errorf("invalid FQN %s: longer than %d characters", fqn, s.MaxLength)
+	}
+
+	if m := s.fqn.FindStringSubmatch(fqn); m != nil {
+		namespace, name = m[1], m[2]
+		if namespace == "" || strings.HasPrefix(namespace, s.Prefix) {
+			return namespace, name, nil
+		}
+		if !s.Legacy {
+			return "", "", fmt.Errorf("invalid FQN %s: the namespace must start with %q", fqn, s.Prefix)
+		}
+	}
+
+	// compatibility shim for FQNs of the default scheme
+	if s.Legacy {
+		if m := legacyFQNRegExp.FindStringSubmatch(fqn); m != nil {
+			namespace, name = m[1], m[2]
+			if namespace != "" && !strings.HasPrefix(namespace, s.Prefix) {
+				namespace = s.Prefix + namespace
+			}
+			return namespace, name, nil
+		}
+	}
+	return "", "", fmt.Errorf("invalid FQN: %s", fqn)
+}
+
+var fqnScheme = func() FQNScheme {
+	s, err := NewFQNScheme(NamingConfig{})
+	if err != nil {
+		panic(err)
+	}
+	return s
+}()
+
+// SetFQNScheme sets the naming scheme of the features' FQNs.
+// It should be called once during the initialization, before any resource is reconciled.
+func SetFQNScheme(s FQNScheme) {
+	fqnScheme = s
+	manifests.FQNFormatter = s.FQN
+}
+
+// ValidateFQN returns an error if the FQN doesn't conform to the naming scheme.
+func ValidateFQN(fqn string) error {
+	_, _, err := fqnScheme.Split(fqn)
+	return err
+}
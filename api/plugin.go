@@ -28,7 +28,8 @@ import (
 type Plugins interface {
 	BindConfig | FeatureApply | DataSourceReconcile | StateFactory |
 		CollectNotifierFactory | WriteNotifierFactory |
-		HistoricalWriterFactory
+		HistoricalWriterFactory | DeadLetterQueueFactory | BackfillSourceFactory | AuditSinkFactory |
+		KeyManagerFactory
 }
 
 // BindConfig adds config flags for the plugin.
@@ -44,6 +45,9 @@ type DataSourceReconcileRequest struct {
 	Client         client.Client
 	Scheme         *runtime.Scheme
 	CoreAddress    string
+	// CoreTLSSecret is the name of the Secret (in the DataSource's namespace) with the client certificate for the
+	// Core's gRPC accessor. It's empty when mTLS is disabled.
+	CoreTLSSecret string
 }
 
 // DataSourceReconcile is the interface to be implemented by plugins that want to be reconciled in the operator.
@@ -75,3 +79,16 @@ type CollectNotifierFactory NotifierFactory[CollectNotification]
 type WriteNotifierFactory NotifierFactory[WriteNotification]
 
 type HistoricalWriterFactory func(viper *viper.Viper) (HistoricalWriter, error)
+
+// DeadLetterQueueFactory is the interface to be implemented by plugins that implements a DeadLetterQueue.
+type DeadLetterQueueFactory func(viper *viper.Viper) (DeadLetterQueue, error)
+
+// AuditSinkFactory is the interface to be implemented by plugins that implements an AuditSink.
+type AuditSinkFactory func(viper *viper.Viper) (AuditSink, error)
+
+// KeyManagerFactory is the interface to be implemented by plugins that implements a KeyManager.
+type KeyManagerFactory func(viper *viper.Viper) (KeyManager, error)
+
+// BackfillSourceFactory is the interface to be implemented by plugins that can read a bounded range of historical data
+// for a Backfill. The config is the parsed config of the Backfill's source.
+type BackfillSourceFactory func(ctx context.Context, cfg manifests.ParsedConfig) (BackfillSource, error)
@@ -165,7 +165,8 @@ func ScalarString(val any) string {
 	case bool:
 		return strconv.FormatBool(v)
 	case time.Time:
-		return strconv.FormatInt(v.UnixMicro(), 10)
+		// RFC3339 preserves the zone offset of the timestamp, as opposed to UnixMicro
+		return v.Format(time.RFC3339Nano)
 	default:
 		panic("unreachable")
 	}
@@ -185,11 +186,16 @@ func ScalarFromString(val string, scalar PrimitiveType) (any, error) {
 	case PrimitiveTypeBoolean:
 		return strconv.ParseBool(val)
 	case PrimitiveTypeTimestamp:
+		if t, err := time.Parse(time.RFC3339Nano, val); err == nil {
+			return t, nil
+		}
+
+		// Fallback to values that were stored as UnixMicro (without the zone offset)
 		n, err := strconv.ParseInt(val, 10, 64)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("failed to parse timestamp %q: %w", val, err)
 		}
-		return time.UnixMicro(n), nil
+		return time.UnixMicro(n).UTC(), nil
 	default:
 		panic("unreachable")
 	}
@@ -224,3 +230,91 @@ func NormalizeAny(t any) (any, error) {
 	}
 	return t, nil
 }
+
+// FromJSONValue converts a decoded JSON value (i.e. float64 numbers, RFC3339 timestamps) to the given primitive.
+func FromJSONValue(v any, primitive PrimitiveType) (any, error) {
+	if primitive.Scalar() {
+		return scalarFromJSON(v, primitive)
+	}
+
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a list for primitive `%s`", primitive)
+	}
+	if len(items) == 0 {
+		return primitive.Interface(), nil
+	}
+	ret := make([]any, len(items))
+	for i, item := range items {
+		s, err := scalarFromJSON(item, primitive.Singular())
+		if err != nil {
+			return nil, fmt.Errorf("item #%d: %w", i, err)
+		}
+		ret[i] = s
+	}
+	return NormalizeAny(ret)
+}
+
+func scalarFromJSON(v any, primitive PrimitiveType) (any, error) {
+	switch v.(type) {
+	case string, float64, bool:
+		return ScalarFromString(ScalarString(v), primitive)
+	default:
+		return nil, fmt.Errorf("expected a scalar of type `%s`, got `%T`", primitive, v)
+	}
+}
+
+// TimestampNormalization defines how timestamp values are normalized when they are stored and served.
+type TimestampNormalization int
+
+const (
+	// TimestampNormalizationUTC converts the timestamp values to UTC.
+	TimestampNormalizationUTC TimestampNormalization = iota
+	// TimestampNormalizationOriginal preserves the original zone offset of the timestamp values.
+	// This is useful for transformations that rely on the local time (i.e. local hour-of-day).
+	//
+	// The offset is preserved only in-process (i.e. for the programs and the historical storage): gRPC Timestamps have
+	// no zone, so the values that are served by the accessor (over gRPC or HTTP) are in UTC.
+	TimestampNormalizationOriginal
+)
+
+// StringToTimestampNormalization parses the `timestampNormalization` of a Feature (`utc` or `original`).
+func StringToTimestampNormalization(s string) (TimestampNormalization, error) {
+	switch s {
+	case "", "utc":
+		return TimestampNormalizationUTC, nil
+	case "original":
+		return TimestampNormalizationOriginal, nil
+	default:
+		return TimestampNormalizationUTC, fmt.Errorf("unsupported timestamp normalization: %s", s)
+	}
+}
+
+func (tn TimestampNormalization) String() string {
+	switch tn {
+	case TimestampNormalizationOriginal:
+		return "original"
+	default:
+		return "utc"
+	}
+}
+
+// Normalize applies the normalization on timestamp values (scalars or lists).
+// Values of other types are returned as is.
+func (tn TimestampNormalization) Normalize(val any) any {
+	if tn == TimestampNormalizationOriginal {
+		return val
+	}
+	switch v := val.(type) {
+	case time.Time:
+		return v.UTC()
+	case []time.Time:
+		ret := make([]time.Time, len(v))
+		for i, t := range v {
+			ret[i] = t.UTC()
+		}
+		return ret
+	default:
+		return val
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScalarString_Timestamp(t *testing.T) {
+	ts := time.Date(2022, 10, 1, 12, 0, 0, 500, time.FixedZone("IST", 2*60*60))
+
+	s := ScalarString(ts)
+	if s != "2022-10-01T12:00:00.0000005+02:00" {
+		t.Fatalf("ScalarString() = %q, want RFC3339Nano with the zone offset", s)
+	}
+
+	got, err := ScalarFromString(s, PrimitiveTypeTimestamp)
+	if err != nil {
+		t.Fatalf("ScalarFromString(%q): unexpected error: %v", s, err)
+	}
+	gt := got.(time.Time)
+	if !gt.Equal(ts) {
+		t.Errorf("ScalarFromString(%q) = %s, want %s", s, gt, ts)
+	}
+	if _, off := gt.Zone(); off != 2*60*60 {
+		t.Errorf("ScalarFromString(%q) has an offset of %ds, want %ds", s, off, 2*60*60)
+	}
+}
+
+func TestScalarFromString_UnixMicro(t *testing.T) {
+	ts := time.Date(2022, 10, 1, 10, 0, 0, 5000, time.UTC)
+
+	got, err := ScalarFromString("1664618400000005", PrimitiveTypeTimestamp)
+	if err != nil {
+		t.Fatalf("ScalarFromString(): unexpected error: %v", err)
+	}
+	gt := got.(time.Time)
+	if !gt.Equal(ts) || gt.Location() != time.UTC {
+		t.Errorf("ScalarFromString() = %s, want %s", gt, ts)
+	}
+
+	if _, err := ScalarFromString("yesterday", PrimitiveTypeTimestamp); err == nil {
+		t.Error("ScalarFromString(\"yesterday\"): expected an error")
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Classification is the data classification of the values of a feature.
+type Classification string
+
+const (
+	ClassificationPublic       Classification = "public"
+	ClassificationInternal     Classification = "internal"
+	ClassificationConfidential Classification = "confidential"
+	ClassificationRestricted   Classification = "restricted"
+)
+
+// Masking defines how the values of sensitive features (see FeatureDescriptor.Sensitive) are masked for callers that
+// aren't allowed to read their plain values.
+type Masking string
+
+const (
+	// MaskingHash replaces the values by a keyed hash, so masked values can still be joined or counted.
+	MaskingHash Masking = "hash"
+	// MaskingRedact replaces the values by nil.
+	MaskingRedact Masking = "redact"
+)
+
+// Sensitive checks if the values of the feature are masked for callers that aren't allowed to read their plain values
+// (i.e. PII or confidential features).
+func (fd FeatureDescriptor) Sensitive() bool {
+	return fd.PII || fd.Classification == ClassificationConfidential || fd.Classification == ClassificationRestricted
+}
+
+// PrivacyTags returns the privacy tags of the feature (`pii` and `classification`), which are propagated to the
+// historical storage. It returns nil if the feature has no tags.
+func (fd FeatureDescriptor) PrivacyTags() map[string]string {
+	if !fd.PII && fd.Classification == "" {
+		return nil
+	}
+	ret := map[string]string{"pii": strconv.FormatBool(fd.PII)}
+	if fd.Classification != "" {
+		ret["classification"] = string(fd.Classification)
+	}
+	return ret
+}
+
+// FormatPrivacyTags formats the privacy tags as sorted `key=value` pairs (i.e. `classification=restricted, pii=true`).
+func FormatPrivacyTags(tags map[string]string) string {
+	var ret []string
+	for k, v := range tags {
+		ret = append(ret, k+"="+v)
+	}
+	sort.Strings(ret)
+	return strings.Join(ret, ", ")
+}
+
+func privacyFromManifest(fd *FeatureDescriptor, pii bool, classification, masking string) error {
+	fd.PII = pii
+	fd.Classification = Classification(strings.ToLower(classification))
+	switch fd.Classification {
+	case "", ClassificationPublic, ClassificationInternal, ClassificationConfidential, ClassificationRestricted:
+	default:
+		return fmt.Errorf("unknown classification `%s`", classification)
+	}
+
+	fd.Masking = Masking(strings.ToLower(masking))
+	switch fd.Masking {
+	case "", MaskingHash, MaskingRedact:
+	default:
+		return fmt.Errorf("unknown masking `%s`", masking)
+	}
+	if !fd.Sensitive() {
+		if fd.Masking != "" {
+			return fmt.Errorf("`masking` can be used only with PII, confidential or restricted features")
+		}
+		return nil
+	}
+	if fd.Masking == "" {
+		fd.Masking = MaskingHash
+	}
+	return nil
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.32.0
+// 	protoc-gen-go v1.35.1
 // 	protoc        (unknown)
 // source: core/v1alpha1/api.proto
 
@@ -24,6 +24,62 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// WriteMethod is the method of a write in a transaction.
+type WriteMethod int32
+
+const (
+	WriteMethod_WRITE_METHOD_UNSPECIFIED WriteMethod = 0
+	WriteMethod_WRITE_METHOD_SET         WriteMethod = 1
+	WriteMethod_WRITE_METHOD_APPEND      WriteMethod = 2
+	WriteMethod_WRITE_METHOD_INCR        WriteMethod = 3
+	WriteMethod_WRITE_METHOD_UPDATE      WriteMethod = 4
+)
+
+// Enum value maps for WriteMethod.
+var (
+	WriteMethod_name = map[int32]string{
+		0: "WRITE_METHOD_UNSPECIFIED",
+		1: "WRITE_METHOD_SET",
+		2: "WRITE_METHOD_APPEND",
+		3: "WRITE_METHOD_INCR",
+		4: "WRITE_METHOD_UPDATE",
+	}
+	WriteMethod_value = map[string]int32{
+		"WRITE_METHOD_UNSPECIFIED": 0,
+		"WRITE_METHOD_SET":         1,
+		"WRITE_METHOD_APPEND":      2,
+		"WRITE_METHOD_INCR":        3,
+		"WRITE_METHOD_UPDATE":      4,
+	}
+)
+
+func (x WriteMethod) Enum() *WriteMethod {
+	p := new(WriteMethod)
+	*p = x
+	return p
+}
+
+func (x WriteMethod) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WriteMethod) Descriptor() protoreflect.EnumDescriptor {
+	return file_core_v1alpha1_api_proto_enumTypes[0].Descriptor()
+}
+
+func (WriteMethod) Type() protoreflect.EnumType {
+	return &file_core_v1alpha1_api_proto_enumTypes[0]
+}
+
+func (x WriteMethod) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WriteMethod.Descriptor instead.
+func (WriteMethod) EnumDescriptor() ([]byte, []int) {
+	return file_core_v1alpha1_api_proto_rawDescGZIP(), []int{0}
+}
+
 // GetRequest is the request to get a feature value.
 type GetRequest struct {
 	state         protoimpl.MessageState
@@ -40,11 +96,9 @@ type GetRequest struct {
 
 func (x *GetRequest) Reset() {
 	*x = GetRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_api_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *GetRequest) String() string {
@@ -55,7 +109,7 @@ func (*GetRequest) ProtoMessage() {}
 
 func (x *GetRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_api_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -107,11 +161,9 @@ type GetResponse struct {
 
 func (x *GetResponse) Reset() {
 	*x = GetResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_api_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *GetResponse) String() string {
@@ -122,7 +174,7 @@ func (*GetResponse) ProtoMessage() {}
 
 func (x *GetResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_api_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -172,11 +224,9 @@ type FeatureDescriptorRequest struct {
 
 func (x *FeatureDescriptorRequest) Reset() {
 	*x = FeatureDescriptorRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_api_proto_msgTypes[2]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *FeatureDescriptorRequest) String() string {
@@ -187,7 +237,7 @@ func (*FeatureDescriptorRequest) ProtoMessage() {}
 
 func (x *FeatureDescriptorRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_api_proto_msgTypes[2]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -230,11 +280,9 @@ type FeatureDescriptorResponse struct {
 
 func (x *FeatureDescriptorResponse) Reset() {
 	*x = FeatureDescriptorResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_api_proto_msgTypes[3]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *FeatureDescriptorResponse) String() string {
@@ -245,7 +293,7 @@ func (*FeatureDescriptorResponse) ProtoMessage() {}
 
 func (x *FeatureDescriptorResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_api_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -294,11 +342,9 @@ type SetRequest struct {
 
 func (x *SetRequest) Reset() {
 	*x = SetRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_api_proto_msgTypes[4]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SetRequest) String() string {
@@ -309,7 +355,7 @@ func (*SetRequest) ProtoMessage() {}
 
 func (x *SetRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_api_proto_msgTypes[4]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -373,11 +419,9 @@ type SetResponse struct {
 
 func (x *SetResponse) Reset() {
 	*x = SetResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_api_proto_msgTypes[5]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SetResponse) String() string {
@@ -388,7 +432,7 @@ func (*SetResponse) ProtoMessage() {}
 
 func (x *SetResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_api_proto_msgTypes[5]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -437,11 +481,9 @@ type AppendRequest struct {
 
 func (x *AppendRequest) Reset() {
 	*x = AppendRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_api_proto_msgTypes[6]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *AppendRequest) String() string {
@@ -452,7 +494,7 @@ func (*AppendRequest) ProtoMessage() {}
 
 func (x *AppendRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_api_proto_msgTypes[6]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -516,11 +558,9 @@ type AppendResponse struct {
 
 func (x *AppendResponse) Reset() {
 	*x = AppendResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_api_proto_msgTypes[7]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *AppendResponse) String() string {
@@ -531,7 +571,7 @@ func (*AppendResponse) ProtoMessage() {}
 
 func (x *AppendResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_api_proto_msgTypes[7]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -580,11 +620,9 @@ type IncrRequest struct {
 
 func (x *IncrRequest) Reset() {
 	*x = IncrRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_api_proto_msgTypes[8]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *IncrRequest) String() string {
@@ -595,7 +633,7 @@ func (*IncrRequest) ProtoMessage() {}
 
 func (x *IncrRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_api_proto_msgTypes[8]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -659,11 +697,9 @@ type IncrResponse struct {
 
 func (x *IncrResponse) Reset() {
 	*x = IncrResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_api_proto_msgTypes[9]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *IncrResponse) String() string {
@@ -674,7 +710,7 @@ func (*IncrResponse) ProtoMessage() {}
 
 func (x *IncrResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_api_proto_msgTypes[9]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -723,11 +759,9 @@ type UpdateRequest struct {
 
 func (x *UpdateRequest) Reset() {
 	*x = UpdateRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_api_proto_msgTypes[10]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *UpdateRequest) String() string {
@@ -738,7 +772,7 @@ func (*UpdateRequest) ProtoMessage() {}
 
 func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_api_proto_msgTypes[10]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -802,11 +836,9 @@ type UpdateResponse struct {
 
 func (x *UpdateResponse) Reset() {
 	*x = UpdateResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_api_proto_msgTypes[11]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *UpdateResponse) String() string {
@@ -817,7 +849,7 @@ func (*UpdateResponse) ProtoMessage() {}
 
 func (x *UpdateResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_api_proto_msgTypes[11]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -846,6 +878,210 @@ func (x *UpdateResponse) GetTimestamp() *timestamppb.Timestamp {
 	return nil
 }
 
+// TransactionWrite is a write of a feature value in a transaction.
+type TransactionWrite struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Method of the write
+	Method WriteMethod `protobuf:"varint,1,opt,name=method,proto3,enum=core.v1alpha1.WriteMethod" json:"method,omitempty"`
+	// Selector of the feature
+	Selector string `protobuf:"bytes,2,opt,name=selector,proto3" json:"selector,omitempty"`
+	// Keys of the feature
+	Keys map[string]string `protobuf:"bytes,3,rep,name=keys,proto3" json:"keys,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Value to write
+	Value *Value `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	// Timestamp of the update
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Idempotency key of the write (i.e. the event's UUID), for deduplication
+	EventId string `protobuf:"bytes,6,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+}
+
+func (x *TransactionWrite) Reset() {
+	*x = TransactionWrite{}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionWrite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionWrite) ProtoMessage() {}
+
+func (x *TransactionWrite) ProtoReflect() protoreflect.Message {
+	mi := &file_core_v1alpha1_api_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionWrite.ProtoReflect.Descriptor instead.
+func (*TransactionWrite) Descriptor() ([]byte, []int) {
+	return file_core_v1alpha1_api_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TransactionWrite) GetMethod() WriteMethod {
+	if x != nil {
+		return x.Method
+	}
+	return WriteMethod_WRITE_METHOD_UNSPECIFIED
+}
+
+func (x *TransactionWrite) GetSelector() string {
+	if x != nil {
+		return x.Selector
+	}
+	return ""
+}
+
+func (x *TransactionWrite) GetKeys() map[string]string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *TransactionWrite) GetValue() *Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *TransactionWrite) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *TransactionWrite) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+// TransactionRequest is the request to write several feature values as a transaction.
+type TransactionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// UUID of the request
+	Uuid string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	// Writes of the transaction
+	Writes []*TransactionWrite `protobuf:"bytes,2,rep,name=writes,proto3" json:"writes,omitempty"`
+}
+
+func (x *TransactionRequest) Reset() {
+	*x = TransactionRequest{}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionRequest) ProtoMessage() {}
+
+func (x *TransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_core_v1alpha1_api_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionRequest.ProtoReflect.Descriptor instead.
+func (*TransactionRequest) Descriptor() ([]byte, []int) {
+	return file_core_v1alpha1_api_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *TransactionRequest) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *TransactionRequest) GetWrites() []*TransactionWrite {
+	if x != nil {
+		return x.Writes
+	}
+	return nil
+}
+
+// TransactionResponse is the response to write several feature values as a transaction.
+type TransactionResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// UUID corresponding to the request
+	Uuid string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	// Timestamp of the transaction
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+func (x *TransactionResponse) Reset() {
+	*x = TransactionResponse{}
+	mi := &file_core_v1alpha1_api_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionResponse) ProtoMessage() {}
+
+func (x *TransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_core_v1alpha1_api_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionResponse.ProtoReflect.Descriptor instead.
+func (*TransactionResponse) Descriptor() ([]byte, []int) {
+	return file_core_v1alpha1_api_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TransactionResponse) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
 var File_core_v1alpha1_api_proto protoreflect.FileDescriptor
 
 var file_core_v1alpha1_api_proto_rawDesc = []byte{
@@ -1035,68 +1271,114 @@ var file_core_v1alpha1_api_proto_rawDesc = []byte{
 	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
 	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
 	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x32, 0xcb, 0x04, 0x0a, 0x0d, 0x45, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x53, 0x65,
-	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x83, 0x01, 0x0a, 0x11, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72,
-	0x65, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x12, 0x27, 0x2e, 0x63, 0x6f,
-	0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x46, 0x65, 0x61, 0x74,
-	0x75, 0x72, 0x65, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c,
+	0x61, 0x6d, 0x70, 0x22, 0x93, 0x03, 0x0a, 0x10, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x57, 0x72, 0x69, 0x74, 0x65, 0x12, 0x3c, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68,
+	0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e,
+	0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x57, 0x72, 0x69, 0x74, 0x65, 0x4d, 0x65,
+	0x74, 0x68, 0x6f, 0x64, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x82, 0x01, 0x02, 0x10, 0x01, 0x52, 0x06,
+	0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x48, 0x0a, 0x08, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x2c, 0xfa, 0x42, 0x29, 0x72, 0x27, 0x32,
+	0x25, 0x28, 0x69, 0x3f, 0x29, 0x5e, 0x28, 0x5b, 0x61, 0x30, 0x2d, 0x7a, 0x39, 0x5c, 0x2d, 0x5c,
+	0x2e, 0x5d, 0x2a, 0x29, 0x28, 0x5c, 0x5b, 0x28, 0x5b, 0x61, 0x30, 0x2d, 0x7a, 0x39, 0x5d, 0x29,
+	0x2a, 0x5c, 0x5d, 0x29, 0x3f, 0x24, 0x52, 0x08, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x12, 0x3d, 0x0a, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29,
+	0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x57, 0x72, 0x69, 0x74, 0x65, 0x2e,
+	0x4b, 0x65, 0x79, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x12,
+	0x2a, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14,
+	0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x38, 0x0a, 0x09, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x19, 0x0a, 0x08, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x69,
+	0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x49, 0x64,
+	0x1a, 0x37, 0x0a, 0x09, 0x4b, 0x65, 0x79, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x75, 0x0a, 0x12, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1c, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa,
+	0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x04, 0x75, 0x75, 0x69, 0x64, 0x12, 0x41, 0x0a,
+	0x06, 0x77, 0x72, 0x69, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e,
+	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x57, 0x72, 0x69, 0x74, 0x65, 0x42, 0x08,
+	0xfa, 0x42, 0x05, 0x92, 0x01, 0x02, 0x08, 0x01, 0x52, 0x06, 0x77, 0x72, 0x69, 0x74, 0x65, 0x73,
+	0x22, 0x6d, 0x0a, 0x13, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52,
+	0x04, 0x75, 0x75, 0x69, 0x64, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2a,
+	0x8a, 0x01, 0x0a, 0x0b, 0x57, 0x72, 0x69, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12,
+	0x1c, 0x0a, 0x18, 0x57, 0x52, 0x49, 0x54, 0x45, 0x5f, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f,
+	0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x14, 0x0a,
+	0x10, 0x57, 0x52, 0x49, 0x54, 0x45, 0x5f, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x53, 0x45,
+	0x54, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x57, 0x52, 0x49, 0x54, 0x45, 0x5f, 0x4d, 0x45, 0x54,
+	0x48, 0x4f, 0x44, 0x5f, 0x41, 0x50, 0x50, 0x45, 0x4e, 0x44, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11,
+	0x57, 0x52, 0x49, 0x54, 0x45, 0x5f, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x49, 0x4e, 0x43,
+	0x52, 0x10, 0x03, 0x12, 0x17, 0x0a, 0x13, 0x57, 0x52, 0x49, 0x54, 0x45, 0x5f, 0x4d, 0x45, 0x54,
+	0x48, 0x4f, 0x44, 0x5f, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x10, 0x04, 0x32, 0xa3, 0x05, 0x0a,
+	0x0d, 0x45, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x83,
+	0x01, 0x0a, 0x11, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69,
+	0x70, 0x74, 0x6f, 0x72, 0x12, 0x27, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c,
 	0x70, 0x68, 0x61, 0x31, 0x2e, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x44, 0x65, 0x73, 0x63,
-	0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b,
-	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x15, 0x42, 0x13, 0x0a, 0x04, 0x48, 0x45, 0x41, 0x44, 0x12, 0x0b,
-	0x2f, 0x7b, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x7d, 0x12, 0x51, 0x0a, 0x03, 0x47,
-	0x65, 0x74, 0x12, 0x19, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68,
-	0x61, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e,
-	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x47, 0x65,
-	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x13, 0x82, 0xd3, 0xe4, 0x93, 0x02,
-	0x0d, 0x12, 0x0b, 0x2f, 0x7b, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x7d, 0x12, 0x51,
-	0x0a, 0x03, 0x53, 0x65, 0x74, 0x12, 0x19, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61,
-	0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x1a, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31,
-	0x2e, 0x53, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x13, 0x82, 0xd3,
-	0xe4, 0x93, 0x02, 0x0d, 0x1a, 0x0b, 0x2f, 0x7b, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72,
-	0x7d, 0x12, 0x5c, 0x0a, 0x06, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x12, 0x1c, 0x2e, 0x63, 0x6f,
-	0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x65,
-	0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x63, 0x6f, 0x72, 0x65,
-	0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x15, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0f,
-	0x22, 0x0d, 0x2f, 0x7b, 0x66, 0x71, 0x6e, 0x7d, 0x2f, 0x61, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x12,
-	0x54, 0x0a, 0x04, 0x49, 0x6e, 0x63, 0x72, 0x12, 0x1a, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76,
-	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x49, 0x6e, 0x63, 0x72, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70,
-	0x68, 0x61, 0x31, 0x2e, 0x49, 0x6e, 0x63, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x22, 0x13, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0d, 0x22, 0x0b, 0x2f, 0x7b, 0x66, 0x71, 0x6e, 0x7d,
-	0x2f, 0x69, 0x6e, 0x63, 0x72, 0x12, 0x5a, 0x0a, 0x06, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12,
-	0x1c, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e,
-	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e,
-	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x55, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x13, 0x82, 0xd3,
-	0xe4, 0x93, 0x02, 0x0d, 0x22, 0x0b, 0x2f, 0x7b, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72,
-	0x7d, 0x42, 0xf5, 0x02, 0x92, 0x41, 0xb6, 0x01, 0x12, 0x5b, 0x0a, 0x08, 0x43, 0x6f, 0x72, 0x65,
-	0x20, 0x41, 0x50, 0x49, 0x12, 0x4f, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x73, 0x20, 0x61,
-	0x63, 0x63, 0x65, 0x73, 0x73, 0x20, 0x6c, 0x6f, 0x77, 0x2d, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x20,
-	0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x20, 0x6f, 0x76, 0x65, 0x72, 0x20,
-	0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x20, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x20, 0x61,
-	0x6e, 0x64, 0x20, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x20, 0x70, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74,
-	0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x1a, 0x27, 0x72, 0x61, 0x70, 0x74, 0x6f, 0x72, 0x2d, 0x63, 0x6f,
-	0x72, 0x65, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x72, 0x61, 0x70, 0x74, 0x6f,
-	0x72, 0x2d, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x3a, 0x36, 0x30, 0x30, 0x30, 0x31, 0x2a, 0x01,
-	0x01, 0x72, 0x2b, 0x0a, 0x16, 0x4f, 0x66, 0x66, 0x69, 0x63, 0x69, 0x61, 0x6c, 0x20, 0x64, 0x6f,
-	0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x11, 0x68, 0x74, 0x74,
-	0x70, 0x73, 0x3a, 0x2f, 0x2f, 0x72, 0x61, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x6d, 0x6c, 0x0a, 0x11,
-	0x63, 0x6f, 0x6d, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61,
-	0x31, 0x42, 0x08, 0x41, 0x70, 0x69, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x47, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x61, 0x70, 0x74, 0x6f, 0x72,
-	0x2d, 0x6d, 0x6c, 0x2f, 0x72, 0x61, 0x70, 0x74, 0x6f, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x67, 0x6f, 0x2f, 0x63, 0x6f, 0x72, 0x65,
-	0x2f, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x3b, 0x63, 0x6f, 0x72, 0x65, 0x76, 0x31,
-	0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0xa2, 0x02, 0x03, 0x43, 0x58, 0x58, 0xaa, 0x02, 0x0d, 0x43,
-	0x6f, 0x72, 0x65, 0x2e, 0x56, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0xca, 0x02, 0x0d, 0x43,
-	0x6f, 0x72, 0x65, 0x5c, 0x56, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0xe2, 0x02, 0x19, 0x43,
-	0x6f, 0x72, 0x65, 0x5c, 0x56, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x5c, 0x47, 0x50, 0x42,
-	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea, 0x02, 0x0e, 0x43, 0x6f, 0x72, 0x65, 0x3a,
-	0x3a, 0x56, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e,
+	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x46, 0x65,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x15, 0x42,
+	0x13, 0x0a, 0x04, 0x48, 0x45, 0x41, 0x44, 0x12, 0x0b, 0x2f, 0x7b, 0x73, 0x65, 0x6c, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x7d, 0x12, 0x51, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x19, 0x2e, 0x63, 0x6f,
+	0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31,
+	0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x13, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0d, 0x12, 0x0b, 0x2f, 0x7b, 0x73, 0x65,
+	0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x7d, 0x12, 0x51, 0x0a, 0x03, 0x53, 0x65, 0x74, 0x12, 0x19,
+	0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53,
+	0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x63, 0x6f, 0x72, 0x65,
+	0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x65, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x13, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0d, 0x1a, 0x0b, 0x2f,
+	0x7b, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x7d, 0x12, 0x5c, 0x0a, 0x06, 0x41, 0x70,
+	0x70, 0x65, 0x6e, 0x64, 0x12, 0x1c, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c,
+	0x70, 0x68, 0x61, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68,
+	0x61, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x15, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0f, 0x22, 0x0d, 0x2f, 0x7b, 0x66, 0x71, 0x6e,
+	0x7d, 0x2f, 0x61, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x12, 0x54, 0x0a, 0x04, 0x49, 0x6e, 0x63, 0x72,
+	0x12, 0x1a, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31,
+	0x2e, 0x49, 0x6e, 0x63, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x63,
+	0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x49, 0x6e, 0x63,
+	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x13, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x0d, 0x22, 0x0b, 0x2f, 0x7b, 0x66, 0x71, 0x6e, 0x7d, 0x2f, 0x69, 0x6e, 0x63, 0x72, 0x12, 0x5a,
+	0x0a, 0x06, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x1c, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e,
+	0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31,
+	0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x13, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0d, 0x22, 0x0b, 0x2f,
+	0x7b, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x7d, 0x12, 0x56, 0x0a, 0x0b, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x2e, 0x63, 0x6f, 0x72, 0x65,
+	0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x63,
+	0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x42, 0x83, 0x02, 0x92, 0x41, 0xb6, 0x01, 0x12, 0x5b, 0x0a, 0x08, 0x43, 0x6f, 0x72,
+	0x65, 0x20, 0x41, 0x50, 0x49, 0x12, 0x4f, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x73, 0x20,
+	0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x20, 0x6c, 0x6f, 0x77, 0x2d, 0x6c, 0x65, 0x76, 0x65, 0x6c,
+	0x20, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x20, 0x6f, 0x76, 0x65, 0x72,
+	0x20, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x20, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x20,
+	0x61, 0x6e, 0x64, 0x20, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x20, 0x70, 0x72, 0x65, 0x64, 0x69, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x1a, 0x27, 0x72, 0x61, 0x70, 0x74, 0x6f, 0x72, 0x2d, 0x63,
+	0x6f, 0x72, 0x65, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x72, 0x61, 0x70, 0x74,
+	0x6f, 0x72, 0x2d, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x3a, 0x36, 0x30, 0x30, 0x30, 0x31, 0x2a,
+	0x01, 0x01, 0x72, 0x2b, 0x0a, 0x16, 0x4f, 0x66, 0x66, 0x69, 0x63, 0x69, 0x61, 0x6c, 0x20, 0x64,
+	0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x11, 0x68, 0x74,
+	0x74, 0x70, 0x73, 0x3a, 0x2f, 0x2f, 0x72, 0x61, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x6d, 0x6c, 0x5a,
+	0x47, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x61, 0x70, 0x74,
+	0x6f, 0x72, 0x2d, 0x6d, 0x6c, 0x2f, 0x72, 0x61, 0x70, 0x74, 0x6f, 0x72, 0x2f, 0x61, 0x70, 0x69,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x67, 0x6f, 0x2f, 0x63, 0x6f,
+	0x72, 0x65, 0x2f, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x3b, 0x63, 0x6f, 0x72, 0x65,
+	0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1111,69 +1393,83 @@ func file_core_v1alpha1_api_proto_rawDescGZIP() []byte {
 	return file_core_v1alpha1_api_proto_rawDescData
 }
 
-var file_core_v1alpha1_api_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
-var file_core_v1alpha1_api_proto_goTypes = []interface{}{
-	(*GetRequest)(nil),                // 0: core.v1alpha1.GetRequest
-	(*GetResponse)(nil),               // 1: core.v1alpha1.GetResponse
-	(*FeatureDescriptorRequest)(nil),  // 2: core.v1alpha1.FeatureDescriptorRequest
-	(*FeatureDescriptorResponse)(nil), // 3: core.v1alpha1.FeatureDescriptorResponse
-	(*SetRequest)(nil),                // 4: core.v1alpha1.SetRequest
-	(*SetResponse)(nil),               // 5: core.v1alpha1.SetResponse
-	(*AppendRequest)(nil),             // 6: core.v1alpha1.AppendRequest
-	(*AppendResponse)(nil),            // 7: core.v1alpha1.AppendResponse
-	(*IncrRequest)(nil),               // 8: core.v1alpha1.IncrRequest
-	(*IncrResponse)(nil),              // 9: core.v1alpha1.IncrResponse
-	(*UpdateRequest)(nil),             // 10: core.v1alpha1.UpdateRequest
-	(*UpdateResponse)(nil),            // 11: core.v1alpha1.UpdateResponse
-	nil,                               // 12: core.v1alpha1.GetRequest.KeysEntry
-	nil,                               // 13: core.v1alpha1.SetRequest.KeysEntry
-	nil,                               // 14: core.v1alpha1.AppendRequest.KeysEntry
-	nil,                               // 15: core.v1alpha1.IncrRequest.KeysEntry
-	nil,                               // 16: core.v1alpha1.UpdateRequest.KeysEntry
-	(*FeatureValue)(nil),              // 17: core.v1alpha1.FeatureValue
-	(*FeatureDescriptor)(nil),         // 18: core.v1alpha1.FeatureDescriptor
-	(*Value)(nil),                     // 19: core.v1alpha1.Value
-	(*timestamppb.Timestamp)(nil),     // 20: google.protobuf.Timestamp
-	(*Scalar)(nil),                    // 21: core.v1alpha1.Scalar
+var file_core_v1alpha1_api_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_core_v1alpha1_api_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_core_v1alpha1_api_proto_goTypes = []any{
+	(WriteMethod)(0),                  // 0: core.v1alpha1.WriteMethod
+	(*GetRequest)(nil),                // 1: core.v1alpha1.GetRequest
+	(*GetResponse)(nil),               // 2: core.v1alpha1.GetResponse
+	(*FeatureDescriptorRequest)(nil),  // 3: core.v1alpha1.FeatureDescriptorRequest
+	(*FeatureDescriptorResponse)(nil), // 4: core.v1alpha1.FeatureDescriptorResponse
+	(*SetRequest)(nil),                // 5: core.v1alpha1.SetRequest
+	(*SetResponse)(nil),               // 6: core.v1alpha1.SetResponse
+	(*AppendRequest)(nil),             // 7: core.v1alpha1.AppendRequest
+	(*AppendResponse)(nil),            // 8: core.v1alpha1.AppendResponse
+	(*IncrRequest)(nil),               // 9: core.v1alpha1.IncrRequest
+	(*IncrResponse)(nil),              // 10: core.v1alpha1.IncrResponse
+	(*UpdateRequest)(nil),             // 11: core.v1alpha1.UpdateRequest
+	(*UpdateResponse)(nil),            // 12: core.v1alpha1.UpdateResponse
+	(*TransactionWrite)(nil),          // 13: core.v1alpha1.TransactionWrite
+	(*TransactionRequest)(nil),        // 14: core.v1alpha1.TransactionRequest
+	(*TransactionResponse)(nil),       // 15: core.v1alpha1.TransactionResponse
+	nil,                               // 16: core.v1alpha1.GetRequest.KeysEntry
+	nil,                               // 17: core.v1alpha1.SetRequest.KeysEntry
+	nil,                               // 18: core.v1alpha1.AppendRequest.KeysEntry
+	nil,                               // 19: core.v1alpha1.IncrRequest.KeysEntry
+	nil,                               // 20: core.v1alpha1.UpdateRequest.KeysEntry
+	nil,                               // 21: core.v1alpha1.TransactionWrite.KeysEntry
+	(*FeatureValue)(nil),              // 22: core.v1alpha1.FeatureValue
+	(*FeatureDescriptor)(nil),         // 23: core.v1alpha1.FeatureDescriptor
+	(*Value)(nil),                     // 24: core.v1alpha1.Value
+	(*timestamppb.Timestamp)(nil),     // 25: google.protobuf.Timestamp
+	(*Scalar)(nil),                    // 26: core.v1alpha1.Scalar
 }
 var file_core_v1alpha1_api_proto_depIdxs = []int32{
-	12, // 0: core.v1alpha1.GetRequest.keys:type_name -> core.v1alpha1.GetRequest.KeysEntry
-	17, // 1: core.v1alpha1.GetResponse.value:type_name -> core.v1alpha1.FeatureValue
-	18, // 2: core.v1alpha1.GetResponse.feature_descriptor:type_name -> core.v1alpha1.FeatureDescriptor
-	18, // 3: core.v1alpha1.FeatureDescriptorResponse.feature_descriptor:type_name -> core.v1alpha1.FeatureDescriptor
-	13, // 4: core.v1alpha1.SetRequest.keys:type_name -> core.v1alpha1.SetRequest.KeysEntry
-	19, // 5: core.v1alpha1.SetRequest.value:type_name -> core.v1alpha1.Value
-	20, // 6: core.v1alpha1.SetRequest.timestamp:type_name -> google.protobuf.Timestamp
-	20, // 7: core.v1alpha1.SetResponse.timestamp:type_name -> google.protobuf.Timestamp
-	14, // 8: core.v1alpha1.AppendRequest.keys:type_name -> core.v1alpha1.AppendRequest.KeysEntry
-	21, // 9: core.v1alpha1.AppendRequest.value:type_name -> core.v1alpha1.Scalar
-	20, // 10: core.v1alpha1.AppendRequest.timestamp:type_name -> google.protobuf.Timestamp
-	20, // 11: core.v1alpha1.AppendResponse.timestamp:type_name -> google.protobuf.Timestamp
-	15, // 12: core.v1alpha1.IncrRequest.keys:type_name -> core.v1alpha1.IncrRequest.KeysEntry
-	21, // 13: core.v1alpha1.IncrRequest.value:type_name -> core.v1alpha1.Scalar
-	20, // 14: core.v1alpha1.IncrRequest.timestamp:type_name -> google.protobuf.Timestamp
-	20, // 15: core.v1alpha1.IncrResponse.timestamp:type_name -> google.protobuf.Timestamp
-	16, // 16: core.v1alpha1.UpdateRequest.keys:type_name -> core.v1alpha1.UpdateRequest.KeysEntry
-	19, // 17: core.v1alpha1.UpdateRequest.value:type_name -> core.v1alpha1.Value
-	20, // 18: core.v1alpha1.UpdateRequest.timestamp:type_name -> google.protobuf.Timestamp
-	20, // 19: core.v1alpha1.UpdateResponse.timestamp:type_name -> google.protobuf.Timestamp
-	2,  // 20: core.v1alpha1.EngineService.FeatureDescriptor:input_type -> core.v1alpha1.FeatureDescriptorRequest
-	0,  // 21: core.v1alpha1.EngineService.Get:input_type -> core.v1alpha1.GetRequest
-	4,  // 22: core.v1alpha1.EngineService.Set:input_type -> core.v1alpha1.SetRequest
-	6,  // 23: core.v1alpha1.EngineService.Append:input_type -> core.v1alpha1.AppendRequest
-	8,  // 24: core.v1alpha1.EngineService.Incr:input_type -> core.v1alpha1.IncrRequest
-	10, // 25: core.v1alpha1.EngineService.Update:input_type -> core.v1alpha1.UpdateRequest
-	3,  // 26: core.v1alpha1.EngineService.FeatureDescriptor:output_type -> core.v1alpha1.FeatureDescriptorResponse
-	1,  // 27: core.v1alpha1.EngineService.Get:output_type -> core.v1alpha1.GetResponse
-	5,  // 28: core.v1alpha1.EngineService.Set:output_type -> core.v1alpha1.SetResponse
-	7,  // 29: core.v1alpha1.EngineService.Append:output_type -> core.v1alpha1.AppendResponse
-	9,  // 30: core.v1alpha1.EngineService.Incr:output_type -> core.v1alpha1.IncrResponse
-	11, // 31: core.v1alpha1.EngineService.Update:output_type -> core.v1alpha1.UpdateResponse
-	26, // [26:32] is the sub-list for method output_type
-	20, // [20:26] is the sub-list for method input_type
-	20, // [20:20] is the sub-list for extension type_name
-	20, // [20:20] is the sub-list for extension extendee
-	0,  // [0:20] is the sub-list for field type_name
+	16, // 0: core.v1alpha1.GetRequest.keys:type_name -> core.v1alpha1.GetRequest.KeysEntry
+	22, // 1: core.v1alpha1.GetResponse.value:type_name -> core.v1alpha1.FeatureValue
+	23, // 2: core.v1alpha1.GetResponse.feature_descriptor:type_name -> core.v1alpha1.FeatureDescriptor
+	23, // 3: core.v1alpha1.FeatureDescriptorResponse.feature_descriptor:type_name -> core.v1alpha1.FeatureDescriptor
+	17, // 4: core.v1alpha1.SetRequest.keys:type_name -> core.v1alpha1.SetRequest.KeysEntry
+	24, // 5: core.v1alpha1.SetRequest.value:type_name -> core.v1alpha1.Value
+	25, // 6: core.v1alpha1.SetRequest.timestamp:type_name -> google.protobuf.Timestamp
+	25, // 7: core.v1alpha1.SetResponse.timestamp:type_name -> google.protobuf.Timestamp
+	18, // 8: core.v1alpha1.AppendRequest.keys:type_name -> core.v1alpha1.AppendRequest.KeysEntry
+	26, // 9: core.v1alpha1.AppendRequest.value:type_name -> core.v1alpha1.Scalar
+	25, // 10: core.v1alpha1.AppendRequest.timestamp:type_name -> google.protobuf.Timestamp
+	25, // 11: core.v1alpha1.AppendResponse.timestamp:type_name -> google.protobuf.Timestamp
+	19, // 12: core.v1alpha1.IncrRequest.keys:type_name -> core.v1alpha1.IncrRequest.KeysEntry
+	26, // 13: core.v1alpha1.IncrRequest.value:type_name -> core.v1alpha1.Scalar
+	25, // 14: core.v1alpha1.IncrRequest.timestamp:type_name -> google.protobuf.Timestamp
+	25, // 15: core.v1alpha1.IncrResponse.timestamp:type_name -> google.protobuf.Timestamp
+	20, // 16: core.v1alpha1.UpdateRequest.keys:type_name -> core.v1alpha1.UpdateRequest.KeysEntry
+	24, // 17: core.v1alpha1.UpdateRequest.value:type_name -> core.v1alpha1.Value
+	25, // 18: core.v1alpha1.UpdateRequest.timestamp:type_name -> google.protobuf.Timestamp
+	25, // 19: core.v1alpha1.UpdateResponse.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 20: core.v1alpha1.TransactionWrite.method:type_name -> core.v1alpha1.WriteMethod
+	21, // 21: core.v1alpha1.TransactionWrite.keys:type_name -> core.v1alpha1.TransactionWrite.KeysEntry
+	24, // 22: core.v1alpha1.TransactionWrite.value:type_name -> core.v1alpha1.Value
+	25, // 23: core.v1alpha1.TransactionWrite.timestamp:type_name -> google.protobuf.Timestamp
+	13, // 24: core.v1alpha1.TransactionRequest.writes:type_name -> core.v1alpha1.TransactionWrite
+	25, // 25: core.v1alpha1.TransactionResponse.timestamp:type_name -> google.protobuf.Timestamp
+	3,  // 26: core.v1alpha1.EngineService.FeatureDescriptor:input_type -> core.v1alpha1.FeatureDescriptorRequest
+	1,  // 27: core.v1alpha1.EngineService.Get:input_type -> core.v1alpha1.GetRequest
+	5,  // 28: core.v1alpha1.EngineService.Set:input_type -> core.v1alpha1.SetRequest
+	7,  // 29: core.v1alpha1.EngineService.Append:input_type -> core.v1alpha1.AppendRequest
+	9,  // 30: core.v1alpha1.EngineService.Incr:input_type -> core.v1alpha1.IncrRequest
+	11, // 31: core.v1alpha1.EngineService.Update:input_type -> core.v1alpha1.UpdateRequest
+	14, // 32: core.v1alpha1.EngineService.Transaction:input_type -> core.v1alpha1.TransactionRequest
+	4,  // 33: core.v1alpha1.EngineService.FeatureDescriptor:output_type -> core.v1alpha1.FeatureDescriptorResponse
+	2,  // 34: core.v1alpha1.EngineService.Get:output_type -> core.v1alpha1.GetResponse
+	6,  // 35: core.v1alpha1.EngineService.Set:output_type -> core.v1alpha1.SetResponse
+	8,  // 36: core.v1alpha1.EngineService.Append:output_type -> core.v1alpha1.AppendResponse
+	10, // 37: core.v1alpha1.EngineService.Incr:output_type -> core.v1alpha1.IncrResponse
+	12, // 38: core.v1alpha1.EngineService.Update:output_type -> core.v1alpha1.UpdateResponse
+	15, // 39: core.v1alpha1.EngineService.Transaction:output_type -> core.v1alpha1.TransactionResponse
+	33, // [33:40] is the sub-list for method output_type
+	26, // [26:33] is the sub-list for method input_type
+	26, // [26:26] is the sub-list for extension type_name
+	26, // [26:26] is the sub-list for extension extendee
+	0,  // [0:26] is the sub-list for field type_name
 }
 
 func init() { file_core_v1alpha1_api_proto_init() }
@@ -1182,164 +1478,19 @@ func file_core_v1alpha1_api_proto_init() {
 		return
 	}
 	file_core_v1alpha1_types_proto_init()
-	if !protoimpl.UnsafeEnabled {
-		file_core_v1alpha1_api_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_api_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_api_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FeatureDescriptorRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_api_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FeatureDescriptorResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_api_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_api_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_api_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AppendRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_api_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AppendResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_api_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*IncrRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_api_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*IncrResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_api_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_api_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_core_v1alpha1_api_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   17,
+			NumEnums:      1,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_core_v1alpha1_api_proto_goTypes,
 		DependencyIndexes: file_core_v1alpha1_api_proto_depIdxs,
+		EnumInfos:         file_core_v1alpha1_api_proto_enumTypes,
 		MessageInfos:      file_core_v1alpha1_api_proto_msgTypes,
 	}.Build()
 	File_core_v1alpha1_api_proto = out.File
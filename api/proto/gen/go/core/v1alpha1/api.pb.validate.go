@@ -1998,3 +1998,507 @@ var _ interface {
 	Cause() error
 	ErrorName() string
 } = UpdateResponseValidationError{}
+
+// Validate checks the field values on TransactionWrite with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *TransactionWrite) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on TransactionWrite with the rules
+// defined in the proto definition for this message. If any rules are violated,
+// the result is a list of violation errors wrapped in
+// TransactionWriteMultiError, or nil if none found.
+func (m *TransactionWrite) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *TransactionWrite) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, ok := WriteMethod_name[int32(m.GetMethod())]; !ok {
+		err := TransactionWriteValidationError{
+			field:  "Method",
+			reason: "value must be one of the defined enum values",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if !_TransactionWrite_Selector_Pattern.MatchString(m.GetSelector()) {
+		err := TransactionWriteValidationError{
+			field:  "Selector",
+			reason: "value does not match regex pattern \"(i?)^([a0-z9\\\\-\\\\.]*)(\\\\[([a0-z9])*\\\\])?$\"",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for Keys
+
+	if all {
+		switch v := interface{}(m.GetValue()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, TransactionWriteValidationError{
+					field:  "Value",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, TransactionWriteValidationError{
+					field:  "Value",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetValue()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return TransactionWriteValidationError{
+				field:  "Value",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetTimestamp()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, TransactionWriteValidationError{
+					field:  "Timestamp",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, TransactionWriteValidationError{
+					field:  "Timestamp",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetTimestamp()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return TransactionWriteValidationError{
+				field:  "Timestamp",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for EventId
+
+	if len(errors) > 0 {
+		return TransactionWriteMultiError(errors)
+	}
+
+	return nil
+}
+
+// TransactionWriteMultiError is an error wrapping multiple validation errors
+// returned by TransactionWrite.ValidateAll() if the designated constraints
+// aren't met.
+type TransactionWriteMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m TransactionWriteMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m TransactionWriteMultiError) AllErrors() []error { return m }
+
+// TransactionWriteValidationError is the validation error returned by
+// TransactionWrite.Validate if the designated constraints aren't met.
+type TransactionWriteValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e TransactionWriteValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e TransactionWriteValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e TransactionWriteValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e TransactionWriteValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e TransactionWriteValidationError) ErrorName() string { return "TransactionWriteValidationError" }
+
+// Error satisfies the builtin error interface
+func (e TransactionWriteValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sTransactionWrite.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = TransactionWriteValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = TransactionWriteValidationError{}
+
+var _TransactionWrite_Selector_Pattern = regexp.MustCompile("(i?)^([a0-z9\\-\\.]*)(\\[([a0-z9])*\\])?$")
+
+// Validate checks the field values on TransactionRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *TransactionRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on TransactionRequest with the rules
+// defined in the proto definition for this message. If any rules are violated,
+// the result is a list of violation errors wrapped in
+// TransactionRequestMultiError, or nil if none found.
+func (m *TransactionRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *TransactionRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetUuid()); err != nil {
+		err = TransactionRequestValidationError{
+			field:  "Uuid",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(m.GetWrites()) < 1 {
+		err := TransactionRequestValidationError{
+			field:  "Writes",
+			reason: "value must contain at least 1 item(s)",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	for idx, item := range m.GetWrites() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, TransactionRequestValidationError{
+						field:  fmt.Sprintf("Writes[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, TransactionRequestValidationError{
+						field:  fmt.Sprintf("Writes[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return TransactionRequestValidationError{
+					field:  fmt.Sprintf("Writes[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return TransactionRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *TransactionRequest) _validateUuid(uuid string) error {
+	if matched := _api_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// TransactionRequestMultiError is an error wrapping multiple validation errors
+// returned by TransactionRequest.ValidateAll() if the designated constraints
+// aren't met.
+type TransactionRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m TransactionRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m TransactionRequestMultiError) AllErrors() []error { return m }
+
+// TransactionRequestValidationError is the validation error returned by
+// TransactionRequest.Validate if the designated constraints aren't met.
+type TransactionRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e TransactionRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e TransactionRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e TransactionRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e TransactionRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e TransactionRequestValidationError) ErrorName() string {
+	return "TransactionRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e TransactionRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sTransactionRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = TransactionRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = TransactionRequestValidationError{}
+
+// Validate checks the field values on TransactionResponse with the rules
+// defined in the proto definition for this message. If any rules are violated,
+// the first error encountered is returned, or nil if there are no violations.
+func (m *TransactionResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on TransactionResponse with the rules
+// defined in the proto definition for this message. If any rules are violated,
+// the result is a list of violation errors wrapped in
+// TransactionResponseMultiError, or nil if none found.
+func (m *TransactionResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *TransactionResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetUuid()); err != nil {
+		err = TransactionResponseValidationError{
+			field:  "Uuid",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if all {
+		switch v := interface{}(m.GetTimestamp()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, TransactionResponseValidationError{
+					field:  "Timestamp",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, TransactionResponseValidationError{
+					field:  "Timestamp",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetTimestamp()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return TransactionResponseValidationError{
+				field:  "Timestamp",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return TransactionResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *TransactionResponse) _validateUuid(uuid string) error {
+	if matched := _api_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// TransactionResponseMultiError is an error wrapping multiple validation errors
+// returned by TransactionResponse.ValidateAll() if the designated constraints
+// aren't met.
+type TransactionResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m TransactionResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m TransactionResponseMultiError) AllErrors() []error { return m }
+
+// TransactionResponseValidationError is the validation error returned by
+// TransactionResponse.Validate if the designated constraints aren't met.
+type TransactionResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e TransactionResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e TransactionResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e TransactionResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e TransactionResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e TransactionResponseValidationError) ErrorName() string {
+	return "TransactionResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e TransactionResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sTransactionResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = TransactionResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = TransactionResponseValidationError{}
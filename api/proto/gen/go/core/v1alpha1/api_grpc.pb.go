@@ -25,6 +25,7 @@ const (
 	EngineService_Append_FullMethodName            = "/core.v1alpha1.EngineService/Append"
 	EngineService_Incr_FullMethodName              = "/core.v1alpha1.EngineService/Incr"
 	EngineService_Update_FullMethodName            = "/core.v1alpha1.EngineService/Update"
+	EngineService_Transaction_FullMethodName       = "/core.v1alpha1.EngineService/Transaction"
 )
 
 // EngineServiceClient is the client API for EngineService service.
@@ -43,6 +44,9 @@ type EngineServiceClient interface {
 	Incr(ctx context.Context, in *IncrRequest, opts ...grpc.CallOption) (*IncrResponse, error)
 	// Update updates the feature value for the given selector.
 	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
+	// Transaction writes the given feature values of a single entity together: they're validated before any of them is
+	// written, and applied by a single transaction of the state. Writes of several entities are rejected.
+	Transaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
 }
 
 type engineServiceClient struct {
@@ -107,6 +111,15 @@ func (c *engineServiceClient) Update(ctx context.Context, in *UpdateRequest, opt
 	return out, nil
 }
 
+func (c *engineServiceClient) Transaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error) {
+	out := new(TransactionResponse)
+	err := c.cc.Invoke(ctx, EngineService_Transaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EngineServiceServer is the server API for EngineService service.
 // All implementations should embed UnimplementedEngineServiceServer
 // for forward compatibility
@@ -123,6 +136,9 @@ type EngineServiceServer interface {
 	Incr(context.Context, *IncrRequest) (*IncrResponse, error)
 	// Update updates the feature value for the given selector.
 	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
+	// Transaction writes the given feature values of a single entity together: they're validated before any of them is
+	// written, and applied by a single transaction of the state. Writes of several entities are rejected.
+	Transaction(context.Context, *TransactionRequest) (*TransactionResponse, error)
 }
 
 // UnimplementedEngineServiceServer should be embedded to have forward compatible implementations.
@@ -147,6 +163,9 @@ func (UnimplementedEngineServiceServer) Incr(context.Context, *IncrRequest) (*In
 func (UnimplementedEngineServiceServer) Update(context.Context, *UpdateRequest) (*UpdateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
 }
+func (UnimplementedEngineServiceServer) Transaction(context.Context, *TransactionRequest) (*TransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transaction not implemented")
+}
 
 // UnsafeEngineServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to EngineServiceServer will
@@ -267,6 +286,24 @@ func _EngineService_Update_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EngineService_Transaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EngineServiceServer).Transaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EngineService_Transaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EngineServiceServer).Transaction(ctx, req.(*TransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // EngineService_ServiceDesc is the grpc.ServiceDesc for EngineService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -298,6 +335,10 @@ var EngineService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Update",
 			Handler:    _EngineService_Update_Handler,
 		},
+		{
+			MethodName: "Transaction",
+			Handler:    _EngineService_Transaction_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "core/v1alpha1/api.proto",
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.32.0
+// 	protoc-gen-go v1.35.1
 // 	protoc        (unknown)
 // source: core/v1alpha1/types.proto
 
@@ -97,6 +97,66 @@ func (Primitive) EnumDescriptor() ([]byte, []int) {
 	return file_core_v1alpha1_types_proto_rawDescGZIP(), []int{0}
 }
 
+// ValueSource is the path that produced a feature value.
+type ValueSource int32
+
+const (
+	ValueSource_VALUE_SOURCE_UNSPECIFIED ValueSource = 0
+	// The value was read from the state.
+	ValueSource_VALUE_SOURCE_STATE ValueSource = 1
+	// The value was read from the in-process read cache of the state.
+	ValueSource_VALUE_SOURCE_CACHE ValueSource = 2
+	// The value is the fallback of the feature, since there was no fresh value.
+	ValueSource_VALUE_SOURCE_FALLBACK ValueSource = 3
+	// The value was computed on the request (i.e. by the feature's builder).
+	ValueSource_VALUE_SOURCE_COMPUTED ValueSource = 4
+)
+
+// Enum value maps for ValueSource.
+var (
+	ValueSource_name = map[int32]string{
+		0: "VALUE_SOURCE_UNSPECIFIED",
+		1: "VALUE_SOURCE_STATE",
+		2: "VALUE_SOURCE_CACHE",
+		3: "VALUE_SOURCE_FALLBACK",
+		4: "VALUE_SOURCE_COMPUTED",
+	}
+	ValueSource_value = map[string]int32{
+		"VALUE_SOURCE_UNSPECIFIED": 0,
+		"VALUE_SOURCE_STATE":       1,
+		"VALUE_SOURCE_CACHE":       2,
+		"VALUE_SOURCE_FALLBACK":    3,
+		"VALUE_SOURCE_COMPUTED":    4,
+	}
+)
+
+func (x ValueSource) Enum() *ValueSource {
+	p := new(ValueSource)
+	*p = x
+	return p
+}
+
+func (x ValueSource) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ValueSource) Descriptor() protoreflect.EnumDescriptor {
+	return file_core_v1alpha1_types_proto_enumTypes[1].Descriptor()
+}
+
+func (ValueSource) Type() protoreflect.EnumType {
+	return &file_core_v1alpha1_types_proto_enumTypes[1]
+}
+
+func (x ValueSource) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ValueSource.Descriptor instead.
+func (ValueSource) EnumDescriptor() ([]byte, []int) {
+	return file_core_v1alpha1_types_proto_rawDescGZIP(), []int{1}
+}
+
 type AggrFn int32
 
 const (
@@ -139,11 +199,11 @@ func (x AggrFn) String() string {
 }
 
 func (AggrFn) Descriptor() protoreflect.EnumDescriptor {
-	return file_core_v1alpha1_types_proto_enumTypes[1].Descriptor()
+	return file_core_v1alpha1_types_proto_enumTypes[2].Descriptor()
 }
 
 func (AggrFn) Type() protoreflect.EnumType {
-	return &file_core_v1alpha1_types_proto_enumTypes[1]
+	return &file_core_v1alpha1_types_proto_enumTypes[2]
 }
 
 func (x AggrFn) Number() protoreflect.EnumNumber {
@@ -152,7 +212,7 @@ func (x AggrFn) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use AggrFn.Descriptor instead.
 func (AggrFn) EnumDescriptor() ([]byte, []int) {
-	return file_core_v1alpha1_types_proto_rawDescGZIP(), []int{1}
+	return file_core_v1alpha1_types_proto_rawDescGZIP(), []int{2}
 }
 
 type Scalar struct {
@@ -161,7 +221,6 @@ type Scalar struct {
 	unknownFields protoimpl.UnknownFields
 
 	// Types that are assignable to Value:
-	//
 	//	*Scalar_StringValue
 	//	*Scalar_IntValue
 	//	*Scalar_FloatValue
@@ -172,11 +231,9 @@ type Scalar struct {
 
 func (x *Scalar) Reset() {
 	*x = Scalar{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_types_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_types_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *Scalar) String() string {
@@ -187,7 +244,7 @@ func (*Scalar) ProtoMessage() {}
 
 func (x *Scalar) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_types_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -288,11 +345,9 @@ type List struct {
 
 func (x *List) Reset() {
 	*x = List{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_types_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_types_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *List) String() string {
@@ -303,7 +358,7 @@ func (*List) ProtoMessage() {}
 
 func (x *List) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_types_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -331,7 +386,6 @@ type Value struct {
 	unknownFields protoimpl.UnknownFields
 
 	// Types that are assignable to Value:
-	//
 	//	*Value_ScalarValue
 	//	*Value_ListValue
 	Value isValue_Value `protobuf_oneof:"value"`
@@ -339,11 +393,9 @@ type Value struct {
 
 func (x *Value) Reset() {
 	*x = Value{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_types_proto_msgTypes[2]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_types_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *Value) String() string {
@@ -354,7 +406,7 @@ func (*Value) ProtoMessage() {}
 
 func (x *Value) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_types_proto_msgTypes[2]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -417,11 +469,9 @@ type ObjectReference struct {
 
 func (x *ObjectReference) Reset() {
 	*x = ObjectReference{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_types_proto_msgTypes[3]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_types_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *ObjectReference) String() string {
@@ -432,7 +482,7 @@ func (*ObjectReference) ProtoMessage() {}
 
 func (x *ObjectReference) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_types_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -472,11 +522,9 @@ type KeepPrevious struct {
 
 func (x *KeepPrevious) Reset() {
 	*x = KeepPrevious{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_types_proto_msgTypes[4]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_types_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *KeepPrevious) String() string {
@@ -487,7 +535,7 @@ func (*KeepPrevious) ProtoMessage() {}
 
 func (x *KeepPrevious) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_types_proto_msgTypes[4]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -536,11 +584,9 @@ type FeatureDescriptor struct {
 
 func (x *FeatureDescriptor) Reset() {
 	*x = FeatureDescriptor{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_types_proto_msgTypes[5]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_types_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *FeatureDescriptor) String() string {
@@ -551,7 +597,7 @@ func (*FeatureDescriptor) ProtoMessage() {}
 
 func (x *FeatureDescriptor) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_types_proto_msgTypes[5]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -653,15 +699,16 @@ type FeatureValue struct {
 	Value     *Value                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
 	Timestamp *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	Fresh     bool                   `protobuf:"varint,5,opt,name=fresh,proto3" json:"fresh,omitempty"`
+	// Stale indicates that the value is older than the freshness of the feature.
+	Stale  bool        `protobuf:"varint,6,opt,name=stale,proto3" json:"stale,omitempty"`
+	Source ValueSource `protobuf:"varint,7,opt,name=source,proto3,enum=core.v1alpha1.ValueSource" json:"source,omitempty"`
 }
 
 func (x *FeatureValue) Reset() {
 	*x = FeatureValue{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_core_v1alpha1_types_proto_msgTypes[6]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_core_v1alpha1_types_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *FeatureValue) String() string {
@@ -672,7 +719,7 @@ func (*FeatureValue) ProtoMessage() {}
 
 func (x *FeatureValue) ProtoReflect() protoreflect.Message {
 	mi := &file_core_v1alpha1_types_proto_msgTypes[6]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -722,6 +769,20 @@ func (x *FeatureValue) GetFresh() bool {
 	return false
 }
 
+func (x *FeatureValue) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *FeatureValue) GetSource() ValueSource {
+	if x != nil {
+		return x.Source
+	}
+	return ValueSource_VALUE_SOURCE_UNSPECIFIED
+}
+
 var File_core_v1alpha1_types_proto protoreflect.FileDescriptor
 
 var file_core_v1alpha1_types_proto_rawDesc = []byte{
@@ -805,7 +866,7 @@ var file_core_v1alpha1_types_proto_rawDesc = []byte{
 	0x75, 0x72, 0x63, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x5f,
 	0x65, 0x6e, 0x76, 0x18, 0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x75, 0x6e, 0x74, 0x69,
 	0x6d, 0x65, 0x45, 0x6e, 0x76, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x6b, 0x65, 0x65, 0x70, 0x5f, 0x70,
-	0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x4a, 0x04, 0x08, 0x09, 0x10, 0x0f, 0x22, 0xbe, 0x02,
+	0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x4a, 0x04, 0x08, 0x09, 0x10, 0x0f, 0x22, 0x88, 0x03,
 	0x0a, 0x0c, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x3e,
 	0x0a, 0x03, 0x66, 0x71, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x2c, 0xfa, 0x42, 0x29,
 	0x72, 0x27, 0x32, 0x25, 0x28, 0x69, 0x3f, 0x29, 0x5e, 0x28, 0x5b, 0x61, 0x30, 0x2d, 0x7a, 0x39,
@@ -822,48 +883,55 @@ var file_core_v1alpha1_types_proto_rawDesc = []byte{
 	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
 	0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12,
 	0x14, 0x0a, 0x05, 0x66, 0x72, 0x65, 0x73, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05,
-	0x66, 0x72, 0x65, 0x73, 0x68, 0x1a, 0x37, 0x0a, 0x09, 0x4b, 0x65, 0x79, 0x73, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x2a, 0x9d,
-	0x02, 0x0a, 0x09, 0x50, 0x72, 0x69, 0x6d, 0x69, 0x74, 0x69, 0x76, 0x65, 0x12, 0x19, 0x0a, 0x15,
-	0x50, 0x52, 0x49, 0x4d, 0x49, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43,
-	0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x14, 0x0a, 0x10, 0x50, 0x52, 0x49, 0x4d, 0x49,
-	0x54, 0x49, 0x56, 0x45, 0x5f, 0x53, 0x54, 0x52, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x15, 0x0a,
-	0x11, 0x50, 0x52, 0x49, 0x4d, 0x49, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x49, 0x4e, 0x54, 0x45, 0x47,
-	0x45, 0x52, 0x10, 0x02, 0x12, 0x13, 0x0a, 0x0f, 0x50, 0x52, 0x49, 0x4d, 0x49, 0x54, 0x49, 0x56,
-	0x45, 0x5f, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x10, 0x03, 0x12, 0x12, 0x0a, 0x0e, 0x50, 0x52, 0x49,
-	0x4d, 0x49, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x42, 0x4f, 0x4f, 0x4c, 0x10, 0x04, 0x12, 0x17, 0x0a,
-	0x13, 0x50, 0x52, 0x49, 0x4d, 0x49, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x53,
-	0x54, 0x41, 0x4d, 0x50, 0x10, 0x05, 0x12, 0x19, 0x0a, 0x15, 0x50, 0x52, 0x49, 0x4d, 0x49, 0x54,
-	0x49, 0x56, 0x45, 0x5f, 0x53, 0x54, 0x52, 0x49, 0x4e, 0x47, 0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10,
-	0x0a, 0x12, 0x1a, 0x0a, 0x16, 0x50, 0x52, 0x49, 0x4d, 0x49, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x49,
-	0x4e, 0x54, 0x45, 0x47, 0x45, 0x52, 0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x0b, 0x12, 0x18, 0x0a,
-	0x14, 0x50, 0x52, 0x49, 0x4d, 0x49, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x46, 0x4c, 0x4f, 0x41, 0x54,
-	0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x0c, 0x12, 0x17, 0x0a, 0x13, 0x50, 0x52, 0x49, 0x4d, 0x49,
-	0x54, 0x49, 0x56, 0x45, 0x5f, 0x42, 0x4f, 0x4f, 0x4c, 0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x0d,
-	0x12, 0x1c, 0x0a, 0x18, 0x50, 0x52, 0x49, 0x4d, 0x49, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x54, 0x49,
-	0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d, 0x50, 0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x0e, 0x2a, 0x78,
-	0x0a, 0x06, 0x41, 0x67, 0x67, 0x72, 0x46, 0x6e, 0x12, 0x17, 0x0a, 0x13, 0x41, 0x47, 0x47, 0x52,
-	0x5f, 0x46, 0x4e, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
-	0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x47, 0x47, 0x52, 0x5f, 0x46, 0x4e, 0x5f, 0x53, 0x55, 0x4d,
-	0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x47, 0x47, 0x52, 0x5f, 0x46, 0x4e, 0x5f, 0x41, 0x56,
-	0x47, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x47, 0x47, 0x52, 0x5f, 0x46, 0x4e, 0x5f, 0x4d,
-	0x41, 0x58, 0x10, 0x03, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x47, 0x47, 0x52, 0x5f, 0x46, 0x4e, 0x5f,
-	0x4d, 0x49, 0x4e, 0x10, 0x04, 0x12, 0x11, 0x0a, 0x0d, 0x41, 0x47, 0x47, 0x52, 0x5f, 0x46, 0x4e,
-	0x5f, 0x43, 0x4f, 0x55, 0x4e, 0x54, 0x10, 0x05, 0x42, 0xbd, 0x01, 0x0a, 0x11, 0x63, 0x6f, 0x6d,
-	0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x42, 0x0a,
-	0x54, 0x79, 0x70, 0x65, 0x73, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x47, 0x67, 0x69,
-	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x61, 0x70, 0x74, 0x6f, 0x72, 0x2d,
-	0x6d, 0x6c, 0x2f, 0x72, 0x61, 0x70, 0x74, 0x6f, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x67, 0x6f, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f,
-	0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x3b, 0x63, 0x6f, 0x72, 0x65, 0x76, 0x31, 0x61,
-	0x6c, 0x70, 0x68, 0x61, 0x31, 0xa2, 0x02, 0x03, 0x43, 0x58, 0x58, 0xaa, 0x02, 0x0d, 0x43, 0x6f,
-	0x72, 0x65, 0x2e, 0x56, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0xca, 0x02, 0x0d, 0x43, 0x6f,
-	0x72, 0x65, 0x5c, 0x56, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0xe2, 0x02, 0x19, 0x43, 0x6f,
-	0x72, 0x65, 0x5c, 0x56, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x5c, 0x47, 0x50, 0x42, 0x4d,
-	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea, 0x02, 0x0e, 0x43, 0x6f, 0x72, 0x65, 0x3a, 0x3a,
-	0x56, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x66, 0x72, 0x65, 0x73, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x12, 0x32, 0x0a, 0x06, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x63, 0x6f,
+	0x72, 0x65, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x1a,
+	0x37, 0x0a, 0x09, 0x4b, 0x65, 0x79, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x2a, 0x9d, 0x02, 0x0a, 0x09, 0x50, 0x72, 0x69,
+	0x6d, 0x69, 0x74, 0x69, 0x76, 0x65, 0x12, 0x19, 0x0a, 0x15, 0x50, 0x52, 0x49, 0x4d, 0x49, 0x54,
+	0x49, 0x56, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x14, 0x0a, 0x10, 0x50, 0x52, 0x49, 0x4d, 0x49, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x53,
+	0x54, 0x52, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x50, 0x52, 0x49, 0x4d, 0x49,
+	0x54, 0x49, 0x56, 0x45, 0x5f, 0x49, 0x4e, 0x54, 0x45, 0x47, 0x45, 0x52, 0x10, 0x02, 0x12, 0x13,
+	0x0a, 0x0f, 0x50, 0x52, 0x49, 0x4d, 0x49, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x46, 0x4c, 0x4f, 0x41,
+	0x54, 0x10, 0x03, 0x12, 0x12, 0x0a, 0x0e, 0x50, 0x52, 0x49, 0x4d, 0x49, 0x54, 0x49, 0x56, 0x45,
+	0x5f, 0x42, 0x4f, 0x4f, 0x4c, 0x10, 0x04, 0x12, 0x17, 0x0a, 0x13, 0x50, 0x52, 0x49, 0x4d, 0x49,
+	0x54, 0x49, 0x56, 0x45, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d, 0x50, 0x10, 0x05,
+	0x12, 0x19, 0x0a, 0x15, 0x50, 0x52, 0x49, 0x4d, 0x49, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x53, 0x54,
+	0x52, 0x49, 0x4e, 0x47, 0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x0a, 0x12, 0x1a, 0x0a, 0x16, 0x50,
+	0x52, 0x49, 0x4d, 0x49, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x49, 0x4e, 0x54, 0x45, 0x47, 0x45, 0x52,
+	0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x0b, 0x12, 0x18, 0x0a, 0x14, 0x50, 0x52, 0x49, 0x4d, 0x49,
+	0x54, 0x49, 0x56, 0x45, 0x5f, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10,
+	0x0c, 0x12, 0x17, 0x0a, 0x13, 0x50, 0x52, 0x49, 0x4d, 0x49, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x42,
+	0x4f, 0x4f, 0x4c, 0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x0d, 0x12, 0x1c, 0x0a, 0x18, 0x50, 0x52,
+	0x49, 0x4d, 0x49, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d,
+	0x50, 0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x0e, 0x2a, 0x91, 0x01, 0x0a, 0x0b, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x18, 0x56, 0x41, 0x4c, 0x55,
+	0x45, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49,
+	0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x56, 0x41, 0x4c, 0x55, 0x45, 0x5f,
+	0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0x01, 0x12, 0x16,
+	0x0a, 0x12, 0x56, 0x41, 0x4c, 0x55, 0x45, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x43,
+	0x41, 0x43, 0x48, 0x45, 0x10, 0x02, 0x12, 0x19, 0x0a, 0x15, 0x56, 0x41, 0x4c, 0x55, 0x45, 0x5f,
+	0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x46, 0x41, 0x4c, 0x4c, 0x42, 0x41, 0x43, 0x4b, 0x10,
+	0x03, 0x12, 0x19, 0x0a, 0x15, 0x56, 0x41, 0x4c, 0x55, 0x45, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43,
+	0x45, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x55, 0x54, 0x45, 0x44, 0x10, 0x04, 0x2a, 0x78, 0x0a, 0x06,
+	0x41, 0x67, 0x67, 0x72, 0x46, 0x6e, 0x12, 0x17, 0x0a, 0x13, 0x41, 0x47, 0x47, 0x52, 0x5f, 0x46,
+	0x4e, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12,
+	0x0f, 0x0a, 0x0b, 0x41, 0x47, 0x47, 0x52, 0x5f, 0x46, 0x4e, 0x5f, 0x53, 0x55, 0x4d, 0x10, 0x01,
+	0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x47, 0x47, 0x52, 0x5f, 0x46, 0x4e, 0x5f, 0x41, 0x56, 0x47, 0x10,
+	0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x47, 0x47, 0x52, 0x5f, 0x46, 0x4e, 0x5f, 0x4d, 0x41, 0x58,
+	0x10, 0x03, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x47, 0x47, 0x52, 0x5f, 0x46, 0x4e, 0x5f, 0x4d, 0x49,
+	0x4e, 0x10, 0x04, 0x12, 0x11, 0x0a, 0x0d, 0x41, 0x47, 0x47, 0x52, 0x5f, 0x46, 0x4e, 0x5f, 0x43,
+	0x4f, 0x55, 0x4e, 0x54, 0x10, 0x05, 0x42, 0x49, 0x5a, 0x47, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x61, 0x70, 0x74, 0x6f, 0x72, 0x2d, 0x6d, 0x6c, 0x2f, 0x72,
+	0x61, 0x70, 0x74, 0x6f, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f,
+	0x67, 0x65, 0x6e, 0x2f, 0x67, 0x6f, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x76, 0x31, 0x61, 0x6c,
+	0x70, 0x68, 0x61, 0x31, 0x3b, 0x63, 0x6f, 0x72, 0x65, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61,
+	0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -878,42 +946,44 @@ func file_core_v1alpha1_types_proto_rawDescGZIP() []byte {
 	return file_core_v1alpha1_types_proto_rawDescData
 }
 
-var file_core_v1alpha1_types_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_core_v1alpha1_types_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
 var file_core_v1alpha1_types_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
-var file_core_v1alpha1_types_proto_goTypes = []interface{}{
+var file_core_v1alpha1_types_proto_goTypes = []any{
 	(Primitive)(0),                // 0: core.v1alpha1.Primitive
-	(AggrFn)(0),                   // 1: core.v1alpha1.AggrFn
-	(*Scalar)(nil),                // 2: core.v1alpha1.Scalar
-	(*List)(nil),                  // 3: core.v1alpha1.List
-	(*Value)(nil),                 // 4: core.v1alpha1.Value
-	(*ObjectReference)(nil),       // 5: core.v1alpha1.ObjectReference
-	(*KeepPrevious)(nil),          // 6: core.v1alpha1.KeepPrevious
-	(*FeatureDescriptor)(nil),     // 7: core.v1alpha1.FeatureDescriptor
-	(*FeatureValue)(nil),          // 8: core.v1alpha1.FeatureValue
-	nil,                           // 9: core.v1alpha1.FeatureValue.KeysEntry
-	(*timestamppb.Timestamp)(nil), // 10: google.protobuf.Timestamp
-	(*durationpb.Duration)(nil),   // 11: google.protobuf.Duration
+	(ValueSource)(0),              // 1: core.v1alpha1.ValueSource
+	(AggrFn)(0),                   // 2: core.v1alpha1.AggrFn
+	(*Scalar)(nil),                // 3: core.v1alpha1.Scalar
+	(*List)(nil),                  // 4: core.v1alpha1.List
+	(*Value)(nil),                 // 5: core.v1alpha1.Value
+	(*ObjectReference)(nil),       // 6: core.v1alpha1.ObjectReference
+	(*KeepPrevious)(nil),          // 7: core.v1alpha1.KeepPrevious
+	(*FeatureDescriptor)(nil),     // 8: core.v1alpha1.FeatureDescriptor
+	(*FeatureValue)(nil),          // 9: core.v1alpha1.FeatureValue
+	nil,                           // 10: core.v1alpha1.FeatureValue.KeysEntry
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 12: google.protobuf.Duration
 }
 var file_core_v1alpha1_types_proto_depIdxs = []int32{
-	10, // 0: core.v1alpha1.Scalar.timestamp_value:type_name -> google.protobuf.Timestamp
-	2,  // 1: core.v1alpha1.List.values:type_name -> core.v1alpha1.Scalar
-	2,  // 2: core.v1alpha1.Value.scalar_value:type_name -> core.v1alpha1.Scalar
-	3,  // 3: core.v1alpha1.Value.list_value:type_name -> core.v1alpha1.List
-	11, // 4: core.v1alpha1.KeepPrevious.over:type_name -> google.protobuf.Duration
+	11, // 0: core.v1alpha1.Scalar.timestamp_value:type_name -> google.protobuf.Timestamp
+	3,  // 1: core.v1alpha1.List.values:type_name -> core.v1alpha1.Scalar
+	3,  // 2: core.v1alpha1.Value.scalar_value:type_name -> core.v1alpha1.Scalar
+	4,  // 3: core.v1alpha1.Value.list_value:type_name -> core.v1alpha1.List
+	12, // 4: core.v1alpha1.KeepPrevious.over:type_name -> google.protobuf.Duration
 	0,  // 5: core.v1alpha1.FeatureDescriptor.primitive:type_name -> core.v1alpha1.Primitive
-	1,  // 6: core.v1alpha1.FeatureDescriptor.aggr:type_name -> core.v1alpha1.AggrFn
-	11, // 7: core.v1alpha1.FeatureDescriptor.freshness:type_name -> google.protobuf.Duration
-	11, // 8: core.v1alpha1.FeatureDescriptor.staleness:type_name -> google.protobuf.Duration
-	11, // 9: core.v1alpha1.FeatureDescriptor.timeout:type_name -> google.protobuf.Duration
-	6,  // 10: core.v1alpha1.FeatureDescriptor.keep_previous:type_name -> core.v1alpha1.KeepPrevious
-	9,  // 11: core.v1alpha1.FeatureValue.keys:type_name -> core.v1alpha1.FeatureValue.KeysEntry
-	4,  // 12: core.v1alpha1.FeatureValue.value:type_name -> core.v1alpha1.Value
-	10, // 13: core.v1alpha1.FeatureValue.timestamp:type_name -> google.protobuf.Timestamp
-	14, // [14:14] is the sub-list for method output_type
-	14, // [14:14] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	2,  // 6: core.v1alpha1.FeatureDescriptor.aggr:type_name -> core.v1alpha1.AggrFn
+	12, // 7: core.v1alpha1.FeatureDescriptor.freshness:type_name -> google.protobuf.Duration
+	12, // 8: core.v1alpha1.FeatureDescriptor.staleness:type_name -> google.protobuf.Duration
+	12, // 9: core.v1alpha1.FeatureDescriptor.timeout:type_name -> google.protobuf.Duration
+	7,  // 10: core.v1alpha1.FeatureDescriptor.keep_previous:type_name -> core.v1alpha1.KeepPrevious
+	10, // 11: core.v1alpha1.FeatureValue.keys:type_name -> core.v1alpha1.FeatureValue.KeysEntry
+	5,  // 12: core.v1alpha1.FeatureValue.value:type_name -> core.v1alpha1.Value
+	11, // 13: core.v1alpha1.FeatureValue.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 14: core.v1alpha1.FeatureValue.source:type_name -> core.v1alpha1.ValueSource
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_core_v1alpha1_types_proto_init() }
@@ -921,110 +991,24 @@ func file_core_v1alpha1_types_proto_init() {
 	if File_core_v1alpha1_types_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_core_v1alpha1_types_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Scalar); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_types_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*List); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_types_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Value); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_types_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ObjectReference); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_types_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*KeepPrevious); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_types_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FeatureDescriptor); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_core_v1alpha1_types_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FeatureValue); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
-	file_core_v1alpha1_types_proto_msgTypes[0].OneofWrappers = []interface{}{
+	file_core_v1alpha1_types_proto_msgTypes[0].OneofWrappers = []any{
 		(*Scalar_StringValue)(nil),
 		(*Scalar_IntValue)(nil),
 		(*Scalar_FloatValue)(nil),
 		(*Scalar_BoolValue)(nil),
 		(*Scalar_TimestampValue)(nil),
 	}
-	file_core_v1alpha1_types_proto_msgTypes[2].OneofWrappers = []interface{}{
+	file_core_v1alpha1_types_proto_msgTypes[2].OneofWrappers = []any{
 		(*Value_ScalarValue)(nil),
 		(*Value_ListValue)(nil),
 	}
-	file_core_v1alpha1_types_proto_msgTypes[5].OneofWrappers = []interface{}{}
+	file_core_v1alpha1_types_proto_msgTypes[5].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_core_v1alpha1_types_proto_rawDesc,
-			NumEnums:      2,
+			NumEnums:      3,
 			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
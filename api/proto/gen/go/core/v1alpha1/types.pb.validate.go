@@ -1156,6 +1156,10 @@ func (m *FeatureValue) validate(all bool) error {
 
 	// no validation rules for Fresh
 
+	// no validation rules for Stale
+
+	// no validation rules for Source
+
 	if len(errors) > 0 {
 		return FeatureValueMultiError(errors)
 	}
@@ -19,7 +19,7 @@ from validate import validate_pb2 as validate_dot_validate__pb2
 from protoc_gen_openapiv2.options import annotations_pb2 as protoc__gen__openapiv2_dot_options_dot_annotations__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x17\x63ore/v1alpha1/api.proto\x12\rcore.v1alpha1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x19\x63ore/v1alpha1/types.proto\x1a\x17validate/validate.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"\x8e\x03\n\nGetRequest\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\xef\x01\n\x08selector\x18\x02 \x01(\tB\xd2\x01\xfa\x42\xce\x01r\xcb\x01\x32\xc8\x01(?si)^((?P<namespace>([a0-z9]+[a0-z9_]*[a0-z9]+){1,256})\\.)?(?P<name>([a0-z9]+[a0-z9_]*[a0-z9]+){1,256})(\\+(?P<aggrFn>([a-z]+_*[a-z]+)))?(@-(?P<version>([0-9]+)))?(\\[(?P<encoding>([a-z]+_*[a-z]+))])?$R\x08selector\x12\x37\n\x04keys\x18\x03 \x03(\x0b\x32#.core.v1alpha1.GetRequest.KeysEntryR\x04keys\x1a\x37\n\tKeysEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"\xaf\x01\n\x0bGetResponse\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\x31\n\x05value\x18\x02 \x01(\x0b\x32\x1b.core.v1alpha1.FeatureValueR\x05value\x12O\n\x12\x66\x65\x61ture_descriptor\x18\x03 \x01(\x0b\x32 .core.v1alpha1.FeatureDescriptorR\x11\x66\x65\x61tureDescriptor\"\xaa\x02\n\x18\x46\x65\x61tureDescriptorRequest\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\xef\x01\n\x08selector\x18\x02 \x01(\tB\xd2\x01\xfa\x42\xce\x01r\xcb\x01\x32\xc8\x01(?si)^((?P<namespace>([a0-z9]+[a0-z9_]*[a0-z9]+){1,256})\\.)?(?P<name>([a0-z9]+[a0-z9_]*[a0-z9]+){1,256})(\\+(?P<aggrFn>([a-z]+_*[a-z]+)))?(@-(?P<version>([0-9]+)))?(\\[(?P<encoding>([a-z]+_*[a-z]+))])?$R\x08selector\"\x8a\x01\n\x19\x46\x65\x61tureDescriptorResponse\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12O\n\x12\x66\x65\x61ture_descriptor\x18\x02 \x01(\x0b\x32 .core.v1alpha1.FeatureDescriptorR\x11\x66\x65\x61tureDescriptor\"\xcc\x02\n\nSetRequest\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12H\n\x08selector\x18\x02 \x01(\tB,\xfa\x42)r\'2%(i?)^([a0-z9\\-\\.]*)(\\[([a0-z9])*\\])?$R\x08selector\x12\x37\n\x04keys\x18\x03 \x03(\x0b\x32#.core.v1alpha1.SetRequest.KeysEntryR\x04keys\x12*\n\x05value\x18\x04 \x01(\x0b\x32\x14.core.v1alpha1.ValueR\x05value\x12\x38\n\ttimestamp\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\x1a\x37\n\tKeysEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"e\n\x0bSetResponse\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\x38\n\ttimestamp\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\"\xc9\x02\n\rAppendRequest\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12>\n\x03\x66qn\x18\x02 \x01(\tB,\xfa\x42)r\'2%(i?)^([a0-z9\\-\\.]*)(\\[([a0-z9])*\\])?$R\x03\x66qn\x12:\n\x04keys\x18\x03 \x03(\x0b\x32&.core.v1alpha1.AppendRequest.KeysEntryR\x04keys\x12+\n\x05value\x18\x04 \x01(\x0b\x32\x15.core.v1alpha1.ScalarR\x05value\x12\x38\n\ttimestamp\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\x1a\x37\n\tKeysEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"h\n\x0e\x41ppendResponse\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\x38\n\ttimestamp\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\"\xc5\x02\n\x0bIncrRequest\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12>\n\x03\x66qn\x18\x02 \x01(\tB,\xfa\x42)r\'2%(i?)^([a0-z9\\-\\.]*)(\\[([a0-z9])*\\])?$R\x03\x66qn\x12\x38\n\x04keys\x18\x03 \x03(\x0b\x32$.core.v1alpha1.IncrRequest.KeysEntryR\x04keys\x12+\n\x05value\x18\x04 \x01(\x0b\x32\x15.core.v1alpha1.ScalarR\x05value\x12\x38\n\ttimestamp\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\x1a\x37\n\tKeysEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"f\n\x0cIncrResponse\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\x38\n\ttimestamp\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\"\xd2\x02\n\rUpdateRequest\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12H\n\x08selector\x18\x02 \x01(\tB,\xfa\x42)r\'2%(i?)^([a0-z9\\-\\.]*)(\\[([a0-z9])*\\])?$R\x08selector\x12:\n\x04keys\x18\x03 \x03(\x0b\x32&.core.v1alpha1.UpdateRequest.KeysEntryR\x04keys\x12*\n\x05value\x18\x04 \x01(\x0b\x32\x14.core.v1alpha1.ValueR\x05value\x12\x38\n\ttimestamp\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\x1a\x37\n\tKeysEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"h\n\x0eUpdateResponse\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\x38\n\ttimestamp\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp2\xcb\x04\n\rEngineService\x12\x83\x01\n\x11\x46\x65\x61tureDescriptor\x12\'.core.v1alpha1.FeatureDescriptorRequest\x1a(.core.v1alpha1.FeatureDescriptorResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x42\x13\n\x04HEAD\x12\x0b/{selector}\x12Q\n\x03Get\x12\x19.core.v1alpha1.GetRequest\x1a\x1a.core.v1alpha1.GetResponse\"\x13\x82\xd3\xe4\x93\x02\r\x12\x0b/{selector}\x12Q\n\x03Set\x12\x19.core.v1alpha1.SetRequest\x1a\x1a.core.v1alpha1.SetResponse\"\x13\x82\xd3\xe4\x93\x02\r\x1a\x0b/{selector}\x12\\\n\x06\x41ppend\x12\x1c.core.v1alpha1.AppendRequest\x1a\x1d.core.v1alpha1.AppendResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\"\r/{fqn}/append\x12T\n\x04Incr\x12\x1a.core.v1alpha1.IncrRequest\x1a\x1b.core.v1alpha1.IncrResponse\"\x13\x82\xd3\xe4\x93\x02\r\"\x0b/{fqn}/incr\x12Z\n\x06Update\x12\x1c.core.v1alpha1.UpdateRequest\x1a\x1d.core.v1alpha1.UpdateResponse\"\x13\x82\xd3\xe4\x93\x02\r\"\x0b/{selector}B\xf5\x02\n\x11\x63om.core.v1alpha1B\x08\x41piProtoP\x01ZGgithub.com/raptor-ml/raptor/api/proto/gen/go/core/v1alpha1;corev1alpha1\xa2\x02\x03\x43XX\xaa\x02\rCore.V1alpha1\xca\x02\rCore\\V1alpha1\xe2\x02\x19\x43ore\\V1alpha1\\GPBMetadata\xea\x02\x0e\x43ore::V1alpha1\x92\x41\xb6\x01\x12[\n\x08\x43ore API\x12OProvides access low-level operations over feature values and model predictions.\x1a\'raptor-core-service.raptor-system:60001*\x01\x01r+\n\x16Official documentation\x12\x11https://raptor.mlb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x17\x63ore/v1alpha1/api.proto\x12\rcore.v1alpha1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x19\x63ore/v1alpha1/types.proto\x1a\x17validate/validate.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"\x8e\x03\n\nGetRequest\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\xef\x01\n\x08selector\x18\x02 \x01(\tB\xd2\x01\xfa\x42\xce\x01r\xcb\x01\x32\xc8\x01(?si)^((?P<namespace>([a0-z9]+[a0-z9_]*[a0-z9]+){1,256})\\.)?(?P<name>([a0-z9]+[a0-z9_]*[a0-z9]+){1,256})(\\+(?P<aggrFn>([a-z]+_*[a-z]+)))?(@-(?P<version>([0-9]+)))?(\\[(?P<encoding>([a-z]+_*[a-z]+))])?$R\x08selector\x12\x37\n\x04keys\x18\x03 \x03(\x0b\x32#.core.v1alpha1.GetRequest.KeysEntryR\x04keys\x1a\x37\n\tKeysEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"\xaf\x01\n\x0bGetResponse\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\x31\n\x05value\x18\x02 \x01(\x0b\x32\x1b.core.v1alpha1.FeatureValueR\x05value\x12O\n\x12\x66\x65\x61ture_descriptor\x18\x03 \x01(\x0b\x32 .core.v1alpha1.FeatureDescriptorR\x11\x66\x65\x61tureDescriptor\"\xaa\x02\n\x18\x46\x65\x61tureDescriptorRequest\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\xef\x01\n\x08selector\x18\x02 \x01(\tB\xd2\x01\xfa\x42\xce\x01r\xcb\x01\x32\xc8\x01(?si)^((?P<namespace>([a0-z9]+[a0-z9_]*[a0-z9]+){1,256})\\.)?(?P<name>([a0-z9]+[a0-z9_]*[a0-z9]+){1,256})(\\+(?P<aggrFn>([a-z]+_*[a-z]+)))?(@-(?P<version>([0-9]+)))?(\\[(?P<encoding>([a-z]+_*[a-z]+))])?$R\x08selector\"\x8a\x01\n\x19\x46\x65\x61tureDescriptorResponse\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12O\n\x12\x66\x65\x61ture_descriptor\x18\x02 \x01(\x0b\x32 .core.v1alpha1.FeatureDescriptorR\x11\x66\x65\x61tureDescriptor\"\xcc\x02\n\nSetRequest\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12H\n\x08selector\x18\x02 \x01(\tB,\xfa\x42)r\'2%(i?)^([a0-z9\\-\\.]*)(\\[([a0-z9])*\\])?$R\x08selector\x12\x37\n\x04keys\x18\x03 \x03(\x0b\x32#.core.v1alpha1.SetRequest.KeysEntryR\x04keys\x12*\n\x05value\x18\x04 \x01(\x0b\x32\x14.core.v1alpha1.ValueR\x05value\x12\x38\n\ttimestamp\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\x1a\x37\n\tKeysEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"e\n\x0bSetResponse\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\x38\n\ttimestamp\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\"\xc9\x02\n\rAppendRequest\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12>\n\x03\x66qn\x18\x02 \x01(\tB,\xfa\x42)r\'2%(i?)^([a0-z9\\-\\.]*)(\\[([a0-z9])*\\])?$R\x03\x66qn\x12:\n\x04keys\x18\x03 \x03(\x0b\x32&.core.v1alpha1.AppendRequest.KeysEntryR\x04keys\x12+\n\x05value\x18\x04 \x01(\x0b\x32\x15.core.v1alpha1.ScalarR\x05value\x12\x38\n\ttimestamp\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\x1a\x37\n\tKeysEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"h\n\x0e\x41ppendResponse\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\x38\n\ttimestamp\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\"\xc5\x02\n\x0bIncrRequest\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12>\n\x03\x66qn\x18\x02 \x01(\tB,\xfa\x42)r\'2%(i?)^([a0-z9\\-\\.]*)(\\[([a0-z9])*\\])?$R\x03\x66qn\x12\x38\n\x04keys\x18\x03 \x03(\x0b\x32$.core.v1alpha1.IncrRequest.KeysEntryR\x04keys\x12+\n\x05value\x18\x04 \x01(\x0b\x32\x15.core.v1alpha1.ScalarR\x05value\x12\x38\n\ttimestamp\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\x1a\x37\n\tKeysEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"f\n\x0cIncrResponse\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\x38\n\ttimestamp\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\"\xd2\x02\n\rUpdateRequest\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12H\n\x08selector\x18\x02 \x01(\tB,\xfa\x42)r\'2%(i?)^([a0-z9\\-\\.]*)(\\[([a0-z9])*\\])?$R\x08selector\x12:\n\x04keys\x18\x03 \x03(\x0b\x32&.core.v1alpha1.UpdateRequest.KeysEntryR\x04keys\x12*\n\x05value\x18\x04 \x01(\x0b\x32\x14.core.v1alpha1.ValueR\x05value\x12\x38\n\ttimestamp\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\x1a\x37\n\tKeysEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"h\n\x0eUpdateResponse\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\x38\n\ttimestamp\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\"\x93\x03\n\x10TransactionWrite\x12<\n\x06method\x18\x01 \x01(\x0e\x32\x1a.core.v1alpha1.WriteMethodB\x08\xfa\x42\x05\x82\x01\x02\x10\x01R\x06method\x12H\n\x08selector\x18\x02 \x01(\tB,\xfa\x42)r\'2%(i?)^([a0-z9\\-\\.]*)(\\[([a0-z9])*\\])?$R\x08selector\x12=\n\x04keys\x18\x03 \x03(\x0b\x32).core.v1alpha1.TransactionWrite.KeysEntryR\x04keys\x12*\n\x05value\x18\x04 \x01(\x0b\x32\x14.core.v1alpha1.ValueR\x05value\x12\x38\n\ttimestamp\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp\x12\x19\n\x08\x65vent_id\x18\x06 \x01(\tR\x07\x65ventId\x1a\x37\n\tKeysEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"u\n\x12TransactionRequest\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\x41\n\x06writes\x18\x02 \x03(\x0b\x32\x1f.core.v1alpha1.TransactionWriteB\x08\xfa\x42\x05\x92\x01\x02\x08\x01R\x06writes\"m\n\x13TransactionResponse\x12\x1c\n\x04uuid\x18\x01 \x01(\tB\x08\xfa\x42\x05r\x03\xb0\x01\x01R\x04uuid\x12\x38\n\ttimestamp\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.TimestampR\ttimestamp*\x8a\x01\n\x0bWriteMethod\x12\x1c\n\x18WRITE_METHOD_UNSPECIFIED\x10\x00\x12\x14\n\x10WRITE_METHOD_SET\x10\x01\x12\x17\n\x13WRITE_METHOD_APPEND\x10\x02\x12\x15\n\x11WRITE_METHOD_INCR\x10\x03\x12\x17\n\x13WRITE_METHOD_UPDATE\x10\x04\x32\xa3\x05\n\rEngineService\x12\x83\x01\n\x11\x46\x65\x61tureDescriptor\x12\'.core.v1alpha1.FeatureDescriptorRequest\x1a(.core.v1alpha1.FeatureDescriptorResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x42\x13\n\x04HEAD\x12\x0b/{selector}\x12Q\n\x03Get\x12\x19.core.v1alpha1.GetRequest\x1a\x1a.core.v1alpha1.GetResponse\"\x13\x82\xd3\xe4\x93\x02\r\x12\x0b/{selector}\x12Q\n\x03Set\x12\x19.core.v1alpha1.SetRequest\x1a\x1a.core.v1alpha1.SetResponse\"\x13\x82\xd3\xe4\x93\x02\r\x1a\x0b/{selector}\x12\\\n\x06\x41ppend\x12\x1c.core.v1alpha1.AppendRequest\x1a\x1d.core.v1alpha1.AppendResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\"\r/{fqn}/append\x12T\n\x04Incr\x12\x1a.core.v1alpha1.IncrRequest\x1a\x1b.core.v1alpha1.IncrResponse\"\x13\x82\xd3\xe4\x93\x02\r\"\x0b/{fqn}/incr\x12Z\n\x06Update\x12\x1c.core.v1alpha1.UpdateRequest\x1a\x1d.core.v1alpha1.UpdateResponse\"\x13\x82\xd3\xe4\x93\x02\r\"\x0b/{selector}\x12V\n\x0bTransaction\x12!.core.v1alpha1.TransactionRequest\x1a\".core.v1alpha1.TransactionResponse\"\x00\x42\xf5\x02\n\x11\x63om.core.v1alpha1B\x08\x41piProtoP\x01ZGgithub.com/raptor-ml/raptor/api/proto/gen/go/core/v1alpha1;corev1alpha1\xa2\x02\x03\x43XX\xaa\x02\rCore.V1alpha1\xca\x02\rCore\\V1alpha1\xe2\x02\x19\x43ore\\V1alpha1\\GPBMetadata\xea\x02\x0e\x43ore::V1alpha1\x92\x41\xb6\x01\x12[\n\x08\x43ore API\x12OProvides access low-level operations over feature values and model predictions.\x1a\'raptor-core-service.raptor-system:60001*\x01\x01r+\n\x16Official documentation\x12\x11https://raptor.mlb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -73,6 +73,18 @@ if _descriptor._USE_C_DESCRIPTORS == False:
   _globals['_UPDATEREQUEST'].fields_by_name['selector']._serialized_options = b'\372B)r\'2%(i?)^([a0-z9\\-\\.]*)(\\[([a0-z9])*\\])?$'
   _globals['_UPDATERESPONSE'].fields_by_name['uuid']._options = None
   _globals['_UPDATERESPONSE'].fields_by_name['uuid']._serialized_options = b'\372B\005r\003\260\001\001'
+  _globals['_TRANSACTIONWRITE_KEYSENTRY']._options = None
+  _globals['_TRANSACTIONWRITE_KEYSENTRY']._serialized_options = b'8\001'
+  _globals['_TRANSACTIONWRITE'].fields_by_name['method']._options = None
+  _globals['_TRANSACTIONWRITE'].fields_by_name['method']._serialized_options = b'\372B\005\202\001\002\020\001'
+  _globals['_TRANSACTIONWRITE'].fields_by_name['selector']._options = None
+  _globals['_TRANSACTIONWRITE'].fields_by_name['selector']._serialized_options = b'\372B)r\'2%(i?)^([a0-z9\\-\\.]*)(\\[([a0-z9])*\\])?$'
+  _globals['_TRANSACTIONREQUEST'].fields_by_name['uuid']._options = None
+  _globals['_TRANSACTIONREQUEST'].fields_by_name['uuid']._serialized_options = b'\372B\005r\003\260\001\001'
+  _globals['_TRANSACTIONREQUEST'].fields_by_name['writes']._options = None
+  _globals['_TRANSACTIONREQUEST'].fields_by_name['writes']._serialized_options = b'\372B\005\222\001\002\010\001'
+  _globals['_TRANSACTIONRESPONSE'].fields_by_name['uuid']._options = None
+  _globals['_TRANSACTIONRESPONSE'].fields_by_name['uuid']._serialized_options = b'\372B\005r\003\260\001\001'
   _globals['_ENGINESERVICE'].methods_by_name['FeatureDescriptor']._options = None
   _globals['_ENGINESERVICE'].methods_by_name['FeatureDescriptor']._serialized_options = b'\202\323\344\223\002\025B\023\n\004HEAD\022\013/{selector}'
   _globals['_ENGINESERVICE'].methods_by_name['Get']._options = None
@@ -85,6 +97,8 @@ if _descriptor._USE_C_DESCRIPTORS == False:
   _globals['_ENGINESERVICE'].methods_by_name['Incr']._serialized_options = b'\202\323\344\223\002\r\"\013/{fqn}/incr'
   _globals['_ENGINESERVICE'].methods_by_name['Update']._options = None
   _globals['_ENGINESERVICE'].methods_by_name['Update']._serialized_options = b'\202\323\344\223\002\r\"\013/{selector}'
+  _globals['_WRITEMETHOD']._serialized_start=3618
+  _globals['_WRITEMETHOD']._serialized_end=3756
   _globals['_GETREQUEST']._serialized_start=206
   _globals['_GETREQUEST']._serialized_end=604
   _globals['_GETREQUEST_KEYSENTRY']._serialized_start=549
@@ -119,6 +133,14 @@ if _descriptor._USE_C_DESCRIPTORS == False:
   _globals['_UPDATEREQUEST_KEYSENTRY']._serialized_end=604
   _globals['_UPDATERESPONSE']._serialized_start=2875
   _globals['_UPDATERESPONSE']._serialized_end=2979
-  _globals['_ENGINESERVICE']._serialized_start=2982
-  _globals['_ENGINESERVICE']._serialized_end=3569
+  _globals['_TRANSACTIONWRITE']._serialized_start=2982
+  _globals['_TRANSACTIONWRITE']._serialized_end=3385
+  _globals['_TRANSACTIONWRITE_KEYSENTRY']._serialized_start=549
+  _globals['_TRANSACTIONWRITE_KEYSENTRY']._serialized_end=604
+  _globals['_TRANSACTIONREQUEST']._serialized_start=3387
+  _globals['_TRANSACTIONREQUEST']._serialized_end=3504
+  _globals['_TRANSACTIONRESPONSE']._serialized_start=3506
+  _globals['_TRANSACTIONRESPONSE']._serialized_end=3615
+  _globals['_ENGINESERVICE']._serialized_start=3759
+  _globals['_ENGINESERVICE']._serialized_end=4434
 # @@protoc_insertion_point(module_scope)
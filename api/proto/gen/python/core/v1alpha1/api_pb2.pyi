@@ -4,12 +4,27 @@ from core.v1alpha1 import types_pb2 as _types_pb2
 from validate import validate_pb2 as _validate_pb2
 from protoc_gen_openapiv2.options import annotations_pb2 as _annotations_pb2_1
 from google.protobuf.internal import containers as _containers
+from google.protobuf.internal import enum_type_wrapper as _enum_type_wrapper
 from google.protobuf import descriptor as _descriptor
 from google.protobuf import message as _message
-from typing import ClassVar as _ClassVar, Mapping as _Mapping, Optional as _Optional, Union as _Union
+from typing import ClassVar as _ClassVar, Iterable as _Iterable, Mapping as _Mapping, Optional as _Optional, Union as _Union
 
 DESCRIPTOR: _descriptor.FileDescriptor
 
+class WriteMethod(int, metaclass=_enum_type_wrapper.EnumTypeWrapper):
+    __slots__ = ()
+    WRITE_METHOD_UNSPECIFIED: _ClassVar[WriteMethod]
+    WRITE_METHOD_SET: _ClassVar[WriteMethod]
+    WRITE_METHOD_APPEND: _ClassVar[WriteMethod]
+    WRITE_METHOD_INCR: _ClassVar[WriteMethod]
+    WRITE_METHOD_UPDATE: _ClassVar[WriteMethod]
+
+WRITE_METHOD_UNSPECIFIED: WriteMethod
+WRITE_METHOD_SET: WriteMethod
+WRITE_METHOD_APPEND: WriteMethod
+WRITE_METHOD_INCR: WriteMethod
+WRITE_METHOD_UPDATE: WriteMethod
+
 class GetRequest(_message.Message):
     __slots__ = ("uuid", "selector", "keys")
     class KeysEntry(_message.Message):
@@ -168,3 +183,42 @@ class UpdateResponse(_message.Message):
     uuid: str
     timestamp: _timestamp_pb2.Timestamp
     def __init__(self, uuid: _Optional[str] = ..., timestamp: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ...) -> None: ...
+
+class TransactionWrite(_message.Message):
+    __slots__ = ("method", "selector", "keys", "value", "timestamp", "event_id")
+    class KeysEntry(_message.Message):
+        __slots__ = ("key", "value")
+        KEY_FIELD_NUMBER: _ClassVar[int]
+        VALUE_FIELD_NUMBER: _ClassVar[int]
+        key: str
+        value: str
+        def __init__(self, key: _Optional[str] = ..., value: _Optional[str] = ...) -> None: ...
+    METHOD_FIELD_NUMBER: _ClassVar[int]
+    SELECTOR_FIELD_NUMBER: _ClassVar[int]
+    KEYS_FIELD_NUMBER: _ClassVar[int]
+    VALUE_FIELD_NUMBER: _ClassVar[int]
+    TIMESTAMP_FIELD_NUMBER: _ClassVar[int]
+    EVENT_ID_FIELD_NUMBER: _ClassVar[int]
+    method: WriteMethod
+    selector: str
+    keys: _containers.ScalarMap[str, str]
+    value: _types_pb2.Value
+    timestamp: _timestamp_pb2.Timestamp
+    event_id: str
+    def __init__(self, method: _Optional[_Union[WriteMethod, str]] = ..., selector: _Optional[str] = ..., keys: _Optional[_Mapping[str, str]] = ..., value: _Optional[_Union[_types_pb2.Value, _Mapping]] = ..., timestamp: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., event_id: _Optional[str] = ...) -> None: ...
+
+class TransactionRequest(_message.Message):
+    __slots__ = ("uuid", "writes")
+    UUID_FIELD_NUMBER: _ClassVar[int]
+    WRITES_FIELD_NUMBER: _ClassVar[int]
+    uuid: str
+    writes: _containers.RepeatedCompositeFieldContainer[TransactionWrite]
+    def __init__(self, uuid: _Optional[str] = ..., writes: _Optional[_Iterable[_Union[TransactionWrite, _Mapping]]] = ...) -> None: ...
+
+class TransactionResponse(_message.Message):
+    __slots__ = ("uuid", "timestamp")
+    UUID_FIELD_NUMBER: _ClassVar[int]
+    TIMESTAMP_FIELD_NUMBER: _ClassVar[int]
+    uuid: str
+    timestamp: _timestamp_pb2.Timestamp
+    def __init__(self, uuid: _Optional[str] = ..., timestamp: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ...) -> None: ...
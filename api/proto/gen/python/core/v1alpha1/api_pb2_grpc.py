@@ -49,6 +49,11 @@ class EngineServiceStub(object):
                 request_serializer=core_dot_v1alpha1_dot_api__pb2.UpdateRequest.SerializeToString,
                 response_deserializer=core_dot_v1alpha1_dot_api__pb2.UpdateResponse.FromString,
                 )
+        self.Transaction = channel.unary_unary(
+                '/core.v1alpha1.EngineService/Transaction',
+                request_serializer=core_dot_v1alpha1_dot_api__pb2.TransactionRequest.SerializeToString,
+                response_deserializer=core_dot_v1alpha1_dot_api__pb2.TransactionResponse.FromString,
+                )
 
 
 class EngineServiceServicer(object):
@@ -101,6 +106,14 @@ class EngineServiceServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def Transaction(self, request, context):
+        """Transaction writes the given feature values of a single entity together: they're validated before any of them is
+        written, and applied by a single transaction of the state. Writes of several entities are rejected.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_EngineServiceServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -134,6 +147,11 @@ def add_EngineServiceServicer_to_server(servicer, server):
                     request_deserializer=core_dot_v1alpha1_dot_api__pb2.UpdateRequest.FromString,
                     response_serializer=core_dot_v1alpha1_dot_api__pb2.UpdateResponse.SerializeToString,
             ),
+            'Transaction': grpc.unary_unary_rpc_method_handler(
+                    servicer.Transaction,
+                    request_deserializer=core_dot_v1alpha1_dot_api__pb2.TransactionRequest.FromString,
+                    response_serializer=core_dot_v1alpha1_dot_api__pb2.TransactionResponse.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'core.v1alpha1.EngineService', rpc_method_handlers)
@@ -250,3 +268,20 @@ class EngineService(object):
             core_dot_v1alpha1_dot_api__pb2.UpdateResponse.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Transaction(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/core.v1alpha1.EngineService/Transaction',
+            core_dot_v1alpha1_dot_api__pb2.TransactionRequest.SerializeToString,
+            core_dot_v1alpha1_dot_api__pb2.TransactionResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
@@ -23,6 +23,14 @@ class Primitive(int, metaclass=_enum_type_wrapper.EnumTypeWrapper):
     PRIMITIVE_BOOL_LIST: _ClassVar[Primitive]
     PRIMITIVE_TIMESTAMP_LIST: _ClassVar[Primitive]
 
+class ValueSource(int, metaclass=_enum_type_wrapper.EnumTypeWrapper):
+    __slots__ = ()
+    VALUE_SOURCE_UNSPECIFIED: _ClassVar[ValueSource]
+    VALUE_SOURCE_STATE: _ClassVar[ValueSource]
+    VALUE_SOURCE_CACHE: _ClassVar[ValueSource]
+    VALUE_SOURCE_FALLBACK: _ClassVar[ValueSource]
+    VALUE_SOURCE_COMPUTED: _ClassVar[ValueSource]
+
 class AggrFn(int, metaclass=_enum_type_wrapper.EnumTypeWrapper):
     __slots__ = ()
     AGGR_FN_UNSPECIFIED: _ClassVar[AggrFn]
@@ -42,6 +50,11 @@ PRIMITIVE_INTEGER_LIST: Primitive
 PRIMITIVE_FLOAT_LIST: Primitive
 PRIMITIVE_BOOL_LIST: Primitive
 PRIMITIVE_TIMESTAMP_LIST: Primitive
+VALUE_SOURCE_UNSPECIFIED: ValueSource
+VALUE_SOURCE_STATE: ValueSource
+VALUE_SOURCE_CACHE: ValueSource
+VALUE_SOURCE_FALLBACK: ValueSource
+VALUE_SOURCE_COMPUTED: ValueSource
 AGGR_FN_UNSPECIFIED: AggrFn
 AGGR_FN_SUM: AggrFn
 AGGR_FN_AVG: AggrFn
@@ -120,7 +133,7 @@ class FeatureDescriptor(_message.Message):
     def __init__(self, fqn: _Optional[str] = ..., primitive: _Optional[_Union[Primitive, str]] = ..., aggr: _Optional[_Iterable[_Union[AggrFn, str]]] = ..., freshness: _Optional[_Union[_duration_pb2.Duration, _Mapping]] = ..., staleness: _Optional[_Union[_duration_pb2.Duration, _Mapping]] = ..., timeout: _Optional[_Union[_duration_pb2.Duration, _Mapping]] = ..., keep_previous: _Optional[_Union[KeepPrevious, _Mapping]] = ..., keys: _Optional[_Iterable[str]] = ..., builder: _Optional[str] = ..., data_source: _Optional[str] = ..., runtime_env: _Optional[str] = ...) -> None: ...
 
 class FeatureValue(_message.Message):
-    __slots__ = ("fqn", "keys", "value", "timestamp", "fresh")
+    __slots__ = ("fqn", "keys", "value", "timestamp", "fresh", "stale", "source")
     class KeysEntry(_message.Message):
         __slots__ = ("key", "value")
         KEY_FIELD_NUMBER: _ClassVar[int]
@@ -133,9 +146,13 @@ class FeatureValue(_message.Message):
     VALUE_FIELD_NUMBER: _ClassVar[int]
     TIMESTAMP_FIELD_NUMBER: _ClassVar[int]
     FRESH_FIELD_NUMBER: _ClassVar[int]
+    STALE_FIELD_NUMBER: _ClassVar[int]
+    SOURCE_FIELD_NUMBER: _ClassVar[int]
     fqn: str
     keys: _containers.ScalarMap[str, str]
     value: Value
     timestamp: _timestamp_pb2.Timestamp
     fresh: bool
-    def __init__(self, fqn: _Optional[str] = ..., keys: _Optional[_Mapping[str, str]] = ..., value: _Optional[_Union[Value, _Mapping]] = ..., timestamp: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., fresh: bool = ...) -> None: ...
+    stale: bool
+    source: ValueSource
+    def __init__(self, fqn: _Optional[str] = ..., keys: _Optional[_Mapping[str, str]] = ..., value: _Optional[_Union[Value, _Mapping]] = ..., timestamp: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., fresh: bool = ..., stale: bool = ..., source: _Optional[_Union[ValueSource, str]] = ...) -> None: ...
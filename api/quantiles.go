@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// QuantileSketchAccuracy is the relative accuracy of the percentile aggregations.
+const QuantileSketchAccuracy = 0.01
+
+var (
+	quantileGamma    = (1 + QuantileSketchAccuracy) / (1 - QuantileSketchAccuracy)
+	quantileLogGamma = math.Log(quantileGamma)
+)
+
+// QuantileSketch is a mergeable quantile sketch (DDSketch) that maps the sketch bins to the number of values in them.
+// Each bin covers a range of values with a relative width of QuantileSketchAccuracy, so the sketch can be stored as
+// a Hash of counters and merged by summing the counters of its bins.
+type QuantileSketch map[string]uint64
+
+// QuantileSketchBin returns the bin of a value in a QuantileSketch.
+// Positive values are prefixed with `p`, negative values with `n`, and zero is `z`.
+func QuantileSketchBin(v float64) string {
+	switch {
+	case v > 0:
+		return "p" + strconv.Itoa(quantileIndex(v))
+	case v < 0:
+		return "n" + strconv.Itoa(quantileIndex(-v))
+	default:
+		return "z"
+	}
+}
+
+func quantileIndex(v float64) int {
+	return int(math.Ceil(math.Log(v) / quantileLogGamma))
+}
+
+// binValue returns the representative value of a bin, which is within QuantileSketchAccuracy of every value in it.
+func binValue(bin string) (float64, error) {
+	if bin == "z" {
+		return 0, nil
+	}
+	if len(bin) < 2 || (bin[0] != 'p' && bin[0] != 'n') {
+		return 0, fmt.Errorf("invalid quantile sketch bin: %s", bin)
+	}
+	idx, err := strconv.Atoi(bin[1:])
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantile sketch bin %s: %w", bin, err)
+	}
+	v := 2 * math.Pow(quantileGamma, float64(idx)) / (quantileGamma + 1)
+	if bin[0] == 'n' {
+		v = -v
+	}
+	return v, nil
+}
+
+// Add adds a value to the sketch.
+func (s QuantileSketch) Add(v float64) {
+	s[QuantileSketchBin(v)]++
+}
+
+// MergeRaw merges the raw counters of a sketch (i.e. as stored in a Hash) into the sketch.
+func (s QuantileSketch) MergeRaw(raw map[string]string) error {
+	for bin, v := range raw {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid quantile sketch counter of %s: %w", bin, err)
+		}
+		s[bin] += n
+	}
+	return nil
+}
+
+// Quantile returns the approximated value of the q-quantile (0-1) of the values in the sketch.
+func (s QuantileSketch) Quantile(q float64) (float64, error) {
+	type bin struct {
+		value float64
+		count uint64
+	}
+	bins := make([]bin, 0, len(s))
+	var total uint64
+	for k, n := range s {
+		if n == 0 {
+			continue
+		}
+		v, err := binValue(k)
+		if err != nil {
+			return 0, err
+		}
+		bins = append(bins, bin{value: v, count: n})
+		total += n
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("the quantile sketch is empty")
+	}
+	sort.Slice(bins, func(i, j int) bool { return bins[i].value < bins[j].value })
+
+	rank := q * float64(total-1)
+	var cum float64
+	for _, b := range bins {
+		cum += float64(b.count)
+		if cum > rank {
+			return b.value, nil
+		}
+	}
+	return bins[len(bins)-1].value, nil
+}
+
+// HasPercentiles checks if one of the aggregation functions is a percentile.
+func HasPercentiles(fns []AggrFn) bool {
+	for _, fn := range fns {
+		if _, ok := fn.Quantile(); ok {
+			return true
+		}
+	}
+	return false
+}
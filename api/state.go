@@ -18,6 +18,7 @@ package api
 
 import (
 	"context"
+	"reflect"
 	"time"
 )
 
@@ -27,8 +28,34 @@ type Value struct {
 	Value     any       `json:"value"`
 	Timestamp time.Time `json:"timestamp"`
 	Fresh     bool      `json:"fresh"`
+	// Fallback indicates that the value is the fallback of the feature (see Fallback), since there was no fresh value.
+	Fallback bool `json:"fallback,omitempty"`
+	// Masked indicates that the value of a sensitive feature is masked (see FeatureDescriptor.Sensitive), since the
+	// caller isn't allowed to read its plain value.
+	Masked bool `json:"masked,omitempty"`
+	// Source is the path that produced the value.
+	Source ValueSource `json:"source,omitempty"`
 }
 
+// Stale indicates that the value is older than the freshness of the feature.
+func (v Value) Stale() bool {
+	return v.Value != nil && !v.Fresh
+}
+
+// ValueSource is the path that produced a feature value.
+type ValueSource string
+
+const (
+	// ValueSourceState is a value that was read from the State.
+	ValueSourceState ValueSource = "state"
+	// ValueSourceCache is a value that was read from the in-process read cache of the State.
+	ValueSourceCache ValueSource = "cache"
+	// ValueSourceFallback is the fallback of the feature (see Fallback), since there was no fresh value.
+	ValueSourceFallback ValueSource = "fallback"
+	// ValueSourceComputed is a value that was computed on the request (i.e. by the feature's builder).
+	ValueSourceComputed ValueSource = "computed"
+)
+
 // WindowResultMap is a map of AggrFn and their aggregated results
 type WindowResultMap map[AggrFn]float64
 
@@ -96,6 +123,189 @@ type State interface {
 	Ping(ctx context.Context) error
 }
 
+// Deduplicator is implemented by States (and Engines) that can track the events that were already written, so
+// redelivered events are written only once. Events are tracked per feature and entity, since one event can write the
+// same feature of several entities (i.e. the debit and the credit of a transfer).
+type Deduplicator interface {
+	// MarkEvent marks the event as written to the entity for the given TTL. It returns false if the event is already
+	// marked.
+	MarkEvent(ctx context.Context, fd FeatureDescriptor, keys Keys, eventID string, ttl time.Duration) (bool, error)
+	// UnmarkEvent removes the mark of the event, so it can be written again. i.e. when the write has failed.
+	UnmarkEvent(ctx context.Context, fd FeatureDescriptor, keys Keys, eventID string) error
+}
+
+// UsageTracker is implemented by States (and Engines) that can track when the feature was last read.
+type UsageTracker interface {
+	// TouchFeature records that the feature was read at the given time.
+	TouchFeature(ctx context.Context, fqn string, ts time.Time) error
+	// FeatureLastRead returns the last time the feature was read, or the zero time if it was never read.
+	FeatureLastRead(ctx context.Context, fqn string) (time.Time, error)
+}
+
+// FreshnessTracker is implemented by States (and Engines) that can track when the feature was last updated.
+// The updates are tracked for the whole feature (rather than per entity), so stale pipelines can be detected.
+type FreshnessTracker interface {
+	// MarkFeatureUpdated records that the feature was updated at the given time.
+	MarkFeatureUpdated(ctx context.Context, fqn string, ts time.Time) error
+	// FeatureLastUpdate returns the last time the feature was updated, or the zero time if it was never updated.
+	FeatureLastUpdate(ctx context.Context, fqn string) (time.Time, error)
+}
+
+// Purger is implemented by States (and Engines) that can remove all the stored data of a feature.
+type Purger interface {
+	// PurgeFeature removes all the values, buckets and metadata of the feature for all the entities.
+	PurgeFeature(ctx context.Context, fqn string) error
+}
+
+// MemoryUsageEstimator is implemented by States (and Engines) that can estimate the memory the values of a feature
+// take in the state store.
+type MemoryUsageEstimator interface {
+	// FeatureMemoryUsage returns the estimated number of bytes the values, buckets and sketches of the feature take.
+	FeatureMemoryUsage(ctx context.Context, fqn string) (int64, error)
+}
+
+// StateQuotaEnforcer is implemented by Engines that can reject the writes to the features of tenants that exceeded
+// their state-store memory quota.
+type StateQuotaEnforcer interface {
+	// EnforceStateQuota sets the features of the tenant whose writes are rejected with ErrQuotaExceeded. An empty list
+	// lifts the enforcement.
+	EnforceStateQuota(tenant string, fqns []string)
+}
+
+// EntityCollector is implemented by States that track the activity (writes) of the entities, and can remove the
+// values of entities that were inactive for a while.
+type EntityCollector interface {
+	// CollectInactiveEntities removes the values of the feature for the entities that had no activity in any feature
+	// with the same keys during the horizon, and returns the number of removed entities.
+	CollectInactiveEntities(ctx context.Context, fd FeatureDescriptor, horizon time.Duration) (int, error)
+}
+
+// WriteCondition is the condition of a conditional write (compare-and-set). The write is applied only if all of the
+// set conditions are met by the stored value.
+type WriteCondition struct {
+	// IfOlder applies the write only if the stored value is older than the written one (or missing), so out-of-order
+	// events don't overwrite newer values.
+	IfOlder bool `json:"ifOlder,omitempty"`
+	// Expected applies the write only if the stored value equals it. It's ignored when nil.
+	Expected any `json:"expected,omitempty"`
+}
+
+// Met checks if the condition is met by the stored value (nil if missing) for a write at the given timestamp.
+func (c WriteCondition) Met(stored *Value, ts time.Time) bool {
+	if c.IfOlder && stored != nil && !stored.Timestamp.Before(ts) {
+		return false
+	}
+	if c.Expected != nil && (stored == nil || !valuesEqual(stored.Value, c.Expected)) {
+		return false
+	}
+	return true
+}
+
+// valuesEqual compares two values of the same primitive. Timestamps are compared by their instant.
+func valuesEqual(a, b any) bool {
+	p := TypeDetect(a)
+	if p == PrimitiveTypeUnknown || p != TypeDetect(b) {
+		return false
+	}
+	if p.Scalar() {
+		return scalarsEqual(a, b)
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Len() != vb.Len() {
+		return false
+	}
+	for i := 0; i < va.Len(); i++ {
+		if !scalarsEqual(va.Index(i).Interface(), vb.Index(i).Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+func scalarsEqual(a, b any) bool {
+	if ta, ok := a.(time.Time); ok {
+		return ta.Equal(b.(time.Time))
+	}
+	return a == b
+}
+
+// ConditionalWriter is implemented by States that can set the value of a non-windowed feature only if a condition is
+// met by the stored value, atomically.
+type ConditionalWriter interface {
+	// SetIf sets the value of the feature if the condition is met, and returns false if it isn't.
+	SetIf(ctx context.Context, fd FeatureDescriptor, keys Keys, val any, ts time.Time, cond WriteCondition) (bool, error)
+}
+
+// StateWrite is a write of a feature-value to the State by one of its methods (Set, Append or Incr).
+type StateWrite struct {
+	FeatureDescriptor FeatureDescriptor
+	Method            StateMethod
+	Keys              Keys
+	Value             any
+	Timestamp         time.Time
+}
+
+// BatchWriter is implemented by States that can apply a batch of writes of non-windowed features at once (i.e. in a
+// single pipeline). Appends of list values append all of their elements.
+type BatchWriter interface {
+	// WriteBatch applies the writes, and returns the error of each of them (nil if all of them succeeded).
+	WriteBatch(ctx context.Context, writes []StateWrite) []error
+}
+
+// TransactionalWriter is implemented by States that can apply the writes of several features of one entity (i.e. of
+// one event) together, so a failure doesn't leave them inconsistent. Transactions can contain Set, Append and Incr
+// writes of non-windowed features, and WindowAdd writes of bucketed windows.
+type TransactionalWriter interface {
+	// WriteTransaction applies the writes, or none of them if any of them is invalid. Writes of several entities are
+	// rejected with ErrTransactionSpansEntities.
+	WriteTransaction(ctx context.Context, writes []StateWrite) error
+}
+
+// EntityValuesDeleter is implemented by States that can remove the values of a single entity.
+type EntityValuesDeleter interface {
+	// DeleteEntityValues removes the values, previous versions and window buckets of the feature for the entities
+	// whose `key` is `id`, including the entities of composite keys (i.e. all the merchants of a user in a feature
+	// that is keyed by both). It returns the encoded keys of the removed entities.
+	DeleteEntityValues(ctx context.Context, fd FeatureDescriptor, key, id string) ([]string, error)
+}
+
+// EntityDeletion is the result of deleting the values of an entity.
+type EntityDeletion struct {
+	EntityType string `json:"entity_type"`
+	EntityID   string `json:"entity_id"`
+	// Features are the number of removed entities of each feature (more than one for composite keys).
+	Features map[string]int `json:"features"`
+}
+
+// EntityDeleter is implemented by Engines that can remove all the values of an entity (i.e. to comply with the
+// right to be forgotten of the GDPR).
+type EntityDeleter interface {
+	// DeleteEntity removes the values of the entity from the online store for every bound feature that is keyed by
+	// the entityType (i.e. `user_id`), and records tombstones of them in the historical storage.
+	DeleteEntity(ctx context.Context, entityType, entityID string) (EntityDeletion, error)
+}
+
+// StateSnapshotRecord is a raw entry of the stored data of a feature, as exported by a StateSnapshotter. The records
+// can be restored only into a State of the same provider.
+type StateSnapshotRecord struct {
+	FQN string `json:"fqn"`
+	Key string `json:"key"`
+	// ExpireAt is the time the entry expires at, or the zero time if it doesn't expire.
+	ExpireAt time.Time `json:"expire_at,omitempty"`
+	Data     []byte    `json:"data"`
+}
+
+// StateSnapshotter is implemented by States (and Engines) that can export the stored data of features, and restore it
+// into another store (i.e. for blue/green migrations and disaster recovery of the state store).
+type StateSnapshotter interface {
+	// SnapshotFeature calls fn with every entry of the values, buckets and metadata of the feature, and returns the
+	// number of exported records.
+	SnapshotFeature(ctx context.Context, fqn string, fn func(StateSnapshotRecord) error) (int, error)
+	// RestoreSnapshot writes the records, replacing the existing entries of their keys. Records that already expired
+	// are skipped.
+	RestoreSnapshot(ctx context.Context, records []StateSnapshotRecord) error
+}
+
 // StateMethod is a method that can be used with a State.
 type StateMethod int
 
@@ -106,6 +316,7 @@ const (
 	StateMethodIncr
 	StateMethodUpdate
 	StateMethodWindowAdd
+	StateMethodDelete
 )
 
 func (s StateMethod) String() string {
@@ -122,6 +333,8 @@ func (s StateMethod) String() string {
 		return "Update"
 	case StateMethodWindowAdd:
 		return "WindowAdd"
+	case StateMethodDelete:
+		return "Delete"
 	default:
 		panic("unreachable")
 	}
@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWriteConditionMet(t *testing.T) {
+	now := time.Now()
+	stored := &Value{Value: 5, Timestamp: now}
+	tests := []struct {
+		name   string
+		cond   WriteCondition
+		stored *Value
+		ts     time.Time
+		want   bool
+	}{
+		{name: "unconditional", stored: stored, ts: now.Add(-time.Minute), want: true},
+		{name: "newer", cond: WriteCondition{IfOlder: true}, stored: stored, ts: now.Add(time.Second), want: true},
+		{name: "out of order", cond: WriteCondition{IfOlder: true}, stored: stored, ts: now.Add(-time.Second)},
+		{name: "same timestamp", cond: WriteCondition{IfOlder: true}, stored: stored, ts: now},
+		{name: "missing", cond: WriteCondition{IfOlder: true}, ts: now, want: true},
+		{name: "expected", cond: WriteCondition{Expected: 5}, stored: stored, ts: now, want: true},
+		{name: "unexpected", cond: WriteCondition{Expected: 6}, stored: stored, ts: now},
+		{name: "unexpected type", cond: WriteCondition{Expected: 5.0}, stored: stored, ts: now},
+		{name: "expected of missing", cond: WriteCondition{Expected: 5}, ts: now},
+		{
+			name:   "expected list",
+			cond:   WriteCondition{Expected: []time.Time{now.UTC()}},
+			stored: &Value{Value: []time.Time{now}, Timestamp: now},
+			ts:     now,
+			want:   true,
+		},
+		{name: "both", cond: WriteCondition{IfOlder: true, Expected: 5}, stored: stored, ts: now.Add(-time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cond.Met(tt.stored, tt.ts); got != tt.want {
+				t.Fatalf("Met() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
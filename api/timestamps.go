@@ -0,0 +1,76 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"strings"
+	"time"
+)
+
+// TimestampPolicy defines which time is used to timestamp, and bucket, the feature-values.
+type TimestampPolicy struct {
+	// ProcessingTime indicates that the values are timestamped by the time they were processed, rather than by the
+	// time their event occurred.
+	ProcessingTime bool `json:"processing_time"`
+	// Field is the compiled path of the event time in the DataSource's rows (nil to use the timestamp of the row).
+	Field *JSONPath `json:"-"`
+}
+
+// TimestampPolicyFromManifest compiles a timestamp policy. It returns nil if no policy was defined.
+func TimestampPolicyFromManifest(in *manifests.TimestampPolicy) (*TimestampPolicy, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	p := &TimestampPolicy{}
+	switch strings.ToLower(in.Time) {
+	case "", "eventtime":
+	case "processingtime":
+		p.ProcessingTime = true
+	default:
+		return nil, fmt.Errorf("unsupported timestamp policy time: %s", in.Time)
+	}
+	if in.Field != "" {
+		if p.ProcessingTime {
+			return nil, fmt.Errorf("the timestamp policy `field` can be used only with `eventTime`")
+		}
+		f, err := CompileJSONPath(in.Field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile the timestamp policy field: %w", err)
+		}
+		p.Field = f
+	}
+	return p, nil
+}
+
+// Timestamp returns the timestamp of a row by the policy, where ts is the timestamp of the row as determined by the
+// DataSource. Rows without a parsable event time field keep ts.
+func (p *TimestampPolicy) Timestamp(row map[string]any, ts time.Time) time.Time {
+	switch {
+	case p == nil:
+		return ts
+	case p.ProcessingTime:
+		return time.Now()
+	case p.Field != nil:
+		if t, err := ParseTimestamp(p.Field.Get(row)); err == nil {
+			return t
+		}
+	}
+	return ts
+}
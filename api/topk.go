@@ -0,0 +1,57 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"hash/fnv"
+	"strconv"
+)
+
+// DefaultTopK is the default number of values that the `top_k` aggregation returns.
+const DefaultTopK = 10
+
+const (
+	// CountMinDepth is the number of rows (hash functions) of the count-min sketch of the `top_k` aggregation.
+	// The probability that a count exceeds the error bound is e^-CountMinDepth.
+	CountMinDepth = 4
+	// CountMinWidth is the number of counters in every row of the count-min sketch of the `top_k` aggregation.
+	// The error of a count is at most e/CountMinWidth of the number of values.
+	CountMinWidth = 2048
+)
+
+// CountMinCells returns the counters (as `<row>:<column>`) of a value in a count-min sketch. The estimated count of
+// the value is the minimum of its counters.
+func CountMinCells(v string) []string {
+	cells := make([]string, CountMinDepth)
+	for i := range cells {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte{byte(i)})
+		_, _ = h.Write([]byte(v))
+		cells[i] = strconv.Itoa(i) + ":" + strconv.FormatUint(h.Sum64()%CountMinWidth, 10)
+	}
+	return cells
+}
+
+// TopKCandidates returns the number of heavy-hitter candidates to keep for the `top_k` aggregation of k values.
+// Keeping more candidates than k reduces the chance of missing a value that is frequent in the window, but not in
+// every bucket of it.
+func TopKCandidates(k int) int {
+	if c := k * 10; c > 100 {
+		return c
+	}
+	return 100
+}
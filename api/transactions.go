@@ -0,0 +1,43 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// FeatureWrite is a write of a feature-value by one of the write methods of the Engine (Set, Append, Incr or Update).
+type FeatureWrite struct {
+	FQN       string
+	Method    StateMethod
+	Keys      Keys
+	Value     any
+	Timestamp time.Time
+	// EventID is the idempotency key of the write (optional), see ContextKeyEventID.
+	EventID string
+}
+
+// Transactor is implemented by Engines that can write several features as a transaction, i.e. when one event updates
+// several features of the same entity, so a partial failure doesn't leave the features inconsistent.
+type Transactor interface {
+	// WriteTransaction applies the writes of a single entity together. Each write is validated by the pipeline of its
+	// feature before any of them is applied, so an invalid write fails the whole transaction, and the valid writes are
+	// applied by a single transaction of the State (see TransactionalWriter). Writes of several entities are rejected
+	// with ErrTransactionSpansEntities.
+	WriteTransaction(ctx context.Context, writes []FeatureWrite) error
+}
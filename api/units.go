@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrIncompatibleUnits is returned when converting between units of different dimensions.
+var ErrIncompatibleUnits = fmt.Errorf("incompatible units")
+
+type unitDef struct {
+	dimension string
+	// factor is the multiplier to convert a value of this unit to the dimension's base unit
+	factor float64
+}
+
+// knownUnits is the list of units that can be converted automatically.
+// Units that are not listed here (i.e. currencies) are still allowed, but can only be "converted" to themselves.
+var knownUnits = map[string]unitDef{
+	// time (base: seconds)
+	"ns":  {"time", 1e-9},
+	"us":  {"time", 1e-6},
+	"ms":  {"time", 1e-3},
+	"s":   {"time", 1},
+	"min": {"time", 60},
+	"h":   {"time", 3600},
+	"d":   {"time", 86400},
+
+	// distance (base: meters)
+	"mm": {"distance", 1e-3},
+	"cm": {"distance", 1e-2},
+	"m":  {"distance", 1},
+	"km": {"distance", 1e3},
+	"in": {"distance", 0.0254},
+	"ft": {"distance", 0.3048},
+	"mi": {"distance", 1609.344},
+
+	// mass (base: grams)
+	"mg": {"mass", 1e-3},
+	"g":  {"mass", 1},
+	"kg": {"mass", 1e3},
+	"lb": {"mass", 453.59237},
+
+	// data size (base: bytes)
+	"b":  {"data", 1},
+	"kb": {"data", 1 << 10},
+	"mb": {"data", 1 << 20},
+	"gb": {"data", 1 << 30},
+	"tb": {"data", 1 << 40},
+}
+
+// NormalizeUnit returns the canonical representation of a unit.
+func NormalizeUnit(unit string) string {
+	u := strings.TrimSpace(unit)
+	if _, ok := knownUnits[strings.ToLower(u)]; ok {
+		return strings.ToLower(u)
+	}
+	return u
+}
+
+// UnitsCompatible checks if a value can be converted between the given units.
+func UnitsCompatible(from, to string) bool {
+	from, to = NormalizeUnit(from), NormalizeUnit(to)
+	if from == to {
+		return true
+	}
+	f, ok := knownUnits[from]
+	if !ok {
+		return false
+	}
+	t, ok := knownUnits[to]
+	if !ok {
+		return false
+	}
+	return f.dimension == t.dimension
+}
+
+// ConvertUnit converts a numeric value (scalar or list) from one unit to another.
+// Integer values are converted to float64 unless the units are identical.
+func ConvertUnit(val any, from, to string) (any, error) {
+	from, to = NormalizeUnit(from), NormalizeUnit(to)
+	if from == to || val == nil {
+		return val, nil
+	}
+	if !UnitsCompatible(from, to) {
+		return nil, fmt.Errorf("%w: cannot convert `%s` to `%s`", ErrIncompatibleUnits, from, to)
+	}
+	ratio := knownUnits[from].factor / knownUnits[to].factor
+
+	switch v := val.(type) {
+	case int:
+		return float64(v) * ratio, nil
+	case float64:
+		return v * ratio, nil
+	case []int:
+		ret := make([]float64, len(v))
+		for i, n := range v {
+			ret[i] = float64(n) * ratio
+		}
+		return ret, nil
+	case []float64:
+		ret := make([]float64, len(v))
+		for i, n := range v {
+			ret[i] = n * ratio
+		}
+		return ret, nil
+	case WindowResultMap:
+		ret := make(WindowResultMap)
+		for fn, n := range v {
+			if fn == AggrFnCount {
+				ret[fn] = n
+				continue
+			}
+			ret[fn] = n * ratio
+		}
+		return ret, nil
+	default:
+		return nil, fmt.Errorf("%w: unit conversion is supported only for numeric values, got %T", ErrUnsupportedPrimitiveError, val)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"path"
+)
+
+// AccessVerb is an action on the values of features that is granted by an AccessPolicy.
+// +kubebuilder:validation:Enum=read;write;admin;decrypt;unmask
+type AccessVerb string
+
+const (
+	// AccessVerbRead allows reading the values of the features (Get).
+	AccessVerbRead AccessVerb = "read"
+	// AccessVerbWrite allows writing the values of the features (Set, Append, Incr and Update).
+	AccessVerbWrite AccessVerb = "write"
+	// AccessVerbAdmin allows the admin endpoints of the accessor on the features (i.e. inspecting windows and
+	// replaying dead-letters). Admin endpoints that aren't scoped to a feature require `admin` on all the features
+	// (`*`) of the Core's namespace.
+	AccessVerbAdmin AccessVerb = "admin"
+	// AccessVerbDecrypt allows reading the decrypted values of encrypted features (see FeatureSpec.Encryption), in
+	// addition to `read`.
+	AccessVerbDecrypt AccessVerb = "decrypt"
+	// AccessVerbUnmask allows reading the plain values of sensitive features (see FeatureSpec.PII), in addition to
+	// `read`. Callers that aren't granted with it are served masked values.
+	AccessVerbUnmask AccessVerb = "unmask"
+)
+
+// AccessPolicySpec defines the identities that can access the features of the namespace, and what they can do.
+type AccessPolicySpec struct {
+	// Subjects are the identities that are granted by the policy.
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Subjects"
+	Subjects AccessSubjects `json:"subjects"`
+
+	// Rules are the verbs that are granted on the features of the namespace.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Rules"
+	Rules []AccessRule `json:"rules"`
+}
+
+// AccessSubjects are the identities that are granted by an AccessPolicy.
+type AccessSubjects struct {
+	// APIKeys are references to keys of Secrets (in the namespace of the policy) that hold API keys.
+	// The API keys are sent in the `X-API-Key` header (or the `x-api-key` gRPC metadata).
+	// +optional
+	// +nullable
+	APIKeys []corev1.SecretKeySelector `json:"apiKeys,omitempty"`
+
+	// Users are the usernames of the bearer tokens: the configured claim of OIDC tokens (`sub` by default) with the
+	// OIDC username prefix (`oidc:` by default, i.e. `oidc:alice`), or the username of Kubernetes ServiceAccount tokens
+	// (i.e. `system:serviceaccount:<namespace>:<name>`).
+	// +optional
+	// +nullable
+	Users []string `json:"users,omitempty"`
+
+	// Groups are the groups of the bearer tokens: the configured claim of OIDC tokens (`groups` by default) with the
+	// OIDC groups prefix (`oidc:` by default), or the groups of Kubernetes ServiceAccount tokens (i.e.
+	// `system:serviceaccounts:<namespace>`).
+	// +optional
+	// +nullable
+	Groups []string `json:"groups,omitempty"`
+}
+
+// AccessRule grants verbs on a set of features.
+type AccessRule struct {
+	// Features are the names of the features (in the namespace of the policy) that the rule applies to.
+	// Glob patterns are supported (i.e. `fraud_*`), and `*` matches all the features of the namespace.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	Features []string `json:"features"`
+
+	// Verbs are the actions that are granted on the features.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	Verbs []AccessVerb `json:"verbs"`
+}
+
+// Allows checks if the rule grants the verb on the feature with the given name.
+func (in AccessRule) Allows(verb AccessVerb, name string) bool {
+	granted := false
+	for _, v := range in.Verbs {
+		if v == verb {
+			granted = true
+			break
+		}
+	}
+	if !granted {
+		return false
+	}
+	for _, pattern := range in.Features {
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:categories=datascience,shortName=ap
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +operator-sdk:csv:customresourcedefinitions:displayName="Access Policy",resources={{Deployment,v1,raptor-controller-core}}
+
+// AccessPolicy is the Schema for the accesspolicies API.
+// It grants identities (API keys, OIDC users and groups, or Kubernetes ServiceAccounts) access to read or write the
+// values of the features of its namespace via the accessor. The policies are enforced only when the accessor
+// authentication is enabled.
+type AccessPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AccessPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AccessPolicyList contains a list of AccessPolicy
+type AccessPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AccessPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AccessPolicy{}, &AccessPolicyList{})
+}
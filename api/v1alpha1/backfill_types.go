@@ -0,0 +1,178 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BackfillPhase is the phase of a Backfill.
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+type BackfillPhase string
+
+const (
+	BackfillPending   BackfillPhase = "Pending"
+	BackfillRunning   BackfillPhase = "Running"
+	BackfillSucceeded BackfillPhase = "Succeeded"
+	BackfillFailed    BackfillPhase = "Failed"
+)
+
+// BackfillSpec defines the historical data to replay through a Feature
+type BackfillSpec struct {
+	// Feature is the reference to the Feature to backfill.
+	// If the namespace is omitted, the namespace of the Backfill is used.
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Feature"
+	Feature ResourceReference `json:"feature"`
+
+	// Source is the bounded historical source to replay.
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Source"
+	Source BackfillSource `json:"source"`
+
+	// From filters out rows with an earlier timestamp.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="From"
+	From *metav1.Time `json:"from,omitempty"`
+
+	// To filters out rows with a later timestamp.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="To"
+	To *metav1.Time `json:"to,omitempty"`
+
+	// WarmOnline writes the values that are still fresh to the online store as well.
+	// By default, the values are only written to the historical storage.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Warm Online Store"
+	WarmOnline bool `json:"warmOnline,omitempty"`
+
+	// RateLimit is the maximum number of rows to replay per second.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1000
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Rate Limit"
+	RateLimit int `json:"rateLimit,omitempty"`
+}
+
+// BackfillSource is a bounded historical source.
+type BackfillSource struct {
+	// Kind of the source. i.e. `batch` for files in an object store, or `snowflake` for a warehouse query.
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Kind"
+	Kind string `json:"kind"`
+
+	// Config of the source. The config keys are specific to the source kind.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Config"
+	Config []ConfigVar `json:"config,omitempty"`
+}
+
+// BackfillStatus defines the observed state of Backfill
+type BackfillStatus struct {
+	// Phase of the Backfill.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Phase BackfillPhase `json:"phase,omitempty"`
+
+	// ObservedGeneration is the generation of the spec that is replayed.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Processed is the number of rows that were replayed.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Processed int64 `json:"processed,omitempty"`
+
+	// Skipped is the number of rows that were out of the time range.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Skipped int64 `json:"skipped,omitempty"`
+
+	// Cursor is the position of the last replayed row in the source, so the Backfill can be resumed after a restart.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Cursor string `json:"cursor,omitempty"`
+
+	// StartedAt is the time the Backfill started.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// CompletedAt is the time the Backfill succeeded or failed.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// Message describes why the Backfill failed.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories=datascience
+// +kubebuilder:printcolumn:name="Feature",type=string,JSONPath=`.spec.feature.name`
+// +kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.spec.source.kind`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Processed",type=integer,JSONPath=`.status.processed`
+// +operator-sdk:csv:customresourcedefinitions:displayName="Backfill",resources={{Deployment,v1,raptor-controller-core}}
+
+// Backfill is the Schema for the backfills API.
+// It replays a bounded historical source through a Feature's builder, and writes the values to the historical
+// storage (and optionally to the online store). A Backfill runs once; changing its spec starts it over.
+type Backfill struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackfillSpec   `json:"spec,omitempty"`
+	Status BackfillStatus `json:"status,omitempty"`
+}
+
+// FeatureReference returns the reference of the backfilled Feature.
+func (in *Backfill) FeatureReference() ResourceReference {
+	ref := in.Spec.Feature
+	if ref.Namespace == "" {
+		ref.Namespace = in.GetNamespace()
+	}
+	return ref
+}
+
+// ParseConfig parses the config of the Backfill's source, and extracts the secrets, into a map of key-value pairs
+func (in *Backfill) ParseConfig(ctx context.Context, rdr client.Reader) (ParsedConfig, error) {
+	return parseConfig(ctx, in.Spec.Source.Config, in.GetNamespace(), rdr)
+}
+
+// +kubebuilder:object:root=true
+
+// BackfillList contains a list of Backfill
+type BackfillList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Backfill `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Backfill{}, &BackfillList{})
+}
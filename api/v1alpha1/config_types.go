@@ -46,7 +46,8 @@ type ConfigVar struct {
 	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
-// ParsedConfig is a parsed configuration
+// ParsedConfig is a parsed configuration. Values that are originated from secrets are base64 encoded (see
+// DecodeSecrets).
 type ParsedConfig map[string]string
 
 // Unmarshal is unmarshalling the config into a Struct. Make sure that the tags
@@ -123,3 +124,21 @@ func parseConfig(ctx context.Context, pairs []ConfigVar, ns string, rdr client.R
 	}
 	return cfg, nil
 }
+
+// DecodeSecrets decodes the values of the config that are originated from secrets. The parsing keeps these values
+// base64 encoded, so they can be passed as-is to the consumers that decode them (i.e. model servers and external
+// runners).
+func (cfg ParsedConfig) DecodeSecrets(pairs []ConfigVar) error {
+	for _, cv := range pairs {
+		v, ok := cfg[cv.Name]
+		if !ok || cv.Value != "" || cv.SecretKeyRef == nil {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return fmt.Errorf("failed to decode the value of %s from secret %s: %w", cv.Name, cv.SecretKeyRef.Name, err)
+		}
+		cfg[cv.Name] = string(b)
+	}
+	return nil
+}
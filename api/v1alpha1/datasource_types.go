@@ -73,6 +73,60 @@ type DataSourceSpec struct {
 	// +nullable
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Schema"
 	Schema json.RawMessage `json:"schema,omitempty"`
+
+	// Mapping defines declarative extractions of the keys, timestamp and fields from the DataSource's rows.
+	// Features can use a mapped field as their value directly (via `builder.field`), without writing a program.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Mapping"
+	Mapping *DataSourceMapping `json:"mapping,omitempty"`
+
+	// TimestampPolicy defines which time is used to timestamp, and bucket, the values of the DataSource's features.
+	// Features can override it with their own policy.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Timestamp Policy"
+	TimestampPolicy *TimestampPolicy `json:"timestampPolicy,omitempty"`
+
+	// Transactional writes the values that are computed from each row to the DataSource's features in a single
+	// transaction, so a partial failure doesn't leave the features of the row's entity inconsistent.
+	// Values that are written by the features' programs themselves aren't part of the transaction, and a transaction
+	// can't span entities, so the features should be keyed by the same entity.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Transactional"
+	Transactional bool `json:"transactional,omitempty"`
+}
+
+// DataSourceMapping defines JSONPath expressions that are evaluated on every row of the DataSource.
+// i.e. `$.user.id`, `$.items[0].price` or `$['event-time']`
+type DataSourceMapping struct {
+	// Keys maps key fields (entity identifiers) to JSONPath expressions.
+	// +optional
+	// +nullable
+	Keys map[string]string `json:"keys,omitempty"`
+
+	// Timestamp is a JSONPath expression of the row's timestamp (RFC3339 or unix timestamp).
+	// +optional
+	Timestamp string `json:"timestamp,omitempty"`
+
+	// Fields maps field names to JSONPath expressions.
+	// +optional
+	// +nullable
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// TimestampPolicy defines which time is used to timestamp, and bucket, the feature-values.
+type TimestampPolicy struct {
+	// Time defines the time semantics.
+	// `eventTime` (default) uses the time the event occurred, while `processingTime` uses the time it was processed.
+	// +optional
+	// +kubebuilder:validation:Enum=eventTime;processingTime
+	Time string `json:"time,omitempty"`
+
+	// Field is a JSONPath expression of the event time in the DataSource's rows (RFC3339 or unix timestamp).
+	// It's applicable only to `eventTime`, and defaults to the timestamp of the row as determined by the DataSource.
+	// +optional
+	Field string `json:"field,omitempty"`
 }
 
 // ResourceReference represents a resource reference. It has enough information to retrieve resource in any namespace.
@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EntityKeyFormat is the format of the IDs of an entity key
+// +kubebuilder:validation:Enum=string;integer;uuid
+type EntityKeyFormat string
+
+const (
+	// EntityKeyFormatString accepts any (non-empty) ID.
+	EntityKeyFormatString EntityKeyFormat = "string"
+	// EntityKeyFormatInteger accepts IDs of decimal integers (i.e. `42`).
+	EntityKeyFormatInteger EntityKeyFormat = "integer"
+	// EntityKeyFormatUUID accepts IDs of UUIDs (i.e. `123e4567-e89b-12d3-a456-426614174000`).
+	EntityKeyFormatUUID EntityKeyFormat = "uuid"
+)
+
+// EntityKey is a key field of an entity, and the format of its IDs
+type EntityKey struct {
+	// Name is the name of the key, as it's used in the keys of the Features (i.e. `user_id`).
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name"
+	Name string `json:"name"`
+
+	// Format is the format of the IDs. Defaults to `string`.
+	// +optional
+	// +kubebuilder:default=string
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Format"
+	Format EntityKeyFormat `json:"format,omitempty"`
+
+	// Pattern is a regular expression the IDs must (fully) match, in addition to the format (i.e. `^u-[0-9]{8}$`).
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Pattern"
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// EntitySpec defines the key fields of an entity
+type EntitySpec struct {
+	// Description of the entity.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Description"
+	Description string `json:"description,omitempty"`
+
+	// Keys are the key fields that identify the entity, in the order of the keys of its Features.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Keys"
+	Keys []EntityKey `json:"keys"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:categories=datascience
+// +operator-sdk:csv:customresourcedefinitions:displayName="Entity",resources={{Deployment,v1,raptor-controller-core}}
+
+// Entity is the Schema for the entities API.
+// It's a type of entity (i.e. a user or an account) that Features are keyed by. Features that reference an entity
+// are keyed by its keys, and the IDs of their keys are validated by the key formats when the values are written and
+// read, so mismatched join keys are caught early.
+type Entity struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec EntitySpec `json:"spec,omitempty"`
+}
+
+// FQN returns the Fully Qualified Name of the entity.
+func (in *Entity) FQN() string {
+	return FQNFormatter(in.GetNamespace(), in.GetName())
+}
+
+// KeyNames returns the names of the keys of the entity.
+func (in *Entity) KeyNames() []string {
+	ret := make([]string, len(in.Spec.Keys))
+	for i, k := range in.Spec.Keys {
+		ret[i] = k.Name
+	}
+	return ret
+}
+
+// +kubebuilder:object:root=true
+
+// EntityList contains a list of Entity
+type EntityList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Entity `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Entity{}, &EntityList{})
+}
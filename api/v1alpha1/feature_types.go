@@ -21,12 +21,17 @@ package v1alpha1
 import (
 	"encoding/json"
 	"fmt"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"strings"
 )
 
 // AggrFn defines the type of aggregation
-// +kubebuilder:validation:Enum=count;min;max;sum;avg;mean
+// `count_distinct` is an approximation (HyperLogLog), and `approx_distinct` is an alias of it.
+// The percentiles (`p50`, `p90`, `p95` and `p99`) are approximations (DDSketch) with a relative error of 1%.
+// `top_k` returns the most frequent values (see FeatureBuilder.AggrTopK), and can't be combined with other functions.
+// +kubebuilder:validation:Enum=count;min;max;sum;avg;mean;count_distinct;approx_distinct;duration;p50;median;p90;p95;p99;top_k
 type AggrFn string
 
 // PrimitiveType defines the type of primitive
@@ -64,10 +69,17 @@ type FeatureSpec struct {
 	KeepPrevious *KeepPrevious `json:"keepPrevious,omitempty"`
 
 	// Keys defines the list of keys that are required to calculate the feature value.
-	// +kubebuilder:validation:Required
+	// Defaults to the keys of the Entity, when it's set.
+	// +optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Keys"
 	Keys []string `json:"keys"`
 
+	// Entity is the name of the Entity (of the Feature's namespace) the Feature is keyed by. The IDs of the keys are
+	// validated by the formats of the Entity's keys when the values are written and read.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Entity"
+	Entity string `json:"entity,omitempty"`
+
 	// DataSource is a reference for the DataSource that this Feature is associated with
 	// +optional
 	// +nullable
@@ -78,6 +90,297 @@ type FeatureSpec struct {
 	// +kubebuilder:validation:Required
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Builder"
 	Builder FeatureBuilder `json:"builder"`
+
+	// TimestampNormalization defines how timestamp values are stored and served.
+	// `utc` (default) converts the timestamps to UTC, while `original` preserves the original zone offset.
+	// The offset is preserved only within the Core, since the values that are served by the accessor are in UTC.
+	// +optional
+	// +kubebuilder:validation:Enum=utc;original
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Timestamp Normalization"
+	TimestampNormalization string `json:"timestampNormalization,omitempty"`
+
+	// TimestampPolicy defines which time is used to timestamp, and bucket, the feature-values.
+	// Defaults to the policy of the DataSource.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Timestamp Policy"
+	TimestampPolicy *TimestampPolicy `json:"timestampPolicy,omitempty"`
+
+	// Unit defines the unit of a numeric feature-value (i.e. `ms`, `km`, `USD`).
+	// Known units of the same dimension are automatically converted when requested by a Model.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Unit"
+	Unit string `json:"unit,omitempty"`
+
+	// Historical defines whether the feature-values should be recorded to the historical storage.
+	// Defaults to true, or to false for features in sandbox namespaces.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Historical"
+	Historical *bool `json:"historical,omitempty"`
+
+	// WriteSampling limits the writes of the feature-values of each entity, to reduce the volume of very chatty
+	// sources. It's applied to sets and updates of non-windowed features.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Write Sampling"
+	WriteSampling *WriteSampling `json:"writeSampling,omitempty"`
+
+	// WriteBatching buffers the writes of the feature-values for a short window, and flushes them to the state store
+	// in pipelined batches, to raise the ingestion throughput of high-volume features. The writes of each entity
+	// within the window are coalesced. It's applied to non-windowed features, when the state store supports it.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Write Batching"
+	WriteBatching *WriteBatching `json:"writeBatching,omitempty"`
+
+	// WriteMode defines when a write of a feature-value is acknowledged.
+	// `sync` (default) acknowledges the write once it's persisted in the state store, while `async` acknowledges it
+	// once it's appended to the local write-ahead log of the instance, and applies it to the state store in batches
+	// in the background. Async writes aren't visible to reads until they're applied. It's applied to non-windowed
+	// features, when the write-ahead log is enabled.
+	// +optional
+	// +kubebuilder:validation:Enum=sync;async
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Write Mode"
+	WriteMode string `json:"writeMode,omitempty"`
+
+	// ListPolicy bounds the values of list features, and defines how the appended elements are merged into them.
+	// It's enforced by the state store on every write of the list.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="List Policy"
+	ListPolicy *ListPolicy `json:"listPolicy,omitempty"`
+
+	// Validations defines data-quality rules the feature-values must satisfy. They are evaluated on every write, after
+	// the builder computed the value. The elements of list values are validated individually.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Validations"
+	Validations *Validations `json:"validations,omitempty"`
+
+	// Fallback defines the value that is returned when there is no fresh value for the entity. Fallback responses are
+	// marked, so clients can tell them apart. It can't be used with windowed features.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Fallback"
+	Fallback *Fallback `json:"fallback,omitempty"`
+
+	// Encryption defines the field-level encryption of the feature-values. The values are envelope-encrypted before
+	// they're written to the state and the historical storage, and are decrypted only by the accessor, for callers that
+	// are granted with the `decrypt` verb. The values can only be set or updated (not appended or incremented), and it
+	// can't be used with windowed features.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Encryption"
+	Encryption *FeatureEncryption `json:"encryption,omitempty"`
+
+	// PII marks the feature-values as personally identifiable information. The values of PII features are masked
+	// (see Masking) for callers that aren't granted with the `unmask` verb, and the tag is propagated to the historical
+	// storage.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="PII"
+	PII bool `json:"pii,omitempty"`
+
+	// Classification is the data classification of the feature-values. `confidential` and `restricted` values are
+	// masked like PII values.
+	// +optional
+	// +kubebuilder:validation:Enum=public;internal;confidential;restricted
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Classification"
+	Classification string `json:"classification,omitempty"`
+
+	// Masking defines how the values of PII and confidential features are masked: `hash` (default) serves a keyed
+	// hash of the value, so masked values can still be joined or counted, and `redact` serves no value.
+	// +optional
+	// +kubebuilder:validation:Enum=hash;redact
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Masking"
+	Masking string `json:"masking,omitempty"`
+
+	// Retention defines how long the feature-values are kept in the historical storage (and optionally in the state).
+	// The historical data is pruned by whole partitions, so records are kept until their partition is entirely older
+	// than the horizon.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Retention"
+	Retention *Retention `json:"retention,omitempty"`
+
+	// Warmup bulk-loads the latest historical value of each entity into the state once the Feature is created, so
+	// serving doesn't start cold when the feature was backfilled. Values that were written to the state since are
+	// kept. It can't be used with windowed features.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Warmup"
+	Warmup *Warmup `json:"warmup,omitempty"`
+
+	// Owner is the team or the person that owns the Feature (i.e. a team handle or an email), so it can be reached by
+	// the teams that discover the Feature in the catalog.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Owner"
+	Owner string `json:"owner,omitempty"`
+
+	// Description describes what the feature-value represents, and how it's computed.
+	// Defaults to the `a8r.io/description` annotation.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Description"
+	Description string `json:"description,omitempty"`
+
+	// Tags categorize the Feature in the catalog (i.e. `fraud` or `payments`).
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tags"
+	Tags []string `json:"tags,omitempty"`
+
+	// Links are references to external resources about the Feature (i.e. dashboards, docs or runbooks).
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Links"
+	Links []FeatureLink `json:"links,omitempty"`
+}
+
+// FeatureLink is a named reference to an external resource about a Feature.
+type FeatureLink struct {
+	// Name of the link (i.e. `dashboard`).
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name"
+	Name string `json:"name"`
+
+	// URL of the resource.
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="URL"
+	URL string `json:"url"`
+}
+
+type Retention struct {
+	// Horizon defines the age of the historical records to remove.
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Horizon"
+	Horizon metav1.Duration `json:"horizon"`
+
+	// Online defines whether the online values of the entities that weren't active during the horizon are removed
+	// as well. It's ignored for windowed features.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Online"
+	Online bool `json:"online,omitempty"`
+
+	// DryRun reports what would be pruned in the Feature's status, without removing it.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Dry Run"
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+type Warmup struct {
+	// Lookback limits the loaded values to the ones that were written within the lookback. Defaults to the whole
+	// history.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Lookback"
+	Lookback metav1.Duration `json:"lookback,omitempty"`
+}
+
+type FeatureEncryption struct {
+	// Provider is the key manager that wraps the data keys of the values (i.e. `local` or `vault`).
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Provider"
+	Provider string `json:"provider"`
+
+	// KeyID is the key-encryption key of the provider that wraps the data keys (i.e. the name of a Vault transit key).
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Key ID"
+	KeyID string `json:"keyId"`
+}
+
+type Fallback struct {
+	// Default is the value that is returned when there is no value for the entity. It must match the primitive of the
+	// Feature (i.e. a list of numbers for `[]float`). Timestamps are expressed as RFC3339 strings.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Default"
+	Default *runtime.RawExtension `json:"default,omitempty"`
+
+	// LastKnown defines for how long after it went stale the last known value is returned, before falling back to
+	// the Default. The values are kept in the state for this long after their staleness.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Last Known"
+	LastKnown *metav1.Duration `json:"lastKnown,omitempty"`
+}
+
+type Validations struct {
+	// Min defines the minimal numeric value.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Min"
+	Min *resource.Quantity `json:"min,omitempty"`
+
+	// Max defines the maximal numeric value.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Max"
+	Max *resource.Quantity `json:"max,omitempty"`
+
+	// Regex defines a regular expression (RE2 syntax) that string values must match.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Regex"
+	Regex string `json:"regex,omitempty"`
+
+	// NotNull defines whether null values are invalid.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Not Null"
+	NotNull bool `json:"notNull,omitempty"`
+
+	// Allowed defines the set of the allowed values, compared by their string representation (i.e. `true` or `42`).
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Allowed"
+	Allowed []string `json:"allowed,omitempty"`
+
+	// OnViolation defines what happens to an invalid value.
+	// `drop` (default) drops the value, `clamp` writes numeric values clamped to the min/max range (and drops values
+	// that violate other rules), and `warn` writes the value as-is.
+	// Violations are always counted and published as events.
+	// +optional
+	// +kubebuilder:validation:Enum=drop;clamp;warn
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="On Violation"
+	OnViolation string `json:"onViolation,omitempty"`
+}
+
+type WriteSampling struct {
+	// Interval defines the minimal time between two writes of the feature-value of an entity.
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Interval"
+	Interval metav1.Duration `json:"interval"`
+
+	// Keep defines which of the values written during the interval is persisted.
+	// `latest` (default) persists the latest value at the end of the interval, while `first` persists the first value
+	// immediately and drops the rest.
+	// +optional
+	// +kubebuilder:validation:Enum=latest;first
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Keep"
+	Keep string `json:"keep,omitempty"`
+}
+
+type WriteBatching struct {
+	// Window defines the maximal time a write is buffered before it's flushed.
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Window"
+	Window metav1.Duration `json:"window"`
+
+	// MaxSize defines the maximal number of buffered entities. The buffer is flushed early once it's reached.
+	// Defaults to 1000.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Max Size"
+	MaxSize int `json:"maxSize,omitempty"`
+}
+
+type ListPolicy struct {
+	// MaxLength defines the maximal number of elements of the list. The oldest elements are removed once it's
+	// exceeded. Defaults to unbounded.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Max Length"
+	MaxLength int `json:"maxLength,omitempty"`
+
+	// Dedup removes the previous occurrences of an appended element, so every element is kept once, at the position
+	// of its latest append.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Dedup"
+	Dedup bool `json:"dedup,omitempty"`
+
+	// UpsertKeyDelimiter enables upsert semantics for lists of strings whose elements are in the form of
+	// `<key><delimiter><value>` (i.e. `sku_42:3` with `:`). An appended element replaces the elements with the same
+	// key, so every key is kept once, at the position of its latest append.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Upsert Key Delimiter"
+	UpsertKeyDelimiter string `json:"upsertKeyDelimiter,omitempty"`
 }
 
 type KeepPrevious struct {
@@ -113,21 +416,120 @@ type FeatureBuilder struct {
 	// +nullable
 	AggrGranularity metav1.Duration `json:"aggrGranularity,omitempty"`
 
+	// AggrSlide turns the aggregation into a sliding (hopping) window of the Feature's staleness, that advances every
+	// AggrSlide. i.e. a staleness of `30m` with a slide of `1m` is "the last 30 minutes, updated every minute".
+	// The window result includes only complete buckets, so it must be a multiple of the granularity, and the staleness
+	// must be a multiple of it. When unset, the window includes the current (open) bucket.
+	// +optional
+	// +nullable
+	AggrSlide metav1.Duration `json:"aggrSlide,omitempty"`
+
+	// AggrSessionGap turns the aggregation into a session window: the events of an entity are aggregated until there is
+	// no event for AggrSessionGap, and then the session is closed, and a new one begins with the next event.
+	// i.e. a gap of `30m` with `count` is "the number of events in the current session".
+	// The `duration` aggregation returns the time (in seconds) between the first and the last event of the session.
+	// Session windows are not recorded to the historical storage, and can't be used with AggrSlide.
+	// +optional
+	// +nullable
+	AggrSessionGap metav1.Duration `json:"aggrSessionGap,omitempty"`
+
+	// AggrHalfLife turns the aggregation into an exponentially decayed window: rather than keeping buckets, the
+	// aggregations of an entity are decayed by half every AggrHalfLife, so recent events weigh more than older ones.
+	// i.e. a half-life of `1d` with `sum` is "the sum of the amounts, where an amount of yesterday counts as half".
+	// Only `sum`, `count` and `avg` (the decayed sum divided by the decayed count) are supported. The aggregations
+	// expire after the staleness without events.
+	// Decayed windows are not recorded to the historical storage, and can't be used with AggrSlide or AggrSessionGap.
+	// +optional
+	// +nullable
+	AggrHalfLife metav1.Duration `json:"aggrHalfLife,omitempty"`
+
+	// AggrTopK is the number of values that the `top_k` aggregation returns (default 10). The `top_k` aggregation counts
+	// string values, and returns the most frequent of them in the window, from the most frequent to the least, so the
+	// Feature's primitive must be `[]string`. i.e. `top_k` with AggrTopK of `5` over `7d` is "the top 5 values in the
+	// last 7 days". The counts are approximated by a count-min sketch.
+	// Top-K windows are not recorded to the historical storage.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	AggrTopK int `json:"aggrTopK,omitempty"`
+
 	// Runtime defines the runtime virtualenv to use for running the python computation.
+	// A RuntimeEnvironment of the Feature's namespace by this name takes precedence over the runtimes of the Core.
 	// +optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="RuntimeManager"
 	Runtime string `json:"runtime,omitempty"`
 
 	// Packages defines the list of python packages to install in the runtime virtualenv.
+	// Packages aren't installed in RuntimeEnvironments, so they must be part of the environment's requirements.
 	// +optional
 	// +nullable
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Packages"
 	Packages []string `json:"packages,omitempty"`
 
 	// Code defines a Python processing code to use to build the feature-value.
-	// +kubebuilder:validation:Required
+	// Code is required unless `field`, `sql`, `cel` or `wasm` is set.
+	// +optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Python Expression"
-	Code string `json:"code"`
+	Code string `json:"code,omitempty"`
+
+	// Field is a field of the DataSource's rows (or a field of the DataSource's mapping) that is used as
+	// the feature-value as is, without running a program.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Field"
+	Field string `json:"field,omitempty"`
+
+	// SQL is a SQL-like expression that is evaluated over the DataSource's events to build the feature-value,
+	// i.e. `SELECT sum(amount) FROM payments WHERE status = 'approved'`.
+	// A selected aggregation must be declared in `aggr` as well. Setting SQL defaults the builder kind to `sql`.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="SQL Expression"
+	SQL string `json:"sql,omitempty"`
+
+	// CEL is a CEL (Common Expression Language) expression that is evaluated over the DataSource's events to build
+	// the feature-value, i.e. `row.status == 'approved' ? row.amount : null`.
+	// Setting CEL defaults the builder kind to `cel`.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="CEL Expression"
+	CEL string `json:"cel,omitempty"`
+
+	// Wasm defines a compiled WebAssembly module to use to build the feature-value.
+	// Setting Wasm defaults the builder kind to `wasm`.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="WebAssembly Module"
+	Wasm *WasmModule `json:"wasm,omitempty"`
+
+	// EventID is a field of the DataSource's rows that uniquely identifies an event. Events with an ID that was
+	// already written are ignored, so redeliveries are aggregated only once. Setting EventID defaults the builder
+	// kind to `aggregation`.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Event ID Field"
+	EventID string `json:"eventId,omitempty"`
+
+	// AllowedLateness defines how late an event of a windowed feature can arrive and still be aggregated. An event is
+	// late compared to the watermark of the DataSource, which is the latest event time that was written to the
+	// DataSource's windowed features. Defaults to the dead buckets' grace period (10m).
+	// Late events that land in a bucket that was already recorded to the historical storage correct the bucket's
+	// record, and events that arrive after the allowed lateness are dropped and counted.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Allowed Lateness"
+	AllowedLateness metav1.Duration `json:"allowedLateness,omitempty"`
+
+	// ExecutionTimeout is the deadline of each invocation of the builder's program. Invocations that exceed it fail
+	// (and are sent to the dead-letter queue). Unlimited when unset.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Execution Timeout"
+	ExecutionTimeout metav1.Duration `json:"executionTimeout,omitempty"`
+
+	// CircuitBreaker is the number of consecutive failed invocations of the builder's program after which the
+	// Feature is marked as `Degraded`, and its invocations are skipped (and sent to the dead-letter queue) rather
+	// than retried over and over. The program is invoked again once in a minute, and the circuit is closed when it
+	// succeeds, or when the Feature is changed. Disabled when 0.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Circuit Breaker"
+	CircuitBreaker int `json:"circuitBreaker,omitempty"`
 
 	// Embedded custom configuration of the Builder to use to build the feature-value.
 	// +kubebuilder:pruning:PreserveUnknownFields
@@ -135,6 +537,34 @@ type FeatureBuilder struct {
 	Raw json.RawMessage `json:",inline"`
 }
 
+// HasProgram checks if the feature-value is built by a Python program, rather than mapped from a field as is or
+// built by an expression (SQL or CEL) or a WebAssembly module.
+func (in *FeatureBuilder) HasProgram() bool {
+	return in.Code != "" || (in.Field == "" && in.SQL == "" && in.CEL == "" && in.Wasm == nil)
+}
+
+// WasmModule defines a compiled WebAssembly module.
+// The module is either embedded, or fetched from a URL when the Feature is bound.
+type WasmModule struct {
+	// Module is the compiled (binary) module.
+	// +optional
+	Module []byte `json:"module,omitempty"`
+
+	// URL is an https URL to fetch the compiled module from. The host of the URL must be allowed by the Core's
+	// `--wasm-allowed-hosts` flag.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// SHA256 is the expected hex-encoded SHA-256 checksum of the module. It is required when the module is fetched
+	// from a URL.
+	// +optional
+	SHA256 string `json:"sha256,omitempty"`
+
+	// Entrypoint is the name of the exported handler function. Defaults to `handler`.
+	// +optional
+	Entrypoint string `json:"entrypoint,omitempty"`
+}
+
 // FeatureStatus defines the observed state of Feature
 type FeatureStatus struct {
 	// FQN is the Fully Qualified Name for the Feature
@@ -144,16 +574,118 @@ type FeatureStatus struct {
 	// State is the current state of the Feature
 	Ready bool `json:"ready"`
 
+	// Message describes why the Feature is not ready (i.e. a compilation error of the builder).
+	// +optional
+	Message string `json:"message,omitempty"`
+
 	// Dependencies is the list of dependencies for the Feature
 	// +optional
 	// +nullable
 	Dependencies []ResourceReference `json:"dependencies,omitempty"`
+
+	// Checksum is a content hash of the Feature's descriptor and spec (including the builder's program).
+	// Replicas verify it when they bind the Feature, to detect corrupted or partially updated specs.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+
+	// Conditions are the latest observations of the Feature's state.
+	// The `Stale` condition reports whether the Feature wasn't updated within its freshness SLO.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Retention is the report of the latest pruning by the Feature's retention policy.
+	// +optional
+	// +nullable
+	Retention *RetentionStatus `json:"retention,omitempty"`
+
+	// Warmup is the report of the warmup of the state from the historical storage.
+	// +optional
+	// +nullable
+	Warmup *WarmupStatus `json:"warmup,omitempty"`
+}
+
+type RetentionStatus struct {
+	// LastRun is the time of the latest pruning.
+	LastRun metav1.Time `json:"lastRun"`
+
+	// DryRun reports whether the latest pruning was a dry-run, so nothing was removed.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Before is the time that the pruned records were written before.
+	Before metav1.Time `json:"before"`
+
+	// PrunedPartitions is the number of historical partitions that were (or would be) removed.
+	// +optional
+	PrunedPartitions int `json:"prunedPartitions,omitempty"`
+
+	// PrunedFiles is the number of historical files that were (or would be) removed.
+	// +optional
+	PrunedFiles int `json:"prunedFiles,omitempty"`
+
+	// PrunedBytes is the size of the historical files that were (or would be) removed.
+	// +optional
+	PrunedBytes int64 `json:"prunedBytes,omitempty"`
+
+	// PrunedEntities is the number of entities whose online values were removed.
+	// +optional
+	PrunedEntities int `json:"prunedEntities,omitempty"`
+
+	// Message describes why the latest pruning failed, or what it skipped.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
+type WarmupStatus struct {
+	// CompletedAt is the time the warmup completed (or failed) at.
+	CompletedAt metav1.Time `json:"completedAt"`
+
+	// Entities is the number of entities whose values were loaded into the state.
+	// +optional
+	Entities int `json:"entities,omitempty"`
+
+	// Skipped is the number of entities whose values were kept, since they were written to the state since.
+	// +optional
+	Skipped int `json:"skipped,omitempty"`
+
+	// Message describes why the warmup failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// The types of the conditions of the Feature's status.
+const (
+	// FeatureConditionValidated reports whether the Feature's spec is valid, and its dependencies are accessible.
+	FeatureConditionValidated = "Validated"
+	// FeatureConditionBuilderCompileError reports whether the builder's program (or expression) failed to compile.
+	FeatureConditionBuilderCompileError = "BuilderCompileError"
+	// FeatureConditionBound reports whether the Feature is bound by the Core, so it can be served and written.
+	FeatureConditionBound = "Bound"
+	// FeatureConditionIngesting reports whether the Feature was updated recently (within its freshness, or the
+	// default ingestion window).
+	FeatureConditionIngesting = "Ingesting"
+	// FeatureConditionStale reports whether the Feature wasn't updated within its freshness SLO (i.e. its pipeline
+	// is broken).
+	FeatureConditionStale = "Stale"
+	// FeatureConditionDegraded reports whether the circuit breaker of the Feature's builder is open, after
+	// consecutive failures of its program.
+	FeatureConditionDegraded = "Degraded"
+)
+
 // +k8s:openapi-gen=true
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:categories=datascience,shortName=ft
+// +kubebuilder:printcolumn:name="Primitive",type=string,JSONPath=`.spec.primitive`
+// +kubebuilder:printcolumn:name="Unit",type=string,JSONPath=`.spec.unit`
+// +kubebuilder:printcolumn:name="Ready",type=boolean,JSONPath=`.status.ready`
+// +kubebuilder:printcolumn:name="Bound",type=string,JSONPath=`.status.conditions[?(@.type=="Bound")].status`
+// +kubebuilder:printcolumn:name="Ingesting",type=string,JSONPath=`.status.conditions[?(@.type=="Ingesting")].status`
+// +kubebuilder:printcolumn:name="Stale",type=string,JSONPath=`.status.conditions[?(@.type=="Stale")].status`
+// +kubebuilder:printcolumn:name="Message",type=string,priority=1,JSONPath=`.status.message`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +operator-sdk:csv:customresourcedefinitions:displayName="ML Feature",resources={{Deployment,v1,raptor-controller-core}}
 
 // Feature is the Schema for the features API
@@ -165,11 +697,17 @@ type Feature struct {
 	Status FeatureStatus `json:"status,omitempty"`
 }
 
+// FQNFormatter builds the fully qualified name of a Feature or a Model from its namespace and name.
+// It's replaced by api.SetFQNScheme, so the resources are named by the configured naming scheme.
+var FQNFormatter = func(namespace, name string) string {
+	ns := strings.Replace(namespace, "-", "_", -1)
+	name = strings.Replace(name, "-", "_", -1)
+	return fmt.Sprintf("%s.%s", ns, name)
+}
+
 // FQN returns the fully qualified name of the feature.
 func (in *Feature) FQN() string {
-	ns := strings.Replace(in.GetNamespace(), "-", "_", -1)
-	name := strings.Replace(in.GetName(), "-", "_", -1)
-	return fmt.Sprintf("%s.%s", ns, name)
+	return FQNFormatter(in.GetNamespace(), in.GetName())
 }
 
 func (in *Feature) ResourceReference() ResourceReference {
@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// FeatureSeedSpec defines the sample values to load into the online store for a Feature
+type FeatureSeedSpec struct {
+	// Feature is the reference to the Feature to seed.
+	// If the namespace is omitted, the namespace of the FeatureSeed is used.
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Feature"
+	Feature ResourceReference `json:"feature"`
+
+	// Environments is the list of environments the seed is applied in (i.e. `dev`, `staging`).
+	// The seed is applied only if the Core's `seed-environment` is one of them.
+	// If empty, the seed is applied in every environment that has seeding enabled.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Environments"
+	Environments []string `json:"environments,omitempty"`
+
+	// Values is the list of sample values to load.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Values"
+	Values []FeatureSeedValue `json:"values"`
+}
+
+// FeatureSeedValue is a sample value of an entity
+type FeatureSeedValue struct {
+	// Keys are the keys of the entity.
+	// +kubebuilder:validation:Required
+	Keys map[string]string `json:"keys"`
+
+	// Value is the sample value. It must match the primitive of the Feature (i.e. a list of numbers for `[]float`).
+	// Timestamps are expressed as RFC3339 strings.
+	// For windowed features, the value is added to the window as a single event.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Value runtime.RawExtension `json:"value"`
+
+	// Timestamp is the timestamp of the value. Defaults to the time the seed is applied.
+	// +optional
+	// +nullable
+	Timestamp *metav1.Time `json:"timestamp,omitempty"`
+}
+
+// FeatureSeedStatus defines the observed state of FeatureSeed
+type FeatureSeedStatus struct {
+	// ObservedGeneration is the generation of the spec that was last applied.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Environment is the environment the seed was applied in.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Environment string `json:"environment,omitempty"`
+
+	// Seeded is the number of values that were loaded into the online store.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Seeded int `json:"seeded,omitempty"`
+
+	// SeededAt is the time the seed was last applied.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	SeededAt *metav1.Time `json:"seededAt,omitempty"`
+
+	// Message describes why the seed was skipped or failed.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories=datascience,shortName=seed
+// +kubebuilder:printcolumn:name="Feature",type=string,JSONPath=`.spec.feature.name`
+// +kubebuilder:printcolumn:name="Seeded",type=integer,JSONPath=`.status.seeded`
+// +kubebuilder:printcolumn:name="Environment",type=string,JSONPath=`.status.environment`
+// +operator-sdk:csv:customresourcedefinitions:displayName="Feature Seed",resources={{Deployment,v1,raptor-controller-core}}
+
+// FeatureSeed is the Schema for the featureseeds API.
+// It loads sample entity values into the online store, so preview environments have realistic feature values without
+// running the ingestion stack. Seeds are ignored unless seeding is enabled in the Core.
+type FeatureSeed struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FeatureSeedSpec   `json:"spec,omitempty"`
+	Status FeatureSeedStatus `json:"status,omitempty"`
+}
+
+// FeatureReference returns the reference of the seeded Feature.
+func (in *FeatureSeed) FeatureReference() ResourceReference {
+	ref := in.Spec.Feature
+	if ref.Namespace == "" {
+		ref.Namespace = in.GetNamespace()
+	}
+	return ref
+}
+
+// +kubebuilder:object:root=true
+
+// FeatureSeedList contains a list of FeatureSeed
+type FeatureSeedList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FeatureSeed `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FeatureSeed{}, &FeatureSeedList{})
+}
@@ -18,10 +18,8 @@ package v1alpha1
 
 import (
 	"context"
-	"fmt"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"strings"
 )
 
 // ModelServer defines the backend inference server for the model.
@@ -61,6 +59,13 @@ type ModelSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Key Feature FQN"
 	KeyFeature string `json:"keyFeature,omitempty"`
 
+	// Units defines the unit that a feature's value should be converted to when assembling the feature set.
+	// The key is the feature FQN and the value is the requested unit.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Units"
+	Units map[string]string `json:"units,omitempty"`
+
 	// Labels is a list of feature FQNs that are used to label the prediction result.
 	// +optional
 	// +nullable
@@ -132,9 +137,7 @@ type Model struct {
 
 // FQN returns the fully qualified name of the feature.
 func (in *Model) FQN() string {
-	ns := strings.Replace(in.GetNamespace(), "-", "_", -1)
-	name := strings.Replace(in.GetName(), "-", "_", -1)
-	return fmt.Sprintf("%s.%s", ns, name)
+	return FQNFormatter(in.GetNamespace(), in.GetName())
 }
 
 // ParseInferenceConfig parses the inference config, and extracts the secrets, into a map of key-value pairs
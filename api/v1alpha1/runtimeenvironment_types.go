@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RuntimeEnvironmentSpec defines the Python requirements of an isolated runtime environment
+type RuntimeEnvironmentSpec struct {
+	// Requirements is the list of Python requirements of the environment, in the pip requirements format
+	// (i.e. `pandas==2.2.2`). The requirements are resolved and baked into a runtime image by the operator, so the
+	// programs of the environment can import them.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Requirements"
+	Requirements []string `json:"requirements"`
+
+	// BaseImage is the runtime image the environment is built upon. Defaults to the image of the Core's default
+	// runtime.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Base Image"
+	BaseImage string `json:"baseImage,omitempty"`
+}
+
+// RuntimeEnvironmentPhase is the phase of the build of a RuntimeEnvironment
+type RuntimeEnvironmentPhase string
+
+const (
+	// RuntimeEnvironmentBuilding means that the image of the environment is being built.
+	RuntimeEnvironmentBuilding RuntimeEnvironmentPhase = "Building"
+	// RuntimeEnvironmentReady means that the image was built, and the environment is attached to the Core.
+	RuntimeEnvironmentReady RuntimeEnvironmentPhase = "Ready"
+	// RuntimeEnvironmentFailed means that the image couldn't be built.
+	RuntimeEnvironmentFailed RuntimeEnvironmentPhase = "Failed"
+)
+
+// RuntimeEnvironmentStatus defines the observed state of RuntimeEnvironment
+type RuntimeEnvironmentStatus struct {
+	// ObservedGeneration is the generation of the spec that was last built.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase is the phase of the build of the environment.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Phase RuntimeEnvironmentPhase `json:"phase,omitempty"`
+
+	// Image is the runtime image that was built for the environment.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Image string `json:"image,omitempty"`
+
+	// Message describes why the build failed.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories=datascience,shortName=rtenv
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.status.image`
+// +operator-sdk:csv:customresourcedefinitions:displayName="Runtime Environment",resources={{Deployment,v1,raptor-controller-core},{Job,v1,runtime-environment-build}}
+
+// RuntimeEnvironment is the Schema for the runtimeenvironments API.
+// It's an isolated Python environment for the programs of the namespace's Features, with a declared list of
+// requirements. Features use it by setting their builder's `runtime` to its name.
+type RuntimeEnvironment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RuntimeEnvironmentSpec   `json:"spec,omitempty"`
+	Status RuntimeEnvironmentStatus `json:"status,omitempty"`
+}
+
+// RuntimeName returns the name of the environment's runtime in the Core.
+func (in *RuntimeEnvironment) RuntimeName() string {
+	return RuntimeEnvironmentName(in.GetNamespace(), in.GetName())
+}
+
+// RuntimeEnvironmentName returns the name of the runtime of a namespace's RuntimeEnvironment in the Core. Runtimes of
+// RuntimeEnvironments are scoped to their namespace, so Features of other namespaces can't use them.
+func RuntimeEnvironmentName(namespace, name string) string {
+	return namespace + "-" + name
+}
+
+// +kubebuilder:object:root=true
+
+// RuntimeEnvironmentList contains a list of RuntimeEnvironment
+type RuntimeEnvironmentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RuntimeEnvironment `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RuntimeEnvironment{}, &RuntimeEnvironmentList{})
+}
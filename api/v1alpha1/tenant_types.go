@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"path"
+)
+
+// TenantSpec defines the namespaces of a tenant, the resources it shares with the other tenants, and its quotas.
+type TenantSpec struct {
+	// Namespaces are the namespaces of the tenant. A namespace can belong to a single tenant.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespaces"
+	Namespaces []string `json:"namespaces"`
+
+	// Exports are the DataSources and Features of the tenant that can be used by the namespaces of other tenants, in
+	// the form of `<namespace>/<name>`. Glob patterns are supported (i.e. `shared/*`).
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Exports"
+	Exports []string `json:"exports,omitempty"`
+
+	// Quota limits the resources of the tenant.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Quota"
+	Quota TenantQuota `json:"quota,omitempty"`
+}
+
+// TenantQuota limits the resources of a tenant. Zero values are unlimited.
+type TenantQuota struct {
+	// MaxFeatures is the maximum number of features in the namespaces of the tenant.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxFeatures int32 `json:"maxFeatures,omitempty"`
+
+	// MaxStateMemory is the maximum memory the values of the tenant's features can take in the state store
+	// (i.e. `2Gi`). Once it's exceeded, the writes to the tenant's features are rejected, and new features can't be
+	// created, until the values are removed or expire.
+	// +optional
+	// +nullable
+	MaxStateMemory *resource.Quantity `json:"maxStateMemory,omitempty"`
+}
+
+// TenantStatus defines the observed usage of a tenant.
+type TenantStatus struct {
+	// Features is the number of features in the namespaces of the tenant.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Features int32 `json:"features,omitempty"`
+
+	// StateMemory is the estimated memory the values of the tenant's features take in the state store.
+	// +optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	StateMemory *resource.Quantity `json:"stateMemory,omitempty"`
+
+	// Conditions are the latest observations of the Tenant's state.
+	// The `QuotaExceeded` condition reports whether the tenant exceeded its state-store memory quota.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// TenantConditionQuotaExceeded is the type of the condition that reports whether the Tenant exceeded its state-store
+// memory quota.
+const TenantConditionQuotaExceeded = "QuotaExceeded"
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories=datascience
+// +kubebuilder:printcolumn:name="Features",type=integer,JSONPath=`.status.features`
+// +kubebuilder:printcolumn:name="Memory",type=string,JSONPath=`.status.stateMemory`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +operator-sdk:csv:customresourcedefinitions:displayName="Tenant",resources={{Deployment,v1,raptor-controller-core}}
+
+// Tenant is the Schema for the tenants API.
+// It isolates a group of namespaces: their DataSources and Features can be used only by the namespaces of the tenant,
+// unless they are exported, and their usage is limited by the tenant's quota. Namespaces that don't belong to a tenant
+// are shared, so their resources can be used by any namespace.
+type Tenant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TenantSpec   `json:"spec,omitempty"`
+	Status TenantStatus `json:"status,omitempty"`
+}
+
+// HasNamespace checks if the namespace belongs to the tenant.
+func (in *Tenant) HasNamespace(namespace string) bool {
+	for _, ns := range in.Spec.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// Exported checks if the resource with the given namespace and name is exported by the tenant.
+func (in *Tenant) Exported(namespace, name string) bool {
+	for _, pattern := range in.Spec.Exports {
+		if ok, err := path.Match(pattern, namespace+"/"+name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// +kubebuilder:object:root=true
+
+// TenantList contains a list of Tenant
+type TenantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Tenant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Tenant{}, &TenantList{})
+}
@@ -0,0 +1,232 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ViolationAction defines what happens to a value that violates the Validations of its feature.
+type ViolationAction string
+
+const (
+	// ViolationActionDrop drops the invalid value.
+	ViolationActionDrop ViolationAction = "drop"
+	// ViolationActionClamp writes numeric values clamped to the min/max range, and drops values that violate other rules.
+	ViolationActionClamp ViolationAction = "clamp"
+	// ViolationActionWarn writes the invalid value as-is.
+	ViolationActionWarn ViolationAction = "warn"
+)
+
+// ValidationRule is a data-quality rule of the Validations.
+type ValidationRule string
+
+const (
+	ValidationRuleMin     ValidationRule = "min"
+	ValidationRuleMax     ValidationRule = "max"
+	ValidationRuleRegex   ValidationRule = "regex"
+	ValidationRuleNotNull ValidationRule = "not_null"
+	ValidationRuleAllowed ValidationRule = "allowed"
+)
+
+// Validations are data-quality rules the feature-values must satisfy when they are written.
+// The elements of list values are validated individually.
+type Validations struct {
+	Min         *float64        `json:"min,omitempty"`
+	Max         *float64        `json:"max,omitempty"`
+	Regex       string          `json:"regex,omitempty"`
+	NotNull     bool            `json:"not_null,omitempty"`
+	Allowed     []string        `json:"allowed,omitempty"`
+	OnViolation ViolationAction `json:"on_violation"`
+
+	regex   *regexp.Regexp
+	allowed map[string]struct{}
+}
+
+// ValidationsFromManifest parses the validations of a Feature of the given primitive.
+func ValidationsFromManifest(in *manifests.Validations, primitive PrimitiveType) (*Validations, error) {
+	ret := &Validations{
+		NotNull:     in.NotNull,
+		Allowed:     in.Allowed,
+		OnViolation: ViolationAction(strings.ToLower(in.OnViolation)),
+	}
+	switch ret.OnViolation {
+	case "":
+		ret.OnViolation = ViolationActionDrop
+	case ViolationActionDrop, ViolationActionClamp, ViolationActionWarn:
+	default:
+		return nil, fmt.Errorf("unknown `onViolation` action: %s", in.OnViolation)
+	}
+
+	scalar := primitive.Singular()
+	if in.Min != nil || in.Max != nil {
+		if scalar != PrimitiveTypeInteger && scalar != PrimitiveTypeFloat {
+			return nil, fmt.Errorf("the `min` and `max` validations can be used only with numeric features")
+		}
+		if in.Min != nil {
+			v := in.Min.AsApproximateFloat64()
+			ret.Min = &v
+		}
+		if in.Max != nil {
+			v := in.Max.AsApproximateFloat64()
+			ret.Max = &v
+		}
+		if ret.Min != nil && ret.Max != nil && *ret.Min > *ret.Max {
+			return nil, fmt.Errorf("the `min` validation must not be greater than `max`")
+		}
+	} else if ret.OnViolation == ViolationActionClamp {
+		return nil, fmt.Errorf("the `clamp` action requires a `min` or `max` validation")
+	}
+
+	if in.Regex != "" {
+		if scalar != PrimitiveTypeString {
+			return nil, fmt.Errorf("the `regex` validation can be used only with string features")
+		}
+		re, err := regexp.Compile(in.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid `regex` validation: %w", err)
+		}
+		ret.Regex = in.Regex
+		ret.regex = re
+	}
+
+	if len(in.Allowed) > 0 {
+		ret.allowed = make(map[string]struct{}, len(in.Allowed))
+		for _, v := range in.Allowed {
+			ret.allowed[v] = struct{}{}
+		}
+	}
+	return ret, nil
+}
+
+// Validate evaluates the validations on the value, and returns the rules it violated (if any), alongside the value
+// with its numeric elements clamped to the min/max range.
+func (v *Validations) Validate(val any) (any, []ValidationRule) {
+	switch t := val.(type) {
+	case nil:
+		if v.NotNull {
+			return nil, []ValidationRule{ValidationRuleNotNull}
+		}
+		return nil, nil
+	case []int:
+		return validateList(v, t)
+	case []float64:
+		return validateList(v, t)
+	case []string:
+		return validateList(v, t)
+	case []bool:
+		return validateList(v, t)
+	case []time.Time:
+		return validateList(v, t)
+	case []any:
+		return validateList(v, t)
+	}
+	return v.validate(val)
+}
+
+func validateList[T any](v *Validations, l []T) (any, []ValidationRule) {
+	var ret []T
+	var violations []ValidationRule
+	for i, e := range l {
+		c, vs := v.validate(e)
+		if len(vs) == 0 {
+			continue
+		}
+		if ret == nil {
+			ret = make([]T, len(l))
+			copy(ret, l)
+		}
+		ret[i] = c.(T)
+		for _, r := range vs {
+			if !containsRule(violations, r) {
+				violations = append(violations, r)
+			}
+		}
+	}
+	if ret == nil {
+		return l, nil
+	}
+	return ret, violations
+}
+
+func containsRule(rules []ValidationRule, r ValidationRule) bool {
+	for _, v := range rules {
+		if v == r {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Validations) validate(val any) (any, []ValidationRule) {
+	var ret []ValidationRule
+	if v.NotNull && val == nil {
+		return val, []ValidationRule{ValidationRuleNotNull}
+	}
+	if v.allowed != nil {
+		if _, ok := v.allowed[validationString(val)]; !ok {
+			ret = append(ret, ValidationRuleAllowed)
+		}
+	}
+
+	switch t := val.(type) {
+	case string:
+		if v.regex != nil && !v.regex.MatchString(t) {
+			ret = append(ret, ValidationRuleRegex)
+		}
+	case int:
+		c, r := v.clamp(float64(t))
+		switch r {
+		case ValidationRuleMin:
+			val = int(math.Ceil(c))
+		case ValidationRuleMax:
+			val = int(math.Floor(c))
+		}
+		if r != "" {
+			ret = append(ret, r)
+		}
+	case float64:
+		c, r := v.clamp(t)
+		if r != "" {
+			val = c
+			ret = append(ret, r)
+		}
+	}
+	return val, ret
+}
+
+func (v *Validations) clamp(f float64) (float64, ValidationRule) {
+	if v.Min != nil && f < *v.Min {
+		return *v.Min, ValidationRuleMin
+	}
+	if v.Max != nil && f > *v.Max {
+		return *v.Max, ValidationRuleMax
+	}
+	return f, ""
+}
+
+func validationString(val any) string {
+	if t, ok := val.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%v", val)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// NormalizeValue converts a decoded value (i.e. of JSON) to a value that is supported by the runtime: integral numbers
+// are converted to integers, lists to typed slices (or their string representation if they are of mixed types), and
+// objects to their JSON representation.
+func NormalizeValue(v any) any {
+	switch v := v.(type) {
+	case float64:
+		if v == float64(int(v)) {
+			return int(v)
+		}
+		return v
+	case json.Number:
+		if i, err := strconv.Atoi(v.String()); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		vals := make([]any, len(v))
+		for i, item := range v {
+			vals[i] = NormalizeValue(item)
+		}
+		return NormalizeList(vals)
+	case map[string]any:
+		buf, _ := json.Marshal(v)
+		return string(buf)
+	default:
+		return v
+	}
+}
+
+// NormalizeList converts a list of normalized values to a typed slice. NULL items are dropped, lists of mixed types are
+// converted to their string representation, and empty lists to nil.
+func NormalizeList(vals []any) any {
+	items := make([]any, 0, len(vals))
+	for _, v := range vals {
+		if v != nil {
+			items = append(items, v)
+		}
+	}
+	vals = items
+	if len(vals) == 0 {
+		return nil
+	}
+	if TypeDetect(vals) == PrimitiveTypeUnknown {
+		return fmt.Sprint(vals)
+	}
+	ret, _ := NormalizeAny(vals)
+	return ret
+}
+
+// ParseTimestamp parses a timestamp value: a time, an RFC3339 string or a unix timestamp (in seconds or milliseconds).
+func ParseTimestamp(v any) (time.Time, error) {
+	var n float64
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse timestamp `%s`", v)
+		}
+		n = f
+	case int:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case float64:
+		n = v
+	default:
+		return time.Time{}, fmt.Errorf("unsupported timestamp value type %T", v)
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return time.Time{}, fmt.Errorf("invalid timestamp `%v`", n)
+	}
+	// heuristic: values that are too big to be seconds are milliseconds
+	if n > 1e11 {
+		return time.UnixMilli(int64(n)), nil
+	}
+	sec := int64(n)
+	return time.Unix(sec, int64((n-float64(sec))*1e9)), nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	tests := []struct {
+		name string
+		in   any
+		want any
+	}{
+		{name: "integral float", in: 3.0, want: 3},
+		{name: "float", in: 3.5, want: 3.5},
+		{name: "huge float", in: 1e300, want: 1e300},
+		{name: "integral number", in: json.Number("4"), want: 4},
+		{name: "number", in: json.Number("4.5"), want: 4.5},
+		{name: "string", in: "a", want: "a"},
+		{name: "nil", in: nil, want: nil},
+		{name: "list of integers", in: []any{1.0, 2.0}, want: []int{1, 2}},
+		{name: "list of strings", in: []any{"a", "b"}, want: []string{"a", "b"}},
+		{name: "list of mixed types", in: []any{"a", 1.0}, want: "[a 1]"},
+		{name: "list with NULLs", in: []any{nil, "a", nil}, want: []string{"a"}},
+		{name: "list of NULLs", in: []any{nil}, want: nil},
+		{name: "empty list", in: []any{}, want: nil},
+		{name: "object", in: map[string]any{"a": 1.0}, want: `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeValue(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		in      any
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "2022-10-01T12:00:00.5+02:00", want: time.Date(2022, 10, 1, 10, 0, 0, 5e8, time.UTC)},
+		{in: "1664625600", want: time.Unix(1664625600, 0)},
+		{in: 1664625600, want: time.Unix(1664625600, 0)},
+		{in: int64(1664625600500), want: time.UnixMilli(1664625600500)},
+		{in: 1664625600.25, want: time.Unix(1664625600, 25e7)},
+		{in: time.Unix(1, 0), want: time.Unix(1, 0)},
+		{in: "yesterday", wantErr: true},
+		{in: "", wantErr: true},
+		{in: true, wantErr: true},
+		{in: nil, wantErr: true},
+		{in: math.NaN(), wantErr: true},
+		{in: math.Inf(1), wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimestamp(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTimestamp(%#v): unexpected error: %v", tt.in, err)
+			continue
+		}
+		if !tt.wantErr && !got.Equal(tt.want) {
+			t.Errorf("ParseTimestamp(%#v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package api
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"strconv"
@@ -38,6 +39,17 @@ const (
 	AggrFnMax
 	AggrFnMin
 	AggrFnCount
+	// AggrFnCountDistinct is an approximate (HyperLogLog) count of the distinct values, with a standard error of 0.81%.
+	AggrFnCountDistinct
+	AggrFnDuration
+	// AggrFnP50 and the rest of the percentiles are approximations (DDSketch), with a relative error of
+	// QuantileSketchAccuracy.
+	AggrFnP50
+	AggrFnP90
+	AggrFnP95
+	AggrFnP99
+	// AggrFnTopK is the most frequent values of the window, approximated by a count-min sketch.
+	AggrFnTopK
 )
 
 func (w AggrFn) String() string {
@@ -52,11 +64,41 @@ func (w AggrFn) String() string {
 		return "min"
 	case AggrFnCount:
 		return "count"
+	case AggrFnCountDistinct:
+		return "count_distinct"
+	case AggrFnDuration:
+		return "duration"
+	case AggrFnP50:
+		return "p50"
+	case AggrFnP90:
+		return "p90"
+	case AggrFnP95:
+		return "p95"
+	case AggrFnP99:
+		return "p99"
+	case AggrFnTopK:
+		return "top_k"
 	default:
 		return "unknown"
 	}
 }
 
+// Quantile returns the quantile (0-1) that is calculated by a percentile aggregation function.
+func (w AggrFn) Quantile() (float64, bool) {
+	switch w {
+	case AggrFnP50:
+		return 0.5, true
+	case AggrFnP90:
+		return 0.9, true
+	case AggrFnP95:
+		return 0.95, true
+	case AggrFnP99:
+		return 0.99, true
+	default:
+		return 0, false
+	}
+}
+
 func StringsToAggrFns(fns []string) ([]AggrFn, error) {
 	aggrFnsMap := make(map[AggrFn]bool)
 	for _, fn := range fns {
@@ -87,6 +129,20 @@ func StringToAggrFn(s string) AggrFn {
 		return AggrFnMax
 	case "count":
 		return AggrFnCount
+	case "count_distinct", "approx_distinct":
+		return AggrFnCountDistinct
+	case "duration":
+		return AggrFnDuration
+	case "p50", "median":
+		return AggrFnP50
+	case "p90":
+		return AggrFnP90
+	case "p95":
+		return AggrFnP95
+	case "p99":
+		return AggrFnP99
+	case "top_k", "topk":
+		return AggrFnTopK
 	default:
 		return AggrFnUnknown
 	}
@@ -114,12 +170,16 @@ func BucketDeadTime(bucketName string, bucketSize, staleness time.Duration) time
 
 // AliveWindowBuckets returns a list of all the *valid* buckets up until now
 func AliveWindowBuckets(staleness, bucketSize time.Duration) []string {
+	return AliveWindowBucketsAt(staleness, bucketSize, time.Now())
+}
+
+// AliveWindowBucketsAt returns a list of all the *valid* buckets up until the given time
+func AliveWindowBucketsAt(staleness, bucketSize time.Duration, at time.Time) []string {
 	numberOfBuckets := int(math.Ceil(float64(staleness) / float64(bucketSize)))
-	now := time.Now()
 
 	keys := make([]string, numberOfBuckets)
 	for i := 0; i < numberOfBuckets; i++ {
-		keys[i] = BucketName(now.Add(-bucketSize*time.Duration(i)), bucketSize)
+		keys[i] = BucketName(at.Add(-bucketSize*time.Duration(i)), bucketSize)
 	}
 	return keys
 }
@@ -136,3 +196,201 @@ func DeadWindowBuckets(staleness, bucketSize time.Duration) []string {
 	}
 	return keys
 }
+
+// SlidingWindowBuckets returns a list of the buckets of the last complete sliding window, which ends at the last slide
+// boundary. Unlike AliveWindowBuckets, the current (open) bucket is never included, so the result is stable for the
+// whole slide. The slide must be a multiple of the bucket size.
+func SlidingWindowBuckets(staleness, bucketSize, slide time.Duration) []string {
+	return SlidingWindowBucketsAt(staleness, bucketSize, slide, time.Now())
+}
+
+// SlidingWindowBucketsAt returns a list of the buckets of the last complete sliding window at the given time.
+func SlidingWindowBucketsAt(staleness, bucketSize, slide time.Duration, at time.Time) []string {
+	numberOfBuckets := int(staleness / bucketSize)
+	end := at.Truncate(slide)
+
+	keys := make([]string, numberOfBuckets)
+	for i := 0; i < numberOfBuckets; i++ {
+		keys[i] = BucketName(end.Add(-bucketSize*time.Duration(i+1)), bucketSize)
+	}
+	return keys
+}
+
+// SessionWindow checks if the feature is aggregated by session windows (see FeatureBuilder.AggrSessionGap), which
+// are kept per entity rather than in time buckets.
+func (fd FeatureDescriptor) SessionWindow() bool {
+	return fd.SessionGap > 0 && fd.ValidWindow()
+}
+
+// DecayWindow checks if the feature is aggregated by an exponentially decayed window (see
+// FeatureBuilder.AggrHalfLife), which is kept per entity rather than in time buckets.
+func (fd FeatureDescriptor) DecayWindow() bool {
+	return fd.HalfLife > 0 && fd.ValidWindow()
+}
+
+// BucketedWindow checks if the feature is aggregated in time buckets, which can be collected to the historical
+// storage and inspected.
+func (fd FeatureDescriptor) BucketedWindow() bool {
+	return fd.ValidWindow() && !fd.SessionWindow() && !fd.DecayWindow() && !fd.TopKWindow()
+}
+
+// Lateness returns how late an event of the feature can arrive, compared to the watermark of its DataSource, and still
+// be aggregated.
+func (fd FeatureDescriptor) Lateness() time.Duration {
+	if fd.AllowedLateness > 0 {
+		return fd.AllowedLateness
+	}
+	return DeadGracePeriod
+}
+
+// TopKWindow checks if the feature is a top-K window (see FeatureBuilder.AggrTopK), which returns the most frequent
+// values rather than a WindowResultMap.
+func (fd FeatureDescriptor) TopKWindow() bool {
+	return len(fd.Aggr) == 1 && fd.Aggr[0] == AggrFnTopK && fd.ValidWindow()
+}
+
+// ValuePrimitive returns the primitive of the values that are written to the feature. It's the feature's primitive,
+// except for top-K windows, which count string values.
+func (fd FeatureDescriptor) ValuePrimitive() PrimitiveType {
+	if len(fd.Aggr) == 1 && fd.Aggr[0] == AggrFnTopK {
+		return PrimitiveTypeString
+	}
+	return fd.Primitive
+}
+
+// AliveWindowBuckets returns the buckets of the current window result of the feature.
+func (fd FeatureDescriptor) AliveWindowBuckets() []string {
+	return fd.AliveWindowBucketsAt(time.Now())
+}
+
+// AliveWindowBucketsAt returns the buckets of the window result of the feature at the given time.
+func (fd FeatureDescriptor) AliveWindowBucketsAt(at time.Time) []string {
+	if fd.WindowSlide > 0 {
+		return SlidingWindowBucketsAt(fd.Staleness, fd.Freshness, fd.WindowSlide, at)
+	}
+	return AliveWindowBucketsAt(fd.Staleness, fd.Freshness, at)
+}
+
+// BucketFields returns the aggregations that are kept in each bucket of the window, and merged to its result. Avg is
+// calculated from the sum and the count of the window, so they are kept for it even if they aren't aggregations of
+// the feature.
+func (fd FeatureDescriptor) BucketFields() []AggrFn {
+	has := make(map[AggrFn]bool, len(fd.Aggr))
+	for _, fn := range fd.Aggr {
+		has[fn] = true
+	}
+	var ret []AggrFn
+	for _, fn := range []AggrFn{AggrFnSum, AggrFnCount, AggrFnMin, AggrFnMax} {
+		if has[fn] || (has[AggrFnAvg] && (fn == AggrFnSum || fn == AggrFnCount)) {
+			ret = append(ret, fn)
+		}
+	}
+	return ret
+}
+
+// windowSpan is the time range the buckets of the feature must be kept for. A sliding window lags behind up to a
+// slide, so its buckets are kept for an extra slide.
+func (fd FeatureDescriptor) windowSpan() time.Duration {
+	return fd.Staleness + fd.WindowSlide
+}
+
+// DeadWindowBuckets returns the dead buckets of the feature that should still be available (for the historian).
+func (fd FeatureDescriptor) DeadWindowBuckets() []string {
+	return DeadWindowBuckets(fd.windowSpan(), fd.Freshness)
+}
+
+// BucketDeadTime returns the time the given bucket of the feature expires.
+func (fd FeatureDescriptor) BucketDeadTime(bucketName string) time.Time {
+	return BucketDeadTime(bucketName, fd.Freshness, fd.windowSpan())
+}
+
+// WindowBucketInspection is a human-readable representation of a single window bucket.
+type WindowBucketInspection struct {
+	Name   string    `json:"name"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	DeadAt time.Time `json:"dead_at"`
+	// Alive indicates if the bucket is part of the window's result
+	Alive bool `json:"alive"`
+	// Found indicates if the bucket exists in the state
+	Found bool `json:"found"`
+	// Raw is the aggregated data that is stored in the bucket
+	Raw map[string]float64 `json:"raw,omitempty"`
+	// Cumulative is the intermediate aggregation state of the window after merging this bucket
+	Cumulative map[string]float64 `json:"cumulative,omitempty"`
+}
+
+// WindowInspection is a human-readable dump of the window state of a feature for a specific entity.
+// It's used for debugging wrong window results.
+type WindowInspection struct {
+	FQN         string                   `json:"fqn"`
+	Keys        Keys                     `json:"keys"`
+	EncodedKeys string                   `json:"encoded_keys"`
+	Aggr        []string                 `json:"aggr"`
+	BucketSize  string                   `json:"bucket_size"`
+	Slide       string                   `json:"slide,omitempty"`
+	Staleness   string                   `json:"staleness"`
+	InspectedAt time.Time                `json:"inspected_at"`
+	Buckets     []WindowBucketInspection `json:"buckets"`
+	// Result is the window's result as calculated from the alive buckets
+	Result map[string]float64 `json:"result"`
+	// Watermark is the watermark of the feature's DataSource, if known. Events older than the watermark minus the
+	// allowed lateness are dropped.
+	Watermark *time.Time `json:"watermark,omitempty"`
+}
+
+// WindowInspector is implemented by engines that can dump the internal window state of a feature.
+type WindowInspector interface {
+	InspectWindow(ctx context.Context, selector string, keys Keys) (WindowInspection, error)
+}
+
+// WatermarkReader is implemented by engines that track the watermarks of the DataSources. The watermark of a
+// DataSource is the latest event time that was written to its windowed features.
+type WatermarkReader interface {
+	Watermark(dataSource string) (time.Time, bool)
+}
+
+// Readable returns a copy of the WindowResultMap with the AggrFn names as keys.
+func (w WindowResultMap) Readable() map[string]float64 {
+	if w == nil {
+		return nil
+	}
+	ret := make(map[string]float64, len(w))
+	for fn, v := range w {
+		ret[fn.String()] = v
+	}
+	return ret
+}
+
+// MergeWindowResults merges the data of a bucket into an aggregated window result according to the aggregation functions.
+// Avg is calculated from sum and count, so they are merged for it as well (see FeatureDescriptor.BucketFields).
+// Fields that are missing from the bucket are ignored, so an empty window has no result.
+// Distinct counts can't be merged from the buckets' counts, so they are omitted: the distinct count of the window is
+// the cardinality of the union of the buckets' sketches, which only the state can calculate.
+// Percentiles can't be merged from the buckets' percentiles either, so they are omitted as well: they are calculated
+// from the merge of the buckets' quantile sketches.
+func MergeWindowResults(into, bucket WindowResultMap, fns []AggrFn) {
+	for _, fn := range (FeatureDescriptor{Aggr: fns}).BucketFields() {
+		v, ok := bucket[fn]
+		if !ok {
+			continue
+		}
+		switch fn {
+		case AggrFnCount, AggrFnSum:
+			into[fn] += v
+		case AggrFnMin:
+			if _, exists := into[fn]; !exists || v < into[fn] {
+				into[fn] = v
+			}
+		case AggrFnMax:
+			if _, exists := into[fn]; !exists || v > into[fn] {
+				into[fn] = v
+			}
+		}
+	}
+	for _, fn := range fns {
+		if fn == AggrFnAvg && into[AggrFnCount] != 0 {
+			into[AggrFnAvg] = into[AggrFnSum] / into[AggrFnCount]
+		}
+	}
+}
@@ -18,16 +18,30 @@ package setup
 
 import (
 	"flag"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/access"
+	"github.com/raptor-ml/raptor/internal/encryption"
+	"github.com/raptor-ml/raptor/internal/engine"
+	"github.com/raptor-ml/raptor/internal/historian"
+	"github.com/raptor-ml/raptor/internal/lab"
+	"github.com/raptor-ml/raptor/internal/sharding"
+	"github.com/raptor-ml/raptor/pkg/crypto"
 	"github.com/raptor-ml/raptor/pkg/plugins"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"strings"
+	"time"
 )
 
 var updatesAllowed = false
 
+// logTap streams the logs of the features to the LabSDK, when it's enabled.
+var logTap *lab.LogTap
+
 func InitConfig() {
 	pflag.Bool("leader-elect", false, "Enable leader election for controller manager."+
 		"Enabling this will ensure there is only one active controller manager.")
@@ -37,6 +51,27 @@ func InitConfig() {
 	pflag.String("accessor-grpc-address", ":60000", "The address the grpc accessor binds to.")
 	pflag.String("accessor-http-address", ":60001", "The address the http accessor binds to.")
 	pflag.String("accessor-http-prefix", "/api", "The the http accessor path prefix.")
+	pflag.String("accessor-feast-address", "", "The address the Feast-compatible online serving API binds to "+
+		"(i.e. :6566). Disabled when empty.")
+	pflag.String("accessor-feast-namespace", "default", "The namespace of the Feast feature references without a "+
+		"feature view, and of the feature services (Models) without a namespace.")
+	pflag.Bool("accessor-auth", false, "Authenticate the callers of the serving API, and authorize their access to "+
+		"the features by the AccessPolicies.")
+	pflag.Bool("accessor-auth-kubernetes", true, "Accept Kubernetes ServiceAccount tokens when the authentication "+
+		"is enabled.")
+	pflag.String("oidc-issuer-url", "", "The URL of the OIDC issuer of the bearer tokens of the serving API. "+
+		"OIDC tokens are not accepted when empty.")
+	pflag.String("oidc-client-id", "", "The expected audience of the OIDC tokens. Required when the OIDC issuer is set.")
+	pflag.String("oidc-username-claim", "sub", "The claim of the username in the OIDC tokens.")
+	pflag.String("oidc-username-prefix", access.DefaultOIDCPrefix, "The prefix of the usernames of the OIDC tokens, "+
+		"so they can't match Kubernetes identities. `-` disables the prefix.")
+	pflag.String("oidc-groups-claim", "groups", "The claim of the groups in the OIDC tokens.")
+	pflag.String("oidc-groups-prefix", access.DefaultOIDCPrefix, "The prefix of the groups of the OIDC tokens, "+
+		"so they can't match Kubernetes groups. `-` disables the prefix.")
+	pflag.Bool("grpc-mtls", false, "Require mutual TLS on the gRPC accessor, and issue client certificates to the "+
+		"DataSource runners.")
+	pflag.String("grpc-mtls-cluster-issuer", "", "The cert-manager ClusterIssuer of the mTLS certificates. "+
+		"The issuer must populate the `ca.crt` (i.e. a CA or Vault issuer). An internal CA is used when empty.")
 	pflag.String("accessor-service", "", "The the accessor service URL (that points the this application).")
 	pflag.Bool("dev", false, "Set as development")
 	pflag.Bool("usage-reporting", true, "Allow us to anonymously report usage statistics to improve RaptorML 🪄")
@@ -44,11 +79,91 @@ func InitConfig() {
 		"You can use this to set a unique identifier for your cluster.")
 	pflag.String("state-provider", "redis", "The state provider.")
 	pflag.String("notifier-provider", "redis", "The notifier provider.")
+	pflag.String("dlq-provider", "redis", "The dead-letter queue provider for failed feature computations "+
+		"(empty to disable).")
+	pflag.String("audit-provider", "log", "The audit provider that the records of the feature reads and writes are "+
+		"shipped to.")
+	pflag.StringSlice("audit-namespaces", nil, "The namespaces of the features whose reads and writes are audited "+
+		"(`*` for all). Auditing is disabled when empty.")
+	pflag.String("audit-hash-key", "", "The secret key of the HMAC of the entities' keys in the audit records. "+
+		"A plain SHA-256 hash is used when empty.")
+	pflag.String("masking-hash-key", "", "The secret key of the HMAC of the masked values of PII and confidential "+
+		"features. A plain SHA-256 hash is used when empty.")
+	pflag.Duration("encryption-data-key-ttl", encryption.DefaultDataKeyTTL, "The time a data key encrypts the values "+
+		"of encrypted features before it's rotated, and the time an unwrapped data key is cached.")
+	pflag.Duration("dedup-horizon", engine.DefaultDeduplicationHorizon, "The time an idempotency key of a write "+
+		"is remembered, so replays of the same event are written only once (0 to disable).")
+	pflag.Duration("notification-batch-window", 10*time.Millisecond, "The time to accumulate the historian "+
+		"notifications of a feature before sending them together (0 to disable). Capped at 1s.")
+	pflag.Int("notification-batch-size", historian.DefaultNotificationBatchSize, "The maximal number of historian "+
+		"notifications to send together.")
 	pflag.Bool("disable-cert-management", false, "Setting this flag will disable the automatically "+
 		"certificate binding to the K8s API webhooks.")
 	pflag.Bool("no-webhooks", false, "Setting this flag will disable the K8s API webhook.")
 	pflag.String("system-namespace", "", "The Raptor System namespace.") // the default must be null for auto discovery!
 	pflag.String("pod-name", "", "The current pod name.")
+	pflag.String("crypto-provider", crypto.StdProviderName, fmt.Sprintf("The crypto provider for signatures and "+
+		"encryption-at-rest. Available providers: %v", crypto.Providers()))
+	pflag.String("seed-environment", "", "The environment name for FeatureSeeds (i.e. `dev`, `staging`). "+
+		"Seeding is disabled when empty. Do NOT set this in production.")
+	pflag.Bool("lab", false, "Serve the LabSDK endpoints, which issue short-lived session tokens to notebooks.")
+	pflag.String("lab-sandbox-namespace", "", "The namespace the LabSDK can push manifests to. "+
+		"Pushing manifests is disabled when empty.")
+	pflag.Duration("lab-token-ttl", time.Hour, "The lifetime of the LabSDK session tokens (up to 12h).")
+	pflag.Int("backfill-concurrency", 2, "The maximum number of Backfills that run at once.")
+	pflag.Float64("freshness-slo-tolerance", 2, "A feature is stale when it wasn't updated for longer than its "+
+		"freshness times the tolerance. Freshness tracking is disabled when 0.")
+	pflag.String("tracing-endpoint", "", "The OTLP/gRPC collector address to export the OpenTelemetry traces of the "+
+		"requests to (i.e. `otel-collector:4317`). Tracing is disabled when empty.")
+	pflag.Bool("tracing-insecure", false, "Connect to the tracing collector without TLS.")
+	pflag.Float64("tracing-sample-ratio", 0.1, "The ratio (0-1) of the traced requests, unless the caller "+
+		"already sampled the trace (i.e. by the `traceparent` header).")
+	pflag.Float64("value-monitoring-sample-rate", 0, "The ratio (0-1) of the written values that are sampled to "+
+		"monitor the features' value distribution and drift. Monitoring is disabled when 0.")
+	pflag.Duration("value-monitoring-window", time.Hour, "The duration of the value distributions that are "+
+		"compared to detect a drift.")
+	pflag.Int("read-cache-size", 0, "The maximum number of values that are cached in-process in front of the state "+
+		"store, where concurrent reads of the same value are coalesced. Caching is disabled when 0.")
+	pflag.Duration("read-cache-max-ttl", engine.DefaultReadCacheMaxTTL, "The maximum time a value is cached. Values "+
+		"are cached up to their feature's freshness, and writes of other instances may be served this late.")
+	pflag.Duration("read-cache-negative-ttl", engine.DefaultReadCacheNegativeTTL, "The time a missing value "+
+		"(i.e. of a new entity) is cached, so repeated reads of it don't reach the state store. Disabled when 0.")
+	pflag.String("wal-dir", "", "The directory of the write-ahead log of the features with the async `writeMode` "+
+		"(should be on a persistent volume). The writes of async features are synchronous when empty.")
+	pflag.Bool("wal-fsync", true, "Sync every async write to the disk before it's acknowledged, so it survives a "+
+		"crash of the node rather than only of the process.")
+	pflag.Bool("sharding", false, "Shard the entities across the Core replicas by consistent hashing, so each "+
+		"entity is served by a single replica. The requests of other replicas' entities are forwarded to them.")
+	pflag.String("sharding-group", "raptor-core", "The shard group of the replica. The replicas of the same "+
+		"deployment should share the group.")
+	pflag.String("sharding-advertise-address", "", "The address the other replicas reach this replica's gRPC "+
+		"accessor by. Defaults to the pod address and the gRPC accessor's port.")
+	pflag.Duration("sharding-lease-duration", sharding.DefaultLeaseDuration, "The time a replica is considered "+
+		"alive after it renewed its membership Lease.")
+	pflag.String("sharding-tls-dir", "", "The directory of the client certificate (`tls.crt`, `tls.key` and "+
+		"`ca.crt`) of the forwarded requests, when mTLS is enabled.")
+	pflag.Bool("grafana-dashboard", true, "Maintain a ConfigMap with a Grafana dashboard of the per-feature metrics "+
+		"in the system namespace (picked up by the Grafana dashboards sidecar).")
+	pflag.Duration("sandbox-ttl", 7*24*time.Hour, "The time a feature in a sandbox namespace can be left unread "+
+		"before it's removed alongside its values. Cleanup is disabled when 0.")
+	pflag.Int("sandbox-max-features", 50, "The maximum number of features in a sandbox namespace (0 for unlimited).")
+	pflag.Duration("sandbox-max-staleness", 24*time.Hour, "The maximum staleness of features in a sandbox namespace "+
+		"(0 for unlimited).")
+	pflag.Duration("tenant-usage-interval", 5*time.Minute, "The time between two measurements of the usage of the "+
+		"tenants (the number of their features, and the memory their values take in the state store).")
+	pflag.String("runtime-registry", "", "The container registry the images of the RuntimeEnvironments are pushed "+
+		"to (i.e. `ghcr.io/acme`). RuntimeEnvironments are disabled when empty.")
+	pflag.String("runtime-registry-secret", "", "The docker-config Secret (in the system namespace) with the "+
+		"credentials to push to the RuntimeEnvironments' registry.")
+	pflag.String("runtime-builder-image", "gcr.io/kaniko-project/executor:v1.23.2", "The image of the kaniko "+
+		"executor that builds the images of the RuntimeEnvironments.")
+	pflag.String("fqn-separator", ".", "The separator between the namespace and the name of the features' FQNs.")
+	pflag.String("fqn-charset", "", "The characters that are allowed in the features' FQNs, as a regular "+
+		"expression character class (i.e. `a-z0-9_`). Defaults to lowercase alphanumerics separated by underscores.")
+	pflag.Int("fqn-max-length", 0, "The maximum length of the features' FQNs (0 for unlimited).")
+	pflag.String("fqn-prefix", "", "A prefix for the namespaces of the features' FQNs, i.e. a domain or owner code.")
+	pflag.Bool("fqn-legacy", true, "Accept FQNs of the default naming scheme (`<namespace>.<name>`) "+
+		"when a custom naming scheme is configured.")
 
 	zapOpts := zap.Options{}
 	zapOpts.BindFlags(flag.CommandLine)
@@ -66,7 +181,24 @@ func InitConfig() {
 		viper.Set("no-webhooks", true)
 	}
 	logger := zap.New(zap.UseFlagOptions(&zapOpts))
+	if viper.GetBool("lab") {
+		logTap = lab.NewLogTap(logger.GetSink())
+		logger = logr.New(logTap)
+	}
 	ctrl.SetLogger(logger)
 
 	updatesAllowed = viper.GetBool("dev")
+
+	OrFail(crypto.Use(viper.GetString("crypto-provider")), "Failed to set the crypto provider")
+	setupLog.WithValues("provider", crypto.Default().Name(), "fips", crypto.Default().FIPS()).Info("Crypto provider configured")
+
+	fqnScheme, err := api.NewFQNScheme(api.NamingConfig{
+		Separator: viper.GetString("fqn-separator"),
+		Charset:   viper.GetString("fqn-charset"),
+		MaxLength: viper.GetInt("fqn-max-length"),
+		Prefix:    viper.GetString("fqn-prefix"),
+		Legacy:    viper.GetBool("fqn-legacy"),
+	})
+	OrFail(err, "Failed to configure the FQN naming scheme")
+	api.SetFQNScheme(fqnScheme)
 }
@@ -17,21 +17,37 @@ limitations under the License.
 package setup
 
 import (
+	"context"
 	"fmt"
+	certApi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/access"
 	"github.com/raptor-ml/raptor/internal/accessor"
+	"github.com/raptor-ml/raptor/internal/audit"
+	"github.com/raptor-ml/raptor/internal/encryption"
 	"github.com/raptor-ml/raptor/internal/engine"
 	corectrl "github.com/raptor-ml/raptor/internal/engine/controllers"
 	"github.com/raptor-ml/raptor/internal/historian"
+	"github.com/raptor-ml/raptor/internal/lab"
+	"github.com/raptor-ml/raptor/internal/mtls"
 	opctrl "github.com/raptor-ml/raptor/internal/operator"
+	"github.com/raptor-ml/raptor/internal/plan"
+	"github.com/raptor-ml/raptor/internal/sharding"
 	"github.com/raptor-ml/raptor/internal/stats"
+	"github.com/raptor-ml/raptor/internal/version"
+	"github.com/raptor-ml/raptor/internal/wal"
 	"github.com/raptor-ml/raptor/pkg/plugins"
 	"github.com/raptor-ml/raptor/pkg/runtimemanager"
+	"github.com/raptor-ml/raptor/pkg/tracing"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc/credentials"
+	"net"
 	"net/http"
 	"os"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"slices"
+	"time"
 )
 
 func setupStats(mgr manager.Manager) {
@@ -45,6 +61,25 @@ func setupStats(mgr manager.Manager) {
 	)), "unable to add stats")
 }
 
+func setupTracing(mgr manager.Manager) {
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+		Endpoint:       viper.GetString("tracing-endpoint"),
+		Insecure:       viper.GetBool("tracing-insecure"),
+		SampleRatio:    viper.GetFloat64("tracing-sample-ratio"),
+		ServiceName:    "raptor-core",
+		ServiceVersion: version.Version,
+	})
+	OrFail(err, "unable to setup tracing")
+
+	// flush the pending spans when the manager stops
+	OrFail(mgr.Add(accessor.NoLeaderRunnableFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return shutdown(ctx)
+	})), "unable to add tracing")
+}
+
 func historianClient(mgr manager.Manager) historian.Client {
 	// Create Notifiers
 	collectNotifier, err := plugins.NewCollectNotifier(viper.GetString("notifier-provider"), viper.GetViper())
@@ -59,6 +94,8 @@ func historianClient(mgr manager.Manager) historian.Client {
 		Logger:                     ctrl.Log.WithName("historian"),
 		CollectNotificationWorkers: 5,
 		WriteNotificationWorkers:   5,
+		NotificationBatchWindow:    viper.GetDuration("notification-batch-window"),
+		NotificationBatchSize:      viper.GetInt("notification-batch-size"),
 	})
 	OrFail(hsc.WithManager(mgr), "failed to create historian client")
 
@@ -90,16 +127,111 @@ func coreControllers(mgr manager.Manager, eng api.ManagerEngine) {
 		EngineManager: eng,
 	}).SetupWithManager(mgr)
 	OrFail(err, "unable to create core controller", "controller", "Model")
+
+	err = (&corectrl.TenantReconciler{
+		Reader:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		EngineManager: eng.(api.StateQuotaEnforcer),
+	}).SetupWithManager(mgr)
+	OrFail(err, "unable to create core controller", "controller", "Tenant")
+
+	err = (&corectrl.EntityReconciler{
+		Reader:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		EngineManager: eng.(api.EntityManager),
+	}).SetupWithManager(mgr)
+	OrFail(err, "unable to create core controller", "controller", "Entity")
+}
+
+const coreServiceName = "raptor-core-service"
+
+// coreDNSNames returns the DNS names of the Core's service, which are used for its gRPC server certificate.
+func coreDNSNames(ns string) []string {
+	names := []string{
+		coreServiceName,
+		fmt.Sprintf("%s.%s", coreServiceName, ns),
+		fmt.Sprintf("%s.%s.svc", coreServiceName, ns),
+		fmt.Sprintf("%s.%s.svc.cluster.local", coreServiceName, ns),
+	}
+	if addr := viper.GetString("accessor-service"); addr != "" {
+		host := addr
+		if h, _, err := net.SplitHostPort(addr); err == nil {
+			host = h
+		}
+		if !slices.Contains(names, host) {
+			names = append(names, host)
+		}
+	}
+	return names
+}
+
+func setupMTLS(mgr manager.Manager, ns string) (*mtls.Issuer, credentials.TransportCredentials) {
+	if !viper.GetBool("grpc-mtls") {
+		return nil, nil
+	}
+
+	clusterIssuer := viper.GetString("grpc-mtls-cluster-issuer")
+	if clusterIssuer != "" {
+		OrFail(certApi.AddToScheme(mgr.GetScheme()), "unable to add cert-manager api to scheme")
+	}
+	setupLog.WithValues("clusterIssuer", clusterIssuer).Info("mTLS is enabled for the gRPC accessor")
+
+	issuer := mtls.NewIssuer(mgr.GetClient(), mgr.GetAPIReader(), ns, clusterIssuer, coreDNSNames(ns),
+		ctrl.Log.WithName("mtls"))
+	OrFail(mgr.Add(issuer), "unable to add the mTLS certificates issuer")
+	return issuer, mtls.ServerCredentials(mgr.GetAPIReader(), ns)
+}
+
+func setupSharding(mgr manager.Manager, ns, podname string) *sharding.Sharder {
+	if !viper.GetBool("sharding") {
+		return nil
+	}
+
+	addr := viper.GetString("sharding-advertise-address")
+	if addr == "" {
+		_, port, err := net.SplitHostPort(viper.GetString("accessor-grpc-address"))
+		OrFail(err, "unable to parse the gRPC accessor address")
+		ips, err := net.LookupHost(podname)
+		if err != nil || len(ips) == 0 {
+			OrFail(fmt.Errorf("failed to resolve %s: %w", podname, err),
+				"unable to resolve the pod address. Please set the sharding-advertise-address flag")
+		}
+		addr = net.JoinHostPort(ips[0], port)
+	}
+
+	// the members are reached by their pod address, so their certificates are verified by the service name
+	var authority string
+	tlsDir := viper.GetString("sharding-tls-dir")
+	if viper.GetBool("grpc-mtls") {
+		if tlsDir == "" {
+			OrFail(fmt.Errorf("the sharding-tls-dir flag is not set"), "mTLS requires a client certificate for sharding")
+		}
+		authority = fmt.Sprintf("%s.%s.svc", coreServiceName, ns)
+	}
+	creds, err := mtls.TransportCredentials(tlsDir)
+	OrFail(err, "unable to load the sharding client certificate")
+
+	sh := sharding.New(sharding.Config{
+		Namespace:     ns,
+		Group:         viper.GetString("sharding-group"),
+		Identity:      podname,
+		Address:       addr,
+		Credentials:   creds,
+		Authority:     authority,
+		LeaseDuration: viper.GetDuration("sharding-lease-duration"),
+	}, mgr.GetClient(), mgr.GetAPIReader(), ctrl.Log.WithName("sharding"))
+	OrFail(mgr.Add(sh), "unable to add the sharding")
+	return sh
 }
 
-func operatorControllers(mgr manager.Manager, rm api.RuntimeManager) {
+func operatorControllers(mgr manager.Manager, eng api.ManagerEngine, rm api.RuntimeManager, issuer *mtls.Issuer) {
 	var err error
 
 	coreAddr := viper.GetString("accessor-service")
 	if coreAddr == "" {
 		ns, err := getInClusterNamespace()
 		OrFail(err, "unable to get in-cluster namespace. Please set the accessor-service flag")
-		coreAddr = fmt.Sprintf("raptor-core-service.%s.svc", ns)
+		coreAddr = fmt.Sprintf("%s.%s.svc", coreServiceName, ns)
 	}
 
 	err = (&opctrl.DataSourceReconciler{
@@ -108,6 +240,7 @@ func operatorControllers(mgr manager.Manager, rm api.RuntimeManager) {
 		CoreAddr:       coreAddr,
 		RuntimeManager: rm,
 		EventRecorder:  mgr.GetEventRecorderFor("DataSource-controller"),
+		TLS:            issuer,
 	}).SetupWithManager(mgr)
 	OrFail(err, "unable to create controller", "operator", "DataSource")
 
@@ -125,14 +258,103 @@ func operatorControllers(mgr manager.Manager, rm api.RuntimeManager) {
 	}).SetupWithManager(mgr)
 	OrFail(err, "unable to create controller", "operator", "FeaturePipeliner")
 
+	err = (&opctrl.ConditionsReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Engine: eng,
+	}).SetupWithManager(mgr)
+	OrFail(err, "unable to create controller", "operator", "Conditions")
+
+	if env := viper.GetString("seed-environment"); env != "" {
+		setupLog.WithValues("environment", env).Info("FeatureSeeds are enabled")
+		err = (&opctrl.FeatureSeedReconciler{
+			Client:        mgr.GetClient(),
+			Scheme:        mgr.GetScheme(),
+			Engine:        eng,
+			Environment:   env,
+			EventRecorder: mgr.GetEventRecorderFor("FeatureSeed-controller"),
+		}).SetupWithManager(mgr)
+		OrFail(err, "unable to create controller", "operator", "FeatureSeed")
+	}
+
+	err = (&opctrl.BackfillReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Engine:        eng,
+		Runtime:       rm,
+		Concurrency:   viper.GetInt("backfill-concurrency"),
+		EventRecorder: mgr.GetEventRecorderFor("Backfill-controller"),
+	}).SetupWithManager(mgr)
+	OrFail(err, "unable to create controller", "operator", "Backfill")
+
+	if tolerance := viper.GetFloat64("freshness-slo-tolerance"); tolerance > 0 {
+		err = (&opctrl.FreshnessReconciler{
+			Client:        mgr.GetClient(),
+			Scheme:        mgr.GetScheme(),
+			Engine:        eng,
+			Tolerance:     tolerance,
+			EventRecorder: mgr.GetEventRecorderFor("Freshness-controller"),
+		}).SetupWithManager(mgr)
+		OrFail(err, "unable to create controller", "operator", "Freshness")
+	}
+
+	err = (&opctrl.TenantReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Engine:        eng,
+		Interval:      viper.GetDuration("tenant-usage-interval"),
+		EventRecorder: mgr.GetEventRecorderFor("Tenant-controller"),
+	}).SetupWithManager(mgr)
+	OrFail(err, "unable to create controller", "operator", "Tenant")
+
+	sandbox := opctrl.SandboxConfig{
+		TTL:          viper.GetDuration("sandbox-ttl"),
+		MaxFeatures:  viper.GetInt("sandbox-max-features"),
+		MaxStaleness: viper.GetDuration("sandbox-max-staleness"),
+	}
+	if sandbox.TTL > 0 {
+		err = (&opctrl.SandboxReconciler{
+			Client:        mgr.GetClient(),
+			Scheme:        mgr.GetScheme(),
+			Engine:        eng,
+			Config:        sandbox,
+			EventRecorder: mgr.GetEventRecorderFor("Sandbox-controller"),
+		}).SetupWithManager(mgr)
+		OrFail(err, "unable to create controller", "operator", "Sandbox")
+	}
+
+	if registry := viper.GetString("runtime-registry"); registry != "" {
+		ns, err := getInClusterNamespace()
+		OrFail(err, "unable to get in-cluster namespace. Please set the system-namespace flag")
+		err = (&opctrl.RuntimeEnvironmentReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+			Config: opctrl.RuntimeEnvironmentConfig{
+				Namespace:      ns,
+				Registry:       registry,
+				RegistrySecret: viper.GetString("runtime-registry-secret"),
+				BuilderImage:   viper.GetString("runtime-builder-image"),
+			},
+			EventRecorder: mgr.GetEventRecorderFor("RuntimeEnvironment-controller"),
+		}).SetupWithManager(mgr)
+		OrFail(err, "unable to create controller", "operator", "RuntimeEnvironment")
+	}
+
+	if viper.GetBool("grafana-dashboard") {
+		ns, err := getInClusterNamespace()
+		OrFail(err, "unable to get in-cluster namespace. Please set the system-namespace flag")
+		OrFail(mgr.Add(opctrl.Dashboard(mgr.GetClient(), ns)), "unable to add the Grafana dashboard")
+	}
+
 	if !viper.GetBool("no-webhooks") {
-		opctrl.SetupFeatureWebhook(mgr, updatesAllowed, rm)
+		opctrl.SetupFeatureWebhook(mgr, updatesAllowed, rm, sandbox)
 	}
 }
 
 func Core(mgr manager.Manager, certsReady chan struct{}) {
 	// Setup usage reporting
 	setupStats(mgr)
+	setupTracing(mgr)
 
 	// Create a Historian Client
 	hsc := historianClient(mgr)
@@ -161,16 +383,102 @@ func Core(mgr manager.Manager, certsReady chan struct{}) {
 	rm, err := runtimemanager.New(mgr, ns, podname)
 	OrFail(err, "unable to create python runtime manager")
 
+	// Create the dead-letter queue
+	var dlq api.DeadLetterQueue
+	if provider := viper.GetString("dlq-provider"); provider != "" {
+		dlq, err = plugins.NewDeadLetterQueue(provider, viper.GetViper())
+		OrFail(err, fmt.Sprintf("failed to create dead-letter queue for provider %s", provider))
+	}
+
+	// Create the audit log
+	var al *audit.Logger
+	if namespaces := viper.GetStringSlice("audit-namespaces"); len(namespaces) > 0 {
+		sink, err := plugins.NewAuditSink(viper.GetString("audit-provider"), viper.GetViper())
+		OrFail(err, fmt.Sprintf("failed to create audit sink for provider %s", viper.GetString("audit-provider")))
+		al = audit.New(audit.Config{
+			Sink:       sink,
+			Namespaces: namespaces,
+			HashKey:    []byte(viper.GetString("audit-hash-key")),
+			Logger:     ctrl.Log.WithName("audit"),
+		})
+		OrFail(al.WithManager(mgr), "unable to add the audit log")
+	}
+
+	// Create the keyring of the encrypted features
+	kr := encryption.New(encryption.Config{
+		KeyManager: func(provider string) (api.KeyManager, error) {
+			return plugins.NewKeyManager(provider, viper.GetViper())
+		},
+		DataKeyTTL: viper.GetDuration("encryption-data-key-ttl"),
+	})
+
 	// Create a new Core engine
-	eng := engine.New(state, hsc, rm, ctrl.Log.WithName("engine"))
+	vm := engine.ValueMonitoring{
+		SampleRate: viper.GetFloat64("value-monitoring-sample-rate"),
+		Window:     viper.GetDuration("value-monitoring-window"),
+	}
+	rc := engine.ReadCache{
+		Size:        viper.GetInt("read-cache-size"),
+		MaxTTL:      viper.GetDuration("read-cache-max-ttl"),
+		NegativeTTL: viper.GetDuration("read-cache-negative-ttl"),
+	}
+	var w *wal.Log
+	if dir := viper.GetString("wal-dir"); dir != "" {
+		w, err = wal.Open(dir, viper.GetBool("wal-fsync"))
+		OrFail(err, "failed to open the write-ahead log")
+	}
+	eng := engine.New(state, hsc, rm, dlq, viper.GetDuration("dedup-horizon"), vm, rc, w, al, kr, ctrl.Log.WithName("engine"))
+	if bus, ok := eng.(api.EventBus); ok {
+		api.SubscribeTo(bus, func(_ context.Context, ev api.ProviderReconnectedEvent) {
+			setupLog.Info("provider reconnected", "provider", ev.Provider, "downtime", ev.Downtime)
+		})
+	}
+
+	// Create the LabSDK endpoints
+	var lb *lab.Lab
+	if viper.GetBool("lab") {
+		lb = lab.New(lab.Config{
+			Namespace:        ns,
+			SandboxNamespace: viper.GetString("lab-sandbox-namespace"),
+			TTL:              viper.GetDuration("lab-token-ttl"),
+			Logs:             logTap,
+		}, eng, mgr.GetClient(), mgr.GetAPIReader(), ctrl.Log.WithName("lab"))
+	}
+
+	// Create the authentication of the serving API
+	var guard *access.Guard
+	if viper.GetBool("accessor-auth") {
+		guard, err = access.NewGuard(access.Config{
+			OIDCIssuerURL:      viper.GetString("oidc-issuer-url"),
+			OIDCClientID:       viper.GetString("oidc-client-id"),
+			OIDCUsernameClaim:  viper.GetString("oidc-username-claim"),
+			OIDCUsernamePrefix: viper.GetString("oidc-username-prefix"),
+			OIDCGroupsClaim:    viper.GetString("oidc-groups-claim"),
+			OIDCGroupsPrefix:   viper.GetString("oidc-groups-prefix"),
+			Kubernetes:         viper.GetBool("accessor-auth-kubernetes"),
+			Namespace:          ns,
+			MaskingKey:         []byte(viper.GetString("masking-hash-key")),
+		}, mgr.GetClient(), ctrl.Log.WithName("access"))
+		OrFail(err, "unable to create the authentication of the serving API")
+	}
+
+	// Secure the gRPC accessor with mTLS
+	issuer, creds := setupMTLS(mgr, ns)
+
+	// Shard the entities across the replicas
+	sh := setupSharding(mgr, ns, podname)
 
 	// Create a new Accessor
-	acc := accessor.New(eng, ctrl.Log.WithName("accessor"))
-	OrFail(mgr.Add(acc.GRPC(viper.GetString("accessor-grpc-address"))), "unable to start gRPC accessor")
+	acc := accessor.New(eng, lb, plan.New(mgr.GetClient(), updatesAllowed), guard, sh, ctrl.Log.WithName("accessor"))
+	OrFail(mgr.Add(acc.GRPC(viper.GetString("accessor-grpc-address"), creds)), "unable to start gRPC accessor")
 	OrFail(mgr.Add(acc.GrpcUds()), "unable to start gRPC UDS accessor")
 	OrFail(
 		mgr.Add(acc.HTTP(viper.GetString("accessor-http-address"), viper.GetString("accessor-http-prefix"))),
 		"unable to start HTTP accessor")
+	if addr := viper.GetString("accessor-feast-address"); addr != "" {
+		OrFail(mgr.Add(acc.Feast(addr, mgr.GetClient(), viper.GetString("accessor-feast-namespace"))),
+			"unable to start the Feast-compatible accessor")
+	}
 
 	// The call to mgr.Start will never return, but the certs won't be ready until the manager starts
 	// and we can't set up the webhooks without them (the webhook server runnable will try to read the
@@ -185,6 +493,6 @@ func Core(mgr manager.Manager, certsReady chan struct{}) {
 		setupLog.Info("Certs ready")
 
 		coreControllers(mgr, eng)
-		operatorControllers(mgr, rm)
+		operatorControllers(mgr, eng, rm, issuer)
 	}()
 }
@@ -17,7 +17,10 @@ limitations under the License.
 package main
 
 import (
+	"net/http"
+
 	"github.com/raptor-ml/raptor/cmd/core/internal/setup"
+	"github.com/raptor-ml/raptor/internal/stats"
 	"github.com/raptor-ml/raptor/internal/version"
 	"github.com/spf13/viper"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
@@ -59,6 +62,8 @@ func main() {
 		Metrics: metricsserver.Options{
 			BindAddress:   viper.GetString("metrics-bind-address"),
 			SecureServing: viper.GetBool("metrics-secure-serving"),
+			// Per-feature metrics are served separately, so they are never shipped along with the usage reports.
+			ExtraHandlers: map[string]http.Handler{"/metrics/features": stats.Handler()},
 		},
 		HealthProbeBindAddress:        viper.GetString("health-probe-bind-address"),
 		LeaderElection:                viper.GetBool("leader-elect"),
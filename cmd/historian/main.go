@@ -29,6 +29,7 @@ import (
 	"github.com/spf13/viper"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 
+	"github.com/raptor-ml/raptor/api"
 	"github.com/raptor-ml/raptor/internal/historian"
 	"github.com/raptor-ml/raptor/internal/version"
 
@@ -49,6 +50,8 @@ import (
 	corectrl "github.com/raptor-ml/raptor/internal/engine/controllers"
 )
 
+const inClusterNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -71,6 +74,33 @@ func main() {
 	pflag.String("state-provider", "redis", "The state provider.")
 	pflag.String("notifier-provider", "redis", "The notifier provider.")
 	pflag.String("historical-writer-provider", "s3-parquet", "The historical writer provider.")
+	pflag.Duration("entity-gc-horizon", 0, "Remove the values of entities that were inactive for longer than the "+
+		"horizon. Set to 0 to disable.")
+	pflag.String("export-bind-address", "", "The address the export endpoint of the historical data binds to. "+
+		"Requires a historical writer that supports exporting (i.e. `s3-parquet` with `--duckdb`). Disabled when empty.")
+	pflag.Bool("notifications-leader-elect", false, "Elect a leader among the replicas to consume the notifications, "+
+		"so they're not processed twice. A standby replica takes over once the leader's Lease expires.")
+	pflag.String("notifications-lease-name", "historian-notifications.raptor.ml", "The name of the Lease of the "+
+		"notifications' leader election.")
+	pflag.String("notifications-lease-namespace", "", "The namespace of the Lease of the notifications' leader "+
+		"election. Defaults to the in-cluster namespace.")
+	pflag.Duration("notifications-lease-duration", historian.DefaultElectionLeaseDuration, "The time a standby "+
+		"replica waits before it takes over the notifications from a leader that stopped renewing its Lease.")
+	pflag.Int("notifier-retry-max-attempts", historian.DefaultRetryPolicy.MaxAttempts, "The maximal number of "+
+		"attempts to handle a notification before it's handed over to the dead-letter queue (0 to retry forever). "+
+		"Overridden for a notifier provider by `<provider>-retry-max-attempts` (i.e. `KAFKA_RETRY_MAX_ATTEMPTS`).")
+	pflag.Duration("notifier-retry-base-delay", historian.DefaultRetryPolicy.BaseDelay, "The delay of the first "+
+		"retry of a notification. It's doubled on every retry. Overridden by `<provider>-retry-base-delay`.")
+	pflag.Duration("notifier-retry-max-delay", historian.DefaultRetryPolicy.MaxDelay, "The maximal delay of the "+
+		"retries of a notification. Overridden by `<provider>-retry-max-delay`.")
+	pflag.Float64("notifier-retry-jitter", historian.DefaultRetryPolicy.Jitter, "The maximal fraction of the delay "+
+		"that is added to it randomly. Overridden by `<provider>-retry-jitter`.")
+	pflag.Duration("historical-compaction-interval", 0, "The time between compactions of the small files of the "+
+		"historical data. Requires a historical writer that supports compaction (i.e. `s3-parquet`). Set to 0 to disable.")
+	pflag.Duration("retention-period", historian.DefaultRetentionPeriod, "The time between two prunings of the "+
+		"historical data of a feature by its retention policy.")
+	pflag.String("dlq-provider", "", "The dead-letter queue provider for the notifications that exhausted their "+
+		"retries. They're dropped when empty.")
 
 	zapOpts := zap.Options{}
 	zapOpts.BindFlags(flag.CommandLine)
@@ -119,13 +149,46 @@ func main() {
 	orFail(err, "failed to create historical writer")
 	defer historicalWriter.Close(context.TODO())
 
+	// Elect the consumer of the notifications
+	var election *historian.Election
+	if viper.GetBool("notifications-leader-elect") {
+		ns := viper.GetString("notifications-lease-namespace")
+		if ns == "" {
+			b, err := os.ReadFile(inClusterNamespacePath)
+			orFail(err, "unable to get the in-cluster namespace. Please set the notifications-lease-namespace flag")
+			ns = strings.TrimSpace(string(b))
+		}
+		identity, err := os.Hostname()
+		orFail(err, "unable to get the hostname")
+		election = &historian.Election{
+			Config:        mgr.GetConfig(),
+			Namespace:     ns,
+			Name:          viper.GetString("notifications-lease-name"),
+			Identity:      identity,
+			LeaseDuration: viper.GetDuration("notifications-lease-duration"),
+		}
+	}
+
+	// Create the dead-letter queue
+	var dlq api.DeadLetterQueue
+	if provider := viper.GetString("dlq-provider"); provider != "" {
+		dlq, err = plugins.NewDeadLetterQueue(provider, viper.GetViper())
+		orFail(err, fmt.Sprintf("failed to create dead-letter queue for provider %s", provider))
+	}
+
 	// Create a Historian Client
 	hss := historian.NewServer(historian.ServerConfig{
-		CollectNotifier:  collectNotifier,
-		WriteNotifier:    writeNotifier,
-		State:            state,
-		Logger:           logger.WithName("historian"),
-		HistoricalWriter: historicalWriter,
+		CollectNotifier:    collectNotifier,
+		WriteNotifier:      writeNotifier,
+		State:              state,
+		Logger:             logger.WithName("historian"),
+		HistoricalWriter:   historicalWriter,
+		EntityHorizon:      viper.GetDuration("entity-gc-horizon"),
+		ExportAddr:         viper.GetString("export-bind-address"),
+		CompactionInterval: viper.GetDuration("historical-compaction-interval"),
+		Election:           election,
+		RetryPolicy:        retryPolicy(viper.GetString("notifier-provider")),
+		DeadLetterQueue:    dlq,
 	})
 	orFail(hss.WithManager(mgr), "failed to create historian client")
 
@@ -146,6 +209,23 @@ func main() {
 	}).SetupWithManager(mgr)
 	orFail(err, "unable to create core controller", "controller", "Feature")
 
+	err = (&historian.RetentionReconciler{
+		Client:           mgr.GetClient(),
+		HistoricalWriter: historicalWriter,
+		State:            state,
+		EventRecorder:    mgr.GetEventRecorderFor("historian"),
+		Period:           viper.GetDuration("retention-period"),
+	}).SetupWithManager(mgr)
+	orFail(err, "unable to create controller", "controller", "Retention")
+
+	err = (&historian.WarmupReconciler{
+		Client:           mgr.GetClient(),
+		HistoricalWriter: historicalWriter,
+		State:            state,
+		EventRecorder:    mgr.GetEventRecorderFor("historian"),
+	}).SetupWithManager(mgr)
+	orFail(err, "unable to create controller", "controller", "Warmup")
+
 	err = (&corectrl.ModelReconciler{
 		Reader:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
@@ -179,3 +259,20 @@ func orFail(err error, message string, keyAndValues ...any) {
 		os.Exit(1)
 	}
 }
+
+// retryPolicy returns the retry policy of the notifier provider. The policy of the provider overrides the
+// `notifier-retry-*` flags by its `<provider>-retry-*` config keys.
+func retryPolicy(provider string) *historian.RetryPolicy {
+	key := func(name string) string {
+		if k := provider + "-retry-" + name; viper.IsSet(k) {
+			return k
+		}
+		return "notifier-retry-" + name
+	}
+	return &historian.RetryPolicy{
+		MaxAttempts: viper.GetInt(key("max-attempts")),
+		BaseDelay:   viper.GetDuration(key("base-delay")),
+		MaxDelay:    viper.GetDuration(key("max-delay")),
+		Jitter:      viper.GetFloat64(key("jitter")),
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/raptor-ml/raptor/internal/plan"
+	"github.com/raptor-ml/raptor/pkg/client/gen"
+)
+
+func codegen(args []string) error {
+	set := pflag.NewFlagSet("codegen", pflag.ExitOnError)
+	paths := set.StringSliceP("filename", "f", nil, "The files, or directories, of the Model and Feature manifests. "+
+		"Directories are walked recursively.")
+	lang := set.String("lang", "go", "The language of the generated source: `go` or `typescript`.")
+	pkg := set.String("package", "features", "The name of the generated Go package.")
+	output := set.StringP("output", "o", "", "The file to write the generated source to. Defaults to stdout.")
+	set.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Generate typed Go structs of the feature sets of Models, and functions to fetch them "+
+			"with the Go client (github.com/raptor-ml/raptor/pkg/client), or their TypeScript bindings for the Node "+
+			"client (@raptor-ml/client). The fields are typed by the Feature manifests; features whose manifest "+
+			"isn't given are typed as `any` (`unknown` in TypeScript).\n\n"+
+			"Usage: raptorctl codegen -f <file or directory>... [flags]\n\n%s", set.FlagUsages())
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	files := append(*paths, set.Args()...)
+	if len(files) == 0 {
+		set.Usage()
+		return fmt.Errorf("`--filename` is required")
+	}
+
+	objs, err := readManifests(files)
+	if err != nil {
+		return err
+	}
+	var src []byte
+	switch strings.ToLower(*lang) {
+	case "go":
+		src, err = gen.Generate(*pkg, objs)
+	case "typescript", "ts":
+		src, err = gen.GenerateTypeScript(objs)
+	default:
+		return fmt.Errorf("unsupported language %q", *lang)
+	}
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*output, src, 0o644) //nolint:gosec // the generated source is not sensitive
+}
+
+// readManifests reads the manifests (YAML or JSON) of the files. Directories are walked recursively for `.yaml`,
+// `.yml` and `.json` files.
+func readManifests(paths []string) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	for _, p := range paths {
+		err := filepath.WalkDir(p, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".yaml", ".yml", ".json":
+			default:
+				if path != p {
+					return nil
+				}
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			decoded, err := plan.DecodeManifests(f)
+			if err != nil {
+				return fmt.Errorf("failed to decode %s: %w", path, err)
+			}
+			objs = append(objs, decoded...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return objs, nil
+}
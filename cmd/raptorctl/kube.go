@@ -0,0 +1,325 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+)
+
+// The commands in this file talk to the Kubernetes API rather than to the accessor.
+
+func kubeFlags(set *pflag.FlagSet) func() (*rest.Config, error) {
+	kubeconfig := set.String("kubeconfig", "", "The kubeconfig file. Defaults to $KUBECONFIG, the in-cluster config "+
+		"or ~/.kube/config.")
+	kubeContext := set.String("context", "", "The kubeconfig context to use.")
+
+	return func() (*rest.Config, error) {
+		var (
+			cfg *rest.Config
+			err error
+		)
+		if *kubeconfig != "" {
+			rules := &clientcmd.ClientConfigLoadingRules{ExplicitPath: *kubeconfig}
+			overrides := &clientcmd.ConfigOverrides{CurrentContext: *kubeContext}
+			cfg, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+		} else if *kubeContext != "" {
+			cfg, err = config.GetConfigWithContext(*kubeContext)
+		} else {
+			cfg, err = config.GetConfig()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the kubeconfig: %w", err)
+		}
+		return cfg, nil
+	}
+}
+
+func kubeClient(cfg *rest.Config) (ctrlclient.Client, error) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := manifests.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	c, err := ctrlclient.New(cfg, ctrlclient.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a kubernetes client: %w", err)
+	}
+	return c, nil
+}
+
+func logs(args []string) error {
+	set := pflag.NewFlagSet("logs", pflag.ExitOnError)
+	fqn := set.String("fqn", "", "The FQN of the feature (i.e. `name.namespace`).")
+	namespace := set.String("core-namespace", "raptor-system", "The namespace of the Core.")
+	selector := set.String("selector", "control-plane=controller-core", "The label selector of the Core's pods.")
+	follow := set.BoolP("follow", "f", false, "Follow the logs.")
+	since := set.Duration("since", time.Hour, "Show the logs that are newer than the duration.")
+	newConfig := kubeFlags(set)
+	set.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Show the logs of the Core's pods that mention a feature (i.e. its binding, and the "+
+			"errors of its computations).\n\nUsage: raptorctl logs [flags]\n\n%s", set.FlagUsages())
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *fqn == "" {
+		set.Usage()
+		return fmt.Errorf("`--fqn` is required")
+	}
+
+	cfg, err := newConfig()
+	if err != nil {
+		return err
+	}
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create a kubernetes client: %w", err)
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	pods, err := cs.CoreV1().Pods(*namespace).List(ctx, metav1.ListOptions{LabelSelector: *selector})
+	if err != nil {
+		return fmt.Errorf("failed to list the Core's pods: %w", err)
+	}
+	if len(pods.Items) == 0 {
+		return fmt.Errorf("no pods match %q in the namespace %s", *selector, *namespace)
+	}
+
+	sinceSeconds := int64(since.Seconds())
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, pod := range pods.Items {
+		wg.Add(1)
+		go func(pod corev1.Pod) {
+			defer wg.Done()
+			opts := &corev1.PodLogOptions{Container: "core", Follow: *follow}
+			if sinceSeconds > 0 {
+				opts.SinceSeconds = &sinceSeconds
+			}
+			rc, err := cs.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts).Stream(ctx)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("failed to stream the logs of %s: %w", pod.Name, err))
+				mu.Unlock()
+				return
+			}
+			defer rc.Close()
+
+			// the Core logs the feature by its FQN (i.e. `"feature": "name.namespace"`)
+			scanner := bufio.NewScanner(rc)
+			scanner.Buffer(make([]byte, 64*1024), 1<<20)
+			for scanner.Scan() {
+				if !strings.Contains(scanner.Text(), *fqn) {
+					continue
+				}
+				mu.Lock()
+				fmt.Printf("%s %s\n", pod.Name, scanner.Text())
+				mu.Unlock()
+			}
+		}(pod)
+	}
+	wg.Wait()
+	if len(errs) > 0 && ctx.Err() == nil {
+		return errs[0]
+	}
+	return nil
+}
+
+func graph(args []string) error {
+	set := pflag.NewFlagSet("graph", pflag.ExitOnError)
+	namespace := set.String("namespace", "", "Show the features of the namespace. Defaults to all the namespaces.")
+	output := set.StringP("output", "o", "text", "The output format: text or dot (Graphviz).")
+	newConfig := kubeFlags(set)
+	set.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Show the dependency graph of the features: the DataSource of each feature, and the "+
+			"features (or models) its program depends on.\n\nUsage: raptorctl graph [flags]\n\n%s", set.FlagUsages())
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *output != "text" && *output != "dot" {
+		set.Usage()
+		return fmt.Errorf("unsupported output %q", *output)
+	}
+
+	cfg, err := newConfig()
+	if err != nil {
+		return err
+	}
+	c, err := kubeClient(cfg)
+	if err != nil {
+		return err
+	}
+	features := manifests.FeatureList{}
+	if err := c.List(context.Background(), &features, ctrlclient.InNamespace(*namespace)); err != nil {
+		return fmt.Errorf("failed to list features: %w", err)
+	}
+	sort.Slice(features.Items, func(i, j int) bool {
+		return features.Items[i].FQN() < features.Items[j].FQN()
+	})
+
+	if *output == "dot" {
+		fmt.Println("digraph raptor {")
+		fmt.Println("  rankdir=LR;")
+		for _, ft := range features.Items {
+			fmt.Printf("  %q [shape=box];\n", ft.FQN())
+			if src := ft.Spec.DataSource; src != nil {
+				fmt.Printf("  %q [shape=cylinder];\n", "datasource/"+resourceName(*src, ft.GetNamespace()))
+				fmt.Printf("  %q -> %q;\n", "datasource/"+resourceName(*src, ft.GetNamespace()), ft.FQN())
+			}
+			for _, dep := range ft.Status.Dependencies {
+				fmt.Printf("  %q -> %q;\n", resourceFQN(dep, ft.GetNamespace()), ft.FQN())
+			}
+		}
+		fmt.Println("}")
+		return nil
+	}
+
+	for _, ft := range features.Items {
+		fmt.Println(ft.FQN())
+		if src := ft.Spec.DataSource; src != nil {
+			fmt.Printf("  <- datasource %s\n", resourceName(*src, ft.GetNamespace()))
+		}
+		for _, dep := range ft.Status.Dependencies {
+			fmt.Printf("  <- %s\n", resourceFQN(dep, ft.GetNamespace()))
+		}
+	}
+	return nil
+}
+
+// resourceName returns the `namespace/name` of the reference.
+func resourceName(ref manifests.ResourceReference, namespace string) string {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	return fmt.Sprintf("%s/%s", namespace, ref.Name)
+}
+
+// resourceFQN returns the FQN (`name.namespace`) of the referenced feature or model.
+func resourceFQN(ref manifests.ResourceReference, namespace string) string {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	return fmt.Sprintf("%s.%s", ref.Name, namespace)
+}
+
+func backfill(args []string) error {
+	set := pflag.NewFlagSet("backfill", pflag.ExitOnError)
+	feature := set.String("feature", "", "The name of the feature to backfill.")
+	namespace := set.String("namespace", "default", "The namespace of the feature and the Backfill.")
+	name := set.String("name", "", "The name of the Backfill. Defaults to a generated name.")
+	kind := set.String("source-kind", "", "The kind of the historical source (i.e. `batch` or `snowflake`).")
+	cfgVars := set.StringToString("config", nil, "The config of the source (i.e. `--config uri=s3://bucket/events/`).")
+	from := set.String("from", "", "Replay the rows since the timestamp (RFC3339).")
+	to := set.String("to", "", "Replay the rows until the timestamp (RFC3339).")
+	warmOnline := set.Bool("warm-online", false, "Write the values that are still fresh to the online store as well.")
+	rateLimit := set.Int("rate-limit", 0, "The maximum number of rows to replay per second. Defaults to the "+
+		"Backfill's default.")
+	newConfig := kubeFlags(set)
+	set.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Trigger a backfill of a feature from a historical source, by creating a Backfill."+
+			"\n\nUsage: raptorctl backfill [flags]\n\n%s", set.FlagUsages())
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *feature == "" || *kind == "" {
+		set.Usage()
+		return fmt.Errorf("`--feature` and `--source-kind` are required")
+	}
+
+	bf := &manifests.Backfill{
+		ObjectMeta: metav1.ObjectMeta{Namespace: *namespace, Name: *name},
+		Spec: manifests.BackfillSpec{
+			Feature:    manifests.ResourceReference{Name: *feature, Namespace: *namespace},
+			Source:     manifests.BackfillSource{Kind: *kind},
+			WarmOnline: *warmOnline,
+			RateLimit:  *rateLimit,
+		},
+	}
+	if *name == "" {
+		bf.GenerateName = *feature + "-"
+	}
+	keys := make([]string, 0, len(*cfgVars))
+	for k := range *cfgVars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		bf.Spec.Source.Config = append(bf.Spec.Source.Config, manifests.ConfigVar{Name: k, Value: (*cfgVars)[k]})
+	}
+	var err error
+	if bf.Spec.From, err = parseTime(*from); err != nil {
+		return err
+	}
+	if bf.Spec.To, err = parseTime(*to); err != nil {
+		return err
+	}
+
+	cfg, err := newConfig()
+	if err != nil {
+		return err
+	}
+	c, err := kubeClient(cfg)
+	if err != nil {
+		return err
+	}
+	if err := c.Create(context.Background(), bf); err != nil {
+		return fmt.Errorf("failed to create the Backfill: %w", err)
+	}
+	fmt.Printf("Backfill %s/%s was created\n", bf.GetNamespace(), bf.GetName())
+	return nil
+}
+
+// parseTime parses an optional RFC3339 timestamp.
+func parseTime(s string) (*metav1.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	ts, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %s: %w", s, err)
+	}
+	return &metav1.Time{Time: ts}, nil
+}
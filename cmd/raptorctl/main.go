@@ -0,0 +1,202 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// raptorctl is a command-line client of the Core's HTTP accessor and of the Raptor resources in the cluster.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/raptor-ml/raptor/internal/access"
+)
+
+const usage = `Usage: raptorctl <command> [flags]
+
+Commands:
+  get             Get the value of a feature for an entity
+  set             Set the value of a feature for an entity
+  logs            Show the logs of the Core that mention a feature
+  validate        Validate manifests of features and DataSources offline (i.e. in CI)
+  codegen         Generate typed Go structs of the feature sets of Models, for the Go client
+  graph           Show the dependency graph of the features
+  backfill        Trigger a backfill of a feature from a historical source
+  replay          Replay recorded events through a modified builder, and diff the outputs
+  delete-entity   Delete all the values of an entity (i.e. for the right to be forgotten of the GDPR)
+  snapshot        Export the online values of features to a file or to S3
+  restore         Restore a snapshot of online values (i.e. into a new cluster)
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "get":
+		err = get(os.Args[2:])
+	case "set":
+		err = setValue(os.Args[2:])
+	case "logs":
+		err = logs(os.Args[2:])
+	case "validate":
+		err = validate(os.Args[2:])
+	case "codegen":
+		err = codegen(os.Args[2:])
+	case "graph":
+		err = graph(os.Args[2:])
+	case "backfill":
+		err = backfill(os.Args[2:])
+	case "replay":
+		err = replay(os.Args[2:])
+	case "delete-entity":
+		err = deleteEntity(os.Args[2:])
+	case "snapshot":
+		err = snapshot(os.Args[2:])
+	case "restore":
+		err = restore(os.Args[2:])
+	case "-h", "--help", "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// client calls the endpoints of the accessor.
+type client struct {
+	url    string
+	apiKey string
+	token  string
+	http   *http.Client
+}
+
+func clientFlags(set *pflag.FlagSet) func() (*client, error) {
+	addr := set.String("accessor-url", "http://raptor-core-service.raptor-system:60001/api", "The URL of the "+
+		"Core's HTTP accessor, including its prefix.")
+	apiKeyFile := set.String("api-key-file", "", "The file of an API key to authenticate with.")
+	tokenFile := set.String("token-file", "", "The file of a bearer token (i.e. OIDC or a ServiceAccount token) to "+
+		"authenticate with.")
+	timeout := set.Duration("timeout", 5*time.Minute, "The timeout of the request.")
+
+	return func() (*client, error) {
+		c := &client{url: strings.TrimSuffix(*addr, "/") + "/", http: &http.Client{Timeout: *timeout}}
+		if *apiKeyFile != "" {
+			b, err := os.ReadFile(*apiKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the API key: %w", err)
+			}
+			c.apiKey = strings.TrimSpace(string(b))
+		}
+		if *tokenFile != "" {
+			b, err := os.ReadFile(*tokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the token: %w", err)
+			}
+			c.token = strings.TrimSpace(string(b))
+		}
+		return c, nil
+	}
+}
+
+// request calls the endpoint, and returns its response if it succeeded. The caller should close the response's body.
+func (c *client) request(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path+"?"+query.Encode(), body)
+	if err != nil {
+		return nil, err
+	}
+	if c.apiKey != "" {
+		req.Header.Set(access.APIKeyHeader, c.apiKey)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call the accessor: %w", err)
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		return nil, fmt.Errorf("the accessor responded with %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return resp, nil
+}
+
+// do calls the endpoint, and prints its response.
+func (c *client) do(ctx context.Context, method, path string, query url.Values, body io.Reader) error {
+	resp, err := c.request(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read the response: %w", err)
+	}
+
+	var out bytes.Buffer
+	if json.Indent(&out, b, "", "  ") != nil {
+		out.Reset()
+		out.Write(b)
+	}
+	fmt.Println(strings.TrimSpace(out.String()))
+	return nil
+}
+
+func deleteEntity(args []string) error {
+	set := pflag.NewFlagSet("delete-entity", pflag.ExitOnError)
+	entityType := set.String("entity-type", "", "The key name of the entity (i.e. `user_id`).")
+	entityID := set.String("entity-id", "", "The ID of the entity.")
+	newClient := clientFlags(set)
+	set.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Delete the values of an entity from every feature that is keyed by the entity type, "+
+			"and record their deletion in the historical storage.\n\nUsage: raptorctl delete-entity [flags]\n\n%s",
+			set.FlagUsages())
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *entityType == "" || *entityID == "" {
+		set.Usage()
+		return fmt.Errorf("`--entity-type` and `--entity-id` are required")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	q := url.Values{"entity_type": {*entityType}, "entity_id": {*entityID}}
+	return c.do(context.Background(), http.MethodDelete, "admin/entities", q, nil)
+}
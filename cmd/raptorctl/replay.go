@@ -0,0 +1,121 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+func replay(args []string) error {
+	set := pflag.NewFlagSet("replay", pflag.ExitOnError)
+	manifest := set.StringP("filename", "f", "", "The file of the modified Feature manifest.")
+	input := set.StringP("input", "i", "", "The file (or S3 location) of the captured events to replay, as "+
+		"dead-letter records (JSON array or newline-delimited JSON, gzipped if it ends with `.gz`). Defaults to the "+
+		"dead-letters of the feature.")
+	limit := set.Int("limit", 100, "The maximal number of dead-letters to replay.")
+	region := set.String("aws-region", "", "The AWS region of the S3 bucket. Defaults to the environment's region.")
+	newClient := clientFlags(set)
+	set.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Replay recorded events through a modified version of a feature's builder, and diff "+
+			"the outputs against the original values. Nothing is persisted.\n\nUsage: raptorctl replay [flags]\n\n%s",
+			set.FlagUsages())
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *manifest == "" {
+		set.Usage()
+		return fmt.Errorf("`--filename` is required")
+	}
+
+	m, err := os.ReadFile(*manifest)
+	if err != nil {
+		return fmt.Errorf("failed to read the manifest: %w", err)
+	}
+	req := map[string]any{"manifest": string(m), "limit": *limit}
+	ctx := context.Background()
+	if *input != "" {
+		records, err := readRecords(ctx, *input, *region)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return fmt.Errorf("no records were found in %s", *input)
+		}
+		req["records"] = records
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, "admin/builder/replay", nil, bytes.NewReader(body))
+}
+
+// readRecords reads the records of a JSON array or of newline-delimited JSON.
+func readRecords(ctx context.Context, location, region string) ([]json.RawMessage, error) {
+	rc, err := source(ctx, location, region)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var r io.Reader = rc
+	if strings.HasSuffix(location, ".gz") {
+		zr, err := gzip.NewReader(rc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the records: %w", err)
+		}
+		r = zr
+	}
+
+	var records []json.RawMessage
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid record: %w", err)
+		}
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+			var arr []json.RawMessage
+			if err := json.Unmarshal(raw, &arr); err != nil {
+				return nil, fmt.Errorf("invalid records: %w", err)
+			}
+			records = append(records, arr...)
+			continue
+		}
+		records = append(records, raw)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/spf13/pflag"
+)
+
+// The snapshots are stored as gzipped newline-delimited JSON records, as they're streamed by the accessor.
+
+func snapshot(args []string) error {
+	set := pflag.NewFlagSet("snapshot", pflag.ExitOnError)
+	namespace := set.String("namespace", "", "Export the features of the namespace.")
+	fqns := set.StringSlice("fqn", nil, "Export the features of the FQNs (i.e. `name.namespace`).")
+	output := set.StringP("output", "o", "", "The file of the snapshot, or its S3 location (i.e. "+
+		"`s3://bucket/snapshots/2022-10-01.ndjson.gz`).")
+	region := set.String("aws-region", "", "The AWS region of the S3 bucket. Defaults to the environment's region.")
+	newClient := clientFlags(set)
+	set.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Export the online values of the features to a gzipped snapshot, which can be restored "+
+			"by `raptorctl restore`.\n\nUsage: raptorctl snapshot [flags]\n\n%s", set.FlagUsages())
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if (*namespace == "") == (len(*fqns) == 0) || *output == "" {
+		set.Usage()
+		return fmt.Errorf("`--output`, and either `--namespace` or `--fqn` are required")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	q := url.Values{}
+	if *namespace != "" {
+		q.Set("namespace", *namespace)
+	}
+	for _, fqn := range *fqns {
+		q.Add("fqn", fqn)
+	}
+
+	// the snapshot is written to a local file first, so it's uploaded only once it's complete
+	local, upload, err := destination(*output, *region)
+	if err != nil {
+		return err
+	}
+	if upload != nil {
+		defer os.Remove(local)
+	}
+	f, err := os.Create(local)
+	if err != nil {
+		return fmt.Errorf("failed to create the snapshot file: %w", err)
+	}
+	defer f.Close()
+
+	resp, err := c.request(ctx, http.MethodGet, "admin/snapshot", q, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	zw := gzip.NewWriter(f)
+	if _, err := io.Copy(zw, resp.Body); err != nil {
+		return fmt.Errorf("failed to download the snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write the snapshot: %w", err)
+	}
+	if upload != nil {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := upload(ctx, f); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Snapshot was written to %s\n", *output)
+	return nil
+}
+
+func restore(args []string) error {
+	set := pflag.NewFlagSet("restore", pflag.ExitOnError)
+	input := set.StringP("input", "i", "", "The file of the snapshot, or its S3 location.")
+	region := set.String("aws-region", "", "The AWS region of the S3 bucket. Defaults to the environment's region.")
+	newClient := clientFlags(set)
+	set.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Restore a snapshot of online values that was exported by `raptorctl snapshot`. The "+
+			"existing values of the snapshot's entities are replaced.\n\nUsage: raptorctl restore [flags]\n\n%s",
+			set.FlagUsages())
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *input == "" {
+		set.Usage()
+		return fmt.Errorf("`--input` is required")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	r, err := source(ctx, *input, *region)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to read the snapshot: %w", err)
+	}
+	return c.do(ctx, http.MethodPost, "admin/snapshot/restore", nil, zr)
+}
+
+// destination returns the local file to write the snapshot to, and a function that uploads it to S3 (if the location
+// is an S3 one).
+func destination(location, region string) (string, func(context.Context, io.ReadSeeker) error, error) {
+	bucket, key, ok := s3Location(location)
+	if !ok {
+		return location, nil, nil
+	}
+	if bucket == "" || key == "" {
+		return "", nil, fmt.Errorf("invalid s3 location %s", location)
+	}
+	f, err := os.CreateTemp("", "raptor-snapshot-*.ndjson.gz")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create a temporary file: %w", err)
+	}
+	_ = f.Close()
+	upload := func(ctx context.Context, body io.ReadSeeker) error {
+		client, err := s3Client(ctx, region)
+		if err != nil {
+			return err
+		}
+		_, err = client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:          aws.String(bucket),
+			Key:             aws.String(key),
+			Body:            body,
+			ContentType:     aws.String("application/x-ndjson"),
+			ContentEncoding: aws.String("gzip"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upload the snapshot: %w", err)
+		}
+		return nil
+	}
+	return f.Name(), upload, nil
+}
+
+// source opens the snapshot's file or S3 object.
+func source(ctx context.Context, location, region string) (io.ReadCloser, error) {
+	bucket, key, ok := s3Location(location)
+	if !ok {
+		f, err := os.Open(location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the snapshot: %w", err)
+		}
+		return f, nil
+	}
+	client, err := s3Client(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the snapshot: %w", err)
+	}
+	return out.Body, nil
+}
+
+func s3Location(location string) (bucket, key string, ok bool) {
+	rest, ok := strings.CutPrefix(location, "s3://")
+	if !ok {
+		return "", "", false
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	return bucket, key, true
+}
+
+func s3Client(ctx context.Context, region string) (*s3.Client, error) {
+	var opts []func(*config.LoadOptions) error
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+	return s3.NewFromConfig(cfg), nil
+}
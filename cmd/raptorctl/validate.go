@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/raptor-ml/raptor/pkg/validator"
+)
+
+func validate(args []string) error {
+	set := pflag.NewFlagSet("validate", pflag.ExitOnError)
+	paths := set.StringSliceP("filename", "f", nil, "The files, or directories, of the manifests to validate. "+
+		"Directories are walked recursively.")
+	allowMissing := set.Bool("allow-missing-datasources", false, "Validate features whose DataSource isn't in the "+
+		"manifests (i.e. it already exists in the cluster), without validating their builder against it.")
+	set.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Validate manifests of features and DataSources offline, without a cluster: their "+
+			"spec, their `sql` or `cel` expressions, and their builder against their DataSource. Python programs and "+
+			"the dependencies of the features are validated by the admission webhook when the manifests are applied."+
+			"\n\nUsage: raptorctl validate -f <file or directory>...\n\n%s", set.FlagUsages())
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	files := append(*paths, set.Args()...)
+	if len(files) == 0 {
+		set.Usage()
+		return fmt.Errorf("`--filename` is required")
+	}
+
+	v := &validator.Validator{AllowMissingDataSources: *allowMissing}
+	results, err := v.ValidatePaths(files...)
+	if err != nil {
+		return err
+	}
+	invalid := 0
+	for _, res := range results {
+		if res.Err != nil {
+			invalid++
+		}
+		fmt.Println(res)
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d manifests are invalid", invalid, len(results))
+	}
+	return nil
+}
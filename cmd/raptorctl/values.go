@@ -0,0 +1,149 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// The values are read and written by the REST gateway of the accessor, which accepts the fields of the request as
+// query parameters (i.e. `keys[user_id]=123`).
+
+func get(args []string) error {
+	set := pflag.NewFlagSet("get", pflag.ExitOnError)
+	selector := set.String("fqn", "", "The selector of the feature (i.e. `name.namespace`, or `name.namespace+sum` "+
+		"for a windowed feature).")
+	keys := set.StringToString("key", nil, "The keys of the entity (i.e. `--key user_id=123`).")
+	newClient := clientFlags(set)
+	set.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Get the value of a feature for an entity.\n\nUsage: raptorctl get [flags]\n\n%s",
+			set.FlagUsages())
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *selector == "" || len(*keys) == 0 {
+		set.Usage()
+		return fmt.Errorf("`--fqn` and `--key` are required")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	return c.do(context.Background(), http.MethodGet, url.PathEscape(*selector), keysQuery(*keys), nil)
+}
+
+func setValue(args []string) error {
+	set := pflag.NewFlagSet("set", pflag.ExitOnError)
+	fqn := set.String("fqn", "", "The FQN of the feature (i.e. `name.namespace`).")
+	keys := set.StringToString("key", nil, "The keys of the entity (i.e. `--key user_id=123`).")
+	value := set.String("value", "", "The value to set.")
+	typ := set.String("type", "", "The type of the value: string, int, float, bool or timestamp (RFC3339). "+
+		"Defaults to the type the value is parsed as.")
+	ts := set.String("timestamp", "", "The timestamp of the value (RFC3339). Defaults to now.")
+	newClient := clientFlags(set)
+	set.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Set the value of a feature for an entity. Only scalar values are supported.\n\n"+
+			"Usage: raptorctl set [flags]\n\n%s", set.FlagUsages())
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *fqn == "" || len(*keys) == 0 || !set.Changed("value") {
+		set.Usage()
+		return fmt.Errorf("`--fqn`, `--key` and `--value` are required")
+	}
+
+	q := keysQuery(*keys)
+	field, err := scalarField(*value, *typ)
+	if err != nil {
+		return err
+	}
+	q.Set("value.scalar_value."+field, *value)
+	at := time.Now()
+	if *ts != "" {
+		at, err = time.Parse(time.RFC3339, *ts)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp: %w", err)
+		}
+	}
+	q.Set("timestamp", at.UTC().Format(time.RFC3339Nano))
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	return c.do(context.Background(), http.MethodPut, url.PathEscape(*fqn), q, nil)
+}
+
+func keysQuery(keys map[string]string) url.Values {
+	q := url.Values{}
+	for k, v := range keys {
+		q.Set(fmt.Sprintf("keys[%s]", k), v)
+	}
+	return q
+}
+
+// scalarField returns the field of the Scalar message that holds the value.
+func scalarField(value, typ string) (string, error) {
+	switch typ {
+	case "":
+		if _, err := strconv.ParseBool(value); err == nil {
+			return "bool_value", nil
+		}
+		if _, err := strconv.ParseInt(value, 10, 32); err == nil {
+			return "int_value", nil
+		}
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return "float_value", nil
+		}
+		return "string_value", nil
+	case "string":
+		return "string_value", nil
+	case "int":
+		if _, err := strconv.ParseInt(value, 10, 32); err != nil {
+			return "", fmt.Errorf("invalid int value: %w", err)
+		}
+		return "int_value", nil
+	case "float":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("invalid float value: %w", err)
+		}
+		return "float_value", nil
+	case "bool":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "", fmt.Errorf("invalid bool value: %w", err)
+		}
+		return "bool_value", nil
+	case "timestamp":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "", fmt.Errorf("invalid timestamp value: %w", err)
+		}
+		return "timestamp_value", nil
+	default:
+		return "", fmt.Errorf("unsupported type %q", typ)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package access authenticates the callers of the accessor, and authorizes their reads and writes of the features by
+// the AccessPolicies of the features' namespaces.
+//
+// Callers are authenticated by one of:
+//   - An API key, in the `X-API-Key` header (or the `x-api-key` gRPC metadata), that is held by a Secret which is
+//     referenced by an AccessPolicy. An API key is granted only by the policies of its own namespace.
+//   - An OIDC token (when an issuer and a client ID are configured), as a bearer token of the `Authorization` header.
+//     The usernames and groups of OIDC tokens are prefixed (`oidc:` by default), so an identity provider can't issue
+//     tokens that impersonate Kubernetes identities (i.e. `system:masters`).
+//   - A Kubernetes ServiceAccount token (when enabled), as a bearer token. The tokens are reviewed by the API server,
+//     so in-cluster services (i.e. the DataSource runners) can authenticate with their projected token.
+//
+// Requests over the local unix socket are trusted, since they're made by the sidecars of the Core's pod.
+package access
+
+import (
+	"context"
+	"fmt"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-logr/logr"
+	"github.com/jellydator/ttlcache/v3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+	"time"
+)
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=accesspolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+
+const (
+	// APIKeyHeader is the HTTP header of the API keys.
+	APIKeyHeader = "X-API-Key"
+	// apiKeyMetadataKey is the gRPC metadata key of the API keys.
+	apiKeyMetadataKey = "x-api-key"
+
+	// credentialsTTL is the time the identity of a credential is cached, which is also the time it takes to revoke
+	// an API key.
+	credentialsTTL   = 30 * time.Second
+	credentialsCache = 10_000
+	// rejectedTTL is the time invalid credentials are remembered, so repeated attempts don't reach the API server.
+	rejectedTTL = 5 * time.Second
+
+	// DefaultOIDCPrefix is the default prefix of the usernames and groups of OIDC tokens.
+	DefaultOIDCPrefix = "oidc:"
+)
+
+// Config is the configuration of the authentication.
+type Config struct {
+	// OIDCIssuerURL is the URL of the OIDC issuer. OIDC tokens are not accepted when it's empty.
+	OIDCIssuerURL string
+	// OIDCClientID is the expected audience of the OIDC tokens. It's required when the OIDCIssuerURL is set.
+	OIDCClientID string
+	// OIDCUsernameClaim is the claim of the username in the OIDC tokens. Defaults to `sub`.
+	OIDCUsernameClaim string
+	// OIDCUsernamePrefix is prepended to the usernames of the OIDC tokens, so they can't match Kubernetes identities.
+	// Defaults to DefaultOIDCPrefix, and `-` disables it.
+	OIDCUsernamePrefix string
+	// OIDCGroupsClaim is the claim of the groups in the OIDC tokens. Defaults to `groups`.
+	OIDCGroupsClaim string
+	// OIDCGroupsPrefix is prepended to the groups of the OIDC tokens, so they can't match Kubernetes groups. Defaults
+	// to DefaultOIDCPrefix, and `-` disables it.
+	OIDCGroupsPrefix string
+	// Kubernetes enables authenticating Kubernetes ServiceAccount tokens.
+	Kubernetes bool
+	// Namespace is the Core's namespace, whose AccessPolicies grant the admin endpoints that aren't scoped to a feature.
+	Namespace string
+	// MaskingKey is the key of the HMAC of the masked values of sensitive features. A plain SHA-256 is used when empty,
+	// which is weaker for guessable values.
+	MaskingKey []byte
+}
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Name is the username of the caller, or `apikey:<namespace>/<secret>` for API keys.
+	Name   string
+	Groups []string
+	// Trusted callers are allowed to access all the features (i.e. the sidecars of the Core).
+	Trusted bool
+
+	apiKey *apiKeyRef
+}
+
+type apiKeyRef struct {
+	namespace string
+	secret    string
+	key       string
+}
+
+type principalContextKey struct{}
+
+func withPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, p)
+}
+
+// PrincipalFromContext returns the authenticated caller of the request.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalContextKey{}).(Principal)
+	return p, ok
+}
+
+// Guard authenticates the callers of the accessor, and authorizes their access to the features.
+type Guard struct {
+	cfg    Config
+	client client.Client
+	logger logr.Logger
+
+	credentials *ttlcache.Cache[string, Principal]
+	rejected    *ttlcache.Cache[string, error]
+
+	mu       sync.Mutex
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewGuard creates a new Guard. The client is used to read the AccessPolicies and the Secrets of their API keys, and
+// to review the Kubernetes tokens. It should be a cached client, so unknown credentials don't load the API server.
+func NewGuard(cfg Config, c client.Client, logger logr.Logger) (*Guard, error) {
+	if cfg.OIDCIssuerURL != "" && cfg.OIDCClientID == "" {
+		return nil, fmt.Errorf("an OIDC client ID is required, so tokens that were issued for other audiences are rejected")
+	}
+	if cfg.OIDCUsernameClaim == "" {
+		cfg.OIDCUsernameClaim = "sub"
+	}
+	if cfg.OIDCGroupsClaim == "" {
+		cfg.OIDCGroupsClaim = "groups"
+	}
+	cfg.OIDCUsernamePrefix = oidcPrefix(cfg.OIDCUsernamePrefix)
+	cfg.OIDCGroupsPrefix = oidcPrefix(cfg.OIDCGroupsPrefix)
+	return &Guard{
+		cfg:    cfg,
+		client: c,
+		logger: logger,
+		credentials: ttlcache.New[string, Principal](
+			ttlcache.WithTTL[string, Principal](credentialsTTL),
+			ttlcache.WithCapacity[string, Principal](credentialsCache),
+			ttlcache.WithDisableTouchOnHit[string, Principal](),
+		),
+		rejected: ttlcache.New[string, error](
+			ttlcache.WithTTL[string, error](rejectedTTL),
+			ttlcache.WithCapacity[string, error](credentialsCache),
+			ttlcache.WithDisableTouchOnHit[string, error](),
+		),
+	}, nil
+}
+
+// oidcPrefix returns the configured prefix of the OIDC identities: DefaultOIDCPrefix when it's empty, and no prefix
+// when it's `-`.
+func oidcPrefix(prefix string) string {
+	switch prefix {
+	case "":
+		return DefaultOIDCPrefix
+	case "-":
+		return ""
+	default:
+		return prefix
+	}
+}
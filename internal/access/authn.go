@@ -0,0 +1,221 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package access
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/jellydator/ttlcache/v3"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	authnv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"time"
+)
+
+// ErrUnauthenticated is returned when the credentials of the caller are missing or invalid.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Credentials are the credentials that are sent by the caller.
+type Credentials struct {
+	APIKey      string
+	BearerToken string
+}
+
+// CredentialsFromAuthorization returns the Credentials of an API key and an `Authorization` header value.
+func CredentialsFromAuthorization(apiKey, authorization string) Credentials {
+	creds := Credentials{APIKey: strings.TrimSpace(apiKey)}
+	if len(authorization) > 7 && strings.EqualFold(authorization[:7], "bearer ") {
+		creds.BearerToken = strings.TrimSpace(authorization[7:])
+	}
+	return creds
+}
+
+// Authenticate returns the Principal of the credentials.
+func (g *Guard) Authenticate(ctx context.Context, creds Credentials) (Principal, error) {
+	var kind, secret string
+	switch {
+	case creds.APIKey != "":
+		kind, secret = "apikey", creds.APIKey
+	case creds.BearerToken != "":
+		kind, secret = "bearer", creds.BearerToken
+	default:
+		return Principal{}, fmt.Errorf("%w: missing credentials", ErrUnauthenticated)
+	}
+
+	digest := sha256.Sum256([]byte(secret))
+	cacheKey := kind + ":" + hex.EncodeToString(digest[:])
+	if item := g.credentials.Get(cacheKey); item != nil {
+		return item.Value(), nil
+	}
+	if item := g.rejected.Get(cacheKey); item != nil {
+		return Principal{}, item.Value()
+	}
+
+	var p Principal
+	var ttl time.Duration
+	var err error
+	if kind == "apikey" {
+		p, err = g.authenticateAPIKey(ctx, digest)
+		ttl = credentialsTTL
+	} else {
+		p, ttl, err = g.authenticateToken(ctx, creds.BearerToken)
+	}
+	if err != nil {
+		// invalid credentials are remembered for a short while, but failures to verify them (i.e. when the API server
+		// is unavailable) are not
+		if errors.Is(err, ErrUnauthenticated) {
+			g.rejected.Set(cacheKey, err, ttlcache.DefaultTTL)
+		}
+		return Principal{}, err
+	}
+	g.credentials.Set(cacheKey, p, ttl)
+	return p, nil
+}
+
+// authenticateAPIKey looks for the API key in the Secrets that are referenced by the AccessPolicies. Both are read by
+// the cached client, so looking up an unknown key doesn't reach the API server.
+func (g *Guard) authenticateAPIKey(ctx context.Context, digest [sha256.Size]byte) (Principal, error) {
+	policies := &manifests.AccessPolicyList{}
+	if err := g.client.List(ctx, policies); err != nil {
+		return Principal{}, fmt.Errorf("failed to list AccessPolicies: %w", err)
+	}
+
+	secrets := make(map[client.ObjectKey]*corev1.Secret)
+	for _, ap := range policies.Items {
+		for _, ref := range ap.Spec.Subjects.APIKeys {
+			key := client.ObjectKey{Namespace: ap.GetNamespace(), Name: ref.Name}
+			secret, ok := secrets[key]
+			if !ok {
+				secret = &corev1.Secret{}
+				if err := g.client.Get(ctx, key, secret); err != nil {
+					g.logger.V(1).Info("failed to get the secret of an API key", "secret", key, "error", err.Error())
+					secret = nil
+				}
+				secrets[key] = secret
+			}
+			if secret == nil || len(secret.Data[ref.Key]) == 0 {
+				continue
+			}
+
+			expected := sha256.Sum256(secret.Data[ref.Key])
+			if subtle.ConstantTimeCompare(digest[:], expected[:]) == 1 {
+				return Principal{
+					Name:   fmt.Sprintf("apikey:%s/%s", key.Namespace, key.Name),
+					apiKey: &apiKeyRef{namespace: key.Namespace, secret: key.Name, key: ref.Key},
+				}, nil
+			}
+		}
+	}
+	return Principal{}, fmt.Errorf("%w: invalid API key", ErrUnauthenticated)
+}
+
+// authenticateToken verifies a bearer token as an OIDC token, or reviews it as a Kubernetes token.
+func (g *Guard) authenticateToken(ctx context.Context, token string) (Principal, time.Duration, error) {
+	var errs []error
+	if g.cfg.OIDCIssuerURL != "" {
+		p, ttl, err := g.authenticateOIDC(ctx, token)
+		if err == nil {
+			return p, ttl, nil
+		}
+		errs = append(errs, err)
+	}
+	if g.cfg.Kubernetes {
+		p, err := g.authenticateKubernetes(ctx, token)
+		if err == nil {
+			return p, credentialsTTL, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return Principal{}, 0, fmt.Errorf("%w: bearer tokens are not accepted", ErrUnauthenticated)
+	}
+	return Principal{}, 0, fmt.Errorf("%w: %w", ErrUnauthenticated, errors.Join(errs...))
+}
+
+func (g *Guard) oidcVerifier() (*oidc.IDTokenVerifier, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.verifier != nil {
+		return g.verifier, nil
+	}
+
+	// the provider is discovered lazily, so the Core can start while the issuer is unavailable.
+	// its keys are refreshed in the background, so it outlives the request's context.
+	provider, err := oidc.NewProvider(context.Background(), g.cfg.OIDCIssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the OIDC issuer: %w", err)
+	}
+	g.verifier = provider.Verifier(&oidc.Config{ClientID: g.cfg.OIDCClientID})
+	return g.verifier, nil
+}
+
+func (g *Guard) authenticateOIDC(ctx context.Context, token string) (Principal, time.Duration, error) {
+	verifier, err := g.oidcVerifier()
+	if err != nil {
+		return Principal{}, 0, err
+	}
+	idToken, err := verifier.Verify(ctx, token)
+	if err != nil {
+		return Principal{}, 0, fmt.Errorf("invalid OIDC token: %w", err)
+	}
+
+	claims := make(map[string]any)
+	if err := idToken.Claims(&claims); err != nil {
+		return Principal{}, 0, fmt.Errorf("failed to parse the OIDC token claims: %w", err)
+	}
+	username, _ := claims[g.cfg.OIDCUsernameClaim].(string)
+	if username == "" {
+		return Principal{}, 0, fmt.Errorf("the OIDC token has no `%s` claim", g.cfg.OIDCUsernameClaim)
+	}
+	// the identities are prefixed, so the issuer can't impersonate Kubernetes identities (i.e. `system:masters`)
+	p := Principal{Name: g.cfg.OIDCUsernamePrefix + username}
+	switch groups := claims[g.cfg.OIDCGroupsClaim].(type) {
+	case string:
+		p.Groups = []string{g.cfg.OIDCGroupsPrefix + groups}
+	case []any:
+		for _, group := range groups {
+			if s, ok := group.(string); ok {
+				p.Groups = append(p.Groups, g.cfg.OIDCGroupsPrefix+s)
+			}
+		}
+	}
+
+	// the identity is never cached beyond the expiration of the token
+	ttl := credentialsTTL
+	if left := time.Until(idToken.Expiry); left < ttl {
+		ttl = left
+	}
+	return p, ttl, nil
+}
+
+func (g *Guard) authenticateKubernetes(ctx context.Context, token string) (Principal, error) {
+	tr := &authnv1.TokenReview{Spec: authnv1.TokenReviewSpec{Token: token}}
+	if err := g.client.Create(ctx, tr); err != nil {
+		return Principal{}, fmt.Errorf("failed to review token: %w", err)
+	}
+	if !tr.Status.Authenticated {
+		return Principal{}, fmt.Errorf("invalid Kubernetes token: %s", tr.Status.Error)
+	}
+	return Principal{Name: tr.Status.User.Username, Groups: tr.Status.User.Groups}, nil
+}
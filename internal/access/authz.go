@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package access
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Authorize checks that the AccessPolicies of the feature's namespace grant the principal with the verb.
+func (g *Guard) Authorize(ctx context.Context, p Principal, verb manifests.AccessVerb, selector string) error {
+	if p.Trusted {
+		return nil
+	}
+
+	ns, name, _, _, _, err := api.ParseSelector(selector)
+	if err != nil {
+		return err
+	}
+	if ns == "" {
+		return fmt.Errorf("namespace is required in Feature Selector `%s`", selector)
+	}
+	return g.authorize(ctx, p, verb, ns, name)
+}
+
+// authorize checks that the AccessPolicies of the namespace grant the principal with the verb on the feature's name.
+func (g *Guard) authorize(ctx context.Context, p Principal, verb manifests.AccessVerb, ns, name string) error {
+	if p.Trusted {
+		return nil
+	}
+
+	policies := &manifests.AccessPolicyList{}
+	if err := g.client.List(ctx, policies, client.InNamespace(ns)); err != nil {
+		return fmt.Errorf("failed to list AccessPolicies: %w", err)
+	}
+	for _, ap := range policies.Items {
+		if !p.subjectOf(ap) {
+			continue
+		}
+		for _, rule := range ap.Spec.Rules {
+			if rule.Allows(verb, name) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: `%s` can't %s `%s` of namespace `%s`", api.ErrPermissionDenied, p.Name, verb, name, ns)
+}
+
+// subjectOf checks if the principal is a subject of the policy.
+func (p Principal) subjectOf(ap manifests.AccessPolicy) bool {
+	if p.apiKey != nil {
+		if p.apiKey.namespace != ap.GetNamespace() {
+			return false
+		}
+		for _, ref := range ap.Spec.Subjects.APIKeys {
+			if ref.Name == p.apiKey.secret && ref.Key == p.apiKey.key {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, user := range ap.Spec.Subjects.Users {
+		if user == p.Name {
+			return true
+		}
+	}
+	for _, group := range ap.Spec.Subjects.Groups {
+		for _, g := range p.Groups {
+			if group == g {
+				return true
+			}
+		}
+	}
+	return false
+}
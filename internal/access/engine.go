@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package access
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"time"
+)
+
+type guardedEngine struct {
+	api.Engine
+	guard *Guard
+}
+
+// Engine wraps the engine, so the requests are authorized by the principal of their context (see Middleware and
+// UnaryServerInterceptor).
+func (g *Guard) Engine(e api.Engine) api.Engine {
+	return &guardedEngine{Engine: e, guard: g}
+}
+
+// AuthorizeContext checks that the principal of the context (see Middleware and UnaryServerInterceptor) is granted
+// with the verb on the feature.
+func (g *Guard) AuthorizeContext(ctx context.Context, verb manifests.AccessVerb, selector string) error {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("%w: the request isn't authenticated", api.ErrPermissionDenied)
+	}
+	return g.Authorize(ctx, p, verb, selector)
+}
+
+func (e *guardedEngine) authorize(ctx context.Context, verb manifests.AccessVerb, selector string) error {
+	return e.guard.AuthorizeContext(ctx, verb, selector)
+}
+
+func (e *guardedEngine) FeatureDescriptor(ctx context.Context, selector string) (api.FeatureDescriptor, error) {
+	if err := e.authorize(ctx, manifests.AccessVerbRead, selector); err != nil {
+		return api.FeatureDescriptor{}, err
+	}
+	return e.Engine.FeatureDescriptor(ctx, selector)
+}
+func (e *guardedEngine) Get(ctx context.Context, selector string, keys api.Keys) (api.Value, api.FeatureDescriptor, error) {
+	if err := e.authorize(ctx, manifests.AccessVerbRead, selector); err != nil {
+		return api.Value{}, api.FeatureDescriptor{}, err
+	}
+	return e.Engine.Get(ctx, selector, keys)
+}
+func (e *guardedEngine) Set(ctx context.Context, fqn string, keys api.Keys, val any, ts time.Time) error {
+	if err := e.authorize(ctx, manifests.AccessVerbWrite, fqn); err != nil {
+		return err
+	}
+	return e.Engine.Set(ctx, fqn, keys, val, ts)
+}
+func (e *guardedEngine) Append(ctx context.Context, fqn string, keys api.Keys, val any, ts time.Time) error {
+	if err := e.authorize(ctx, manifests.AccessVerbWrite, fqn); err != nil {
+		return err
+	}
+	return e.Engine.Append(ctx, fqn, keys, val, ts)
+}
+func (e *guardedEngine) Incr(ctx context.Context, fqn string, keys api.Keys, by any, ts time.Time) error {
+	if err := e.authorize(ctx, manifests.AccessVerbWrite, fqn); err != nil {
+		return err
+	}
+	return e.Engine.Incr(ctx, fqn, keys, by, ts)
+}
+func (e *guardedEngine) Update(ctx context.Context, fqn string, keys api.Keys, val any, ts time.Time) error {
+	if err := e.authorize(ctx, manifests.AccessVerbWrite, fqn); err != nil {
+		return err
+	}
+	return e.Engine.Update(ctx, fqn, keys, val, ts)
+}
+
+// WriteTransaction implements api.Transactor, when the wrapped engine does. Each of the written features should be
+// authorized.
+func (e *guardedEngine) WriteTransaction(ctx context.Context, writes []api.FeatureWrite) error {
+	tx, ok := e.Engine.(api.Transactor)
+	if !ok {
+		return api.ErrTransactionsUnsupported
+	}
+	for _, w := range writes {
+		if err := e.authorize(ctx, manifests.AccessVerbWrite, w.FQN); err != nil {
+			return err
+		}
+	}
+	return tx.WriteTransaction(ctx, writes)
+}
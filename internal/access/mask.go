@@ -0,0 +1,75 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package access
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"hash"
+	"reflect"
+)
+
+// MaskValue masks the value of a sensitive feature (see api.FeatureDescriptor.Sensitive), unless the principal of the
+// context is granted with the `unmask` verb on the feature. Masked values are strings (or lists of strings), or nil
+// when they're redacted, and they're marked as masked.
+func (g *Guard) MaskValue(ctx context.Context, selector string, fd api.FeatureDescriptor, val api.Value) (api.Value, error) {
+	if !fd.Sensitive() || val.Value == nil {
+		return val, nil
+	}
+	err := g.AuthorizeContext(ctx, manifests.AccessVerbUnmask, selector)
+	if err == nil {
+		return val, nil
+	}
+	if !errors.Is(err, api.ErrPermissionDenied) {
+		return api.Value{}, err
+	}
+	val.Value = mask(val.Value, fd.Masking, g.cfg.MaskingKey)
+	val.Masked = true
+	return val, nil
+}
+
+// mask replaces the value by its keyed hash, or by nil when it's redacted. The items of lists are hashed individually.
+func mask(val any, masking api.Masking, key []byte) any {
+	if masking == api.MaskingRedact || val == nil {
+		return nil
+	}
+	if api.TypeDetect(val).Scalar() {
+		return maskScalar(val, key)
+	}
+	rv := reflect.ValueOf(val)
+	ret := make([]string, rv.Len())
+	for i := range ret {
+		ret[i] = maskScalar(rv.Index(i).Interface(), key)
+	}
+	return ret
+}
+
+func maskScalar(val any, key []byte) string {
+	var h hash.Hash
+	if len(key) > 0 {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write([]byte(api.ScalarString(val)))
+	return hex.EncodeToString(h.Sum(nil))
+}
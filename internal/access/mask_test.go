@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package access
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/raptor-ml/raptor/api"
+)
+
+func TestMask(t *testing.T) {
+	key := []byte("secret")
+	hashed := mask("123-45-6789", api.MaskingHash, key)
+	s, ok := hashed.(string)
+	if !ok || len(s) != 64 {
+		t.Fatalf("expected a hex-encoded hash, got %#v", hashed)
+	}
+	if mask("123-45-6789", api.MaskingHash, key) != s {
+		t.Errorf("expected the hash to be stable, so masked values can be joined")
+	}
+	if mask("123-45-6789", api.MaskingHash, []byte("other")) == s {
+		t.Errorf("expected the hash to be keyed")
+	}
+	if mask("123-45-6789", api.MaskingHash, nil) == s {
+		t.Errorf("expected an unkeyed hash to differ from the keyed one")
+	}
+
+	list, ok := mask([]int{1, 2, 1}, api.MaskingHash, key).([]string)
+	if !ok || len(list) != 3 || list[0] != list[2] || list[0] == list[1] {
+		t.Errorf("expected the items of a list to be hashed individually, got %#v", list)
+	}
+	if list[0] != mask(1, api.MaskingHash, key) {
+		t.Errorf("expected the items to be hashed like scalars")
+	}
+
+	for _, v := range []any{"123-45-6789", 42, []string{"a"}} {
+		if got := mask(v, api.MaskingRedact, key); got != nil {
+			t.Errorf("expected a redacted value to be nil, got %#v", got)
+		}
+	}
+	if got := mask(nil, api.MaskingHash, key); !reflect.DeepEqual(got, nil) {
+		t.Errorf("expected nil to stay nil, got %#v", got)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package access
+
+import (
+	"context"
+	"errors"
+	grpcMiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+// localPrincipal is the principal of the requests over the local unix socket.
+var localPrincipal = Principal{Name: "system:local", Trusted: true}
+
+// Middleware authenticates the HTTP requests, and attaches their principal to the request's context.
+func (g *Guard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		creds := CredentialsFromAuthorization(r.Header.Get(APIKeyHeader), r.Header.Get("Authorization"))
+		p, err := g.Authenticate(r.Context(), creds)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(withCaller(r.Context(), p)))
+	})
+}
+
+// AdminMiddleware authenticates the HTTP requests of the admin endpoints, and authorizes them by the `admin` verb: on
+// the feature of the `fqn` query parameter when it's set, and on all the features of the Core's namespace otherwise.
+func (g *Guard) AdminMiddleware(next http.Handler) http.Handler {
+	return g.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := PrincipalFromContext(r.Context())
+
+		var err error
+		if fqn := r.URL.Query().Get("fqn"); fqn != "" {
+			err = g.Authorize(r.Context(), p, manifests.AccessVerbAdmin, fqn)
+		} else {
+			err = g.authorize(r.Context(), p, manifests.AccessVerbAdmin, g.cfg.Namespace, "*")
+		}
+		if errors.Is(err, api.ErrPermissionDenied) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
+
+// UnaryServerInterceptor authenticates the unary gRPC requests.
+func (g *Guard) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := g.authenticateGRPC(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authenticates the streaming gRPC requests.
+func (g *Guard) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := g.authenticateGRPC(ss.Context())
+		if err != nil {
+			return err
+		}
+		wrapped := grpcMiddleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+		return handler(srv, wrapped)
+	}
+}
+
+func (g *Guard) authenticateGRPC(ctx context.Context) (context.Context, error) {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil && p.Addr.Network() == "unix" {
+		return withPrincipal(ctx, localPrincipal), nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	var apiKey, authorization string
+	if vals := md.Get(apiKeyMetadataKey); len(vals) > 0 {
+		apiKey = vals[0]
+	}
+	if vals := md.Get("authorization"); len(vals) > 0 {
+		authorization = vals[0]
+	}
+	p, err := g.Authenticate(ctx, CredentialsFromAuthorization(apiKey, authorization))
+	if err != nil {
+		return ctx, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return withCaller(ctx, p), nil
+}
+
+// withCaller attaches the principal to the context, and records it as the caller of the request (see api.WithCaller).
+func withCaller(ctx context.Context, p Principal) context.Context {
+	return api.WithCaller(withPrincipal(ctx, p), p.Name)
+}
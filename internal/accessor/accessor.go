@@ -30,60 +30,129 @@ import (
 	"github.com/raptor-ml/raptor/api"
 	protoApi "github.com/raptor-ml/raptor/api/proto/gen/go"
 	coreApi "github.com/raptor-ml/raptor/api/proto/gen/go/core/v1alpha1"
+	"github.com/raptor-ml/raptor/internal/access"
+	"github.com/raptor-ml/raptor/internal/lab"
+	"github.com/raptor-ml/raptor/internal/plan"
+	"github.com/raptor-ml/raptor/internal/sharding"
+	"github.com/raptor-ml/raptor/pkg/protocol"
 	"github.com/raptor-ml/raptor/pkg/sdk"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 	"net"
 	"net/http"
 	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 type Accessor interface {
-	GRPC(addr string) NoLeaderRunnableFunc
+	// GRPC serves the gRPC accessor on the address. The connections are secured by the credentials when they're not nil
+	// (i.e. mTLS for the DataSource runners).
+	GRPC(addr string, creds credentials.TransportCredentials) NoLeaderRunnableFunc
 	GrpcUds() NoLeaderRunnableFunc
 	HTTP(addr string, prefix string) NoLeaderRunnableFunc
+	// Feast serves the Feast online serving API on the address (see feastServer). The feature services are resolved
+	// to the Models by the reader, and the features without a feature view are of the namespace.
+	Feast(addr string, r client.Reader, namespace string) NoLeaderRunnableFunc
 }
 
 type accessor struct {
+	engine    api.Engine
 	sdkServer coreApi.EngineServiceServer
 	server    *grpc.Server
+	newServer func(opts ...grpc.ServerOption) *grpc.Server
+	lab       *lab.Lab
+	planner   *plan.Planner
+	guard     *access.Guard
 	logger    logr.Logger
+
+	// unaryInterceptor is the interceptors chain of the unary calls, for the calls that are served over gRPC-web.
+	unaryInterceptor grpc.UnaryServerInterceptor
+	// serving is the engine of the serving API, with the access and privacy controls.
+	serving api.Engine
 }
 
-func New(e api.FeatureManager, logger logr.Logger) Accessor {
+// New creates a new Accessor. The LabSDK endpoints are served by the HTTP accessor when `lb` is not nil, and the
+// manifests planning endpoint when `pl` is not nil. The serving API is authenticated and authorized by the
+// AccessPolicies when `g` is not nil, which also limits the decryption of the values of encrypted features to the
+// callers that are granted with `decrypt`, and masks the values of sensitive features for the callers that aren't
+// granted with `unmask`. The requests of entities that are owned by another replica are forwarded to it when `sh` is
+// not nil.
+func New(e api.FeatureManager, lb *lab.Lab, pl *plan.Planner, g *access.Guard, sh *sharding.Sharder, logger logr.Logger) Accessor {
+	var eng = e.(api.Engine)
+	d, _ := eng.(api.ValueDecrypter)
+	eng = &privacyEngine{Engine: eng, decrypter: d, guard: g}
+	if g != nil {
+		eng = g.Engine(eng)
+	}
+	if sh != nil {
+		// the owner authorizes the forwarded requests and applies the privacy controls, so they're not applied twice
+		eng = sh.Engine(eng, e.(api.Engine).FeatureDescriptor)
+	}
 	svc := &accessor{
-		sdkServer: sdk.NewServiceServer(e.(api.Engine)),
+		engine:    e.(api.Engine),
+		sdkServer: sdk.NewServiceServer(eng),
+		lab:       lb,
+		planner:   pl,
+		guard:     g,
 		logger:    logger,
+		serving:   eng,
 	}
 
 	zapLogger := svc.logger.GetSink().(zapr.Underlier).GetUnderlying()
 
+	caps := []protocol.Capability{protocol.CapabilityRequestContext}
+	if _, ok := svc.engine.(api.WindowInspector); ok {
+		caps = append(caps, protocol.CapabilityWindowInspection)
+	}
+	proto := protocol.Local(caps...)
+
 	grpcMetrics := grpcPrometheus.NewServerMetrics()
 	metrics.Registry.MustRegister(grpcMetrics)
 
-	svc.server = grpc.NewServer(
-		grpc.StreamInterceptor(grpcMiddleware.ChainStreamServer(
-			grpcCtxTags.StreamServerInterceptor(),
-			grpcMetrics.StreamServerInterceptor(),
-			grpcZap.StreamServerInterceptor(zapLogger),
-			grpcValidator.StreamServerInterceptor(),
-		)),
-		grpc.UnaryInterceptor(grpcMiddleware.ChainUnaryServer(
-			grpcCtxTags.UnaryServerInterceptor(),
-			grpcMetrics.UnaryServerInterceptor(),
-			grpcZap.UnaryServerInterceptor(zapLogger),
-			grpcValidator.UnaryServerInterceptor(),
-		)),
-	)
-	coreApi.RegisterEngineServiceServer(svc.server, svc.sdkServer)
-	grpcMetrics.InitializeMetrics(svc.server)
-	reflection.Register(svc.server)
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		grpcCtxTags.StreamServerInterceptor(),
+		grpcMetrics.StreamServerInterceptor(),
+		grpcZap.StreamServerInterceptor(zapLogger),
+	}
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		grpcCtxTags.UnaryServerInterceptor(),
+		grpcMetrics.UnaryServerInterceptor(),
+		grpcZap.UnaryServerInterceptor(zapLogger),
+	}
+	if g != nil {
+		streamInterceptors = append(streamInterceptors, g.StreamServerInterceptor())
+		unaryInterceptors = append(unaryInterceptors, g.UnaryServerInterceptor())
+	}
+
+	svc.unaryInterceptor = grpcMiddleware.ChainUnaryServer(append(unaryInterceptors,
+		proto.UnaryServerInterceptor(),
+		grpcValidator.UnaryServerInterceptor(),
+	)...)
+	svc.newServer = func(opts ...grpc.ServerOption) *grpc.Server {
+		server := grpc.NewServer(append([]grpc.ServerOption{
+			// continues the trace of the caller (if any) from the `traceparent` metadata
+			grpc.StatsHandler(otelgrpc.NewServerHandler()),
+			grpc.StreamInterceptor(grpcMiddleware.ChainStreamServer(append(streamInterceptors,
+				proto.StreamServerInterceptor(),
+				grpcValidator.StreamServerInterceptor(),
+			)...)),
+			grpc.UnaryInterceptor(svc.unaryInterceptor),
+		}, opts...)...)
+		coreApi.RegisterEngineServiceServer(server, svc.sdkServer)
+		grpcMetrics.InitializeMetrics(server)
+		reflection.Register(server)
+		return server
+	}
+	svc.server = svc.newServer()
 
 	return svc
 }
 
-func (a *accessor) GRPC(addr string) NoLeaderRunnableFunc {
+func (a *accessor) GRPC(addr string, creds credentials.TransportCredentials) NoLeaderRunnableFunc {
 	return func(ctx context.Context) error {
 		l, err := net.Listen("tcp", addr)
 		if err != nil {
@@ -91,12 +160,18 @@ func (a *accessor) GRPC(addr string) NoLeaderRunnableFunc {
 			return fmt.Errorf("failed to listen: %w", err)
 		}
 
-		a.logger.WithValues("kind", "grpc", "addr", l.Addr()).Info("Starting Accessor GRPC server")
+		// the credentials are per server, so a secured listener can't share the server with the UDS
+		server := a.server
+		if creds != nil {
+			server = a.newServer(grpc.Creds(creds))
+		}
+
+		a.logger.WithValues("kind", "grpc", "addr", l.Addr(), "tls", creds != nil).Info("Starting Accessor GRPC server")
 		go func() {
 			<-ctx.Done()
-			a.server.Stop()
+			server.Stop()
 		}()
-		return a.server.Serve(l)
+		return server.Serve(l)
 	}
 }
 
@@ -132,27 +207,186 @@ func (a *accessor) GrpcUds() NoLeaderRunnableFunc {
 	}
 }
 
+// admin authenticates and authorizes the requests of an admin endpoint when the authentication is enabled.
+func (a *accessor) admin(h http.HandlerFunc) http.Handler {
+	if a.guard == nil {
+		return h
+	}
+	return a.guard.AdminMiddleware(h)
+}
+
 func (a *accessor) HTTP(addr string, prefix string) NoLeaderRunnableFunc {
 	return func(ctx context.Context) error {
-		gwMux := runtime.NewServeMux()
+		gwMux := runtime.NewServeMux(runtime.SetQueryParameterParser(&keysQueryParser{}))
 		err := coreApi.RegisterEngineServiceHandlerServer(ctx, gwMux, a.sdkServer)
 		if err != nil {
 			return fmt.Errorf("failed to register grpc gateway: %w", err)
 		}
 
-		if prefix[len(prefix)-1] == '/' {
+		if prefix == "" || prefix[len(prefix)-1] != '/' {
 			prefix += "/"
 		}
+		var apiHandler http.Handler = http.StripPrefix(prefix[:len(prefix)-1], gwMux)
+		if a.guard != nil {
+			apiHandler = a.guard.Middleware(apiHandler)
+		}
 		mux := http.NewServeMux()
-		mux.Handle(prefix[:len(prefix)-1], http.StripPrefix(fmt.Sprintf("%s/", prefix), gwMux))
+		mux.Handle(prefix, apiHandler)
+		// the gRPC-web calls are authenticated by the interceptors, as the gRPC calls
+		mux.Handle(fmt.Sprintf("%s%s/", prefix, coreApi.EngineService_ServiceDesc.ServiceName), a.grpcWebHandler(prefix))
 
 		mux.HandleFunc(fmt.Sprintf("%sapidocs.swagger.yaml", prefix), func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/x-yaml")
 			_, _ = w.Write(protoApi.ApiDocs)
 		})
+		// the admin routes are documented by the OpenAPI document, along with the routes of the gRPC gateway
+		var routes []route
+		handle := func(r route, h http.HandlerFunc) {
+			mux.Handle(prefix+r.path, a.admin(h))
+			routes = append(routes, r)
+		}
+		fqnParam := queryParam("fqn", "The FQN of the feature.")
+		if wi, ok := a.engine.(api.WindowInspector); ok {
+			handle(route{
+				path: "admin/windows", method: http.MethodGet, operationID: "Admin_InspectWindow",
+				summary: "Dumps the window state of a feature for an entity.",
+				params:  []oaParameter{fqnParam, queryParam("entity_id", "The ID of the entity, of single-keyed features.")},
+			}, a.inspectWindowHandler(wi))
+		}
+		if cr, ok := a.engine.(api.ChecksumReporter); ok {
+			handle(route{
+				path: "admin/checksums", method: http.MethodGet, operationID: "Admin_Checksums",
+				summary: "Lists the checksums of the features that are bound to the replica.",
+				params:  []oaParameter{fqnParam},
+			}, a.checksumsHandler(cr))
+		}
+		if dr, ok := a.engine.(api.DeadLetterReplayer); ok {
+			handle(route{
+				path: "admin/dlq", method: http.MethodGet, operationID: "Admin_DeadLetters",
+				summary: "Lists the latest dead-letters.",
+				params:  []oaParameter{fqnParam, queryParam("limit", "The maximal number of dead-letters.")},
+			}, a.deadLettersHandler(dr))
+			handle(route{
+				path: "admin/dlq/replay", method: http.MethodPost, operationID: "Admin_ReplayDeadLetter",
+				summary: "Replays a dead-letter, and removes it from the queue if it succeeded.",
+				params:  []oaParameter{queryParam("id", "The ID of the dead-letter.")},
+			}, a.replayDeadLetterHandler(dr))
+		}
+		if ed, ok := a.engine.(api.EntityDeleter); ok {
+			handle(route{
+				path: "admin/entities", method: http.MethodDelete, operationID: "Admin_DeleteEntity",
+				summary: "Removes the values of an entity from every feature that is keyed by the entity type.",
+				params: []oaParameter{
+					queryParam("entity_type", "The key of the entity type."),
+					queryParam("entity_id", "The ID of the entity."),
+				},
+			}, a.deleteEntityHandler(ed))
+		}
+		if as, ok := a.engine.(api.AliasStore); ok {
+			aliasParams := []oaParameter{
+				queryParam("alias_type", "The type of the alternate ID (i.e. `email_hash`)."),
+				queryParam("alias_id", "The alternate ID."),
+			}
+			handle(route{
+				path: "admin/aliases", method: http.MethodPut, operationID: "Admin_SetAlias",
+				summary: "Maps an alternate ID of an entity to its canonical ID.",
+				body: &oaBody{Required: true, Content: jsonContent(schema{
+					"type": "object",
+					"properties": map[string]any{
+						"alias_type":  schema{"type": "string"},
+						"alias_id":    schema{"type": "string"},
+						"entity_type": schema{"type": "string"},
+						"entity_id":   schema{"type": "string"},
+					},
+				})},
+			}, a.aliasesHandler(as))
+			// the same handler serves the lookups and the removals of the aliases
+			routes = append(routes, route{
+				path: "admin/aliases", method: http.MethodGet, operationID: "Admin_GetAlias",
+				summary: "Returns the canonical ID an alternate ID of an entity is mapped to.",
+				params:  aliasParams,
+			}, route{
+				path: "admin/aliases", method: http.MethodDelete, operationID: "Admin_DeleteAlias",
+				summary: "Removes the mapping of an alternate ID of an entity.",
+				params:  aliasParams,
+			})
+		}
+		if ss, ok := a.engine.(api.StateSnapshotter); ok {
+			handle(route{
+				path: "admin/snapshot", method: http.MethodGet, operationID: "Admin_Snapshot",
+				summary:     "Streams the stored data of the features as newline-delimited JSON records.",
+				params:      []oaParameter{queryParam("namespace", "The namespace of the features."), fqnParam},
+				contentType: "application/x-ndjson",
+			}, a.snapshotHandler(ss))
+			handle(route{
+				path: "admin/snapshot/restore", method: http.MethodPost, operationID: "Admin_RestoreSnapshot",
+				summary: "Restores the newline-delimited JSON records of a snapshot.",
+				body: &oaBody{Required: true, Content: map[string]oaMediaType{
+					"application/x-ndjson": {Schema: schema{"type": "string"}},
+				}},
+			}, a.restoreSnapshotHandler(ss))
+		}
+		if sa, ok := a.engine.(api.StalenessAdvisor); ok {
+			handle(route{
+				path: "admin/recommendations", method: http.MethodGet, operationID: "Admin_Recommendations",
+				summary: "Recommends the freshness, staleness and retention of the features.",
+				params:  []oaParameter{fqnParam},
+			}, a.recommendationsHandler(sa))
+		}
+		if vm, ok := a.engine.(api.ValueMonitor); ok {
+			handle(route{
+				path: "admin/values", method: http.MethodGet, operationID: "Admin_ValueStats",
+				summary: "Reports the distribution of the sampled values of the features, and their drift.",
+				params:  []oaParameter{fqnParam},
+			}, a.valueStatsHandler(vm))
+		}
+		if fc, ok := a.engine.(api.FeatureCatalog); ok {
+			// the catalog is served to the callers of the API, rather than to the admins
+			var h http.Handler = a.catalogHandler(fc)
+			if a.guard != nil {
+				h = a.guard.Middleware(h)
+			}
+			mux.Handle(prefix+"v1/catalog", h)
+			routes = append(routes, route{
+				path: "v1/catalog", method: http.MethodGet, operationID: "Catalog_List", tag: "Catalog",
+				summary: "Lists the features that match the query, so existing features can be discovered.",
+				params: []oaParameter{
+					queryParam("q", "Words to search in the FQN, description, owner and tags of the features."),
+					queryParam("namespace", "The namespace of the features."),
+					queryParam("owner", "The owner of the features."),
+					queryParam("entity", "The entity of the features."),
+					queryParam("tag", "A tag the features must have. Can be repeated, or comma-separated."),
+				},
+			})
+		}
+		handle(route{
+			path: "admin/builder/replay", method: http.MethodPost, operationID: "Admin_BuilderReplay",
+			summary: "Replays recorded events through a modified version of a feature's builder.",
+			body: &oaBody{Required: true, Content: jsonContent(schema{
+				"type": "object",
+				"properties": map[string]any{
+					"manifest": schema{"type": "string"},
+					"limit":    schema{"type": "integer"},
+					"records":  schema{"type": "array", "items": schema{"type": "object"}},
+				},
+			})},
+		}, a.builderReplayHandler())
+		if a.planner != nil {
+			mux.Handle(fmt.Sprintf("%sadmin/plan", prefix), a.admin(a.planner.Handler()))
+		}
+
+		doc, err := a.openAPIDocument(prefix, routes)
+		if err != nil {
+			return fmt.Errorf("failed to generate the OpenAPI document: %w", err)
+		}
+		mux.Handle(fmt.Sprintf("%sopenapi.json", prefix), openAPIHandler(doc))
+		mux.Handle(fmt.Sprintf("%sdocs/", prefix), swaggerUIHandler(fmt.Sprintf("%sopenapi.json", prefix)))
+		if a.lab != nil {
+			a.lab.Register(mux, prefix)
+		}
 
 		a.logger.WithValues("kind", "http", "addr", addr).Info("Starting Accessor HTTP server")
-		srv := http.Server{Handler: mux, Addr: addr}
+		srv := http.Server{Handler: otelhttp.NewHandler(mux, "accessor"), Addr: addr}
 		go func() {
 			<-ctx.Done()
 			_ = srv.Shutdown(context.TODO())
@@ -0,0 +1,565 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/simulate"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// entityIDParam is a shorthand for the value of the key of single-keyed features.
+const entityIDParam = "entity_id"
+
+// inspectWindowHandler returns a handler that dumps the window state of a feature for a given entity.
+//
+// Usage: GET <prefix>admin/windows?fqn=<fqn>&entity_id=<id>
+// or, for features with multiple keys: GET <prefix>admin/windows?fqn=<fqn>&<key>=<value>&<key2>=<value2>
+func (a *accessor) inspectWindowHandler(wi api.WindowInspector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		fqn := q.Get("fqn")
+		if fqn == "" {
+			http.Error(w, "`fqn` is required", http.StatusBadRequest)
+			return
+		}
+
+		fd, err := a.engine.FeatureDescriptor(r.Context(), fqn)
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+
+		keys := api.Keys{}
+		for k := range q {
+			if k != "fqn" && k != entityIDParam {
+				keys[k] = q.Get(k)
+			}
+		}
+		if id := q.Get(entityIDParam); id != "" {
+			if len(fd.Keys) != 1 {
+				http.Error(w, fmt.Sprintf("`%s` can be used only with single-keyed features. use the key names instead: %v", entityIDParam, fd.Keys), http.StatusBadRequest)
+				return
+			}
+			keys[fd.Keys[0]] = id
+		}
+
+		ret, err := wi.InspectWindow(r.Context(), fqn, keys)
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(ret); err != nil {
+			a.logger.Error(err, "failed to encode window inspection")
+		}
+	}
+}
+
+// checksumsHandler returns a handler that lists the checksums of the features that are bound to this replica, so they
+// can be compared across the replicas and with the Features' status.
+//
+// Usage: GET <prefix>admin/checksums or GET <prefix>admin/checksums?fqn=<fqn>
+func (a *accessor) checksumsHandler(cr api.ChecksumReporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		ret := cr.FeatureChecksums()
+		if fqn := r.URL.Query().Get("fqn"); fqn != "" {
+			sum, ok := ret[fqn]
+			if !ok {
+				httpError(w, fmt.Errorf("%w: %s", api.ErrFeatureNotFound, fqn))
+				return
+			}
+			ret = map[string]string{fqn: sum}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(ret); err != nil {
+			a.logger.Error(err, "failed to encode checksums")
+		}
+	}
+}
+
+// deadLettersHandler returns a handler that lists the latest dead-letters, optionally only of a given feature.
+//
+// Usage: GET <prefix>admin/dlq or GET <prefix>admin/dlq?fqn=<fqn>&limit=<limit>
+func (a *accessor) deadLettersHandler(ds api.DeadLetterStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		limit := 0
+		if l := q.Get("limit"); l != "" {
+			var err error
+			limit, err = strconv.Atoi(l)
+			if err != nil {
+				http.Error(w, "`limit` must be a number", http.StatusBadRequest)
+				return
+			}
+		}
+
+		ret, err := ds.DeadLetters(r.Context(), q.Get("fqn"), limit)
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+		if ret == nil {
+			ret = []api.DeadLetter{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(ret); err != nil {
+			a.logger.Error(err, "failed to encode dead-letters")
+		}
+	}
+}
+
+// replayDeadLetterHandler returns a handler that replays a dead-letter, and removes it from the queue if it succeeded.
+//
+// Usage: POST <prefix>admin/dlq/replay?id=<id>
+func (a *accessor) replayDeadLetterHandler(dr api.DeadLetterReplayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			http.Error(w, "`id` is required", http.StatusBadRequest)
+			return
+		}
+		if err := dr.ReplayDeadLetter(r.Context(), id); err != nil {
+			httpError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// recommendationsHandler returns a handler that recommends the freshness, staleness and retention of the features,
+// by their read and write patterns as observed by this replica.
+//
+// Usage: GET <prefix>admin/recommendations or GET <prefix>admin/recommendations?fqn=<fqn>
+func (a *accessor) recommendationsHandler(sa api.StalenessAdvisor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		ret, err := sa.StalenessRecommendations(r.Context(), r.URL.Query().Get("fqn"))
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(ret); err != nil {
+			a.logger.Error(err, "failed to encode recommendations")
+		}
+	}
+}
+
+// valueStatsHandler returns a handler that reports the distribution of the sampled values of the features, and their
+// drift relative to the previous monitoring window, as observed by this replica.
+//
+// Usage: GET <prefix>admin/values or GET <prefix>admin/values?fqn=<fqn>
+func (a *accessor) valueStatsHandler(vm api.ValueMonitor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		ret, err := vm.ValueStats(r.Context(), r.URL.Query().Get("fqn"))
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(ret); err != nil {
+			a.logger.Error(err, "failed to encode value stats")
+		}
+	}
+}
+
+// deleteEntityHandler returns a handler that removes the values of an entity from every feature that is keyed by the
+// entity type, and records their deletion in the historical storage (i.e. for the right to be forgotten of the GDPR).
+//
+// Usage: DELETE <prefix>admin/entities?entity_type=<key>&entity_id=<id>
+func (a *accessor) deleteEntityHandler(ed api.EntityDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		entityType, entityID := q.Get("entity_type"), q.Get(entityIDParam)
+		if entityType == "" || entityID == "" {
+			http.Error(w, fmt.Sprintf("`entity_type` and `%s` are required", entityIDParam), http.StatusBadRequest)
+			return
+		}
+
+		ret, err := ed.DeleteEntity(r.Context(), entityType, entityID)
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(ret); err != nil {
+			a.logger.Error(err, "failed to encode entity deletion")
+		}
+	}
+}
+
+// aliasesHandler returns a handler that maps the alternate IDs of entities (i.e. the hash of a user's email) to their
+// canonical IDs, so features can be read and written by the alternate IDs.
+//
+// Usage: PUT <prefix>admin/aliases with a JSON body of `{"alias_type": "email_hash", "alias_id": "<id>",
+// "entity_type": "user_id", "entity_id": "<id>"}`, GET <prefix>admin/aliases?alias_type=<type>&alias_id=<id> or
+// DELETE <prefix>admin/aliases?alias_type=<type>&alias_id=<id>
+func (a *accessor) aliasesHandler(as api.AliasStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var alias api.EntityAlias
+		switch r.Method {
+		case http.MethodPut:
+			if err := json.NewDecoder(r.Body).Decode(&alias); err != nil {
+				http.Error(w, fmt.Sprintf("failed to decode the alias: %s", err), http.StatusBadRequest)
+				return
+			}
+			if err := alias.Validate(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := as.SetAlias(r.Context(), alias); err != nil {
+				httpError(w, err)
+				return
+			}
+		case http.MethodGet, http.MethodDelete:
+			q := r.URL.Query()
+			aliasType, aliasID := q.Get("alias_type"), q.Get("alias_id")
+			if aliasType == "" || aliasID == "" {
+				http.Error(w, "`alias_type` and `alias_id` are required", http.StatusBadRequest)
+				return
+			}
+			var err error
+			if r.Method == http.MethodDelete {
+				alias = api.EntityAlias{AliasType: aliasType, AliasID: aliasID}
+				err = as.DeleteAlias(r.Context(), aliasType, aliasID)
+			} else {
+				alias, err = as.GetAlias(r.Context(), aliasType, aliasID)
+			}
+			if err != nil {
+				httpError(w, err)
+				return
+			}
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(alias); err != nil {
+			a.logger.Error(err, "failed to encode entity alias")
+		}
+	}
+}
+
+// restoreBatchSize is the number of snapshot records that are restored at once.
+const restoreBatchSize = 500
+
+// snapshotHandler returns a handler that streams the stored data of the features as newline-delimited JSON records,
+// so they can be restored into another cluster (see restoreSnapshotHandler).
+// The features are selected by their FQNs, or by their namespace (of the features that are bound to this replica).
+//
+// Usage: GET <prefix>admin/snapshot?namespace=<ns> or GET <prefix>admin/snapshot?fqn=<fqn>&fqn=<fqn2>
+func (a *accessor) snapshotHandler(ss api.StateSnapshotter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		fqns := q["fqn"]
+		if ns := q.Get("namespace"); ns != "" {
+			cr, ok := a.engine.(api.ChecksumReporter)
+			if !ok {
+				http.Error(w, "the engine can't list its features. use `fqn` instead", http.StatusBadRequest)
+				return
+			}
+			for fqn := range cr.FeatureChecksums() {
+				if fns, _, _, _, _, err := api.ParseSelector(fqn); err == nil && fns == ns {
+					fqns = append(fqns, fqn)
+				}
+			}
+		}
+		if len(fqns) == 0 {
+			http.Error(w, "`namespace` or `fqn` is required, and should match at least one feature", http.StatusBadRequest)
+			return
+		}
+		sort.Strings(fqns)
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for _, fqn := range fqns {
+			n, err := ss.SnapshotFeature(r.Context(), fqn, func(rec api.StateSnapshotRecord) error {
+				return enc.Encode(rec)
+			})
+			if err != nil {
+				// the response is already partially written, so the error is only logged and the stream is cut
+				a.logger.Error(err, "failed to snapshot feature", "fqn", fqn, "records", n)
+				panic(http.ErrAbortHandler)
+			}
+		}
+	}
+}
+
+// restoreSnapshotHandler returns a handler that restores the newline-delimited JSON records of a snapshot (see
+// snapshotHandler), and reports the number of restored records of each feature.
+//
+// Usage: POST <prefix>admin/snapshot/restore
+func (a *accessor) restoreSnapshotHandler(ss api.StateSnapshotter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		ret := make(map[string]int)
+		batch := make([]api.StateSnapshotRecord, 0, restoreBatchSize)
+		restore := func() error {
+			if err := ss.RestoreSnapshot(r.Context(), batch); err != nil {
+				return err
+			}
+			for _, rec := range batch {
+				ret[rec.FQN]++
+			}
+			batch = batch[:0]
+			return nil
+		}
+
+		dec := json.NewDecoder(r.Body)
+		for {
+			var rec api.StateSnapshotRecord
+			err := dec.Decode(&rec)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid snapshot record: %s", err), http.StatusBadRequest)
+				return
+			}
+			batch = append(batch, rec)
+			if len(batch) == restoreBatchSize {
+				if err := restore(); err != nil {
+					httpError(w, err)
+					return
+				}
+			}
+		}
+		if err := restore(); err != nil {
+			httpError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(map[string]any{"features": ret}); err != nil {
+			a.logger.Error(err, "failed to encode snapshot restoration")
+		}
+	}
+}
+
+// replaySuffix is appended to the name of the replayed features when their program is loaded to the runtime.
+const replaySuffix = "-replay"
+
+type builderReplayRequest struct {
+	// Manifest is the modified Feature manifest (YAML or JSON).
+	Manifest string `json:"manifest"`
+	// Records are the recorded events to replay, in the format of the dead-letters (i.e. a capture of the events).
+	// When empty, the latest dead-letters of the feature are replayed.
+	Records []api.DeadLetter `json:"records,omitempty"`
+	// Limit is the maximal number of dead-letters to replay.
+	Limit int `json:"limit,omitempty"`
+}
+
+type replayedValue struct {
+	Value any    `json:"value,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type replayedRecord struct {
+	ID        string          `json:"id,omitempty"`
+	Keys      api.Keys        `json:"keys,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+	Original  replayedValue   `json:"original"`
+	Replayed  simulate.Result `json:"replayed"`
+	// Changed indicates that the replayed value (or error) differs from the original one.
+	Changed bool `json:"changed"`
+}
+
+// builderReplayHandler returns a handler that replays recorded events through a modified version of a feature's
+// builder, and diffs the outputs against the original values, so bugfixes can be validated before the feature is
+// redeployed. Nothing is persisted, and the dead-letters are kept in the queue.
+// The recorded events are already mapped by the DataSource, so its mapping isn't applied again. Manifests without a
+// namespace are replayed in the `default` namespace.
+//
+// Usage: POST <prefix>admin/builder/replay with a JSON body of `{"manifest": "<yaml>", "limit": 100}` to replay the
+// dead-letters of the feature, or `{"manifest": "<yaml>", "records": [<dead-letter>...]}` to replay captured events.
+func (a *accessor) builderReplayHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		req := builderReplayRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode the request: %s", err), http.StatusBadRequest)
+			return
+		}
+		ft, err := simulate.DecodeFeature(req.Manifest)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if ft.GetNamespace() == "" {
+			ft.SetNamespace("default")
+		}
+		fqn := ft.FQN()
+
+		records := req.Records
+		if len(records) == 0 {
+			ds, ok := a.engine.(api.DeadLetterStore)
+			if !ok {
+				http.Error(w, "the engine doesn't support listing dead-letters, so `records` are required", http.StatusBadRequest)
+				return
+			}
+			if records, err = ds.DeadLetters(r.Context(), fqn, req.Limit); err != nil {
+				httpError(w, err)
+				return
+			}
+		}
+
+		var events []simulate.Event
+		ret := make([]replayedRecord, 0, len(records))
+		for _, rec := range records {
+			if rec.Notification != "" {
+				// the failed notifications of the historian weren't computations
+				continue
+			}
+			if rec.FQN != "" && rec.FQN != fqn {
+				http.Error(w, fmt.Sprintf("the record %s is of feature %s, rather than %s", rec.ID, rec.FQN, fqn), http.StatusBadRequest)
+				return
+			}
+			events = append(events, simulate.Event{Row: rec.Row, Timestamp: rec.Timestamp})
+			ret = append(ret, replayedRecord{
+				ID:        rec.ID,
+				Keys:      rec.Keys,
+				Timestamp: rec.Timestamp,
+				Original:  replayedValue{Value: rec.Value, Error: rec.Error},
+			})
+		}
+
+		sim := &simulate.Simulator{Engine: a.engine}
+		results, err := sim.Run(r.Context(), ft, events, simulate.Options{Suffix: replaySuffix, Mapped: true})
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+		changed, fixed := 0, 0
+		for i, res := range results {
+			ret[i].Replayed = res
+			ret[i].Changed = ret[i].Original.Error != res.Error || !sameValue(ret[i].Original.Value, res.Value)
+			if ret[i].Changed {
+				changed++
+			}
+			if ret[i].Original.Error != "" && res.Error == "" && res.Value != nil {
+				fixed++
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(map[string]any{
+			"fqn":      fqn,
+			"replayed": len(ret),
+			"changed":  changed,
+			"fixed":    fixed,
+			"records":  ret,
+		})
+		if err != nil {
+			a.logger.Error(err, "failed to encode builder replay")
+		}
+	}
+}
+
+// sameValue compares values by their JSON encoding, since the recorded values were decoded from JSON.
+func sameValue(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+func httpError(w http.ResponseWriter, err error) {
+	if errors.Is(err, api.ErrFeatureNotFound) || errors.Is(err, api.ErrDeadLetterNotFound) || errors.Is(err, api.ErrAliasNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessor
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+)
+
+// catalogHandler returns a handler that lists the features that match the query, so teams can discover existing
+// features instead of re-creating them. When access control is enabled, only the features the caller is allowed to
+// read are listed.
+//
+// Usage: GET <prefix>v1/catalog?q=<search>&namespace=<ns>&owner=<owner>&entity=<entity>&tag=<tag>&tag=<tag2>
+func (a *accessor) catalogHandler(fc api.FeatureCatalog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		params := r.URL.Query()
+		q := api.CatalogQuery{
+			Search:    params.Get("q"),
+			Namespace: params.Get("namespace"),
+			Owner:     params.Get("owner"),
+			Entity:    params.Get("entity"),
+		}
+		for _, tags := range params["tag"] {
+			for _, t := range strings.Split(tags, ",") {
+				if t = strings.TrimSpace(t); t != "" {
+					q.Tags = append(q.Tags, t)
+				}
+			}
+		}
+
+		ret := make([]api.CatalogEntry, 0)
+		for _, ce := range fc.Catalog(r.Context(), q) {
+			if a.guard != nil && a.guard.AuthorizeContext(r.Context(), manifests.AccessVerbRead, ce.FQN) != nil {
+				continue
+			}
+			ret = append(ret, ce)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(ret); err != nil {
+			a.logger.Error(err, "failed to encode the catalog")
+		}
+	}
+}
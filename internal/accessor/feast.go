@@ -0,0 +1,418 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	feastApi "github.com/raptor-ml/raptor/api/proto/gen/go/feast/serving"
+	feastTypes "github.com/raptor-ml/raptor/api/proto/gen/go/feast/types"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"net"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// feastServingVersion is reported by GetFeastServingInfo.
+const feastServingVersion = "raptor"
+
+// maxFeastConcurrency is the maximal number of values that are read concurrently by a GetOnlineFeatures call.
+const maxFeastConcurrency = 64
+
+// feastServer implements the Feast online serving API over the Raptor features, so the model servers that use a Feast
+// client can switch to Raptor without changing their code:
+//   - A feature reference `view:feature` is the feature `view.feature` (i.e. the feature view is the namespace), and
+//     a reference without a feature view is a feature of the default namespace. The references can use the Raptor
+//     selectors' syntax, i.e. `view:clicks+count`.
+//   - A feature service is a Model (`name` of the default namespace, or `namespace.name`).
+//   - The entities are the keys of the features.
+//   - The request context is passed to the features, as the `x-raptor-request-context` of the Core's API.
+type feastServer struct {
+	engine    api.Engine
+	reader    client.Reader
+	namespace string
+}
+
+type feastFeature struct {
+	selector string
+	name     string
+}
+
+func (s *feastServer) GetFeastServingInfo(context.Context, *feastApi.GetFeastServingInfoRequest) (*feastApi.GetFeastServingInfoResponse, error) {
+	return &feastApi.GetFeastServingInfoResponse{Version: feastServingVersion}, nil
+}
+
+func (s *feastServer) GetOnlineFeatures(ctx context.Context, req *feastApi.GetOnlineFeaturesRequest) (*feastApi.GetOnlineFeaturesResponse, error) {
+	var features []feastFeature
+	var err error
+	switch kind := req.GetKind().(type) {
+	case *feastApi.GetOnlineFeaturesRequest_FeatureService:
+		features, err = s.featureService(ctx, kind.FeatureService, req.GetFullFeatureNames())
+	case *feastApi.GetOnlineFeaturesRequest_Features:
+		features, err = s.featureRefs(kind.Features.GetVal(), req.GetFullFeatureNames())
+	default:
+		err = status.Error(codes.InvalidArgument, "either a feature service or a list of features must be specified")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entityNames := make([]string, 0, len(req.GetEntities()))
+	for name := range req.GetEntities() {
+		entityNames = append(entityNames, name)
+	}
+	sort.Strings(entityNames)
+	rows, err := feastRows(req.GetEntities(), entityNames, req.GetRequestContext())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &feastApi.GetOnlineFeaturesResponse{
+		Metadata: &feastApi.GetOnlineFeaturesResponseMetadata{FeatureNames: &feastApi.FeatureList{}},
+		Status:   true,
+	}
+	// the entities are returned as the first columns, as Feast does
+	for _, name := range entityNames {
+		col := &feastApi.GetOnlineFeaturesResponse_FeatureVector{Values: req.GetEntities()[name].GetVal()}
+		for range col.Values {
+			col.Statuses = append(col.Statuses, feastApi.FieldStatus_PRESENT)
+			col.EventTimestamps = append(col.EventTimestamps, &timestamppb.Timestamp{})
+		}
+		resp.Metadata.FeatureNames.Val = append(resp.Metadata.FeatureNames.Val, name)
+		resp.Results = append(resp.Results, col)
+	}
+
+	cols := make([]*feastApi.GetOnlineFeaturesResponse_FeatureVector, len(features))
+	for i, f := range features {
+		cols[i] = &feastApi.GetOnlineFeaturesResponse_FeatureVector{
+			Values:          make([]*feastTypes.Value, len(rows)),
+			Statuses:        make([]feastApi.FieldStatus, len(rows)),
+			EventTimestamps: make([]*timestamppb.Timestamp, len(rows)),
+		}
+		resp.Metadata.FeatureNames.Val = append(resp.Metadata.FeatureNames.Val, f.name)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	sem := make(chan struct{}, maxFeastConcurrency)
+	for r, row := range rows {
+		for i, f := range features {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(r int, row feastRow, i int, f feastFeature) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				val, st, ts, err := s.get(ctx, f.selector, row)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				cols[i].Values[r], cols[i].Statuses[r], cols[i].EventTimestamps[r] = val, st, ts
+			}(r, row, i, f)
+		}
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	resp.Results = append(resp.Results, cols...)
+	return resp, nil
+}
+
+// get reads the value of the feature of an entity, and returns it with its status and timestamp.
+func (s *feastServer) get(ctx context.Context, selector string, row feastRow) (*feastTypes.Value, feastApi.FieldStatus, *timestamppb.Timestamp, error) {
+	if len(row.context) > 0 {
+		ctx = api.WithRequestContext(ctx, row.context)
+	}
+	val, fd, err := s.engine.Get(ctx, selector, row.keys)
+	if err != nil {
+		if errors.Is(err, api.ErrFeatureNotFound) {
+			return nil, 0, nil, status.Errorf(codes.NotFound, "feature %s not found", selector)
+		}
+		if errors.Is(err, api.ErrPermissionDenied) {
+			return nil, 0, nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+		return nil, 0, nil, status.Errorf(codes.Internal, "failed to get the value of %s: %s", selector, err)
+	}
+
+	v := val.Value
+	if r, ok := v.(api.WindowResultMap); ok {
+		if len(fd.Aggr) != 1 {
+			return nil, 0, nil, status.Errorf(codes.InvalidArgument,
+				"the feature %s is windowed, so the reference must specify the window function, i.e. `%s+<fn>`",
+				selector, selector)
+		}
+		v = r[fd.Aggr[0]]
+	}
+	if v == nil {
+		return &feastTypes.Value{}, feastApi.FieldStatus_NOT_FOUND, &timestamppb.Timestamp{}, nil
+	}
+	st := feastApi.FieldStatus_PRESENT
+	if !val.Fresh {
+		st = feastApi.FieldStatus_OUTSIDE_MAX_AGE
+	}
+	return toFeastValue(v), st, timestamppb.New(val.Timestamp), nil
+}
+
+// featureRefs returns the features of the Feast feature references.
+func (s *feastServer) featureRefs(refs []string, fullNames bool) ([]feastFeature, error) {
+	if len(refs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no features were requested")
+	}
+	ret := make([]feastFeature, 0, len(refs))
+	for _, ref := range refs {
+		view, name, ok := strings.Cut(ref, ":")
+		if !ok {
+			view, name = s.namespace, ref
+		}
+		selector, err := api.NormalizeSelector(fmt.Sprintf("%s.%s", view, name), s.namespace)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid feature reference %s: %s", ref, err)
+		}
+		ret = append(ret, feastFeature{selector: selector, name: feastFeatureName(view, name, fullNames)})
+	}
+	return ret, nil
+}
+
+// featureService returns the features of the Model of the feature service.
+func (s *feastServer) featureService(ctx context.Context, name string, fullNames bool) ([]feastFeature, error) {
+	if s.reader == nil {
+		return nil, status.Error(codes.Unimplemented, "feature services are not supported")
+	}
+	key := types.NamespacedName{Namespace: s.namespace, Name: name}
+	if ns, n, ok := strings.Cut(name, "."); ok {
+		key = types.NamespacedName{Namespace: ns, Name: n}
+	}
+	model := &manifests.Model{}
+	if err := s.reader.Get(ctx, key, model); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, status.Errorf(codes.NotFound, "feature service %s not found", name)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get the model of feature service %s: %s", name, err)
+	}
+
+	ret := make([]feastFeature, 0, len(model.Spec.Features))
+	for _, f := range model.Spec.Features {
+		selector, err := api.NormalizeSelector(f, model.Namespace)
+		if err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "invalid feature %s of feature service %s: %s", f, name, err)
+		}
+		view, fn, _ := strings.Cut(selector, ".")
+		ret = append(ret, feastFeature{selector: selector, name: feastFeatureName(view, fn, fullNames)})
+	}
+	return ret, nil
+}
+
+func feastFeatureName(view, name string, fullNames bool) string {
+	if fullNames {
+		return fmt.Sprintf("%s__%s", view, name)
+	}
+	return name
+}
+
+// feastRow is an entity of a GetOnlineFeatures request.
+type feastRow struct {
+	keys    api.Keys
+	context map[string]any
+}
+
+// feastRows transposes the columns of the entities (and the request context) of a request to rows.
+func feastRows(entities map[string]*feastTypes.RepeatedValue, names []string, rc map[string]*feastTypes.RepeatedValue) ([]feastRow, error) {
+	if len(entities) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no entities were specified")
+	}
+	n := len(entities[names[0]].GetVal())
+	for _, name := range names {
+		if l := len(entities[name].GetVal()); l != n {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"the entities must have the same number of values, but %s has %d and %s has %d", names[0], n, name, l)
+		}
+	}
+	for name, vals := range rc {
+		if l := len(vals.GetVal()); l != n {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"the request context must have a value per entity, but %s has %d values for %d entities", name, l, n)
+		}
+	}
+
+	rows := make([]feastRow, n)
+	for i := range rows {
+		rows[i].keys = make(api.Keys, len(names))
+		for _, name := range names {
+			k, err := feastKey(entities[name].GetVal()[i])
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid value of entity %s: %s", name, err)
+			}
+			rows[i].keys[name] = k
+		}
+		if len(rc) == 0 {
+			continue
+		}
+		rows[i].context = make(map[string]any, len(rc))
+		for name, vals := range rc {
+			v, err := api.NormalizeAny(fromFeastValue(vals.GetVal()[i]))
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid value of request context %s: %s", name, err)
+			}
+			rows[i].context[name] = v
+		}
+	}
+	return rows, nil
+}
+
+// feastKey returns the key of a Feast entity value.
+func feastKey(v *feastTypes.Value) (string, error) {
+	switch val := v.GetVal().(type) {
+	case *feastTypes.Value_StringVal:
+		return val.StringVal, nil
+	case *feastTypes.Value_Int64Val:
+		return strconv.FormatInt(val.Int64Val, 10), nil
+	case *feastTypes.Value_Int32Val:
+		return strconv.FormatInt(int64(val.Int32Val), 10), nil
+	case *feastTypes.Value_BytesVal:
+		return string(val.BytesVal), nil
+	case *feastTypes.Value_BoolVal:
+		return strconv.FormatBool(val.BoolVal), nil
+	case *feastTypes.Value_UnixTimestampVal:
+		return strconv.FormatInt(val.UnixTimestampVal, 10), nil
+	default:
+		return "", fmt.Errorf("unsupported key type %T", val)
+	}
+}
+
+// toFeastValue converts a value of a feature to a Feast value.
+func toFeastValue(v any) *feastTypes.Value {
+	switch val := v.(type) {
+	case string:
+		return &feastTypes.Value{Val: &feastTypes.Value_StringVal{StringVal: val}}
+	case int:
+		return &feastTypes.Value{Val: &feastTypes.Value_Int64Val{Int64Val: int64(val)}}
+	case float64:
+		return &feastTypes.Value{Val: &feastTypes.Value_DoubleVal{DoubleVal: val}}
+	case bool:
+		return &feastTypes.Value{Val: &feastTypes.Value_BoolVal{BoolVal: val}}
+	case time.Time:
+		return &feastTypes.Value{Val: &feastTypes.Value_UnixTimestampVal{UnixTimestampVal: val.Unix()}}
+	case []string:
+		return &feastTypes.Value{Val: &feastTypes.Value_StringListVal{StringListVal: &feastTypes.StringList{Val: val}}}
+	case []int:
+		l := &feastTypes.Int64List{Val: make([]int64, len(val))}
+		for i, x := range val {
+			l.Val[i] = int64(x)
+		}
+		return &feastTypes.Value{Val: &feastTypes.Value_Int64ListVal{Int64ListVal: l}}
+	case []float64:
+		return &feastTypes.Value{Val: &feastTypes.Value_DoubleListVal{DoubleListVal: &feastTypes.DoubleList{Val: val}}}
+	case []bool:
+		return &feastTypes.Value{Val: &feastTypes.Value_BoolListVal{BoolListVal: &feastTypes.BoolList{Val: val}}}
+	case []time.Time:
+		l := &feastTypes.Int64List{Val: make([]int64, len(val))}
+		for i, x := range val {
+			l.Val[i] = x.Unix()
+		}
+		return &feastTypes.Value{Val: &feastTypes.Value_UnixTimestampListVal{UnixTimestampListVal: l}}
+	default:
+		return &feastTypes.Value{Val: &feastTypes.Value_StringVal{StringVal: fmt.Sprintf("%v", val)}}
+	}
+}
+
+// fromFeastValue converts a Feast value to a value of the request context.
+func fromFeastValue(v *feastTypes.Value) any {
+	switch val := v.GetVal().(type) {
+	case *feastTypes.Value_StringVal:
+		return val.StringVal
+	case *feastTypes.Value_BytesVal:
+		return string(val.BytesVal)
+	case *feastTypes.Value_Int32Val:
+		return int(val.Int32Val)
+	case *feastTypes.Value_Int64Val:
+		return int(val.Int64Val)
+	case *feastTypes.Value_DoubleVal:
+		return val.DoubleVal
+	case *feastTypes.Value_FloatVal:
+		return float64(val.FloatVal)
+	case *feastTypes.Value_BoolVal:
+		return val.BoolVal
+	case *feastTypes.Value_UnixTimestampVal:
+		return time.Unix(val.UnixTimestampVal, 0).UTC()
+	case *feastTypes.Value_StringListVal:
+		return val.StringListVal.GetVal()
+	case *feastTypes.Value_Int64ListVal:
+		ret := make([]int, len(val.Int64ListVal.GetVal()))
+		for i, x := range val.Int64ListVal.GetVal() {
+			ret[i] = int(x)
+		}
+		return ret
+	case *feastTypes.Value_Int32ListVal:
+		ret := make([]int, len(val.Int32ListVal.GetVal()))
+		for i, x := range val.Int32ListVal.GetVal() {
+			ret[i] = int(x)
+		}
+		return ret
+	case *feastTypes.Value_DoubleListVal:
+		return val.DoubleListVal.GetVal()
+	case *feastTypes.Value_FloatListVal:
+		ret := make([]float64, len(val.FloatListVal.GetVal()))
+		for i, x := range val.FloatListVal.GetVal() {
+			ret[i] = float64(x)
+		}
+		return ret
+	case *feastTypes.Value_BoolListVal:
+		return val.BoolListVal.GetVal()
+	default:
+		return nil
+	}
+}
+
+func (a *accessor) Feast(addr string, r client.Reader, namespace string) NoLeaderRunnableFunc {
+	return func(ctx context.Context) error {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			a.logger.Error(err, "failed to listen")
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+
+		server := a.newServer()
+		feastApi.RegisterServingServiceServer(server, &feastServer{engine: a.serving, reader: r, namespace: namespace})
+
+		a.logger.WithValues("kind", "feast", "addr", l.Addr()).Info("Starting Accessor Feast-compatible server")
+		go func() {
+			<-ctx.Done()
+			server.Stop()
+		}()
+		return server.Serve(l)
+	}
+}
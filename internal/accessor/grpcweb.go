@@ -0,0 +1,204 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	coreApi "github.com/raptor-ml/raptor/api/proto/gen/go/core/v1alpha1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+
+	// grpcWebTrailerFlag marks the frame of the trailers.
+	grpcWebTrailerFlag = 0x80
+	// maxGRPCWebMessage is the maximal size of a request message, as the default of the gRPC server.
+	maxGRPCWebMessage = 4 << 20
+)
+
+// grpcWebHandler returns a handler that serves the unary methods of the EngineService over gRPC-web, with the same
+// interceptors as the gRPC server (authentication, validation and the protocol negotiation).
+//
+// Usage: POST <prefix>core.v1alpha1.EngineService/<method>
+func (a *accessor) grpcWebHandler(prefix string) http.Handler {
+	methods := map[string]grpc.MethodDesc{}
+	for _, m := range coreApi.EngineService_ServiceDesc.Methods {
+		methods[m.MethodName] = m
+	}
+	svc := coreApi.EngineService_ServiceDesc.ServiceName
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType := r.Header.Get("Content-Type")
+		text := strings.HasPrefix(contentType, grpcWebTextContentType)
+		if r.Method != http.MethodPost || !strings.HasPrefix(contentType, grpcWebContentType) {
+			http.Error(w, "expected a gRPC-web POST request", http.StatusUnsupportedMediaType)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, fmt.Sprintf("%s%s/", prefix, svc))
+		m, ok := methods[name]
+		if !ok {
+			writeGRPCWeb(w, text, nil, nil, status.Errorf(codes.Unimplemented, "unknown method %s", name))
+			return
+		}
+
+		var body io.Reader = http.MaxBytesReader(w, r.Body, maxGRPCWebMessage+5)
+		if text {
+			body = base64.NewDecoder(base64.StdEncoding, body)
+		}
+		msg, err := readGRPCWebFrame(body)
+		if err != nil {
+			writeGRPCWeb(w, text, nil, nil, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+
+		md := metadata.MD{}
+		for k, vals := range r.Header {
+			k = strings.ToLower(k)
+			if k == "content-type" || k == "content-length" || k == "connection" || k == "host" {
+				continue
+			}
+			md.Append(k, vals...)
+		}
+		stream := &grpcWebStream{method: fmt.Sprintf("/%s/%s", svc, name), header: metadata.MD{}, trailer: metadata.MD{}}
+		ctx := metadata.NewIncomingContext(r.Context(), md)
+		ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+
+		dec := func(v any) error {
+			return proto.Unmarshal(msg, v.(proto.Message))
+		}
+		resp, err := m.Handler(a.sdkServer, ctx, dec, a.unaryInterceptor)
+		writeGRPCWeb(w, text, stream, resp, err)
+	})
+}
+
+// readGRPCWebFrame reads the message of the (single) data frame of a unary request.
+func readGRPCWebFrame(r io.Reader) ([]byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, fmt.Errorf("failed to read the message frame: %w", err)
+	}
+	if hdr[0] != 0 {
+		return nil, fmt.Errorf("compressed messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(hdr[1:])
+	if size > maxGRPCWebMessage {
+		return nil, fmt.Errorf("the message is larger than %d bytes", maxGRPCWebMessage)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, fmt.Errorf("failed to read the message: %w", err)
+	}
+	return msg, nil
+}
+
+// writeGRPCWeb writes the response of a unary call: the headers that were set by the handler, the message frame and
+// the trailers frame with the status of the call.
+func writeGRPCWeb(w http.ResponseWriter, text bool, stream *grpcWebStream, resp any, err error) {
+	var out bytes.Buffer
+	trailer := metadata.MD{}
+	if stream != nil {
+		stream.mu.Lock()
+		for k, vals := range stream.header {
+			for _, v := range vals {
+				w.Header().Add(k, v)
+			}
+		}
+		trailer = stream.trailer.Copy()
+		stream.mu.Unlock()
+	}
+
+	if err == nil {
+		b, merr := proto.Marshal(resp.(proto.Message))
+		if merr != nil {
+			err = status.Errorf(codes.Internal, "failed to marshal the response: %v", merr)
+		} else {
+			writeGRPCWebFrame(&out, 0, b)
+		}
+	}
+	st := status.Convert(err)
+	trailer.Set("grpc-status", fmt.Sprintf("%d", st.Code()))
+	trailer.Set("grpc-message", url.PathEscape(st.Message()))
+	var tb bytes.Buffer
+	for k, vals := range trailer {
+		for _, v := range vals {
+			_, _ = fmt.Fprintf(&tb, "%s: %s\r\n", k, v)
+		}
+	}
+	writeGRPCWebFrame(&out, grpcWebTrailerFlag, tb.Bytes())
+
+	contentType := grpcWebContentType + "+proto"
+	payload := out.Bytes()
+	if text {
+		contentType = grpcWebTextContentType + "+proto"
+		payload = []byte(base64.StdEncoding.EncodeToString(payload))
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(payload)
+}
+
+func writeGRPCWebFrame(buf *bytes.Buffer, flag byte, b []byte) {
+	var hdr [5]byte
+	hdr[0] = flag
+	binary.BigEndian.PutUint32(hdr[1:], uint32(len(b)))
+	buf.Write(hdr[:])
+	buf.Write(b)
+}
+
+// grpcWebStream collects the headers and trailers that are set by the handlers (i.e. by grpc.SetHeader).
+type grpcWebStream struct {
+	method  string
+	mu      sync.Mutex
+	header  metadata.MD
+	trailer metadata.MD
+}
+
+func (s *grpcWebStream) Method() string {
+	return s.method
+}
+
+func (s *grpcWebStream) SetHeader(md metadata.MD) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *grpcWebStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *grpcWebStream) SetTrailer(md metadata.MD) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessor
+
+import (
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/utilities"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"net/url"
+	"strings"
+)
+
+// keysQueryParser parses the query parameters of the gRPC gateway as the default parser, and lets the key parts of the
+// requests' keys be set by query parameters of their own. The query parameters that aren't fields of a request with
+// keys are its key parts, so both of these are the same:
+//
+//	GET <prefix><selector>?keys[user_id]=1&keys[merchant_id]=2
+//	GET <prefix><selector>?user_id=1&merchant_id=2
+//
+// The `keys[<key>]` form takes precedence when both are set.
+type keysQueryParser struct {
+	runtime.DefaultQueryParser
+}
+
+func (p *keysQueryParser) Parse(msg proto.Message, values url.Values, filter *utilities.DoubleArray) error {
+	m := msg.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	keys := fields.ByName("keys")
+	if keys == nil || !keys.IsMap() || keys.MapKey().Kind() != protoreflect.StringKind ||
+		keys.MapValue().Kind() != protoreflect.StringKind {
+		return p.DefaultQueryParser.Parse(msg, values, filter)
+	}
+
+	rest := url.Values{}
+	var km protoreflect.Map
+	for name, vals := range values {
+		field := name
+		if i := strings.IndexAny(field, ".["); i != -1 {
+			field = field[:i]
+		}
+		if fields.ByName(protoreflect.Name(field)) != nil || fields.ByJSONName(field) != nil || len(vals) == 0 {
+			rest[name] = vals
+			continue
+		}
+		if km == nil {
+			km = m.Mutable(keys).Map()
+		}
+		km.Set(protoreflect.ValueOfString(name).MapKey(), protoreflect.ValueOfString(vals[0]))
+	}
+	return p.DefaultQueryParser.Parse(msg, rest, filter)
+}
@@ -0,0 +1,553 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessor
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	protoApi "github.com/raptor-ml/raptor/api/proto/gen/go"
+	coreApi "github.com/raptor-ml/raptor/api/proto/gen/go/core/v1alpha1"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"html/template"
+	"net/http"
+	"regexp"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+// valueSchema is the name of the schema of the feature values, which is one of the typed schemas of the primitives.
+const valueSchema = "core.v1alpha1.Value"
+
+// valuePrimitives are the primitives that have a typed value schema (see primitiveValueSchema).
+var valuePrimitives = []api.PrimitiveType{
+	api.PrimitiveTypeString,
+	api.PrimitiveTypeInteger,
+	api.PrimitiveTypeFloat,
+	api.PrimitiveTypeBoolean,
+	api.PrimitiveTypeTimestamp,
+	api.PrimitiveTypeStringList,
+	api.PrimitiveTypeIntegerList,
+	api.PrimitiveTypeFloatList,
+	api.PrimitiveTypeBooleanList,
+	api.PrimitiveTypeTimestampList,
+}
+
+var pathParamRegExp = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?}`)
+
+// schema is an OpenAPI schema object.
+type schema map[string]any
+
+type oaDocument struct {
+	OpenAPI    string                            `json:"openapi"`
+	Info       oaInfo                            `json:"info"`
+	Servers    []oaServer                        `json:"servers"`
+	Paths      map[string]map[string]oaOperation `json:"paths"`
+	Components oaComponents                      `json:"components"`
+	Security   []map[string][]string             `json:"security,omitempty"`
+}
+
+type oaInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type oaServer struct {
+	URL string `json:"url"`
+}
+
+type oaComponents struct {
+	Schemas         map[string]schema `json:"schemas"`
+	SecuritySchemes map[string]schema `json:"securitySchemes,omitempty"`
+}
+
+type oaOperation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []oaParameter         `json:"parameters,omitempty"`
+	RequestBody *oaBody               `json:"requestBody,omitempty"`
+	Responses   map[string]oaResponse `json:"responses"`
+}
+
+type oaParameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Style       string `json:"style,omitempty"`
+	Explode     *bool  `json:"explode,omitempty"`
+	Schema      schema `json:"schema"`
+}
+
+type oaBody struct {
+	Description string                 `json:"description,omitempty"`
+	Required    bool                   `json:"required,omitempty"`
+	Content     map[string]oaMediaType `json:"content"`
+}
+
+type oaResponse struct {
+	Description string                 `json:"description"`
+	Content     map[string]oaMediaType `json:"content,omitempty"`
+}
+
+type oaMediaType struct {
+	Schema schema `json:"schema"`
+}
+
+// route is an HTTP route of the accessor (other than the gRPC gateway's), that is documented by the OpenAPI document.
+type route struct {
+	path        string
+	method      string
+	operationID string
+	summary     string
+	params      []oaParameter
+	body        *oaBody
+	contentType string
+	// tag groups the route in the document. Defaults to `Admin`.
+	tag string
+}
+
+// queryParam is a shorthand for an optional query parameter of a string.
+func queryParam(name, description string) oaParameter {
+	return oaParameter{Name: name, In: "query", Description: description, Schema: schema{"type": "string"}}
+}
+
+// openAPIDocument generates the OpenAPI 3 document of the HTTP accessor: the routes of the gRPC gateway (by the HTTP
+// rules of the EngineService), and the additional routes that are served under the prefix.
+func (a *accessor) openAPIDocument(prefix string, routes []route) ([]byte, error) {
+	g := &oaGenerator{schemas: map[string]schema{}}
+	for _, pt := range valuePrimitives {
+		g.schemas[primitiveValueSchema(pt)] = typedValueSchema(pt)
+	}
+	refs := make([]any, 0, len(valuePrimitives))
+	for _, pt := range valuePrimitives {
+		refs = append(refs, schemaRef(primitiveValueSchema(pt)))
+	}
+	g.schemas[valueSchema] = schema{
+		"description": "The value of a feature, by the primitive of the feature.",
+		"oneOf":       refs,
+	}
+	g.schemas["google.rpc.Status"] = schema{
+		"type": "object",
+		"properties": map[string]any{
+			"code":    schema{"type": "integer", "format": "int32"},
+			"message": schema{"type": "string"},
+			"details": schema{"type": "array", "items": schema{"type": "object", "additionalProperties": true}},
+		},
+	}
+
+	// the summaries of the methods are taken from the (generated) OpenAPI v2 document, since the descriptors of the
+	// generated code don't include the comments
+	summaries := map[string]string{}
+	var v2 struct {
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Summary     string `json:"summary"`
+		} `json:"paths"`
+	}
+	if err := yaml.Unmarshal(protoApi.ApiDocs, &v2); err == nil {
+		for _, ops := range v2.Paths {
+			for _, op := range ops {
+				summaries[op.OperationID] = op.Summary
+			}
+		}
+	}
+
+	doc := oaDocument{
+		OpenAPI: "3.0.3",
+		Info: oaInfo{
+			Title:       "Raptor Core API",
+			Description: "Provides access low-level operations over feature values and model predictions.",
+			Version:     "v1alpha1",
+		},
+		Servers: []oaServer{{URL: strings.TrimSuffix(prefix, "/")}},
+		Paths:   map[string]map[string]oaOperation{},
+	}
+
+	svc := coreApi.File_core_v1alpha1_api_proto.Services().ByName("EngineService")
+	methods := svc.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		m := methods.Get(i)
+		rule, ok := proto.GetExtension(m.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if !ok || rule == nil {
+			continue
+		}
+		method, path := httpRulePattern(rule)
+		if method == "" {
+			continue
+		}
+		opID := fmt.Sprintf("%s_%s", svc.Name(), m.Name())
+		op := oaOperation{
+			OperationID: opID,
+			Summary:     summaries[opID],
+			Tags:        []string{string(svc.Name())},
+			Parameters:  g.parameters(m.Input(), path, rule.GetBody()),
+			Responses: map[string]oaResponse{
+				"200": {Description: "A successful response.", Content: jsonContent(g.messageRef(m.Output()))},
+				"default": {
+					Description: "An unexpected error response.",
+					Content:     jsonContent(schemaRef("google.rpc.Status")),
+				},
+			},
+		}
+		if m.Name() == "Get" {
+			op.Parameters = append(op.Parameters, oaParameter{
+				Name:        "Grpc-Metadata-X-Raptor-Request-Context",
+				In:          "header",
+				Description: "The JSON object of the request context, that is passed to the features.",
+				Schema:      schema{"type": "string"},
+			})
+		}
+		if body := rule.GetBody(); body != "" {
+			s := g.messageRef(m.Input())
+			if body != "*" {
+				s = g.fieldSchema(m.Input().Fields().ByJSONName(body))
+			}
+			op.RequestBody = &oaBody{Required: true, Content: jsonContent(s)}
+		}
+		addOperation(doc.Paths, pathParamRegExp.ReplaceAllString(path, "{$1}"), method, op)
+	}
+
+	for _, r := range routes {
+		contentType := r.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		tag := r.tag
+		if tag == "" {
+			tag = "Admin"
+		}
+		addOperation(doc.Paths, "/"+r.path, r.method, oaOperation{
+			OperationID: r.operationID,
+			Summary:     r.summary,
+			Tags:        []string{tag},
+			Parameters:  r.params,
+			RequestBody: r.body,
+			Responses: map[string]oaResponse{
+				"200": {
+					Description: "A successful response.",
+					Content:     map[string]oaMediaType{contentType: {Schema: schema{}}},
+				},
+				"default": {Description: "An unexpected error response."},
+			},
+		})
+	}
+
+	if a.guard != nil {
+		doc.Components.SecuritySchemes = map[string]schema{
+			"bearer": {"type": "http", "scheme": "bearer"},
+			"apiKey": {"type": "apiKey", "in": "header", "name": "X-Api-Key"},
+		}
+		doc.Security = []map[string][]string{{"bearer": {}}, {"apiKey": {}}}
+	}
+	doc.Components.Schemas = g.schemas
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func addOperation(paths map[string]map[string]oaOperation, path, method string, op oaOperation) {
+	if paths[path] == nil {
+		paths[path] = map[string]oaOperation{}
+	}
+	paths[path][strings.ToLower(method)] = op
+}
+
+// httpRulePattern returns the method and the path template of the HTTP rule.
+func httpRulePattern(rule *annotations.HttpRule) (string, string) {
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		return strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+	default:
+		return "", ""
+	}
+}
+
+func jsonContent(s schema) map[string]oaMediaType {
+	return map[string]oaMediaType{"application/json": {Schema: s}}
+}
+
+func schemaRef(name string) schema {
+	return schema{"$ref": "#/components/schemas/" + name}
+}
+
+// primitiveValueSchema returns the name of the typed value schema of the primitive, i.e. `IntegerListValue`.
+func primitiveValueSchema(pt api.PrimitiveType) string {
+	var name string
+	switch pt.Singular() {
+	case api.PrimitiveTypeString:
+		name = "String"
+	case api.PrimitiveTypeInteger:
+		name = "Integer"
+	case api.PrimitiveTypeFloat:
+		name = "Float"
+	case api.PrimitiveTypeBoolean:
+		name = "Boolean"
+	case api.PrimitiveTypeTimestamp:
+		name = "Timestamp"
+	}
+	if !pt.Scalar() {
+		name += "List"
+	}
+	return fmt.Sprintf("core.v1alpha1.%sValue", name)
+}
+
+// typedValueSchema returns the schema of the (JSON encoded) values of the primitive.
+func typedValueSchema(pt api.PrimitiveType) schema {
+	var field string
+	var s schema
+	switch pt.Singular() {
+	case api.PrimitiveTypeString:
+		field, s = "stringValue", schema{"type": "string"}
+	case api.PrimitiveTypeInteger:
+		field, s = "intValue", schema{"type": "integer", "format": "int32"}
+	case api.PrimitiveTypeFloat:
+		field, s = "floatValue", schema{"type": "number", "format": "double"}
+	case api.PrimitiveTypeBoolean:
+		field, s = "boolValue", schema{"type": "boolean"}
+	case api.PrimitiveTypeTimestamp:
+		field, s = "timestampValue", schema{"type": "string", "format": "date-time"}
+	}
+	scalar := schema{
+		"type":                 "object",
+		"required":             []string{field},
+		"properties":           map[string]any{field: s},
+		"additionalProperties": false,
+	}
+	description := fmt.Sprintf("A value of the `%s` primitive.", pt)
+	if !pt.Scalar() {
+		description = fmt.Sprintf("A value of the `[]%s` primitive.", pt.Singular())
+	}
+	if pt.Scalar() {
+		return schema{
+			"type":        "object",
+			"description": description,
+			"required":    []string{"scalarValue"},
+			"properties":  map[string]any{"scalarValue": scalar},
+		}
+	}
+	return schema{
+		"type":        "object",
+		"description": description,
+		"required":    []string{"listValue"},
+		"properties": map[string]any{
+			"listValue": schema{
+				"type":       "object",
+				"properties": map[string]any{"values": schema{"type": "array", "items": scalar}},
+			},
+		},
+	}
+}
+
+// oaGenerator generates the schemas of the protobuf messages, by their JSON mapping (as the gRPC gateway encodes them).
+type oaGenerator struct {
+	schemas map[string]schema
+}
+
+// messageRef returns a reference to the schema of the message, and generates it if needed.
+func (g *oaGenerator) messageRef(md protoreflect.MessageDescriptor) schema {
+	if s, ok := wellKnownSchema(md); ok {
+		return s
+	}
+	name := string(md.FullName())
+	if _, ok := g.schemas[name]; ok {
+		return schemaRef(name)
+	}
+	// the placeholder stops the recursion of recursive messages
+	g.schemas[name] = schema{}
+
+	props := map[string]any{}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		props[fields.Get(i).JSONName()] = g.fieldSchema(fields.Get(i))
+	}
+	g.schemas[name] = schema{"type": "object", "properties": props}
+	return schemaRef(name)
+}
+
+func (g *oaGenerator) fieldSchema(fd protoreflect.FieldDescriptor) schema {
+	if fd.IsMap() {
+		return schema{"type": "object", "additionalProperties": g.singularSchema(fd.MapValue())}
+	}
+	s := g.singularSchema(fd)
+	if fd.IsList() {
+		return schema{"type": "array", "items": s}
+	}
+	return s
+}
+
+func (g *oaGenerator) singularSchema(fd protoreflect.FieldDescriptor) schema {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return schema{"type": "boolean"}
+	case protoreflect.StringKind:
+		return schema{"type": "string"}
+	case protoreflect.BytesKind:
+		return schema{"type": "string", "format": "byte"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return schema{"type": "integer", "format": "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return schema{"type": "integer", "format": "int64", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// the 64-bit integers are encoded as strings by the JSON mapping
+		return schema{"type": "string", "format": "int64"}
+	case protoreflect.FloatKind:
+		return schema{"type": "number", "format": "float"}
+	case protoreflect.DoubleKind:
+		return schema{"type": "number", "format": "double"}
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		return schema{"type": "string", "enum": names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return g.messageRef(fd.Message())
+	default:
+		return schema{}
+	}
+}
+
+// wellKnownSchema returns the schemas of the well-known types, and the feature values, that aren't generated by
+// their fields.
+func wellKnownSchema(md protoreflect.MessageDescriptor) (schema, bool) {
+	switch md.FullName() {
+	case valueSchema:
+		return schemaRef(valueSchema), true
+	case "google.protobuf.Timestamp":
+		return schema{"type": "string", "format": "date-time"}, true
+	case "google.protobuf.Duration":
+		return schema{"type": "string", "description": "A duration in seconds, i.e. `1.5s`."}, true
+	default:
+		return nil, false
+	}
+}
+
+// parameters returns the path and query parameters of the request message, as they're parsed by the gRPC gateway:
+// the variables of the path template, and the rest of the fields (if they're not the body).
+func (g *oaGenerator) parameters(md protoreflect.MessageDescriptor, path, body string) []oaParameter {
+	var ret []oaParameter
+	inPath := map[string]bool{}
+	for _, m := range pathParamRegExp.FindAllStringSubmatch(path, -1) {
+		inPath[m[1]] = true
+		s := schema{"type": "string"}
+		if fd := md.Fields().ByName(protoreflect.Name(m[1])); fd != nil {
+			s = g.singularSchema(fd)
+		}
+		ret = append(ret, oaParameter{Name: m[1], In: "path", Required: true, Schema: s})
+	}
+	if body == "*" {
+		return ret
+	}
+	return append(ret, g.queryParameters(md, "", inPath, body, map[protoreflect.FullName]bool{})...)
+}
+
+func (g *oaGenerator) queryParameters(md protoreflect.MessageDescriptor, prefix string, inPath map[string]bool, body string, seen map[protoreflect.FullName]bool) []oaParameter {
+	if seen[md.FullName()] {
+		return nil
+	}
+	seen[md.FullName()] = true
+	defer delete(seen, md.FullName())
+
+	var ret []oaParameter
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if prefix == "" && (inPath[string(fd.Name())] || string(fd.Name()) == body) {
+			continue
+		}
+		name := prefix + fd.JSONName()
+		switch {
+		case fd.IsMap():
+			explode := true
+			desc := fmt.Sprintf("A map, in the `%s[key]=value` format.", name)
+			if prefix == "" && fd.Name() == "keys" {
+				// see keysQueryParser
+				desc += " Each key can also be set by a query parameter of its own, i.e. `user_id=1&merchant_id=2`."
+			}
+			ret = append(ret, oaParameter{
+				Name:        name,
+				In:          "query",
+				Description: desc,
+				Style:       "deepObject",
+				Explode:     &explode,
+				Schema:      g.fieldSchema(fd),
+			})
+		case fd.Kind() == protoreflect.MessageKind:
+			if fd.IsList() {
+				// repeated messages can't be set by the query parameters
+				continue
+			}
+			if s, ok := wellKnownSchema(fd.Message()); ok && fd.Message().FullName() != valueSchema {
+				ret = append(ret, oaParameter{Name: name, In: "query", Schema: s})
+				continue
+			}
+			ret = append(ret, g.queryParameters(fd.Message(), name+".", inPath, body, seen)...)
+		default:
+			ret = append(ret, oaParameter{Name: name, In: "query", Schema: g.fieldSchema(fd)})
+		}
+	}
+	return ret
+}
+
+//go:embed swagger-ui.html
+var swaggerUIPage string
+
+var swaggerUITemplate = template.Must(template.New("swagger-ui").Parse(swaggerUIPage))
+
+// openAPIHandler returns a handler that serves the OpenAPI document.
+//
+// Usage: GET <prefix>openapi.json
+func openAPIHandler(doc []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(doc)
+	}
+}
+
+// swaggerUIHandler returns a handler that serves the Swagger UI of the OpenAPI document.
+//
+// Usage: GET <prefix>docs/
+func swaggerUIHandler(specURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = swaggerUITemplate.Execute(w, struct{ SpecURL string }{specURL})
+	}
+}
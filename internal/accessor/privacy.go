@@ -0,0 +1,67 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessor
+
+import (
+	"context"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/internal/access"
+)
+
+// privacyEngine decrypts the values of encrypted features, which are served sealed by the engine, and masks the
+// values of sensitive features. When the serving API is authenticated, the values are decrypted only for the callers
+// that are granted with the `decrypt` verb on the feature, and are unmasked only for the callers that are granted
+// with `unmask`.
+type privacyEngine struct {
+	api.Engine
+	decrypter api.ValueDecrypter
+	guard     *access.Guard
+}
+
+func (e *privacyEngine) Get(ctx context.Context, selector string, keys api.Keys) (api.Value, api.FeatureDescriptor, error) {
+	val, fd, err := e.Engine.Get(ctx, selector, keys)
+	if err != nil {
+		return val, fd, err
+	}
+	if fd.Encryption != nil {
+		if e.guard != nil {
+			if err := e.guard.AuthorizeContext(ctx, manifests.AccessVerbDecrypt, selector); err != nil {
+				return api.Value{}, fd, err
+			}
+		}
+		if e.decrypter != nil {
+			val, err = e.decrypter.DecryptValue(ctx, fd, keys, val)
+			if err != nil {
+				return val, fd, err
+			}
+		}
+	}
+	if e.guard != nil {
+		val, err = e.guard.MaskValue(ctx, selector, fd, val)
+	}
+	return val, fd, err
+}
+
+// WriteTransaction implements api.Transactor, when the wrapped engine does.
+func (e *privacyEngine) WriteTransaction(ctx context.Context, writes []api.FeatureWrite) error {
+	tx, ok := e.Engine.(api.Transactor)
+	if !ok {
+		return api.ErrTransactionsUnsupported
+	}
+	return tx.WriteTransaction(ctx, writes)
+}
@@ -0,0 +1,201 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/historian"
+	"github.com/raptor-ml/raptor/internal/stats"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// AllNamespaces audits the requests of the features of every namespace.
+const AllNamespaces = "*"
+
+const (
+	defaultBatchSize     = 500
+	defaultFlushInterval = 5 * time.Second
+	bufferSize           = 10_000
+)
+
+// Config configures the audit log.
+type Config struct {
+	Sink api.AuditSink
+	// Namespaces are the namespaces of the features that are audited (or AllNamespaces).
+	Namespaces []string
+	// HashKey is the key of the HMAC of the entities' keys. A plain SHA-256 is used when empty, which is weaker for
+	// guessable identifiers.
+	HashKey []byte
+	// BatchSize is the maximal number of records that are written to the sink together.
+	BatchSize int
+	// FlushInterval is the maximal time a record waits before it's written to the sink.
+	FlushInterval time.Duration
+	Logger        logr.Logger
+}
+
+// Logger records the feature reads and writes of the audited namespaces, and ships them to the sink in batches.
+// The records are buffered, so auditing doesn't slow down the requests. When the sink can't keep up with the
+// requests, the records are dropped (see the `core_number_of_dropped_audit_records` metric).
+type Logger struct {
+	cfg        Config
+	all        bool
+	namespaces map[string]struct{}
+	records    chan api.AuditRecord
+}
+
+// New creates a new audit Logger. It returns nil when there's no sink or no audited namespaces, which disables
+// the auditing.
+func New(cfg Config) *Logger {
+	if cfg.Sink == nil || len(cfg.Namespaces) == 0 {
+		return nil
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+
+	l := &Logger{
+		cfg:        cfg,
+		namespaces: make(map[string]struct{}),
+		records:    make(chan api.AuditRecord, bufferSize),
+	}
+	for _, ns := range cfg.Namespaces {
+		if ns == AllNamespaces {
+			l.all = true
+		}
+		l.namespaces[ns] = struct{}{}
+	}
+	return l
+}
+
+// Audited checks if the requests of the feature are audited.
+func (l *Logger) Audited(fqn string) bool {
+	if l == nil {
+		return false
+	}
+	if l.all {
+		return true
+	}
+	ns, _, _, _, _, err := api.ParseSelector(fqn)
+	if err != nil {
+		return false
+	}
+	_, ok := l.namespaces[ns]
+	return ok
+}
+
+// Record records a request of the feature, if its namespace is audited.
+func (l *Logger) Record(ctx context.Context, method api.StateMethod, fd api.FeatureDescriptor, keys api.Keys, err error) {
+	if !l.Audited(fd.FQN) {
+		return
+	}
+
+	rec := api.AuditRecord{
+		Method:         method.String(),
+		FQN:            fd.FQN,
+		EntityHash:     l.hash(keys),
+		PII:            fd.PII,
+		Classification: fd.Classification,
+		Caller:         api.CallerFromContext(ctx),
+		Timestamp:      time.Now(),
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	select {
+	case l.records <- rec:
+	default:
+		stats.IncrDroppedAuditRecords(1)
+	}
+}
+
+func (l *Logger) hash(keys api.Keys) string {
+	var h hash.Hash
+	if len(l.cfg.HashKey) > 0 {
+		h = hmac.New(sha256.New, l.cfg.HashKey)
+	} else {
+		h = sha256.New()
+	}
+	// Keys.String() is sorted by the key names, so the hash is stable
+	h.Write([]byte(keys.String()))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// WithManager adds the shipping of the records to the manager. It runs on every instance, since each instance
+// records its own requests.
+func (l *Logger) WithManager(mgr manager.Manager) error {
+	if l == nil {
+		return nil
+	}
+	return mgr.Add(historian.NoLeaderRunnableFunc(l.run))
+}
+
+func (l *Logger) run(ctx context.Context) error {
+	ticker := time.NewTicker(l.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]api.AuditRecord, 0, l.cfg.BatchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := l.cfg.Sink.WriteAudit(ctx, batch); err != nil {
+			l.cfg.Logger.Error(err, "failed to write audit records", "records", len(batch))
+			stats.IncrDroppedAuditRecords(len(batch))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// drain the buffered records before shutting down
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			for {
+				select {
+				case rec := <-l.records:
+					batch = append(batch, rec)
+					if len(batch) >= l.cfg.BatchSize {
+						flush(ctx)
+					}
+				default:
+					flush(ctx)
+					return nil
+				}
+			}
+		case rec := <-l.records:
+			batch = append(batch, rec)
+			if len(batch) >= l.cfg.BatchSize {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+//go:build e2e
+// +build e2e
+
+/*
+ * Copyright (c) 2022 RaptorML authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/plugins/providers/historical/parquet"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/envfuncs"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+	"testing"
+	"time"
+)
+
+func TestConnector(t *testing.T) {
+	namespace := "connector"
+	topic := "e2e/events"
+	selector := fmt.Sprintf("%s.last_amount", namespace)
+	start := time.Now()
+
+	// The last event of each user is the value that should be served.
+	events := []map[string]any{
+		{"user_id": "alice", "amount": 10.5, "timestamp": start.Add(-2 * time.Second).Format(time.RFC3339)},
+		{"user_id": "bob", "amount": 3.0, "timestamp": start.Add(-2 * time.Second).Format(time.RFC3339)},
+		{"user_id": "alice", "amount": 42.25, "timestamp": start.Add(-time.Second).Format(time.RFC3339)},
+	}
+	want := map[string]float64{"alice": 42.25, "bob": 3.0}
+
+	feature := features.New("Push events through an MQTT connector").
+		Setup(FeatureEnvFn(SetupMQTT("mqtt"))).
+		Setup(FeatureEnvFn(envfuncs.CreateNamespace(namespace))).
+		Teardown(FeatureEnvFn(envfuncs.DeleteNamespace(namespace))).
+		Setup(ApplyFixtures(namespace, "testdata/connector", func(ctx context.Context) map[string]string {
+			return map[string]string{
+				"MQTT_BROKER": MQTTBroker(ctx, "mqtt"),
+				"MQTT_TOPIC":  topic,
+			}
+		})).
+		Assess("Serve the values of the pushed events", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+			// The events are published until they are served, since they are dropped until the runner subscribes.
+			err := Eventually(func() (bool, error) {
+				if err := PublishEvents(topic, events...); err != nil {
+					return false, err
+				}
+				for user, amount := range want {
+					ok, err := ValueEquals(ctx, selector, api.Keys{"user_id": user}, amount)
+					if err != nil || !ok {
+						return false, err
+					}
+				}
+				return true, nil
+			})
+			if err != nil {
+				t.Errorf("the pushed events weren't served: %s", err)
+				t.FailNow()
+			}
+			return ctx
+		}).
+		Assess("Write the historical rows", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+			fqn, err := api.NormalizeFQN(selector, namespace)
+			if err != nil {
+				t.Errorf("failed to normalize the selector: %s", err)
+				t.FailNow()
+			}
+
+			var rows []parquet.HistoricalRecord
+			err = Eventually(func() (bool, error) {
+				rows, err = HistoricalRows(ctx, "system", parquet.Predicate{FQN: fqn, From: start.Add(-time.Minute)})
+				if err != nil {
+					return false, nil
+				}
+				return len(rows) >= len(events), nil
+			})
+			if err != nil {
+				t.Errorf("expected at least %d historical rows, got %d: %s", len(events), len(rows), err)
+				t.FailNow()
+			}
+
+			for _, row := range rows {
+				if row.Value == nil || row.Value.Double == nil {
+					t.Errorf("unexpected historical row without a value: %+v", row)
+					continue
+				}
+				found := false
+				for _, ev := range events {
+					if ev["amount"] == *row.Value.Double {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("unexpected historical value %v of %s", *row.Value.Double, row.Keys)
+				}
+			}
+			return ctx
+		}).Feature()
+
+	testEnv.Test(t, feature)
+}
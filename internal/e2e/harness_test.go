@@ -0,0 +1,339 @@
+//go:build e2e
+// +build e2e
+
+/*
+ * Copyright (c) 2022 RaptorML authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/internal/plugins/providers/historical/parquet"
+	s3parquet "github.com/raptor-ml/raptor/internal/plugins/providers/historical/parquet/s3"
+	"github.com/spf13/viper"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/e2e-framework/klient/decoder"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/envfuncs"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+	"sigs.k8s.io/e2e-framework/third_party/helm"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type minioContextKey string
+type mqttContextKey string
+type historicalContextKey string
+
+const (
+	minioBucket   = "raptor"
+	minioUser     = "raptor"
+	minioPassword = "raptor-e2e"
+	minioRegion   = "us-east-1"
+	// minioHostEndpoint is the address of MinIO from the host, through the NodePort that is mapped in kind-cluster.yaml
+	minioHostEndpoint = "http://localhost:22090"
+	minioNodePort     = 32090
+
+	// mqttHostBroker is the address of the MQTT broker from the host, through the NodePort that is mapped in
+	// kind-cluster.yaml
+	mqttHostBroker = "tcp://localhost:21883"
+	mqttNodePort   = 31883
+
+	// assessInterval is the interval between the checks of eventually-consistent assertions.
+	assessInterval = 2 * time.Second
+	// assessTimeout is the time eventually-consistent assertions have to pass.
+	assessTimeout = 3 * time.Minute
+)
+
+// SetupMinio installs MinIO as the S3-compatible storage of the historical data.
+func SetupMinio(name string) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		if n := ctx.Value(minioContextKey(name)); n != nil {
+			return ctx, nil
+		}
+		ns := envconf.RandomName(name, 32)
+		ctx, err := envfuncs.CreateNamespace(ns)(ctx, cfg)
+		if err != nil {
+			return ctx, fmt.Errorf("failed to create minio namespace: %w", err)
+		}
+
+		manager := helm.New(cfg.KubeconfigFile())
+		err = manager.RunRepo(helm.WithArgs("add", "bitnami", "https://charts.bitnami.com/bitnami"))
+		if err != nil {
+			return ctx, fmt.Errorf("failed to add bitnami chart repo: %w", err)
+		}
+		err = manager.RunRepo(helm.WithArgs("update"))
+		if err != nil {
+			return ctx, fmt.Errorf("failed to update chart repo: %w", err)
+		}
+		err = manager.RunInstall(helm.WithName(name),
+			helm.WithReleaseName("bitnami/minio"),
+			helm.WithNamespace(ns),
+			helm.WithArgs(
+				"--set", "mode=standalone",
+				"--set", "persistence.enabled=false",
+				"--set", fmt.Sprintf("auth.rootUser=%s", minioUser),
+				"--set", fmt.Sprintf("auth.rootPassword=%s", minioPassword),
+				"--set", fmt.Sprintf("defaultBuckets=%s", minioBucket),
+				"--set", "service.type=NodePort",
+				"--set", fmt.Sprintf("service.nodePorts.api=%d", minioNodePort),
+			),
+		)
+		if err != nil {
+			return ctx, fmt.Errorf("failed to install minio: %w", err)
+		}
+
+		dep := &appsv1.Deployment{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+			},
+		}
+		err = wait.For(conditions.New(cfg.Client().Resources()).ResourceScaled(dep, func(object k8s.Object) int32 {
+			return object.(*appsv1.Deployment).Status.ReadyReplicas
+		}, 1), wait.WithTimeout(waitTimeout))
+		if err != nil {
+			return ctx, fmt.Errorf("failed to wait for minio to be ready: %w", err)
+		}
+
+		return context.WithValue(ctx, minioContextKey(name), dep.ObjectMeta), nil
+	}
+}
+
+// historicalConfig is the configuration of the historical reader of a Core, accessed from the host.
+func historicalConfig(basedir string) *viper.Viper {
+	v := viper.New()
+	v.Set("s3-endpoint", minioHostEndpoint)
+	v.Set("s3-bucket", minioBucket)
+	v.Set("s3-basedir", basedir)
+	v.Set("aws-access-key", minioUser)
+	v.Set("aws-secret-key", minioPassword)
+	v.Set("aws-region", minioRegion)
+	return v
+}
+
+// SetupMQTT installs a Mosquitto MQTT broker that is used as a test connector: the tests publish synthetic events to
+// it, and `mqtt` DataSources consume them.
+func SetupMQTT(name string) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		if n := ctx.Value(mqttContextKey(name)); n != nil {
+			return ctx, nil
+		}
+		ns := envconf.RandomName(name, 32)
+		ctx, err := envfuncs.CreateNamespace(ns)(ctx, cfg)
+		if err != nil {
+			return ctx, fmt.Errorf("failed to create mqtt namespace: %w", err)
+		}
+
+		meta := v1.ObjectMeta{Name: name, Namespace: ns, Labels: map[string]string{"app": name}}
+		cm := &corev1.ConfigMap{
+			ObjectMeta: meta,
+			Data: map[string]string{
+				"mosquitto.conf": "listener 1883\nallow_anonymous true\npersistence false\n",
+			},
+		}
+		replicas := int32(1)
+		dep := &appsv1.Deployment{
+			ObjectMeta: meta,
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &v1.LabelSelector{MatchLabels: meta.Labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: v1.ObjectMeta{Labels: meta.Labels},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name:  "mosquitto",
+							Image: "eclipse-mosquitto:2",
+							Ports: []corev1.ContainerPort{{Name: "mqtt", ContainerPort: 1883}},
+							VolumeMounts: []corev1.VolumeMount{{
+								Name:      "config",
+								MountPath: "/mosquitto/config/mosquitto.conf",
+								SubPath:   "mosquitto.conf",
+							}},
+						}},
+						Volumes: []corev1.Volume{{
+							Name: "config",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: name},
+								},
+							},
+						}},
+					},
+				},
+			},
+		}
+		svc := &corev1.Service{
+			ObjectMeta: meta,
+			Spec: corev1.ServiceSpec{
+				Type:     corev1.ServiceTypeNodePort,
+				Selector: meta.Labels,
+				Ports: []corev1.ServicePort{{
+					Name:       "mqtt",
+					Port:       1883,
+					TargetPort: intstr.FromString("mqtt"),
+					NodePort:   mqttNodePort,
+				}},
+			},
+		}
+		for _, obj := range []k8s.Object{cm, dep, svc} {
+			if err := cfg.Client().Resources().Create(ctx, obj); err != nil {
+				return ctx, fmt.Errorf("failed to create mqtt %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, err)
+			}
+		}
+
+		err = wait.For(conditions.New(cfg.Client().Resources()).ResourceScaled(dep, func(object k8s.Object) int32 {
+			return object.(*appsv1.Deployment).Status.ReadyReplicas
+		}, 1), wait.WithTimeout(waitTimeout))
+		if err != nil {
+			return ctx, fmt.Errorf("failed to wait for mqtt to be ready: %w", err)
+		}
+
+		return context.WithValue(ctx, mqttContextKey(name), svc.ObjectMeta), nil
+	}
+}
+
+// MQTTBroker returns the in-cluster address of the MQTT broker, as it should be configured in the DataSources.
+func MQTTBroker(ctx context.Context, name string) string {
+	svc := ctx.Value(mqttContextKey(name)).(v1.ObjectMeta)
+	return fmt.Sprintf("tcp://%s.%s:1883", svc.Name, svc.Namespace)
+}
+
+// ApplyFixtures creates the manifests in the given directory (ordered by their file name) in the namespace.
+// `${VAR}` references in the manifests are expanded by the vars that are returned from varsFn.
+func ApplyFixtures(namespace, dir string, varsFn func(ctx context.Context) map[string]string) features.Func {
+	return func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		r, err := resources.New(c.Client().RESTConfig())
+		if err != nil {
+			t.Errorf("failed to create resources client: %s", err)
+			t.FailNow()
+			return ctx
+		}
+		r = r.WithNamespace(namespace)
+		if err := manifests.AddToScheme(r.GetScheme()); err != nil {
+			t.Errorf("failed to add manifests to scheme: %s", err)
+			t.FailNow()
+			return ctx
+		}
+
+		var vars map[string]string
+		if varsFn != nil {
+			vars = varsFn(ctx)
+		}
+
+		files, err := filepath.Glob(filepath.Join(dir, "*.y*ml"))
+		if err != nil {
+			t.Errorf("failed to list fixtures: %s", err)
+			t.FailNow()
+			return ctx
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				t.Errorf("failed to read fixture %s: %s", file, err)
+				t.FailNow()
+				return ctx
+			}
+			content := os.Expand(string(b), func(k string) string { return vars[k] })
+			err = decoder.DecodeEach(ctx, strings.NewReader(content), decoder.CreateHandler(r), decoder.MutateNamespace(namespace))
+			if err != nil {
+				t.Errorf("failed to apply fixture %s: %s", file, err)
+				t.FailNow()
+				return ctx
+			}
+		}
+		return ctx
+	}
+}
+
+// PublishEvents publishes the events as JSON messages to the MQTT topic.
+func PublishEvents(topic string, events ...map[string]any) error {
+	opts := paho.NewClientOptions().
+		AddBroker(mqttHostBroker).
+		SetClientID(envconf.RandomName("e2e-publisher", 24)).
+		SetConnectTimeout(10 * time.Second)
+	client := paho.NewClient(opts)
+	if t := client.Connect(); t.Wait() && t.Error() != nil {
+		return fmt.Errorf("failed to connect to the mqtt broker: %w", t.Error())
+	}
+	defer client.Disconnect(250)
+
+	for _, ev := range events {
+		payload, err := json.Marshal(ev)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		if t := client.Publish(topic, 1, false, payload); t.Wait() && t.Error() != nil {
+			return fmt.Errorf("failed to publish event: %w", t.Error())
+		}
+	}
+	return nil
+}
+
+// Eventually waits for the condition to be met, since the values are propagated asynchronously.
+func Eventually(condition func() (bool, error)) error {
+	return wait.For(condition, wait.WithInterval(assessInterval), wait.WithTimeout(assessTimeout))
+}
+
+// ValueEquals checks that the feature serves the expected value for the keys.
+func ValueEquals(ctx context.Context, selector string, keys api.Keys, want any) (bool, error) {
+	sdkClient, err := CreateSDK()
+	if err != nil {
+		return false, fmt.Errorf("failed to create sdk client: %w", err)
+	}
+	v, _, err := sdkClient.Get(ctx, selector, keys)
+	if err != nil {
+		return false, nil
+	}
+	return fmt.Sprint(v.Value) == fmt.Sprint(want), nil
+}
+
+// HistoricalRows reads the historical records of a feature that were written by the Historian of the named Core.
+func HistoricalRows(ctx context.Context, name string, p parquet.Predicate) ([]parquet.HistoricalRecord, error) {
+	cfg, ok := ctx.Value(historicalContextKey(name)).(*viper.Viper)
+	if !ok {
+		return nil, fmt.Errorf("no historical storage found for core %s", name)
+	}
+	reader, err := s3parquet.HistoricalReader(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create historical reader: %w", err)
+	}
+
+	var ret []parquet.HistoricalRecord
+	err = reader.Read(ctx, p, func(hr parquet.HistoricalRecord) error {
+		ret = append(ret, hr)
+		return nil
+	})
+	return ret, err
+}
@@ -184,6 +184,23 @@ func SetupCore(name, kindClusterName, imgBasename, buildTag string, args ...stri
 		redisSvc := ctx.Value(redisContextKey(redisName)).(v1.ObjectMeta)
 		args = append(args, fmt.Sprintf("--redis=%s.%s:6379", redisSvc.Name, redisSvc.Namespace))
 
+		// Create the historical storage. MinIO is shared between the Cores, so each one writes to its own directory.
+		ctx, err = SetupMinio("minio")(ctx, cfg)
+		if err != nil {
+			return ctx, fmt.Errorf("failed to setup minio: %w", err)
+		}
+		minioSvc := ctx.Value(minioContextKey("minio")).(v1.ObjectMeta)
+		basedir := fmt.Sprintf("%s/features/", ns)
+		args = append(args,
+			fmt.Sprintf("--s3-endpoint=http://%s.%s:9000", minioSvc.Name, minioSvc.Namespace),
+			fmt.Sprintf("--s3-bucket=%s", minioBucket),
+			fmt.Sprintf("--s3-basedir=%s", basedir),
+			fmt.Sprintf("--aws-access-key=%s", minioUser),
+			fmt.Sprintf("--aws-secret-key=%s", minioPassword),
+			fmt.Sprintf("--aws-region=%s", minioRegion),
+		)
+		ctx = context.WithValue(ctx, historicalContextKey(name), historicalConfig(basedir))
+
 		// Upload images to the registry
 		coreImg := fmt.Sprintf("%s-core:%s", imgBasename, buildTag)
 		ctx, err = envfuncs.LoadDockerImageToCluster(kindClusterName, coreImg)(ctx, cfg)
@@ -197,6 +214,12 @@ func SetupCore(name, kindClusterName, imgBasename, buildTag string, args ...stri
 			return ctx, fmt.Errorf("failed to load core image: %w", err)
 		}
 
+		mqttRunnerImg := fmt.Sprintf("%s-mqtt-runner:%s", imgBasename, buildTag)
+		ctx, err = envfuncs.LoadDockerImageToCluster(kindClusterName, mqttRunnerImg)(ctx, cfg)
+		if err != nil {
+			return ctx, fmt.Errorf("failed to load mqtt runner image: %w", err)
+		}
+
 		runtimeImgBase := fmt.Sprintf("%s-runtime", imgBasename)
 		for _, rt := range supportedRuntimes {
 			runtimeImg := fmt.Sprintf("%s:%s-%s", runtimeImgBase, buildTag, rt)
@@ -245,6 +268,20 @@ func SetupCore(name, kindClusterName, imgBasename, buildTag string, args ...stri
 			return ctx, fmt.Errorf("failed to wait for Core to be ready: %w", err)
 		}
 
+		hdep := &appsv1.Deployment{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "raptor-historian",
+				Namespace: ns,
+			},
+		}
+		err = wait.For(conditions.New(cfg.Client().Resources()).ResourceScaled(hdep, func(object k8s.Object) int32 {
+			return object.(*appsv1.Deployment).Status.ReadyReplicas
+		}, 1), wait.WithTimeout(waitTimeout))
+		if err != nil {
+			CollectNamespaceLogs(ns, -1)(ctx, cfg)
+			return ctx, fmt.Errorf("failed to wait for Historian to be ready: %w", err)
+		}
+
 		return ctx, nil
 	}
 }
@@ -329,10 +366,6 @@ func MutateRaptorKustomize(ns string, baseImg string, buildTag string, args ...s
 						dep.Spec.Template.Spec.Containers[i].Args = append(c.Args, args...)
 					}
 				}
-
-				//TODO remove this once we have historian tests
-				zero := int32(0)
-				dep.Spec.Replicas = &zero
 			}
 		}
 		return nil
@@ -0,0 +1,252 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package encryption seals the values of encrypted features (see api.Encryption) by envelope encryption: the values are
+// encrypted by data keys of the crypto provider, and the data keys are wrapped by the KeyManager of the feature, and
+// stored alongside the values.
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/crypto"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// envelopePrefix prefixes the sealed values, followed by the wrapped data key and the ciphertext.
+const envelopePrefix = "enc:v1:"
+
+// DefaultDataKeyTTL is the default time a data key seals values before it's rotated.
+const DefaultDataKeyTTL = time.Hour
+
+// unwrappedKeys is the maximal number of unwrapped data keys that are cached.
+const unwrappedKeys = 10_000
+
+// Config configures the Keyring.
+type Config struct {
+	// KeyManager creates the KeyManager of a provider.
+	KeyManager func(provider string) (api.KeyManager, error)
+	// DataKeyTTL is the time a data key seals values before it's rotated, and the time an unwrapped data key is cached.
+	DataKeyTTL time.Duration
+}
+
+// Keyring seals and unseals the values of encrypted features. A data key is generated (and wrapped once) for every
+// key-encryption key, and it's rotated every DataKeyTTL, so the KeyManagers are called only when a key is rotated, or
+// when a value of an unknown data key is unsealed.
+type Keyring struct {
+	cfg Config
+
+	mu       sync.Mutex
+	managers map[string]api.KeyManager
+	active   map[api.Encryption]*dataKey
+
+	unwrapped *ttlcache.Cache[string, []byte]
+}
+
+type dataKey struct {
+	key     []byte
+	wrapped string
+	expires time.Time
+}
+
+// New creates a new Keyring.
+func New(cfg Config) *Keyring {
+	if cfg.DataKeyTTL <= 0 {
+		cfg.DataKeyTTL = DefaultDataKeyTTL
+	}
+	return &Keyring{
+		cfg:      cfg,
+		managers: make(map[string]api.KeyManager),
+		active:   make(map[api.Encryption]*dataKey),
+		unwrapped: ttlcache.New[string, []byte](
+			ttlcache.WithTTL[string, []byte](cfg.DataKeyTTL),
+			ttlcache.WithCapacity[string, []byte](unwrappedKeys),
+		),
+	}
+}
+
+// Check verifies that the KeyManager of the encryption's provider is available.
+func (k *Keyring) Check(enc api.Encryption) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	_, err := k.manager(enc.Provider)
+	return err
+}
+
+// manager returns the KeyManager of the provider. It must be called with the lock held.
+func (k *Keyring) manager(provider string) (api.KeyManager, error) {
+	if km, ok := k.managers[provider]; ok {
+		return km, nil
+	}
+	km, err := k.cfg.KeyManager(provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the key manager: %w", err)
+	}
+	k.managers[provider] = km
+	return km, nil
+}
+
+// dataKey returns the active data key of the key-encryption key, and rotates it when it's expired.
+func (k *Keyring) dataKey(ctx context.Context, enc api.Encryption) (*dataKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if dk, ok := k.active[enc]; ok && time.Now().Before(dk.expires) {
+		return dk, nil
+	}
+	km, err := k.manager(enc.Provider)
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, crypto.Default().KeySize())
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("failed to generate a data key: %w", err)
+	}
+	wrapped, err := km.WrapKey(ctx, enc.KeyID, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap the data key: %w", err)
+	}
+	dk := &dataKey{
+		key:     key,
+		wrapped: base64.RawURLEncoding.EncodeToString(wrapped),
+		expires: time.Now().Add(k.cfg.DataKeyTTL),
+	}
+	k.active[enc] = dk
+	k.unwrapped.Set(dk.wrapped, key, ttlcache.DefaultTTL)
+	return dk, nil
+}
+
+// unwrap returns the data key of a wrapped (and encoded) data key.
+func (k *Keyring) unwrap(ctx context.Context, enc api.Encryption, wrapped string) ([]byte, error) {
+	if item := k.unwrapped.Get(wrapped); item != nil {
+		return item.Value(), nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid data key", crypto.ErrDecryption)
+	}
+	k.mu.Lock()
+	km, err := k.manager(enc.Provider)
+	k.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	key, err := km.UnwrapKey(ctx, enc.KeyID, raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap the data key: %w", err)
+	}
+	k.unwrapped.Set(wrapped, key, ttlcache.DefaultTTL)
+	return key, nil
+}
+
+// Seal encrypts the value of the feature. The value is bound to the feature and to the entity by the additional data
+// (aad), so a sealed value can't be served for another feature or entity.
+func (k *Keyring) Seal(ctx context.Context, fd api.FeatureDescriptor, aad string, val any) (api.SealedValue, error) {
+	if fd.Encryption == nil {
+		return "", fmt.Errorf("feature %s is not encrypted", fd.FQN)
+	}
+	plaintext, err := marshal(val, fd.Primitive)
+	if err != nil {
+		return "", err
+	}
+	dk, err := k.dataKey(ctx, *fd.Encryption)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := crypto.Default().Encrypt(dk.key, plaintext, []byte(aad))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt the value: %w", err)
+	}
+	return api.SealedValue(envelopePrefix + dk.wrapped + "." + base64.RawURLEncoding.EncodeToString(ciphertext)), nil
+}
+
+// Unseal decrypts a value that was sealed by Seal with the same additional data (aad).
+func (k *Keyring) Unseal(ctx context.Context, fd api.FeatureDescriptor, aad string, sealed api.SealedValue) (any, error) {
+	if fd.Encryption == nil {
+		return nil, fmt.Errorf("feature %s is not encrypted", fd.FQN)
+	}
+	wrapped, ciphertext, ok := strings.Cut(strings.TrimPrefix(string(sealed), envelopePrefix), ".")
+	if !ok || !strings.HasPrefix(string(sealed), envelopePrefix) {
+		return nil, fmt.Errorf("%w: the value is not sealed", crypto.ErrDecryption)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid ciphertext", crypto.ErrDecryption)
+	}
+	key, err := k.unwrap(ctx, *fd.Encryption, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := crypto.Default().Decrypt(key, raw, []byte(aad))
+	if err != nil {
+		return nil, err
+	}
+	return unmarshal(plaintext, fd.Primitive)
+}
+
+// IsSealed checks if the string is a sealed value (i.e. as stored in the state).
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, envelopePrefix)
+}
+
+// marshal encodes the value by the string representation of its scalars (see api.ScalarString), so it's decoded
+// without a loss of precision (i.e. of large integers, or of the zone offset of timestamps).
+func marshal(val any, primitive api.PrimitiveType) ([]byte, error) {
+	if val == nil || api.TypeDetect(val) != primitive {
+		return nil, fmt.Errorf("value mismatch: got value with a different type than the feature type")
+	}
+	if primitive.Scalar() {
+		return []byte(api.ScalarString(val)), nil
+	}
+	rv := reflect.ValueOf(val)
+	items := make([]string, rv.Len())
+	for i := range items {
+		items[i] = api.ScalarString(rv.Index(i).Interface())
+	}
+	return json.Marshal(items)
+}
+
+func unmarshal(plaintext []byte, primitive api.PrimitiveType) (any, error) {
+	if primitive.Scalar() {
+		return api.ScalarFromString(string(plaintext), primitive)
+	}
+	var items []string
+	if err := json.Unmarshal(plaintext, &items); err != nil {
+		return nil, fmt.Errorf("failed to decode the value: %w", err)
+	}
+	if len(items) == 0 {
+		return primitive.Interface(), nil
+	}
+	ret := make([]any, len(items))
+	for i, item := range items {
+		v, err := api.ScalarFromString(item, primitive.Singular())
+		if err != nil {
+			return nil, fmt.Errorf("item #%d: %w", i, err)
+		}
+		ret[i] = v
+	}
+	return api.NormalizeAny(ret)
+}
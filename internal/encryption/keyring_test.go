@@ -0,0 +1,196 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/crypto"
+)
+
+// xorKeyManager wraps the keys by a XOR with the key ID, and counts the calls.
+type xorKeyManager struct {
+	wraps, unwraps atomic.Int32
+}
+
+func (m *xorKeyManager) xor(keyID string, key []byte) []byte {
+	ret := make([]byte, len(key))
+	for i := range key {
+		ret[i] = key[i] ^ keyID[i%len(keyID)]
+	}
+	return ret
+}
+
+func (m *xorKeyManager) WrapKey(_ context.Context, keyID string, key []byte) ([]byte, error) {
+	m.wraps.Add(1)
+	return m.xor(keyID, key), nil
+}
+
+func (m *xorKeyManager) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	m.unwraps.Add(1)
+	return m.xor(keyID, wrapped), nil
+}
+
+func newKeyring(km api.KeyManager, ttl time.Duration) *Keyring {
+	return New(Config{
+		KeyManager: func(provider string) (api.KeyManager, error) {
+			if provider != "xor" {
+				return nil, fmt.Errorf("unknown provider %s", provider)
+			}
+			return km, nil
+		},
+		DataKeyTTL: ttl,
+	})
+}
+
+func encrypted(primitive api.PrimitiveType) api.FeatureDescriptor {
+	return api.FeatureDescriptor{
+		FQN:        "default.ssn",
+		Primitive:  primitive,
+		Encryption: &api.Encryption{Provider: "xor", KeyID: "kek"},
+	}
+}
+
+func TestKeyring_SealUnseal(t *testing.T) {
+	kr := newKeyring(&xorKeyManager{}, time.Hour)
+	ts := time.Date(2022, 10, 1, 12, 0, 0, 5, time.FixedZone("", 2*3600))
+	tests := []struct {
+		primitive api.PrimitiveType
+		val       any
+	}{
+		{primitive: api.PrimitiveTypeString, val: "123-45-6789"},
+		{primitive: api.PrimitiveTypeString, val: ""},
+		{primitive: api.PrimitiveTypeInteger, val: 1<<62 + 1},
+		{primitive: api.PrimitiveTypeFloat, val: 0.1},
+		{primitive: api.PrimitiveTypeBoolean, val: true},
+		{primitive: api.PrimitiveTypeTimestamp, val: ts},
+		{primitive: api.PrimitiveTypeStringList, val: []string{"a", "b,c", `"d"`}},
+		{primitive: api.PrimitiveTypeIntegerList, val: []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.primitive.String(), func(t *testing.T) {
+			fd := encrypted(tt.primitive)
+			sealed, err := kr.Seal(context.Background(), fd, "k1", tt.val)
+			if err != nil {
+				t.Fatalf("failed to seal: %v", err)
+			}
+			if !IsSealed(string(sealed)) {
+				t.Errorf("expected a sealed value, got %s", sealed)
+			}
+			if s := fmt.Sprint(tt.val); len(s) > 2 && strings.Contains(string(sealed), s) {
+				t.Errorf("the sealed value discloses the plaintext: %s", sealed)
+			}
+
+			got, err := kr.Unseal(context.Background(), fd, "k1", sealed)
+			if err != nil {
+				t.Fatalf("failed to unseal: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.val) {
+				t.Errorf("got %#v, want %#v", got, tt.val)
+			}
+		})
+	}
+}
+
+func TestKeyring_Tampering(t *testing.T) {
+	kr := newKeyring(&xorKeyManager{}, time.Hour)
+	fd := encrypted(api.PrimitiveTypeString)
+	sealed, err := kr.Seal(context.Background(), fd, "k1", "secret")
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+
+	if _, err := kr.Unseal(context.Background(), fd, "k2", sealed); !errors.Is(err, crypto.ErrDecryption) {
+		t.Errorf("expected a sealed value of another entity to fail, got %v", err)
+	}
+	flipped := []byte(sealed)
+	flipped[len(flipped)-2] ^= 1
+	for name, s := range map[string]string{
+		"flipped":    string(flipped),
+		"truncated":  string(sealed[:len(sealed)-4]),
+		"plaintext":  "secret",
+		"no key":     envelopePrefix + "abc",
+		"bad base64": envelopePrefix + "abc.!!",
+	} {
+		if _, err := kr.Unseal(context.Background(), fd, "k1", api.SealedValue(s)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	other := encrypted(api.PrimitiveTypeString)
+	other.Encryption = &api.Encryption{Provider: "unknown", KeyID: "kek"}
+	if err := kr.Check(*other.Encryption); err == nil {
+		t.Errorf("expected an unknown provider to fail")
+	}
+	if _, err := kr.Seal(context.Background(), fd, "k1", 42); err == nil {
+		t.Errorf("expected a value of another type to fail")
+	}
+}
+
+func TestKeyring_DataKeys(t *testing.T) {
+	km := &xorKeyManager{}
+	kr := newKeyring(km, time.Hour)
+	fd := encrypted(api.PrimitiveTypeString)
+
+	var sealed []api.SealedValue
+	for i := 0; i < 3; i++ {
+		s, err := kr.Seal(context.Background(), fd, "k1", "secret")
+		if err != nil {
+			t.Fatalf("failed to seal: %v", err)
+		}
+		sealed = append(sealed, s)
+	}
+	if sealed[0] == sealed[1] {
+		t.Errorf("expected every value to be sealed with a unique nonce")
+	}
+	if n := km.wraps.Load(); n != 1 {
+		t.Errorf("expected the data key to be wrapped once, got %d", n)
+	}
+
+	// another keyring (i.e. another replica) unwraps the data key once
+	other := newKeyring(km, time.Hour)
+	for _, s := range sealed {
+		if _, err := other.Unseal(context.Background(), fd, "k1", s); err != nil {
+			t.Fatalf("failed to unseal: %v", err)
+		}
+	}
+	if n := km.unwraps.Load(); n != 1 {
+		t.Errorf("expected the data key to be unwrapped once, got %d", n)
+	}
+
+	// expired data keys are rotated
+	rotating := newKeyring(km, time.Nanosecond)
+	a, _ := rotating.Seal(context.Background(), fd, "k1", "secret")
+	time.Sleep(time.Millisecond)
+	b, _ := rotating.Seal(context.Background(), fd, "k1", "secret")
+	wrappedKey := func(s api.SealedValue) []byte {
+		k, _, _ := strings.Cut(strings.TrimPrefix(string(s), envelopePrefix), ".")
+		return []byte(k)
+	}
+	if bytes.Equal(wrappedKey(a), wrappedKey(b)) {
+		t.Errorf("expected the data key to be rotated")
+	}
+}
@@ -0,0 +1,263 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// minAdvisorReads is the minimal number of reads that found a value before the settings of a feature are tuned.
+	minAdvisorReads = 100
+	// advisorExpiredRatio is the ratio of reads that found an expired value above which the staleness is too short.
+	advisorExpiredRatio = 0.01
+	// advisorHeadroom is the factor that is added on top of the observed ages, so the usage can grow a bit.
+	advisorHeadroom = 1.5
+)
+
+// usage tracks the read and write patterns of the features, so the StalenessAdvisor can recommend their settings.
+// The usage is kept per instance, and it's reset when the feature is re-bound since its settings may have changed.
+type usage struct {
+	m sync.Map
+}
+
+type featureUsage struct {
+	since                                       time.Time
+	reads, hits, expired, previousReads, writes atomic.Uint64
+
+	mu sync.Mutex
+	// readAge and previousReadAge are the ages of the read values, in milliseconds.
+	readAge, previousReadAge api.QuantileSketch
+}
+
+func (u *usage) get(fqn string) *featureUsage {
+	if v, ok := u.m.Load(fqn); ok {
+		return v.(*featureUsage)
+	}
+	v, _ := u.m.LoadOrStore(fqn, &featureUsage{
+		since:           time.Now(),
+		readAge:         api.QuantileSketch{},
+		previousReadAge: api.QuantileSketch{},
+	})
+	return v.(*featureUsage)
+}
+
+func (u *usage) reset(fqn string) {
+	u.m.Delete(fqn)
+}
+
+// read records a read of the feature. previous is true if the read was of a previous version of the value.
+func (u *usage) read(fqn string, val api.Value, previous bool) {
+	fu := u.get(fqn)
+	fu.reads.Add(1)
+	if val.Value == nil || val.Fallback {
+		// fallbacks are misses
+		return
+	}
+	age := float64(time.Since(val.Timestamp).Milliseconds())
+	if age < 0 {
+		age = 0
+	}
+
+	fu.mu.Lock()
+	defer fu.mu.Unlock()
+	if previous {
+		fu.previousReads.Add(1)
+		fu.previousReadAge.Add(age)
+		return
+	}
+	fu.hits.Add(1)
+	fu.readAge.Add(age)
+}
+
+// expire records a read that found a value older than the staleness.
+func (u *usage) expire(fqn string) {
+	u.get(fqn).expired.Add(1)
+}
+
+func (u *usage) write(fqn string) {
+	u.get(fqn).writes.Add(1)
+}
+
+var advisorPercentiles = map[string]float64{"p50": 0.5, "p90": 0.9, "p99": 0.99}
+
+// stats returns a snapshot of the usage, and the observed read ages by percentile.
+func (fu *featureUsage) stats() (api.UsageStats, map[string]time.Duration, map[string]time.Duration) {
+	ret := api.UsageStats{
+		Since:         fu.since,
+		Reads:         fu.reads.Load(),
+		Hits:          fu.hits.Load(),
+		Expired:       fu.expired.Load(),
+		PreviousReads: fu.previousReads.Load(),
+		Writes:        fu.writes.Load(),
+	}
+	if elapsed := time.Since(fu.since).Seconds(); elapsed > 0 {
+		ret.WritesPerSecond = float64(ret.Writes) / elapsed
+	}
+
+	fu.mu.Lock()
+	defer fu.mu.Unlock()
+	readAge := percentiles(fu.readAge)
+	previousReadAge := percentiles(fu.previousReadAge)
+	ret.ReadAge = durationStrings(readAge)
+	ret.PreviousReadAge = durationStrings(previousReadAge)
+	return ret, readAge, previousReadAge
+}
+
+func percentiles(s api.QuantileSketch) map[string]time.Duration {
+	if len(s) == 0 {
+		return nil
+	}
+	ret := make(map[string]time.Duration, len(advisorPercentiles))
+	for name, q := range advisorPercentiles {
+		v, err := s.Quantile(q)
+		if err != nil {
+			return nil
+		}
+		ret[name] = time.Duration(v * float64(time.Millisecond))
+	}
+	return ret
+}
+
+func durationStrings(m map[string]time.Duration) map[string]string {
+	if m == nil {
+		return nil
+	}
+	ret := make(map[string]string, len(m))
+	for k, v := range m {
+		ret[k] = roundDuration(v).String()
+	}
+	return ret
+}
+
+// roundDuration rounds a recommended duration to a precision that fits its magnitude.
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Hour:
+		return d.Round(time.Minute)
+	case d >= time.Minute:
+		return d.Round(time.Second)
+	case d >= time.Second:
+		return d.Round(100 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(time.Millisecond)
+	default:
+		return time.Millisecond
+	}
+}
+
+// StalenessRecommendations implements api.StalenessAdvisor
+func (e *engine) StalenessRecommendations(_ context.Context, fqn string) ([]api.StalenessRecommendation, error) {
+	var fps []*FeaturePipeliner
+	if fqn != "" {
+		f, ok := e.features.Load(fqn)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", api.ErrFeatureNotFound, fqn)
+		}
+		fps = append(fps, f.(*FeaturePipeliner))
+	} else {
+		e.features.Range(func(_, v any) bool {
+			fps = append(fps, v.(*FeaturePipeliner))
+			return true
+		})
+		sort.Slice(fps, func(i, j int) bool { return fps[i].FQN < fps[j].FQN })
+	}
+
+	ret := make([]api.StalenessRecommendation, 0, len(fps))
+	for _, f := range fps {
+		ret = append(ret, recommend(f.FeatureDescriptor, e.usage.get(f.FQN)))
+	}
+	return ret, nil
+}
+
+func stalenessSettings(fd api.FeatureDescriptor) api.StalenessSettings {
+	ret := api.StalenessSettings{
+		Freshness: fd.Freshness.String(),
+		Staleness: fd.Staleness.String(),
+	}
+	if fd.KeepPrevious != nil {
+		ret.Retention = fd.KeepPrevious.Over.String()
+	}
+	return ret
+}
+
+// recommend tunes the settings of a feature by the age of the values when they are read:
+//   - The staleness is increased if reads find expired values, or decreased if values are never read near it.
+//   - The freshness is increased if most of the reads find values older than it, since they are updated less often.
+//   - The retention of previous versions is decreased if they are never read near it.
+func recommend(fd api.FeatureDescriptor, fu *featureUsage) api.StalenessRecommendation {
+	st, readAge, previousReadAge := fu.stats()
+	ret := api.StalenessRecommendation{
+		FQN:         fd.FQN,
+		Current:     stalenessSettings(fd),
+		Recommended: stalenessSettings(fd),
+		Usage:       st,
+	}
+
+	switch {
+	case fd.DataSource == "":
+		ret.Reasons = append(ret.Reasons, "the feature is computed on read and its values are not stored; nothing to tune")
+		return ret
+	case fd.ValidWindow():
+		ret.Reasons = append(ret.Reasons, "the freshness and staleness of windowed features define their buckets and window; nothing to tune")
+		return ret
+	case st.Hits < minAdvisorReads:
+		ret.Reasons = append(ret.Reasons, fmt.Sprintf("not enough reads to recommend settings (%d of %d)", st.Hits, minAdvisorReads))
+		return ret
+	}
+
+	staleness := fd.Staleness
+	if ratio := float64(st.Expired) / float64(st.Reads); ratio > advisorExpiredRatio {
+		staleness = roundDuration(2 * fd.Staleness)
+		ret.Reasons = append(ret.Reasons, fmt.Sprintf("%.1f%% of the reads found a value older than the staleness and ignored it", ratio*100))
+	} else if d := time.Duration(advisorHeadroom * float64(readAge["p99"])); fd.Staleness > 0 && d < fd.Staleness/2 {
+		staleness = roundDuration(d)
+		ret.Reasons = append(ret.Reasons, fmt.Sprintf("99%% of the reads found a value younger than %s; a shorter staleness frees the state earlier", roundDuration(readAge["p99"])))
+	}
+
+	freshness := fd.Freshness
+	if fd.Freshness > 0 && readAge["p50"] > fd.Freshness {
+		freshness = roundDuration(readAge["p90"])
+		ret.Reasons = append(ret.Reasons, fmt.Sprintf("most of the reads found a value older than the freshness; the values are updated about every %s", freshness))
+	}
+	if staleness < freshness {
+		staleness = freshness
+	}
+	ret.Recommended.Freshness = freshness.String()
+	ret.Recommended.Staleness = staleness.String()
+
+	if kp := fd.KeepPrevious; kp != nil && kp.Over > 0 {
+		if st.PreviousReads == 0 {
+			ret.Recommended.Retention = time.Duration(0).String()
+			ret.Reasons = append(ret.Reasons, "previous versions of the values were never read; consider removing keep_previous")
+		} else if d := time.Duration(advisorHeadroom * float64(previousReadAge["p99"])); d < kp.Over/2 {
+			ret.Recommended.Retention = roundDuration(d).String()
+			ret.Reasons = append(ret.Reasons, fmt.Sprintf("99%% of the reads of previous versions found a value younger than %s", roundDuration(previousReadAge["p99"])))
+		}
+	}
+
+	if len(ret.Reasons) == 0 {
+		ret.Reasons = append(ret.Reasons, "the current settings fit the observed usage")
+	}
+	return ret
+}
@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"slices"
+)
+
+// SetAlias implements api.AliasStore by the State
+func (e *engine) SetAlias(ctx context.Context, alias api.EntityAlias) error {
+	if err := alias.Validate(); err != nil {
+		return err
+	}
+	s, ok := e.state.(api.AliasStore)
+	if !ok {
+		return fmt.Errorf("the state provider doesn't support entity aliases")
+	}
+	return s.SetAlias(ctx, alias)
+}
+
+// GetAlias implements api.AliasStore by the State
+func (e *engine) GetAlias(ctx context.Context, aliasType, aliasID string) (api.EntityAlias, error) {
+	s, ok := e.state.(api.AliasStore)
+	if !ok {
+		return api.EntityAlias{}, fmt.Errorf("the state provider doesn't support entity aliases")
+	}
+	return s.GetAlias(ctx, aliasType, aliasID)
+}
+
+// DeleteAlias implements api.AliasStore by the State
+func (e *engine) DeleteAlias(ctx context.Context, aliasType, aliasID string) error {
+	s, ok := e.state.(api.AliasStore)
+	if !ok {
+		return fmt.Errorf("the state provider doesn't support entity aliases")
+	}
+	return s.DeleteAlias(ctx, aliasType, aliasID)
+}
+
+// resolveAliases resolves the keys of the feature that are missing from the keys, by the other keys that are alternate
+// IDs of them (see api.AliasStore). The State is queried only when keys are missing, and the given keys are not
+// modified. Keys that can't be resolved are kept missing, so they're reported as such.
+func (e *engine) resolveAliases(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys) (api.Keys, error) {
+	missing := make(map[string]bool)
+	for _, k := range fd.Keys {
+		if _, ok := keys[k]; !ok {
+			missing[k] = true
+		}
+	}
+	if len(missing) == 0 || len(keys) == 0 {
+		return keys, nil
+	}
+	s, ok := e.state.(api.AliasStore)
+	if !ok {
+		return keys, nil
+	}
+
+	ret := make(api.Keys, len(keys)+len(missing))
+	for k, v := range keys {
+		ret[k] = v
+	}
+	for k, v := range keys {
+		if len(missing) == 0 || slices.Contains(fd.Keys, k) {
+			continue
+		}
+		alias, err := s.GetAlias(ctx, k, v)
+		if goerrors.Is(err, api.ErrAliasNotFound) {
+			continue
+		}
+		if err != nil {
+			return keys, fmt.Errorf("failed to resolve the alias %s of the entity: %w", k, err)
+		}
+		if missing[alias.EntityType] {
+			ret[alias.EntityType] = alias.EntityID
+			delete(missing, alias.EntityType)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,262 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/stats"
+	"github.com/raptor-ml/raptor/internal/wal"
+	"sync"
+	"time"
+)
+
+const (
+	// asyncBatchSize is the maximal number of logged writes that are applied to the state at once.
+	asyncBatchSize = 500
+	// asyncMaxAttempts is the number of attempts to apply a logged write, before it's sent to the dead-letter queue.
+	asyncMaxAttempts = 5
+	// asyncRetryInterval is the time between two attempts to apply the logged writes.
+	asyncRetryInterval = time.Second
+	// asyncApplyTimeout is the timeout of applying a batch of logged writes.
+	asyncApplyTimeout = 30 * time.Second
+	// asyncUnboundGrace is the time the logged writes of features that are not bound are kept after startup, since
+	// the features are bound gradually after a restart.
+	asyncUnboundGrace = 5 * time.Minute
+)
+
+// asyncDeletion is a deletion of an entity, that drops the logged writes of the entity up to seq.
+type asyncDeletion struct {
+	fqn, key, id string
+	seq          uint64
+}
+
+// asyncDeletions holds the deletions of entities whose logged writes were not committed yet.
+type asyncDeletions struct {
+	mu        sync.Mutex
+	deletions []asyncDeletion
+}
+
+// asyncWritable checks if the write of the feature should be logged, and applied to the state asynchronously.
+func (e *engine) asyncWritable(fd api.FeatureDescriptor, method api.StateMethod) bool {
+	return fd.AsyncWrites && e.wal != nil && !fd.ValidWindow() && method != api.StateMethodWindowAdd
+}
+
+// asyncWrite logs the write, to be applied to the state by applyLog.
+func (e *engine) asyncWrite(fd api.FeatureDescriptor, method api.StateMethod, keys api.Keys, val api.Value) error {
+	return e.wal.Append(wal.Entry{
+		FQN:       fd.FQN,
+		Method:    updateMethod(fd, method),
+		Keys:      keys,
+		Value:     val.Value,
+		Timestamp: val.Timestamp,
+	})
+}
+
+// applyLog applies the logged writes to the state in batches, in the order they were logged. It runs for the lifetime
+// of the engine.
+func (e *engine) applyLog() {
+	started := time.Now()
+	window := asyncBatchSize
+	for {
+		stats.SetWALPendingWrites(e.wal.Len())
+		entries := e.wal.Pending(window)
+		if len(entries) == 0 {
+			<-e.wal.Notify()
+			continue
+		}
+		done, failed := e.applyEntries(entries, started)
+
+		// the log is committed in order, so only the entries up to the first one that is not done are committed
+		n := 0
+		for n < len(entries) && entries[n].Applied {
+			n++
+		}
+		if n > 0 {
+			if err := e.wal.Commit(n); err != nil {
+				e.logger.Error(err, "failed to commit the applied async writes")
+			}
+			e.pruneAsyncDeletions(entries[n-1].Seq)
+		}
+
+		// the entries that wait for their features to be bound are kept in the window, while the following entries
+		// are applied
+		requested := window
+		window = len(entries) - n + asyncBatchSize
+		if failed || (!done && len(entries) < requested) {
+			time.Sleep(asyncRetryInterval)
+		}
+	}
+}
+
+// applyEntries applies the entries that were not applied yet, and reports whether all of them are done (either
+// applied or dropped), and whether any of them failed. Entries that failed are retried, until they're sent to the
+// dead-letter queue.
+func (e *engine) applyEntries(entries []*wal.Entry, started time.Time) (done, failed bool) {
+	done = true
+	var writes []api.StateWrite
+	var applying []*wal.Entry
+	waiting := make(map[string]bool)
+	for _, le := range entries {
+		if le.Applied {
+			continue
+		}
+		if e.asyncDeleted(le) {
+			le.Applied = true
+			continue
+		}
+		if waiting[le.FQN] {
+			done = false
+			continue
+		}
+		f, ok := e.features.Load(le.FQN)
+		if !ok {
+			if time.Since(started) < asyncUnboundGrace {
+				// the feature might not be bound yet, so its writes wait for it to keep their order, while the writes
+				// of the other features are applied
+				waiting[le.FQN] = true
+				done = false
+				continue
+			}
+			e.logger.Info("dropped an async write of a feature that isn't bound", "feature", le.FQN)
+			le.Applied = true
+			continue
+		}
+		fd := storedDescriptor(f.(*FeaturePipeliner).FeatureDescriptor)
+		if le.Recovered {
+			primitive := fd.Primitive
+			if le.Method == api.StateMethodAppend {
+				primitive = primitive.Singular()
+			}
+			v, err := api.FromJSONValue(le.Value, primitive)
+			if err != nil {
+				e.asyncDeadLetter(le, err)
+				le.Applied = true
+				continue
+			}
+			le.Value, le.Recovered = v, false
+		}
+		writes = append(writes, api.StateWrite{FeatureDescriptor: fd, Method: le.Method, Keys: le.Keys, Value: le.Value, Timestamp: le.Timestamp})
+		applying = append(applying, le)
+	}
+	if len(writes) == 0 {
+		return done, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), asyncApplyTimeout)
+	defer cancel()
+	errs := e.writeStateBatch(ctx, writes)
+	for i, le := range applying {
+		if errs == nil || errs[i] == nil {
+			le.Applied = true
+			if encodedKeys, err := le.Keys.Encode(writes[i].FeatureDescriptor); err == nil {
+				e.invalidate(writes[i].FeatureDescriptor, encodedKeys)
+			}
+			continue
+		}
+		le.Attempts++
+		if le.Attempts >= asyncMaxAttempts {
+			e.asyncDeadLetter(le, errs[i])
+			le.Applied = true
+			continue
+		}
+		done, failed = false, true
+	}
+	return done, failed
+}
+
+// writeStateBatch applies the writes by the State's batch writer, or one by one if it doesn't support batches.
+func (e *engine) writeStateBatch(ctx context.Context, writes []api.StateWrite) []error {
+	if bw, ok := e.state.(api.BatchWriter); ok {
+		return bw.WriteBatch(ctx, writes)
+	}
+	var errs []error
+	for i, w := range writes {
+		var err error
+		switch w.Method {
+		case api.StateMethodSet:
+			err = e.state.Set(ctx, w.FeatureDescriptor, w.Keys, w.Value, w.Timestamp)
+		case api.StateMethodAppend:
+			err = e.state.Append(ctx, w.FeatureDescriptor, w.Keys, w.Value, w.Timestamp)
+		case api.StateMethodIncr:
+			err = e.state.Incr(ctx, w.FeatureDescriptor, w.Keys, w.Value, w.Timestamp)
+		default:
+			err = fmt.Errorf("method %s can't be written asynchronously", w.Method)
+		}
+		if err != nil {
+			if errs == nil {
+				errs = make([]error, len(writes))
+			}
+			errs[i] = err
+		}
+	}
+	return errs
+}
+
+func (e *engine) asyncDeadLetter(le *wal.Entry, err error) {
+	e.logger.Error(err, "failed to apply an async write", "feature", le.FQN, "keys", le.Keys)
+	dl := api.DeadLetter{
+		FQN:       le.FQN,
+		Keys:      le.Keys,
+		Value:     le.Value,
+		Timestamp: le.Timestamp,
+		Error:     err.Error(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.SendDeadLetter(ctx, dl); err != nil {
+		e.logger.Error(err, "failed to send an async write to the dead-letter queue", "feature", le.FQN)
+	}
+}
+
+// dropAsyncWrites drops the logged writes of the entity that were not applied yet, so they're not written after it
+// was deleted.
+func (e *engine) dropAsyncWrites(fqn, entityType, entityID string) {
+	if e.wal == nil {
+		return
+	}
+	e.asyncDeletions.mu.Lock()
+	defer e.asyncDeletions.mu.Unlock()
+	e.asyncDeletions.deletions = append(e.asyncDeletions.deletions,
+		asyncDeletion{fqn: fqn, key: entityType, id: entityID, seq: e.wal.Seq()})
+}
+
+func (e *engine) asyncDeleted(le *wal.Entry) bool {
+	e.asyncDeletions.mu.Lock()
+	defer e.asyncDeletions.mu.Unlock()
+	for _, d := range e.asyncDeletions.deletions {
+		if d.fqn == le.FQN && le.Seq <= d.seq && le.Keys[d.key] == d.id {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneAsyncDeletions removes the deletions whose logged writes were all committed.
+func (e *engine) pruneAsyncDeletions(committed uint64) {
+	e.asyncDeletions.mu.Lock()
+	defer e.asyncDeletions.mu.Unlock()
+	n := 0
+	for _, d := range e.asyncDeletions.deletions {
+		if d.seq > committed {
+			e.asyncDeletions.deletions[n] = d
+			n++
+		}
+	}
+	e.asyncDeletions.deletions = e.asyncDeletions.deletions[:n]
+}
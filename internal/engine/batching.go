@@ -0,0 +1,258 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/stats"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// pendingWrite is a buffered write of a feature-value of an entity. The following writes of the entity during the
+// batching window are coalesced into it, and their writers wait for it to be flushed.
+type pendingWrite struct {
+	api.StateWrite
+	done []chan error
+}
+
+// writeBatch is the buffered writes of a feature, by the encoded keys of their entities.
+type writeBatch struct {
+	fd     api.FeatureDescriptor
+	writes map[string]*pendingWrite
+	order  []string
+	timer  *time.Timer
+}
+
+// batches holds the write batches of the features that were written during their last batching window.
+type batches struct {
+	mu      sync.Mutex
+	pending map[string]*writeBatch
+}
+
+// batchable checks if the write of the feature should be buffered by its write batching.
+func (e *engine) batchable(fd api.FeatureDescriptor, method api.StateMethod) bool {
+	if fd.WriteBatching == nil || fd.ValidWindow() || method == api.StateMethodWindowAdd {
+		return false
+	}
+	_, ok := e.state.(api.BatchWriter)
+	return ok
+}
+
+// batchWrite buffers the write in the feature's batch, and waits for the batch to be flushed. The batch is flushed
+// at the end of its window, or once it reaches its maximal size.
+func (e *engine) batchWrite(ctx context.Context, fd api.FeatureDescriptor, method api.StateMethod, keys api.Keys, encodedKeys string, val api.Value) error {
+	method = updateMethod(fd, method)
+	done := make(chan error, 1)
+
+	e.batches.mu.Lock()
+	if e.batches.pending == nil {
+		e.batches.pending = make(map[string]*writeBatch)
+	}
+	b, ok := e.batches.pending[fd.FQN]
+	if !ok {
+		b = &writeBatch{fd: fd, writes: make(map[string]*pendingWrite)}
+		b.timer = time.AfterFunc(fd.WriteBatching.Window, func() { e.flushBatch(b) })
+		e.batches.pending[fd.FQN] = b
+	}
+	if pw, ok := b.writes[encodedKeys]; ok {
+		if err := pw.coalesce(method, val); err != nil {
+			e.batches.mu.Unlock()
+			return err
+		}
+		pw.done = append(pw.done, done)
+		stats.IncrCoalescedWrites(fd.FQN)
+	} else {
+		pw := &pendingWrite{
+			StateWrite: api.StateWrite{FeatureDescriptor: fd, Method: method, Keys: keys, Value: val.Value, Timestamp: val.Timestamp},
+			done:       []chan error{done},
+		}
+		if method == api.StateMethodAppend {
+			pw.Value = []any{val.Value}
+		}
+		b.writes[encodedKeys] = pw
+		b.order = append(b.order, encodedKeys)
+	}
+	if len(b.writes) >= fd.WriteBatching.MaxSize && b.timer.Stop() {
+		delete(e.batches.pending, fd.FQN)
+		go e.flushBatch(b)
+	}
+	e.batches.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
+
+// updateMethod resolves an update to the method it's applied by: a set of scalars, or an append to lists.
+func updateMethod(fd api.FeatureDescriptor, method api.StateMethod) api.StateMethod {
+	if method != api.StateMethodUpdate {
+		return method
+	}
+	if fd.Primitive.Scalar() {
+		return api.StateMethodSet
+	}
+	return api.StateMethodAppend
+}
+
+// flushBatch detaches the batch from the feature, so the following writes start a new batch, and writes it to the
+// State.
+func (e *engine) flushBatch(b *writeBatch) {
+	e.batches.mu.Lock()
+	if e.batches.pending[b.fd.FQN] == b {
+		delete(e.batches.pending, b.fd.FQN)
+	}
+	writes := make([]*pendingWrite, 0, len(b.order))
+	for _, ek := range b.order {
+		if pw, ok := b.writes[ek]; ok {
+			writes = append(writes, pw)
+			delete(b.writes, ek)
+		}
+	}
+	b.order = nil
+	e.batches.mu.Unlock()
+	if len(writes) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	cancel := func() {}
+	if b.fd.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, b.fd.Timeout)
+	}
+	defer cancel()
+
+	sws := make([]api.StateWrite, len(writes))
+	for i, pw := range writes {
+		sws[i] = pw.StateWrite
+	}
+	errs := e.state.(api.BatchWriter).WriteBatch(ctx, sws)
+	stats.ObserveWriteBatch(b.fd.FQN, len(sws))
+	for i, pw := range writes {
+		var err error
+		if errs != nil {
+			err = errs[i]
+		}
+		for _, done := range pw.done {
+			done <- err
+		}
+	}
+}
+
+// flushFeatureBatch flushes the pending batch of the feature, i.e. when it's unbound.
+func (e *engine) flushFeatureBatch(fqn string) {
+	e.batches.mu.Lock()
+	b, ok := e.batches.pending[fqn]
+	e.batches.mu.Unlock()
+	if ok && b.timer.Stop() {
+		go e.flushBatch(b)
+	}
+}
+
+// dropBatchedWrites drops the pending batched writes of the entity, so they're not written after it was deleted.
+// Their writers are released as if the writes succeeded, since they were deleted afterwards.
+func (e *engine) dropBatchedWrites(fqn, entityType, entityID string) {
+	e.batches.mu.Lock()
+	defer e.batches.mu.Unlock()
+	b, ok := e.batches.pending[fqn]
+	if !ok {
+		return
+	}
+	for ek, pw := range b.writes {
+		if pw.Keys[entityType] != entityID {
+			continue
+		}
+		for _, done := range pw.done {
+			done <- nil
+		}
+		delete(b.writes, ek)
+	}
+}
+
+// coalesce merges a following write of the entity into the pending write. A set replaces the pending write, an
+// increment is added to the pending value, and an appended value is appended to the pending list.
+func (pw *pendingWrite) coalesce(method api.StateMethod, val api.Value) error {
+	switch method {
+	case api.StateMethodSet:
+		pw.Method = api.StateMethodSet
+		pw.Value = val.Value
+	case api.StateMethodIncr:
+		if pw.Method == api.StateMethodAppend {
+			return fmt.Errorf("can't increment a list value")
+		}
+		sum, err := addNumbers(pw.Value, val.Value)
+		if err != nil {
+			return err
+		}
+		pw.Value = sum
+	case api.StateMethodAppend:
+		switch pw.Method {
+		case api.StateMethodAppend:
+			pw.Value = append(pw.Value.([]any), val.Value)
+		case api.StateMethodSet:
+			rv := reflect.ValueOf(pw.Value)
+			if rv.Kind() != reflect.Slice {
+				return fmt.Errorf("can't append to a scalar value")
+			}
+			l := make([]any, 0, rv.Len()+1)
+			for i := 0; i < rv.Len(); i++ {
+				l = append(l, rv.Index(i).Interface())
+			}
+			pw.Value = append(l, val.Value)
+		default:
+			return fmt.Errorf("can't append to a scalar value")
+		}
+	default:
+		return fmt.Errorf("method %s can't be batched", method)
+	}
+	if val.Timestamp.After(pw.Timestamp) {
+		pw.Timestamp = val.Timestamp
+	}
+	return nil
+}
+
+// addNumbers adds two numeric values. The sum of integers is an integer, and a float otherwise.
+func addNumbers(a, b any) (any, error) {
+	if ai, ok := a.(int); ok {
+		if bi, ok := b.(int); ok {
+			return ai + bi, nil
+		}
+	}
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if !aok || !bok {
+		return nil, fmt.Errorf("can't increment a non-numeric value")
+	}
+	return af + bf, nil
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
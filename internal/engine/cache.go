@@ -0,0 +1,205 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/stats"
+	"golang.org/x/sync/singleflight"
+	"strings"
+	"time"
+)
+
+// DefaultReadCacheMaxTTL is the default maximal time a value is kept in the read-through cache.
+const DefaultReadCacheMaxTTL = 5 * time.Second
+
+// DefaultReadCacheNegativeTTL is the default time a missing value is remembered by the read-through cache.
+const DefaultReadCacheNegativeTTL = time.Second
+
+// invalidationTTL is the time an invalidation is remembered, so a read from the State that started before the
+// invalidation doesn't cache the value it read. It should be longer than the timeout of the reads.
+const invalidationTTL = time.Minute
+
+// ReadCache configures the read-through cache of the values that are read from the State.
+type ReadCache struct {
+	// Size is the maximal number of cached values. The cache is disabled when 0.
+	Size int
+	// MaxTTL is the maximal time a value is cached. The values are cached for the freshness of their feature (bounded
+	// by MaxTTL), and they are invalidated when they are written by this instance, so the values that are written by
+	// other instances may be served up to MaxTTL late.
+	MaxTTL time.Duration
+	// NegativeTTL is the time a missing value (i.e. of an entity that wasn't written yet) is cached, so repeated reads
+	// of missing entities don't reach the State. The missing values are invalidated when they are written by this
+	// instance. Negative caching is disabled when 0.
+	NegativeTTL time.Duration
+}
+
+// readCache is an in-process LRU cache in front of the State. Concurrent reads of the same value are suppressed, so a
+// hot entity is read from the State once, no matter how many requests read it concurrently.
+type readCache struct {
+	maxTTL      time.Duration
+	negativeTTL time.Duration
+	values      *ttlcache.Cache[string, *api.Value]
+	invalidated *ttlcache.Cache[string, time.Time]
+	flight      singleflight.Group
+}
+
+func newReadCache(cfg ReadCache) *readCache {
+	if cfg.Size <= 0 {
+		return nil
+	}
+	if cfg.MaxTTL <= 0 {
+		cfg.MaxTTL = DefaultReadCacheMaxTTL
+	}
+	return &readCache{
+		maxTTL:      cfg.MaxTTL,
+		negativeTTL: cfg.NegativeTTL,
+		values: ttlcache.New[string, *api.Value](
+			ttlcache.WithCapacity[string, *api.Value](uint64(cfg.Size)),
+			ttlcache.WithDisableTouchOnHit[string, *api.Value](),
+		),
+		invalidated: ttlcache.New[string, time.Time](
+			ttlcache.WithTTL[string, time.Time](invalidationTTL),
+			ttlcache.WithCapacity[string, time.Time](uint64(cfg.Size)),
+			ttlcache.WithDisableTouchOnHit[string, time.Time](),
+		),
+	}
+}
+
+func cacheKey(fqn, encodedKeys string, version uint) string {
+	return fmt.Sprintf("%s\x00%s\x00%d", fqn, encodedKeys, version)
+}
+
+// ttl returns the time the value can be cached: the freshness of the feature, bounded by the maxTTL. Fresh values
+// are cached only until they become stale, so the freshness of the served values is kept. Missing values are cached
+// for the negativeTTL.
+func (c *readCache) ttl(fd api.FeatureDescriptor, v *api.Value) time.Duration {
+	if v == nil {
+		return c.negativeTTL
+	}
+	ttl := min(fd.Freshness, c.maxTTL)
+	if v.Fresh && !fd.ValidWindow() {
+		ttl = min(ttl, fd.Freshness-time.Since(v.Timestamp))
+	}
+	return ttl
+}
+
+// stateGet reads the value from the State through the read-through cache. The value is shared with the concurrent
+// readers, so it must not be modified.
+func (e *engine) stateGet(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, version uint) (*api.Value, error) {
+	c := e.cache
+	if c == nil {
+		v, err := e.state.Get(ctx, storedDescriptor(fd), keys, version)
+		return withSource(v, api.ValueSourceState), err
+	}
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode keys: %w", err)
+	}
+	key := cacheKey(fd.FQN, encodedKeys, version)
+	if item := c.values.Get(key); item != nil {
+		if item.Value() == nil {
+			stats.IncrReadCacheResult(fd.FQN, stats.ReadCacheNegativeHit)
+			return nil, nil
+		}
+		stats.IncrReadCacheResult(fd.FQN, stats.ReadCacheHit)
+		return withSource(copyValue(item.Value()), api.ValueSourceCache), nil
+	}
+
+	// the read isn't canceled when the first reader is canceled, since its result is shared with the rest
+	ch := c.flight.DoChan(key, func() (any, error) {
+		start := time.Now()
+		v, err := e.state.Get(context.WithoutCancel(ctx), storedDescriptor(fd), keys, version)
+		if err != nil {
+			return nil, err
+		}
+		if item := c.invalidated.Get(key); item != nil && !item.Value().Before(start) {
+			// the value was written while it was read, so it might be outdated
+			return v, nil
+		}
+		if ttl := c.ttl(fd, v); ttl > 0 {
+			c.values.Set(key, v, ttl)
+		}
+		return v, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Shared {
+			stats.IncrReadCacheResult(fd.FQN, stats.ReadCacheShared)
+		} else {
+			stats.IncrReadCacheResult(fd.FQN, stats.ReadCacheMiss)
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		v, _ := res.Val.(*api.Value)
+		return withSource(copyValue(v), api.ValueSourceState), nil
+	}
+}
+
+func copyValue(v *api.Value) *api.Value {
+	if v == nil {
+		return nil
+	}
+	ret := *v
+	return &ret
+}
+
+// withSource marks the path that produced the value.
+func withSource(v *api.Value, src api.ValueSource) *api.Value {
+	if v != nil {
+		v.Source = src
+	}
+	return v
+}
+
+// invalidate removes the cached values (and their previous versions) of the entity, after they were written.
+func (e *engine) invalidate(fd api.FeatureDescriptor, encodedKeys string) {
+	c := e.cache
+	if c == nil {
+		return
+	}
+	versions := uint(0)
+	if fd.KeepPrevious != nil {
+		versions = fd.KeepPrevious.Versions
+	}
+	now := time.Now()
+	for v := uint(0); v <= versions; v++ {
+		key := cacheKey(fd.FQN, encodedKeys, v)
+		c.invalidated.Set(key, now, ttlcache.DefaultTTL)
+		c.values.Delete(key)
+	}
+}
+
+// invalidateFeature removes the cached values of all the entities of the feature.
+func (e *engine) invalidateFeature(fqn string) {
+	c := e.cache
+	if c == nil {
+		return
+	}
+	prefix := fqn + "\x00"
+	for _, key := range c.values.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			c.values.Delete(key)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"sort"
+
+	"github.com/raptor-ml/raptor/api"
+)
+
+// Catalog implements api.FeatureCatalog
+func (e *engine) Catalog(_ context.Context, q api.CatalogQuery) []api.CatalogEntry {
+	var ret []api.CatalogEntry
+	e.features.Range(func(_, v any) bool {
+		f := v.(*FeaturePipeliner)
+		if q.Match(f.FeatureDescriptor) {
+			ret = append(ret, api.CatalogEntryFor(f.FeatureDescriptor))
+		}
+		return true
+	})
+	sort.Slice(ret, func(i, j int) bool { return ret[i].FQN < ret[j].FQN })
+	return ret
+}
@@ -39,7 +39,26 @@ func attachCoreController(rcl reconcile.Reconciler, obj client.Object, updatesAl
 	_, err := newCoreController(rcl, obj, updatesAllowed, mgr)
 	return err
 }
+
+// attachCoreStatusController attaches a core controller that reconciles every change of the objects, including the
+// changes of their status.
+func attachCoreStatusController(rcl reconcile.Reconciler, obj client.Object, mgr manager.Manager) error {
+	_, err := newCoreControllerWithPredicates(rcl, obj, mgr)
+	return err
+}
+
 func newCoreController(rcl reconcile.Reconciler, obj client.Object, updatesAllowed bool, mgr manager.Manager) (controller.Controller, error) {
+	if updatesAllowed {
+		return newCoreControllerWithPredicates(rcl, obj, mgr, predicate.GenerationChangedPredicate{})
+	}
+	return newCoreControllerWithPredicates(rcl, obj, mgr, predicate.Funcs{
+		UpdateFunc: func(event event.UpdateEvent) bool {
+			return false
+		},
+	})
+}
+
+func newCoreControllerWithPredicates(rcl reconcile.Reconciler, obj client.Object, mgr manager.Manager, prct ...predicate.Predicate) (controller.Controller, error) {
 	basec, err := controller.NewUnmanaged("core", mgr, controller.Options{Reconciler: rcl})
 	if err != nil {
 		return nil, err
@@ -47,18 +66,9 @@ func newCoreController(rcl reconcile.Reconciler, obj client.Object, updatesAllow
 	c := &coreController{basec}
 
 	// Predicates
-	prct := []predicate.Predicate{predicate.Funcs{GenericFunc: func(genericEvent event.GenericEvent) bool {
+	prct = append([]predicate.Predicate{predicate.Funcs{GenericFunc: func(genericEvent event.GenericEvent) bool {
 		return false
-	}}}
-	if updatesAllowed {
-		prct = append(prct, predicate.GenerationChangedPredicate{})
-	} else {
-		prct = append(prct, predicate.Funcs{
-			UpdateFunc: func(event event.UpdateEvent) bool {
-				return false
-			},
-		})
-	}
+	}}}, prct...)
 	src := source.Kind(mgr.GetCache(), obj)
 	err = c.Watch(src, &handler.EnqueueRequestForObject{}, prct...)
 	if err != nil {
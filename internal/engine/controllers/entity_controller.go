@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=entities,verbs=get;list;watch
+
+import (
+	"context"
+	"github.com/raptor-ml/raptor/api"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+)
+
+// EntityReconciler reconciles an Entity object
+// This reconciler is used in every instance of the app, and not only the leader.
+// It binds the key schema of the entity to the engine, so the entity IDs of its features are validated when their
+// values are written and read.
+type EntityReconciler struct {
+	client.Reader
+	Scheme        *runtime.Scheme
+	EngineManager api.EntityManager
+}
+
+// Reconcile is the main function of the reconciler.
+func (r *EntityReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("component", "entity-controller")
+
+	entity := &manifests.Entity{}
+	err := r.Get(ctx, req.NamespacedName, entity)
+	if err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			entity.SetNamespace(req.Namespace)
+			entity.SetName(req.Name)
+			r.EngineManager.UnbindEntity(entity.FQN())
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !entity.ObjectMeta.DeletionTimestamp.IsZero() {
+		r.EngineManager.UnbindEntity(entity.FQN())
+		return ctrl.Result{}, nil
+	}
+
+	ent, err := api.EntityFromManifest(entity)
+	if err != nil {
+		// the entity is invalid, so its IDs are not validated until it's fixed
+		logger.Error(err, "Failed to parse the entity")
+		r.EngineManager.UnbindEntity(entity.FQN())
+		return ctrl.Result{}, nil
+	}
+	r.EngineManager.BindEntity(ent)
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Controller Manager.
+func (r *EntityReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the key formats only affect the validation of the IDs, so they can always be updated
+	return attachCoreController(r, &manifests.Entity{}, true, mgr)
+}
@@ -22,6 +22,7 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/tenancy"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -91,6 +92,7 @@ func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		Features:        model.Spec.Features,
 		KeyFeature:      model.Spec.KeyFeature,
 		Keys:            model.Spec.Keys,
+		Units:           model.Spec.Units,
 		ModelFramework:  model.Spec.ModelFramework,
 		ModelServer:     string(model.Spec.ModelServer),
 		InferenceConfig: cfg,
@@ -122,6 +124,17 @@ func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		}
 	}
 
+	for _, fqn := range append([]string{model.Spec.KeyFeature}, model.Spec.Features...) {
+		if fqn == "" {
+			continue
+		}
+		if err := tenancy.CheckFeatureAccess(ctx, r.Reader, model.Namespace, fqn); err != nil {
+			// the model uses a feature of another tenant, which can't be fixed by a requeue
+			logger.Error(err, "Model's feature is not accessible")
+			return ctrl.Result{}, nil
+		}
+	}
+
 	if err := r.EngineManager.BindFeature(ft); err != nil {
 		logger.Error(err, "Failed to bind Model as feature")
 		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
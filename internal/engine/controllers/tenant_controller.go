@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=tenants,verbs=get;list;watch
+
+import (
+	"context"
+	"github.com/raptor-ml/raptor/api"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+)
+
+// TenantReconciler reconciles a Tenant object
+// This reconciler is used in every instance of the app, and not only the leader.
+// It rejects the writes to the features of the tenant while the tenant exceeds its state-store memory quota, as
+// reported in its status by the operator's controller (see `internal/operator/tenant_controller.go`).
+type TenantReconciler struct {
+	client.Reader
+	Scheme        *runtime.Scheme
+	EngineManager api.StateQuotaEnforcer
+}
+
+// Reconcile is the main function of the reconciler.
+func (r *TenantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("component", "tenant-controller")
+
+	tenant := &manifests.Tenant{}
+	err := r.Get(ctx, req.NamespacedName, tenant)
+	if err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			r.EngineManager.EnforceStateQuota(req.Name, nil)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !tenant.ObjectMeta.DeletionTimestamp.IsZero() ||
+		!meta.IsStatusConditionTrue(tenant.Status.Conditions, manifests.TenantConditionQuotaExceeded) {
+		r.EngineManager.EnforceStateQuota(tenant.GetName(), nil)
+		return ctrl.Result{}, nil
+	}
+
+	var fqns []string
+	for _, ns := range tenant.Spec.Namespaces {
+		features := manifests.FeatureList{}
+		if err := r.List(ctx, &features, client.InNamespace(ns)); err != nil {
+			logger.Error(err, "Failed to list the features of the tenant")
+			return ctrl.Result{}, err
+		}
+		for _, ft := range features.Items {
+			fqns = append(fqns, ft.FQN())
+		}
+	}
+	r.EngineManager.EnforceStateQuota(tenant.GetName(), fqns)
+	logger.Info("the writes to the tenant's features are rejected, since it exceeded its state memory quota",
+		"tenant", tenant.GetName())
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Controller Manager.
+func (r *TenantReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the status is watched as well, since the quota is enforced by the usage the operator reports
+	return attachCoreStatusController(r, &manifests.Tenant{}, mgr)
+}
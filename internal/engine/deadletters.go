@@ -0,0 +1,112 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/stats"
+	"time"
+)
+
+// SendDeadLetter implements api.DeadLetterQueue. The dead-letters are counted, and dropped if no DeadLetterQueue is
+// configured. The events that failed to be computed (by the runners) are recorded by the feature's circuit breaker.
+func (e *engine) SendDeadLetter(ctx context.Context, dl api.DeadLetter) error {
+	stats.IncrDeadLetters(dl.FQN)
+	if f, ok := e.features.Load(dl.FQN); ok && dl.Notification == "" && dl.Value == nil {
+		e.guards.Record(f.(*FeaturePipeliner).FeatureDescriptor, errors.New(dl.Error))
+	}
+	if e.dlq == nil {
+		return nil
+	}
+	if dl.FailedAt.IsZero() {
+		dl.FailedAt = time.Now()
+	}
+	return e.dlq.SendDeadLetter(ctx, dl)
+}
+
+func (e *engine) deadLetterStore() (api.DeadLetterStore, error) {
+	s, ok := e.dlq.(api.DeadLetterStore)
+	if !ok {
+		return nil, fmt.Errorf("the dead-letter queue doesn't support listing dead-letters")
+	}
+	return s, nil
+}
+
+// DeadLetters implements api.DeadLetterStore by the DeadLetterQueue
+func (e *engine) DeadLetters(ctx context.Context, fqn string, limit int) ([]api.DeadLetter, error) {
+	s, err := e.deadLetterStore()
+	if err != nil {
+		return nil, err
+	}
+	return s.DeadLetters(ctx, fqn, limit)
+}
+
+// DeadLetter implements api.DeadLetterStore by the DeadLetterQueue
+func (e *engine) DeadLetter(ctx context.Context, id string) (api.DeadLetter, error) {
+	s, err := e.deadLetterStore()
+	if err != nil {
+		return api.DeadLetter{}, err
+	}
+	return s.DeadLetter(ctx, id)
+}
+
+// RemoveDeadLetter implements api.DeadLetterStore by the DeadLetterQueue
+func (e *engine) RemoveDeadLetter(ctx context.Context, id string) error {
+	s, err := e.deadLetterStore()
+	if err != nil {
+		return err
+	}
+	return s.RemoveDeadLetter(ctx, id)
+}
+
+// ReplayDeadLetter implements api.DeadLetterReplayer.
+// Values that failed to be written are written again, and events that failed to be computed are given again to the
+// feature's program.
+func (e *engine) ReplayDeadLetter(ctx context.Context, id string) error {
+	dl, err := e.DeadLetter(ctx, id)
+	if err != nil {
+		return err
+	}
+	if dl.Notification != "" {
+		return fmt.Errorf("dead-letter %s is a historian `%s` notification, which can't be replayed", id, dl.Notification)
+	}
+	fd, err := e.FeatureDescriptor(ctx, dl.FQN)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case dl.Value != nil:
+		var val any
+		val, err = api.FromJSONValue(dl.Value, fd.ValuePrimitive())
+		if err != nil {
+			return fmt.Errorf("failed to parse the value of dead-letter %s: %w", id, err)
+		}
+		err = e.Update(ctx, dl.FQN, dl.Keys, val, dl.Timestamp)
+	case fd.Builder == api.SQLBuilder || fd.Builder == api.CELBuilder:
+		return fmt.Errorf("dead-letters of `%s` features can be replayed only when their value was computed", fd.Builder)
+	default:
+		_, _, err = e.ExecuteProgram(ctx, fd.RuntimeEnv, dl.FQN, dl.Keys, dl.Row, dl.Timestamp, false)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to replay dead-letter %s: %w", id, err)
+	}
+	return e.RemoveDeadLetter(ctx, id)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/stats"
+	"time"
+)
+
+// DefaultDeduplicationHorizon is the default time an idempotency key is remembered after its write.
+const DefaultDeduplicationHorizon = 24 * time.Hour
+
+// dedup marks the idempotency key of the write (if any) for the entity, so replays of the same event (i.e. after a
+// consumer rebalance) are written only once. It returns false if the write is a duplicate of a write to the same
+// entity within the deduplication horizon, and a function that unmarks the key, so a failed write can be retried.
+func (e *engine) dedup(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, ts time.Time) (bool, func(), error) {
+	id, ok := ctx.Value(api.ContextKeyEventID).(string)
+	if !ok || id == "" || e.dedupHorizon <= 0 {
+		return true, func() {}, nil
+	}
+	d, ok := e.state.(api.Deduplicator)
+	if !ok {
+		return true, func() {}, nil
+	}
+
+	ttl := e.dedupHorizon
+	if fd.ValidWindow() {
+		// duplicates that arrive after the allowed lateness are dropped anyway, so the mark can expire by then
+		if t := time.Until(ts.Add(fd.Lateness())); t > ttl {
+			ttl = t
+		}
+	}
+	marked, err := d.MarkEvent(ctx, fd, keys, id, ttl)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to mark event %s: %w", id, err)
+	}
+	if !marked {
+		stats.IncrDuplicateEvents(fd.FQN)
+		api.LoggerFromContext(ctx).V(1).Info("dropping duplicate event", "feature", fd.FQN, "event", id)
+		return false, nil, nil
+	}
+	return true, func() {
+		// the mark is removed even if the write failed since the request was canceled (or was rolled back after it)
+		if err := d.UnmarkEvent(context.WithoutCancel(ctx), fd, keys, id); err != nil {
+			api.LoggerFromContext(ctx).Error(err, "failed to unmark event", "feature", fd.FQN, "event", id)
+		}
+	}, nil
+}
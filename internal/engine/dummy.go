@@ -25,6 +25,18 @@ import (
 type Dummy struct {
 	DataSource api.DataSource
 	api.RuntimeManager
+	// Dependencies are the dependencies of the latest program that was loaded.
+	Dependencies []string
+}
+
+// LoadProgram loads the program by the RuntimeManager, and records its dependencies.
+func (d *Dummy) LoadProgram(env, fqn, program string, packages []string) (*api.ParsedProgram, error) {
+	prog, err := d.RuntimeManager.LoadProgram(env, fqn, program, packages)
+	if err != nil {
+		return nil, err
+	}
+	d.Dependencies = prog.Dependencies
+	return prog, nil
 }
 
 func (*Dummy) FeatureDescriptor(ctx context.Context, selector string) (api.FeatureDescriptor, error) {
@@ -46,6 +58,13 @@ func (*Dummy) Update(ctx context.Context, FQN string, keys api.Keys, val any, ts
 	return nil
 }
 
+func (*Dummy) MarkEvent(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, eventID string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+func (*Dummy) UnmarkEvent(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, eventID string) error {
+	return nil
+}
+
 func (d *Dummy) GetDataSource(_ string) (api.DataSource, error) {
 	return d.DataSource, nil
 }
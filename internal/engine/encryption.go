@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/encryption"
+)
+
+// sealingAAD is the additional data of the sealed values, which binds them to the feature and to the entity.
+func sealingAAD(fd api.FeatureDescriptor, encodedKeys string) string {
+	return fd.FQN + "/" + encodedKeys
+}
+
+// storedDescriptor returns the descriptor the values of the feature are stored by. The sealed values of encrypted
+// features are stored as strings.
+func storedDescriptor(fd api.FeatureDescriptor) api.FeatureDescriptor {
+	if fd.Encryption != nil {
+		fd.Primitive = api.PrimitiveTypeString
+	}
+	return fd
+}
+
+// checkEncryption verifies that the values of an encrypted feature can be sealed.
+func (e *engine) checkEncryption(enc api.Encryption) error {
+	if e.keyring == nil {
+		return fmt.Errorf("field-level encryption is not enabled")
+	}
+	return e.keyring.Check(enc)
+}
+
+// seal encrypts a value of an encrypted feature, before it's written to the state and to the historical storage.
+func (e *engine) seal(ctx context.Context, fd api.FeatureDescriptor, encodedKeys string, val any) (string, error) {
+	if e.keyring == nil {
+		return "", fmt.Errorf("field-level encryption is not enabled")
+	}
+	sealed, err := e.keyring.Seal(ctx, fd, sealingAAD(fd, encodedKeys), val)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt the value: %w", err)
+	}
+	return string(sealed), nil
+}
+
+// unsealStored decrypts a value of an encrypted feature that was read from the state, so the pipeline works on the
+// plaintext. Values that were stored before the feature was encrypted are parsed as is.
+func (e *engine) unsealStored(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, stored any) (any, error) {
+	if stored == nil {
+		return nil, nil
+	}
+	s, ok := stored.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected stored value of type %T for an encrypted feature", stored)
+	}
+	if !encryption.IsSealed(s) {
+		return api.ScalarFromString(s, fd.Primitive)
+	}
+	v, err := e.DecryptValue(ctx, fd, keys, api.Value{Value: api.SealedValue(s)})
+	return v.Value, err
+}
+
+// sealValue seals a value that is served for an encrypted feature, so it's decrypted only by the accessor.
+func (e *engine) sealValue(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, val api.Value) (api.Value, error) {
+	if _, sealed := val.Value.(api.SealedValue); fd.Encryption == nil || val.Value == nil || sealed {
+		return val, nil
+	}
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return val, fmt.Errorf("failed to encode keys: %w", err)
+	}
+	s, err := e.seal(ctx, fd, encodedKeys, val.Value)
+	if err != nil {
+		return val, err
+	}
+	val.Value = api.SealedValue(s)
+	return val, nil
+}
+
+// DecryptValue implements api.ValueDecrypter
+func (e *engine) DecryptValue(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, val api.Value) (api.Value, error) {
+	sealed, ok := val.Value.(api.SealedValue)
+	if !ok || fd.Encryption == nil {
+		return val, nil
+	}
+	if e.keyring == nil {
+		return val, fmt.Errorf("field-level encryption is not enabled")
+	}
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return val, fmt.Errorf("failed to encode keys: %w", err)
+	}
+	v, err := e.keyring.Unseal(ctx, fd, sealingAAD(fd, encodedKeys), sealed)
+	if err != nil {
+		return val, fmt.Errorf("failed to decrypt the value of feature %s: %w", fd.FQN, err)
+	}
+	val.Value = v
+	return val, nil
+}
@@ -22,36 +22,93 @@ import (
 	"fmt"
 	"github.com/go-logr/logr"
 	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/audit"
+	"github.com/raptor-ml/raptor/internal/encryption"
 	"github.com/raptor-ml/raptor/internal/historian"
 	"github.com/raptor-ml/raptor/internal/stats"
+	"github.com/raptor-ml/raptor/internal/wal"
+	"github.com/raptor-ml/raptor/pkg/eventbus"
+	"github.com/raptor-ml/raptor/pkg/runtimemanager"
+	"github.com/raptor-ml/raptor/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"strings"
 	"sync"
 	"time"
 )
 
 type engine struct {
-	features    sync.Map
-	dataSources sync.Map
-	state       api.State
-	historian   historian.Client
-	logger      logr.Logger
+	features       sync.Map
+	bindErrors     sync.Map
+	dataSources    sync.Map
+	touches        sync.Map
+	updates        sync.Map
+	samples        samples
+	batches        batches
+	wal            *wal.Log
+	asyncDeletions asyncDeletions
+	watermarks     watermarks
+	usage          usage
+	monitor        monitor
+	quotas         quotas
+	entitySchemas  entitySchemas
+	cache          *readCache
+	state          api.State
+	historian      historian.Client
+	bus            *eventbus.Bus
+	logger         logr.Logger
+	dlq            api.DeadLetterQueue
+	audit          *audit.Logger
+	keyring        *encryption.Keyring
+	// dedupHorizon is the time an idempotency key is remembered after its write (0 to disable deduplication).
+	dedupHorizon time.Duration
+	// guards are the execution guards (timeouts and circuit breakers) of the features' programs.
+	guards runtimemanager.Guards
 	api.RuntimeManager
 }
 
-// New creates a new engine manager
-func New(state api.State, h historian.Client, rm api.RuntimeManager, logger logr.Logger) api.ManagerEngine {
+// New creates a new engine manager. Writes with an idempotency key (see api.ContextKeyEventID) are deduplicated within
+// the dedupHorizon, events that failed to be computed are sent to the dlq (nil to drop them), and the written values
+// are sampled to monitor their distribution by the vm configuration. The values that are read from the state are cached
+// by the rc configuration, and the writes of async features are logged to the w write-ahead log (nil to write them
+// synchronously). The requests of the audited namespaces are recorded by al (nil to disable auditing), and the
+// values of encrypted features are sealed by the kr (nil to disable encryption).
+func New(state api.State, h historian.Client, rm api.RuntimeManager, dlq api.DeadLetterQueue, dedupHorizon time.Duration, vm ValueMonitoring, rc ReadCache, w *wal.Log, al *audit.Logger, kr *encryption.Keyring, logger logr.Logger) api.ManagerEngine {
 	if state == nil {
 		panic("state is nil")
 	}
 	e := &engine{
 		state:          state,
 		historian:      h,
+		bus:            eventbus.New(logger.WithName("events")),
 		logger:         logger,
+		dlq:            dlq,
+		audit:          al,
+		keyring:        kr,
+		dedupHorizon:   dedupHorizon,
+		monitor:        monitor{cfg: vm},
+		cache:          newReadCache(rc),
+		wal:            w,
 		RuntimeManager: rm,
 	}
+	if a, ok := state.(api.EventBusAware); ok {
+		a.SetEventBus(e)
+	}
+	if w != nil {
+		go e.applyLog()
+	}
 	return e
 }
 
+// Subscribe implements api.EventBus
+func (e *engine) Subscribe(handler api.EventHandler, types ...api.EventType) func() {
+	return e.bus.Subscribe(handler, types...)
+}
+
+// Publish implements api.EventBus
+func (e *engine) Publish(ctx context.Context, ev api.Event) {
+	e.bus.Publish(ctx, ev)
+}
+
 func (e *engine) Append(ctx context.Context, fqn string, keys api.Keys, val any, ts time.Time) error {
 	defer stats.IncrFeatureAppends()
 	return e.write(ctx, fqn, keys, val, ts, api.StateMethodAppend)
@@ -69,25 +126,219 @@ func (e *engine) Update(ctx context.Context, fqn string, keys api.Keys, val any,
 	return e.write(ctx, fqn, keys, val, ts, api.StateMethodUpdate)
 }
 func (e *engine) write(ctx context.Context, fqn string, keys api.Keys, val any, ts time.Time, method api.StateMethod) error {
+	ctx, span := tracing.Start(ctx, "engine."+method.String(), tracing.Feature(fqn))
+	err := e.writeValue(ctx, fqn, keys, val, ts, method)
+	tracing.End(span, err)
+	return err
+}
+func (e *engine) writeValue(ctx context.Context, fqn string, keys api.Keys, val any, ts time.Time, method api.StateMethod) (err error) {
 	f, ctx, cancel, err := e.featureForRequest(ctx, fqn)
 	if err != nil {
 		return err
 	}
 	defer cancel()
+	defer func(start time.Time) {
+		record := func(err error) {
+			stats.ObserveFeatureRequest(ctx, f.FQN, method.String(), time.Since(start), err)
+			e.audit.Record(ctx, method, f.FeatureDescriptor, keys, err)
+		}
+		// the writes of a transaction are recorded once it's committed, or rolled back
+		if tx := transactionFromContext(ctx); tx != nil && err == nil {
+			tx.deferred(func() { record(nil) }, record)
+			return
+		}
+		record(err)
+	}(time.Now())
 
-	_, err = keys.Encode(f.FeatureDescriptor)
+	if f.Virtual() {
+		return fmt.Errorf("feature %s is computed on request, so it can't be written", fqn)
+	}
+	if err := e.checkQuota(f.FQN); err != nil {
+		return err
+	}
+	if keys, err = e.resolveAliases(ctx, f.FeatureDescriptor, keys); err != nil {
+		return err
+	}
+	if err := e.checkEntity(f.FeatureDescriptor, keys); err != nil {
+		return err
+	}
+
+	encodedKeys, err := keys.Encode(f.FeatureDescriptor)
 	if err != nil {
 		return fmt.Errorf("failed to encode keys: %w", err)
 	}
 
+	if f.Validations != nil {
+		var valid bool
+		if val, valid = e.validate(ctx, f.FeatureDescriptor, encodedKeys, api.Value{Value: val, Timestamp: ts}); !valid {
+			return nil
+		}
+	}
+
+	ok, unmark, err := e.dedup(ctx, f.FeatureDescriptor, keys, ts)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
 	v := api.Value{Value: val, Timestamp: ts}
+	if f.WriteSampling != nil && (method == api.StateMethodSet || method == api.StateMethodUpdate) {
+		if !e.sample(f, method, keys, encodedKeys, v) {
+			return nil
+		}
+	}
 	if _, err = e.writePipeline(f, method).Apply(ctx, keys, v); err != nil {
+		unmark()
 		return fmt.Errorf("failed to %s value for feature %s with keys %s: %w", method, fqn, keys, err)
 	}
+	historicalOnly, _ := ctx.Value(api.ContextKeyHistoricalOnly).(bool)
+	written := func() {
+		e.usage.write(f.FQN)
+		e.guards.Record(f.FeatureDescriptor, nil)
+		if (method == api.StateMethodSet || method == api.StateMethodUpdate) && f.Encryption == nil {
+			// the distribution of encrypted values isn't monitored, since it would disclose them
+			e.monitor.sample(f.FQN, val)
+		}
+		if !historicalOnly {
+			e.markUpdated(f.FQN)
+		}
+	}
+	if tx := transactionFromContext(ctx); tx != nil {
+		tx.deferred(written, func(error) { unmark() })
+		return nil
+	}
+	written()
 	return nil
 }
 
+// MarkEvent implements api.Deduplicator by the State
+func (e *engine) MarkEvent(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, eventID string, ttl time.Duration) (bool, error) {
+	d, ok := e.state.(api.Deduplicator)
+	if !ok {
+		return false, fmt.Errorf("the state provider doesn't support events deduplication")
+	}
+	return d.MarkEvent(ctx, fd, keys, eventID, ttl)
+}
+
+// UnmarkEvent implements api.Deduplicator by the State
+func (e *engine) UnmarkEvent(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, eventID string) error {
+	d, ok := e.state.(api.Deduplicator)
+	if !ok {
+		return fmt.Errorf("the state provider doesn't support events deduplication")
+	}
+	return d.UnmarkEvent(ctx, fd, keys, eventID)
+}
+
+// touchInterval is the minimal interval between two recordings of a feature read.
+const touchInterval = time.Minute
+
+// TouchFeature implements api.UsageTracker by the State
+func (e *engine) TouchFeature(ctx context.Context, fqn string, ts time.Time) error {
+	u, ok := e.state.(api.UsageTracker)
+	if !ok {
+		return fmt.Errorf("the state provider doesn't support usage tracking")
+	}
+	return u.TouchFeature(ctx, fqn, ts)
+}
+
+// FeatureLastRead implements api.UsageTracker by the State
+func (e *engine) FeatureLastRead(ctx context.Context, fqn string) (time.Time, error) {
+	u, ok := e.state.(api.UsageTracker)
+	if !ok {
+		return time.Time{}, fmt.Errorf("the state provider doesn't support usage tracking")
+	}
+	return u.FeatureLastRead(ctx, fqn)
+}
+
+// MarkFeatureUpdated implements api.FreshnessTracker by the State
+func (e *engine) MarkFeatureUpdated(ctx context.Context, fqn string, ts time.Time) error {
+	t, ok := e.state.(api.FreshnessTracker)
+	if !ok {
+		return fmt.Errorf("the state provider doesn't support freshness tracking")
+	}
+	return t.MarkFeatureUpdated(ctx, fqn, ts)
+}
+
+// FeatureLastUpdate implements api.FreshnessTracker by the State
+func (e *engine) FeatureLastUpdate(ctx context.Context, fqn string) (time.Time, error) {
+	t, ok := e.state.(api.FreshnessTracker)
+	if !ok {
+		return time.Time{}, fmt.Errorf("the state provider doesn't support freshness tracking")
+	}
+	return t.FeatureLastUpdate(ctx, fqn)
+}
+
+// PurgeFeature implements api.Purger by the State
+func (e *engine) PurgeFeature(ctx context.Context, fqn string) error {
+	p, ok := e.state.(api.Purger)
+	if !ok {
+		return fmt.Errorf("the state provider doesn't support purging")
+	}
+	defer e.invalidateFeature(fqn)
+	return p.PurgeFeature(ctx, fqn)
+}
+
+// touch records the read of the feature in the background, at most once per touchInterval.
+func (e *engine) touch(fqn string) {
+	if _, ok := e.state.(api.UsageTracker); !ok {
+		return
+	}
+	now := time.Now()
+	if last, ok := e.touches.Load(fqn); ok && now.Sub(last.(time.Time)) < touchInterval {
+		return
+	}
+	e.touches.Store(fqn, now)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := e.TouchFeature(ctx, fqn, now); err != nil {
+			e.logger.Error(err, "failed to record the feature read", "fqn", fqn)
+		}
+	}()
+}
+
+// updateInterval is the minimal interval between two recordings of a feature update.
+const updateInterval = 5 * time.Second
+
+// markUpdated records the update of the feature in the background, at most once per updateInterval.
+func (e *engine) markUpdated(fqn string) {
+	now := time.Now()
+	stats.SetFeatureLastUpdate(fqn, now)
+	if _, ok := e.state.(api.FreshnessTracker); !ok {
+		return
+	}
+	if last, ok := e.updates.Load(fqn); ok && now.Sub(last.(time.Time)) < updateInterval {
+		return
+	}
+	e.updates.Store(fqn, now)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := e.MarkFeatureUpdated(ctx, fqn, now); err != nil {
+			e.logger.Error(err, "failed to record the feature update", "fqn", fqn)
+		}
+	}()
+}
+
 func (e *engine) Get(ctx context.Context, selector string, keys api.Keys) (api.Value, api.FeatureDescriptor, error) {
+	ctx, span := tracing.Start(ctx, "engine.Get", tracing.Feature(selector))
+	start := time.Now()
+	ret, fd, err := e.get(ctx, selector, keys)
+	if fd.FQN != "" {
+		// unknown features aren't recorded, to keep the cardinality of the metrics bounded
+		stats.ObserveFeatureRequest(ctx, fd.FQN, api.StateMethodGet.String(), time.Since(start), err)
+		e.audit.Record(ctx, api.StateMethodGet, fd, keys, err)
+	}
+	span.SetAttributes(attribute.Bool("raptor.fresh", ret.Fresh), attribute.Bool("raptor.fallback", ret.Fallback),
+		attribute.String("raptor.source", string(ret.Source)))
+	tracing.End(span, err)
+	return ret, fd, err
+}
+func (e *engine) get(ctx context.Context, selector string, keys api.Keys) (api.Value, api.FeatureDescriptor, error) {
 	defer stats.IncrFeatureGets()
 
 	ret := api.Value{Timestamp: time.Now()}
@@ -96,14 +347,66 @@ func (e *engine) Get(ctx context.Context, selector string, keys api.Keys) (api.V
 		return ret, api.FeatureDescriptor{}, err
 	}
 	defer cancel()
+	e.touch(f.FQN)
+	if keys, err = e.resolveAliases(ctx, f.FeatureDescriptor, keys); err != nil {
+		return ret, f.FeatureDescriptor, err
+	}
+	if err := e.checkEntity(f.FeatureDescriptor, keys); err != nil {
+		return ret, f.FeatureDescriptor, err
+	}
 
 	ret, err = e.readPipeline(f).Apply(ctx, keys, ret)
 	if err != nil && !(goerrors.Is(err, context.DeadlineExceeded) && ret.Value != nil && !ret.Fresh) {
 		return ret, f.FeatureDescriptor, fmt.Errorf("failed to GET value for feature %s with keys %s: %w", selector, keys, err)
 	}
+	if ret.Value == nil && f.Fallback != nil && f.Fallback.Default != nil {
+		ret = api.Value{Value: f.Fallback.Default, Timestamp: time.Now(), Fallback: true}
+	}
+	switch {
+	case ret.Fallback:
+		ret.Source = api.ValueSourceFallback
+	case ret.Value != nil && ret.Source == "":
+		// values that weren't read from the state (or its cache) were computed by the builder
+		ret.Source = api.ValueSourceComputed
+	}
+	if f.Encryption != nil {
+		// the values of encrypted features are served sealed, and are decrypted by the accessor
+		if ret, err = e.sealValue(ctx, f.FeatureDescriptor, keys, ret); err != nil {
+			return ret, f.FeatureDescriptor, fmt.Errorf("failed to GET value for feature %s with keys %s: %w", selector, keys, err)
+		}
+	}
+	if ret.Fallback {
+		stats.IncrFallbacks(f.FQN)
+	}
+	e.usage.read(f.FQN, ret, f.KeepPrevious != nil && previousVersion(selector))
 	return ret, f.FeatureDescriptor, nil
 }
 
+// ExecuteProgram implements api.RuntimeManager, and records the execution time of the feature's program.
+// The programs of bound features are executed with their guards (see runtimemanager.Guards).
+func (e *engine) ExecuteProgram(ctx context.Context, env string, fqn string, keys api.Keys, row map[string]any, ts time.Time, dryRun bool) (api.Value, api.Keys, error) {
+	f, ok := e.features.Load(fqn)
+	if !ok {
+		return e.RuntimeManager.ExecuteProgram(ctx, env, fqn, keys, row, ts, dryRun)
+	}
+	fp := f.(*FeaturePipeliner)
+	start := time.Now()
+	val, keys, err := e.guards.Execute(ctx, e.RuntimeManager, fp.FeatureDescriptor, keys, row, ts, dryRun)
+	stats.ObserveBuilderExecution(ctx, fqn, fp.Builder, time.Since(start), err)
+	return val, keys, err
+}
+
+// CircuitOpen implements api.CircuitBreakerReporter
+func (e *engine) CircuitOpen(fqn string) error {
+	return e.guards.Open(fqn)
+}
+
+// previousVersion checks if the selector reads a previous version of the value.
+func previousVersion(selector string) bool {
+	_, _, _, ver, _, err := api.ParseSelector(selector)
+	return err == nil && ver > 0
+}
+
 func (e *engine) FeatureDescriptor(ctx context.Context, selector string) (api.FeatureDescriptor, error) {
 	defer stats.IncrFeatureDescriptorReqs()
 	f, _, cancel, err := e.featureForRequest(ctx, selector)
@@ -17,6 +17,7 @@ limitations under the License.
 package engine
 
 import (
+	"context"
 	"fmt"
 	"github.com/raptor-ml/raptor/api"
 	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
@@ -43,7 +44,7 @@ func FeatureWithEngine(e api.ExtendedManager, in *manifests.Feature) (*FeaturePi
 		}
 	}
 
-	if fd.Builder != "model" {
+	if fd.Builder != api.ModelBuilder && fd.Builder != api.RemoteBuilder && in.Spec.Builder.HasProgram() {
 		prog, err := e.LoadProgram(fd.RuntimeEnv, fd.FQN, in.Spec.Builder.Code, in.Spec.Builder.Packages)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load python program: %w", err)
@@ -65,18 +66,64 @@ func FeatureWithEngine(e api.ExtendedManager, in *manifests.Feature) (*FeaturePi
 }
 
 // BindFeature converts the k8s manifests.Feature CRD to the internal implementation, and adds it to the engine.
-func (e *engine) BindFeature(in *manifests.Feature) error {
+// The checksum of the Feature is verified against the checksum that was stored in its status, so replicas won't bind
+// a corrupted or partially updated spec.
+func (e *engine) BindFeature(in *manifests.Feature) (err error) {
+	defer func() {
+		if err != nil {
+			e.bindErrors.Store(in.FQN(), err)
+		} else {
+			e.bindErrors.Delete(in.FQN())
+		}
+	}()
+
+	sum, err := api.VerifyFeatureChecksum(in)
+	if err != nil {
+		return err
+	}
 	ft, err := FeatureWithEngine(e, in)
 	if err != nil {
 		return fmt.Errorf("failed to parse FeatureDescriptor from CR: %w", err)
 	}
+	ft.Checksum = sum
+	if ft.Encryption != nil {
+		if err := e.checkEncryption(*ft.Encryption); err != nil {
+			return fmt.Errorf("failed to bind encrypted feature: %w", err)
+		}
+	}
 	return e.bindFeature(ft)
 }
 
+// BindError implements api.BindErrorReporter
+func (e *engine) BindError(fqn string) error {
+	if err, ok := e.bindErrors.Load(fqn); ok {
+		return err.(error)
+	}
+	return nil
+}
+
+// FeatureChecksums implements api.ChecksumReporter
+func (e *engine) FeatureChecksums() map[string]string {
+	ret := make(map[string]string)
+	e.features.Range(func(k, v any) bool {
+		ret[k.(string)] = v.(*FeaturePipeliner).Checksum
+		return true
+	})
+	return ret
+}
+
 func (e *engine) UnbindFeature(fqn string) error {
 	defer stats.DecNumberOfFeatures()
 	e.features.Delete(fqn)
+	e.bindErrors.Delete(fqn)
+	e.usage.reset(fqn)
+	e.monitor.reset(fqn)
+	e.guards.Forget(fqn)
+	e.invalidateFeature(fqn)
+	e.flushFeatureBatch(fqn)
+	stats.DeleteFeatureRequestStats(fqn)
 	e.logger.Info("feature unbound", "feature", fqn)
+	e.Publish(context.Background(), api.FeatureUnboundEvent{FQN: fqn})
 	return nil
 }
 
@@ -86,7 +133,10 @@ func (e *engine) bindFeature(f *FeaturePipeliner) error {
 		return fmt.Errorf("%w: %s", api.ErrFeatureAlreadyExists, f.FQN)
 	}
 	e.features.Store(f.FQN, f)
+	e.usage.reset(f.FQN)
+	e.guards.Sync(f.FQN, f.Checksum)
 	e.logger.Info("feature bound", "FQN", f.FQN)
+	e.Publish(context.Background(), api.FeatureBoundEvent{FeatureDescriptor: f.FeatureDescriptor})
 	return nil
 }
 
@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"time"
+)
+
+// InspectWindow dumps the raw window buckets and the intermediate aggregation state of a windowed feature
+// for the given keys. Both the alive buckets and the dead buckets (that are kept for the historian) are included.
+func (e *engine) InspectWindow(ctx context.Context, selector string, keys api.Keys) (api.WindowInspection, error) {
+	f, ctx, cancel, err := e.featureForRequest(ctx, selector)
+	if err != nil {
+		return api.WindowInspection{}, err
+	}
+	defer cancel()
+
+	fd := f.FeatureDescriptor
+	if !fd.ValidWindow() {
+		return api.WindowInspection{}, fmt.Errorf("feature %s is not a windowed feature", fd.FQN)
+	}
+	if fd.SessionWindow() {
+		return api.WindowInspection{}, fmt.Errorf("feature %s is a session window, which has no buckets to inspect", fd.FQN)
+	}
+	if fd.DecayWindow() {
+		return api.WindowInspection{}, fmt.Errorf("feature %s is a decayed window, which has no buckets to inspect", fd.FQN)
+	}
+	if fd.TopKWindow() {
+		return api.WindowInspection{}, fmt.Errorf("feature %s is a top-k window, which keeps sketches rather than aggregations", fd.FQN)
+	}
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return api.WindowInspection{}, fmt.Errorf("failed to encode keys: %w", err)
+	}
+
+	alive := fd.AliveWindowBuckets()
+	dead := fd.DeadWindowBuckets()
+	raw, err := e.state.WindowBuckets(ctx, fd, keys, append(append([]string{}, alive...), dead...))
+	if err != nil {
+		return api.WindowInspection{}, fmt.Errorf("failed to fetch window buckets: %w", err)
+	}
+	found := make(map[string]api.WindowResultMap, len(raw))
+	for _, b := range raw {
+		found[b.Bucket] = b.Data
+	}
+
+	ret := api.WindowInspection{
+		FQN:         fd.FQN,
+		Keys:        keys,
+		EncodedKeys: encodedKeys,
+		BucketSize:  fd.Freshness.String(),
+		Staleness:   fd.Staleness.String(),
+		InspectedAt: time.Now(),
+	}
+	if fd.WindowSlide > 0 {
+		ret.Slide = fd.WindowSlide.String()
+	}
+	if wm, ok := e.Watermark(fd.DataSource); ok {
+		ret.Watermark = &wm
+	}
+	for _, fn := range fd.Aggr {
+		ret.Aggr = append(ret.Aggr, fn.String())
+	}
+
+	// buckets are listed from the newest to the oldest, which is the order they are merged in
+	cumulative := make(api.WindowResultMap)
+	inspect := func(name string, isAlive bool) {
+		start := api.BucketTime(name, fd.Freshness)
+		bi := api.WindowBucketInspection{
+			Name:   name,
+			Start:  start,
+			End:    start.Add(fd.Freshness),
+			DeadAt: fd.BucketDeadTime(name),
+			Alive:  isAlive,
+		}
+		if data, ok := found[name]; ok {
+			bi.Found = true
+			bi.Raw = data.Readable()
+			if isAlive {
+				api.MergeWindowResults(cumulative, data, fd.Aggr)
+			}
+		}
+		if isAlive {
+			bi.Cumulative = cumulative.Readable()
+		}
+		ret.Buckets = append(ret.Buckets, bi)
+	}
+	for _, name := range alive {
+		inspect(name, true)
+	}
+	for _, name := range dead {
+		inspect(name, false)
+	}
+	ret.Result = cumulative.Readable()
+
+	// the distinct count and the percentiles can't be merged from the buckets, so they are taken from the window's
+	// value, which is calculated by the state from the merge of the buckets' sketches
+	if err := e.sketchedResults(ctx, fd, keys, ret.Result); err != nil {
+		return api.WindowInspection{}, err
+	}
+
+	return ret, nil
+}
+
+// sketchedResults sets the aggregations that are calculated from the buckets' sketches (rather than from their
+// results) from the window's value of the state.
+func (e *engine) sketchedResults(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, result map[string]float64) error {
+	var fns []api.AggrFn
+	for _, fn := range fd.Aggr {
+		if _, ok := fn.Quantile(); ok || fn == api.AggrFnCountDistinct {
+			fns = append(fns, fn)
+		}
+	}
+	if len(fns) == 0 {
+		return nil
+	}
+
+	v, err := e.state.Get(ctx, fd, keys, 0)
+	if err != nil {
+		return fmt.Errorf("failed to get the window's value: %w", err)
+	}
+	if v == nil {
+		return nil
+	}
+	wrm, ok := v.Value.(api.WindowResultMap)
+	if !ok {
+		return nil
+	}
+	for _, fn := range fns {
+		if n, ok := wrm[fn]; ok {
+			result[fn.String()] = n
+		}
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/stats"
 	"time"
 )
 
@@ -51,34 +52,58 @@ func (e *engine) getValueMiddleware() api.Middleware {
 				}
 			}
 
-			v, err := e.state.Get(ctx, fd, keys, ver)
+			v, err := e.stateGet(ctx, fd, keys, ver)
 			if err != nil {
 				return val, err
 			}
 
 			if v == nil {
+				stats.IncrCacheResult(fd.FQN, stats.CacheMiss)
 				return next(ctx, fd, keys, val)
 			}
+			if fd.Encryption != nil {
+				if v.Value, err = e.unsealStored(ctx, fd, keys, v.Value); err != nil {
+					return val, err
+				}
+			}
 			if time.Now().Add(-fd.Staleness).After(v.Timestamp) {
 				// Ignore expired values.
-				return next(ctx, fd, keys, val)
+				stats.IncrCacheResult(fd.FQN, stats.CacheExpired)
+				e.usage.expire(fd.FQN)
+				if fd.Fallback == nil || time.Now().Add(-fd.ValueTTL()).After(v.Timestamp) {
+					return next(ctx, fd, keys, val)
+				}
+
+				// the last known value is returned only if a fresh value can't be computed
+				ret, err := next(ctx, fd, keys, val)
+				if err != nil || ret.Value != nil {
+					return ret, err
+				}
+				v.Value = fd.TimestampNormalization.Normalize(v.Value)
+				v.Fresh = false
+				v.Fallback = true
+				return *v, nil
 			}
 
+			stats.IncrCacheResult(fd.FQN, stats.CacheHit)
+
 			// Mark the context as from cache.
 			ctx = context.WithValue(ctx, api.ContextKeyFromCache, v.Value != nil)
 			ctx = context.WithValue(ctx, api.ContextKeyCacheFresh, v.Fresh)
 
-			if fd.ValidWindow() && af != api.AggrFnUnknown {
+			if fd.ValidWindow() && !fd.TopKWindow() && af != api.AggrFnUnknown {
 				val = api.Value{
 					Value:     api.ToLowLevelValue[api.WindowResultMap](v.Value)[af],
 					Timestamp: v.Timestamp,
 					Fresh:     v.Fresh,
+					Source:    v.Source,
 				}
 				return next(ctx, fd, keys, val)
 			}
 
 			// modify the value to the result from the state
 			val = *v
+			val.Value = fd.TimestampNormalization.Normalize(val.Value)
 
 			return next(ctx, fd, keys, val)
 		}
@@ -122,6 +147,13 @@ func (e *engine) cachePostGetMiddleware(f *FeaturePipeliner) api.Middleware {
 }
 
 func (e *engine) readPipeline(f *FeaturePipeliner) Pipeline {
+	if f.Virtual() {
+		// virtual features are computed on every request, so the state is neither read nor updated
+		return Pipeline{
+			Middlewares:       append(f.preGet.Middlewares(), f.postGet.Middlewares()...),
+			FeatureDescriptor: f.FeatureDescriptor,
+		}
+	}
 	return Pipeline{
 		Middlewares:       append(append(f.preGet.Middlewares(), e.getValueMiddleware()), append(f.postGet.Middlewares(), e.cachePostGetMiddleware(f))...),
 		FeatureDescriptor: f.FeatureDescriptor,
@@ -141,45 +173,144 @@ func (e *engine) setMiddleware(method api.StateMethod) api.Middleware {
 				return next(ctx, fd, keys, val)
 			}
 
-			if api.TypeDetect(val.Value) != fd.Primitive {
+			if api.TypeDetect(val.Value) != fd.ValuePrimitive() {
 				return val, fmt.Errorf("value mismatch: got value with a different type than the feature type")
 			}
+			val.Value = fd.TimestampNormalization.Normalize(val.Value)
+
+			// processing-time features are timestamped, and therefore bucketed, by the time they're written, regardless
+			// of the event time that was reported by the DataSource or the program
+			if p := e.timestampPolicy(fd); p != nil && p.ProcessingTime {
+				val.Timestamp = time.Now()
+			}
 
 			encodedKeys, err := keys.Encode(fd)
 			if err != nil {
 				return val, fmt.Errorf("failed to encode keys: %v", err)
 			}
 
-			// (retrospective write): when the value is expired, only write it to the historical storage
-			if !fd.ValidWindow() && val.Timestamp.Before(time.Now().Add(-fd.Staleness)) {
-				e.historian.AddWriteNotification(fd.FQN, encodedKeys, "", &val)
+			// events that arrive after the allowed lateness are dropped, since their bucket might have been collected
+			// and expired already. Late events within the allowed lateness are collected again, which corrects the
+			// bucket's record in the historical storage.
+			if fd.ValidWindow() && e.late(ctx, fd, encodedKeys, val) {
+				return val, nil
+			}
+
+			historicalOnly, _ := ctx.Value(api.ContextKeyHistoricalOnly).(bool)
+			if historicalOnly && fd.ValidWindow() {
+				// windows are recorded in the historical storage by collecting their buckets from the online store
+				return val, fmt.Errorf("windowed features can't be written only to the historical storage")
+			}
+
+			// the values of encrypted features are sealed before they're written to the state and to the historical
+			// storage, and they're stored as strings
+			stored, sfd := val, fd
+			if fd.Encryption != nil {
+				if method != api.StateMethodSet && method != api.StateMethodUpdate {
+					return val, fmt.Errorf("the values of encrypted features can only be set or updated")
+				}
+				if stored.Value, err = e.seal(ctx, fd, encodedKeys, val.Value); err != nil {
+					return val, err
+				}
+				sfd = storedDescriptor(fd)
+			}
+
+			// (retrospective write): when the value is expired, or the write is historical-only (i.e. of a backfill),
+			// only write it to the historical storage
+			if !fd.ValidWindow() && (historicalOnly || val.Timestamp.Before(time.Now().Add(-fd.Staleness))) {
+				if !fd.SkipHistorical {
+					e.historian.AddWriteNotification(fd.FQN, encodedKeys, "", &stored)
+				}
 				return next(ctx, fd, keys, val)
 			}
 
-			switch method {
-			case api.StateMethodSet:
-				err = e.state.Set(ctx, fd, keys, val.Value, val.Timestamp)
-			case api.StateMethodAppend:
-				err = e.state.Append(ctx, fd, keys, val.Value, val.Timestamp)
-			case api.StateMethodIncr:
-				err = e.state.Incr(ctx, fd, keys, val.Value, val.Timestamp)
-			case api.StateMethodUpdate:
-				err = e.state.Update(ctx, fd, keys, val.Value, val.Timestamp)
-			case api.StateMethodWindowAdd:
-				err = e.state.WindowAdd(ctx, fd, keys, val.Value, val.Timestamp)
+			// the writes of a transaction are applied to the state once all of them are prepared (see WriteTransaction)
+			tx := transactionFromContext(ctx)
+			cond := api.WriteConditionFromContext(ctx)
+			switch {
+			case tx != nil && cond != nil:
+				err = fmt.Errorf("conditional writes can't be part of a transaction")
+			case tx != nil:
+				err = tx.add(sfd, method, keys, encodedKeys, stored, func() { e.written(fd, encodedKeys, val, stored) })
+			case cond != nil:
+				err = e.conditionalWrite(ctx, sfd, method, keys, stored, *cond)
+			case e.asyncWritable(sfd, method):
+				err = e.asyncWrite(sfd, method, keys, stored)
+			case e.batchable(sfd, method):
+				err = e.batchWrite(ctx, sfd, method, keys, encodedKeys, stored)
+			case method == api.StateMethodSet:
+				err = e.state.Set(ctx, sfd, keys, stored.Value, stored.Timestamp)
+			case method == api.StateMethodAppend:
+				err = e.state.Append(ctx, sfd, keys, stored.Value, stored.Timestamp)
+			case method == api.StateMethodIncr:
+				err = e.state.Incr(ctx, sfd, keys, stored.Value, stored.Timestamp)
+			case method == api.StateMethodUpdate:
+				err = e.state.Update(ctx, sfd, keys, stored.Value, stored.Timestamp)
+			case method == api.StateMethodWindowAdd:
+				err = e.state.WindowAdd(ctx, sfd, keys, stored.Value, stored.Timestamp)
 			}
 			if err != nil {
 				return val, err
 			}
-
-			if fd.ValidWindow() {
-				bucket := api.BucketName(val.Timestamp, fd.Freshness)
-				e.historian.AddCollectNotification(fd.FQN, encodedKeys, bucket)
-			} else {
-				e.historian.AddWriteNotification(fd.FQN, encodedKeys, "", &val)
+			if tx == nil {
+				e.written(fd, encodedKeys, val, stored)
 			}
-
 			return next(ctx, fd, keys, val)
 		}
 	}
 }
+
+// written invalidates the cached value of the entity after it was written to the State, and records the write in the
+// historical storage.
+func (e *engine) written(fd api.FeatureDescriptor, encodedKeys string, val, stored api.Value) {
+	e.invalidate(fd, encodedKeys)
+
+	if fd.SkipHistorical || (fd.ValidWindow() && !fd.BucketedWindow()) {
+		// session and decayed windows are kept per entity rather than in buckets, and top-K windows are kept as
+		// sketches, so they can't be collected
+		return
+	}
+	if fd.ValidWindow() {
+		bucket := api.BucketName(val.Timestamp, fd.Freshness)
+		e.historian.AddCollectNotification(fd.FQN, encodedKeys, bucket)
+	} else {
+		e.historian.AddWriteNotification(fd.FQN, encodedKeys, "", &stored)
+	}
+}
+
+// conditionalWrite sets the value only if the condition is met by the stored value (see api.WithWriteCondition).
+// Conditional writes are written directly to the State, since they must be applied in order.
+func (e *engine) conditionalWrite(ctx context.Context, fd api.FeatureDescriptor, method api.StateMethod, keys api.Keys, val api.Value, cond api.WriteCondition) error {
+	if fd.ValidWindow() {
+		return fmt.Errorf("conditional writes aren't supported for windowed features")
+	}
+	if method != api.StateMethodSet && (method != api.StateMethodUpdate || !fd.Primitive.Scalar()) {
+		return fmt.Errorf("conditional writes are only supported for Set, and for Update of scalar features")
+	}
+	if cond.Expected != nil && fd.Encryption != nil {
+		return fmt.Errorf("the stored values of encrypted features can't be compared")
+	}
+	cw, ok := e.state.(api.ConditionalWriter)
+	if !ok {
+		return fmt.Errorf("the state provider doesn't support conditional writes")
+	}
+	applied, err := cw.SetIf(ctx, fd, keys, val.Value, val.Timestamp, cond)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return api.ErrWriteConditionFailed
+	}
+	return nil
+}
+
+// timestampPolicy returns the timestamp policy of the feature, which defaults to the policy of its DataSource.
+func (e *engine) timestampPolicy(fd api.FeatureDescriptor) *api.TimestampPolicy {
+	if fd.TimestampPolicy != nil {
+		return fd.TimestampPolicy
+	}
+	if src, err := e.GetDataSource(fd.DataSource); err == nil {
+		return src.TimestampPolicy
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DeleteEntity implements api.EntityDeleter by the State
+// Virtual features have no stored values, and features that skip the historical storage get no tombstones. The
+// pending sampled, batched and async writes of the entity are dropped, so they're not written afterwards.
+func (e *engine) DeleteEntity(ctx context.Context, entityType, entityID string) (api.EntityDeletion, error) {
+	ret := api.EntityDeletion{EntityType: entityType, EntityID: entityID, Features: make(map[string]int)}
+	if entityType == "" || entityID == "" {
+		return ret, fmt.Errorf("the entity type and ID are required")
+	}
+	d, ok := e.state.(api.EntityValuesDeleter)
+	if !ok {
+		return ret, fmt.Errorf("the state provider doesn't support deleting entities")
+	}
+
+	var fps []*FeaturePipeliner
+	e.features.Range(func(_, v any) bool {
+		f := v.(*FeaturePipeliner)
+		for _, k := range f.Keys {
+			if k == entityType && !f.Virtual() {
+				fps = append(fps, f)
+				break
+			}
+		}
+		return true
+	})
+	sort.Slice(fps, func(i, j int) bool { return fps[i].FQN < fps[j].FQN })
+
+	keys := api.Keys{entityType: entityID}
+	for _, f := range fps {
+		e.dropSamples(f.FQN, entityType, entityID)
+		e.dropBatchedWrites(f.FQN, entityType, entityID)
+		e.dropAsyncWrites(f.FQN, entityType, entityID)
+		removed, err := d.DeleteEntityValues(ctx, storedDescriptor(f.FeatureDescriptor), entityType, entityID)
+		e.audit.Record(ctx, api.StateMethodDelete, f.FeatureDescriptor, keys, err)
+		if err != nil {
+			return ret, fmt.Errorf("failed to delete the values of feature %s: %w", f.FQN, err)
+		}
+		if len(removed) == 0 {
+			continue
+		}
+		ret.Features[f.FQN] = len(removed)
+		for _, encodedKeys := range removed {
+			e.invalidate(f.FeatureDescriptor, encodedKeys)
+		}
+
+		if f.SkipHistorical {
+			continue
+		}
+		now := time.Now()
+		for _, encodedKeys := range removed {
+			e.historian.AddTombstoneNotification(f.FQN, encodedKeys, now)
+		}
+	}
+	e.logger.Info("deleted the values of an entity", "entityType", entityType, "features", len(ret.Features))
+	return ret, nil
+}
+
+// dropSamples drops the pending sampled writes of the entity, so they're not written at the end of their interval.
+func (e *engine) dropSamples(fqn, entityType, entityID string) {
+	e.samples.mu.Lock()
+	defer e.samples.mu.Unlock()
+	for id, sw := range e.samples.writes {
+		if strings.HasPrefix(id, fqn+":") && sw.keys[entityType] == entityID {
+			sw.pending = nil
+		}
+	}
+}
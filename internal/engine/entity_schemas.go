@@ -0,0 +1,73 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"github.com/raptor-ml/raptor/api"
+	"sync"
+)
+
+// entitySchemas holds the key schemas of the entities, to validate the IDs of the features that are keyed by them.
+type entitySchemas struct {
+	mu      sync.RWMutex
+	schemas map[string]*api.Entity
+}
+
+func (s *entitySchemas) set(ent *api.Entity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.schemas == nil {
+		s.schemas = make(map[string]*api.Entity)
+	}
+	s.schemas[ent.FQN] = ent
+}
+
+func (s *entitySchemas) delete(fqn string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.schemas, fqn)
+}
+
+func (s *entitySchemas) get(fqn string) (*api.Entity, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ent, ok := s.schemas[fqn]
+	return ent, ok
+}
+
+// BindEntity implements api.EntityManager
+func (e *engine) BindEntity(ent *api.Entity) {
+	e.entitySchemas.set(ent)
+}
+
+// UnbindEntity implements api.EntityManager
+func (e *engine) UnbindEntity(fqn string) {
+	e.entitySchemas.delete(fqn)
+}
+
+// checkEntity rejects the keys whose IDs don't match the formats of the keys of the feature's entity.
+// Features without an entity, or whose entity isn't bound (yet), are not validated.
+func (e *engine) checkEntity(fd api.FeatureDescriptor, keys api.Keys) error {
+	if fd.Entity == "" {
+		return nil
+	}
+	ent, ok := e.entitySchemas.get(fd.Entity)
+	if !ok {
+		return nil
+	}
+	return ent.ValidateKeys(keys)
+}
@@ -27,6 +27,8 @@ import (
 // FeaturePipeliner is a Core's engine feature abstraction. It contains the FD and the pipelines.
 type FeaturePipeliner struct {
 	api.FeatureDescriptor
+	// Checksum is the content hash of the Feature that was bound (see api.FeatureChecksum).
+	Checksum string
 
 	preGet  mws
 	postGet mws
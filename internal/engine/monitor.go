@@ -0,0 +1,325 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/stats"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// monitorReservoirSize is the number of numeric samples that are kept per window to estimate the distribution.
+	monitorReservoirSize = 1024
+	// monitorMaxCategories is the number of distinct non-numeric values that are counted per window. The rest are
+	// counted as monitorOtherCategory.
+	monitorMaxCategories = 50
+	monitorOtherCategory = "__other__"
+	// monitorPublishEvery is the number of samples between two updates of the Prometheus metrics of a feature.
+	monitorPublishEvery = 100
+	// monitorDriftBins is the number of (quantile) bins that numeric distributions are compared by.
+	monitorDriftBins = 10
+)
+
+// ValueMonitoring configures the sampling of the written values, to monitor their distribution and detect drifts.
+type ValueMonitoring struct {
+	// SampleRate is the ratio (0-1) of the written values that are sampled. Monitoring is disabled when 0.
+	SampleRate float64
+	// Window is the duration of the distributions that are compared to detect a drift.
+	Window time.Duration
+}
+
+// monitor keeps the distribution of the sampled values of every feature, in the current window and in the previous
+// one. The distributions are kept per instance, so each instance monitors the values it writes.
+type monitor struct {
+	cfg ValueMonitoring
+	m   sync.Map
+}
+
+type valueWindow struct {
+	start      time.Time
+	count      uint64
+	nulls      uint64
+	numeric    uint64
+	mean, m2   float64
+	min, max   float64
+	samples    []float64
+	categories map[string]uint64
+}
+
+type featureMonitor struct {
+	mu       sync.Mutex
+	current  *valueWindow
+	previous *valueWindow
+	sampled  uint64
+}
+
+func newValueWindow(start time.Time) *valueWindow {
+	return &valueWindow{start: start, categories: make(map[string]uint64)}
+}
+
+func (m *monitor) enabled() bool {
+	return m.cfg.SampleRate > 0 && m.cfg.Window > 0
+}
+
+// sample records the written value of the feature, by the configured sample rate.
+func (m *monitor) sample(fqn string, val any) {
+	if !m.enabled() || rand.Float64() >= m.cfg.SampleRate {
+		return
+	}
+	v, ok := m.m.Load(fqn)
+	if !ok {
+		v, _ = m.m.LoadOrStore(fqn, &featureMonitor{current: newValueWindow(time.Now())})
+	}
+	fm := v.(*featureMonitor)
+
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	now := time.Now()
+	if now.Sub(fm.current.start) >= m.cfg.Window {
+		fm.previous = fm.current
+		fm.current = newValueWindow(now)
+	}
+	fm.current.add(val)
+	fm.sampled++
+	if fm.sampled%monitorPublishEvery == 0 {
+		publishValueStats(fm.stats(fqn))
+	}
+}
+
+func (m *monitor) reset(fqn string) {
+	m.m.Delete(fqn)
+	stats.DeleteValueStats(fqn)
+}
+
+func (w *valueWindow) add(val any) {
+	w.count++
+	if val == nil {
+		w.nulls++
+		return
+	}
+
+	var f float64
+	switch v := val.(type) {
+	case int:
+		f = float64(v)
+	case int32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case float32:
+		f = float64(v)
+	case float64:
+		f = v
+	default:
+		c := category(val)
+		if _, ok := w.categories[c]; !ok && len(w.categories) >= monitorMaxCategories {
+			c = monitorOtherCategory
+		}
+		w.categories[c]++
+		return
+	}
+
+	// Welford's online mean and variance
+	w.numeric++
+	d := f - w.mean
+	w.mean += d / float64(w.numeric)
+	w.m2 += d * (f - w.mean)
+	if w.numeric == 1 || f < w.min {
+		w.min = f
+	}
+	if w.numeric == 1 || f > w.max {
+		w.max = f
+	}
+
+	// reservoir sampling keeps a uniform sample of the window's values
+	if len(w.samples) < monitorReservoirSize {
+		w.samples = append(w.samples, f)
+	} else if i := rand.Int63n(int64(w.numeric)); i < monitorReservoirSize {
+		w.samples[i] = f
+	}
+}
+
+func category(val any) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func (w *valueWindow) distribution() api.ValueDistribution {
+	ret := api.ValueDistribution{
+		From:  w.start,
+		Count: w.count,
+		Nulls: w.nulls,
+	}
+	if w.numeric > 0 {
+		ret.Mean = w.mean
+		ret.StdDev = math.Sqrt(w.m2 / float64(w.numeric))
+		ret.Min = w.min
+		ret.Max = w.max
+
+		sorted := w.sorted()
+		ret.Quantiles = map[string]float64{
+			"p1":  quantile(sorted, 0.01),
+			"p50": quantile(sorted, 0.5),
+			"p90": quantile(sorted, 0.9),
+			"p99": quantile(sorted, 0.99),
+		}
+	}
+	if len(w.categories) > 0 {
+		ret.Categories = make(map[string]uint64, len(w.categories))
+		for k, v := range w.categories {
+			ret.Categories[k] = v
+		}
+	}
+	return ret
+}
+
+func (w *valueWindow) sorted() []float64 {
+	ret := make([]float64, len(w.samples))
+	copy(ret, w.samples)
+	sort.Float64s(ret)
+	return ret
+}
+
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(q*float64(len(sorted)-1))]
+}
+
+// drift calculates the Population Stability Index (PSI) of the current window relative to the previous one.
+// Numeric values are binned by the quantiles of the previous window, and the rest by their value.
+func drift(prev, cur *valueWindow) (float64, bool) {
+	if len(prev.samples) >= monitorDriftBins && len(cur.samples) > 0 {
+		ps := prev.sorted()
+		edges := make([]float64, 0, monitorDriftBins-1)
+		for i := 1; i < monitorDriftBins; i++ {
+			edges = append(edges, quantile(ps, float64(i)/monitorDriftBins))
+		}
+		return psi(histogram(ps, edges), histogram(cur.samples, edges)), true
+	}
+	if len(prev.categories) > 0 && len(cur.categories) > 0 {
+		keys := make(map[string]int)
+		for k := range prev.categories {
+			keys[k] = len(keys)
+		}
+		for k := range cur.categories {
+			if _, ok := keys[k]; !ok {
+				keys[k] = len(keys)
+			}
+		}
+		p := make([]float64, len(keys))
+		c := make([]float64, len(keys))
+		for k, n := range prev.categories {
+			p[keys[k]] = float64(n)
+		}
+		for k, n := range cur.categories {
+			c[keys[k]] = float64(n)
+		}
+		return psi(p, c), true
+	}
+	return 0, false
+}
+
+func histogram(values []float64, edges []float64) []float64 {
+	ret := make([]float64, len(edges)+1)
+	for _, v := range values {
+		ret[sort.SearchFloat64s(edges, v)]++
+	}
+	return ret
+}
+
+func psi(prev, cur []float64) float64 {
+	// empty bins are smoothed, so the index stays finite
+	const eps = 1e-4
+	var pt, ct float64
+	for i := range prev {
+		pt += prev[i]
+		ct += cur[i]
+	}
+	var ret float64
+	for i := range prev {
+		p := math.Max(prev[i]/pt, eps)
+		c := math.Max(cur[i]/ct, eps)
+		ret += (c - p) * math.Log(c/p)
+	}
+	return ret
+}
+
+func (fm *featureMonitor) stats(fqn string) api.ValueStats {
+	ret := api.ValueStats{
+		FQN:     fqn,
+		Current: fm.current.distribution(),
+	}
+	if fm.previous != nil {
+		prev := fm.previous.distribution()
+		ret.Previous = &prev
+		if d, ok := drift(fm.previous, fm.current); ok {
+			ret.Drift = &d
+		}
+	}
+	return ret
+}
+
+func publishValueStats(vs api.ValueStats) {
+	d := vs.Current
+	var nullRatio float64
+	if d.Count > 0 {
+		nullRatio = float64(d.Nulls) / float64(d.Count)
+	}
+	stats.SetValueStats(vs.FQN, d.Mean, d.StdDev, nullRatio, d.Quantiles, vs.Drift)
+}
+
+// ValueStats implements api.ValueMonitor
+func (e *engine) ValueStats(_ context.Context, fqn string) ([]api.ValueStats, error) {
+	if !e.monitor.enabled() {
+		return nil, fmt.Errorf("value monitoring is disabled")
+	}
+	if fqn != "" && !e.HasFeature(fqn) {
+		return nil, fmt.Errorf("%w: %s", api.ErrFeatureNotFound, fqn)
+	}
+
+	var ret []api.ValueStats
+	e.monitor.m.Range(func(k, v any) bool {
+		if fqn != "" && k.(string) != fqn {
+			return true
+		}
+		fm := v.(*featureMonitor)
+		fm.mu.Lock()
+		ret = append(ret, fm.stats(k.(string)))
+		fm.mu.Unlock()
+		return true
+	})
+	sort.Slice(ret, func(i, j int) bool { return ret[i].FQN < ret[j].FQN })
+	return ret, nil
+}
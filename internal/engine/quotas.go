@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/stats"
+	"sync"
+)
+
+// quotas tracks the features whose writes are rejected, since their tenant exceeded its state-store memory quota.
+type quotas struct {
+	mu      sync.RWMutex
+	tenants map[string][]string
+	blocked map[string]string
+}
+
+func (q *quotas) set(tenant string, fqns []string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.blocked == nil {
+		q.tenants = make(map[string][]string)
+		q.blocked = make(map[string]string)
+	}
+	for _, fqn := range q.tenants[tenant] {
+		delete(q.blocked, fqn)
+	}
+	delete(q.tenants, tenant)
+	if len(fqns) == 0 {
+		return
+	}
+	q.tenants[tenant] = fqns
+	for _, fqn := range fqns {
+		q.blocked[fqn] = tenant
+	}
+}
+
+// exceeded returns the tenant of the feature if its writes are rejected.
+func (q *quotas) exceeded(fqn string) (string, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	tenant, ok := q.blocked[fqn]
+	return tenant, ok
+}
+
+// EnforceStateQuota implements api.StateQuotaEnforcer
+func (e *engine) EnforceStateQuota(tenant string, fqns []string) {
+	e.quotas.set(tenant, fqns)
+}
+
+// checkQuota rejects the writes to the features of tenants that exceeded their state-store memory quota.
+func (e *engine) checkQuota(fqn string) error {
+	tenant, exceeded := e.quotas.exceeded(fqn)
+	if !exceeded {
+		return nil
+	}
+	stats.IncrTenantQuotaRejections(tenant, stats.QuotaStateMemory)
+	return fmt.Errorf("%w: tenant %s exceeded its state memory quota, so feature %s can't be written", api.ErrQuotaExceeded, tenant, fqn)
+}
+
+// FeatureMemoryUsage implements api.MemoryUsageEstimator by the State
+func (e *engine) FeatureMemoryUsage(ctx context.Context, fqn string) (int64, error) {
+	m, ok := e.state.(api.MemoryUsageEstimator)
+	if !ok {
+		return 0, fmt.Errorf("the state provider doesn't support estimating the memory usage")
+	}
+	return m.FeatureMemoryUsage(ctx, fqn)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"github.com/raptor-ml/raptor/api"
+	"sync"
+	"time"
+)
+
+// sampledWrite is the write-sampling state of a feature-value of an entity during a sampling interval.
+type sampledWrite struct {
+	f       *FeaturePipeliner
+	method  api.StateMethod
+	keys    api.Keys
+	pending *api.Value
+}
+
+// samples holds the write-sampling state of the entities that were written during the last sampling interval.
+type samples struct {
+	mu     sync.Mutex
+	writes map[string]*sampledWrite
+}
+
+// sample applies the feature's write sampling, and reports whether the value should be written now.
+// The first value of an interval is always written. The following values of the interval are either dropped, or kept
+// as pending, so the latest of them is written at the end of the interval.
+func (e *engine) sample(f *FeaturePipeliner, method api.StateMethod, keys api.Keys, encodedKeys string, val api.Value) bool {
+	id := f.FQN + ":" + encodedKeys
+
+	e.samples.mu.Lock()
+	defer e.samples.mu.Unlock()
+
+	if e.samples.writes == nil {
+		e.samples.writes = make(map[string]*sampledWrite)
+	}
+	if sw, ok := e.samples.writes[id]; ok {
+		if !f.WriteSampling.KeepFirst {
+			sw.method = method
+			sw.keys = keys
+			sw.pending = &val
+		}
+		return false
+	}
+
+	e.samples.writes[id] = &sampledWrite{f: f, method: method, keys: keys}
+	time.AfterFunc(f.WriteSampling.Interval, func() { e.flushSample(id) })
+	return true
+}
+
+// flushSample ends the sampling interval of an entity, and writes its pending value. Writing a pending value starts
+// a new interval.
+func (e *engine) flushSample(id string) {
+	e.samples.mu.Lock()
+	sw, ok := e.samples.writes[id]
+	if !ok {
+		e.samples.mu.Unlock()
+		return
+	}
+	val := sw.pending
+	if val == nil {
+		delete(e.samples.writes, id)
+		e.samples.mu.Unlock()
+		return
+	}
+	sw.pending = nil
+	time.AfterFunc(sw.f.WriteSampling.Interval, func() { e.flushSample(id) })
+	e.samples.mu.Unlock()
+
+	ctx := context.Background()
+	cancel := func() {}
+	if sw.f.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, sw.f.Timeout)
+	}
+	defer cancel()
+	ctx = context.WithValue(ctx, api.ContextKeyLogger, e.logger)
+	if _, err := e.writePipeline(sw.f, sw.method).Apply(api.ContextWithSelector(ctx, sw.f.FQN), sw.keys, *val); err != nil {
+		e.logger.Error(err, "failed to write a sampled value", "feature", sw.f.FQN, "keys", sw.keys)
+	}
+}
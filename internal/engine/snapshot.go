@@ -0,0 +1,51 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+)
+
+// SnapshotFeature implements api.StateSnapshotter by the State
+func (e *engine) SnapshotFeature(ctx context.Context, fqn string, fn func(api.StateSnapshotRecord) error) (int, error) {
+	s, ok := e.state.(api.StateSnapshotter)
+	if !ok {
+		return 0, fmt.Errorf("the state provider doesn't support snapshots")
+	}
+	return s.SnapshotFeature(ctx, fqn, fn)
+}
+
+// RestoreSnapshot implements api.StateSnapshotter by the State
+// The cached values of the restored features are invalidated, so they're read from the State again.
+func (e *engine) RestoreSnapshot(ctx context.Context, records []api.StateSnapshotRecord) error {
+	s, ok := e.state.(api.StateSnapshotter)
+	if !ok {
+		return fmt.Errorf("the state provider doesn't support snapshots")
+	}
+	fqns := make(map[string]struct{})
+	for _, rec := range records {
+		fqns[rec.FQN] = struct{}{}
+	}
+	defer func() {
+		for fqn := range fqns {
+			e.invalidateFeature(fqn)
+		}
+	}()
+	return s.RestoreSnapshot(ctx, records)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"strings"
+	"sync"
+)
+
+// transaction collects the writes that were prepared by the write pipelines of their features, so they're applied to
+// the State at once.
+type transaction struct {
+	mu         sync.Mutex
+	entity     string
+	writes     []api.StateWrite
+	onCommit   []func()
+	onRollback []func(error)
+}
+
+type transactionContextKey struct{}
+
+func transactionFromContext(ctx context.Context) *transaction {
+	tx, _ := ctx.Value(transactionContextKey{}).(*transaction)
+	return tx
+}
+
+// add adds a prepared write to the transaction. The committed func is called once the transaction is committed.
+// The writes of a transaction must be of the same entity, since only the values of an entity are stored together
+// (i.e. in the same shard and Redis Cluster slot).
+func (tx *transaction) add(fd api.FeatureDescriptor, method api.StateMethod, keys api.Keys, encodedKeys string, val api.Value, committed func()) error {
+	if fd.ValidWindow() {
+		if !fd.BucketedWindow() {
+			return fmt.Errorf("session, decayed and top-K windows can't be written in transactions")
+		}
+		if method != api.StateMethodWindowAdd && method != api.StateMethodUpdate && method != api.StateMethodSet {
+			return fmt.Errorf("windowed features can only be added to in transactions")
+		}
+		method = api.StateMethodWindowAdd
+	} else {
+		method = updateMethod(fd, method)
+		if method == api.StateMethodWindowAdd {
+			return fmt.Errorf("cannot add to a window of a non-windowed feature")
+		}
+	}
+
+	entity := strings.Join(fd.Keys, ",") + "=" + encodedKeys
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.entity == "" {
+		tx.entity = entity
+	} else if tx.entity != entity {
+		return fmt.Errorf("%w: %s is written for another entity", api.ErrTransactionSpansEntities, fd.FQN)
+	}
+	tx.writes = append(tx.writes, api.StateWrite{FeatureDescriptor: fd, Method: method, Keys: keys, Value: val.Value, Timestamp: val.Timestamp})
+	tx.onCommit = append(tx.onCommit, committed)
+	return nil
+}
+
+// deferred registers funcs that are called when the transaction is committed, or rolled back with the error that
+// failed it.
+func (tx *transaction) deferred(committed func(), rolledBack func(error)) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.onCommit = append(tx.onCommit, committed)
+	tx.onRollback = append(tx.onRollback, rolledBack)
+}
+
+func (tx *transaction) done(err error) {
+	if err != nil {
+		for _, fn := range tx.onRollback {
+			fn(err)
+		}
+		return
+	}
+	for _, fn := range tx.onCommit {
+		fn()
+	}
+}
+
+// WriteTransaction implements api.Transactor by the State
+// The writes are prepared by the write pipelines of their features (i.e. validated, deduplicated and sealed), and the
+// prepared writes are applied to the State at once. Writes that are dropped by their pipeline (i.e. duplicates or
+// invalid values) are not part of the transaction.
+func (e *engine) WriteTransaction(ctx context.Context, writes []api.FeatureWrite) (err error) {
+	ctx, span := tracing.Start(ctx, "engine.WriteTransaction", attribute.Int("raptor.writes", len(writes)))
+	defer func() { tracing.End(span, err) }()
+
+	tw, ok := e.state.(api.TransactionalWriter)
+	if !ok {
+		return fmt.Errorf("%w by the state provider", api.ErrTransactionsUnsupported)
+	}
+
+	tx := &transaction{}
+	tctx := context.WithValue(ctx, transactionContextKey{}, tx)
+	for _, w := range writes {
+		wctx := tctx
+		if w.EventID != "" {
+			wctx = context.WithValue(tctx, api.ContextKeyEventID, w.EventID)
+		}
+		if err := e.write(wctx, w.FQN, w.Keys, w.Value, w.Timestamp, w.Method); err != nil {
+			tx.done(fmt.Errorf("the transaction was rolled back: %w", err))
+			return err
+		}
+	}
+	if len(tx.writes) > 0 {
+		if err := tw.WriteTransaction(ctx, tx.writes); err != nil {
+			err = fmt.Errorf("failed to write the transaction: %w", err)
+			tx.done(err)
+			return err
+		}
+	}
+	tx.done(nil)
+	return nil
+}
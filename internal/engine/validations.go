@@ -0,0 +1,64 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/stats"
+)
+
+// validate evaluates the validations of the feature on the value, and returns the value to write, or false if it
+// should be dropped. Violations are counted and published as api.ValidationViolatedEvent.
+func (e *engine) validate(ctx context.Context, fd api.FeatureDescriptor, encodedKeys string, val api.Value) (any, bool) {
+	clamped, violations := fd.Validations.Validate(val.Value)
+	if len(violations) == 0 {
+		return val.Value, true
+	}
+
+	action := fd.Validations.OnViolation
+	if action == api.ViolationActionClamp {
+		for _, r := range violations {
+			if r != api.ValidationRuleMin && r != api.ValidationRuleMax {
+				// only numeric values can be fixed by clamping
+				action = api.ViolationActionDrop
+				break
+			}
+		}
+	}
+
+	for _, r := range violations {
+		stats.IncrValidationViolations(fd.FQN, string(r), string(action))
+	}
+	api.LoggerFromContext(ctx).V(1).Info("invalid value", "feature", fd.FQN, "violations", violations, "action", action)
+	e.Publish(ctx, api.ValidationViolatedEvent{
+		FQN:         fd.FQN,
+		EncodedKeys: encodedKeys,
+		Value:       val,
+		Violations:  violations,
+		Action:      action,
+	})
+
+	switch action {
+	case api.ViolationActionWarn:
+		return val.Value, true
+	case api.ViolationActionClamp:
+		return clamped, true
+	default:
+		return nil, false
+	}
+}
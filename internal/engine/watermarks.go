@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/stats"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// watermarks tracks the watermark (the latest event time) of every DataSource, by the events that are written to its
+// windowed features. The watermarks are kept per instance, so each instance tracks the events it handles.
+type watermarks struct {
+	m sync.Map
+}
+
+// advance moves the watermark of the DataSource forward to the given time, and returns the current watermark.
+// The watermark never advances beyond the current time, so events from the future (i.e. by a skewed clock) don't
+// make the rest of the events late.
+func (w *watermarks) advance(dataSource string, ts time.Time) time.Time {
+	v, _ := w.m.LoadOrStore(dataSource, &atomic.Int64{})
+	wm := v.(*atomic.Int64)
+	if now := time.Now(); ts.After(now) {
+		ts = now
+	}
+	n := ts.UnixNano()
+	for {
+		cur := wm.Load()
+		if n <= cur {
+			return time.Unix(0, cur)
+		}
+		if wm.CompareAndSwap(cur, n) {
+			return ts
+		}
+	}
+}
+
+func (w *watermarks) get(dataSource string) (time.Time, bool) {
+	v, ok := w.m.Load(dataSource)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, v.(*atomic.Int64).Load()), true
+}
+
+// Watermark implements api.WatermarkReader
+func (e *engine) Watermark(dataSource string) (time.Time, bool) {
+	return e.watermarks.get(dataSource)
+}
+
+// late advances the watermark of the feature's DataSource by the event, and checks if the event arrived after the
+// allowed lateness. Late events are counted and published as api.LateEventDroppedEvent.
+func (e *engine) late(ctx context.Context, fd api.FeatureDescriptor, encodedKeys string, val api.Value) bool {
+	wm := e.watermarks.advance(fd.DataSource, val.Timestamp)
+	if !val.Timestamp.Before(wm.Add(-fd.Lateness())) {
+		return false
+	}
+
+	stats.IncrLateEvents(fd.FQN)
+	api.LoggerFromContext(ctx).V(1).Info("dropping late event", "feature", fd.FQN, "timestamp", val.Timestamp, "watermark", wm)
+	e.Publish(ctx, api.LateEventDroppedEvent{
+		FQN:         fd.FQN,
+		EncodedKeys: encodedKeys,
+		Value:       val,
+		Watermark:   wm,
+	})
+	return true
+}
@@ -0,0 +1,139 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package historian
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	"github.com/raptor-ml/raptor/api"
+	"sync"
+	"time"
+)
+
+// MaxNotificationBatchWindow caps the latency that batching adds to the notifications.
+const MaxNotificationBatchWindow = time.Second
+
+// DefaultNotificationBatchSize is the default number of notifications that are emitted together.
+const DefaultNotificationBatchSize = 100
+
+// batcher accumulates the notifications of each feature, and emits them together to reduce the notifier's operations
+// at high ingest rates. A batch is emitted when it's full, or after the window has passed since its first notification.
+type batcher[T api.Notification] struct {
+	window time.Duration
+	size   int
+	key    func(T) string
+	emit   func(context.Context, []T) error
+	failed func(T)
+	logger logr.Logger
+
+	mu      sync.Mutex
+	pending map[string][]T
+}
+
+func newBatcher[T api.Notification](window time.Duration, size int, key func(T) string, notifier api.Notifier[T], failed func(T), logger logr.Logger) *batcher[T] {
+	if window <= 0 {
+		return nil
+	}
+	if window > MaxNotificationBatchWindow {
+		window = MaxNotificationBatchWindow
+	}
+	if size <= 0 {
+		size = DefaultNotificationBatchSize
+	}
+	return &batcher[T]{
+		window:  window,
+		size:    size,
+		key:     key,
+		emit:    batchNotify(notifier),
+		failed:  failed,
+		logger:  logger,
+		pending: make(map[string][]T),
+	}
+}
+
+// batchNotify returns a function that sends a batch of notifications, using a single operation if the notifier
+// supports it.
+func batchNotify[T api.Notification](notifier api.Notifier[T]) func(context.Context, []T) error {
+	if bn, ok := notifier.(api.BatchNotifier[T]); ok {
+		return bn.NotifyBatch
+	}
+	return func(ctx context.Context, notifications []T) error {
+		for _, n := range notifications {
+			if err := notifier.Notify(ctx, n); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Add adds a notification to the batch of its feature.
+func (b *batcher[T]) Add(ctx context.Context, notification T) {
+	k := b.key(notification)
+
+	b.mu.Lock()
+	items := append(b.pending[k], notification)
+	if len(items) < b.size {
+		b.pending[k] = items
+		if len(items) == 1 {
+			// a timer of a batch that was emitted when it was full might flush the next batch earlier, which is fine
+			time.AfterFunc(b.window, func() { b.flush(context.Background(), k) })
+		}
+		b.mu.Unlock()
+		return
+	}
+	delete(b.pending, k)
+	b.mu.Unlock()
+
+	b.send(ctx, items)
+}
+
+func (b *batcher[T]) flush(ctx context.Context, key string) {
+	b.mu.Lock()
+	items := b.pending[key]
+	delete(b.pending, key)
+	b.mu.Unlock()
+
+	if len(items) > 0 {
+		b.send(ctx, items)
+	}
+}
+
+// FlushAll emits all the pending batches. It's used to avoid losing notifications on shutdown.
+func (b *batcher[T]) FlushAll(ctx context.Context) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = make(map[string][]T)
+	b.mu.Unlock()
+
+	for _, items := range pending {
+		b.send(ctx, items)
+	}
+}
+
+func (b *batcher[T]) send(ctx context.Context, items []T) {
+	if err := b.emit(ctx, items); err != nil {
+		b.logger.Error(err, "Failed to send a batch of notifications. Requeuing items...", "size", len(items))
+		for _, n := range items {
+			b.failed(n)
+		}
+	}
+}
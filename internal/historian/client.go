@@ -22,6 +22,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/raptor-ml/raptor/api"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"time"
 )
 
 type (
@@ -32,6 +33,9 @@ type (
 		// AddWriteNotification adds a notification to the writer
 		AddWriteNotification(fqn, encodedKeys, bucket string, value *api.Value)
 
+		// AddTombstoneNotification adds a notification of the deletion of the entity's values to the writer
+		AddTombstoneNotification(fqn, encodedKeys string, ts time.Time)
+
 		// CollectNotifier is a runnable that notifies the collector of a new collection task
 		CollectNotifier() NoLeaderRunnableFunc
 
@@ -47,12 +51,19 @@ type ClientConfig struct {
 	WriteNotifier              api.Notifier[api.WriteNotification]
 	CollectNotificationWorkers int
 	WriteNotificationWorkers   int
-	Logger                     logr.Logger
+	// NotificationBatchWindow is the time to accumulate the notifications of a feature before sending them together
+	// (0 to send every notification on its own). It's capped by MaxNotificationBatchWindow.
+	NotificationBatchWindow time.Duration
+	// NotificationBatchSize is the maximal number of notifications to send together.
+	NotificationBatchSize int
+	Logger                logr.Logger
 }
 type client struct {
 	ClientConfig
 	pendingWrite    queue[api.WriteNotification]
 	pendingCollects queue[api.CollectNotification]
+	writeBatches    *batcher[api.WriteNotification]
+	collectBatches  *batcher[api.CollectNotification]
 }
 
 func NewClient(config ClientConfig) Client {
@@ -67,6 +78,12 @@ func NewClient(config ClientConfig) Client {
 	}
 	c.pendingWrite = newQueue[api.WriteNotification](c.Logger.WithName("pendingWrite"), c.queueWrite)
 	c.pendingCollects = newQueue[api.CollectNotification](c.Logger.WithName("pendingCollect"), c.queueCollect)
+	c.writeBatches = newBatcher(config.NotificationBatchWindow, config.NotificationBatchSize,
+		func(n api.WriteNotification) string { return n.FQN }, config.WriteNotifier,
+		func(n api.WriteNotification) { c.pendingWrite.AddRateLimited(n) }, c.Logger.WithName("writeBatches"))
+	c.collectBatches = newBatcher(config.NotificationBatchWindow, config.NotificationBatchSize,
+		func(n api.CollectNotification) string { return n.FQN }, config.CollectNotifier,
+		func(n api.CollectNotification) { c.pendingCollects.AddRateLimited(n) }, c.Logger.WithName("collectBatches"))
 	return c
 }
 
@@ -90,11 +107,28 @@ func (c *client) AddWriteNotification(fqn, encodedKeys, bucket string, value *ap
 	})
 }
 
+func (c *client) AddTombstoneNotification(fqn, encodedKeys string, ts time.Time) {
+	c.pendingWrite.Add(api.WriteNotification{
+		FQN:         fqn,
+		EncodedKeys: encodedKeys,
+		Value:       &api.Value{Timestamp: ts},
+		Tombstone:   true,
+	})
+}
+
 func (c *client) CollectNotifier() NoLeaderRunnableFunc {
-	return c.pendingCollects.Runnable(c.CollectNotificationWorkers)
+	run := c.pendingCollects.Runnable(c.CollectNotificationWorkers)
+	return func(ctx context.Context) error {
+		defer c.collectBatches.FlushAll(context.Background())
+		return run(ctx)
+	}
 }
 func (c *client) WriteNotifier() NoLeaderRunnableFunc {
-	return c.pendingWrite.Runnable(c.WriteNotificationWorkers)
+	run := c.pendingWrite.Runnable(c.WriteNotificationWorkers)
+	return func(ctx context.Context) error {
+		defer c.writeBatches.FlushAll(context.Background())
+		return run(ctx)
+	}
 }
 
 func (c *client) WithManager(manager manager.Manager) error {
@@ -109,10 +143,18 @@ func (c *client) WithManager(manager manager.Manager) error {
 
 // send write notifications to the external queue
 func (c *client) queueWrite(ctx context.Context, notification api.WriteNotification) error {
+	if c.writeBatches != nil {
+		c.writeBatches.Add(ctx, notification)
+		return nil
+	}
 	return c.ClientConfig.WriteNotifier.Notify(ctx, notification)
 }
 
 // send collect notifications to the external queue
 func (c *client) queueCollect(ctx context.Context, notification api.CollectNotification) error {
+	if c.collectBatches != nil {
+		c.collectBatches.Add(ctx, notification)
+		return nil
+	}
 	return c.ClientConfig.CollectNotifier.Notify(ctx, notification)
 }
@@ -19,22 +19,14 @@ package historian
 import (
 	"context"
 	"fmt"
-	"github.com/jellydator/ttlcache/v3"
 	"github.com/raptor-ml/raptor/api"
 	"strings"
 )
 
 func (h *historian) Collector() LeaderRunnableFunc {
 	return func(ctx context.Context) error {
-		if h.handledBuckets == nil {
-			h.handledBuckets = ttlcache.New[string, struct{}](ttlcache.WithDisableTouchOnHit[string, struct{}]())
-			go h.handledBuckets.Start()
-			go func(ctx context.Context) {
-				<-ctx.Done()
-				h.handledBuckets.Stop()
-			}(ctx)
-		}
-
+		go h.handledBuckets.Start()
+		defer h.handledBuckets.Stop()
 		return h.collectTasks.Runnable(ctx)
 	}
 }
@@ -60,7 +52,7 @@ func (h *historian) dispatchCollect(ctx context.Context, notification api.Collec
 	if err != nil {
 		return fmt.Errorf("failed to get state for %s: %w", notification.FQN, err)
 	}
-	h.writeTasks.queue.Add(api.WriteNotification{
+	h.writeTasks.add(api.WriteNotification{
 		FQN:         notification.FQN,
 		EncodedKeys: notification.EncodedKeys,
 		Value:       v,
@@ -77,7 +69,7 @@ func (h *historian) dispatchCollectWithWindow(ctx context.Context, notification
 		return h.dispatchCollectDead(ctx, fd)
 	}
 
-	deadBuckets := api.DeadWindowBuckets(fd.Staleness, fd.Freshness)
+	deadBuckets := fd.DeadWindowBuckets()
 
 	keys := api.Keys{}
 	err := keys.Decode(notification.EncodedKeys, fd)
@@ -91,7 +83,7 @@ func (h *historian) dispatchCollectWithWindow(ctx context.Context, notification
 	}
 	for _, b := range buckets {
 		activeBucket := !contains(deadBuckets, b.Bucket)
-		h.writeTasks.queue.Add(api.WriteNotification{
+		h.writeTasks.add(api.WriteNotification{
 			FQN:         b.FQN,
 			EncodedKeys: b.EncodedKeys,
 			Value: &api.Value{
@@ -101,6 +93,9 @@ func (h *historian) dispatchCollectWithWindow(ctx context.Context, notification
 			Bucket:       b.Bucket,
 			ActiveBucket: activeBucket,
 		})
+		if !activeBucket {
+			h.publishFinalized(ctx, b)
+		}
 	}
 	return nil
 }
@@ -123,7 +118,7 @@ func (h *historian) dispatchCollectDead(ctx context.Context, fd api.FeatureDescr
 	}
 
 	for _, b := range buckets {
-		h.writeTasks.queue.Add(api.WriteNotification{
+		h.writeTasks.add(api.WriteNotification{
 			FQN:         b.FQN,
 			EncodedKeys: b.EncodedKeys,
 			Value: &api.Value{
@@ -133,6 +128,7 @@ func (h *historian) dispatchCollectDead(ctx context.Context, fd api.FeatureDescr
 			Bucket:       b.Bucket,
 			ActiveBucket: false,
 		})
+		h.publishFinalized(ctx, b)
 	}
 
 	// Add the next dead collection to the queue
@@ -144,6 +140,18 @@ func (h *historian) dispatchCollectDead(ctx context.Context, fd api.FeatureDescr
 	return nil
 }
 
+func (h *historian) publishFinalized(ctx context.Context, b api.RawBucket) {
+	if h.EventBus == nil {
+		return
+	}
+	h.EventBus.Publish(ctx, api.WindowFinalizedEvent{
+		FQN:         b.FQN,
+		Bucket:      b.Bucket,
+		EncodedKeys: b.EncodedKeys,
+		Data:        b.Data,
+	})
+}
+
 func contains(s []string, i string) bool {
 	for _, a := range s {
 		if a == i {
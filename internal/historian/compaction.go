@@ -0,0 +1,51 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package historian
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"time"
+)
+
+// Compactor compacts the output of the HistoricalWriter every CompactionInterval. It runs by the historian's leader,
+// so the replicas don't merge the same files.
+func (h *historian) Compactor() LeaderRunnableFunc {
+	return func(ctx context.Context) error {
+		hc, ok := h.HistoricalWriter.(api.HistoricalCompactor)
+		if !ok {
+			return fmt.Errorf("the historical writer doesn't support compaction")
+		}
+
+		ticker := time.NewTicker(h.CompactionInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				start := time.Now()
+				if err := hc.Compact(ctx); err != nil {
+					h.Logger.Error(err, "failed to compact the historical data")
+					continue
+				}
+				h.Logger.V(1).Info("compacted the historical data", "duration", time.Since(start))
+			}
+		}
+	}
+}
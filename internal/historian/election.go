@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package historian
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;create;update
+
+const (
+	DefaultElectionLeaseDuration = 15 * time.Second
+	DefaultElectionRenewDeadline = 10 * time.Second
+	DefaultElectionRetryPeriod   = 2 * time.Second
+)
+
+// Election configures the leader election of the notification consumers. The election is held over a Lease, so a
+// standby replica takes over the notifications once the Lease of the leader expires (or right away, when the leader
+// releases it on shutdown).
+type Election struct {
+	// Config is the configuration of the Kubernetes API client.
+	Config *rest.Config
+	// Namespace is the namespace of the Lease.
+	Namespace string
+	// Name is the name of the Lease.
+	Name string
+	// Identity is the identity of this replica (i.e. its pod name).
+	Identity string
+
+	// LeaseDuration is the time the standby replicas wait before they take over a Lease that wasn't renewed.
+	LeaseDuration time.Duration
+	// RenewDeadline is the time the leader retries to renew the Lease before it steps down.
+	RenewDeadline time.Duration
+	// RetryPeriod is the time between the attempts to acquire or renew the Lease.
+	RetryPeriod time.Duration
+}
+
+func (e *Election) defaults() {
+	if e.LeaseDuration <= 0 {
+		e.LeaseDuration = DefaultElectionLeaseDuration
+	}
+	if e.RenewDeadline <= 0 {
+		e.RenewDeadline = DefaultElectionRenewDeadline
+	}
+	if e.RetryPeriod <= 0 {
+		e.RetryPeriod = DefaultElectionRetryPeriod
+	}
+}
+
+func (h *historian) ElectedConsumers() NoLeaderRunnableFunc {
+	return func(ctx context.Context) error {
+		e := *h.Election
+		e.defaults()
+		lock, err := resourcelock.NewFromKubeconfig(resourcelock.LeasesResourceLock, e.Namespace, e.Name,
+			resourcelock.ResourceLockConfig{Identity: e.Identity}, e.Config, e.RenewDeadline)
+		if err != nil {
+			return fmt.Errorf("failed to create the election lock: %w", err)
+		}
+
+		go h.handledBuckets.Start()
+		defer h.handledBuckets.Stop()
+		defer h.collectTasks.queue.ShutDownWithDrain()
+		defer h.writeTasks.queue.ShutDownWithDrain()
+
+		logger := h.Logger.WithValues("lease", e.Name, "identity", e.Identity)
+		// the elector returns once the leadership is lost, so this replica stands by for the next term
+		for ctx.Err() == nil {
+			var leading atomic.Bool
+			le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+				Lock:            lock,
+				Name:            e.Name,
+				LeaseDuration:   e.LeaseDuration,
+				RenewDeadline:   e.RenewDeadline,
+				RetryPeriod:     e.RetryPeriod,
+				ReleaseOnCancel: true,
+				Callbacks: leaderelection.LeaderCallbacks{
+					OnStartedLeading: func(ctx context.Context) {
+						logger.Info("Started leading the notification consumers")
+						leading.Store(true)
+						notificationsLeader.Set(1)
+						h.consume(ctx)
+					},
+					// it's called whenever the elector returns, even if this replica didn't lead
+					OnStoppedLeading: func() {
+						if !leading.Load() {
+							return
+						}
+						logger.Info("Stopped leading the notification consumers")
+						notificationsLeader.Set(0)
+						leadershipLost.Inc()
+					},
+					OnNewLeader: func(identity string) {
+						if identity != e.Identity {
+							logger.Info("The notification consumers are led by another replica", "leader", identity)
+						}
+					},
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create the leader elector: %w", err)
+			}
+			le.Run(ctx)
+		}
+		return nil
+	}
+}
+
+// consume handles the notifications until the leadership is lost.
+func (h *historian) consume(ctx context.Context) {
+	// the notifications that were published while no replica was leading were lost, so the dead buckets of the
+	// windowed features are collected again
+	h.fds.Range(func(_, v any) bool {
+		if fd := v.(api.FeatureDescriptor); fd.ValidWindow() {
+			h.collectTasks.add(api.CollectNotification{FQN: fd.FQN, Bucket: DeadRequestMarker})
+		}
+		return true
+	})
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		h.collectTasks.consume(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		h.writeTasks.consume(ctx)
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,67 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package historian
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"time"
+)
+
+// EntityCollectionPeriod is the time between two collections of inactive entities.
+const EntityCollectionPeriod = 6 * time.Hour
+
+// EntityCollector removes the values of the entities that were inactive for longer than the EntityHorizon.
+// It runs by the historian's leader, so it's coordinated with the historical storage: the collected values were
+// written before the horizon, long after the historian recorded them, and windows are not collected since their
+// buckets are recorded (and expire) by the historian.
+func (h *historian) EntityCollector() LeaderRunnableFunc {
+	return func(ctx context.Context) error {
+		ec, ok := h.State.(api.EntityCollector)
+		if !ok {
+			return fmt.Errorf("the state provider doesn't support entities collection")
+		}
+		if h.EntityHorizon < SyncPeriod {
+			return fmt.Errorf("the entity horizon (%s) must be longer than the sync period (%s)", h.EntityHorizon, SyncPeriod)
+		}
+
+		ticker := time.NewTicker(EntityCollectionPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				h.collectEntities(ctx, ec)
+			}
+		}
+	}
+}
+
+func (h *historian) collectEntities(ctx context.Context, ec api.EntityCollector) {
+	h.fds.Range(func(_, v any) bool {
+		fd := v.(api.FeatureDescriptor)
+		n, err := ec.CollectInactiveEntities(ctx, fd, h.EntityHorizon)
+		if err != nil {
+			h.Logger.Error(err, "failed to collect inactive entities", "feature", fd.FQN)
+		} else if n > 0 {
+			h.Logger.Info("collected inactive entities", "feature", fd.FQN, "entities", n)
+		}
+		return ctx.Err() == nil
+	})
+}
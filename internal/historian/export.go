@@ -0,0 +1,95 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ExportRequest is a request to export the historical records of a Feature or a Model.
+type ExportRequest struct {
+	FQN   string    `json:"fqn"`
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	// Destination is the S3 URL to write the records to. The format is detected by its extension (`.csv`, `.json`
+	// or `.parquet`).
+	Destination string `json:"destination"`
+}
+
+func (r ExportRequest) validate() error {
+	if r.FQN == "" {
+		return fmt.Errorf("`fqn` is required")
+	}
+	if r.Since.IsZero() || r.Until.IsZero() || !r.Since.Before(r.Until) {
+		return fmt.Errorf("`since` must be before `until`")
+	}
+	if !strings.HasPrefix(r.Destination, "s3://") {
+		return fmt.Errorf("`destination` must be an S3 URL")
+	}
+	return nil
+}
+
+// Exporter is a runnable that serves the export endpoint (`POST /export`) of the HistoricalWriter, when it's an
+// api.HistoricalExporter.
+func (h *historian) Exporter() NoLeaderRunnableFunc {
+	return func(ctx context.Context) error {
+		exporter, ok := h.HistoricalWriter.(api.HistoricalExporter)
+		if !ok {
+			return fmt.Errorf("the historical writer doesn't support exporting")
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/export", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			req := ExportRequest{}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("failed to decode request: %v", err), http.StatusBadRequest)
+				return
+			}
+			if err := req.validate(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if err := exporter.Export(r.Context(), req.FQN, req.Since, req.Until, req.Destination); err != nil {
+				h.Logger.Error(err, "failed to export", "fqn", req.FQN, "destination", req.Destination)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		srv := http.Server{Handler: mux, Addr: h.ExportAddr}
+		go func() {
+			<-ctx.Done()
+			_ = srv.Shutdown(context.TODO())
+		}()
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	}
+}
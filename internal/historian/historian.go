@@ -50,6 +50,19 @@ type Server interface {
 	// Writer is a runnable that writes data to the Historical Data Storage
 	Writer() LeaderRunnableFunc
 
+	// ElectedConsumers is a runnable that consumes the notifications (as the Collector and the Writer) while this
+	// replica leads the Election
+	ElectedConsumers() NoLeaderRunnableFunc
+
+	// EntityCollector is a runnable that removes the values of inactive entities from the state
+	EntityCollector() LeaderRunnableFunc
+
+	// Exporter is a runnable that serves the export endpoint of the Historical Data Storage
+	Exporter() NoLeaderRunnableFunc
+
+	// Compactor is a runnable that compacts the output of the Historical Data Storage periodically
+	Compactor() LeaderRunnableFunc
+
 	// WithManager adds all the Runnables (Collector, Writer) to the manager
 	WithManager(manager manager.Manager) error
 }
@@ -69,24 +82,79 @@ type ServerConfig struct {
 
 	State            api.State
 	HistoricalWriter api.HistoricalWriter
+
+	// EventBus is optional. When it's set, an api.WindowFinalizedEvent is published for every dead bucket that is
+	// collected.
+	EventBus api.EventBus
+
+	// EntityHorizon is optional. When it's set (and the State is an api.EntityCollector), the values of entities
+	// that were inactive for longer than EntityHorizon are removed from the State periodically.
+	EntityHorizon time.Duration
+
+	// ExportAddr is optional. When it's set (and the HistoricalWriter is an api.HistoricalExporter), the export
+	// endpoint is served on this address.
+	ExportAddr string
+
+	// CompactionInterval is optional. When it's set (and the HistoricalWriter is an api.HistoricalCompactor), the
+	// output of the HistoricalWriter is compacted every CompactionInterval.
+	CompactionInterval time.Duration
+
+	// Election is optional. When it's set, the notifications are consumed only by the replica that leads the election
+	// (rather than by the leader of the manager), so the replicas don't process the same notifications twice.
+	Election *Election
+
+	// RetryPolicy is optional. It configures the retries of the notifications that failed to be handled. When it's
+	// nil, DefaultRetryPolicy is used.
+	RetryPolicy *RetryPolicy
+
+	// DeadLetterQueue is optional. When it's set, the notifications that exhausted their retries are handed over to it
+	// rather than dropped.
+	DeadLetterQueue api.DeadLetterQueue
 }
 
 func NewServer(config ServerConfig) Server {
 	h := &historian{
-		ServerConfig: config,
+		ServerConfig:   config,
+		handledBuckets: ttlcache.New[string, struct{}](ttlcache.WithDisableTouchOnHit[string, struct{}]()),
 	}
-	h.collectTasks = newSubscriptionQueue[api.CollectNotification](h.CollectNotifier, h.Logger.WithName("collectTasks"), h.dispatchCollect)
-	h.writeTasks = newSubscriptionQueue[api.WriteNotification](h.WriteNotifier, h.Logger.WithName("dispatchWrite"), h.dispatchWrite)
+	if h.RetryPolicy == nil {
+		h.RetryPolicy = &DefaultRetryPolicy
+	}
+	h.collectTasks = newSubscriptionQueue[api.CollectNotification]("collect", h.CollectNotifier, h.Logger.WithName("collectTasks"), h.dispatchCollect,
+		*h.RetryPolicy, deadLetter[api.CollectNotification](h, "collect"))
+	h.writeTasks = newSubscriptionQueue[api.WriteNotification]("write", h.WriteNotifier, h.Logger.WithName("dispatchWrite"), h.dispatchWrite,
+		*h.RetryPolicy, deadLetter[api.WriteNotification](h, "write"))
 	h.writeTasks.finalizer = h.finalizeWrite
 	return h
 }
 
 func (h *historian) WithManager(manager manager.Manager) error {
-	if err := manager.Add(h.Collector()); err != nil {
-		return err
+	if h.Election != nil {
+		if err := manager.Add(h.ElectedConsumers()); err != nil {
+			return err
+		}
+	} else {
+		if err := manager.Add(h.Collector()); err != nil {
+			return err
+		}
+		if err := manager.Add(h.Writer()); err != nil {
+			return err
+		}
 	}
-	if err := manager.Add(h.Writer()); err != nil {
-		return err
+	if _, ok := h.State.(api.EntityCollector); ok && h.EntityHorizon > 0 {
+		if err := manager.Add(h.EntityCollector()); err != nil {
+			return err
+		}
+	}
+	if _, ok := h.HistoricalWriter.(api.HistoricalExporter); ok && h.ExportAddr != "" {
+		if err := manager.Add(h.Exporter()); err != nil {
+			return err
+		}
+	}
+	if _, ok := h.HistoricalWriter.(api.HistoricalCompactor); ok && h.CompactionInterval > 0 {
+		if err := manager.Add(h.Compactor()); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -100,6 +168,11 @@ func (h *historian) BindFeature(in *manifests.Feature) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse FeatureDescriptor from CR: %w", err)
 	}
+	if fd.SkipHistorical || (fd.ValidWindow() && !fd.BucketedWindow()) {
+		// Features that are excluded from the historical storage (i.e. in sandbox namespaces), and windows that are
+		// not kept in buckets (session, decayed and top-K windows) are not recorded
+		return nil
+	}
 
 	var model *manifests.ModelSpec
 	if fd.Builder == api.ModelBuilder {
@@ -118,7 +191,7 @@ func (h *historian) BindFeature(in *manifests.Feature) error {
 	}
 
 	if fd.ValidWindow() {
-		h.collectTasks.queue.Add(api.CollectNotification{
+		h.collectTasks.add(api.CollectNotification{
 			FQN:    fd.FQN,
 			Bucket: DeadRequestMarker,
 		})
@@ -0,0 +1,75 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package historian
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const historianSubsystemKey = "historian"
+
+var (
+	notificationsLeader = prometheus.NewGauge(prometheus.GaugeOpts{
+		Subsystem: historianSubsystemKey,
+		Name:      "notifications_leader",
+		Help:      "Whether this replica leads the election of the notification consumers (1) or stands by (0).",
+	})
+	leadershipLost = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: historianSubsystemKey,
+		Name:      "notifications_leadership_lost_total",
+		Help:      "Number of times this replica lost the leadership of the notification consumers.",
+	})
+	notificationQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: historianSubsystemKey,
+		Name:      "notification_queue_depth",
+		Help:      "Number of notifications that were received and not handled yet, by queue.",
+	}, []string{"queue"})
+	notificationLag = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: historianSubsystemKey,
+		Name:      "notification_lag_seconds",
+		Help:      "The time from receiving a notification until it was handled, by queue.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+	}, []string{"queue"})
+	notificationRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: historianSubsystemKey,
+		Name:      "notification_retries_total",
+		Help:      "Number of retries of the notifications that failed to be handled, by queue.",
+	}, []string{"queue"})
+	notificationGaveUp = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: historianSubsystemKey,
+		Name:      "notification_retries_exhausted_total",
+		Help:      "Number of notifications that exhausted their retries, by queue.",
+	}, []string{"queue"})
+	notificationDeadLetters = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: historianSubsystemKey,
+		Name:      "notification_dead_letters_total",
+		Help:      "Number of notifications that were handed over to the dead-letter queue, by queue and result.",
+	}, []string{"queue", "result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		notificationsLeader,
+		leadershipLost,
+		notificationQueueDepth,
+		notificationLag,
+		notificationRetries,
+		notificationGaveUp,
+		notificationDeadLetters,
+	)
+}
@@ -37,6 +37,27 @@ type queue[T api.Notification] struct {
 	workqueue.RateLimitingInterface
 	logger logr.Logger
 	fn     HandleFn[T]
+
+	// retry is optional. When it's set, the failed notifications are retried by the policy, and handed over to giveUp
+	// once the policy is exhausted. Otherwise, they're retried forever.
+	retry  *RetryPolicy
+	name   string
+	giveUp func(ctx context.Context, notification T, err error)
+}
+
+// newRetryingQueue returns a queue that retries the failed notifications by the policy, and calls giveUp with the
+// notifications that exhausted it.
+func newRetryingQueue[T api.Notification](name string, logger logr.Logger, fn HandleFn[T], policy RetryPolicy,
+	giveUp func(ctx context.Context, notification T, err error)) queue[T] {
+	policy = policy.defaults()
+	return queue[T]{
+		RateLimitingInterface: workqueue.NewRateLimitingQueue(policy.rateLimiter()),
+		logger:                logger,
+		fn:                    fn,
+		retry:                 &policy,
+		name:                  name,
+		giveUp:                giveUp,
+	}
 }
 
 func (b *queue[T]) Runnable(workers int) func(ctx context.Context) error {
@@ -87,11 +108,26 @@ func (b *queue[T]) processNextItem(ctx context.Context, stopOnDrained bool) bool
 	}
 
 	err := b.fn(ctx, notification)
-	if err != nil {
+	switch {
+	case err == nil:
+		b.Forget(item)
+	case b.retry == nil:
 		b.logger.WithValues("notification", notification).Error(err, "Failed to process. Requeuing item...")
 		b.AddRateLimited(item)
+		b.Forget(item)
+	case b.retry.exhausted(b.NumRequeues(item) + 1):
+		b.logger.WithValues("notification", notification).Error(err, "Failed to process. Giving up on item",
+			"attempts", b.NumRequeues(item)+1)
+		b.Forget(item)
+		notificationGaveUp.WithLabelValues(b.name).Inc()
+		if b.giveUp != nil {
+			b.giveUp(ctx, notification, err)
+		}
+	default:
+		b.logger.WithValues("notification", notification).Error(err, "Failed to process. Retrying item...",
+			"attempt", b.NumRequeues(item)+1)
+		notificationRetries.WithLabelValues(b.name).Inc()
+		b.AddRateLimited(item)
 	}
-
-	b.Forget(item)
 	return true
 }
@@ -0,0 +1,172 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package historian
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=features,verbs=get;list;watch
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=features/status,verbs=get;update;patch
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"strings"
+	"time"
+)
+
+// DefaultRetentionPeriod is the default time between two prunings of a Feature by its retention policy.
+const DefaultRetentionPeriod = time.Hour
+
+// RetentionReconciler prunes the historical data (and optionally the online values) of the Features that have a
+// retention policy, and reports the pruning in their status.
+type RetentionReconciler struct {
+	ctrlClient.Client
+	HistoricalWriter api.HistoricalWriter
+	State            api.State
+	EventRecorder    record.EventRecorder
+	// Period is the time between two prunings of a Feature. Defaults to DefaultRetentionPeriod.
+	Period time.Duration
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.0/pkg/reconcile
+func (r *RetentionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("component", "retention")
+
+	ft := &manifests.Feature{}
+	if err := r.Get(ctx, req.NamespacedName, ft); err != nil {
+		return ctrl.Result{}, ctrlClient.IgnoreNotFound(err)
+	}
+	if !ft.DeletionTimestamp.IsZero() || ft.Spec.Retention == nil {
+		return ctrl.Result{}, nil
+	}
+	period := r.Period
+	if period <= 0 {
+		period = DefaultRetentionPeriod
+	}
+	if last := ft.Status.Retention; last != nil && last.DryRun == ft.Spec.Retention.DryRun {
+		// the Feature was pruned recently (i.e. by the previous leader)
+		if next := last.LastRun.Add(period); time.Now().Before(next) {
+			return ctrl.Result{RequeueAfter: time.Until(next)}, nil
+		}
+	}
+
+	retention := ft.Spec.Retention
+	horizon := retention.Horizon.Duration
+	if horizon <= 0 {
+		return ctrl.Result{}, nil
+	}
+	now := time.Now()
+	status := &manifests.RetentionStatus{
+		LastRun: metav1.NewTime(now),
+		DryRun:  retention.DryRun,
+		Before:  metav1.NewTime(now.Add(-horizon)),
+	}
+	var messages []string
+
+	if hp, ok := r.HistoricalWriter.(api.HistoricalPruner); ok {
+		report, err := hp.Prune(ctx, ft.FQN(), status.Before.Time, retention.DryRun)
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("failed to prune the historical data: %s", err))
+		}
+		status.PrunedPartitions = report.Partitions
+		status.PrunedFiles = report.Files
+		status.PrunedBytes = report.Bytes
+	} else {
+		messages = append(messages, "the historical writer doesn't support pruning")
+	}
+
+	if retention.Online {
+		n, msg := r.pruneOnline(ctx, ft, horizon, retention.DryRun)
+		status.PrunedEntities = n
+		if msg != "" {
+			messages = append(messages, msg)
+		}
+	}
+	status.Message = strings.Join(messages, "; ")
+
+	patch := ctrlClient.MergeFrom(ft.DeepCopy())
+	ft.Status.Retention = status
+	if err := r.Status().Patch(ctx, ft, patch); err != nil {
+		return ctrl.Result{}, ctrlClient.IgnoreNotFound(err)
+	}
+
+	verb := "Pruned"
+	if retention.DryRun {
+		verb = "Would prune"
+	}
+	msg := fmt.Sprintf("%s %d historical partitions (%d files, %d bytes) and %d entities written before %s", verb,
+		status.PrunedPartitions, status.PrunedFiles, status.PrunedBytes, status.PrunedEntities,
+		status.Before.Format(time.RFC3339))
+	logger.Info(msg, "feature", ft.FQN())
+	if r.EventRecorder == nil {
+		return ctrl.Result{RequeueAfter: period}, nil
+	}
+	if status.Message != "" {
+		r.EventRecorder.Event(ft, "Warning", "RetentionFailed", status.Message)
+	} else if status.PrunedFiles > 0 || status.PrunedEntities > 0 {
+		r.EventRecorder.Event(ft, "Normal", "RetentionApplied", msg)
+	}
+	return ctrl.Result{RequeueAfter: period}, nil
+}
+
+// pruneOnline removes the online values of the entities that weren't active during the horizon. It returns the
+// number of removed entities, and a message when they weren't removed.
+func (r *RetentionReconciler) pruneOnline(ctx context.Context, ft *manifests.Feature, horizon time.Duration, dryRun bool) (int, string) {
+	if dryRun {
+		return 0, "the online values aren't counted in dry-runs"
+	}
+	ec, ok := r.State.(api.EntityCollector)
+	if !ok {
+		return 0, "the state provider doesn't support pruning the online values"
+	}
+	if horizon < SyncPeriod {
+		return 0, fmt.Sprintf("the horizon (%s) must be longer than the sync period (%s) to prune the online values",
+			horizon, SyncPeriod)
+	}
+	fd, err := api.FeatureDescriptorFromManifest(ft)
+	if err != nil {
+		return 0, fmt.Sprintf("failed to parse the feature: %s", err)
+	}
+	if fd.ValidWindow() {
+		// the buckets of windowed features are recorded (and expire) by the historian
+		return 0, ""
+	}
+	n, err := ec.CollectInactiveEntities(ctx, *fd, horizon)
+	if err != nil {
+		return n, fmt.Sprintf("failed to prune the online values: %s", err)
+	}
+	return n, ""
+}
+
+// SetupWithManager sets up the controller with the Controller Manager.
+func (r *RetentionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("retention").
+		For(&manifests.Feature{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package historian
+
+import (
+	"context"
+	"github.com/raptor-ml/raptor/api"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"time"
+)
+
+// DefaultRetryPolicy is the retry policy of the notifications when none is configured.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 10,
+	BaseDelay:   100 * time.Millisecond,
+	MaxDelay:    time.Minute,
+	Jitter:      0.2,
+}
+
+// RetryPolicy configures the retries of the notifications that failed to be handled.
+type RetryPolicy struct {
+	// MaxAttempts is the maximal number of attempts to handle a notification before it's handed over to the
+	// dead-letter queue (0 to retry forever).
+	MaxAttempts int
+	// BaseDelay is the delay of the first retry. The delay is doubled on every retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay of the retries.
+	MaxDelay time.Duration
+	// Jitter is the maximal fraction of the delay that is added to it randomly, so the retries of the notifications
+	// that failed together are spread.
+	Jitter float64
+}
+
+func (p RetryPolicy) defaults() RetryPolicy {
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if p.MaxDelay < p.BaseDelay {
+		p.MaxDelay = max(DefaultRetryPolicy.MaxDelay, p.BaseDelay)
+	}
+	if p.Jitter < 0 {
+		p.Jitter = 0
+	}
+	return p
+}
+
+// exhausted returns true when a notification that failed its attempt-th attempt shouldn't be retried.
+func (p RetryPolicy) exhausted(attempt int) bool {
+	return p.MaxAttempts > 0 && attempt >= p.MaxAttempts
+}
+
+func (p RetryPolicy) rateLimiter() workqueue.RateLimiter {
+	return &jitteredRateLimiter{
+		RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(p.BaseDelay, p.MaxDelay),
+		jitter:      p.Jitter,
+	}
+}
+
+// jitteredRateLimiter adds a random jitter to the delays of a workqueue.RateLimiter.
+type jitteredRateLimiter struct {
+	workqueue.RateLimiter
+	jitter float64
+}
+
+func (r *jitteredRateLimiter) When(item any) time.Duration {
+	d := r.RateLimiter.When(item)
+	if r.jitter <= 0 {
+		return d
+	}
+	return wait.Jitter(d, r.jitter)
+}
+
+// deadLetter returns a function that hands the notifications that exhausted their retries over to the dead-letter
+// queue of the historian (if there's one).
+func deadLetter[T api.Notification](h *historian, kind string) func(context.Context, T, error) {
+	return func(ctx context.Context, notification T, err error) {
+		if h.DeadLetterQueue == nil {
+			return
+		}
+		dl := api.DeadLetter{Notification: kind, Error: err.Error(), FailedAt: time.Now()}
+		switch n := any(notification).(type) {
+		case api.CollectNotification:
+			dl.FQN = n.FQN
+			dl.Row = map[string]any{"encoded_keys": n.EncodedKeys, "bucket": n.Bucket}
+		case api.WriteNotification:
+			dl.FQN = n.FQN
+			dl.Row = map[string]any{
+				"encoded_keys":  n.EncodedKeys,
+				"bucket":        n.Bucket,
+				"active_bucket": n.ActiveBucket,
+				"tombstone":     n.Tombstone,
+			}
+			if n.Value != nil {
+				dl.Value = n.Value.Value
+				dl.Timestamp = n.Value.Timestamp
+			}
+		}
+		if err := h.DeadLetterQueue.SendDeadLetter(ctx, dl); err != nil {
+			notificationDeadLetters.WithLabelValues(kind, "failed").Inc()
+			h.Logger.Error(err, "failed to send the notification to the dead-letter queue", "fqn", dl.FQN)
+			return
+		}
+		notificationDeadLetters.WithLabelValues(kind, "ok").Inc()
+	}
+}
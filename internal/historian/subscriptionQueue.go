@@ -21,6 +21,8 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/raptor-ml/raptor/api"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -28,23 +30,74 @@ type HandleFn[T api.Notification] func(ctx context.Context, notification T) erro
 type FinalizerFunc func(ctx context.Context)
 
 type subscriptionQueue[T api.Notification] struct {
+	name      string
 	queue     queue[T]
 	finalizer func(ctx context.Context)
 	notifier  api.Notifier[T]
 	logger    logr.Logger
+	// received holds the time the pending notifications were added to the queue, to measure the lag of the queue.
+	received *sync.Map
+	pending  *atomic.Int64
 }
 
-func newSubscriptionQueue[T api.Notification](notifier api.Notifier[T], logger logr.Logger, fn HandleFn[T]) subscriptionQueue[T] {
-	return subscriptionQueue[T]{
-		queue:    newQueue[T](logger, fn),
+func newSubscriptionQueue[T api.Notification](name string, notifier api.Notifier[T], logger logr.Logger, fn HandleFn[T],
+	policy RetryPolicy, giveUp func(ctx context.Context, notification T, err error)) subscriptionQueue[T] {
+	c := subscriptionQueue[T]{
+		name:     name,
 		notifier: notifier,
 		logger:   logger,
+		received: &sync.Map{},
+		pending:  &atomic.Int64{},
+	}
+	c.queue = newRetryingQueue[T](name, logger, c.measured(fn), policy, c.abandoned(giveUp))
+	return c
+}
+
+// add adds the notification to the queue, and records the time it was added at.
+func (c *subscriptionQueue[T]) add(notification T) {
+	if _, loaded := c.received.LoadOrStore(notification, time.Now()); !loaded {
+		notificationQueueDepth.WithLabelValues(c.name).Set(float64(c.pending.Add(1)))
+	}
+	c.queue.Add(notification)
+}
+
+// measured records the lag of the notifications that are handled successfully.
+func (c *subscriptionQueue[T]) measured(fn HandleFn[T]) HandleFn[T] {
+	received, pending, name := c.received, c.pending, c.name
+	return func(ctx context.Context, notification T) error {
+		if err := fn(ctx, notification); err != nil {
+			return err
+		}
+		if t, ok := received.LoadAndDelete(notification); ok {
+			notificationLag.WithLabelValues(name).Observe(time.Since(t.(time.Time)).Seconds())
+			notificationQueueDepth.WithLabelValues(name).Set(float64(pending.Add(-1)))
+		}
+		return nil
+	}
+}
+
+// abandoned stops tracking the notifications that exhausted their retries, and hands them over to giveUp.
+func (c *subscriptionQueue[T]) abandoned(giveUp func(ctx context.Context, notification T, err error)) func(context.Context, T, error) {
+	received, pending, name := c.received, c.pending, c.name
+	return func(ctx context.Context, notification T, err error) {
+		if _, ok := received.LoadAndDelete(notification); ok {
+			notificationQueueDepth.WithLabelValues(name).Set(float64(pending.Add(-1)))
+		}
+		if giveUp != nil {
+			giveUp(ctx, notification, err)
+		}
 	}
 }
 
 func (c *subscriptionQueue[T]) Runnable(ctx context.Context) error {
 	defer c.queue.ShutDownWithDrain()
+	c.consume(ctx)
+	return nil
+}
 
+// consume subscribes to the notifications and handles them until the context is done. Unlike Runnable, it doesn't
+// shut down the queue, so the notifications can be consumed again (i.e. when the leadership is regained).
+func (c *subscriptionQueue[T]) consume(ctx context.Context) {
 	go func() {
 		// wait for initialization of the internal feature state
 		time.Sleep(time.Second)
@@ -68,11 +121,10 @@ func (c *subscriptionQueue[T]) Runnable(ctx context.Context) error {
 					c.logger.Error(err, "failed to subscribe to notifications")
 				}
 				for notification := range subscription {
-					c.queue.Add(notification)
+					c.add(notification)
 				}
 			}
 		}
 	}()
 	<-ctx.Done()
-	return nil
 }
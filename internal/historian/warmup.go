@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package historian
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=features,verbs=get;list;watch
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=features/status,verbs=get;update;patch
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"time"
+)
+
+// WarmupReconciler loads the latest historical value of each entity into the state, once, for the Features that
+// have a warmup policy, and reports the warmup in their status. Failed warmups are retried.
+type WarmupReconciler struct {
+	ctrlClient.Client
+	HistoricalWriter api.HistoricalWriter
+	State            api.State
+	EventRecorder    record.EventRecorder
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.0/pkg/reconcile
+func (r *WarmupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("component", "warmup")
+
+	ft := &manifests.Feature{}
+	if err := r.Get(ctx, req.NamespacedName, ft); err != nil {
+		return ctrl.Result{}, ctrlClient.IgnoreNotFound(err)
+	}
+	if !ft.DeletionTimestamp.IsZero() || ft.Spec.Warmup == nil {
+		return ctrl.Result{}, nil
+	}
+	if s := ft.Status.Warmup; s != nil && s.Message == "" {
+		// the state was already warmed up
+		return ctrl.Result{}, nil
+	}
+
+	status := &manifests.WarmupStatus{}
+	err := r.warmup(ctx, ft, status)
+	if err != nil {
+		status.Message = err.Error()
+	}
+	status.CompletedAt = metav1.Now()
+
+	patch := ctrlClient.MergeFrom(ft.DeepCopy())
+	ft.Status.Warmup = status
+	if err := r.Status().Patch(ctx, ft, patch); err != nil {
+		return ctrl.Result{}, ctrlClient.IgnoreNotFound(err)
+	}
+
+	if err != nil {
+		if r.EventRecorder != nil {
+			r.EventRecorder.Event(ft, "Warning", "WarmupFailed", status.Message)
+		}
+		return ctrl.Result{}, err
+	}
+	msg := fmt.Sprintf("Loaded the values of %d entities from the historical storage (%d were kept)",
+		status.Entities, status.Skipped)
+	logger.Info(msg, "feature", ft.FQN())
+	if r.EventRecorder != nil {
+		r.EventRecorder.Event(ft, "Normal", "WarmedUp", msg)
+	}
+	return ctrl.Result{}, nil
+}
+
+// warmup loads the latest historical values into the state. The values of entities that were written to the state
+// since are kept.
+func (r *WarmupReconciler) warmup(ctx context.Context, ft *manifests.Feature, status *manifests.WarmupStatus) error {
+	lr, ok := r.HistoricalWriter.(api.HistoricalLatestReader)
+	if !ok {
+		return fmt.Errorf("the historical writer doesn't support reading the latest values")
+	}
+	fd, err := api.FeatureDescriptorFromManifest(ft)
+	if err != nil {
+		return fmt.Errorf("failed to parse the feature: %w", err)
+	}
+
+	var since time.Time
+	if lookback := ft.Spec.Warmup.Lookback.Duration; lookback > 0 {
+		since = time.Now().Add(-lookback)
+	}
+	return lr.LatestValues(ctx, fd.FQN, since, func(encodedKeys string, val api.Value) error {
+		keys := api.Keys{}
+		if err := keys.Decode(encodedKeys, *fd); err != nil {
+			return fmt.Errorf("failed to decode the keys %s: %w", encodedKeys, err)
+		}
+		cur, err := r.State.Get(ctx, *fd, keys, 0)
+		if err != nil {
+			return fmt.Errorf("failed to get the value of %s: %w", encodedKeys, err)
+		}
+		if cur != nil && !cur.Timestamp.Before(val.Timestamp) {
+			status.Skipped++
+			return nil
+		}
+		if err := r.State.Set(ctx, *fd, keys, val.Value, val.Timestamp); err != nil {
+			return fmt.Errorf("failed to set the value of %s: %w", encodedKeys, err)
+		}
+		status.Entities++
+		return nil
+	})
+}
+
+// SetupWithManager sets up the controller with the Controller Manager.
+func (r *WarmupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("warmup").
+		For(&manifests.Feature{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
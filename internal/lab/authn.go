@@ -0,0 +1,75 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lab
+
+import (
+	"context"
+	"fmt"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	"net/http"
+	"strings"
+)
+
+func bearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	if len(h) < 7 || !strings.EqualFold(h[:7], "bearer ") {
+		return "", false
+	}
+	t := strings.TrimSpace(h[7:])
+	return t, t != ""
+}
+
+// authenticate reviews a Kubernetes token, and returns the identity it belongs to.
+func (l *Lab) authenticate(ctx context.Context, token string) (authnv1.UserInfo, error) {
+	tr := &authnv1.TokenReview{Spec: authnv1.TokenReviewSpec{Token: token}}
+	if err := l.client.Create(ctx, tr); err != nil {
+		return authnv1.UserInfo{}, fmt.Errorf("failed to review token: %w", err)
+	}
+	if !tr.Status.Authenticated {
+		return authnv1.UserInfo{}, fmt.Errorf("unauthenticated: %s", tr.Status.Error)
+	}
+	return tr.Status.User, nil
+}
+
+// allowed checks if the identity is allowed to perform the verb on the features of the namespace.
+// The session token never grants more than the identity is allowed to do by its RBAC.
+func (l *Lab) allowed(ctx context.Context, user authnv1.UserInfo, verb, namespace string) (bool, error) {
+	extra := make(map[string]authzv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authzv1.ExtraValue(v)
+	}
+	sar := &authzv1.SubjectAccessReview{
+		Spec: authzv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authzv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      verb,
+				Group:     manifests.GroupVersion.Group,
+				Resource:  "features",
+			},
+		},
+	}
+	if err := l.client.Create(ctx, sar); err != nil {
+		return false, fmt.Errorf("failed to review access: %w", err)
+	}
+	return sar.Status.Allowed, nil
+}
@@ -0,0 +1,465 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"io"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+const (
+	// entityIDParam is a shorthand for the value of the key of single-keyed features.
+	entityIDParam = "entity_id"
+	// maxSampleSize is the maximal number of entities that can be read at once.
+	maxSampleSize = 100
+	// maxManifestsSize is the maximal size of the pushed manifests.
+	maxManifestsSize = 1 << 20
+
+	fieldOwner        = "raptor-lab"
+	subjectAnnotation = "k8s.raptor.ml/lab-subject"
+)
+
+// errForbidden is returned when the token isn't granted to access a namespace.
+var errForbidden = errors.New("forbidden")
+
+// pushableKinds are the kinds of manifests that can be pushed to the sandbox.
+var pushableKinds = map[string]bool{
+	"Feature":    true,
+	"DataSource": true,
+	"Model":      true,
+}
+
+type tokenRequest struct {
+	Namespaces []string `json:"namespaces"`
+	Scopes     []Scope  `json:"scopes"`
+	TTL        string   `json:"ttl"`
+}
+
+type tokenResponse struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	Namespaces       []string  `json:"namespaces,omitempty"`
+	Scopes           []Scope   `json:"scopes"`
+	SandboxNamespace string    `json:"sandboxNamespace,omitempty"`
+}
+
+// tokenHandler exchanges a Kubernetes bearer token for a session token.
+// The requested grants are reviewed against the RBAC of the Kubernetes identity: `read` requires `get` on the features
+// of each namespace, `write` requires `update` on the features of each namespace, and `dryrun` requires `create` on the
+// features of the sandbox namespace.
+func (l *Lab) tokenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	k8sToken, ok := bearerToken(r)
+	if !ok {
+		http.Error(w, "missing bearer token", http.StatusUnauthorized)
+		return
+	}
+	req := tokenRequest{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxManifestsSize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+		return
+	}
+	if len(req.Scopes) == 0 {
+		req.Scopes = []Scope{ScopeRead}
+	}
+	ttl := l.cfg.TTL
+	if req.TTL != "" {
+		d, err := time.ParseDuration(req.TTL)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid `ttl`", http.StatusBadRequest)
+			return
+		}
+		if d < ttl {
+			ttl = d
+		}
+	}
+
+	ctx := r.Context()
+	user, err := l.authenticate(ctx, k8sToken)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	for _, s := range req.Scopes {
+		var checks []string
+		var verb string
+		switch s {
+		case ScopeRead:
+			if len(req.Namespaces) == 0 {
+				http.Error(w, "`namespaces` are required for the `read` scope", http.StatusBadRequest)
+				return
+			}
+			verb, checks = "get", req.Namespaces
+		case ScopeWrite:
+			if len(req.Namespaces) == 0 {
+				http.Error(w, "`namespaces` are required for the `write` scope", http.StatusBadRequest)
+				return
+			}
+			verb, checks = "update", req.Namespaces
+		case ScopeDryRun:
+			if l.cfg.SandboxNamespace == "" {
+				http.Error(w, "the sandbox namespace is not configured", http.StatusBadRequest)
+				return
+			}
+			verb, checks = "create", []string{l.cfg.SandboxNamespace}
+		default:
+			http.Error(w, fmt.Sprintf("unknown scope `%s`", s), http.StatusBadRequest)
+			return
+		}
+		for _, ns := range checks {
+			allowed, err := l.allowed(ctx, user, verb, ns)
+			if err != nil {
+				l.logger.Error(err, "failed to review access", "user", user.Username)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				http.Error(w, fmt.Sprintf("`%s` is not allowed to %s features in namespace `%s`", user.Username, verb, ns), http.StatusForbidden)
+				return
+			}
+		}
+	}
+
+	key, err := l.signingKey(ctx)
+	if err != nil {
+		l.logger.Error(err, "failed to get the signing key")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	claims := Claims{
+		Subject:    user.Username,
+		Namespaces: req.Namespaces,
+		Scopes:     req.Scopes,
+		IssuedAt:   now.Unix(),
+		ExpiresAt:  now.Add(ttl).Unix(),
+	}
+	token, err := issue(key, claims)
+	if err != nil {
+		l.logger.Error(err, "failed to issue token")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	l.logger.Info("issued lab session token", "user", user.Username, "scopes", req.Scopes, "namespaces", req.Namespaces, "ttl", ttl)
+
+	resp := tokenResponse{
+		Token:      token,
+		ExpiresAt:  time.Unix(claims.ExpiresAt, 0).UTC(),
+		Namespaces: claims.Namespaces,
+		Scopes:     claims.Scopes,
+	}
+	if claims.HasScope(ScopeDryRun) {
+		resp.SandboxNamespace = l.cfg.SandboxNamespace
+	}
+	l.writeJSON(w, resp)
+}
+
+type sampledValue struct {
+	Keys      api.Keys  `json:"keys"`
+	Value     any       `json:"value,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitempty"`
+	Fresh     bool      `json:"fresh"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// valuesHandler reads the online values of a feature for a sample of entities.
+//
+// Usage: GET <prefix>lab/values?fqn=<fqn>&entity_id=<id>&entity_id=<id2>
+// or, for features with multiple keys: GET <prefix>lab/values?fqn=<fqn>&<key>=<value>&<key2>=<value2>
+func (l *Lab) valuesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	claims := claimsFromContext(r.Context())
+
+	q := r.URL.Query()
+	selector := q.Get("fqn")
+	if selector == "" {
+		http.Error(w, "`fqn` is required", http.StatusBadRequest)
+		return
+	}
+	defaultNs := ""
+	if len(claims.Namespaces) > 0 {
+		defaultNs = claims.Namespaces[0]
+	}
+	selector, err := api.NormalizeSelector(selector, defaultNs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ns, _, _, _, _, err := api.ParseSelector(selector)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !claims.HasNamespace(ns) {
+		http.Error(w, fmt.Sprintf("the token isn't granted to read namespace `%s`", ns), http.StatusForbidden)
+		return
+	}
+
+	fd, err := l.engine.FeatureDescriptor(r.Context(), selector)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	var samples []api.Keys
+	if ids := q[entityIDParam]; len(ids) > 0 {
+		if len(fd.Keys) != 1 {
+			http.Error(w, fmt.Sprintf("`%s` can be used only with single-keyed features. use the key names instead: %v", entityIDParam, fd.Keys), http.StatusBadRequest)
+			return
+		}
+		if len(ids) > maxSampleSize {
+			http.Error(w, fmt.Sprintf("the sample is limited to %d entities", maxSampleSize), http.StatusBadRequest)
+			return
+		}
+		for _, id := range ids {
+			samples = append(samples, api.Keys{fd.Keys[0]: id})
+		}
+	} else {
+		keys := api.Keys{}
+		for k := range q {
+			if k != "fqn" {
+				keys[k] = q.Get(k)
+			}
+		}
+		samples = append(samples, keys)
+	}
+
+	ret := make([]sampledValue, len(samples))
+	for i, keys := range samples {
+		ret[i].Keys = keys
+		val, _, err := l.engine.Get(r.Context(), selector, keys)
+		if err != nil {
+			ret[i].Error = err.Error()
+			continue
+		}
+		ret[i].Value = val.Value
+		if wr, ok := val.Value.(api.WindowResultMap); ok {
+			ret[i].Value = wr.Readable()
+		}
+		ret[i].Timestamp = val.Timestamp
+		ret[i].Fresh = val.Fresh
+	}
+	l.writeJSON(w, map[string]any{"fqn": selector, "values": ret})
+}
+
+type writeRequest struct {
+	FQN  string   `json:"fqn"`
+	Keys api.Keys `json:"keys"`
+	// Op is the operation: `set` (the default), `append`, `incr` or `update`.
+	Op    string `json:"op"`
+	Value any    `json:"value"`
+	// Timestamp is the timestamp of the value. Defaults to now.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// writeValueHandler writes a value of an entity. The value is a JSON value of the feature's primitive (or of its
+// scalar, to append to a list).
+//
+// Usage: POST <prefix>lab/values with a JSON body of `{"fqn": "<fqn>", "keys": {...}, "op": "set", "value": ...}`
+func (l *Lab) writeValueHandler(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	req := writeRequest{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxManifestsSize)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode the request: %s", err), http.StatusBadRequest)
+		return
+	}
+	fqn, err := grantedFQN(claims, req.FQN)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	fd, err := l.engine.FeatureDescriptor(r.Context(), fqn)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	ts := time.Now()
+	if req.Timestamp != nil {
+		ts = *req.Timestamp
+	}
+	if req.Op == "" {
+		req.Op = "set"
+	}
+
+	primitive := fd.ValuePrimitive()
+	if req.Op == "append" || req.Op == "incr" {
+		primitive = primitive.Singular()
+	}
+	val, err := api.FromJSONValue(req.Value, primitive)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid value: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	switch req.Op {
+	case "set":
+		err = l.engine.Set(r.Context(), fqn, req.Keys, val, ts)
+	case "append":
+		err = l.engine.Append(r.Context(), fqn, req.Keys, val, ts)
+	case "incr":
+		err = l.engine.Incr(r.Context(), fqn, req.Keys, val, ts)
+	case "update":
+		err = l.engine.Update(r.Context(), fqn, req.Keys, val, ts)
+	default:
+		http.Error(w, fmt.Sprintf("unknown operation `%s`", req.Op), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	l.logger.Info("wrote lab value", "user", claims.Subject, "feature", fqn, "op", req.Op)
+	l.writeJSON(w, map[string]any{"fqn": fqn, "keys": req.Keys, "timestamp": ts})
+}
+
+// featureHandler returns the descriptor (metadata) of a feature.
+//
+// Usage: GET <prefix>lab/features?fqn=<fqn>
+func (l *Lab) featureHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	fqn, err := grantedFQN(claimsFromContext(r.Context()), r.URL.Query().Get("fqn"))
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	fd, err := l.engine.FeatureDescriptor(r.Context(), fqn)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	l.writeJSON(w, fd)
+}
+
+type pushedManifest struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	DryRun    bool   `json:"dryRun"`
+}
+
+// manifestsHandler applies the (YAML or JSON) manifests to the sandbox namespace, regardless of their namespace.
+// With `?dryRun=true`, the manifests are only validated (by the webhooks) and defaulted.
+func (l *Lab) manifestsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	claims := claimsFromContext(r.Context())
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+
+	var objs []*unstructured.Unstructured
+	dec := utilyaml.NewYAMLOrJSONDecoder(io.LimitReader(r.Body, maxManifestsSize), 4096)
+	for {
+		m := map[string]any{}
+		if err := dec.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			http.Error(w, fmt.Sprintf("failed to decode manifests: %s", err), http.StatusBadRequest)
+			return
+		}
+		if len(m) == 0 {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: m}
+		gvk := u.GroupVersionKind()
+		if gvk.Group != manifests.GroupVersion.Group || !pushableKinds[gvk.Kind] {
+			http.Error(w, fmt.Sprintf("manifests of kind `%s` can't be pushed", gvk.GroupKind()), http.StatusBadRequest)
+			return
+		}
+		objs = append(objs, u)
+	}
+	if len(objs) == 0 {
+		http.Error(w, "no manifests were given", http.StatusBadRequest)
+		return
+	}
+
+	opts := []client.PatchOption{client.FieldOwner(fieldOwner), client.ForceOwnership}
+	if dryRun {
+		opts = append(opts, client.DryRunAll)
+	}
+	ret := make([]pushedManifest, 0, len(objs))
+	for _, u := range objs {
+		u.SetNamespace(l.cfg.SandboxNamespace)
+		u.SetResourceVersion("")
+		u.SetUID("")
+		u.SetManagedFields(nil)
+		ann := u.GetAnnotations()
+		if ann == nil {
+			ann = map[string]string{}
+		}
+		ann[subjectAnnotation] = claims.Subject
+		u.SetAnnotations(ann)
+
+		if err := l.client.Patch(r.Context(), u, client.Apply, opts...); err != nil {
+			code := http.StatusInternalServerError
+			if s, ok := err.(apierrors.APIStatus); ok && s.Status().Code != 0 {
+				code = int(s.Status().Code)
+			}
+			http.Error(w, fmt.Sprintf("failed to push %s `%s`: %s", u.GetKind(), u.GetName(), err), code)
+			return
+		}
+		ret = append(ret, pushedManifest{
+			Kind:      u.GetKind(),
+			Name:      u.GetName(),
+			Namespace: u.GetNamespace(),
+			DryRun:    dryRun,
+		})
+	}
+	l.logger.Info("pushed lab manifests", "user", claims.Subject, "count", len(ret), "dryRun", dryRun)
+	l.writeJSON(w, ret)
+}
+
+func (l *Lab) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		l.logger.Error(err, "failed to encode response")
+	}
+}
+
+func httpError(w http.ResponseWriter, err error) {
+	if errors.Is(err, api.ErrFeatureNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, errForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lab
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	keySecretName = "raptor-lab-signing-key" //nolint:gosec //pragma: allowlist secret
+	keySecretKey  = "key"
+	keySize       = 32
+)
+
+// signingKey returns the key that signs the session tokens. The key is kept in a Secret, so the tokens are valid
+// across the replicas of the core (and its restarts). The Secret is created on first use.
+func (l *Lab) signingKey(ctx context.Context) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.key != nil {
+		return l.key, nil
+	}
+
+	key, err := l.readKey(ctx)
+	if apierrors.IsNotFound(err) {
+		key, err = l.createKey(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	l.key = key
+	return key, nil
+}
+
+func (l *Lab) readKey(ctx context.Context) ([]byte, error) {
+	s := &corev1.Secret{}
+	if err := l.reader.Get(ctx, client.ObjectKey{Namespace: l.cfg.Namespace, Name: keySecretName}, s); err != nil {
+		return nil, err
+	}
+	key, ok := s.Data[keySecretKey]
+	if !ok || len(key) < keySize {
+		return nil, fmt.Errorf("secret %s/%s has no valid `%s`", l.cfg.Namespace, keySecretName, keySecretKey)
+	}
+	return key, nil
+}
+
+func (l *Lab) createKey(ctx context.Context) ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: l.cfg.Namespace, Name: keySecretName},
+		Data:       map[string][]byte{keySecretKey: key},
+	}
+	err := l.client.Create(ctx, s)
+	if apierrors.IsAlreadyExists(err) {
+		// another replica has won the race
+		return l.readKey(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signing key secret: %w", err)
+	}
+	return key, nil
+}
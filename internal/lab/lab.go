@@ -0,0 +1,160 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lab serves the LabSDK's (notebooks) access to the cluster with short-lived session tokens.
+//
+// A notebook exchanges a Kubernetes identity (i.e. the token of its ServiceAccount) for a session token that is
+// scoped to a set of namespaces and capabilities, and expires shortly after. This way, data scientists can sample the
+// online values of features, inspect them and push dry-run manifests to a sandbox namespace without being handed a
+// kubeconfig or an access to the state (Redis).
+//
+// The endpoints are served by the HTTP accessor, under `<prefix>lab/`:
+//   - `POST lab/token` exchanges a Kubernetes bearer token for a session token.
+//   - `GET lab/values?fqn=<fqn>&entity_id=<id>&entity_id=<id2>` reads the online values of a sample of entities.
+//   - `POST lab/values` writes a value of an entity (set, append, incr or update).
+//   - `GET lab/features?fqn=<fqn>` returns the descriptor (metadata) of a feature.
+//   - `GET lab/logs?fqn=<fqn>` streams the log lines that mention a feature, when the logs are tapped.
+//   - `POST lab/manifests[?dryRun=true]` applies (YAML or JSON) manifests to the sandbox namespace.
+//   - `POST lab/simulate` computes a Feature manifest from a sample event, without persisting anything.
+package lab
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/raptor-ml/raptor/api"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+	"time"
+)
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// Scope is a capability that is granted to a session token.
+type Scope string
+
+const (
+	// ScopeRead allows reading the online values of the features in the token's namespaces.
+	ScopeRead Scope = "read"
+	// ScopeDryRun allows pushing manifests to the sandbox namespace, and simulating them.
+	ScopeDryRun Scope = "dryrun"
+	// ScopeWrite allows writing the online values of the features in the token's namespaces.
+	ScopeWrite Scope = "write"
+)
+
+const (
+	defaultTTL = time.Hour
+	maxTTL     = 12 * time.Hour
+)
+
+// Config is the configuration of the lab endpoints.
+type Config struct {
+	// Namespace is the namespace of the core, which holds the signing key of the tokens.
+	Namespace string
+	// SandboxNamespace is the namespace the manifests are pushed to. The `dryrun` scope is disabled when it's empty.
+	SandboxNamespace string
+	// TTL is the default lifetime of the tokens. It's capped to 12 hours.
+	TTL time.Duration
+	// Logs is the tap of the Core's logs, which are streamed by `lab/logs`. The endpoint is disabled when it's nil.
+	Logs *LogTap
+}
+
+// Lab serves the LabSDK endpoints.
+type Lab struct {
+	cfg    Config
+	engine api.Engine
+	client client.Client
+	reader client.Reader
+	logger logr.Logger
+
+	mu  sync.Mutex
+	key []byte
+}
+
+// New creates a new Lab. The client is used to review the Kubernetes identities and to apply the manifests, and the
+// reader is used to read the signing key before the cache is started.
+func New(cfg Config, engine api.Engine, c client.Client, reader client.Reader, logger logr.Logger) *Lab {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+	if cfg.TTL > maxTTL {
+		cfg.TTL = maxTTL
+	}
+	return &Lab{
+		cfg:    cfg,
+		engine: engine,
+		client: c,
+		reader: reader,
+		logger: logger,
+	}
+}
+
+// Register registers the lab endpoints on the mux under the given prefix.
+func (l *Lab) Register(mux *http.ServeMux, prefix string) {
+	mux.HandleFunc(fmt.Sprintf("%slab/token", prefix), l.tokenHandler)
+	read, write := l.authorized(ScopeRead, l.valuesHandler), l.authorized(ScopeWrite, l.writeValueHandler)
+	mux.HandleFunc(fmt.Sprintf("%slab/values", prefix), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			write(w, r)
+			return
+		}
+		read(w, r)
+	})
+	mux.HandleFunc(fmt.Sprintf("%slab/features", prefix), l.authorized(ScopeRead, l.featureHandler))
+	if l.cfg.Logs != nil {
+		mux.HandleFunc(fmt.Sprintf("%slab/logs", prefix), l.authorized(ScopeRead, l.logsHandler))
+	}
+	if l.cfg.SandboxNamespace != "" {
+		mux.HandleFunc(fmt.Sprintf("%slab/manifests", prefix), l.authorized(ScopeDryRun, l.manifestsHandler))
+		mux.HandleFunc(fmt.Sprintf("%slab/simulate", prefix), l.authorized(ScopeDryRun, l.simulateHandler))
+	}
+}
+
+type claimsContextKey struct{}
+
+// authorized verifies the session token of the request, and checks that it's granted with the given scope.
+func (l *Lab) authorized(scope Scope, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		key, err := l.signingKey(r.Context())
+		if err != nil {
+			l.logger.Error(err, "failed to get the signing key")
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		claims, err := verify(key, token, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if !claims.HasScope(scope) {
+			http.Error(w, fmt.Sprintf("the token isn't granted with the `%s` scope", scope), http.StatusForbidden)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), claimsContextKey{}, claims)))
+	}
+}
+
+func claimsFromContext(ctx context.Context) Claims {
+	c, _ := ctx.Value(claimsContextKey{}).(Claims)
+	return c
+}
@@ -0,0 +1,238 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lab
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/raptor-ml/raptor/api"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logBufferSize is the number of log lines that are buffered per subscriber. Lines are dropped when a subscriber
+// falls behind, so logging never blocks.
+const logBufferSize = 256
+
+// LogLine is a log line of the Core that mentions a feature.
+type LogLine struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Logger  string         `json:"logger,omitempty"`
+	Message string         `json:"msg"`
+	Error   string         `json:"error,omitempty"`
+	Values  map[string]any `json:"values,omitempty"`
+}
+
+type logSubscription struct {
+	fqn string
+	ch  chan LogLine
+}
+
+type logHub struct {
+	mu   sync.RWMutex
+	subs map[*logSubscription]struct{}
+}
+
+// LogTap is a logr.LogSink that streams the log lines that mention a feature (i.e. the errors of its computations) to
+// the subscribers of `lab/logs`, in addition to writing them to the sink it wraps.
+type LogTap struct {
+	sink   logr.LogSink
+	name   string
+	values []any
+	hub    *logHub
+}
+
+// NewLogTap wraps the sink with a LogTap. Only the lines that are enabled by the wrapped sink are streamed.
+func NewLogTap(sink logr.LogSink) *LogTap {
+	return &LogTap{sink: sink, hub: &logHub{subs: map[*logSubscription]struct{}{}}}
+}
+
+// Init implements logr.LogSink. The wrapped sink is called by the tap, so its call depth is incremented.
+func (t *LogTap) Init(info logr.RuntimeInfo) {
+	info.CallDepth++
+	t.sink.Init(info)
+}
+
+// Enabled implements logr.LogSink
+func (t *LogTap) Enabled(level int) bool {
+	return t.sink.Enabled(level)
+}
+
+// Info implements logr.LogSink
+func (t *LogTap) Info(level int, msg string, keysAndValues ...any) {
+	t.sink.Info(level, msg, keysAndValues...)
+	t.publish(fmt.Sprintf("v%d", level), msg, nil, keysAndValues)
+}
+
+// Error implements logr.LogSink
+func (t *LogTap) Error(err error, msg string, keysAndValues ...any) {
+	t.sink.Error(err, msg, keysAndValues...)
+	t.publish("error", msg, err, keysAndValues)
+}
+
+// WithValues implements logr.LogSink
+func (t *LogTap) WithValues(keysAndValues ...any) logr.LogSink {
+	values := make([]any, 0, len(t.values)+len(keysAndValues))
+	values = append(append(values, t.values...), keysAndValues...)
+	return &LogTap{sink: t.sink.WithValues(keysAndValues...), name: t.name, values: values, hub: t.hub}
+}
+
+// WithName implements logr.LogSink
+func (t *LogTap) WithName(name string) logr.LogSink {
+	if t.name != "" {
+		name = t.name + "." + name
+	}
+	return &LogTap{sink: t.sink.WithName(name), name: name, values: t.values, hub: t.hub}
+}
+
+// WithCallDepth implements logr.CallDepthLogSink
+func (t *LogTap) WithCallDepth(depth int) logr.LogSink {
+	cd, ok := t.sink.(logr.CallDepthLogSink)
+	if !ok {
+		return t
+	}
+	return &LogTap{sink: cd.WithCallDepth(depth), name: t.name, values: t.values, hub: t.hub}
+}
+
+// Subscribe streams the log lines that mention the feature to the returned channel, until it's unsubscribed.
+func (t *LogTap) Subscribe(fqn string) (<-chan LogLine, func()) {
+	sub := &logSubscription{fqn: fqn, ch: make(chan LogLine, logBufferSize)}
+	t.hub.mu.Lock()
+	t.hub.subs[sub] = struct{}{}
+	t.hub.mu.Unlock()
+
+	return sub.ch, func() {
+		t.hub.mu.Lock()
+		delete(t.hub.subs, sub)
+		t.hub.mu.Unlock()
+	}
+}
+
+func (t *LogTap) publish(level, msg string, err error, keysAndValues []any) {
+	t.hub.mu.RLock()
+	defer t.hub.mu.RUnlock()
+	if len(t.hub.subs) == 0 {
+		return
+	}
+
+	var line *LogLine
+	for sub := range t.hub.subs {
+		if !mentions(t.values, sub.fqn) && !mentions(keysAndValues, sub.fqn) {
+			continue
+		}
+		if line == nil {
+			line = &LogLine{Time: time.Now(), Level: level, Logger: t.name, Message: msg, Values: map[string]any{}}
+			if err != nil {
+				line.Error = err.Error()
+			}
+			for _, kvs := range [][]any{t.values, keysAndValues} {
+				for i := 0; i+1 < len(kvs); i += 2 {
+					line.Values[fmt.Sprint(kvs[i])] = kvs[i+1]
+				}
+			}
+		}
+		select {
+		case sub.ch <- *line:
+		default:
+		}
+	}
+}
+
+// mentions checks if any of the values is the FQN of the feature, or one of its selectors (i.e. `<fqn>+sum`).
+func mentions(keysAndValues []any, fqn string) bool {
+	for i := 1; i < len(keysAndValues); i += 2 {
+		s, ok := keysAndValues[i].(string)
+		if !ok || !strings.HasPrefix(s, fqn) {
+			continue
+		}
+		if len(s) == len(fqn) || strings.ContainsRune("+@[", rune(s[len(fqn)])) {
+			return true
+		}
+	}
+	return false
+}
+
+// logsHandler streams the log lines of the Core replica that serves the request, which mention the feature, as
+// newline-delimited JSON. The stream is open until the client disconnects.
+//
+// Usage: GET <prefix>lab/logs?fqn=<fqn>
+func (l *Lab) logsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	fqn, err := grantedFQN(claimsFromContext(r.Context()), r.URL.Query().Get("fqn"))
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	if _, err := l.engine.FeatureDescriptor(r.Context(), fqn); err != nil {
+		httpError(w, err)
+		return
+	}
+
+	lines, unsubscribe := l.cfg.Logs.Subscribe(fqn)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case line := <-lines:
+			if err := enc.Encode(line); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// grantedFQN normalizes the FQN of a feature, and checks that its namespace is granted by the claims.
+func grantedFQN(claims Claims, selector string) (string, error) {
+	if selector == "" {
+		return "", fmt.Errorf("`fqn` is required")
+	}
+	defaultNs := ""
+	if len(claims.Namespaces) > 0 {
+		defaultNs = claims.Namespaces[0]
+	}
+	fqn, err := api.NormalizeFQN(selector, defaultNs)
+	if err != nil {
+		return "", err
+	}
+	ns, _, _, _, _, err := api.ParseSelector(fqn)
+	if err != nil {
+		return "", err
+	}
+	if !claims.HasNamespace(ns) {
+		return "", fmt.Errorf("%w: the token isn't granted to read namespace `%s`", errForbidden, ns)
+	}
+	return fqn, nil
+}
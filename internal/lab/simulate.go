@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lab
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/raptor-ml/raptor/internal/simulate"
+	"io"
+	"net/http"
+	"time"
+)
+
+// simulationSuffix is appended to the name of the simulated features when their program is loaded to the runtime, so
+// the program of the bound feature isn't replaced.
+const simulationSuffix = "-simulation"
+
+type simulateRequest struct {
+	// Manifest is the Feature manifest (YAML or JSON).
+	Manifest string `json:"manifest"`
+	// Event is the sample event, as it's consumed from the DataSource (before its mapping).
+	Event map[string]any `json:"event"`
+	// Timestamp is the timestamp of the event. Defaults to now.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// simulateHandler computes a Feature from a sample event, the way its DataSource's runner would, without persisting
+// anything. The feature is simulated in the sandbox namespace (as if it was pushed), and its DataSource is read from
+// the cluster so its mapping is applied.
+//
+// Usage: POST <prefix>lab/simulate with a JSON body of `{"manifest": "<yaml>", "event": {...}, "timestamp": "..."}`.
+// The response holds the computed value, the state operations of the builder and the logs of the computation.
+func (l *Lab) simulateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	claims := claimsFromContext(r.Context())
+
+	req := simulateRequest{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxManifestsSize)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode the request: %s", err), http.StatusBadRequest)
+		return
+	}
+	ft, err := simulate.DecodeFeature(req.Manifest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ft.SetNamespace(l.cfg.SandboxNamespace)
+	if ref := ft.Spec.DataSource; ref != nil {
+		if ref.Namespace == "" {
+			ref.Namespace = ft.GetNamespace()
+		}
+		if ref.Namespace != l.cfg.SandboxNamespace && !claims.HasNamespace(ref.Namespace) {
+			http.Error(w, fmt.Sprintf("the token isn't granted to read namespace `%s`", ref.Namespace), http.StatusForbidden)
+			return
+		}
+	}
+
+	ev := simulate.Event{Row: req.Event, Timestamp: time.Now()}
+	if req.Timestamp != nil {
+		ev.Timestamp = *req.Timestamp
+	}
+	sim := &simulate.Simulator{Engine: l.engine, Client: l.client}
+	ret, err := sim.Run(r.Context(), ft, []simulate.Event{ev}, simulate.Options{Suffix: simulationSuffix})
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	l.logger.Info("simulated lab feature", "user", claims.Subject, "feature", ft.FQN())
+	l.writeJSON(w, ret[0])
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lab
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/raptor-ml/raptor/pkg/crypto"
+	"strings"
+	"time"
+)
+
+// tokenPrefix identifies the session tokens, so they are not confused with Kubernetes tokens.
+const tokenPrefix = "rlab_"
+
+var (
+	// ErrInvalidToken is returned when a token is malformed or its signature doesn't match.
+	ErrInvalidToken = errors.New("invalid session token")
+	// ErrTokenExpired is returned when a token is past its expiration time.
+	ErrTokenExpired = errors.New("session token expired")
+)
+
+// Claims are the grants of a session token.
+type Claims struct {
+	// Subject is the Kubernetes username the token was issued for.
+	Subject string `json:"sub"`
+	// Namespaces are the namespaces whose features can be read.
+	Namespaces []string `json:"ns,omitempty"`
+	// Scopes are the capabilities of the token.
+	Scopes    []Scope `json:"scp"`
+	IssuedAt  int64   `json:"iat"`
+	ExpiresAt int64   `json:"exp"`
+}
+
+// HasScope checks if the claims grant the given scope.
+func (c Claims) HasScope(s Scope) bool {
+	for _, scope := range c.Scopes {
+		if scope == s {
+			return true
+		}
+	}
+	return false
+}
+
+// HasNamespace checks if the claims grant a read access to the given namespace.
+func (c Claims) HasNamespace(ns string) bool {
+	for _, n := range c.Namespaces {
+		if n == ns {
+			return true
+		}
+	}
+	return false
+}
+
+// issue signs the claims into a session token.
+func issue(key []byte, c Claims) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	msg := base64.RawURLEncoding.EncodeToString(payload)
+	sig := base64.RawURLEncoding.EncodeToString(crypto.Default().Sign(key, []byte(msg)))
+	return tokenPrefix + msg + "." + sig, nil
+}
+
+// verify checks the signature and the expiration of a session token, and returns its claims.
+func verify(key []byte, token string, now time.Time) (Claims, error) {
+	c := Claims{}
+	msg, sig, ok := strings.Cut(strings.TrimPrefix(token, tokenPrefix), ".")
+	if !ok || !strings.HasPrefix(token, tokenPrefix) {
+		return c, ErrInvalidToken
+	}
+	rawSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !crypto.Default().Verify(key, []byte(msg), rawSig) {
+		return c, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(msg)
+	if err != nil {
+		return c, ErrInvalidToken
+	}
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return c, ErrInvalidToken
+	}
+	if now.Unix() >= c.ExpiresAt {
+		return c, ErrTokenExpired
+	}
+	return c, nil
+}
@@ -0,0 +1,320 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtls
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	certApi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"math/big"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"slices"
+	"time"
+)
+
+const (
+	// CASecretName is the name of the Secret of the internal CA (in the Core's namespace).
+	CASecretName = "raptor-grpc-ca" //nolint:gosec //pragma: allowlist secret
+	// LabelKey labels the Secrets of the certificates that are issued by the Issuer.
+	LabelKey = "raptor.ml/grpc-tls"
+
+	caValidity    = 10 * 365 * 24 * time.Hour
+	certValidity  = 90 * 24 * time.Hour
+	renewBefore   = 30 * 24 * time.Hour
+	renewInterval = time.Hour
+
+	serverCommonName = "raptor-core"
+	clientCommonName = "raptor-runner"
+)
+
+// Issuer issues the certificates of the internal gRPC traffic.
+//
+// When a cert-manager ClusterIssuer is configured, it creates cert-manager Certificates and leaves their issuance and
+// renewal to cert-manager. The ClusterIssuer must populate the `ca.crt` of the Secrets (i.e. a CA or a Vault issuer).
+// Otherwise, it keeps an internal CA in the CASecretName Secret, signs the certificates by itself, and renews them
+// periodically.
+type Issuer struct {
+	client        client.Client
+	reader        client.Reader
+	namespace     string
+	clusterIssuer string
+	dnsNames      []string
+	logger        logr.Logger
+}
+
+// NewIssuer creates a new Issuer. The internal CA and the server certificate are kept in the Core's namespace, and
+// the server certificate is valid for the given DNS names of the Core's service.
+func NewIssuer(c client.Client, r client.Reader, namespace, clusterIssuer string, dnsNames []string, logger logr.Logger) *Issuer {
+	return &Issuer{
+		client:        c,
+		reader:        r,
+		namespace:     namespace,
+		clusterIssuer: clusterIssuer,
+		dnsNames:      dnsNames,
+		logger:        logger,
+	}
+}
+
+// Start implements manager.Runnable. It issues the server certificate, and renews the certificates of the internal CA
+// before they expire.
+func (i *Issuer) Start(ctx context.Context) error {
+	ticker := time.NewTicker(renewInterval)
+	defer ticker.Stop()
+	for {
+		if err := i.renew(ctx); err != nil {
+			i.logger.Error(err, "failed to renew the certificates")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (i *Issuer) renew(ctx context.Context) error {
+	if err := i.ensure(ctx, client.ObjectKey{Namespace: i.namespace, Name: ServerSecretName}, serverCommonName,
+		i.dnsNames, x509.ExtKeyUsageServerAuth); err != nil {
+		return fmt.Errorf("failed to issue the server certificate: %w", err)
+	}
+	if i.clusterIssuer != "" {
+		return nil
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := i.reader.List(ctx, secrets, client.MatchingLabels{LabelKey: clientCommonName}); err != nil {
+		return fmt.Errorf("failed to list the client certificates: %w", err)
+	}
+	for _, s := range secrets.Items {
+		if _, err := i.Client(ctx, s.GetNamespace()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Client issues the client certificate of the runners in the namespace, and returns the name of its Secret.
+func (i *Issuer) Client(ctx context.Context, namespace string) (string, error) {
+	err := i.ensure(ctx, client.ObjectKey{Namespace: namespace, Name: ClientSecretName}, clientCommonName, nil,
+		x509.ExtKeyUsageClientAuth)
+	if err != nil {
+		return "", fmt.Errorf("failed to issue the client certificate for namespace %s: %w", namespace, err)
+	}
+	return ClientSecretName, nil
+}
+
+func (i *Issuer) ensure(ctx context.Context, key client.ObjectKey, cn string, dnsNames []string, usage x509.ExtKeyUsage) error {
+	if i.clusterIssuer != "" {
+		return i.ensureCertificate(ctx, key, cn, dnsNames, usage)
+	}
+	return i.ensureSigned(ctx, key, cn, dnsNames, usage)
+}
+
+// ensureCertificate creates (or updates) a cert-manager Certificate.
+func (i *Issuer) ensureCertificate(ctx context.Context, key client.ObjectKey, cn string, dnsNames []string, usage x509.ExtKeyUsage) error {
+	cert := &certApi.Certificate{ObjectMeta: metav1.ObjectMeta{
+		Name:      key.Name,
+		Namespace: key.Namespace,
+	}}
+	_, err := ctrl.CreateOrUpdate(ctx, i.client, cert, func() error {
+		cert.Spec.CommonName = cn
+		cert.Spec.DNSNames = dnsNames
+		cert.Spec.SecretName = key.Name //pragma: allowlist secret
+		cert.Spec.SecretTemplate = &certApi.CertificateSecretTemplate{Labels: map[string]string{LabelKey: cn}}
+		cert.Spec.PrivateKey = &certApi.CertificatePrivateKey{Algorithm: certApi.ECDSAKeyAlgorithm, Size: 256}
+		cert.Spec.Usages = []certApi.KeyUsage{certApi.UsageDigitalSignature, certApi.UsageKeyEncipherment}
+		if usage == x509.ExtKeyUsageServerAuth {
+			cert.Spec.Usages = append(cert.Spec.Usages, certApi.UsageServerAuth)
+		} else {
+			cert.Spec.Usages = append(cert.Spec.Usages, certApi.UsageClientAuth)
+		}
+		cert.Spec.IssuerRef = cmmeta.ObjectReference{
+			Name: i.clusterIssuer,
+			Kind: certApi.ClusterIssuerKind,
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create/update Certificate: %w", err)
+	}
+	return nil
+}
+
+// ensureSigned signs a certificate with the internal CA, unless the existing one is still valid.
+func (i *Issuer) ensureSigned(ctx context.Context, key client.ObjectKey, cn string, dnsNames []string, usage x509.ExtKeyUsage) error {
+	ca, err := i.ca(ctx)
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{}
+	err = i.reader.Get(ctx, key, secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get the certificate's Secret: %w", err)
+	}
+	exists := err == nil
+	if exists && !needsRenewal(secret, ca, dnsNames) {
+		return nil
+	}
+
+	tmpl := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: cn, Organization: []string{"raptor"}},
+		DNSNames:    dnsNames,
+		NotAfter:    time.Now().Add(certValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{usage},
+	}
+	certPEM, keyPEM, err := newCertificate(tmpl, ca)
+	if err != nil {
+		return err
+	}
+
+	secret.SetName(key.Name)
+	secret.SetNamespace(key.Namespace)
+	secret.SetLabels(map[string]string{LabelKey: cn})
+	secret.Type = corev1.SecretTypeTLS
+	secret.Data = map[string][]byte{CertFile: certPEM, KeyFile: keyPEM, CAFile: ca.certPEM}
+	if exists {
+		err = i.client.Update(ctx, secret)
+	} else {
+		err = i.client.Create(ctx, secret)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save the certificate's Secret: %w", err)
+	}
+	i.logger.Info("Certificate issued", "secret", key.String(), "expiration", tmpl.NotAfter)
+	return nil
+}
+
+// needsRenewal checks if the certificate is about to expire, was issued by another CA or for other DNS names.
+func needsRenewal(secret *corev1.Secret, ca *authority, dnsNames []string) bool {
+	if !bytes.Equal(secret.Data[CAFile], ca.certPEM) {
+		return true
+	}
+	block, _ := pem.Decode(secret.Data[CertFile])
+	if block == nil {
+		return true
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return true
+	}
+	if !slices.Equal(cert.DNSNames, dnsNames) {
+		return true
+	}
+	return time.Until(cert.NotAfter) < renewBefore
+}
+
+// authority is the internal CA.
+type authority struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+}
+
+// ca returns the internal CA, and creates it when it doesn't exist.
+func (i *Issuer) ca(ctx context.Context) (*authority, error) {
+	key := client.ObjectKey{Namespace: i.namespace, Name: CASecretName}
+	secret := &corev1.Secret{}
+	err := i.reader.Get(ctx, key, secret)
+	if apierrors.IsNotFound(err) {
+		tmpl := &x509.Certificate{
+			Subject:               pkix.Name{CommonName: CASecretName, Organization: []string{"raptor"}},
+			NotAfter:              time.Now().Add(caValidity),
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+		certPEM, keyPEM, err := newCertificate(tmpl, nil)
+		if err != nil {
+			return nil, err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{CertFile: certPEM, KeyFile: keyPEM},
+		}
+		if err := i.client.Create(ctx, secret); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				// another replica created the CA in the meantime
+				return i.ca(ctx)
+			}
+			return nil, fmt.Errorf("failed to create the CA's Secret: %w", err)
+		}
+		i.logger.Info("Internal CA created", "secret", key.String())
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get the CA's Secret: %w", err)
+	}
+
+	certBlock, _ := pem.Decode(secret.Data[CertFile])
+	keyBlock, _ := pem.Decode(secret.Data[KeyFile])
+	if certBlock == nil || keyBlock == nil {
+		return nil, fmt.Errorf("the CA's Secret is malformed")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the CA certificate: %w", err)
+	}
+	pk, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the CA key: %w", err)
+	}
+	return &authority{cert: cert, key: pk, certPEM: secret.Data[CertFile]}, nil
+}
+
+// newCertificate generates a key and a certificate of the template, which is signed by the CA (or self-signed when
+// the CA is nil).
+func newCertificate(tmpl *x509.Certificate, ca *authority) (certPEM []byte, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate the key: %w", err)
+	}
+	tmpl.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate the serial number: %w", err)
+	}
+	// tolerate clock skews between the nodes
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+
+	parent, signer := tmpl, key
+	if ca != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, signer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sign the certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal the key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
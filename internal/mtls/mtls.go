@@ -0,0 +1,187 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mtls secures the internal gRPC traffic (i.e. between the DataSource runners and the Core's accessor) with
+// mutual TLS.
+//
+// The certificates are kept in Secrets with the layout of cert-manager (`tls.crt`, `tls.key` and `ca.crt`). They're
+// issued either by a cert-manager ClusterIssuer, or by an internal CA that is kept by the Core (see Issuer).
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	corev1 "k8s.io/api/core/v1"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+	"time"
+)
+
+const (
+	// CertFile is the key of the certificate in the Secrets (and the name of its file when they're mounted).
+	CertFile = "tls.crt"
+	// KeyFile is the key of the private key in the Secrets.
+	KeyFile = "tls.key"
+	// CAFile is the key of the CA certificate in the Secrets.
+	CAFile = "ca.crt"
+
+	// ServerSecretName is the name of the Secret of the Core's accessor certificate (in the Core's namespace).
+	ServerSecretName = "raptor-core-grpc-tls" //nolint:gosec //pragma: allowlist secret
+	// ClientSecretName is the name of the Secret of the runners' client certificate (in the DataSources' namespaces).
+	ClientSecretName = "raptor-runner-grpc-tls" //nolint:gosec //pragma: allowlist secret
+)
+
+// keyPair is a certificate and a CA pool that are loaded together.
+type keyPair struct {
+	cert tls.Certificate
+	pool *x509.CertPool
+}
+
+func parseKeyPair(certPEM, keyPEM, caPEM []byte) (*keyPair, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the key pair: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("failed to parse the CA certificate")
+	}
+	return &keyPair{cert: cert, pool: pool}, nil
+}
+
+// dirSource loads the key pair from a mounted Secret, and reloads it when it's rotated.
+type dirSource struct {
+	dir string
+
+	mu      sync.Mutex
+	modTime time.Time
+	current *keyPair
+}
+
+func (s *dirSource) load() (*keyPair, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(filepath.Join(s.dir, CertFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat the certificate: %w", err)
+	}
+	if s.current != nil && info.ModTime().Equal(s.modTime) {
+		return s.current, nil
+	}
+
+	var files [3][]byte
+	for i, name := range []string{CertFile, KeyFile, CAFile} {
+		if files[i], err = os.ReadFile(filepath.Join(s.dir, name)); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+	}
+	kp, err := parseKeyPair(files[0], files[1], files[2])
+	if err != nil {
+		return nil, err
+	}
+	s.current, s.modTime = kp, info.ModTime()
+	return kp, nil
+}
+
+// secretSource loads the key pair from a Secret, and reloads it periodically so rotated certificates are picked up.
+type secretSource struct {
+	reader client.Reader
+	key    client.ObjectKey
+
+	mu       sync.Mutex
+	loadedAt time.Time
+	current  *keyPair
+}
+
+const secretReloadInterval = time.Minute
+
+func (s *secretSource) load() (*keyPair, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil && time.Since(s.loadedAt) < secretReloadInterval {
+		return s.current, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	secret := &corev1.Secret{}
+	if err := s.reader.Get(ctx, s.key, secret); err != nil {
+		if s.current != nil {
+			// keep serving with the loaded certificate while the API server is unavailable
+			return s.current, nil
+		}
+		return nil, fmt.Errorf("failed to get the certificate's Secret: %w", err)
+	}
+	kp, err := parseKeyPair(secret.Data[CertFile], secret.Data[KeyFile], secret.Data[CAFile])
+	if err != nil {
+		return nil, err
+	}
+	s.current, s.loadedAt = kp, time.Now()
+	return kp, nil
+}
+
+// ServerCredentials returns the gRPC credentials of the Core's accessor, which requires the clients to present a
+// certificate of the same CA. The certificate is read from the ServerSecretName Secret in the namespace.
+func ServerCredentials(reader client.Reader, namespace string) credentials.TransportCredentials {
+	src := &secretSource{reader: reader, key: client.ObjectKey{Namespace: namespace, Name: ServerSecretName}}
+	return credentials.NewTLS(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			kp, err := src.load()
+			if err != nil {
+				return nil, err
+			}
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{kp.cert},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    kp.pool,
+			}, nil
+		},
+	})
+}
+
+// TransportCredentials returns the gRPC credentials of a client whose certificates are mounted in the dir.
+// When the dir is empty, the connection is insecure (i.e. over the local unix socket).
+func TransportCredentials(dir string) (credentials.TransportCredentials, error) {
+	if dir == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	src := &dirSource{dir: dir}
+	kp, err := src.load()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    kp.pool,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			kp, err := src.load()
+			if err != nil {
+				return nil, err
+			}
+			return &kp.cert, nil
+		},
+	}), nil
+}
@@ -0,0 +1,340 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=backfills,verbs=get;list;watch
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=backfills/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	"github.com/raptor-ml/raptor/pkg/runner"
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sync"
+	"time"
+)
+
+// backfillProgressInterval is the interval between two progress reports of a running Backfill.
+const backfillProgressInterval = 10 * time.Second
+
+// backfillQueueSize is the maximal number of Backfills that are waiting to run.
+const backfillQueueSize = 100
+
+// BackfillReconciler replays Backfill objects through their Features.
+// The Backfills run in the background, up to Concurrency at once, and report their progress in their status. The
+// status includes the cursor of the last replayed row, so a Backfill is resumed after a restart.
+type BackfillReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Engine        api.Engine
+	Runtime       api.RuntimeManager
+	Concurrency   int
+	EventRecorder record.EventRecorder
+
+	queue     chan client.ObjectKey
+	scheduled sync.Map // client.ObjectKey -> struct{}
+	cancels   sync.Map // client.ObjectKey -> context.CancelFunc
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.0/pkg/reconcile
+func (r *BackfillReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("component", "backfill-operator")
+
+	bf := &manifests.Backfill{}
+	if err := r.Get(ctx, req.NamespacedName, bf); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.cancel(req.NamespacedName)
+		}
+		// we'll ignore not-found errors, since they can't be fixed by an immediate requeue
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !bf.DeletionTimestamp.IsZero() {
+		r.cancel(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	// a changed spec starts the Backfill over
+	if bf.Status.ObservedGeneration != bf.Generation {
+		r.cancel(req.NamespacedName)
+		patch := client.MergeFrom(bf.DeepCopy())
+		bf.Status = manifests.BackfillStatus{
+			Phase:              manifests.BackfillPending,
+			ObservedGeneration: bf.Generation,
+		}
+		if err := r.Status().Patch(ctx, bf, patch); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update Backfill status: %w", err)
+		}
+	}
+	if bf.Status.Phase == manifests.BackfillSucceeded || bf.Status.Phase == manifests.BackfillFailed {
+		return ctrl.Result{}, nil
+	}
+
+	ref := bf.FeatureReference()
+	ft := &manifests.Feature{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, ft); err != nil {
+		logger.Error(err, "Failed to get Feature", "feature", ref)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, client.IgnoreNotFound(err)
+	}
+	if _, err := r.Engine.FeatureDescriptor(ctx, ft.FQN()); err != nil {
+		// the feature might not be bound to the engine yet
+		logger.V(1).Info("feature is not ready yet", "feature", ft.FQN(), "error", err.Error())
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	if _, loaded := r.scheduled.LoadOrStore(req.NamespacedName, struct{}{}); loaded {
+		// the previous generation might still be running; check again once it has stopped
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+	select {
+	case r.queue <- req.NamespacedName:
+		return ctrl.Result{}, nil
+	default:
+		r.scheduled.Delete(req.NamespacedName)
+		logger.V(1).Info("too many backfills are waiting to run")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+}
+
+func (r *BackfillReconciler) cancel(key client.ObjectKey) {
+	if cancel, ok := r.cancels.Load(key); ok {
+		cancel.(context.CancelFunc)()
+	}
+}
+
+// Start runs the scheduled Backfills until the context is canceled.
+func (r *BackfillReconciler) Start(ctx context.Context) error {
+	wg := sync.WaitGroup{}
+	for i := 0; i < r.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case key := <-r.queue:
+					r.run(ctx, key)
+					r.scheduled.Delete(key)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// NeedLeaderElection makes sure that a Backfill runs only on the leader.
+func (r *BackfillReconciler) NeedLeaderElection() bool {
+	return true
+}
+
+func (r *BackfillReconciler) run(ctx context.Context, key client.ObjectKey) {
+	logger := log.FromContext(ctx).WithValues("component", "backfill-operator", "backfill", key)
+
+	bf := &manifests.Backfill{}
+	if err := r.Get(ctx, key, bf); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "Failed to get Backfill")
+		}
+		return
+	}
+	if bf.Status.ObservedGeneration != bf.Generation || bf.Status.Phase == manifests.BackfillSucceeded ||
+		bf.Status.Phase == manifests.BackfillFailed {
+		return
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r.cancels.Store(key, cancel)
+	defer r.cancels.Delete(key)
+
+	fqn, err := r.replay(runCtx, bf, logger)
+	switch {
+	case runCtx.Err() != nil:
+		// interrupted by a shutdown or a change of the Backfill; it's resumed (or started over) by the next reconcile
+		logger.Info("backfill interrupted", "cursor", bf.Status.Cursor)
+		return
+	case apierrors.IsConflict(err) || apierrors.IsNotFound(err):
+		// the Backfill was changed or deleted while running
+		return
+	}
+
+	patch := client.MergeFrom(bf.DeepCopy())
+	now := metav1.Now()
+	bf.Status.CompletedAt = &now
+	if err != nil {
+		logger.Error(err, "backfill failed")
+		r.EventRecorder.Event(bf, "Warning", "BackfillFailed", err.Error())
+		bf.Status.Phase = manifests.BackfillFailed
+		bf.Status.Message = err.Error()
+	} else {
+		r.EventRecorder.Eventf(bf, "Normal", "Backfilled", "Replayed %d rows of %s", bf.Status.Processed, fqn)
+		bf.Status.Phase = manifests.BackfillSucceeded
+		bf.Status.Message = ""
+	}
+	if err := r.Status().Patch(ctx, bf, patch); err != nil {
+		logger.Error(err, "Failed to update Backfill status")
+	}
+}
+
+// replay reads the Backfill's source from its cursor, and executes the rows through the Feature.
+// It returns the FQN of the Feature.
+func (r *BackfillReconciler) replay(ctx context.Context, bf *manifests.Backfill, logger logr.Logger) (string, error) {
+	ref := bf.FeatureReference()
+	ft := &manifests.Feature{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, ft); err != nil {
+		return "", fmt.Errorf("failed to get Feature: %w", err)
+	}
+	fd, err := r.Engine.FeatureDescriptor(ctx, ft.FQN())
+	if err != nil {
+		return "", err
+	}
+	if fd.ValidWindow() {
+		// windows are recorded in the historical storage by collecting their buckets from the online store, and
+		// their events are dropped after the allowed lateness, so they're rebuilt only from the live stream
+		return fd.FQN, fmt.Errorf("windowed features can't be backfilled")
+	}
+	if fd.Virtual() {
+		return fd.FQN, fmt.Errorf("on-demand features are computed on request, and have no history to backfill")
+	}
+
+	executor := &runner.Executor{
+		Client:         r.Client,
+		Engine:         r.Engine,
+		Runtime:        r.Runtime,
+		Logger:         logger,
+		HistoricalOnly: !bf.Spec.WarmOnline,
+	}
+	if ft.Spec.DataSource != nil {
+		executor.DataSource = ft.Spec.DataSource.ObjectKey()
+		if executor.DataSource.Namespace == "" {
+			executor.DataSource.Namespace = ft.GetNamespace()
+		}
+	}
+	snap, err := executor.FeatureSnapshot(ctx, ft)
+	if err != nil {
+		return fd.FQN, err
+	}
+
+	pc, err := bf.ParseConfig(ctx, r.Client)
+	if err != nil {
+		return fd.FQN, fmt.Errorf("failed to parse the source config: %w", err)
+	}
+	if err := pc.DecodeSecrets(bf.Spec.Source.Config); err != nil {
+		return fd.FQN, err
+	}
+	src, err := plugins.NewBackfillSource(ctx, bf.Spec.Source.Kind, pc)
+	if err != nil {
+		return fd.FQN, err
+	}
+	defer src.Close()
+
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if bf.Spec.RateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(bf.Spec.RateLimit), bf.Spec.RateLimit)
+	}
+
+	patch := client.MergeFromWithOptions(bf.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	bf.Status.Phase = manifests.BackfillRunning
+	if bf.Status.StartedAt == nil {
+		now := metav1.Now()
+		bf.Status.StartedAt = &now
+	}
+	if err := r.Status().Patch(ctx, bf, patch); err != nil {
+		return fd.FQN, err
+	}
+	logger.Info("backfill started", "feature", fd.FQN, "cursor", bf.Status.Cursor)
+
+	// the progress is reported with an optimistic lock, so a run of a changed Backfill can't override its status
+	progress := client.MergeFromWithOptions(bf.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	lastReport := time.Now()
+	err = src.Read(ctx, bf.Status.Cursor, func(row map[string]any, ts time.Time, cursor string) error {
+		if bf.Spec.From != nil && ts.Before(bf.Spec.From.Time) || bf.Spec.To != nil && ts.After(bf.Spec.To.Time) {
+			bf.Status.Skipped++
+		} else {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+			// the position of the row identifies it, so a resumed backfill doesn't write the same row twice
+			id := fmt.Sprintf("%s@%d:%s", bf.GetUID(), bf.Generation, cursor)
+			executor.Execute(context.WithValue(ctx, api.ContextKeyEventID, id), snap, row, ts)
+			bf.Status.Processed++
+		}
+		bf.Status.Cursor = cursor
+
+		if time.Since(lastReport) < backfillProgressInterval {
+			return nil
+		}
+		lastReport = time.Now()
+		if err := r.Status().Patch(ctx, bf, progress); err != nil {
+			return err
+		}
+		progress = client.MergeFromWithOptions(bf.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return fd.FQN, err
+		}
+		return fd.FQN, fmt.Errorf("failed to replay the source: %w", err)
+	}
+	if err := r.Status().Patch(ctx, bf, progress); err != nil {
+		return fd.FQN, err
+	}
+
+	if bus, ok := r.Engine.(api.EventBus); ok {
+		bus.Publish(ctx, api.BackfillCompletedEvent{
+			DataSource: fd.DataSource,
+			Source:     fmt.Sprintf("backfill/%s/%s", bf.GetNamespace(), bf.GetName()),
+			Rows:       int(bf.Status.Processed),
+		})
+	}
+	return fd.FQN, nil
+}
+
+// SetupWithManager sets up the controller with the Controller Manager.
+func (r *BackfillReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Concurrency <= 0 {
+		r.Concurrency = 1
+	}
+	r.queue = make(chan client.ObjectKey, backfillQueueSize)
+	if err := mgr.Add(r); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&manifests.Backfill{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=features,verbs=get;list;watch
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=features/status,verbs=get;update;patch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"time"
+)
+
+const (
+	// DefaultIngestionWindow is the time a feature without a freshness is reported as ingesting after its last update.
+	DefaultIngestionWindow = 15 * time.Minute
+
+	// conditionsRequeue is the time between two checks of the conditions of a feature.
+	conditionsRequeue = time.Minute
+	// bindRequeue is the time between two checks of a feature that isn't bound yet.
+	bindRequeue = 10 * time.Second
+)
+
+// ConditionsReconciler reports the `Bound`, `Ingesting` and `Degraded` conditions of the Features, as observed by the
+// leader's engine. The `Validated` and `BuilderCompileError` conditions are reported by the FeatureReconciler, and the `Stale`
+// condition by the FreshnessReconciler.
+type ConditionsReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Engine api.ManagerEngine
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.0/pkg/reconcile
+func (r *ConditionsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ft := &manifests.Feature{}
+	if err := r.Get(ctx, req.NamespacedName, ft); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !ft.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	requeue := conditionsRequeue
+	conds := make([]metav1.Condition, 0, 2)
+	fd, err := r.Engine.FeatureDescriptor(ctx, ft.FQN())
+	switch {
+	case errors.Is(err, api.ErrFeatureNotFound):
+		requeue = bindRequeue
+		cond := metav1.Condition{
+			Type:    manifests.FeatureConditionBound,
+			Status:  metav1.ConditionFalse,
+			Reason:  "NotBound",
+			Message: "Waiting for the Core to bind the Feature",
+		}
+		if br, ok := r.Engine.(api.BindErrorReporter); ok {
+			if err := br.BindError(ft.FQN()); err != nil {
+				cond.Reason = "BindFailed"
+				cond.Message = err.Error()
+			}
+		}
+		conds = append(conds, cond)
+	case err != nil:
+		return ctrl.Result{}, err
+	default:
+		conds = append(conds, metav1.Condition{
+			Type:    manifests.FeatureConditionBound,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Bound",
+			Message: "The Feature is bound by the Core",
+		})
+		if cond, ok, err := r.ingesting(ctx, fd); err != nil {
+			return ctrl.Result{}, err
+		} else if ok {
+			conds = append(conds, cond)
+		}
+		if cond, ok := r.degraded(fd); ok {
+			conds = append(conds, cond)
+		}
+	}
+
+	// the status is patched only when a condition changes, so the other controllers of the Feature aren't triggered
+	// by every check
+	patch := client.MergeFrom(ft.DeepCopy())
+	changed := false
+	for _, cond := range conds {
+		cond.ObservedGeneration = ft.GetGeneration()
+		prev := meta.FindStatusCondition(ft.Status.Conditions, cond.Type)
+		if prev != nil && prev.Status == cond.Status && prev.Reason == cond.Reason && prev.ObservedGeneration == cond.ObservedGeneration {
+			continue
+		}
+		meta.SetStatusCondition(&ft.Status.Conditions, cond)
+		changed = true
+	}
+	if changed {
+		if err := r.Status().Patch(ctx, ft, patch); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeue}, nil
+}
+
+// degraded returns the `Degraded` condition of features with a circuit breaker. The feature is degraded while its
+// circuit breaker is open, and its program isn't invoked.
+func (r *ConditionsReconciler) degraded(fd api.FeatureDescriptor) (metav1.Condition, bool) {
+	cr, ok := r.Engine.(api.CircuitBreakerReporter)
+	if !ok || fd.CircuitBreaker <= 0 {
+		return metav1.Condition{}, false
+	}
+	cond := metav1.Condition{
+		Type:    manifests.FeatureConditionDegraded,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Healthy",
+		Message: "The circuit breaker of the Feature is closed",
+	}
+	if err := cr.CircuitOpen(fd.FQN); err != nil {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "CircuitOpen"
+		cond.Message = fmt.Sprintf("The program of the Feature isn't invoked: %s", err)
+	}
+	return cond, true
+}
+
+// ingesting returns the `Ingesting` condition of the feature. It returns false for features that are computed on
+// read, or when the engine doesn't track the updates of the features.
+func (r *ConditionsReconciler) ingesting(ctx context.Context, fd api.FeatureDescriptor) (metav1.Condition, bool, error) {
+	tracker, ok := r.Engine.(api.FreshnessTracker)
+	if !ok || fd.Virtual() {
+		return metav1.Condition{}, false, nil
+	}
+	lastUpdate, err := tracker.FeatureLastUpdate(ctx, fd.FQN)
+	if err != nil {
+		return metav1.Condition{}, false, fmt.Errorf("failed to get the last update of the feature: %w", err)
+	}
+
+	window := fd.Freshness
+	if window <= 0 {
+		window = DefaultIngestionWindow
+	}
+	cond := metav1.Condition{
+		Type:    manifests.FeatureConditionIngesting,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ReceivingUpdates",
+		Message: fmt.Sprintf("The feature was updated at %s", lastUpdate.Format(time.RFC3339)),
+	}
+	switch {
+	case lastUpdate.IsZero():
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "NeverUpdated"
+		cond.Message = "The feature wasn't updated since it was bound"
+	case time.Since(lastUpdate) > window:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "NoRecentUpdates"
+		cond.Message = fmt.Sprintf("The feature wasn't updated since %s, for longer than %s",
+			lastUpdate.Format(time.RFC3339), window)
+	}
+	return cond, true, nil
+}
+
+// SetupWithManager sets up the controller with the Controller Manager.
+func (r *ConditionsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("conditions").
+		For(&manifests.Feature{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
+
+// DashboardName is the name of the ConfigMap that holds the Grafana dashboard of the per-feature metrics.
+const DashboardName = "raptor-features-dashboard"
+
+//go:embed dashboard.json.tmpl
+var dashboardTemplate string
+
+// Dashboard returns a Runnable that keeps the Grafana dashboard ConfigMap up to date in the given namespace.
+// The ConfigMap is labeled with `grafana_dashboard`, so it's picked up by the Grafana dashboards sidecar.
+func Dashboard(c client.Client, namespace string) manager.Runnable {
+	return manager.RunnableFunc(func(ctx context.Context) error {
+		tpl, err := template.New("dashboard").Delims("[[", "]]").Parse(dashboardTemplate)
+		if err != nil {
+			return fmt.Errorf("failed to parse dashboard template: %w", err)
+		}
+		buf := &bytes.Buffer{}
+		if err := tpl.Execute(buf, map[string]string{"Namespace": namespace}); err != nil {
+			return fmt.Errorf("failed to render dashboard template: %w", err)
+		}
+
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: DashboardName, Namespace: namespace}}
+		_, err = controllerutil.CreateOrUpdate(ctx, c, cm, func() error {
+			if cm.Labels == nil {
+				cm.Labels = map[string]string{}
+			}
+			cm.Labels["grafana_dashboard"] = "1"
+			cm.Labels["app.kubernetes.io/part-of"] = "raptor"
+			cm.Data = map[string]string{"raptor-features.json": buf.String()}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply the dashboard ConfigMap: %w", err)
+		}
+		return nil
+	})
+}
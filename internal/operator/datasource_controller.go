@@ -28,6 +28,7 @@ import (
 	"fmt"
 	"github.com/raptor-ml/raptor/api"
 	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/internal/mtls"
 	"github.com/raptor-ml/raptor/pkg/plugins"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -47,6 +48,8 @@ type DataSourceReconciler struct {
 	CoreAddr       string
 	RuntimeManager api.RuntimeManager
 	EventRecorder  record.EventRecorder
+	// TLS issues the runners' client certificates when mTLS is enabled (nil otherwise).
+	TLS *mtls.Issuer
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -103,7 +106,16 @@ func (r *DataSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	if p := plugins.DataSourceReconciler.Get(src.Spec.Kind); p != nil {
-		if changed, err := p(log.IntoContext(ctx, logger.WithName("runner")), r.reconcileRequest(src)); err != nil {
+		rr := r.reconcileRequest(src)
+		if r.TLS != nil {
+			if rr.CoreTLSSecret, err = r.TLS.Client(ctx, src.GetNamespace()); err != nil {
+				logger.Error(err, "Failed to issue the runner's certificate")
+				r.EventRecorder.Eventf(src, "Warning", "ReconcileFailed",
+					"Failed to issue the runner's certificate: %v", err)
+				return ctrl.Result{}, err
+			}
+		}
+		if changed, err := p(log.IntoContext(ctx, logger.WithName("runner")), rr); err != nil {
 			r.EventRecorder.Eventf(src, "Warning", "ReconcileFailed",
 				"Failed to reconcile DataSource: %v", err)
 			return ctrl.Result{}, err
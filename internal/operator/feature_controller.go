@@ -22,12 +22,19 @@ package operator
 
 import (
 	"context"
+	"fmt"
 	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/tenancy"
+	"github.com/raptor-ml/raptor/pkg/celexpr"
+	"github.com/raptor-ml/raptor/pkg/sqlexpr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strings"
 	"time"
 
 	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
@@ -103,13 +110,45 @@ func (r *FeatureReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{RequeueAfter: time.Second * 2}, client.IgnoreNotFound(err)
 	}
 
-	prog, err := r.RuntimeManager.LoadProgram(feature.Spec.Builder.Runtime, feature.FQN(), feature.Spec.Builder.Code, feature.Spec.Builder.Packages)
-	if err != nil {
-		logger.Error(err, "Failed to load program")
-		return ctrl.Result{}, err
+	if _, err := api.FeatureDescriptorFromManifest(feature); err != nil {
+		// invalid specs can't be fixed by a requeue, so they are reported in the status until the spec changes
+		logger.Error(err, "Invalid Feature spec")
+		return ctrl.Result{}, r.updateStatus(ctx, feature, err, metav1.Condition{
+			Type:    manifests.FeatureConditionValidated,
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidSpec",
+			Message: err.Error(),
+		})
+	}
+
+	if err := ValidateExpression(feature); err != nil {
+		// compilation errors can't be fixed by a requeue, so they are reported in the status until the spec changes
+		logger.Error(err, "Failed to compile expression")
+		return ctrl.Result{}, r.updateStatus(ctx, feature, err, compileErrorCondition("ExpressionCompileFailed", err),
+			metav1.Condition{
+				Type:    manifests.FeatureConditionValidated,
+				Status:  metav1.ConditionFalse,
+				Reason:  "BuilderCompileError",
+				Message: "The builder's expression failed to compile",
+			})
+	}
+
+	var deps []string
+	if feature.Spec.Builder.HasProgram() && !strings.EqualFold(feature.Spec.Builder.Kind, api.RemoteBuilder) {
+		prog, err := r.RuntimeManager.LoadProgram(feature.Spec.Builder.Runtime, feature.FQN(), feature.Spec.Builder.Code, feature.Spec.Builder.Packages)
+		if err != nil {
+			logger.Error(err, "Failed to load program")
+			// the runtime may be unavailable, so the program is loaded again
+			if err := r.updateStatus(ctx, feature, err, compileErrorCondition("ProgramLoadFailed", err)); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, err
+		}
+		deps = prog.Dependencies
 	}
 
-	for _, dep := range prog.Dependencies {
+	feature.Status.Dependencies = nil
+	for _, dep := range deps {
 		ns, n, _, _, _, err := api.ParseSelector(dep)
 		if err != nil {
 			logger.Error(err, "Failed to parse dependency FQN")
@@ -118,22 +157,80 @@ func (r *FeatureReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		if ns == "" {
 			ns = feature.Namespace
 		}
-		feature.Status.Dependencies = append(feature.Status.Dependencies, manifests.ResourceReference{
+		ref := manifests.ResourceReference{
 			Namespace: ns,
 			Name:      n,
-		})
+		}
+		if err := tenancy.CheckAccess(ctx, r.Client, feature.Namespace, ref); err != nil {
+			// the program reads a feature of another tenant, which can't be fixed by a requeue
+			logger.Error(err, "Dependency is not accessible")
+			return ctrl.Result{}, r.updateStatus(ctx, feature, err, metav1.Condition{
+				Type:    manifests.FeatureConditionValidated,
+				Status:  metav1.ConditionFalse,
+				Reason:  "DependencyNotAccessible",
+				Message: err.Error(),
+			})
+		}
+		feature.Status.Dependencies = append(feature.Status.Dependencies, ref)
 	}
 
-	feature.Status.FQN = feature.FQN()
-	feature.Status.Ready = true
-	if err := r.Status().Update(ctx, feature); err != nil {
-		logger.Error(err, "Failed to update Feature status")
+	sum, err := api.FeatureChecksum(feature)
+	if err != nil {
+		logger.Error(err, "Failed to calculate Feature checksum")
+		return ctrl.Result{}, err
+	}
+
+	feature.Status.Checksum = sum
+	msg := "The Feature is valid"
+	if len(deps) > 0 {
+		msg = fmt.Sprintf("The Feature is valid, and its %d dependencies are accessible", len(deps))
+	}
+	err = r.updateStatus(ctx, feature, nil, metav1.Condition{
+		Type:    manifests.FeatureConditionValidated,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Valid",
+		Message: msg,
+	}, metav1.Condition{
+		Type:    manifests.FeatureConditionBuilderCompileError,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Compiled",
+		Message: "The builder compiled successfully",
+	})
+	if err != nil {
 		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// updateStatus updates the readiness of the Feature and its conditions. The Feature is ready when there's no error.
+func (r *FeatureReconciler) updateStatus(ctx context.Context, feature *manifests.Feature, err error, conds ...metav1.Condition) error {
+	feature.Status.FQN = feature.FQN()
+	feature.Status.Ready = err == nil
+	feature.Status.Message = ""
+	if err != nil {
+		feature.Status.Message = err.Error()
+	}
+	for _, cond := range conds {
+		cond.ObservedGeneration = feature.GetGeneration()
+		meta.SetStatusCondition(&feature.Status.Conditions, cond)
+	}
+	if err := r.Status().Update(ctx, feature); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update Feature status")
+		return err
+	}
+	return nil
+}
+
+func compileErrorCondition(reason string, err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    manifests.FeatureConditionBuilderCompileError,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: err.Error(),
+	}
+}
+
 // SetupWithManager sets up the controller with the Controller Manager.
 func (r *FeatureReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -202,3 +299,28 @@ func (r *FeatureReconciler) addToSource(ctx context.Context, feature *manifests.
 	src.Status.Features = append(src.Status.Features, feature.ResourceReference())
 	return r.Status().Update(ctx, src)
 }
+
+// ValidateExpression compiles the `sql` or `cel` expression of the feature, and checks that it can build the feature.
+// It doesn't require a cluster, so it's used to validate manifests offline as well.
+func ValidateExpression(feature *manifests.Feature) error {
+	b := feature.Spec.Builder
+	if b.SQL == "" && b.CEL == "" {
+		return nil
+	}
+	fd, err := api.FeatureDescriptorFromManifest(feature)
+	if err != nil {
+		return err
+	}
+	if b.CEL != "" {
+		p, err := celexpr.Compile(b.CEL)
+		if err != nil {
+			return err
+		}
+		return p.Validate(*fd)
+	}
+	q, err := sqlexpr.Compile(b.SQL)
+	if err != nil {
+		return err
+	}
+	return q.Validate(*fd)
+}
@@ -28,9 +28,12 @@ import (
 	"github.com/raptor-ml/raptor/api"
 	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
 	"github.com/raptor-ml/raptor/internal/engine"
+	"github.com/raptor-ml/raptor/internal/stats"
+	"github.com/raptor-ml/raptor/internal/tenancy"
 	"github.com/raptor-ml/raptor/pkg/plugins"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,9 +45,10 @@ const FeatureWebhookValidateName = "raptor-validating-webhook-configuration"
 const FeatureWebhookMutatePath = "/mutate-k8s-raptor-ml-v1alpha1-feature"
 const FeatureWebhookMutateName = "raptor-mutating-webhook-configuration"
 
-func SetupFeatureWebhook(mgr ctrl.Manager, updatesAllowed bool, rm api.RuntimeManager) {
+func SetupFeatureWebhook(mgr ctrl.Manager, updatesAllowed bool, rm api.RuntimeManager, sandbox SandboxConfig) {
 	impl := &webhook{
 		updatesAllowed: updatesAllowed,
+		sandbox:        sandbox,
 		client:         mgr.GetClient(),
 		logger:         mgr.GetLogger().WithName("feature-webhook"),
 		runtimeManager: rm,
@@ -64,6 +68,7 @@ type webhook struct {
 	logger         logr.Logger
 	updatesAllowed bool
 	runtimeManager api.RuntimeManager
+	sandbox        SandboxConfig
 }
 
 type ctxKey string
@@ -86,6 +91,27 @@ func (wh *webhook) Default(ctx context.Context, obj runtime.Object) error {
 	if f.Spec.DataSource != nil && f.Spec.DataSource.Namespace == "" {
 		f.Spec.DataSource.Namespace = f.GetNamespace()
 	}
+	if f.Spec.Entity != "" && len(f.Spec.Keys) == 0 {
+		if ar, ok := ctx.Value(admissionRequestContextKey).(admission.Request); ok && ar.DryRun == nil || ok && !*ar.DryRun {
+			entity, err := wh.entity(ctx, f)
+			if err != nil {
+				return err
+			}
+			f.Spec.Keys = entity.KeyNames()
+		}
+	}
+	if f.Spec.Builder.Kind == "" && f.Spec.Builder.SQL != "" {
+		f.Spec.Builder.Kind = api.SQLBuilder
+	}
+	if f.Spec.Builder.Kind == "" && f.Spec.Builder.CEL != "" {
+		f.Spec.Builder.Kind = api.CELBuilder
+	}
+	if f.Spec.Builder.Kind == "" && f.Spec.Builder.Wasm != nil {
+		f.Spec.Builder.Kind = api.WasmBuilder
+	}
+	if f.Spec.Builder.Kind == "" && f.Spec.Builder.EventID != "" {
+		f.Spec.Builder.Kind = api.AggregationBuilder
+	}
 	if f.Spec.Builder.Kind == "" {
 		if f.Spec.DataSource != nil {
 			if ar, ok := ctx.Value(admissionRequestContextKey).(admission.Request); ok && ar.DryRun == nil || ok && !*ar.DryRun {
@@ -118,6 +144,18 @@ func (wh *webhook) Default(ctx context.Context, obj runtime.Object) error {
 		}
 	}
 
+	if f.Spec.Historical == nil {
+		sandbox, err := IsSandboxNamespace(ctx, wh.client, f.GetNamespace())
+		if err != nil {
+			return err
+		}
+		if sandbox {
+			// features in sandbox namespaces are experimental, and shouldn't pollute the historical storage
+			historical := false
+			f.Spec.Historical = &historical
+		}
+	}
+
 	return nil
 }
 
@@ -137,16 +175,71 @@ func (wh *webhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admi
 		}
 	}
 
+	if err := wh.checkTenantQuota(ctx, f); err != nil {
+		return nil, err
+	}
+
+	if wh.sandbox.MaxFeatures > 0 {
+		sandbox, err := IsSandboxNamespace(ctx, wh.client, f.GetNamespace())
+		if err != nil {
+			return nil, err
+		}
+		if sandbox {
+			features := manifests.FeatureList{}
+			if err := wh.client.List(ctx, &features, client.InNamespace(f.GetNamespace())); err != nil {
+				return nil, fmt.Errorf("failed to list the features of the sandbox: %w", err)
+			}
+			if len(features.Items) >= wh.sandbox.MaxFeatures {
+				return nil, fmt.Errorf("the sandbox namespace %s has reached its quota of %d features",
+					f.GetNamespace(), wh.sandbox.MaxFeatures)
+			}
+		}
+	}
+
 	return wh.Validate(ctx, f)
 }
 
+// checkTenantQuota verifies that a feature can be added to the namespaces of the tenant, by the tenant's quota.
+func (wh *webhook) checkTenantQuota(ctx context.Context, f *manifests.Feature) error {
+	tenant, err := tenancy.Of(ctx, wh.client, f.GetNamespace())
+	if err != nil || tenant == nil {
+		return err
+	}
+	if meta.IsStatusConditionTrue(tenant.Status.Conditions, manifests.TenantConditionQuotaExceeded) {
+		stats.IncrTenantQuotaRejections(tenant.GetName(), stats.QuotaStateMemory)
+		return fmt.Errorf("tenant %s has exceeded its state memory quota of %s", tenant.GetName(), tenant.Spec.Quota.MaxStateMemory)
+	}
+	if tenant.Spec.Quota.MaxFeatures <= 0 {
+		return nil
+	}
+	count := 0
+	for _, ns := range tenant.Spec.Namespaces {
+		features := manifests.FeatureList{}
+		if err := wh.client.List(ctx, &features, client.InNamespace(ns)); err != nil {
+			return fmt.Errorf("failed to list the features of the tenant: %w", err)
+		}
+		count += len(features.Items)
+	}
+	if count >= int(tenant.Spec.Quota.MaxFeatures) {
+		stats.IncrTenantQuotaRejections(tenant.GetName(), stats.QuotaFeatures)
+		return fmt.Errorf("tenant %s has reached its quota of %d features", tenant.GetName(), tenant.Spec.Quota.MaxFeatures)
+	}
+	return nil
+}
+
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (wh *webhook) ValidateUpdate(ctx context.Context, oldObject, newObj runtime.Object) (admission.Warnings, error) {
 	f := newObj.(*manifests.Feature)
 	old := oldObject.(*manifests.Feature)
 	wh.logger.Info("validate update", "name", f.GetName())
 	if !equality.Semantic.DeepEqual(old.Spec, f.Spec) && !wh.updatesAllowed {
-		return nil, fmt.Errorf("features are immutable in production")
+		sandbox, err := IsSandboxNamespace(ctx, wh.client, f.GetNamespace())
+		if err != nil {
+			return nil, err
+		}
+		if !sandbox {
+			return nil, fmt.Errorf("features are immutable in production")
+		}
 	}
 
 	return wh.Validate(ctx, f)
@@ -155,7 +248,38 @@ func (wh *webhook) ValidateUpdate(ctx context.Context, oldObject, newObj runtime
 func (wh *webhook) Validate(ctx context.Context, f *manifests.Feature) (admission.Warnings, error) {
 	dummyEngine := engine.Dummy{RuntimeManager: wh.runtimeManager}
 
+	if err := api.ValidateFQN(f.FQN()); err != nil {
+		return nil, fmt.Errorf("the feature's name doesn't conform to the naming scheme: %w", err)
+	}
+
+	if wh.sandbox.MaxStaleness > 0 && f.Spec.Staleness.Duration > wh.sandbox.MaxStaleness {
+		sandbox, err := IsSandboxNamespace(ctx, wh.client, f.GetNamespace())
+		if err != nil {
+			return nil, err
+		}
+		if sandbox {
+			return nil, fmt.Errorf("the staleness of features in sandbox namespaces is limited to %s", wh.sandbox.MaxStaleness)
+		}
+	}
+
+	if f.Spec.Entity != "" {
+		if ar, ok := ctx.Value(admissionRequestContextKey).(admission.Request); ok && ar.DryRun == nil || ok && !*ar.DryRun {
+			entity, err := wh.entity(ctx, f)
+			if err != nil {
+				return nil, err
+			}
+			if err := validateEntityKeys(f.Spec.Keys, entity); err != nil {
+				return nil, err
+			}
+		}
+	} else if len(f.Spec.Keys) == 0 {
+		return nil, fmt.Errorf("the feature must have `keys` or an `entity`")
+	}
+
 	if f.Spec.DataSource != nil {
+		if err := tenancy.CheckAccess(ctx, wh.client, f.GetNamespace(), *f.Spec.DataSource); err != nil {
+			return nil, err
+		}
 		if ar, ok := ctx.Value(admissionRequestContextKey).(admission.Request); ok && ar.DryRun == nil || ok && !*ar.DryRun {
 			src := manifests.DataSource{}
 			err := wh.client.Get(ctx, f.Spec.DataSource.ObjectKey(), &src)
@@ -173,8 +297,114 @@ func (wh *webhook) Validate(ctx context.Context, f *manifests.Feature) (admissio
 			dummyEngine.DataSource = dci
 		}
 	}
-	_, err := engine.FeatureWithEngine(&dummyEngine, f)
-	return nil, err
+	if err := ValidateExpression(f); err != nil {
+		return nil, fmt.Errorf("the builder's expression is invalid: %w", err)
+	}
+	if _, err := engine.FeatureWithEngine(&dummyEngine, f); err != nil {
+		return nil, err
+	}
+	if ar, ok := ctx.Value(admissionRequestContextKey).(admission.Request); ok && ar.DryRun == nil || ok && !*ar.DryRun {
+		if err := wh.validateDependencies(ctx, f, dummyEngine.Dependencies); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// entity returns the Entity the feature is keyed by.
+func (wh *webhook) entity(ctx context.Context, f *manifests.Feature) (*manifests.Entity, error) {
+	entity := &manifests.Entity{}
+	err := wh.client.Get(ctx, client.ObjectKey{Namespace: f.GetNamespace(), Name: f.Spec.Entity}, entity)
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("Entity %s/%s not found", f.GetNamespace(), f.Spec.Entity)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Entity: %w", err)
+	}
+	return entity, nil
+}
+
+// validateEntityKeys verifies that the feature is keyed by exactly the keys of its entity, so its values are joined
+// by the same keys as the other features of the entity.
+func validateEntityKeys(keys []string, entity *manifests.Entity) error {
+	names := entity.KeyNames()
+	mismatch := fmt.Errorf("the keys %v of the feature don't match the keys %v of Entity %s", keys, names, entity.GetName())
+	if len(keys) != len(names) {
+		return mismatch
+	}
+	set := make(map[string]bool, len(names))
+	for _, k := range names {
+		set[k] = true
+	}
+	for _, k := range keys {
+		if !set[k] {
+			return mismatch
+		}
+		delete(set, k)
+	}
+	return nil
+}
+
+// validateDependencies verifies that the features (or models) the builder's program depends on exist, and are
+// accessible by the feature's namespace.
+func (wh *webhook) validateDependencies(ctx context.Context, f *manifests.Feature, deps []string) error {
+	_, self, _, _, _, _ := api.ParseSelector(f.FQN())
+	listed := make(map[string]map[string]bool)
+	for _, dep := range deps {
+		ns, name, _, _, _, err := api.ParseSelector(dep)
+		if err != nil {
+			return fmt.Errorf("invalid dependency %s: %w", dep, err)
+		}
+		if ns == "" {
+			ns = f.GetNamespace()
+		}
+		if ns == f.GetNamespace() && name == self {
+			continue
+		}
+		ref := manifests.ResourceReference{Namespace: ns, Name: name}
+		if err := tenancy.CheckAccess(ctx, wh.client, f.GetNamespace(), ref); err != nil {
+			return err
+		}
+
+		// the dependencies are referenced by their FQN, so they're looked up by the FQN of the resources
+		names, ok := listed[ns]
+		if !ok {
+			names, err = wh.resourceNames(ctx, ns)
+			if err != nil {
+				return err
+			}
+			listed[ns] = names
+		}
+		if !names[name] {
+			return fmt.Errorf("the builder depends on %s, but there's no Feature or Model %s in namespace %s", dep, name, ns)
+		}
+	}
+	return nil
+}
+
+// resourceNames returns the names (as they're parsed from their FQN) of the Features and Models of the namespace.
+func (wh *webhook) resourceNames(ctx context.Context, ns string) (map[string]bool, error) {
+	names := make(map[string]bool)
+	add := func(fqn string) {
+		if _, name, _, _, _, err := api.ParseSelector(fqn); err == nil {
+			names[name] = true
+		}
+	}
+	features := manifests.FeatureList{}
+	if err := wh.client.List(ctx, &features, client.InNamespace(ns)); err != nil {
+		return nil, fmt.Errorf("failed to list the features of namespace %s: %w", ns, err)
+	}
+	for _, ft := range features.Items {
+		add(ft.FQN())
+	}
+	models := manifests.ModelList{}
+	if err := wh.client.List(ctx, &models, client.InNamespace(ns)); err != nil {
+		return nil, fmt.Errorf("failed to list the models of namespace %s: %w", ns, err)
+	}
+	for _, m := range models.Items {
+		add(m.FQN())
+	}
+	return names, nil
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=featureseeds,verbs=get;list;watch
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=featureseeds/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"time"
+)
+
+// FeatureSeedReconciler loads the sample values of FeatureSeed objects into the online store.
+// It should be set up only when seeding is enabled (dev/staging environments).
+type FeatureSeedReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Engine        api.Engine
+	Environment   string
+	EventRecorder record.EventRecorder
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.0/pkg/reconcile
+func (r *FeatureSeedReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("component", "featureseed-operator")
+
+	seed := &manifests.FeatureSeed{}
+	if err := r.Get(ctx, req.NamespacedName, seed); err != nil {
+		// we'll ignore not-found errors, since they can't be fixed by an immediate requeue
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !seed.DeletionTimestamp.IsZero() {
+		// seeded values are left in the store, and expire according to the Feature's staleness
+		return ctrl.Result{}, nil
+	}
+	if seed.Status.ObservedGeneration == seed.Generation && seed.Status.Environment == r.Environment {
+		return ctrl.Result{}, nil
+	}
+
+	if !r.environmentAllowed(seed) {
+		logger.V(1).Info("skipping seed of another environment", "environment", r.Environment)
+		return ctrl.Result{}, r.updateStatus(ctx, seed, 0,
+			fmt.Sprintf("skipped: environment `%s` is not listed in the seed's environments", r.Environment))
+	}
+
+	ref := seed.FeatureReference()
+	ft := &manifests.Feature{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, ft); err != nil {
+		logger.Error(err, "Failed to get Feature", "feature", ref)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, client.IgnoreNotFound(err)
+	}
+	fd, err := r.Engine.FeatureDescriptor(ctx, ft.FQN())
+	if err != nil {
+		// the feature might not be bound to the engine yet
+		logger.V(1).Info("feature is not ready yet", "feature", ft.FQN(), "error", err.Error())
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	logger = logger.WithValues("feature", fd.FQN)
+
+	seeded := 0
+	now := time.Now()
+	for i, v := range seed.Spec.Values {
+		val, err := seedValue(v.Value.Raw, fd.Primitive)
+		if err != nil {
+			err = fmt.Errorf("invalid value #%d: %w", i, err)
+			r.EventRecorder.Event(seed, "Warning", "SeedFailed", err.Error())
+			return ctrl.Result{}, r.updateStatus(ctx, seed, seeded, err.Error())
+		}
+
+		ts := now
+		if v.Timestamp != nil {
+			ts = v.Timestamp.Time
+		}
+		if err := r.Engine.Set(ctx, fd.FQN, v.Keys, val, ts); err != nil {
+			logger.Error(err, "Failed to seed value", "keys", v.Keys)
+			r.EventRecorder.Eventf(seed, "Warning", "SeedFailed", "Failed to seed value #%d: %v", i, err)
+			return ctrl.Result{}, err
+		}
+		seeded++
+	}
+
+	r.EventRecorder.Eventf(seed, "Normal", "Seeded", "Seeded %d values of %s", seeded, fd.FQN)
+	return ctrl.Result{}, r.updateStatus(ctx, seed, seeded, "")
+}
+
+func (r *FeatureSeedReconciler) environmentAllowed(seed *manifests.FeatureSeed) bool {
+	if len(seed.Spec.Environments) == 0 {
+		return true
+	}
+	for _, env := range seed.Spec.Environments {
+		if env == r.Environment {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *FeatureSeedReconciler) updateStatus(ctx context.Context, seed *manifests.FeatureSeed, seeded int, msg string) error {
+	now := metav1.Now()
+	seed.Status.ObservedGeneration = seed.Generation
+	seed.Status.Environment = r.Environment
+	seed.Status.Seeded = seeded
+	seed.Status.Message = msg
+	if seeded > 0 {
+		seed.Status.SeededAt = &now
+	}
+	if err := r.Status().Update(ctx, seed); err != nil {
+		return fmt.Errorf("failed to update FeatureSeed status: %w", err)
+	}
+	return nil
+}
+
+// seedValue converts the JSON value of a seed to the Feature's primitive.
+func seedValue(raw []byte, primitive api.PrimitiveType) (any, error) {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, fmt.Errorf("failed to parse value: %w", err)
+	}
+	if v == nil {
+		return nil, fmt.Errorf("value is empty")
+	}
+	return api.FromJSONValue(v, primitive)
+}
+
+// SetupWithManager sets up the controller with the Controller Manager.
+func (r *FeatureSeedReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&manifests.FeatureSeed{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
@@ -0,0 +1,165 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=features,verbs=get;list;watch
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=features/status,verbs=get;update;patch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/internal/stats"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"time"
+)
+
+const (
+	// minFreshnessRequeue and maxFreshnessRequeue bound the time between two checks of a feature's freshness.
+	minFreshnessRequeue = 10 * time.Second
+	maxFreshnessRequeue = time.Hour
+)
+
+// FreshnessReconciler tracks the freshness SLO of features that are updated by a pipeline (i.e. from a DataSource).
+// A feature is stale when it wasn't updated for longer than its freshness times the Tolerance. The staleness is
+// reported by the `Stale` condition of the Feature's status, and by Prometheus metrics so it can be alerted on.
+type FreshnessReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Engine        api.ManagerEngine
+	Tolerance     float64
+	EventRecorder record.EventRecorder
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.0/pkg/reconcile
+func (r *FreshnessReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("component", "freshness-operator")
+
+	ft := &manifests.Feature{}
+	if err := r.Get(ctx, req.NamespacedName, ft); err != nil {
+		if apierrors.IsNotFound(err) {
+			stats.DeleteFeatureStaleness(manifests.FQNFormatter(req.Namespace, req.Name))
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !ft.DeletionTimestamp.IsZero() {
+		stats.DeleteFeatureStaleness(ft.FQN())
+		return ctrl.Result{}, nil
+	}
+
+	fd, err := r.Engine.FeatureDescriptor(ctx, ft.FQN())
+	if errors.Is(err, api.ErrFeatureNotFound) {
+		// the feature isn't bound yet
+		return ctrl.Result{RequeueAfter: minFreshnessRequeue}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if fd.DataSource == "" || fd.Freshness <= 0 || fd.Virtual() {
+		// features that are computed on read have no pipeline that can break
+		return ctrl.Result{}, nil
+	}
+
+	tracker, ok := r.Engine.(api.FreshnessTracker)
+	if !ok {
+		return ctrl.Result{}, fmt.Errorf("the engine doesn't support freshness tracking")
+	}
+	lastUpdate, err := tracker.FeatureLastUpdate(ctx, fd.FQN)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get the last update of the feature: %w", err)
+	}
+
+	slo := time.Duration(float64(fd.Freshness) * r.Tolerance)
+	since := lastUpdate
+	if created := ft.GetCreationTimestamp().Time; created.After(since) {
+		// new features have a grace period of one SLO to receive their first update
+		since = created
+	}
+	staleness := time.Since(since)
+	stale := staleness > slo
+	stats.SetFeatureStaleness(fd.FQN, staleness, stale)
+
+	cond := metav1.Condition{
+		Type:               manifests.FeatureConditionStale,
+		Status:             metav1.ConditionFalse,
+		Reason:             "UpdatedWithinSLO",
+		Message:            fmt.Sprintf("The feature is updated within its freshness SLO (%s)", slo),
+		ObservedGeneration: ft.GetGeneration(),
+	}
+	if stale {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "FreshnessSLOViolated"
+		if lastUpdate.IsZero() {
+			cond.Reason = "NeverUpdated"
+			cond.Message = fmt.Sprintf("The feature wasn't updated since it was created, for longer than its freshness SLO (%s)", slo)
+		} else {
+			cond.Message = fmt.Sprintf("The feature wasn't updated since %s, for longer than its freshness SLO (%s)", lastUpdate.Format(time.RFC3339), slo)
+		}
+	}
+
+	// the status is patched only when the condition changes, so the other controllers of the Feature aren't triggered
+	// by every check
+	if prev := meta.FindStatusCondition(ft.Status.Conditions, cond.Type); prev == nil || prev.Status != cond.Status || prev.ObservedGeneration != cond.ObservedGeneration {
+		patch := client.MergeFrom(ft.DeepCopy())
+		meta.SetStatusCondition(&ft.Status.Conditions, cond)
+		if err := r.Status().Patch(ctx, ft, patch); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		if stale {
+			logger.Info("feature is stale", "feature", fd.FQN, "lastUpdate", lastUpdate, "slo", slo)
+			r.EventRecorder.Event(ft, "Warning", cond.Reason, cond.Message)
+		} else if prev != nil && prev.Status == metav1.ConditionTrue {
+			r.EventRecorder.Event(ft, "Normal", cond.Reason, cond.Message)
+		}
+	}
+
+	// check again when the feature becomes stale, or periodically to detect its recovery
+	requeue := slo - staleness
+	if stale {
+		requeue = fd.Freshness
+	}
+	if requeue < minFreshnessRequeue {
+		requeue = minFreshnessRequeue
+	}
+	if requeue > maxFreshnessRequeue {
+		requeue = maxFreshnessRequeue
+	}
+	return ctrl.Result{RequeueAfter: requeue}, nil
+}
+
+// SetupWithManager sets up the controller with the Controller Manager.
+func (r *FreshnessReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("freshness").
+		For(&manifests.Feature{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
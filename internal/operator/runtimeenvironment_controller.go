@@ -0,0 +1,400 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=runtimeenvironments,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=runtimeenvironments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=runtimeenvironments/finalizers,verbs=update
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"strings"
+	"time"
+)
+
+const (
+	// coreDeploymentName is the name of the Core's deployment, which the runtimes of the RuntimeEnvironments are
+	// attached to.
+	coreDeploymentName = "raptor-controller-core"
+	// runtimeBuildRequeue is the time between two checks of a build of a RuntimeEnvironment's image.
+	runtimeBuildRequeue = 15 * time.Second
+	// runtimeBuildTTL is the time a finished build Job is kept before it's garbage collected.
+	runtimeBuildTTL = int32(24 * 60 * 60)
+)
+
+// RuntimeEnvironmentConfig is the configuration of the builds of the RuntimeEnvironments' images.
+type RuntimeEnvironmentConfig struct {
+	// Namespace is the system namespace, where the Core is deployed and the images are built.
+	Namespace string
+	// Registry is the container registry the images are pushed to (i.e. `ghcr.io/acme`).
+	Registry string
+	// RegistrySecret is the name of a `kubernetes.io/dockerconfigjson` Secret (in the system namespace) with the
+	// credentials to push to the registry. Optional.
+	RegistrySecret string
+	// BuilderImage is the image of the kaniko executor that builds the images.
+	BuilderImage string
+}
+
+// RuntimeEnvironmentReconciler bakes the requirements of RuntimeEnvironments into runtime images, and attaches them
+// to the Core as runtimes, so the Features of the environment's namespace can use them.
+//
+// The images are built upon the Core's default runtime (unless a base image is specified) by a kaniko Job, and the
+// runtimes are attached as sidecars of the Core's deployment, so the Core (and the runners of the DataSources,
+// which inherit its runtimes) is restarted when an environment is changed.
+type RuntimeEnvironmentReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Config        RuntimeEnvironmentConfig
+	EventRecorder record.EventRecorder
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.0/pkg/reconcile
+func (r *RuntimeEnvironmentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("component", "runtimeenvironment-operator")
+
+	env := &manifests.RuntimeEnvironment{}
+	if err := r.Get(ctx, req.NamespacedName, env); err != nil {
+		// we'll ignore not-found errors, since they can't be fixed by an immediate requeue
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	logger = logger.WithValues("environment", env.RuntimeName())
+
+	core := &appsv1.Deployment{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.Config.Namespace, Name: coreDeploymentName}, core); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get the Core deployment: %w", err)
+	}
+
+	if !env.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(env, finalizerName) {
+			if err := r.detach(ctx, core, env); err != nil {
+				logger.Error(err, "Failed to detach the runtime from the Core")
+				return ctrl.Result{}, err
+			}
+			controllerutil.RemoveFinalizer(env, finalizerName)
+			if err := r.Update(ctx, env); err != nil {
+				logger.Error(err, "Failed to remove finalizer")
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+	if !controllerutil.ContainsFinalizer(env, finalizerName) {
+		controllerutil.AddFinalizer(env, finalizerName)
+		if err := r.Update(ctx, env); err != nil {
+			logger.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+
+	base, ok := defaultRuntime(core.Spec.Template.Spec)
+	if !ok {
+		return ctrl.Result{}, fmt.Errorf("the Core deployment has no default runtime")
+	}
+	baseImage := env.Spec.BaseImage
+	if baseImage == "" {
+		baseImage = base.Image
+	}
+	digest := r.digest(env, baseImage)
+	image := fmt.Sprintf("%s/raptor-runtime-%s:%s", strings.TrimSuffix(r.Config.Registry, "/"), env.RuntimeName(),
+		digest)
+
+	if env.Status.Image != image || env.Status.Phase != manifests.RuntimeEnvironmentReady {
+		job, err := r.build(ctx, env, digest, baseImage, image)
+		if err != nil {
+			logger.Error(err, "Failed to build the image")
+			return ctrl.Result{}, err
+		}
+		switch {
+		case job.Status.Succeeded > 0:
+			logger.Info("the image of the environment was built", "image", image)
+			r.EventRecorder.Eventf(env, "Normal", "Built", "The image %s was built", image)
+			if err := r.updateStatus(ctx, env, manifests.RuntimeEnvironmentReady, image, ""); err != nil {
+				return ctrl.Result{}, err
+			}
+		case jobFailed(job):
+			msg := fmt.Sprintf("the build Job %s/%s failed", job.GetNamespace(), job.GetName())
+			r.EventRecorder.Event(env, "Warning", "BuildFailed", msg)
+			// the build is retried when the environment is changed
+			return ctrl.Result{}, r.updateStatus(ctx, env, manifests.RuntimeEnvironmentFailed, env.Status.Image, msg)
+		default:
+			if err := r.updateStatus(ctx, env, manifests.RuntimeEnvironmentBuilding, env.Status.Image, ""); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: runtimeBuildRequeue}, nil
+		}
+	}
+
+	if err := r.attach(ctx, core, base, env); err != nil {
+		logger.Error(err, "Failed to attach the runtime to the Core")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// digest identifies the content of the environment's image.
+func (r *RuntimeEnvironmentReconciler) digest(env *manifests.RuntimeEnvironment, baseImage string) string {
+	h := sha256.New()
+	h.Write([]byte(env.RuntimeName() + "\n" + baseImage + "\n"))
+	h.Write([]byte(strings.Join(env.Spec.Requirements, "\n")))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// build returns the Job that builds the image, and creates it (alongside its build context) if it doesn't exist.
+func (r *RuntimeEnvironmentReconciler) build(ctx context.Context, env *manifests.RuntimeEnvironment, digest,
+	baseImage, image string) (*batchv1.Job, error) {
+	key := client.ObjectKey{Namespace: r.Config.Namespace, Name: fmt.Sprintf("runtime-build-%s", digest)}
+	job := &batchv1.Job{}
+	err := r.Get(ctx, key, job)
+	if err == nil || !k8serrors.IsNotFound(err) {
+		return job, err
+	}
+
+	labels := map[string]string{
+		"app.kubernetes.io/name":       "runtime-environment-build",
+		"app.kubernetes.io/part-of":    "raptor",
+		"app.kubernetes.io/managed-by": "raptor-controller",
+		"raptor.ml/runtime":            env.RuntimeName(),
+	}
+	dockerfile := fmt.Sprintf(`FROM %s
+USER root
+COPY requirements.txt /runtime/environment-requirements.txt
+RUN pip install --no-cache-dir -r /runtime/environment-requirements.txt
+USER 65532:65532
+ENV RUNTIME_ISOLATED=true
+`, baseImage)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Labels: labels},
+		Data: map[string]string{
+			"Dockerfile":       dockerfile,
+			"requirements.txt": strings.Join(env.Spec.Requirements, "\n") + "\n",
+		},
+	}
+	if err := r.Create(ctx, cm); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return nil, fmt.Errorf("failed to create the build context: %w", err)
+	}
+
+	backoff := int32(2)
+	ttl := runtimeBuildTTL
+	container := corev1.Container{
+		Name:  "build",
+		Image: r.Config.BuilderImage,
+		Args: []string{
+			"--context=dir:///workspace",
+			"--dockerfile=/workspace/Dockerfile",
+			fmt.Sprintf("--destination=%s", image),
+		},
+		VolumeMounts: []corev1.VolumeMount{{Name: "workspace", MountPath: "/workspace"}},
+	}
+	volumes := []corev1.Volume{{
+		Name: "workspace",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name}},
+		},
+	}}
+	if r.Config.RegistrySecret != "" {
+		container.VolumeMounts = append(container.VolumeMounts,
+			corev1.VolumeMount{Name: "docker-config", MountPath: "/kaniko/.docker"})
+		volumes = append(volumes, corev1.Volume{
+			Name: "docker-config",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName: r.Config.RegistrySecret,
+				Items:      []corev1.KeyToPath{{Key: corev1.DockerConfigJsonKey, Path: "config.json"}},
+			}},
+		})
+	}
+
+	job = &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Labels: labels},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoff,
+			TTLSecondsAfterFinished: &ttl,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers:    []corev1.Container{container},
+					Volumes:       volumes,
+				},
+			},
+		},
+	}
+	if err := r.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create the build Job: %w", err)
+	}
+
+	// the build context is removed alongside the Job
+	if err := controllerutil.SetOwnerReference(job, cm, r.Scheme); err != nil {
+		return nil, err
+	}
+	if err := r.Update(ctx, cm); err != nil {
+		return nil, fmt.Errorf("failed to update the build context: %w", err)
+	}
+	return job, nil
+}
+
+func jobFailed(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// attach adds the environment's runtime to the Core's deployment, or updates it.
+func (r *RuntimeEnvironmentReconciler) attach(ctx context.Context, core *appsv1.Deployment, base corev1.Container,
+	env *manifests.RuntimeEnvironment) error {
+	c := *base.DeepCopy()
+	c.Name = truncate("runtime-"+env.RuntimeName(), 63)
+	c.Image = env.Status.Image
+	c.Env = []corev1.EnvVar{{Name: "RUNTIME_NAME", Value: env.RuntimeName()}, {Name: "RUNTIME_ISOLATED", Value: "true"}}
+	for _, e := range base.Env {
+		if e.Name != "RUNTIME_NAME" && e.Name != "RUNTIME_ISOLATED" {
+			c.Env = append(c.Env, e)
+		}
+	}
+
+	containers := core.Spec.Template.Spec.Containers
+	if i := runtimeIndex(containers, env.RuntimeName()); i >= 0 {
+		if equality.Semantic.DeepEqual(containers[i], c) {
+			return nil
+		}
+		containers[i] = c
+	} else {
+		core.Spec.Template.Spec.Containers = append(containers, c)
+	}
+	if err := r.Update(ctx, core); err != nil {
+		return fmt.Errorf("failed to update the Core deployment: %w", err)
+	}
+	r.EventRecorder.Eventf(env, "Normal", "Attached", "The runtime %s is attached to the Core", env.RuntimeName())
+	return nil
+}
+
+// detach removes the environment's runtime from the Core's deployment.
+func (r *RuntimeEnvironmentReconciler) detach(ctx context.Context, core *appsv1.Deployment,
+	env *manifests.RuntimeEnvironment) error {
+	containers := core.Spec.Template.Spec.Containers
+	i := runtimeIndex(containers, env.RuntimeName())
+	if i < 0 {
+		return nil
+	}
+	core.Spec.Template.Spec.Containers = append(containers[:i], containers[i+1:]...)
+	if err := r.Update(ctx, core); err != nil {
+		return fmt.Errorf("failed to update the Core deployment: %w", err)
+	}
+	return nil
+}
+
+func (r *RuntimeEnvironmentReconciler) updateStatus(ctx context.Context, env *manifests.RuntimeEnvironment,
+	phase manifests.RuntimeEnvironmentPhase, image, message string) error {
+	status := manifests.RuntimeEnvironmentStatus{
+		ObservedGeneration: env.Generation,
+		Phase:              phase,
+		Image:              image,
+		Message:            message,
+	}
+	if env.Status == status {
+		return nil
+	}
+	env.Status = status
+	if err := r.Status().Update(ctx, env); err != nil {
+		return fmt.Errorf("failed to update RuntimeEnvironment status: %w", err)
+	}
+	return nil
+}
+
+// defaultRuntime returns the runtime container the environments are based on: the `default` runtime, or the first
+// runtime of the Core that isn't of a RuntimeEnvironment.
+func defaultRuntime(pod corev1.PodSpec) (corev1.Container, bool) {
+	var first *corev1.Container
+	for i, c := range pod.Containers {
+		name, isolated := "", false
+		for _, e := range c.Env {
+			switch e.Name {
+			case "RUNTIME_NAME":
+				name = e.Value
+			case "RUNTIME_ISOLATED":
+				isolated = e.Value == "true"
+			}
+		}
+		if name == "" || isolated {
+			continue
+		}
+		if name == "default" {
+			return c, true
+		}
+		if first == nil {
+			first = &pod.Containers[i]
+		}
+	}
+	if first == nil {
+		return corev1.Container{}, false
+	}
+	return *first, true
+}
+
+// runtimeIndex returns the index of the runtime container, or -1 if there's none.
+func runtimeIndex(containers []corev1.Container, name string) int {
+	for i, c := range containers {
+		for _, e := range c.Env {
+			if e.Name == "RUNTIME_NAME" && e.Value == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// SetupWithManager sets up the controller with the Controller Manager.
+func (r *RuntimeEnvironmentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&manifests.RuntimeEnvironment{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
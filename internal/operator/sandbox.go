@@ -0,0 +1,49 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+// SandboxLabel is the label that designates a namespace as a sandbox for experimental features.
+// Features in sandbox namespaces are excluded from the historical storage by default, limited by the
+// SandboxConfig quotas, and removed (alongside their stored values) once they weren't read for SandboxConfig.TTL.
+const SandboxLabel = "k8s.raptor.ml/sandbox"
+
+// SandboxConfig is the configuration of the sandbox namespaces.
+type SandboxConfig struct {
+	// TTL is the time a feature can be left unread before it's removed. Zero disables the cleanup.
+	TTL time.Duration
+	// MaxFeatures is the maximum number of features in a sandbox namespace. Zero means unlimited.
+	MaxFeatures int
+	// MaxStaleness is the maximum staleness of features in a sandbox namespace. Zero means unlimited.
+	MaxStaleness time.Duration
+}
+
+// IsSandboxNamespace checks if the namespace is labeled as a sandbox.
+func IsSandboxNamespace(ctx context.Context, c client.Reader, namespace string) (bool, error) {
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return false, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+	return ns.GetLabels()[SandboxLabel] == "true", nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=features,verbs=get;list;watch;delete
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
+)
+
+// maxSandboxRequeue is the maximal time between two checks of a sandbox feature.
+const maxSandboxRequeue = time.Hour
+
+// SandboxReconciler removes features of sandbox namespaces that weren't read for the configured TTL,
+// and purges their values from the state.
+type SandboxReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Engine        api.ManagerEngine
+	Config        SandboxConfig
+	EventRecorder record.EventRecorder
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.0/pkg/reconcile
+func (r *SandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("component", "sandbox-operator")
+
+	ft := &manifests.Feature{}
+	if err := r.Get(ctx, req.NamespacedName, ft); err != nil {
+		// we'll ignore not-found errors, since they can't be fixed by an immediate requeue
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !ft.DeletionTimestamp.IsZero() || metav1.GetControllerOf(ft) != nil {
+		// owned features (i.e. of Models) are recreated by their owner, so they are left for it to manage
+		return ctrl.Result{}, nil
+	}
+
+	sandbox, err := IsSandboxNamespace(ctx, r.Client, ft.GetNamespace())
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !sandbox {
+		return ctrl.Result{}, nil
+	}
+
+	tracker, ok := r.Engine.(api.UsageTracker)
+	if !ok {
+		return ctrl.Result{}, fmt.Errorf("the engine doesn't support usage tracking")
+	}
+	lastUsed, err := tracker.FeatureLastRead(ctx, ft.FQN())
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get the last read of the feature: %w", err)
+	}
+	if created := ft.GetCreationTimestamp().Time; created.After(lastUsed) {
+		lastUsed = created
+	}
+
+	if remaining := time.Until(lastUsed.Add(r.Config.TTL)); remaining > 0 {
+		if remaining > maxSandboxRequeue {
+			remaining = maxSandboxRequeue
+		}
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	logger.Info("removing an expired sandbox feature", "feature", ft.FQN(), "lastUsed", lastUsed)
+	r.EventRecorder.Eventf(ft, "Normal", "SandboxExpired",
+		"The feature wasn't read since %s and is removed from the sandbox", lastUsed.Format(time.RFC3339))
+	if err := r.Delete(ctx, ft); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if p, ok := r.Engine.(api.Purger); ok {
+		if err := p.PurgeFeature(ctx, ft.FQN()); err != nil {
+			logger.Error(err, "Failed to purge the feature's values", "feature", ft.FQN())
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Controller Manager.
+func (r *SandboxReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sandbox").
+		For(&manifests.Feature{}).
+		Complete(r)
+}
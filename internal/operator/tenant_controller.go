@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=tenants,verbs=get;list;watch
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=tenants/status,verbs=get;update;patch
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/internal/stats"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"time"
+)
+
+// DefaultTenantUsageInterval is the default time between two measurements of the usage of a tenant.
+const DefaultTenantUsageInterval = 5 * time.Minute
+
+// TenantReconciler measures the usage of the tenants: the number of features in their namespaces, and the memory their
+// values take in the state store. The usage is reported in the Tenant's status and by Prometheus metrics, and the
+// `QuotaExceeded` condition is set once the state memory quota is exceeded, so the Core's instances reject the writes
+// to the tenant's features (see `internal/engine/controllers/tenant_controller.go`).
+type TenantReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Engine        api.ManagerEngine
+	Interval      time.Duration
+	EventRecorder record.EventRecorder
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.0/pkg/reconcile
+func (r *TenantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("component", "tenant-operator")
+
+	tenant := &manifests.Tenant{}
+	if err := r.Get(ctx, req.NamespacedName, tenant); err != nil {
+		if apierrors.IsNotFound(err) {
+			stats.DeleteTenantUsage(req.Name)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !tenant.DeletionTimestamp.IsZero() {
+		stats.DeleteTenantUsage(tenant.GetName())
+		return ctrl.Result{}, nil
+	}
+
+	var fqns []string
+	for _, ns := range tenant.Spec.Namespaces {
+		features := manifests.FeatureList{}
+		if err := r.List(ctx, &features, client.InNamespace(ns)); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to list the features of the tenant: %w", err)
+		}
+		for _, ft := range features.Items {
+			fqns = append(fqns, ft.FQN())
+		}
+	}
+
+	var memory int64
+	if estimator, ok := r.Engine.(api.MemoryUsageEstimator); ok {
+		for _, fqn := range fqns {
+			n, err := estimator.FeatureMemoryUsage(ctx, fqn)
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to estimate the memory usage of the tenant: %w", err)
+			}
+			memory += n
+		}
+	}
+
+	quota := tenant.Spec.Quota
+	var maxMemory int64
+	if quota.MaxStateMemory != nil {
+		maxMemory = quota.MaxStateMemory.Value()
+	}
+	stats.SetTenantUsage(tenant.GetName(), int64(len(fqns)), memory, int64(quota.MaxFeatures), maxMemory)
+
+	cond := metav1.Condition{
+		Type:               manifests.TenantConditionQuotaExceeded,
+		Status:             metav1.ConditionFalse,
+		Reason:             "WithinQuota",
+		Message:            "The tenant is within its state memory quota",
+		ObservedGeneration: tenant.GetGeneration(),
+	}
+	if maxMemory > 0 && memory >= maxMemory {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "StateMemoryQuotaExceeded"
+		cond.Message = fmt.Sprintf("The values of the tenant's features take %s, which exceeds the state memory quota of %s",
+			resource.NewQuantity(memory, resource.BinarySI), quota.MaxStateMemory)
+	}
+	prev := meta.FindStatusCondition(tenant.Status.Conditions, cond.Type)
+	changed := prev == nil || prev.Status != cond.Status
+
+	patch := client.MergeFrom(tenant.DeepCopy())
+	tenant.Status.Features = int32(len(fqns))
+	tenant.Status.StateMemory = resource.NewQuantity(memory, resource.BinarySI)
+	meta.SetStatusCondition(&tenant.Status.Conditions, cond)
+	if err := r.Status().Patch(ctx, tenant, patch); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if changed && cond.Status == metav1.ConditionTrue {
+		logger.Info("tenant exceeded its state memory quota", "tenant", tenant.GetName(), "memory", memory)
+		r.EventRecorder.Event(tenant, "Warning", cond.Reason, cond.Message)
+	} else if changed && prev != nil {
+		r.EventRecorder.Event(tenant, "Normal", cond.Reason, cond.Message)
+	}
+
+	return ctrl.Result{RequeueAfter: r.interval()}, nil
+}
+
+func (r *TenantReconciler) interval() time.Duration {
+	if r.Interval <= 0 {
+		return DefaultTenantUsageInterval
+	}
+	return r.Interval
+}
+
+// SetupWithManager sets up the controller with the Controller Manager.
+func (r *TenantReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("tenant").
+		For(&manifests.Tenant{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// featureImpactRules are the impacts of changing a field of a feature (or any of its sub-fields), on top of rebinding it.
+var featureImpactRules = []struct {
+	field    string
+	windowed bool
+	impacts  []Impact
+}{
+	{field: "primitive", impacts: []Impact{ImpactStateReset, ImpactHistoricalMigration}},
+	{field: "keys", impacts: []Impact{ImpactStateReset, ImpactHistoricalMigration}},
+	{field: "builder.aggr", windowed: true, impacts: []Impact{ImpactWindowReset, ImpactHistoricalMigration}},
+	{field: "builder.aggrGranularity", windowed: true, impacts: []Impact{ImpactWindowReset}},
+	{field: "builder.aggrSessionGap", windowed: true, impacts: []Impact{ImpactWindowReset}},
+	{field: "freshness", windowed: true, impacts: []Impact{ImpactWindowReset}},
+}
+
+func featureImpacts(fields []string, windowed bool) []Impact {
+	ret := []Impact{ImpactRebind}
+	for _, f := range fields {
+		for _, rule := range featureImpactRules {
+			if rule.windowed && !windowed {
+				continue
+			}
+			if f != rule.field && !strings.HasPrefix(f, rule.field+".") {
+				continue
+			}
+			for _, i := range rule.impacts {
+				if !hasImpact(ret, i) {
+					ret = append(ret, i)
+				}
+			}
+		}
+	}
+	return ret
+}
+
+func hasImpact(impacts []Impact, impact Impact) bool {
+	for _, i := range impacts {
+		if i == impact {
+			return true
+		}
+	}
+	return false
+}
+
+// diffFields returns the (dot separated) paths of the fields that are different between the specs.
+func diffFields(old, new any) ([]string, error) {
+	om, err := toMap(old)
+	if err != nil {
+		return nil, err
+	}
+	nm, err := toMap(new)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []string
+	diffMaps("", om, nm, &ret)
+	sort.Strings(ret)
+	return ret, nil
+}
+
+func diffMaps(prefix string, a, b map[string]any, ret *[]string) {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	for k := range keys {
+		av, bv := a[k], b[k]
+		am, aok := av.(map[string]any)
+		bm, bok := bv.(map[string]any)
+		if aok && bok {
+			diffMaps(prefix+k+".", am, bm, ret)
+			continue
+		}
+		if !reflect.DeepEqual(av, bv) {
+			*ret = append(*ret, prefix+k)
+		}
+	}
+}
+
+func toMap(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]any{}
+	return m, json.Unmarshal(b, &m)
+}
@@ -0,0 +1,331 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plan reports what would change in the running engine if a set of manifests was applied, before applying
+// them. It's the "plan" step of a plan/apply workflow for feature definitions.
+//
+// Each manifest is compared to the object that is currently in the cluster, and the changed fields are translated to
+// their impact on the engine: features that are rebound, windows that are reset, online values that are dropped,
+// historical records that need a migration, and the Models (feature sets) and features that depend on them.
+package plan
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/internal/operator"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+)
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=features;datasources;models,verbs=get;list;watch
+
+// Action is the action that applying a manifest would take.
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionUnchanged Action = "unchanged"
+)
+
+// Impact is a side effect of applying a manifest on the running engine.
+type Impact string
+
+const (
+	// ImpactRebind means the feature is unbound and bound again with the new definition.
+	ImpactRebind Impact = "rebind"
+	// ImpactWindowReset means the window buckets that were aggregated so far are not used by the new definition.
+	ImpactWindowReset Impact = "window-reset"
+	// ImpactStateReset means the online values that are stored are incompatible with the new definition.
+	ImpactStateReset Impact = "state-reset"
+	// ImpactHistoricalMigration means the records in the historical storage don't match the new definition.
+	ImpactHistoricalMigration Impact = "historical-migration"
+	// ImpactRunnerRestart means the runner of the DataSource is redeployed.
+	ImpactRunnerRestart Impact = "runner-restart"
+)
+
+// Reference is an object that is affected by a change.
+type Reference struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Reason    string `json:"reason"`
+}
+
+// Change is the planned change of a single manifest.
+type Change struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	FQN       string `json:"fqn,omitempty"`
+	Action    Action `json:"action"`
+	// Fields are the changed fields of the spec (i.e. `builder.aggrGranularity`)
+	Fields   []string    `json:"fields,omitempty"`
+	Impacts  []Impact    `json:"impacts,omitempty"`
+	Affected []Reference `json:"affected,omitempty"`
+	// Error is set when the manifest would be rejected
+	Error string `json:"error,omitempty"`
+}
+
+// Report is the planned changes of a set of manifests.
+type Report struct {
+	Changes []Change `json:"changes"`
+	// Blocked indicates that at least one of the manifests would be rejected.
+	Blocked bool `json:"blocked"`
+}
+
+// Planner plans the changes of manifests against the objects in the cluster.
+type Planner struct {
+	reader         client.Reader
+	updatesAllowed bool
+}
+
+// New creates a new Planner. updatesAllowed should match the core's configuration, so changes that would be rejected
+// by the admission webhook (i.e. updating features in production) are reported.
+func New(reader client.Reader, updatesAllowed bool) *Planner {
+	return &Planner{reader: reader, updatesAllowed: updatesAllowed}
+}
+
+// Plan reports the changes that applying the given manifests would make. Manifests without a namespace are planned
+// for the `default` namespace.
+func (p *Planner) Plan(ctx context.Context, objs []*unstructured.Unstructured) (Report, error) {
+	features := manifests.FeatureList{}
+	if err := p.reader.List(ctx, &features); err != nil {
+		return Report{}, fmt.Errorf("failed to list features: %w", err)
+	}
+	models := manifests.ModelList{}
+	if err := p.reader.List(ctx, &models); err != nil {
+		return Report{}, fmt.Errorf("failed to list models: %w", err)
+	}
+
+	rep := Report{Changes: make([]Change, 0, len(objs))}
+	for _, u := range objs {
+		if u.GetNamespace() == "" {
+			u.SetNamespace("default")
+		}
+		gvk := u.GroupVersionKind()
+		if gvk.Group != manifests.GroupVersion.Group {
+			return Report{}, fmt.Errorf("manifests of kind `%s` can't be planned", gvk.GroupKind())
+		}
+
+		var ch Change
+		var err error
+		switch gvk.Kind {
+		case "Feature":
+			ch, err = p.planFeature(ctx, u, features.Items, models.Items)
+		case "DataSource":
+			ch, err = p.planDataSource(ctx, u, features.Items)
+		case "Model":
+			ch, err = p.planModel(ctx, u)
+		default:
+			return Report{}, fmt.Errorf("manifests of kind `%s` can't be planned", gvk.GroupKind())
+		}
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to plan %s `%s`: %w", gvk.Kind, u.GetName(), err)
+		}
+		if ch.Error != "" {
+			rep.Blocked = true
+		}
+		rep.Changes = append(rep.Changes, ch)
+	}
+	return rep, nil
+}
+
+func (p *Planner) planFeature(ctx context.Context, u *unstructured.Unstructured, features []manifests.Feature, models []manifests.Model) (Change, error) {
+	in := &manifests.Feature{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, in); err != nil {
+		return Change{}, err
+	}
+	ch := Change{Kind: "Feature", Name: in.GetName(), Namespace: in.GetNamespace(), FQN: in.FQN()}
+
+	var old *manifests.Feature
+	for i := range features {
+		if features[i].GetNamespace() == in.GetNamespace() && features[i].GetName() == in.GetName() {
+			old = &features[i]
+			break
+		}
+	}
+	if old != nil {
+		defaultFeature(in, old)
+	}
+
+	fd, err := api.FeatureDescriptorFromManifest(in)
+	if err != nil {
+		ch.Error = err.Error()
+	}
+	if old == nil {
+		ch.Action = ActionCreate
+		return ch, nil
+	}
+
+	ch.Fields, err = diffFields(old.Spec, in.Spec)
+	if err != nil {
+		return Change{}, err
+	}
+	if len(ch.Fields) == 0 {
+		ch.Action = ActionUnchanged
+		return ch, nil
+	}
+	ch.Action = ActionUpdate
+
+	windowed := fd != nil && fd.ValidWindow()
+	if oldFD, err := api.FeatureDescriptorFromManifest(old); err == nil && oldFD.ValidWindow() {
+		windowed = true
+	}
+	ch.Impacts = featureImpacts(ch.Fields, windowed)
+
+	if !p.updatesAllowed && ch.Error == "" {
+		sandbox, err := operator.IsSandboxNamespace(ctx, p.reader, in.GetNamespace())
+		if err != nil && !apierrors.IsNotFound(err) {
+			return Change{}, err
+		}
+		if !sandbox {
+			ch.Error = "features are immutable in production"
+		}
+	}
+
+	reason := "its feature is rebound"
+	if hasImpact(ch.Impacts, ImpactStateReset) || hasImpact(ch.Impacts, ImpactWindowReset) {
+		reason = "its feature's values are reset"
+	}
+	for _, f := range features {
+		for _, dep := range f.Status.Dependencies {
+			if dep.Namespace == in.GetNamespace() && dep.Name == in.GetName() {
+				ch.Affected = append(ch.Affected, Reference{Kind: "Feature", Name: f.GetName(), Namespace: f.GetNamespace(),
+					Reason: "depends on the feature, and " + reason})
+				break
+			}
+		}
+	}
+	for _, m := range models {
+		if modelUses(&m, in.FQN()) {
+			ch.Affected = append(ch.Affected, Reference{Kind: "Model", Name: m.GetName(), Namespace: m.GetNamespace(),
+				Reason: "uses the feature, and " + reason})
+		}
+	}
+	return ch, nil
+}
+
+// defaultFeature copies the fields that are defaulted by the admission webhook from the existing feature, so they
+// are not reported as changes.
+func defaultFeature(in, old *manifests.Feature) {
+	if in.Spec.DataSource != nil && in.Spec.DataSource.Namespace == "" {
+		in.Spec.DataSource.Namespace = in.GetNamespace()
+	}
+	if in.Spec.Builder.Kind == "" {
+		in.Spec.Builder.Kind = old.Spec.Builder.Kind
+		if in.Spec.Builder.AggrGranularity.Milliseconds() > 0 && len(in.Spec.Builder.Aggr) > 0 {
+			in.Spec.Freshness = in.Spec.Builder.AggrGranularity
+		}
+	}
+	if in.Spec.Historical == nil {
+		in.Spec.Historical = old.Spec.Historical
+	}
+}
+
+func (p *Planner) planDataSource(ctx context.Context, u *unstructured.Unstructured, features []manifests.Feature) (Change, error) {
+	in := &manifests.DataSource{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, in); err != nil {
+		return Change{}, err
+	}
+	ch := Change{Kind: "DataSource", Name: in.GetName(), Namespace: in.GetNamespace(), FQN: in.FQN()}
+
+	old := &manifests.DataSource{}
+	err := p.reader.Get(ctx, client.ObjectKeyFromObject(in), old)
+	if apierrors.IsNotFound(err) {
+		ch.Action = ActionCreate
+		return ch, nil
+	}
+	if err != nil {
+		return Change{}, err
+	}
+
+	ch.Fields, err = diffFields(old.Spec, in.Spec)
+	if err != nil {
+		return Change{}, err
+	}
+	if len(ch.Fields) == 0 {
+		ch.Action = ActionUnchanged
+		return ch, nil
+	}
+	ch.Action = ActionUpdate
+	ch.Impacts = []Impact{ImpactRunnerRestart}
+	for _, f := range features {
+		if ds := f.Spec.DataSource; ds != nil && ds.Name == in.GetName() &&
+			(ds.Namespace == in.GetNamespace() || ds.Namespace == "" && f.GetNamespace() == in.GetNamespace()) {
+			ch.Affected = append(ch.Affected, Reference{Kind: "Feature", Name: f.GetName(), Namespace: f.GetNamespace(),
+				Reason: "is ingested by the DataSource's runner"})
+		}
+	}
+	return ch, nil
+}
+
+func (p *Planner) planModel(ctx context.Context, u *unstructured.Unstructured) (Change, error) {
+	in := &manifests.Model{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, in); err != nil {
+		return Change{}, err
+	}
+	ch := Change{Kind: "Model", Name: in.GetName(), Namespace: in.GetNamespace(), FQN: in.FQN()}
+
+	old := &manifests.Model{}
+	err := p.reader.Get(ctx, client.ObjectKeyFromObject(in), old)
+	if apierrors.IsNotFound(err) {
+		ch.Action = ActionCreate
+		return ch, nil
+	}
+	if err != nil {
+		return Change{}, err
+	}
+
+	ch.Fields, err = diffFields(old.Spec, in.Spec)
+	if err != nil {
+		return Change{}, err
+	}
+	if len(ch.Fields) == 0 {
+		ch.Action = ActionUnchanged
+		return ch, nil
+	}
+	ch.Action = ActionUpdate
+	// models are implemented as features internally
+	ch.Impacts = []Impact{ImpactRebind}
+	for _, f := range ch.Fields {
+		if f == "keys" || strings.HasPrefix(f, "features") || f == "keyFeature" || strings.HasPrefix(f, "labels") {
+			ch.Impacts = append(ch.Impacts, ImpactHistoricalMigration)
+			break
+		}
+	}
+	return ch, nil
+}
+
+// modelUses checks if the model uses the feature as one of its features, labels or its key feature.
+func modelUses(m *manifests.Model, fqn string) bool {
+	ns := strings.ReplaceAll(m.GetNamespace(), "-", "_")
+	selectors := append(append([]string{m.Spec.KeyFeature}, m.Spec.Features...), m.Spec.Labels...)
+	for _, s := range selectors {
+		if s == "" {
+			continue
+		}
+		if n, err := api.NormalizeFQN(s, ns); err == nil && n == fqn {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package aggregation implements the `aggregation` builder, which aggregates the events of a streaming DataSource
+// into windows with exactly-once semantics.
+//
+// The DataSource's runner writes the `field` of every event, along with the event's ID (taken from `eventId`) as the
+// idempotency key of the write. The engine marks the key in the state before the event is added to the window, so
+// redelivered events are aggregated only once. If the write fails, the mark is removed so the event can be retried.
+//
+// Events are added to the bucket of their timestamp. Events that arrive later than `allowedLateness` (compared to the
+// DataSource's watermark) are dropped by the engine, since their bucket might have already been collected.
+package aggregation
+
+import (
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+)
+
+const name = api.AggregationBuilder
+
+func init() {
+	plugins.FeatureAppliers.Register(name, FeatureApply)
+}
+
+// FeatureApply validates the windowing of the Feature, and that its events can be deduplicated.
+func FeatureApply(fd api.FeatureDescriptor, builder manifests.FeatureBuilder, pl api.Pipeliner, engine api.ExtendedManager) error {
+	if fd.DataSource == "" {
+		return fmt.Errorf("DataSource must be set for `%s` builder", name)
+	}
+	if builder.Field == "" {
+		return fmt.Errorf("`field` must be set for `%s` builder", name)
+	}
+	if !fd.ValidWindow() {
+		return fmt.Errorf("`%s` builder requires a windowed feature (`aggr` and `aggrGranularity`)", name)
+	}
+
+	if builder.EventID != "" {
+		if _, ok := engine.(api.Deduplicator); !ok {
+			return fmt.Errorf("the engine doesn't support events deduplication")
+		}
+	}
+	return nil
+}
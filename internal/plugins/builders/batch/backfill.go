@@ -0,0 +1,121 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"strconv"
+	"strings"
+)
+
+// BackfillSourceFactory creates a BackfillSource that reads the files under the `url` prefix, in the order of their
+// modification time. The config is the same as the config of a `batch` DataSource.
+func BackfillSourceFactory(ctx context.Context, pc manifests.ParsedConfig) (api.BackfillSource, error) {
+	cfg := Config{}
+	if err := pc.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal backfill source config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	s, err := newStore(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &backfillSource{cfg: cfg, store: s}, nil
+}
+
+type backfillSource struct {
+	cfg   Config
+	store *store
+}
+
+// Read implements api.BackfillSource. The cursor is the key of the file and the number of the row in it.
+func (b *backfillSource) Read(ctx context.Context, cursor string, fn api.BackfillRowFn) error {
+	resumeKey, resumeRow, err := parseCursor(cursor)
+	if err != nil {
+		return err
+	}
+
+	objects, err := b.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	if resumeKey != "" {
+		found := false
+		for i, obj := range objects {
+			if obj.Key == resumeKey {
+				objects, found = objects[i:], true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("cannot resume the backfill: %s doesn't exist anymore", resumeKey)
+		}
+	}
+
+	for _, obj := range objects {
+		if b.cfg.formatOf(obj.Key) == FormatAuto {
+			continue
+		}
+
+		skip := 0
+		if obj.Key == resumeKey {
+			skip = resumeRow
+		}
+		rows := 0
+		err := b.store.readRows(ctx, b.cfg, obj.Key, func(row Row) error {
+			rows++
+			if rows <= skip {
+				return nil
+			}
+			ts, err := rowTimestamp(b.cfg, row, obj)
+			if err != nil {
+				return err
+			}
+			return fn(row, ts, fmt.Sprintf("%s#%d", obj.Key, rows))
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", obj.Key, err)
+		}
+	}
+	return nil
+}
+
+// Close implements api.BackfillSource
+func (b *backfillSource) Close() error {
+	return nil
+}
+
+func parseCursor(cursor string) (key string, row int, err error) {
+	if cursor == "" {
+		return "", 0, nil
+	}
+	i := strings.LastIndex(cursor, "#")
+	if i < 0 {
+		return "", 0, fmt.Errorf("invalid cursor: %s", cursor)
+	}
+	row, err = strconv.Atoi(cursor[i+1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid cursor: %s", cursor)
+	}
+	return cursor[:i], row, nil
+}
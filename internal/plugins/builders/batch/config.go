@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Format is the file format of the batch files.
+type Format string
+
+const (
+	FormatAuto    Format = ""
+	FormatCSV     Format = "csv"
+	FormatJSON    Format = "json"
+	FormatParquet Format = "parquet"
+)
+
+// Config is the configuration of a `batch` DataSource.
+type Config struct {
+	// URL is the object-store prefix to watch. i.e. `s3://bucket/path/` or `gs://bucket/path/`
+	URL string `mapstructure:"url"`
+	//+optional
+	Format Format `mapstructure:"format"`
+	//+optional
+	TimestampColumn string `mapstructure:"timestamp_column"`
+	//+optional
+	TimestampFormat string `mapstructure:"timestamp_format"`
+	//+optional
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	//+optional
+	Region string `mapstructure:"region"`
+	//+optional
+	Endpoint string `mapstructure:"endpoint"`
+	//+optional
+	AccessKey string `mapstructure:"access_key"`
+	//+optional
+	SecretKey string `mapstructure:"secret_key"`
+	//+optional
+	WatermarkPath string `mapstructure:"watermark_path"`
+}
+
+// Validate checks the config and sets the defaults.
+func (c *Config) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("`url` is required for `%s` DataSource", name)
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("failed to parse `url`: %w", err)
+	}
+	if u.Scheme != "s3" && u.Scheme != "gs" {
+		return fmt.Errorf("unsupported object-store scheme `%s`. supported schemes are `s3` and `gs`", u.Scheme)
+	}
+	switch c.Format {
+	case FormatAuto, FormatCSV, FormatJSON, FormatParquet:
+	default:
+		return fmt.Errorf("unsupported format `%s`", c.Format)
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Minute
+	}
+	if c.TimestampFormat == "" {
+		c.TimestampFormat = time.RFC3339
+	}
+	return nil
+}
+
+// Bucket returns the bucket name from the URL.
+func (c *Config) Bucket() string {
+	u, _ := url.Parse(c.URL)
+	return u.Host
+}
+
+// Prefix returns the object prefix from the URL.
+func (c *Config) Prefix() string {
+	u, _ := url.Parse(c.URL)
+	return strings.TrimPrefix(u.Path, "/")
+}
+
+// GCS checks if the URL points to Google Cloud Storage.
+func (c *Config) GCS() bool {
+	u, _ := url.Parse(c.URL)
+	return u.Scheme == "gs"
+}
+
+// watermarkKey returns the object key that stores the watermark state of the DataSource.
+func (c *Config) watermarkKey(fqn string) string {
+	if c.WatermarkPath != "" {
+		return strings.TrimPrefix(c.WatermarkPath, "/")
+	}
+	return path.Join(c.Prefix(), ".raptor", fmt.Sprintf("%s.watermarks.json", fqn))
+}
+
+// formatOf returns the format of a given object key.
+func (c *Config) formatOf(key string) Format {
+	if c.Format != FormatAuto {
+		return c.Format
+	}
+	k := strings.TrimSuffix(strings.ToLower(key), ".gz")
+	switch {
+	case strings.HasSuffix(k, ".csv"):
+		return FormatCSV
+	case strings.HasSuffix(k, ".json"), strings.HasSuffix(k, ".jsonl"), strings.HasSuffix(k, ".ndjson"):
+		return FormatJSON
+	case strings.HasSuffix(k, ".parquet"):
+		return FormatParquet
+	default:
+		return FormatAuto
+	}
+}
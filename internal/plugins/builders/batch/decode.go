@@ -0,0 +1,247 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/xitongsys/parquet-go/reader"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Row is a single record of a batch file.
+type Row map[string]any
+
+const parquetBatchSize = 1000
+
+// readRows reads the rows of an object and calls fn for each of them.
+func (s *store) readRows(ctx context.Context, cfg Config, key string, fn func(Row) error) error {
+	format := cfg.formatOf(key)
+	if format == FormatParquet {
+		return s.readParquet(ctx, key, fn)
+	}
+
+	body, err := s.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	var r io.Reader = body
+	if strings.HasSuffix(strings.ToLower(key), ".gz") {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return fmt.Errorf("failed to decompress %s: %w", key, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	switch format {
+	case FormatCSV:
+		return readCSV(r, fn)
+	case FormatJSON:
+		return readJSON(r, fn)
+	default:
+		return fmt.Errorf("cannot detect the format of %s", key)
+	}
+}
+
+func readCSV(r io.Reader, fn func(Row) error) error {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("failed to read csv header: %w", err)
+	}
+	header = append([]string(nil), header...)
+
+	for {
+		rec, err := cr.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read csv record: %w", err)
+		}
+		row := make(Row, len(header))
+		for i, col := range header {
+			if i < len(rec) {
+				row[col] = inferScalar(rec[i])
+			}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+// inferScalar converts a textual value into the most specific primitive.
+func inferScalar(s string) any {
+	if i, err := strconv.Atoi(s); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	return s
+}
+
+func readJSON(r io.Reader, fn func(Row) error) error {
+	br := bufio.NewReader(r)
+
+	// detect JSON array vs. newline-delimited JSON
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read json: %w", err)
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			break
+		}
+		_, _ = br.ReadByte()
+	}
+
+	dec := json.NewDecoder(br)
+	dec.UseNumber()
+
+	b, _ := br.Peek(1)
+	if b[0] == '[' {
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("failed to read json: %w", err)
+		}
+	}
+	for dec.More() {
+		raw := make(map[string]any)
+		if err := dec.Decode(&raw); err != nil {
+			return fmt.Errorf("failed to decode json record: %w", err)
+		}
+		if err := fn(normalizeRow(raw)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *store) readParquet(ctx context.Context, key string, fn func(Row) error) error {
+	pf, err := s.OpenParquet(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to open parquet file %s: %w", key, err)
+	}
+	defer pf.Close()
+
+	pr, err := reader.NewParquetReader(pf, nil, 4)
+	if err != nil {
+		return fmt.Errorf("failed to read parquet file %s: %w", key, err)
+	}
+	defer pr.ReadStop()
+
+	total := int(pr.GetNumRows())
+	for read := 0; read < total; read += parquetBatchSize {
+		records, err := pr.ReadByNumber(parquetBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read parquet records: %w", err)
+		}
+
+		// records are dynamic structs; round-trip them through JSON to get a generic representation
+		buf, err := json.Marshal(records)
+		if err != nil {
+			return fmt.Errorf("failed to encode parquet records: %w", err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(buf))
+		dec.UseNumber()
+		var rows []map[string]any
+		if err := dec.Decode(&rows); err != nil {
+			return fmt.Errorf("failed to decode parquet records: %w", err)
+		}
+
+		for _, raw := range rows {
+			if err := fn(normalizeRow(raw)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeRow converts a generic decoded record to values that are supported by the runtime.
+func normalizeRow(raw map[string]any) Row {
+	row := make(Row, len(raw))
+	for k, v := range raw {
+		row[k] = api.NormalizeValue(v)
+	}
+	return row
+}
+
+// parseTimestamp parses the timestamp of a row according to the configured format.
+func parseTimestamp(val any, format string) (time.Time, error) {
+	switch v := val.(type) {
+	case time.Time:
+		return v, nil
+	case int:
+		return unixTimestamp(int64(v), format), nil
+	case float64:
+		return unixTimestamp(int64(v), format), nil
+	case string:
+		switch format {
+		case "unix", "unix_ms", "unix_us":
+			i, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("failed to parse timestamp `%s`: %w", v, err)
+			}
+			return unixTimestamp(i, format), nil
+		}
+		ts, err := time.Parse(format, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse timestamp `%s`: %w", v, err)
+		}
+		return ts, nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported timestamp value type %T", val)
+	}
+}
+
+func unixTimestamp(i int64, format string) time.Time {
+	switch format {
+	case "unix_ms":
+		return time.UnixMilli(i)
+	case "unix_us":
+		return time.UnixMicro(i)
+	default:
+		return time.Unix(i, 0)
+	}
+}
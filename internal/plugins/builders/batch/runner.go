@@ -0,0 +1,165 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/runner"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+// Runner ingests the files of a `batch` DataSource and feeds their rows to the features' programs.
+// Each file is ingested once; the ingestion state (watermarks) is stored alongside the files.
+type Runner struct {
+	cfg      Config
+	fqn      string
+	executor *runner.Executor
+	store    *store
+	logger   logr.Logger
+}
+
+// NewRunner creates a new batch Runner for the given DataSource.
+func NewRunner(ctx context.Context, src *manifests.DataSource, k8s client.Reader, engine api.Engine, runtime api.RuntimeManager, logger logr.Logger) (*Runner, error) {
+	pc, err := src.ParseConfig(ctx, k8s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DataSource config: %w", err)
+	}
+	if err := pc.DecodeSecrets(src.Spec.Config); err != nil {
+		return nil, err
+	}
+	cfg := Config{}
+	if err := pc.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal DataSource config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	s, err := newStore(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	logger = logger.WithValues("datasource", src.FQN())
+	return &Runner{
+		cfg: cfg,
+		fqn: src.FQN(),
+		executor: &runner.Executor{
+			DataSource: client.ObjectKeyFromObject(src),
+			Client:     k8s,
+			Engine:     engine,
+			Runtime:    runtime,
+			Logger:     logger,
+		},
+		store:  s,
+		logger: logger,
+	}, nil
+}
+
+// Run polls the object store until the context is canceled.
+func (r *Runner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Sync(ctx); err != nil {
+			r.logger.Error(err, "failed to sync batch files")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync ingests the files that were added or changed since the last sync.
+func (r *Runner) Sync(ctx context.Context) error {
+	snap, err := r.executor.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	if len(snap.Features) == 0 {
+		r.logger.V(1).Info("no features are attached to the DataSource")
+		return nil
+	}
+
+	wmKey := r.cfg.watermarkKey(r.fqn)
+	wm, err := r.store.Watermarks(ctx, wmKey)
+	if err != nil {
+		return err
+	}
+
+	objects, err := r.store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		if wm.Ingested(obj) {
+			continue
+		}
+		if r.cfg.formatOf(obj.Key) == FormatAuto {
+			r.logger.V(1).Info("skipping file with unknown format", "key", obj.Key)
+			continue
+		}
+
+		rows := 0
+		err := r.store.readRows(ctx, r.cfg, obj.Key, func(row Row) error {
+			rows++
+			ts, err := rowTimestamp(r.cfg, row, obj)
+			if err != nil {
+				return err
+			}
+			// the file's version and the row's position identify the row, so re-ingestions of a file are idempotent
+			id := fmt.Sprintf("%s@%d:%d", obj.Key, obj.LastModified.UnixMilli(), rows)
+			r.executor.Execute(context.WithValue(ctx, api.ContextKeyEventID, id), snap, row, ts)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to ingest %s: %w", obj.Key, err)
+		}
+
+		wm[obj.Key] = obj
+		if err := r.store.SaveWatermarks(ctx, wmKey, wm); err != nil {
+			return err
+		}
+		r.logger.Info("ingested file", "key", obj.Key, "rows", rows)
+		if bus, ok := r.executor.Engine.(api.EventBus); ok {
+			bus.Publish(ctx, api.BackfillCompletedEvent{DataSource: r.fqn, Source: obj.Key, Rows: rows})
+		}
+	}
+	return nil
+}
+
+// rowTimestamp returns the timestamp of the row. If no timestamp column is configured, the file's modification time is
+// used.
+func rowTimestamp(cfg Config, row Row, obj object) (time.Time, error) {
+	if cfg.TimestampColumn == "" {
+		return obj.LastModified, nil
+	}
+	val, ok := row[cfg.TimestampColumn]
+	if !ok {
+		return time.Time{}, fmt.Errorf("timestamp column `%s` is missing", cfg.TimestampColumn)
+	}
+	return parseTimestamp(val, cfg.TimestampFormat)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/xitongsys/parquet-go-source/s3v2"
+	"github.com/xitongsys/parquet-go/source"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// gcsEndpoint is the S3-interoperable endpoint of Google Cloud Storage (requires HMAC keys).
+const gcsEndpoint = "https://storage.googleapis.com"
+
+type object struct {
+	Key          string    `json:"key"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// watermarks is the per-file ingestion state of a DataSource.
+type watermarks map[string]object
+
+// Ingested checks if the object was already ingested (with the same content).
+func (w watermarks) Ingested(obj object) bool {
+	wm, ok := w[obj.Key]
+	return ok && wm.ETag == obj.ETag
+}
+
+type store struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func newStore(ctx context.Context, cfg Config) (*store, error) {
+	var opts []func(*config.LoadOptions) error
+	if cfg.AccessKey != "" && cfg.SecretKey != "" {
+		opts = append(opts, config.WithCredentialsProvider(credentials.StaticCredentialsProvider{
+			Value: aws.Credentials{
+				AccessKeyID:     cfg.AccessKey,
+				SecretAccessKey: cfg.SecretKey,
+			},
+		}))
+	}
+
+	endpoint := cfg.Endpoint
+	region := cfg.Region
+	if cfg.GCS() {
+		if endpoint == "" {
+			endpoint = gcsEndpoint
+		}
+		if region == "" {
+			region = "auto"
+		}
+	}
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+
+	return &store{
+		client: client,
+		bucket: cfg.Bucket(),
+		prefix: cfg.Prefix(),
+	}, nil
+}
+
+// List returns the objects under the prefix, sorted by their modification time.
+func (s *store) List(ctx context.Context) ([]object, error) {
+	var ret []object
+
+	p := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.prefix),
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		for _, o := range page.Contents {
+			key := aws.ToString(o.Key)
+			if strings.HasSuffix(key, "/") || strings.Contains(key, "/.raptor/") {
+				continue
+			}
+			ret = append(ret, object{
+				Key:          key,
+				ETag:         strings.Trim(aws.ToString(o.ETag), `"`),
+				LastModified: aws.ToTime(o.LastModified),
+			})
+		}
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].LastModified.Before(ret[j].LastModified)
+	})
+	return ret, nil
+}
+
+// Open returns a reader for the object's content.
+func (s *store) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	return resp.Body, nil
+}
+
+// OpenParquet returns a random-access parquet file for the object.
+func (s *store) OpenParquet(ctx context.Context, key string) (source.ParquetFile, error) {
+	return s3v2.NewS3FileReaderWithClient(ctx, s.client, s.bucket, key)
+}
+
+// Watermarks loads the watermarks state. If the state doesn't exist, it returns an empty state.
+func (s *store) Watermarks(ctx context.Context, key string) (watermarks, error) {
+	wm := make(watermarks)
+
+	r, err := s.Open(ctx, key)
+	if err != nil {
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return wm, nil
+		}
+		return nil, err
+	}
+	defer r.Close()
+
+	if err := json.NewDecoder(r).Decode(&wm); err != nil {
+		return nil, fmt.Errorf("failed to decode watermarks: %w", err)
+	}
+	return wm, nil
+}
+
+// SaveWatermarks stores the watermarks state.
+func (s *store) SaveWatermarks(ctx context.Context, key string, wm watermarks) error {
+	buf, err := json.Marshal(wm)
+	if err != nil {
+		return fmt.Errorf("failed to encode watermarks: %w", err)
+	}
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(buf),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store watermarks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/celexpr"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+)
+
+const name = api.CELBuilder
+
+func init() {
+	plugins.FeatureAppliers.Register(name, FeatureApply)
+}
+
+// FeatureApply compiles the CEL expression of the Feature and type-checks it against the FeatureDescriptor.
+// The expression is evaluated by the DataSource's runner for every event, and the results are written to the state,
+// so no middlewares are needed to serve the feature.
+func FeatureApply(fd api.FeatureDescriptor, builder manifests.FeatureBuilder, _ api.Pipeliner, engine api.ExtendedManager) error {
+	if builder.CEL == "" {
+		return fmt.Errorf("`cel` must be set for `%s` builder", name)
+	}
+	if fd.DataSource == "" {
+		return fmt.Errorf("DataSource must be set for `%s` builder", name)
+	}
+
+	src, err := engine.GetDataSource(fd.DataSource)
+	if err != nil {
+		return fmt.Errorf("failed to get DataSource: %v", err)
+	}
+	if err := plugins.RunnerCapabilities.Require(src, builder); err != nil {
+		return fmt.Errorf("DataSource of type `%s` is not supported by the `%s` builder: %w", src.Kind, name, err)
+	}
+
+	return Validate(fd, builder)
+}
+
+// Validate compiles the CEL expression and checks that it can build the feature.
+func Validate(fd api.FeatureDescriptor, builder manifests.FeatureBuilder) error {
+	p, err := celexpr.Compile(builder.CEL)
+	if err != nil {
+		return err
+	}
+	if err := p.Validate(fd); err != nil {
+		return fmt.Errorf("invalid cel: %w", err)
+	}
+	return nil
+}
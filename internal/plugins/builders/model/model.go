@@ -23,6 +23,7 @@ import (
 	"github.com/raptor-ml/raptor/api"
 	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
 	"github.com/raptor-ml/raptor/pkg/plugins"
+	"slices"
 	"sync"
 )
 
@@ -54,6 +55,23 @@ func FeatureApply(fd api.FeatureDescriptor, builder manifests.FeatureBuilder, pl
 			return fmt.Errorf("failed to normalize feature %s in model %s: %w", f, fd.FQN, err)
 		}
 	}
+	units := make(map[string]string, len(md.Units))
+	for f, unit := range md.Units {
+		fqn, err := api.NormalizeSelector(f, ns)
+		if err != nil {
+			return fmt.Errorf("failed to normalize feature %s units in model %s: %w", f, fd.FQN, err)
+		}
+		if !slices.Contains(md.Features, fqn) {
+			return fmt.Errorf("the units of feature %s are set, but it's not a feature of model %s", f, fd.FQN)
+		}
+		// features that are not bound yet are checked when they're read
+		if ffd, err := engine.FeatureDescriptor(context.TODO(), fqn); err == nil && !api.UnitsCompatible(ffd.Unit, unit) {
+			return fmt.Errorf("%w: feature %s of model %s can't be converted from `%s` to `%s`",
+				api.ErrIncompatibleUnits, f, fd.FQN, ffd.Unit, unit)
+		}
+		units[fqn] = api.NormalizeUnit(unit)
+	}
+	md.Units = units
 
 	fs := &model{engine: engine, md: md}
 	pl.AddPostGetMiddleware(0, fs.preGetMiddleware)
@@ -77,16 +95,33 @@ func (m *model) preGetMiddleware(next api.MiddlewareHandler) api.MiddlewareHandl
 		wg := &sync.WaitGroup{}
 		wg.Add(len(m.md.Features))
 
+		mu := sync.Mutex{}
 		ret := api.Value{}
 		results := make(map[string]api.Value)
 		for _, fqn := range m.md.Features {
 			go func(fqn string, wg *sync.WaitGroup) {
 				defer wg.Done()
-				val, _, err := m.engine.Get(ctx, fqn, keys)
+				val, ffd, err := m.engine.Get(ctx, fqn, keys)
 				if err != nil {
 					logger.Error(err, "failed to get feature %s", fqn)
 					return
 				}
+				// the model is served within the Core, so it's fed with the decrypted values of encrypted features
+				val, err = api.DecryptValue(ctx, m.engine, ffd, keys, val)
+				if err != nil {
+					logger.Error(err, "failed to decrypt feature", "feature", fqn)
+					return
+				}
+				if unit, ok := m.md.Units[fqn]; ok {
+					val.Value, err = api.ConvertUnit(val.Value, ffd.Unit, unit)
+					if err != nil {
+						logger.Error(err, "failed to convert feature units", "feature", fqn)
+						return
+					}
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
 				results[fqn] = val
 				if ret.Timestamp.IsZero() || ret.Timestamp.Before(val.Timestamp) {
 					ret.Timestamp = val.Timestamp
@@ -100,14 +135,14 @@ func (m *model) preGetMiddleware(next api.MiddlewareHandler) api.MiddlewareHandl
 		ret.Value = results
 
 		if ms := plugins.ModelServer.Get(m.md.ModelServer); ms != nil {
-			val, err := ms.Serve(ctx, fd, m.md, val)
+			val, err := ms.Serve(ctx, fd, m.md, ret)
 			if err != nil {
 				return val, err
 			}
 			return next(ctx, fd, keys, val)
 		}
 
-		return next(ctx, fd, keys, val)
+		return next(ctx, fd, keys, ret)
 	}
 }
 
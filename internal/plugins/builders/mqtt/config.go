@@ -0,0 +1,166 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mqtt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Config is the configuration of an `mqtt` DataSource.
+type Config struct {
+	// Broker is the address of the MQTT broker. i.e. `tcp://broker:1883` or `ssl://broker:8883`
+	Broker string `mapstructure:"broker"`
+	// Topics is a list of topic filters to subscribe to. Wildcards (`+` and `#`) are supported.
+	Topics []string `mapstructure:"topics"`
+	// TopicFields names the `+` wildcard levels of the topic filters (in order).
+	// The matched levels are added to the message's fields. i.e. `devices/+/telemetry` with `device_id`.
+	//+optional
+	TopicFields []string `mapstructure:"topic_fields"`
+	//+optional
+	QoS byte `mapstructure:"qos"`
+	//+optional
+	ClientID string `mapstructure:"client_id"`
+	//+optional
+	CleanSession *bool `mapstructure:"clean_session"`
+	//+optional
+	Username string `mapstructure:"username"`
+	//+optional
+	Password string `mapstructure:"password"`
+	//+optional
+	TimestampField string `mapstructure:"timestamp_field"`
+
+	// TLSCA is a PEM encoded CA bundle to verify the broker's certificate
+	//+optional
+	TLSCA string `mapstructure:"tls_ca"`
+	// TLSCert is a PEM encoded client certificate (for mutual TLS)
+	//+optional
+	TLSCert string `mapstructure:"tls_cert"`
+	// TLSKey is the PEM encoded private key of the client certificate
+	//+optional
+	TLSKey string `mapstructure:"tls_key"`
+	//+optional
+	TLSInsecureSkipVerify bool `mapstructure:"tls_insecure_skip_verify"`
+}
+
+// Validate checks the config and sets the defaults.
+func (c *Config) Validate() error {
+	if c.Broker == "" {
+		return fmt.Errorf("`broker` is required for `%s` DataSource", name)
+	}
+	u, err := url.Parse(c.Broker)
+	if err != nil {
+		return fmt.Errorf("failed to parse `broker`: %w", err)
+	}
+	switch u.Scheme {
+	case "tcp", "mqtt", "ssl", "tls", "mqtts", "ws", "wss":
+	default:
+		return fmt.Errorf("unsupported broker scheme `%s`", u.Scheme)
+	}
+
+	if len(c.Topics) == 0 {
+		return fmt.Errorf("at least one topic is required for `%s` DataSource", name)
+	}
+	for _, t := range c.Topics {
+		if err := validateTopicFilter(t); err != nil {
+			return err
+		}
+	}
+	if c.QoS > 2 {
+		return fmt.Errorf("invalid `qos` %d. must be 0, 1 or 2", c.QoS)
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return fmt.Errorf("both `tls_cert` and `tls_key` must be set for mutual TLS")
+	}
+	return nil
+}
+
+// TLS checks if the connection to the broker should be encrypted.
+func (c *Config) TLS() bool {
+	u, _ := url.Parse(c.Broker)
+	switch u.Scheme {
+	case "ssl", "tls", "mqtts", "wss":
+		return true
+	}
+	return c.TLSCA != "" || c.TLSCert != ""
+}
+
+// TLSConfig builds the TLS configuration of the connection.
+func (c *Config) TLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.TLSInsecureSkipVerify, //nolint:gosec
+	}
+
+	if c.TLSCA != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(c.TLSCA)) {
+			return nil, fmt.Errorf("failed to parse `tls_ca`")
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.TLSCert != "" {
+		cert, err := tls.X509KeyPair([]byte(c.TLSCert), []byte(c.TLSKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// validateTopicFilter validates the wildcard usage of an MQTT topic filter.
+func validateTopicFilter(filter string) error {
+	if filter == "" {
+		return fmt.Errorf("topic filter cannot be empty")
+	}
+	levels := strings.Split(filter, "/")
+	for i, l := range levels {
+		if strings.Contains(l, "#") && (l != "#" || i != len(levels)-1) {
+			return fmt.Errorf("invalid topic filter `%s`: `#` must occupy the last level", filter)
+		}
+		if strings.Contains(l, "+") && l != "+" {
+			return fmt.Errorf("invalid topic filter `%s`: `+` must occupy an entire level", filter)
+		}
+	}
+	return nil
+}
+
+// matchTopic checks if the topic matches the filter, and returns the levels that matched the `+` wildcards.
+func matchTopic(filter, topic string) ([]string, bool) {
+	fl := strings.Split(filter, "/")
+	tl := strings.Split(topic, "/")
+
+	var wildcards []string
+	for i, f := range fl {
+		switch {
+		case f == "#":
+			return wildcards, true
+		case i >= len(tl):
+			return nil, false
+		case f == "+":
+			wildcards = append(wildcards, tl[i])
+		case f != tl[i]:
+			return nil, false
+		}
+	}
+	return wildcards, len(fl) == len(tl)
+}
@@ -0,0 +1,220 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/go-logr/logr"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/runner"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync/atomic"
+	"time"
+)
+
+// featuresRefreshInterval is the interval of refreshing the Features that are attached to the DataSource.
+const featuresRefreshInterval = time.Minute
+
+// valueField is the field that holds payloads that are not JSON objects.
+const valueField = "value"
+
+// Runner subscribes to the MQTT topics of an `mqtt` DataSource and feeds the messages to the features' programs.
+type Runner struct {
+	cfg      Config
+	executor *runner.Executor
+	snapshot atomic.Pointer[runner.Snapshot]
+	logger   logr.Logger
+}
+
+// NewRunner creates a new MQTT Runner for the given DataSource.
+func NewRunner(ctx context.Context, src *manifests.DataSource, k8s client.Reader, engine api.Engine, runtime api.RuntimeManager, logger logr.Logger) (*Runner, error) {
+	pc, err := src.ParseConfig(ctx, k8s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DataSource config: %w", err)
+	}
+	if err := pc.DecodeSecrets(src.Spec.Config); err != nil {
+		return nil, err
+	}
+	cfg := Config{}
+	if err := pc.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal DataSource config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.ClientID == "" {
+		// a stable client id is required for persistent sessions
+		cfg.ClientID = fmt.Sprintf("raptor-%s", src.FQN())
+		if hostname, err := os.Hostname(); err == nil {
+			cfg.ClientID = fmt.Sprintf("%s-%s", cfg.ClientID, hostname)
+		}
+	}
+
+	logger = logger.WithValues("datasource", src.FQN())
+	return &Runner{
+		cfg: cfg,
+		executor: &runner.Executor{
+			DataSource: client.ObjectKeyFromObject(src),
+			Client:     k8s,
+			Engine:     engine,
+			Runtime:    runtime,
+			Logger:     logger,
+		},
+		logger: logger,
+	}, nil
+}
+
+// Run consumes the messages until the context is canceled.
+func (r *Runner) Run(ctx context.Context) error {
+	if err := r.refreshFeatures(ctx); err != nil {
+		return err
+	}
+
+	opts, err := r.clientOptions(ctx)
+	if err != nil {
+		return err
+	}
+	c := paho.NewClient(opts)
+	if t := c.Connect(); t.Wait() && t.Error() != nil {
+		return fmt.Errorf("failed to connect to the MQTT broker: %w", t.Error())
+	}
+	defer c.Disconnect(250)
+
+	ticker := time.NewTicker(featuresRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.refreshFeatures(ctx); err != nil {
+				r.logger.Error(err, "failed to refresh features")
+			}
+		}
+	}
+}
+
+func (r *Runner) clientOptions(ctx context.Context) (*paho.ClientOptions, error) {
+	opts := paho.NewClientOptions().
+		AddBroker(r.cfg.Broker).
+		SetClientID(r.cfg.ClientID).
+		SetAutoReconnect(true).
+		SetOrderMatters(false)
+
+	if r.cfg.CleanSession != nil {
+		opts.SetCleanSession(*r.cfg.CleanSession)
+	}
+	if r.cfg.Username != "" {
+		opts.SetUsername(r.cfg.Username)
+		opts.SetPassword(r.cfg.Password)
+	}
+	if r.cfg.TLS() {
+		tlsCfg, err := r.cfg.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts.SetTLSConfig(tlsCfg)
+	}
+
+	// subscriptions are (re)established on every connection, so reconnects with a clean session won't lose them
+	opts.SetOnConnectHandler(func(c paho.Client) {
+		filters := make(map[string]byte, len(r.cfg.Topics))
+		for _, t := range r.cfg.Topics {
+			filters[t] = r.cfg.QoS
+		}
+		t := c.SubscribeMultiple(filters, func(_ paho.Client, msg paho.Message) {
+			r.handle(ctx, msg)
+		})
+		if t.Wait() && t.Error() != nil {
+			r.logger.Error(t.Error(), "failed to subscribe to topics", "topics", r.cfg.Topics)
+			return
+		}
+		r.logger.Info("subscribed to topics", "topics", r.cfg.Topics, "qos", r.cfg.QoS)
+	})
+	opts.SetConnectionLostHandler(func(_ paho.Client, err error) {
+		r.logger.Error(err, "connection to the MQTT broker lost")
+	})
+	return opts, nil
+}
+
+func (r *Runner) refreshFeatures(ctx context.Context) error {
+	snap, err := r.executor.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	r.snapshot.Store(snap)
+	return nil
+}
+
+func (r *Runner) handle(ctx context.Context, msg paho.Message) {
+	snap := r.snapshot.Load()
+	if snap == nil || len(snap.Features) == 0 {
+		return
+	}
+
+	row := r.row(msg)
+
+	ts := time.Now()
+	if r.cfg.TimestampField != "" {
+		if t, err := api.ParseTimestamp(row[r.cfg.TimestampField]); err == nil {
+			ts = t
+		} else {
+			r.logger.V(1).Info("failed to parse timestamp. using the receive time instead", "error", err.Error())
+		}
+	}
+	r.executor.Execute(ctx, snap, row, ts)
+}
+
+// row decodes the message's payload and enriches it with the named topic levels.
+func (r *Runner) row(msg paho.Message) map[string]any {
+	row := make(map[string]any)
+
+	var payload any
+	if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+		// non-JSON payloads (i.e. raw sensor readings) are passed as is
+		payload = string(msg.Payload())
+	}
+	switch p := payload.(type) {
+	case map[string]any:
+		for k, v := range p {
+			row[k] = api.NormalizeValue(v)
+		}
+	default:
+		row[valueField] = api.NormalizeValue(p)
+	}
+
+	if len(r.cfg.TopicFields) > 0 {
+		for _, filter := range r.cfg.Topics {
+			levels, ok := matchTopic(filter, msg.Topic())
+			if !ok {
+				continue
+			}
+			for i, name := range r.cfg.TopicFields {
+				if i < len(levels) {
+					row[name] = levels[i]
+				}
+			}
+			break
+		}
+	}
+	return row
+}
@@ -0,0 +1,60 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ondemand
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	"time"
+)
+
+const name = api.OnDemandBuilder
+
+func init() {
+	plugins.FeatureAppliers.Register(name, FeatureApply)
+}
+
+// FeatureApply computes the feature on every request, by executing its program with the request context that was
+// sent by the caller as the row. The program can get other (stored) features, so they can be combined with the
+// request context.
+func FeatureApply(fd api.FeatureDescriptor, _ manifests.FeatureBuilder, pl api.Pipeliner, engine api.ExtendedManager) error {
+	if fd.DataSource != "" {
+		return fmt.Errorf("DataSource can't be set for `%s` builder", name)
+	}
+	e := mw{engine}
+	pl.AddPreGetMiddleware(0, e.getMiddleware)
+	return nil
+}
+
+type mw struct {
+	api.RuntimeManager
+}
+
+func (p *mw) getMiddleware(next api.MiddlewareHandler) api.MiddlewareHandler {
+	return func(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, val api.Value) (api.Value, error) {
+		// on-demand programs are executed as dry-run, so they can't have side effects
+		rc := api.RequestContextFromContext(ctx)
+		val, keys, err := p.ExecuteProgram(ctx, fd.RuntimeEnv, fd.FQN, keys, rc, time.Now(), true)
+		if err != nil {
+			return val, fmt.Errorf("failed to execute on-demand program: %w", err)
+		}
+		return next(ctx, fd, keys, val)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgres
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/raptor-ml/raptor/api"
+	"time"
+)
+
+// Fields that are added to every row.
+const (
+	tableField     = "_table"
+	operationField = "_operation"
+)
+
+// decoder decodes the tuples of the pgoutput messages to rows.
+type decoder struct {
+	cfg       *Config
+	types     *pgtype.Map
+	relations map[uint32]*pglogrepl.RelationMessage
+}
+
+func newDecoder(cfg *Config) *decoder {
+	return &decoder{
+		cfg:       cfg,
+		types:     pgtype.NewMap(),
+		relations: make(map[uint32]*pglogrepl.RelationMessage),
+	}
+}
+
+func (d *decoder) relation(rel *pglogrepl.RelationMessage) {
+	d.relations[rel.RelationID] = rel
+}
+
+// row decodes a tuple of the relation. It returns false if the change should be skipped.
+func (d *decoder) row(relationID uint32, op Operation, tuple *pglogrepl.TupleData) (map[string]any, bool, error) {
+	if !d.cfg.operationAllowed(op) || tuple == nil {
+		return nil, false, nil
+	}
+	rel, ok := d.relations[relationID]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown relation id %d", relationID)
+	}
+	if !d.cfg.tableAllowed(rel.Namespace, rel.RelationName) {
+		return nil, false, nil
+	}
+
+	row := map[string]any{
+		tableField:     fmt.Sprintf("%s.%s", rel.Namespace, rel.RelationName),
+		operationField: string(op),
+	}
+	for i, col := range tuple.Columns {
+		if i >= len(rel.Columns) {
+			break
+		}
+		rc := rel.Columns[i]
+		if !d.cfg.columnAllowed(rc.Name) {
+			continue
+		}
+		switch col.DataType {
+		case pglogrepl.TupleDataTypeNull:
+			row[rc.Name] = nil
+		case pglogrepl.TupleDataTypeToast:
+			// unchanged TOASTed values are not sent by the server
+			continue
+		case pglogrepl.TupleDataTypeText:
+			v, err := d.decode(col.Data, rc.DataType)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to decode column `%s`: %w", rc.Name, err)
+			}
+			row[rc.Name] = v
+		}
+	}
+	return row, true, nil
+}
+
+func (d *decoder) decode(data []byte, oid uint32) (any, error) {
+	dt, ok := d.types.TypeForOID(oid)
+	if !ok {
+		return string(data), nil
+	}
+	v, err := dt.Codec.DecodeValue(d.types, oid, pgtype.TextFormatCode, data)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeValue(v), nil
+}
+
+// normalizeValue converts the decoded values to values that are supported by the runtime.
+func normalizeValue(v any) any {
+	switch v := v.(type) {
+	case int16:
+		return int(v)
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float32:
+		return float64(v)
+	case pgtype.Numeric:
+		f, err := v.Float64Value()
+		if err != nil || !f.Valid {
+			return nil
+		}
+		if f.Float64 == float64(int(f.Float64)) && v.Exp >= 0 {
+			return int(f.Float64)
+		}
+		return f.Float64
+	case time.Time:
+		return v
+	case [16]byte:
+		return uuid.UUID(v).String()
+	case []byte:
+		return string(v)
+	case []any:
+		vals := make([]any, len(v))
+		for i, v2 := range v {
+			vals[i] = normalizeValue(v2)
+		}
+		return api.NormalizeList(vals)
+	case map[string]any:
+		return api.NormalizeValue(v)
+	case string, int, float64, bool, nil:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/runner"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+// featuresRefreshInterval is the interval of refreshing the Features that are attached to the DataSource.
+const featuresRefreshInterval = time.Minute
+
+// duplicateObjectCode is the error code that is returned when the replication slot already exists.
+const duplicateObjectCode = "42710"
+
+type event struct {
+	row map[string]any
+	ts  time.Time
+	lsn pglogrepl.LSN
+}
+
+// Runner consumes the logical replication stream (pgoutput) of a `postgres` DataSource and feeds the changes to the
+// features' programs.
+//
+// Changes are delivered at-least-once: the events of a transaction are executed when it's committed, and only then
+// its position is confirmed to the server.
+type Runner struct {
+	cfg      Config
+	slot     string
+	executor *runner.Executor
+	snapshot *runner.Snapshot
+	logger   logr.Logger
+}
+
+// NewRunner creates a new Postgres Runner for the given DataSource.
+func NewRunner(ctx context.Context, src *manifests.DataSource, k8s client.Reader, engine api.Engine, runtime api.RuntimeManager, logger logr.Logger) (*Runner, error) {
+	pc, err := src.ParseConfig(ctx, k8s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DataSource config: %w", err)
+	}
+	if err := pc.DecodeSecrets(src.Spec.Config); err != nil {
+		return nil, err
+	}
+	cfg := Config{}
+	if err := pc.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal DataSource config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	logger = logger.WithValues("datasource", src.FQN())
+	return &Runner{
+		cfg:  cfg,
+		slot: cfg.SlotName(src.FQN()),
+		executor: &runner.Executor{
+			DataSource: client.ObjectKeyFromObject(src),
+			Client:     k8s,
+			Engine:     engine,
+			Runtime:    runtime,
+			Logger:     logger,
+		},
+		logger: logger,
+	}, nil
+}
+
+// Run consumes the replication stream until the context is canceled.
+func (r *Runner) Run(ctx context.Context) error {
+	if err := r.refreshFeatures(ctx); err != nil {
+		return err
+	}
+
+	conn, err := pgconn.Connect(ctx, r.cfg.ReplicationDSN())
+	if err != nil {
+		return fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	if err := r.createSlot(ctx, conn); err != nil {
+		return err
+	}
+	err = pglogrepl.StartReplication(ctx, conn, r.slot, 0, pglogrepl.StartReplicationOptions{
+		PluginArgs: []string{
+			"proto_version '1'",
+			fmt.Sprintf("publication_names '%s'", r.cfg.Publication),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start replication: %w", err)
+	}
+	r.logger.Info("replication started", "slot", r.slot, "publication", r.cfg.Publication)
+
+	return r.consume(ctx, conn)
+}
+
+// createSlot creates the replication slot, unless it already exists.
+func (r *Runner) createSlot(ctx context.Context, conn *pgconn.PgConn) error {
+	_, err := pglogrepl.CreateReplicationSlot(ctx, conn, r.slot, "pgoutput", pglogrepl.CreateReplicationSlotOptions{
+		Temporary: r.cfg.TemporarySlot,
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == duplicateObjectCode {
+		r.logger.V(1).Info("replication slot already exists", "slot", r.slot)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create replication slot `%s`: %w", r.slot, err)
+	}
+	r.logger.Info("replication slot created", "slot", r.slot)
+	return nil
+}
+
+func (r *Runner) consume(ctx context.Context, conn *pgconn.PgConn) error {
+	dec := newDecoder(&r.cfg)
+
+	var (
+		confirmed pglogrepl.LSN
+		tx        []event
+		commitTs  time.Time
+	)
+	nextStatus := time.Now().Add(r.cfg.StatusInterval)
+	nextRefresh := time.Now().Add(featuresRefreshInterval)
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if time.Now().After(nextRefresh) {
+			if err := r.refreshFeatures(ctx); err != nil {
+				r.logger.Error(err, "failed to refresh features")
+			}
+			nextRefresh = time.Now().Add(featuresRefreshInterval)
+		}
+		if time.Now().After(nextStatus) {
+			if err := r.sendStatus(ctx, conn, confirmed); err != nil {
+				return err
+			}
+			nextStatus = time.Now().Add(r.cfg.StatusInterval)
+		}
+
+		rctx, cancel := context.WithDeadline(ctx, nextStatus)
+		msg, err := conn.ReceiveMessage(rctx)
+		cancel()
+		if err != nil {
+			if pgconn.Timeout(err) || ctx.Err() != nil {
+				continue
+			}
+			return fmt.Errorf("failed to receive message: %w", err)
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.ErrorResponse:
+			return fmt.Errorf("replication failed: %s", msg.Message)
+		case *pgproto3.CopyData:
+			if len(msg.Data) == 0 {
+				continue
+			}
+			switch msg.Data[0] {
+			case pglogrepl.PrimaryKeepaliveMessageByteID:
+				pkm, err := pglogrepl.ParsePrimaryKeepaliveMessage(msg.Data[1:])
+				if err != nil {
+					return fmt.Errorf("failed to parse keepalive message: %w", err)
+				}
+				if pkm.ReplyRequested {
+					nextStatus = time.Time{}
+				}
+			case pglogrepl.XLogDataByteID:
+				xld, err := pglogrepl.ParseXLogData(msg.Data[1:])
+				if err != nil {
+					return fmt.Errorf("failed to parse xlog data: %w", err)
+				}
+				lmsg, err := pglogrepl.Parse(xld.WALData)
+				if err != nil {
+					return fmt.Errorf("failed to parse logical replication message: %w", err)
+				}
+
+				switch lmsg := lmsg.(type) {
+				case *pglogrepl.RelationMessage:
+					dec.relation(lmsg)
+				case *pglogrepl.BeginMessage:
+					tx = tx[:0]
+					commitTs = lmsg.CommitTime
+				case *pglogrepl.InsertMessage:
+					tx = r.appendEvent(tx, dec, lmsg.RelationID, OperationInsert, lmsg.Tuple, commitTs, xld.WALStart)
+				case *pglogrepl.UpdateMessage:
+					tx = r.appendEvent(tx, dec, lmsg.RelationID, OperationUpdate, lmsg.NewTuple, commitTs, xld.WALStart)
+				case *pglogrepl.DeleteMessage:
+					tx = r.appendEvent(tx, dec, lmsg.RelationID, OperationDelete, lmsg.OldTuple, commitTs, xld.WALStart)
+				case *pglogrepl.CommitMessage:
+					r.execute(ctx, tx)
+					tx = tx[:0]
+					confirmed = lmsg.TransactionEndLSN
+				}
+			}
+		}
+	}
+}
+
+func (r *Runner) appendEvent(tx []event, dec *decoder, relationID uint32, op Operation, tuple *pglogrepl.TupleData, commitTs time.Time, lsn pglogrepl.LSN) []event {
+	row, ok, err := dec.row(relationID, op, tuple)
+	if err != nil {
+		r.logger.Error(err, "failed to decode change", "operation", op)
+		return tx
+	}
+	if !ok {
+		return tx
+	}
+
+	ts := commitTs
+	if r.cfg.TimestampField != "" {
+		if t, err := api.ParseTimestamp(row[r.cfg.TimestampField]); err == nil {
+			ts = t
+		} else {
+			r.logger.V(1).Info("failed to parse timestamp. using the commit time instead", "error", err.Error())
+		}
+	}
+	return append(tx, event{row: row, ts: ts, lsn: lsn})
+}
+
+func (r *Runner) execute(ctx context.Context, tx []event) {
+	if r.snapshot == nil || len(r.snapshot.Features) == 0 {
+		return
+	}
+	for _, e := range tx {
+		// the LSN of the change identifies it, so changes that are streamed again after a reconnection are idempotent
+		r.executor.Execute(context.WithValue(ctx, api.ContextKeyEventID, e.lsn.String()), r.snapshot, e.row, e.ts)
+	}
+}
+
+func (r *Runner) sendStatus(ctx context.Context, conn *pgconn.PgConn, lsn pglogrepl.LSN) error {
+	err := pglogrepl.SendStandbyStatusUpdate(ctx, conn, pglogrepl.StandbyStatusUpdate{
+		WALWritePosition: lsn,
+		WALFlushPosition: lsn,
+		WALApplyPosition: lsn,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send standby status update: %w", err)
+	}
+	return nil
+}
+
+func (r *Runner) refreshFeatures(ctx context.Context) error {
+	snap, err := r.executor.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	r.snapshot = snap
+	return nil
+}
@@ -0,0 +1,201 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package remote implements the `remote` builder, which delegates the computation of the feature-value to a
+// user-owned gRPC service.
+//
+// The service implements the `ExecuteProgram` method of the `py_runtime.v1alpha1.RuntimeService` contract
+// (api/proto/py_runtime/v1alpha1/api.proto), so it can be written in any language that has gRPC support.
+// `LoadProgram` is never called. The request's deadline is propagated from the Feature's timeout, and the protocol
+// handshake and the trace context are sent in the request metadata (see the protocol package).
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	grpcMiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/raptor-ml/raptor/api"
+	runtimeApi "github.com/raptor-ml/raptor/api/proto/gen/go/py_runtime/v1alpha1"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	"github.com/raptor-ml/raptor/pkg/protocol"
+	"github.com/raptor-ml/raptor/pkg/sdk"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sync"
+	"time"
+)
+
+const name = api.RemoteBuilder
+
+const (
+	defaultTimeout          = 5 * time.Second
+	defaultFailureThreshold = 5
+	defaultCooldown         = 30 * time.Second
+)
+
+func init() {
+	plugins.FeatureAppliers.Register(name, FeatureApply)
+}
+
+// config is the configuration of the `remote` builder.
+type config struct {
+	// Address is the gRPC target of the service. i.e. `dns:///scorer.ml.svc.cluster.local:9000`
+	Address string `json:"address"`
+	// TLS enables a TLS connection to the service.
+	//+optional
+	TLS bool `json:"tls"`
+	// FailureThreshold is the number of consecutive failures that opens the circuit breaker.
+	//+optional
+	FailureThreshold int `json:"failureThreshold"`
+	// Cooldown is the duration the circuit breaker stays open before probing the service again.
+	//+optional
+	Cooldown string `json:"cooldown"`
+}
+
+// conns are the connections to the services, by address. Connections are shared between features.
+var conns sync.Map
+
+func FeatureApply(fd api.FeatureDescriptor, builder manifests.FeatureBuilder, pl api.Pipeliner, engine api.ExtendedManager) error {
+	if fd.DataSource != "" {
+		return fmt.Errorf("`%s` builder doesn't support DataSources", name)
+	}
+
+	cfg := config{}
+	if err := json.Unmarshal(builder.Raw, &cfg); err != nil {
+		return fmt.Errorf("failed to unmarshal `%s` builder config: %w", name, err)
+	}
+	if cfg.Address == "" {
+		return fmt.Errorf("`address` is required for `%s` builder", name)
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaultFailureThreshold
+	}
+	cooldown := defaultCooldown
+	if cfg.Cooldown != "" {
+		d, err := time.ParseDuration(cfg.Cooldown)
+		if err != nil {
+			return fmt.Errorf("invalid `cooldown`: %w", err)
+		}
+		cooldown = d
+	}
+
+	client, err := dial(cfg)
+	if err != nil {
+		return err
+	}
+	r := &remote{
+		client:  client,
+		breaker: &breaker{threshold: cfg.FailureThreshold, cooldown: cooldown},
+	}
+	if fd.Freshness <= 0 {
+		pl.AddPreGetMiddleware(0, r.getMiddleware)
+	} else {
+		pl.AddPostGetMiddleware(0, r.getMiddleware)
+	}
+	return nil
+}
+
+func dial(cfg config) (runtimeApi.RuntimeServiceClient, error) {
+	key := fmt.Sprintf("%s|%t", cfg.Address, cfg.TLS)
+	if cc, ok := conns.Load(key); ok {
+		return runtimeApi.NewRuntimeServiceClient(cc.(*grpc.ClientConn)), nil
+	}
+
+	creds := insecure.NewCredentials()
+	if cfg.TLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	session := protocol.NewSession(protocol.Local(protocol.CapabilityDryRun))
+	cc, err := grpc.Dial(cfg.Address,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUnaryInterceptor(grpcMiddleware.ChainUnaryClient(session.UnaryClientInterceptor())),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial remote service `%s`: %w", cfg.Address, err)
+	}
+	if existing, loaded := conns.LoadOrStore(key, cc); loaded {
+		_ = cc.Close()
+		cc = existing.(*grpc.ClientConn)
+	}
+	return runtimeApi.NewRuntimeServiceClient(cc), nil
+}
+
+type remote struct {
+	client  runtimeApi.RuntimeServiceClient
+	breaker *breaker
+}
+
+func (r *remote) getMiddleware(next api.MiddlewareHandler) api.MiddlewareHandler {
+	return func(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, val api.Value) (api.Value, error) {
+		cache, cacheOk := ctx.Value(api.ContextKeyFromCache).(bool)
+		if cacheOk && cache && val.Fresh && !fd.ValidWindow() {
+			return next(ctx, fd, keys, val)
+		}
+
+		if err := r.breaker.Allow(); err != nil {
+			return val, fmt.Errorf("failed to call remote service of %s: %w", fd.FQN, err)
+		}
+
+		timeout := fd.Timeout
+		if timeout <= 0 {
+			timeout = defaultTimeout
+		}
+		// the deadline is propagated to the service
+		cctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		ts := val.Timestamp
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		req := &runtimeApi.ExecuteProgramRequest{
+			Uuid:      uuid.NewString(),
+			Fqn:       fd.FQN,
+			Keys:      keys,
+			Timestamp: timestamppb.New(ts),
+			DryRun:    true,
+		}
+		resp, err := r.client.ExecuteProgram(cctx, req)
+		r.breaker.Done(err)
+		if err != nil {
+			return val, fmt.Errorf("failed to call remote service of %s: %w", fd.FQN, err)
+		}
+		if resp.Uuid != "" && resp.Uuid != req.Uuid {
+			return val, fmt.Errorf("uuid mismatch")
+		}
+
+		if resp.Timestamp.CheckValid() == nil && !resp.Timestamp.AsTime().IsZero() {
+			ts = resp.Timestamp.AsTime()
+		}
+		if len(resp.Keys) > 0 {
+			keys = resp.Keys
+		}
+		val = api.Value{
+			Value:     sdk.FromValue(resp.Result),
+			Timestamp: ts,
+			Fresh:     true,
+		}
+		return next(ctx, fd, keys, val)
+	}
+}
@@ -0,0 +1,341 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snowflake
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/runner"
+	_ "github.com/snowflakedb/gosnowflake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata columns of the staging table.
+const (
+	actionColumn     = "RAPTOR_ACTION"
+	isUpdateColumn   = "RAPTOR_ISUPDATE"
+	capturedAtColumn = "RAPTOR_CAPTURED_AT"
+	batchColumn      = "RAPTOR_BATCH"
+)
+
+// Runner reads the changes of a Snowflake table stream and feeds them to the features' programs.
+//
+// Consuming a stream advances its offset only by a DML statement, so the changes are first moved to a (transient)
+// staging table, either by the runner or by a Snowflake Task. Every move is a batch that is identified by its capture
+// time (as text, to avoid binding timestamps of a different time zone type). The runner executes the visible batches
+// and only then deletes them from the staging table, so the changes are delivered at-least-once.
+type Runner struct {
+	cfg      Config
+	db       *sql.DB
+	stream   string
+	stage    string
+	columns  []string
+	executor *runner.Executor
+	logger   logr.Logger
+}
+
+// NewRunner creates a new Snowflake Runner for the given DataSource.
+func NewRunner(ctx context.Context, src *manifests.DataSource, k8s client.Reader, engine api.Engine, runtime api.RuntimeManager, logger logr.Logger) (*Runner, error) {
+	pc, err := src.ParseConfig(ctx, k8s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DataSource config: %w", err)
+	}
+	if err := pc.DecodeSecrets(src.Spec.Config); err != nil {
+		return nil, err
+	}
+	cfg := Config{}
+	if err := pc.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal DataSource config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("snowflake", cfg.ConnectionString())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snowflake connection: %w", err)
+	}
+
+	logger = logger.WithValues("datasource", src.FQN())
+	stream := cfg.StreamName(src.FQN())
+	return &Runner{
+		cfg:    cfg,
+		db:     db,
+		stream: stream,
+		stage:  cfg.StageName(stream),
+		executor: &runner.Executor{
+			DataSource: client.ObjectKeyFromObject(src),
+			Client:     k8s,
+			Engine:     engine,
+			Runtime:    runtime,
+			Logger:     logger,
+		},
+		logger: logger,
+	}, nil
+}
+
+// Run polls the stream until the context is canceled.
+func (r *Runner) Run(ctx context.Context) error {
+	defer r.db.Close()
+	if err := r.init(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.poll(ctx); err != nil {
+			r.logger.Error(err, "failed to poll stream", "stream", r.stream)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// init creates the stream, the staging table and the task (if configured).
+func (r *Runner) init(ctx context.Context) error {
+	cols, err := r.tableColumns(ctx)
+	if err != nil {
+		return err
+	}
+	r.columns = cols
+
+	_, err = r.db.ExecContext(ctx, fmt.Sprintf("CREATE STREAM IF NOT EXISTS %s ON TABLE %s APPEND_ONLY = %t",
+		r.stream, r.cfg.Table, r.cfg.AppendOnly))
+	if err != nil {
+		return fmt.Errorf("failed to create stream: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TRANSIENT TABLE IF NOT EXISTS %s AS SELECT %s FROM %s WHERE 1 = 0",
+		r.stage, r.selectList(), r.stream))
+	if err != nil {
+		return fmt.Errorf("failed to create staging table: %w", err)
+	}
+
+	if r.cfg.TaskSchedule == "" {
+		return nil
+	}
+	task := r.stream + "_TASK"
+	_, err = r.db.ExecContext(ctx, fmt.Sprintf(`CREATE TASK IF NOT EXISTS %s
+    WAREHOUSE = '%s'
+    SCHEDULE = '%s'
+    ALLOW_OVERLAPPING_EXECUTION = FALSE
+    COMMENT = 'Move the changes of %s to the Raptor staging table'
+    WHEN SYSTEM$STREAM_HAS_DATA('%s')
+    AS %s`, task, r.cfg.Warehouse(), r.cfg.TaskSchedule, r.cfg.Table, r.stream, r.moveStatement()))
+	if err != nil {
+		return fmt.Errorf("failed to create snowflake task: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, fmt.Sprintf("ALTER TASK %s RESUME", task)); err != nil {
+		return fmt.Errorf("failed to resume snowflake task: %w", err)
+	}
+	return nil
+}
+
+// tableColumns returns the columns of the table that are passed to the features.
+func (r *Runner) tableColumns(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s LIMIT 0", r.cfg.Table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe table `%s`: %w", r.cfg.Table, err)
+	}
+	defer rows.Close()
+	all, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe table `%s`: %w", r.cfg.Table, err)
+	}
+
+	var cols []string
+	for _, c := range all {
+		if r.cfg.columnAllowed(c) {
+			cols = append(cols, c)
+		}
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("none of the `columns` exist in table `%s`", r.cfg.Table)
+	}
+	return cols, nil
+}
+
+func (r *Runner) selectList() string {
+	cols := make([]string, 0, len(r.columns)+4)
+	for _, c := range r.columns {
+		cols = append(cols, quote(c))
+	}
+	return strings.Join(append(cols,
+		"METADATA$ACTION AS "+actionColumn,
+		"METADATA$ISUPDATE AS "+isUpdateColumn,
+		"CURRENT_TIMESTAMP() AS "+capturedAtColumn,
+		`TO_VARCHAR(CURRENT_TIMESTAMP(), 'YYYY-MM-DD HH24:MI:SS.FF9 TZHTZM') AS `+batchColumn,
+	), ", ")
+}
+
+// moveStatement consumes the stream into the staging table.
+func (r *Runner) moveStatement() string {
+	return fmt.Sprintf("INSERT INTO %s SELECT %s FROM %s", r.stage, r.selectList(), r.stream)
+}
+
+func (r *Runner) poll(ctx context.Context) error {
+	snap, err := r.executor.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	if r.cfg.TaskSchedule == "" {
+		if _, err := r.db.ExecContext(ctx, r.moveStatement()); err != nil {
+			return fmt.Errorf("failed to consume stream: %w", err)
+		}
+	}
+
+	batches, err := r.drain(ctx, snap)
+	if err != nil {
+		return err
+	}
+	if len(batches) == 0 {
+		return nil
+	}
+
+	args := make([]any, len(batches))
+	for i, b := range batches {
+		args[i] = b
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(batches)), ", ")
+	_, err = r.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)", r.stage, batchColumn, placeholders), args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete processed changes: %w", err)
+	}
+	return nil
+}
+
+// drain executes the changes of the staging table, and returns the batches that were executed.
+func (r *Runner) drain(ctx context.Context, snap *runner.Snapshot) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s ORDER BY %s", r.stage, capturedAtColumn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staging table: %w", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staging table: %w", err)
+	}
+
+	var batches []string
+	vals := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan change: %w", err)
+		}
+
+		row := make(map[string]any, len(cols))
+		var (
+			action     string
+			isUpdate   bool
+			capturedAt time.Time
+			batch      string
+		)
+		for i, c := range cols {
+			switch c.Name() {
+			case actionColumn:
+				action = fmt.Sprint(vals[i])
+			case isUpdateColumn:
+				isUpdate, _ = strconv.ParseBool(fmt.Sprint(vals[i]))
+			case capturedAtColumn:
+				capturedAt, _ = vals[i].(time.Time)
+			case batchColumn:
+				batch = fmt.Sprint(vals[i])
+			default:
+				row[strings.ToLower(c.Name())] = normalizeValue(vals[i], c.DatabaseTypeName())
+			}
+		}
+		if len(batches) == 0 || batches[len(batches)-1] != batch {
+			batches = append(batches, batch)
+		}
+
+		op, ok := operation(action, isUpdate)
+		if !ok || !r.cfg.operationAllowed(op) || len(snap.Features) == 0 {
+			continue
+		}
+
+		ts := capturedAt
+		if r.cfg.TimestampColumn != "" {
+			if t, ok := row[strings.ToLower(r.cfg.TimestampColumn)].(time.Time); ok {
+				ts = t
+			}
+		}
+		r.executor.Execute(ctx, snap, row, ts)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read staging table: %w", err)
+	}
+	return batches, nil
+}
+
+// operation returns the operation of a change. The old image of an updated row (a DELETE with ISUPDATE) is skipped.
+func operation(action string, isUpdate bool) (Operation, bool) {
+	switch {
+	case action == "INSERT" && isUpdate:
+		return OperationUpdate, true
+	case action == "INSERT":
+		return OperationInsert, true
+	case action == "DELETE" && !isUpdate:
+		return OperationDelete, true
+	default:
+		return "", false
+	}
+}
+
+// normalizeValue converts the scanned values to values that are supported by the runtime.
+func normalizeValue(v any, dbType string) any {
+	switch v := v.(type) {
+	case int64:
+		return int(v)
+	case []byte:
+		return string(v)
+	case string:
+		if dbType == "FIXED" || dbType == "NUMBER" || dbType == "REAL" {
+			if i, err := strconv.Atoi(v); err == nil {
+				return i
+			}
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
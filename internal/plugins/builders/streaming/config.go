@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"fmt"
+	"github.com/raptor-ml/raptor/pkg/schemaregistry"
+	"strings"
+)
+
+// ValueFormat is the encoding of the messages' values.
+type ValueFormat string
+
+const (
+	ValueFormatJSON     ValueFormat = "json"
+	ValueFormatAvro     ValueFormat = "avro"
+	ValueFormatProtobuf ValueFormat = "protobuf"
+)
+
+// Config is the part of the `streaming` DataSource config that is relevant for the Core.
+// The rest of the config is consumed by the streaming runner.
+type Config struct {
+	Topics []string `mapstructure:"topics"`
+	// ValueFormat is the encoding of the messages. Avro and Protobuf messages are decoded using the Schema Registry.
+	//+optional
+	ValueFormat ValueFormat `mapstructure:"value_format"`
+	//+optional
+	SchemaRegistryURL string `mapstructure:"schema_registry_url"`
+	//+optional
+	SchemaRegistryUsername string `mapstructure:"schema_registry_username"`
+	//+optional
+	SchemaRegistryPassword string `mapstructure:"schema_registry_password"`
+	// Subject overrides the default subject name (`<topic>-value`)
+	//+optional
+	Subject string `mapstructure:"subject"`
+}
+
+// Validate checks the config and sets the defaults.
+func (c *Config) Validate() error {
+	c.ValueFormat = ValueFormat(strings.ToLower(string(c.ValueFormat)))
+	switch c.ValueFormat {
+	case "":
+		c.ValueFormat = ValueFormatJSON
+	case ValueFormatJSON, ValueFormatAvro, ValueFormatProtobuf:
+	default:
+		return fmt.Errorf("unsupported `value_format` `%s`", c.ValueFormat)
+	}
+	if c.ValueFormat != ValueFormatJSON && c.SchemaRegistryURL == "" {
+		return fmt.Errorf("`schema_registry_url` is required for `%s` messages", c.ValueFormat)
+	}
+	return nil
+}
+
+// Subjects returns the registry subjects of the topics.
+func (c *Config) Subjects() []string {
+	if c.Subject != "" {
+		return []string{c.Subject}
+	}
+	var ret []string
+	for _, t := range c.Topics {
+		ret = append(ret, schemaregistry.TopicSubject(t))
+	}
+	return ret
+}
@@ -17,11 +17,16 @@ limitations under the License.
 package streaming
 
 import (
+	"context"
 	"fmt"
 	"github.com/raptor-ml/raptor/api"
 	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
 	"github.com/raptor-ml/raptor/pkg/plugins"
 	"github.com/raptor-ml/raptor/pkg/runner"
+	"github.com/raptor-ml/raptor/pkg/schemaregistry"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Image variable is being overwritten by the build process
@@ -42,6 +47,8 @@ func init() {
 	// Register the plugin
 	plugins.DataSourceReconciler.Register(name, reconciler)
 	plugins.FeatureAppliers.Register(name, FeatureApply)
+	// the streaming runner executes only programs: it doesn't apply mappings, nor build field, `sql` or `cel` features
+	plugins.RunnerCapabilities.Register(name)
 }
 
 func FeatureApply(fd api.FeatureDescriptor, builder manifests.FeatureBuilder, pl api.Pipeliner, engine api.ExtendedManager) error {
@@ -57,5 +64,84 @@ func FeatureApply(fd api.FeatureDescriptor, builder manifests.FeatureBuilder, pl
 	if src.Kind != name {
 		return fmt.Errorf("DataSource must be of type `%s`. got `%s`", name, src.Kind)
 	}
+	if err := plugins.RunnerCapabilities.Require(src, builder); err != nil {
+		return err
+	}
+
+	pc, err := src.DecodedConfig()
+	if err != nil {
+		return err
+	}
+	cfg := Config{}
+	if err := pc.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("failed to unmarshal DataSource config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if cfg.SchemaRegistryURL == "" {
+		return nil
+	}
+	return validateSchema(fd, cfg, builder.Field)
+}
+
+// registries are the Schema Registry clients of the DataSources, so their cached schemas are shared by the binds.
+var registries sync.Map
+
+func registry(cfg Config) (*schemaregistry.Client, error) {
+	key := strings.Join([]string{cfg.SchemaRegistryURL, cfg.SchemaRegistryUsername, cfg.SchemaRegistryPassword}, "\x00")
+	if sr, ok := registries.Load(key); ok {
+		return sr.(*schemaregistry.Client), nil
+	}
+	sr, err := schemaregistry.New(cfg.SchemaRegistryURL, cfg.SchemaRegistryUsername, cfg.SchemaRegistryPassword)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := registries.LoadOrStore(key, sr)
+	return actual.(*schemaregistry.Client), nil
+}
+
+// validateSchema validates the feature against the registered schemas of the DataSource's topics.
+// The latest schemas are cached by the client (see schemaregistry.LatestTTL), so binding the features of a
+// DataSource queries the registry once per subject.
+func validateSchema(fd api.FeatureDescriptor, cfg Config, field string) error {
+	sr, err := registry(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, subject := range cfg.Subjects() {
+		schema, err := sr.Latest(ctx, subject)
+		if err != nil {
+			return fmt.Errorf("failed to get the schema of subject `%s`: %w", subject, err)
+		}
+		if !strings.EqualFold(string(schema.Type), string(cfg.ValueFormat)) {
+			return fmt.Errorf("subject `%s` is registered with a %s schema, but `value_format` is `%s`", subject, schema.Type, cfg.ValueFormat)
+		}
+
+		fields, err := schema.Fields()
+		if err != nil {
+			return fmt.Errorf("failed to parse the schema of subject `%s`: %w", subject, err)
+		}
+		for _, k := range fd.Keys {
+			if _, ok := fields[k]; !ok {
+				return fmt.Errorf("key `%s` is not a field of subject `%s`", k, subject)
+			}
+		}
+		if field == "" {
+			continue
+		}
+		ft, ok := fields[field]
+		if !ok {
+			return fmt.Errorf("field `%s` is not a field of subject `%s`", field, subject)
+		}
+		if !schemaregistry.Compatible(ft, fd.Primitive) {
+			return fmt.Errorf("field `%s` of subject `%s` is of type `%s`, which is incompatible with the feature primitive `%s`",
+				field, subject, ft, fd.Primitive)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,256 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wasm
+
+// The ABI between the core and the guest modules.
+//
+// Data is passed as JSON through the guest's linear memory. Buffers that are passed to the guest are allocated by
+// calling its exported `alloc(size i32) -> i32` function, and buffers that are returned to the host are packed to a
+// single i64 as `ptr<<32 | len`.
+//
+// The guest must export:
+//   - `memory`
+//   - `alloc(size i32) -> i32`
+//   - `handler(ptr i32, len i32) -> i64` (or the configured entrypoint). The input is a JSON `request`, and the output
+//     is a JSON `response`. Returning 0 means that there's no value.
+//
+// The guest may import the following functions from the `raptor` module:
+//   - `get(sel_ptr, sel_len, keys_ptr, keys_len i32) -> i64` returns a JSON `hostValue`, or 0 if not found.
+//   - `set(sel_ptr, sel_len, keys_ptr, keys_len, val_ptr, val_len i32) -> i32` returns 0 on success.
+//   - `incr(sel_ptr, sel_len, keys_ptr, keys_len, val_ptr, val_len i32) -> i32` returns 0 on success.
+//   - `log(ptr, len i32)` writes a message to the core's log.
+//
+// Keys are passed as a JSON object. Empty keys default to the keys of the executed feature.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/tetratelabs/wazero"
+	wapi "github.com/tetratelabs/wazero/api"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
+)
+
+const hostModule = "raptor"
+
+const (
+	statusOK uint32 = iota
+	statusError
+)
+
+type request struct {
+	FQN       string         `json:"fqn"`
+	Keys      api.Keys       `json:"keys"`
+	Row       map[string]any `json:"row,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+	DryRun    bool           `json:"dry_run"`
+}
+
+type response struct {
+	Value     any        `json:"value"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Keys      api.Keys   `json:"keys,omitempty"`
+}
+
+type hostValue struct {
+	Value     any       `json:"value"`
+	Timestamp time.Time `json:"timestamp"`
+	Fresh     bool      `json:"fresh"`
+}
+
+// callState is the state of an execution. It is passed to the host functions via the context.
+type callState struct {
+	engine    api.Engine
+	fd        api.FeatureDescriptor
+	namespace string
+	keys      api.Keys
+	ts        time.Time
+	dryRun    bool
+}
+
+type ctxKey struct{}
+
+func stateFrom(ctx context.Context) *callState {
+	s, _ := ctx.Value(ctxKey{}).(*callState)
+	return s
+}
+
+// instantiateHost instantiates the `raptor` host module in the runtime.
+func instantiateHost(ctx context.Context, r wazero.Runtime) error {
+	_, err := r.NewHostModuleBuilder(hostModule).
+		NewFunctionBuilder().WithFunc(hostGet).Export("get").
+		NewFunctionBuilder().WithFunc(hostSet).Export("set").
+		NewFunctionBuilder().WithFunc(hostIncr).Export("incr").
+		NewFunctionBuilder().WithFunc(hostLog).Export("log").
+		Instantiate(ctx)
+	return err
+}
+
+func hostGet(ctx context.Context, m wapi.Module, selPtr, selLen, keysPtr, keysLen uint32) uint64 {
+	s := stateFrom(ctx)
+	if s == nil {
+		return 0
+	}
+	selector, keys, err := s.args(m, selPtr, selLen, keysPtr, keysLen)
+	if err != nil {
+		s.error(ctx, err, "get")
+		return 0
+	}
+	val, fd, err := s.engine.Get(ctx, selector, keys)
+	if err == nil {
+		// the module runs within the Core, so it's given the decrypted values of encrypted features
+		val, err = api.DecryptValue(ctx, s.engine, fd, keys, val)
+	}
+	if err != nil {
+		s.error(ctx, err, "get", "selector", selector)
+		return 0
+	}
+	if val.Value == nil {
+		return 0
+	}
+	ret, err := writeJSON(ctx, m, hostValue{Value: val.Value, Timestamp: val.Timestamp, Fresh: val.Fresh})
+	if err != nil {
+		s.error(ctx, err, "get", "selector", selector)
+		return 0
+	}
+	return ret
+}
+
+func hostSet(ctx context.Context, m wapi.Module, selPtr, selLen, keysPtr, keysLen, valPtr, valLen uint32) uint32 {
+	return update(ctx, m, "set", selPtr, selLen, keysPtr, keysLen, valPtr, valLen)
+}
+
+func hostIncr(ctx context.Context, m wapi.Module, selPtr, selLen, keysPtr, keysLen, valPtr, valLen uint32) uint32 {
+	return update(ctx, m, "incr", selPtr, selLen, keysPtr, keysLen, valPtr, valLen)
+}
+
+func update(ctx context.Context, m wapi.Module, op string, selPtr, selLen, keysPtr, keysLen, valPtr, valLen uint32) uint32 {
+	s := stateFrom(ctx)
+	if s == nil {
+		return statusError
+	}
+	selector, keys, err := s.args(m, selPtr, selLen, keysPtr, keysLen)
+	if err != nil {
+		s.error(ctx, err, op)
+		return statusError
+	}
+	fd, err := s.engine.FeatureDescriptor(ctx, selector)
+	if err != nil {
+		s.error(ctx, err, op, "selector", selector)
+		return statusError
+	}
+
+	var raw any
+	if err := readJSON(m, valPtr, valLen, &raw); err != nil {
+		s.error(ctx, err, op, "selector", selector)
+		return statusError
+	}
+	val, err := api.FromJSONValue(raw, fd.Primitive)
+	if err != nil {
+		s.error(ctx, err, op, "selector", selector)
+		return statusError
+	}
+	if s.dryRun {
+		return statusOK
+	}
+
+	switch op {
+	case "incr":
+		err = s.engine.Incr(ctx, fd.FQN, keys, val, s.ts)
+	default:
+		err = s.engine.Set(ctx, fd.FQN, keys, val, s.ts)
+	}
+	if err != nil {
+		s.error(ctx, err, op, "selector", selector)
+		return statusError
+	}
+	return statusOK
+}
+
+func hostLog(ctx context.Context, m wapi.Module, ptr, size uint32) {
+	buf, ok := m.Memory().Read(ptr, size)
+	if !ok {
+		return
+	}
+	logger := log.FromContext(ctx)
+	if s := stateFrom(ctx); s != nil {
+		logger = logger.WithValues("feature", s.fd.FQN)
+	}
+	logger.Info(string(buf))
+}
+
+// args reads the selector and keys arguments of the host functions.
+func (s *callState) args(m wapi.Module, selPtr, selLen, keysPtr, keysLen uint32) (string, api.Keys, error) {
+	sel, ok := m.Memory().Read(selPtr, selLen)
+	if !ok {
+		return "", nil, fmt.Errorf("selector is out of memory range")
+	}
+	selector, err := api.NormalizeSelector(string(sel), s.namespace)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid selector `%s`: %w", sel, err)
+	}
+
+	keys := api.Keys{}
+	if keysLen > 0 {
+		if err := readJSON(m, keysPtr, keysLen, &keys); err != nil {
+			return "", nil, fmt.Errorf("invalid keys: %w", err)
+		}
+	}
+	if len(keys) == 0 {
+		keys = s.keys
+	}
+	return selector, keys, nil
+}
+
+func (s *callState) error(ctx context.Context, err error, op string, kv ...any) {
+	log.FromContext(ctx).Error(err, "wasm host call failed", append([]any{"feature", s.fd.FQN, "op", op}, kv...)...)
+}
+
+func readJSON(m wapi.Module, ptr, size uint32, v any) error {
+	buf, ok := m.Memory().Read(ptr, size)
+	if !ok {
+		return fmt.Errorf("buffer is out of memory range")
+	}
+	return json.Unmarshal(buf, v)
+}
+
+// writeJSON writes a JSON encoded value to a buffer that is allocated by the guest, and returns the packed buffer.
+func writeJSON(ctx context.Context, m wapi.Module, v any) (uint64, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode: %w", err)
+	}
+	ret, err := m.ExportedFunction("alloc").Call(ctx, uint64(len(buf)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate guest memory: %w", err)
+	}
+	ptr := uint32(ret[0])
+	if !m.Memory().Write(ptr, buf) {
+		return 0, fmt.Errorf("allocated buffer is out of memory range")
+	}
+	return pack(ptr, uint32(len(buf))), nil
+}
+
+func pack(ptr, size uint32) uint64 {
+	return uint64(ptr)<<32 | uint64(size)
+}
+
+func unpack(v uint64) (ptr, size uint32) {
+	return uint32(v >> 32), uint32(v)
+}
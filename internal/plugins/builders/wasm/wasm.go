@@ -0,0 +1,371 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wasm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/tetratelabs/wazero"
+	wapi "github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const name = api.WasmBuilder
+
+const (
+	defaultEntrypoint = "handler"
+	// maxModuleSize is the maximum size of a module that is fetched from a URL.
+	maxModuleSize = 64 << 20
+	fetchTimeout  = 30 * time.Second
+	// maxRedirects is the maximum number of redirects that are followed when fetching a module.
+	maxRedirects = 5
+	// memoryLimitPages limits the linear memory of each instance (64KiB pages).
+	memoryLimitPages = 512
+)
+
+func init() {
+	plugins.Configurers.Register(name, BindConfig)
+	plugins.FeatureAppliers.Register(name, FeatureApply)
+}
+
+// BindConfig adds the flags of the wasm builder.
+func BindConfig(set *pflag.FlagSet) error {
+	set.StringSlice("wasm-allowed-hosts", nil, "The hosts that WebAssembly modules can be fetched from (i.e. "+
+		"`artifacts.example.com`, or `*.example.com` for its subdomains). Fetching modules from URLs is disabled when empty.")
+	return nil
+}
+
+var (
+	rt     wazero.Runtime
+	rtErr  error
+	rtOnce sync.Once
+
+	// modules are the compiled modules of the bound features, by FQN.
+	modules sync.Map
+)
+
+// runtime returns the shared WebAssembly runtime.
+func runtime() (wazero.Runtime, error) {
+	rtOnce.Do(func() {
+		ctx := context.Background()
+		cfg := wazero.NewRuntimeConfig().
+			WithCloseOnContextDone(true).
+			WithMemoryLimitPages(memoryLimitPages)
+		rt = wazero.NewRuntimeWithConfig(ctx, cfg)
+		if _, err := wasi_snapshot_preview1.Instantiate(ctx, rt); err != nil {
+			rtErr = fmt.Errorf("failed to instantiate WASI: %w", err)
+			return
+		}
+		if err := instantiateHost(ctx, rt); err != nil {
+			rtErr = fmt.Errorf("failed to instantiate the `%s` host module: %w", hostModule, err)
+		}
+	})
+	return rt, rtErr
+}
+
+// FeatureApply compiles the WebAssembly module of the Feature, and registers a middleware that executes it when the
+// feature is requested.
+func FeatureApply(fd api.FeatureDescriptor, builder manifests.FeatureBuilder, pl api.Pipeliner, engine api.ExtendedManager) error {
+	if builder.Wasm == nil {
+		return fmt.Errorf("`wasm` must be set for `%s` builder", name)
+	}
+	if fd.DataSource != "" {
+		return fmt.Errorf("`%s` builder doesn't support DataSources", name)
+	}
+
+	ctx := context.Background()
+	bin, err := load(ctx, builder.Wasm)
+	if err != nil {
+		return err
+	}
+	m, err := compile(ctx, bin, builder.Wasm.Entrypoint)
+	if err != nil {
+		return err
+	}
+	if old, ok := modules.Swap(fd.FQN, m); ok {
+		old.(*module).Close(ctx)
+	}
+
+	ns, _, _, _, _, err := api.ParseSelector(fd.FQN)
+	if err != nil {
+		return fmt.Errorf("failed to parse FQN: %w", err)
+	}
+	e := mw{engine: engine, module: m, namespace: ns}
+	if fd.Freshness <= 0 {
+		pl.AddPreGetMiddleware(0, e.getMiddleware)
+	} else {
+		pl.AddPostGetMiddleware(0, e.getMiddleware)
+	}
+	return nil
+}
+
+// load returns the binary of the module, either embedded or fetched from its URL, and verifies its checksum.
+func load(ctx context.Context, spec *manifests.WasmModule) ([]byte, error) {
+	bin := spec.Module
+	switch {
+	case len(bin) > 0 && spec.URL != "":
+		return nil, fmt.Errorf("wasm `module` and `url` are mutually exclusive")
+	case len(bin) == 0 && spec.URL == "":
+		return nil, fmt.Errorf("wasm `module` or `url` must be set")
+	case spec.URL != "":
+		if spec.SHA256 == "" {
+			return nil, fmt.Errorf("wasm `sha256` must be set when fetching the module from a URL")
+		}
+		var err error
+		bin, err = fetch(ctx, spec.URL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if spec.SHA256 != "" {
+		sum := sha256.Sum256(bin)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), spec.SHA256) {
+			return nil, fmt.Errorf("wasm module checksum mismatch")
+		}
+	}
+	return bin, nil
+}
+
+// fetch fetches the module from an https URL of an allowed host (see `--wasm-allowed-hosts`), so features can't be
+// used to probe the network of the cluster. Redirects are followed only to allowed hosts as well.
+func fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	allowed := viper.GetStringSlice("wasm-allowed-hosts")
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wasm module url: %w", err)
+	}
+	if err := allowedURL(u, allowed); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return allowedURL(req.URL, allowed)
+	}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch wasm module: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch wasm module: unexpected status %s", resp.Status)
+	}
+
+	bin, err := io.ReadAll(io.LimitReader(resp.Body, maxModuleSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wasm module: %w", err)
+	}
+	if len(bin) > maxModuleSize {
+		return nil, fmt.Errorf("wasm module exceeds the maximum size of %d bytes", maxModuleSize)
+	}
+	return bin, nil
+}
+
+// allowedURL checks that the URL is an https URL of an allowed host.
+func allowedURL(u *url.URL, allowed []string) error {
+	if u.Scheme != "https" {
+		return fmt.Errorf("unsupported wasm module url `%s`: only https is supported", u.Redacted())
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range allowed {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if host == h || (strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:])) {
+			return nil
+		}
+	}
+	return fmt.Errorf("wasm module host `%s` is not allowed (see `--wasm-allowed-hosts`)", host)
+}
+
+// module is a compiled module, and a pool of its instances.
+type module struct {
+	compiled   wazero.CompiledModule
+	entrypoint string
+	pool       sync.Pool
+}
+
+func compile(ctx context.Context, bin []byte, entrypoint string) (*module, error) {
+	r, err := runtime()
+	if err != nil {
+		return nil, err
+	}
+	if entrypoint == "" {
+		entrypoint = defaultEntrypoint
+	}
+
+	cm, err := r.CompileModule(ctx, bin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile wasm module: %w", err)
+	}
+	if _, ok := cm.ExportedMemories()["memory"]; !ok {
+		_ = cm.Close(ctx)
+		return nil, fmt.Errorf("wasm module must export `memory`")
+	}
+	fns := cm.ExportedFunctions()
+	for _, fn := range []string{"alloc", entrypoint} {
+		if _, ok := fns[fn]; !ok {
+			_ = cm.Close(ctx)
+			return nil, fmt.Errorf("wasm module must export the `%s` function", fn)
+		}
+	}
+	return &module{compiled: cm, entrypoint: entrypoint}, nil
+}
+
+// Close releases the compiled module. In-flight executions are not affected.
+func (m *module) Close(ctx context.Context) {
+	_ = m.compiled.Close(ctx)
+}
+
+func (m *module) instance(ctx context.Context) (wapi.Module, error) {
+	if inst, ok := m.pool.Get().(wapi.Module); ok {
+		return inst, nil
+	}
+	cfg := wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions("_initialize").
+		WithSysWalltime().
+		WithSysNanotime()
+	inst, err := rt.InstantiateModule(ctx, m.compiled, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate wasm module: %w", err)
+	}
+	return inst, nil
+}
+
+// Execute runs the entrypoint of the module.
+func (m *module) Execute(ctx context.Context, req request) (*response, error) {
+	// Instances are created with a background context, since a closed context terminates them.
+	inst, err := m.instance(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := m.call(ctx, inst, req)
+	if err != nil {
+		// the state of the instance is unknown after a failure (i.e. a trap or a timeout)
+		_ = inst.Close(context.Background())
+		return nil, err
+	}
+	m.pool.Put(inst)
+	return ret, nil
+}
+
+func (m *module) call(ctx context.Context, inst wapi.Module, req request) (*response, error) {
+	in, err := writeJSON(ctx, inst, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write request: %w", err)
+	}
+	ptr, size := unpack(in)
+	if dealloc := inst.ExportedFunction("dealloc"); dealloc != nil {
+		defer func() {
+			_, _ = dealloc.Call(context.Background(), uint64(ptr), uint64(size))
+		}()
+	}
+
+	out, err := inst.ExportedFunction(m.entrypoint).Call(ctx, uint64(ptr), uint64(size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute wasm module: %w", err)
+	}
+	if len(out) == 0 || out[0] == 0 {
+		return &response{}, nil
+	}
+
+	ret := &response{}
+	ptr, size = unpack(out[0])
+	if err := readJSON(inst, ptr, size, ret); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if dealloc := inst.ExportedFunction("dealloc"); dealloc != nil {
+		_, _ = dealloc.Call(ctx, uint64(ptr), uint64(size))
+	}
+	return ret, nil
+}
+
+type mw struct {
+	engine    api.Engine
+	module    *module
+	namespace string
+}
+
+func (p *mw) getMiddleware(next api.MiddlewareHandler) api.MiddlewareHandler {
+	return func(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, val api.Value) (api.Value, error) {
+		cache, cacheOk := ctx.Value(api.ContextKeyFromCache).(bool)
+		if cacheOk && cache && val.Fresh && !fd.ValidWindow() {
+			return next(ctx, fd, keys, val)
+		}
+
+		ts := val.Timestamp
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		if fd.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, fd.Timeout)
+			defer cancel()
+		}
+		ctx = context.WithValue(ctx, ctxKey{}, &callState{
+			engine:    p.engine,
+			fd:        fd,
+			namespace: p.namespace,
+			keys:      keys,
+			ts:        ts,
+			dryRun:    true,
+		})
+
+		resp, err := p.module.Execute(ctx, request{FQN: fd.FQN, Keys: keys, Timestamp: ts, DryRun: true})
+		if err != nil {
+			return val, err
+		}
+		if resp.Value == nil {
+			return next(ctx, fd, keys, val)
+		}
+
+		v, err := api.FromJSONValue(resp.Value, fd.Primitive)
+		if err != nil {
+			return val, fmt.Errorf("invalid value returned by the wasm module: %w", err)
+		}
+		val = api.Value{Value: v, Timestamp: ts, Fresh: true}
+		if resp.Timestamp != nil {
+			val.Timestamp = *resp.Timestamp
+		}
+		if len(resp.Keys) > 0 {
+			keys = resp.Keys
+		}
+		return next(ctx, fd, keys, val)
+	}
+}
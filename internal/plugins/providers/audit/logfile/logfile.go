@@ -0,0 +1,79 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logfile
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"io"
+	"os"
+	"sync"
+)
+
+const pluginName = "log"
+
+func init() {
+	plugins.Configurers.Register("log-audit", BindConfig)
+	plugins.AuditSinkFactories.Register(pluginName, AuditSinkFactory)
+}
+
+// BindConfig adds the flags of the log audit provider.
+func BindConfig(set *pflag.FlagSet) error {
+	set.String("audit-log-file", "", "The file the audit records are appended to (as JSON lines). "+
+		"The records are written to stdout when empty.")
+	return nil
+}
+
+// AuditSinkFactory creates an AuditSink that writes each record as a JSON line to a file (or to stdout), so it can
+// be collected by the log shipper of the cluster.
+func AuditSinkFactory(viper *viper.Viper) (api.AuditSink, error) {
+	var w io.Writer = os.Stdout
+	if path := viper.GetString("audit-log-file"); path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the audit log file: %w", err)
+		}
+		w = f
+	}
+	return &sink{w: w}, nil
+}
+
+type sink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// WriteAudit implements api.AuditSink
+func (s *sink) WriteAudit(_ context.Context, records []api.AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bw := bufio.NewWriter(s.w)
+	enc := json.NewEncoder(bw)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("failed to write audit record: %w", err)
+		}
+	}
+	return bw.Flush()
+}
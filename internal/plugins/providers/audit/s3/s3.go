@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/google/uuid"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"strings"
+	"time"
+)
+
+const pluginName = "s3"
+
+func init() {
+	plugins.Configurers.Register("s3-audit", BindConfig)
+	plugins.AuditSinkFactories.Register(pluginName, AuditSinkFactory)
+}
+
+// BindConfig adds the flags of the S3 audit provider.
+// The AWS credentials and the bucket are shared with the `s3-parquet` historical provider.
+func BindConfig(set *pflag.FlagSet) error {
+	set.String("audit-s3-prefix", "raptor/audit/", "S3 prefix for storing the audit records")
+	return nil
+}
+
+// AuditSinkFactory creates an AuditSink that writes each batch of records as a JSON lines object to S3.
+func AuditSinkFactory(viper *viper.Viper) (api.AuditSink, error) {
+	var opts []func(*config.LoadOptions) error
+	if viper.GetString("aws-access-key") != "" && viper.GetString("aws-secret-key") != "" {
+		opts = append(opts, config.WithCredentialsProvider(credentials.StaticCredentialsProvider{
+			Value: aws.Credentials{
+				AccessKeyID:     viper.GetString("aws-access-key"),
+				SecretAccessKey: viper.GetString("aws-secret-key"),
+			},
+		}))
+	}
+	if viper.GetString("aws-region") != "" {
+		opts = append(opts, config.WithRegion(viper.GetString("aws-region")))
+	}
+	cfg, err := config.LoadDefaultConfig(context.TODO(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	bucket := viper.GetString("s3-bucket")
+	if bucket == "" {
+		return nil, fmt.Errorf("s3-bucket is required")
+	}
+	prefix := viper.GetString("audit-s3-prefix")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	return &sink{
+		client: s3.NewFromConfig(cfg),
+		bucket: bucket,
+		prefix: prefix,
+	}, nil
+}
+
+type sink struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// WriteAudit implements api.AuditSink
+func (s *sink) WriteAudit(ctx context.Context, records []api.AuditRecord) error {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("cannot marshal audit record: %w", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	key := fmt.Sprintf("%sdate=%s/hour=%s/%s.jsonl", s.prefix, now.Format("2006-01-02"), now.Format("15"), uuid.NewString())
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(buf.Bytes()),
+		ContentType: aws.String("application/x-ndjson"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write audit records to s3: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/google/uuid"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"strings"
+	"time"
+)
+
+const pluginName = "s3"
+
+func init() {
+	plugins.Configurers.Register("s3-dlq", BindConfig)
+	plugins.DeadLetterQueueFactories.Register(pluginName, DeadLetterQueueFactory)
+}
+
+// BindConfig adds the flags of the S3 dead-letter queue.
+// The AWS credentials and the bucket are shared with the `s3-parquet` historical provider.
+func BindConfig(set *pflag.FlagSet) error {
+	set.String("dlq-s3-prefix", "raptor/dlq/", "S3 prefix for storing dead-letters")
+	return nil
+}
+
+// DeadLetterQueueFactory creates a DeadLetterQueue that writes each dead-letter as a JSON object to S3.
+// The S3 queue is write-only; the dead-letters can't be listed or replayed via the Core.
+func DeadLetterQueueFactory(viper *viper.Viper) (api.DeadLetterQueue, error) {
+	var opts []func(*config.LoadOptions) error
+	if viper.GetString("aws-access-key") != "" && viper.GetString("aws-secret-key") != "" {
+		opts = append(opts, config.WithCredentialsProvider(credentials.StaticCredentialsProvider{
+			Value: aws.Credentials{
+				AccessKeyID:     viper.GetString("aws-access-key"),
+				SecretAccessKey: viper.GetString("aws-secret-key"),
+			},
+		}))
+	}
+	if viper.GetString("aws-region") != "" {
+		opts = append(opts, config.WithRegion(viper.GetString("aws-region")))
+	}
+	cfg, err := config.LoadDefaultConfig(context.TODO(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	bucket := viper.GetString("s3-bucket")
+	if bucket == "" {
+		return nil, fmt.Errorf("s3-bucket is required")
+	}
+	prefix := viper.GetString("dlq-s3-prefix")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	return &deadLetters{
+		client: s3.NewFromConfig(cfg),
+		bucket: bucket,
+		prefix: prefix,
+	}, nil
+}
+
+type deadLetters struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// SendDeadLetter implements api.DeadLetterQueue
+func (d *deadLetters) SendDeadLetter(ctx context.Context, dl api.DeadLetter) error {
+	if dl.ID == "" {
+		dl.ID = uuid.NewString()
+	}
+	body, err := json.Marshal(dl)
+	if err != nil {
+		return fmt.Errorf("cannot marshal dead-letter: %w", err)
+	}
+
+	failedAt := dl.FailedAt
+	if failedAt.IsZero() {
+		failedAt = time.Now()
+	}
+	key := fmt.Sprintf("%sfqn=%s/date=%s/%s.json", d.prefix, dl.FQN, failedAt.UTC().Format("2006-01-02"), dl.ID)
+	_, err = d.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(d.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write dead-letter to s3: %w", err)
+	}
+	return nil
+}
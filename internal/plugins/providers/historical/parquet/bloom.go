@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parquet
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// bloomFalsePositiveRate is the target false-positive rate of the bloom filters.
+const bloomFalsePositiveRate = 0.01
+
+// bloomFilter is a probabilistic set of the (encoded) keys of a row-group. It's stored in the file's key-value
+// metadata, since the parquet writer doesn't support writing native bloom filters.
+type bloomFilter struct {
+	k    uint32
+	bits []uint64
+}
+
+func newBloomFilter(n int) *bloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	m := math.Ceil(-float64(n) * math.Log(bloomFalsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := uint32(math.Max(1, math.Round(m/float64(n)*math.Ln2)))
+	return &bloomFilter{
+		k:    k,
+		bits: make([]uint64, (uint64(m)+63)/64),
+	}
+}
+
+func (b *bloomFilter) locations(s string) (uint64, uint64) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	sum := h.Sum64()
+	return sum & math.MaxUint32, sum >> 32
+}
+
+func (b *bloomFilter) Add(s string) {
+	h1, h2 := b.locations(s)
+	m := uint64(len(b.bits)) * 64
+	for i := uint64(0); i < uint64(b.k); i++ {
+		idx := (h1 + i*h2) % m
+		b.bits[idx/64] |= 1 << (idx % 64)
+	}
+}
+
+// Has checks if the value might be in the set. False positives are possible, false negatives are not.
+func (b *bloomFilter) Has(s string) bool {
+	h1, h2 := b.locations(s)
+	m := uint64(len(b.bits)) * 64
+	for i := uint64(0); i < uint64(b.k); i++ {
+		idx := (h1 + i*h2) % m
+		if b.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *bloomFilter) String() string {
+	buf := make([]byte, 4+len(b.bits)*8)
+	binary.LittleEndian.PutUint32(buf, b.k)
+	for i, w := range b.bits {
+		binary.LittleEndian.PutUint64(buf[4+i*8:], w)
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+func parseBloomFilter(s string) (*bloomFilter, error) {
+	buf, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bloom filter: %w", err)
+	}
+	if len(buf) < 12 || (len(buf)-4)%8 != 0 {
+		return nil, fmt.Errorf("invalid bloom filter")
+	}
+	b := &bloomFilter{
+		k:    binary.LittleEndian.Uint32(buf),
+		bits: make([]uint64, (len(buf)-4)/8),
+	}
+	for i := range b.bits {
+		b.bits[i] = binary.LittleEndian.Uint64(buf[4+i*8:])
+	}
+	return b, nil
+}
+
+// bloomMetadataPrefix prefixes the key-value metadata keys of the bloom filters.
+const bloomMetadataPrefix = "raptor.bloom."
+
+// bloomMetadataKey is the key of a row-group's bloom filter in the file's key-value metadata.
+func bloomMetadataKey(rowGroup int) string {
+	return fmt.Sprintf("%skeys.%d", bloomMetadataPrefix, rowGroup)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parquet
+
+import (
+	"context"
+	"fmt"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// aliveSuffix is the suffix of the files of the records of the active buckets.
+const aliveSuffix = "-alive.snappy.parquet"
+
+// FileInfo describes a completed parquet file.
+type FileInfo struct {
+	// Path is the path of the file, relative to the base directory.
+	Path     string
+	Size     int64
+	Modified time.Time
+}
+
+// Storage is the storage of the parquet files, which is used to compact them.
+type Storage interface {
+	// List lists the completed parquet files whose path starts with the prefix.
+	List(ctx context.Context, prefix string) ([]FileInfo, error)
+	Open(ctx context.Context, path string) (source.ParquetFile, error)
+	// Create creates a new file in the partition directory, like SourceFactory, and returns its path.
+	Create(ctx context.Context, partition string, alive bool) (string, source.ParquetFile, error)
+	Remove(ctx context.Context, paths []string) error
+}
+
+// Compact implements api.HistoricalCompactor. It merges the files of each partition that are smaller than the target
+// file size into files of up to the target size. The partitions that were written recently are skipped, since their
+// files are still being added.
+//
+// The merged files are removed only after the compacted file is completed, so the readers may read the records of
+// both of them in between.
+func (bw *baseParquet) Compact(ctx context.Context) error {
+	if bw.opts.Storage == nil || bw.opts.TargetFileSize <= 0 {
+		return nil
+	}
+	files, err := bw.opts.Storage.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("cannot list parquet files: %w", err)
+	}
+
+	type group struct {
+		partition string
+		alive     bool
+		files     []FileInfo
+		recent    bool
+	}
+	groups := make(map[string]*group)
+	for _, f := range files {
+		partition := path.Dir(f.Path) + "/"
+		alive := strings.HasSuffix(f.Path, aliveSuffix)
+		key := fmt.Sprintf("%s#%t", partition, alive)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{partition: partition, alive: alive}
+			groups[key] = g
+		}
+		if time.Since(f.Modified) < bw.opts.CompactionMinAge {
+			g.recent = true
+		}
+		if f.Size < bw.opts.TargetFileSize {
+			g.files = append(g.files, f)
+		}
+	}
+
+	for _, g := range groups {
+		if g.recent || len(g.files) < 2 {
+			continue
+		}
+		sort.Slice(g.files, func(i, j int) bool { return g.files[i].Path < g.files[j].Path })
+
+		var batch []FileInfo
+		var size int64
+		for _, f := range g.files {
+			if len(batch) > 0 && size+f.Size > bw.opts.TargetFileSize {
+				if err := bw.merge(ctx, g.partition, g.alive, batch); err != nil {
+					return err
+				}
+				batch, size = nil, 0
+			}
+			batch = append(batch, f)
+			size += f.Size
+		}
+		if err := bw.merge(ctx, g.partition, g.alive, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge writes the records of the files into a new file of the partition, and removes them.
+func (bw *baseParquet) merge(ctx context.Context, partition string, alive bool, files []FileInfo) error {
+	if len(files) < 2 {
+		return nil
+	}
+
+	dest, pf, err := bw.opts.Storage.Create(ctx, partition, alive)
+	if err != nil {
+		return fmt.Errorf("cannot create compacted parquet file: %w", err)
+	}
+	var pw *parquetWriter
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		err := bw.readAll(ctx, f.Path, func(metadata []*parquet.KeyValue, recs []HistoricalRecord) error {
+			if pw == nil {
+				// the privacy tags of the feature are kept, and the bloom filters are written again
+				w, err := newParquetWriter(pf, bw.np, tagsOf(metadata))
+				if err != nil {
+					return err
+				}
+				pw = w
+			}
+			for _, hr := range recs {
+				if err := pw.Write(hr); err != nil {
+					return fmt.Errorf("cannot write record: %w", err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			// the partial file is removed, so its records aren't read twice
+			_ = pf.Close()
+			_ = bw.opts.Storage.Remove(ctx, []string{dest})
+			return fmt.Errorf("cannot compact parquet file %s: %w", f.Path, err)
+		}
+		paths = append(paths, f.Path)
+	}
+	if pw == nil {
+		_ = pf.Close()
+		return bw.opts.Storage.Remove(ctx, []string{dest})
+	}
+	if err := pw.close(); err != nil {
+		_ = bw.opts.Storage.Remove(ctx, []string{dest})
+		return fmt.Errorf("cannot complete compacted parquet file: %w", err)
+	}
+
+	if err := bw.opts.Storage.Remove(ctx, paths); err != nil {
+		return fmt.Errorf("cannot remove compacted parquet files: %w", err)
+	}
+	return nil
+}
+
+// readAll calls fn with the key-value metadata of the file, and every batch of its records.
+func (bw *baseParquet) readAll(ctx context.Context, path string, fn func([]*parquet.KeyValue, []HistoricalRecord) error) error {
+	pf, err := bw.opts.Storage.Open(ctx, path)
+	if err != nil {
+		return fmt.Errorf("cannot open parquet file: %w", err)
+	}
+	defer pf.Close()
+
+	pr, err := reader.NewParquetReader(pf, new(HistoricalRecord), bw.np)
+	if err != nil {
+		return fmt.Errorf("cannot create parquet reader: %w", err)
+	}
+	defer pr.ReadStop()
+
+	for remaining := int(pr.GetNumRows()); remaining > 0; remaining -= readBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		recs := make([]HistoricalRecord, min(remaining, readBatchSize))
+		if err := pr.Read(&recs); err != nil {
+			return fmt.Errorf("cannot read records: %w", err)
+		}
+		if err := fn(pr.Footer.KeyValueMetadata, recs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagsOf returns the privacy tags of the key-value metadata (see tagsMetadata).
+func tagsOf(metadata []*parquet.KeyValue) []*parquet.KeyValue {
+	var ret []*parquet.KeyValue
+	for _, kv := range metadata {
+		if strings.HasPrefix(kv.Key, tagMetadataPrefix) && !strings.HasPrefix(kv.Key, bloomMetadataPrefix) {
+			ret = append(ret, kv)
+		}
+	}
+	return ret
+}
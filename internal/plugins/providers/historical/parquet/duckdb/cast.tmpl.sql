@@ -0,0 +1,10 @@
+{{- /* gotype: github.com/raptor-ml/raptor/api.FeatureDescriptor */ -}}
+{{- /* @formatter:off */ -}}
+{{- /* cast casts the JSON value (VAL) of a feature to its type */ -}}
+{{- define "cast" -}}
+{{- $t := castFeature . -}}
+{{- if eq $t "JSON"}}VAL
+{{- else if eq $t "VARCHAR"}}(VAL ->> '$')
+{{- else}}CAST(VAL AS {{$t}})
+{{- end -}}
+{{- end -}}
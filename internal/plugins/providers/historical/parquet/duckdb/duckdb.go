@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package duckdb implements an embedded query engine over the historical parquet files.
+//
+// The engine runs the point-in-time join and export queries locally, using DuckDB, so small and medium deployments
+// can generate training sets without a data warehouse. It requires a build with the `duckdb` tag (and CGO enabled).
+package duckdb
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"strings"
+	"time"
+)
+
+//go:embed *.tmpl.sql
+var tplFiles embed.FS
+
+const featuresTable = "HISTORICAL_FEATURES"
+
+// ErrDisabled is returned when the binary is built without DuckDB support.
+var ErrDisabled = errors.New("raptor is built without duckdb support. Rebuild with the `duckdb` build tag")
+
+// Config is the configuration of the engine.
+type Config struct {
+	// Path is the path of the DuckDB database file, where the features' views are stored.
+	// Defaults to an in-memory database.
+	Path string
+	// Source is the (glob) location of the historical parquet files. i.e. `s3://bucket/raptor/features/**/*.parquet`
+	Source string
+
+	// AWS credentials to read sources from S3. Defaults to the AWS credential chain.
+	AccessKey string
+	SecretKey string
+	Region    string
+}
+
+// Engine queries the historical data.
+type Engine interface {
+	// BindFeature creates (or replaces) the view of a Feature or a Model.
+	BindFeature(fd *api.FeatureDescriptor, model *manifests.ModelSpec, getter api.FeatureDescriptorGetter) error
+	// Export writes the records of a Feature's or a Model's view between `since` and `until` to the destination.
+	// The format is detected by the destination's extension (`.csv`, `.json` or `.parquet`).
+	Export(ctx context.Context, fqn string, since, until time.Time, dest string) error
+	Close() error
+}
+
+func subtractDuration(d time.Duration, field string) string {
+	return fmt.Sprintf("(%s - to_microseconds(%d))", field, d.Microseconds())
+}
+
+func castFeature(ft api.FeatureDescriptor) string {
+	if ft.ValidWindow() {
+		return "JSON"
+	}
+	switch ft.Primitive {
+	case api.PrimitiveTypeString:
+		return "VARCHAR"
+	case api.PrimitiveTypeInteger:
+		return "BIGINT"
+	case api.PrimitiveTypeFloat:
+		return "DOUBLE"
+	case api.PrimitiveTypeTimestamp:
+		return "TIMESTAMP"
+	case api.PrimitiveTypeStringList:
+		return "VARCHAR[]"
+	case api.PrimitiveTypeIntegerList:
+		return "BIGINT[]"
+	case api.PrimitiveTypeFloatList:
+		return "DOUBLE[]"
+	case api.PrimitiveTypeTimestampList:
+		return "TIMESTAMP[]"
+	}
+	return "JSON"
+}
+
+func escapeName(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func literal(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
+
+// exportFormat returns the COPY format of the destination.
+func exportFormat(dest string) string {
+	switch {
+	case strings.HasSuffix(dest, ".csv"):
+		return "CSV, HEADER"
+	case strings.HasSuffix(dest, ".json"), strings.HasSuffix(dest, ".ndjson"):
+		return "JSON"
+	default:
+		return "PARQUET"
+	}
+}
@@ -0,0 +1,162 @@
+//go:build duckdb
+
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/marcboeker/go-duckdb"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/querybuilder"
+	"strings"
+	"time"
+)
+
+// historicalView flattens the records of the parquet files to the layout that is expected by the query templates.
+// Values are stored as JSON, and casted to the feature's type by the templates.
+const historicalView = `CREATE OR REPLACE VIEW %s AS
+SELECT  fqn AS FQN,
+        keys AS KEYS,
+        "timestamp" AS TIMESTAMP,
+        CASE
+            WHEN bucket IS NULL THEN COALESCE(
+                to_json(value.string), to_json(value.int), to_json(value.double), to_json(value."timestamp"),
+                to_json(value.string_list), to_json(value.int_list), to_json(value.double_list),
+                to_json(value.timestamp_list))
+            ELSE json_object('count', bucket."count", 'sum', bucket.sum, 'min', bucket.min, 'max', bucket.max)
+        END AS VALUE,
+        bucket.bucket_name AS BUCKET,
+        bucket.alive AS BUCKET_ACTIVE
+FROM read_parquet(%s, union_by_name = true, hive_partitioning = false)`
+
+type engine struct {
+	db           *sql.DB
+	queryBuilder querybuilder.QueryBuilder
+}
+
+// New creates a new Engine.
+func New(cfg Config) (Engine, error) {
+	if cfg.Source == "" {
+		return nil, fmt.Errorf("duckdb source is required")
+	}
+	db, err := sql.Open("duckdb", cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open duckdb database: %w", err)
+	}
+
+	e := &engine{
+		db: db,
+		queryBuilder: querybuilder.New(querybuilder.Config{
+			FeaturesTable:    featuresTable,
+			EscapeName:       escapeName,
+			SubtractDuration: subtractDuration,
+			CastFeature:      castFeature,
+			Templates:        tplFiles,
+			Since:            "getvariable('SINCE')",
+			Until:            "getvariable('UNTIL')",
+		}),
+	}
+	if err := e.init(context.TODO(), cfg); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize duckdb: %w", err)
+	}
+	return e, nil
+}
+
+func (e *engine) init(ctx context.Context, cfg Config) error {
+	if strings.HasPrefix(cfg.Source, "s3://") {
+		if _, err := e.db.ExecContext(ctx, "INSTALL httpfs; LOAD httpfs;"); err != nil {
+			return fmt.Errorf("failed to load httpfs extension: %w", err)
+		}
+
+		secret := "TYPE S3, PROVIDER CREDENTIAL_CHAIN"
+		if cfg.AccessKey != "" && cfg.SecretKey != "" {
+			secret = fmt.Sprintf("TYPE S3, KEY_ID %s, SECRET %s", literal(cfg.AccessKey), literal(cfg.SecretKey))
+		}
+		if cfg.Region != "" {
+			secret += fmt.Sprintf(", REGION %s", literal(cfg.Region))
+		}
+		if _, err := e.db.ExecContext(ctx, fmt.Sprintf("CREATE OR REPLACE SECRET raptor_s3 (%s)", secret)); err != nil {
+			return fmt.Errorf("failed to create s3 secret: %w", err)
+		}
+	}
+
+	if _, err := e.db.ExecContext(ctx, fmt.Sprintf(historicalView, featuresTable, literal(cfg.Source))); err != nil {
+		return fmt.Errorf("failed to create historical view: %w", err)
+	}
+	return nil
+}
+
+func (e *engine) BindFeature(fd *api.FeatureDescriptor, model *manifests.ModelSpec, getter api.FeatureDescriptorGetter) error {
+	var query string
+	var typ string
+	if fd.Builder == api.ModelBuilder {
+		typ = "Model"
+		if model == nil {
+			return fmt.Errorf("model is nil")
+		}
+		q, err := e.queryBuilder.FeatureSet(context.TODO(), *model, getter)
+		if err != nil {
+			return fmt.Errorf("failed to build Model query: %w", err)
+		}
+		query = q
+	} else {
+		typ = "Feature"
+		q, err := e.queryBuilder.Feature(*fd)
+		if err != nil {
+			return fmt.Errorf("failed to build Feature query: %w", err)
+		}
+		query = q
+	}
+
+	_, err := e.db.Exec(fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s", escapeName(fd.FQN), query))
+	if err != nil {
+		return fmt.Errorf("failed to create %s view for %s: %w", typ, fd.FQN, err)
+	}
+	return nil
+}
+
+func (e *engine) Export(ctx context.Context, fqn string, since, until time.Time, dest string) error {
+	// variables are scoped to the connection
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get duckdb connection: %w", err)
+	}
+	defer conn.Close()
+
+	const layout = "2006-01-02 15:04:05.999999"
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("SET VARIABLE SINCE = TIMESTAMP %s; SET VARIABLE UNTIL = TIMESTAMP %s;",
+		literal(since.UTC().Format(layout)), literal(until.UTC().Format(layout))))
+	if err != nil {
+		return fmt.Errorf("failed to set export time range: %w", err)
+	}
+
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("COPY (SELECT * FROM %s) TO %s (FORMAT %s)",
+		escapeName(fqn), literal(dest), exportFormat(dest)))
+	if err != nil {
+		return fmt.Errorf("failed to export %s: %w", fqn, err)
+	}
+	return nil
+}
+
+func (e *engine) Close() error {
+	return e.db.Close()
+}
@@ -0,0 +1,84 @@
+{{- /* gotype: github.com/raptor-ml/raptor/pkg/querybuilder.featureSetQuery */ -}}
+{{- /* @formatter:off */ -}}
+{{- /***
+  # Point in time join query
+  --------------------------
+  A CTE is created per feature, and the key feature is joined with each CTE using an ASOF join, which takes the
+  latest value of the feature at the key feature's timestamp.
+
+  1. Prepare the Windows data - for each window create the following CTEs:
+    1.1. winData_f_XX - the dead buckets of the feature
+    1.2. f_XX - for each bucket, aggregate the buckets of the same keys that are within the window
+  2. Prepare the primitives' data - for each primitive create the `f_XX` CTE
+  3. Build the final view by ASOF joining the key feature with each feature CTE
+    3.1. ON f_XX.KEYS = key.KEYS AND key.TIMESTAMP >= f_XX.TIMESTAMP
+    3.2. Values that are stale at the key feature's timestamp are omitted
+ ***/ -}}
+WITH
+{{- range $i, $f := .Features}}
+{{- if $i}},{{end}}
+{{- $n := tmpName $f.FQN}}
+{{- if $f.ValidWindow}}
+    {{- /* 1.1. Get the buckets */}}
+    winData_{{$n}} AS (
+        SELECT  KEYS,
+                TIMESTAMP,
+                CAST(VALUE ->> 'count' AS DOUBLE) AS _COUNT,
+                CAST(VALUE ->> 'sum' AS DOUBLE) AS _SUM,
+                CAST(VALUE ->> 'min' AS DOUBLE) AS _MIN,
+                CAST(VALUE ->> 'max' AS DOUBLE) AS _MAX
+        FROM {{$.FeaturesTable}}
+        WHERE FQN = '{{$f.FQN}}'
+            AND BUCKET IS NOT NULL
+            AND BUCKET_ACTIVE = false
+            AND TIMESTAMP BETWEEN {{subtractDuration $.BeforePadding $.Since}} AND {{$.Until}}
+    ),
+    {{- /* 1.2. Get the windowed feature */}}
+    {{$n}} AS (
+        SELECT  b1.KEYS,
+                b1.TIMESTAMP,
+                json_object(
+                    'count', sum(b2._COUNT)::BIGINT,
+                    'sum', sum(b2._SUM),
+                    'min', min(b2._MIN),
+                    'max', max(b2._MAX),
+                    'avg', sum(b2._SUM) / NULLIF(sum(b2._COUNT), 0)
+                ) AS VAL
+        FROM winData_{{$n}} AS b1
+        JOIN winData_{{$n}} AS b2 ON b2.KEYS = b1.KEYS
+            AND b2.TIMESTAMP > {{subtractDuration $f.Staleness "b1.TIMESTAMP"}}
+            AND b2.TIMESTAMP <= b1.TIMESTAMP
+        GROUP BY b1.KEYS, b1.TIMESTAMP
+    )
+{{- else}}
+    {{- /* 2. Get the primitive feature */}}
+    {{$n}} AS (
+        SELECT  KEYS,
+                TIMESTAMP,
+                {{template "cast" $f}} AS VAL
+        FROM (SELECT KEYS, TIMESTAMP, VALUE AS VAL FROM {{$.FeaturesTable}}
+            WHERE FQN = '{{$f.FQN}}'
+                AND BUCKET IS NULL
+                AND TIMESTAMP BETWEEN {{subtractDuration $f.Staleness $.Since}} AND {{$.Until}})
+    )
+{{- end}}
+{{- end}}
+{{- /* 3. Build the final results */}}
+SELECT  key.TIMESTAMP,
+        key.KEYS
+{{- range $_, $f := .Features}},
+    {{- if eq $f.FQN $.KeyFeature}}
+        key.VAL AS {{escapeName $f.FQN}}
+    {{- else}}
+    {{- $n := tmpName $f.FQN}}
+        CASE WHEN {{$n}}.TIMESTAMP >= {{subtractDuration $f.Staleness "key.TIMESTAMP"}} THEN {{$n}}.VAL END AS {{escapeName $f.FQN}}
+    {{- end}}
+{{- end}}
+FROM (SELECT * FROM {{tmpName .KeyFeature}} WHERE TIMESTAMP >= {{.Since}}) AS key
+{{- range $_, $f := .Features}}
+{{- if eq $f.FQN $.KeyFeature}}{{continue}}{{end}}
+{{- $n := tmpName $f.FQN}}
+    {{- /* 3.1. ASOF join the key feature with the feature's CTE */}}
+    ASOF LEFT JOIN {{$n}} ON {{$n}}.KEYS = key.KEYS AND key.TIMESTAMP >= {{$n}}.TIMESTAMP
+{{- end}}
+ORDER BY key.TIMESTAMP
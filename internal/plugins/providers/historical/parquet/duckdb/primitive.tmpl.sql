@@ -0,0 +1,25 @@
+{{- /* gotype: github.com/raptor-ml/raptor/pkg/querybuilder.featureQuery */ -}}
+{{- /* @formatter:off */ -}}
+WITH data AS (
+    SELECT  FQN,
+            KEYS,
+            TIMESTAMP,
+            VALUE AS VAL
+    FROM {{.FeaturesTable}}
+    WHERE FQN = '{{.FQN}}'
+        AND TIMESTAMP BETWEEN {{.Since}} AND {{.Until}}
+        AND BUCKET IS NULL
+)
+SELECT  FQN,
+        KEYS,
+        TIMESTAMP,
+        {{template "cast" .FeatureDescriptor}} AS VALUE,
+        {{- /* the value is valid until the next value, or until it becomes stale */}}
+        {{- if .Staleness}}
+        LEAST(LEAD(TIMESTAMP) OVER w, TIMESTAMP + to_microseconds({{.Staleness.Microseconds}})) AS VALID_TILL
+        {{- else}}
+        LEAD(TIMESTAMP) OVER w AS VALID_TILL
+        {{- end}}
+FROM data
+WINDOW w AS (PARTITION BY FQN, KEYS ORDER BY TIMESTAMP)
+ORDER BY FQN, KEYS, TIMESTAMP
@@ -0,0 +1,55 @@
+{{- /* gotype: github.com/raptor-ml/raptor/pkg/querybuilder.featureQuery */ -}}
+{{- /* @formatter:off */ -}}
+{{- /***
+  # Windowed feature
+  --------------------------
+  1. buckets - the dead buckets since $SINCE minus the window size
+  2. windows - for each bucket, aggregate the buckets of the same keys that are within the window
+  3. Show the result ordered
+ ***/ -}}
+WITH
+    {{- /* 1. Get the buckets */}}
+    buckets AS (
+        SELECT  FQN,
+                KEYS,
+                TIMESTAMP,
+                CAST(VALUE ->> 'count' AS DOUBLE) AS _COUNT,
+                CAST(VALUE ->> 'sum' AS DOUBLE) AS _SUM,
+                CAST(VALUE ->> 'min' AS DOUBLE) AS _MIN,
+                CAST(VALUE ->> 'max' AS DOUBLE) AS _MAX
+        FROM {{.FeaturesTable}}
+        WHERE FQN = '{{.FQN}}'
+            AND BUCKET IS NOT NULL
+            AND BUCKET_ACTIVE = false
+            AND TIMESTAMP BETWEEN {{subtractDuration .Staleness .Since}} AND {{.Until}}
+    ),
+    {{- /* 2. Aggregate the buckets of each window */}}
+    windows AS (
+        SELECT  b1.FQN,
+                b1.KEYS,
+                b1.TIMESTAMP,
+                sum(b2._COUNT) AS _COUNT,
+                sum(b2._SUM) AS _SUM,
+                min(b2._MIN) AS _MIN,
+                max(b2._MAX) AS _MAX
+        FROM buckets AS b1
+        JOIN buckets AS b2 ON b2.KEYS = b1.KEYS
+            AND b2.TIMESTAMP > {{subtractDuration .Staleness "b1.TIMESTAMP"}}
+            AND b2.TIMESTAMP <= b1.TIMESTAMP
+        WHERE b1.TIMESTAMP >= {{.Since}}
+        GROUP BY b1.FQN, b1.KEYS, b1.TIMESTAMP
+    )
+SELECT  FQN,
+        KEYS,
+        TIMESTAMP,
+        json_object(
+            'count', _COUNT::BIGINT,
+            'sum', _SUM,
+            'min', _MIN,
+            'max', _MAX,
+            'avg', _SUM / NULLIF(_COUNT, 0)
+        ) AS VALUE,
+        LEAST(LEAD(TIMESTAMP) OVER w, TIMESTAMP + to_microseconds({{.Staleness.Microseconds}})) AS VALID_TILL
+FROM windows
+WINDOW w AS (PARTITION BY FQN, KEYS ORDER BY TIMESTAMP)
+ORDER BY FQN, KEYS, TIMESTAMP
@@ -0,0 +1,81 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parquet
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/types"
+	"strings"
+	"time"
+)
+
+// LatestValues implements api.HistoricalLatestReader. The files of the feature's partitions are read entirely, and
+// the latest record of each entity is kept in memory until all of them were read.
+func (bw *baseParquet) LatestValues(ctx context.Context, fqn string, since time.Time, fn func(string, api.Value) error) error {
+	if bw.opts.Storage == nil {
+		return fmt.Errorf("reading the historical data isn't supported by the storage")
+	}
+	files, err := bw.opts.Storage.List(ctx, bw.opts.Partitioning.Prefix(fqn))
+	if err != nil {
+		return fmt.Errorf("cannot list parquet files: %w", err)
+	}
+
+	from := int64(0)
+	if !since.IsZero() {
+		from = micros(since)
+	}
+	latest := make(map[string]HistoricalRecord)
+	for _, f := range files {
+		if strings.HasSuffix(f.Path, aliveSuffix) || !bw.opts.Partitioning.Match(f.Path, fqn, since) {
+			// the files of the active buckets contain only the records of windowed features
+			continue
+		}
+		err := bw.readAll(ctx, f.Path, func(_ []*parquet.KeyValue, recs []HistoricalRecord) error {
+			for _, hr := range recs {
+				if hr.FQN != fqn || hr.Bucket != nil || hr.Timestamp < from {
+					continue
+				}
+				if prev, ok := latest[hr.Keys]; ok && prev.Timestamp > hr.Timestamp {
+					continue
+				}
+				latest[hr.Keys] = hr
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot read parquet file %s: %w", f.Path, err)
+		}
+	}
+
+	for keys, hr := range latest {
+		if hr.Value == nil {
+			// tombstone
+			continue
+		}
+		val := api.Value{
+			Value:     hr.Value.value(),
+			Timestamp: types.TIMESTAMP_MICROSToTime(hr.Timestamp, false),
+		}
+		if err := fn(keys, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
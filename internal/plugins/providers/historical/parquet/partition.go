@@ -0,0 +1,171 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parquet
+
+import (
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"strings"
+	"time"
+)
+
+// The partition keys of the files. The files are partitioned in hive-style directories (i.e. `fqn=x/timestamp=y/`),
+// so they can be queried as partitioned tables by Athena, Trino and DuckDB.
+const (
+	PartitionNamespace = "namespace"
+	PartitionFeature   = "feature"
+	PartitionDate      = "date"
+	PartitionHour      = "hour"
+)
+
+// partitionColumns are the names of the partition keys' columns. `fqn` and `timestamp` are kept for compatibility
+// with the files of the previous versions.
+var partitionColumns = map[string]string{
+	PartitionNamespace: "namespace",
+	PartitionFeature:   "fqn",
+	PartitionDate:      "timestamp",
+	PartitionHour:      "hour",
+}
+
+// Partitioning is the ordered list of the partition keys of the files.
+type Partitioning []string
+
+// DefaultPartitioning partitions the files by the feature, and then by the day they were written.
+var DefaultPartitioning = Partitioning{PartitionFeature, PartitionDate}
+
+// ParsePartitioning validates the partition keys. The feature is required, since every file holds the records of a
+// single feature, and the hour requires the date.
+func ParsePartitioning(keys []string) (Partitioning, error) {
+	if len(keys) == 0 {
+		return DefaultPartitioning, nil
+	}
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if _, ok := partitionColumns[k]; !ok {
+			return nil, fmt.Errorf("unknown partition key `%s`", k)
+		}
+		if seen[k] {
+			return nil, fmt.Errorf("duplicate partition key `%s`", k)
+		}
+		seen[k] = true
+	}
+	if !seen[PartitionFeature] {
+		return nil, fmt.Errorf("the `%s` partition key is required", PartitionFeature)
+	}
+	if seen[PartitionHour] && !seen[PartitionDate] {
+		return nil, fmt.Errorf("the `%s` partition key requires the `%s` partition key", PartitionHour, PartitionDate)
+	}
+	return keys, nil
+}
+
+func (p Partitioning) has(key string) bool {
+	for _, k := range p {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Dir returns the partition directory (relative to the base directory) of the files of the feature that are written
+// at t. It ends with a slash.
+func (p Partitioning) Dir(fqn string, t time.Time) string {
+	sb := strings.Builder{}
+	for _, k := range p {
+		sb.WriteString(partitionColumns[k])
+		sb.WriteByte('=')
+		sb.WriteString(p.value(k, fqn, t))
+		sb.WriteByte('/')
+	}
+	return sb.String()
+}
+
+// Prefix returns the longest prefix of the feature's partition directories, so the files of the feature can be
+// listed without listing the files of the others.
+func (p Partitioning) Prefix(fqn string) string {
+	sb := strings.Builder{}
+	for _, k := range p {
+		if k == PartitionDate || k == PartitionHour {
+			break
+		}
+		sb.WriteString(partitionColumns[k])
+		sb.WriteByte('=')
+		sb.WriteString(p.value(k, fqn, time.Time{}))
+		sb.WriteByte('/')
+	}
+	return sb.String()
+}
+
+// Match returns false when the file (relative to the base directory) can't contain records of the feature that were
+// written at or after from.
+func (p Partitioning) Match(path string, fqn string, from time.Time) bool {
+	values := partitionValues(path)
+	for _, k := range []string{PartitionNamespace, PartitionFeature} {
+		if v, ok := values[partitionColumns[k]]; ok && v != p.value(k, fqn, time.Time{}) {
+			return false
+		}
+	}
+	if from.IsZero() {
+		return true
+	}
+	end, ok := p.End(path)
+	return !ok || !end.Before(from)
+}
+
+// End returns the time the records of the file's partition were written before. It returns false when the files
+// aren't partitioned by the date.
+func (p Partitioning) End(path string) (time.Time, bool) {
+	values := partitionValues(path)
+	d, err := time.Parse("2006-01-02", values[partitionColumns[PartitionDate]])
+	if err != nil {
+		return time.Time{}, false
+	}
+	if h, err := time.Parse("15", values[partitionColumns[PartitionHour]]); err == nil {
+		return d.Add(time.Duration(h.Hour()+1) * time.Hour), true
+	}
+	return d.AddDate(0, 0, 1), true
+}
+
+// partitionValues returns the values of the partition columns of the file's path.
+func partitionValues(path string) map[string]string {
+	parts := strings.Split(path, "/")
+	values := make(map[string]string, len(parts))
+	for _, part := range parts[:len(parts)-1] {
+		if k, v, ok := strings.Cut(part, "="); ok {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+func (p Partitioning) value(key string, fqn string, t time.Time) string {
+	switch key {
+	case PartitionNamespace:
+		ns, _, _, _, _, err := api.ParseSelector(fqn)
+		if err != nil {
+			return ""
+		}
+		return ns
+	case PartitionFeature:
+		return fqn
+	case PartitionDate:
+		return t.UTC().Format("2006-01-02")
+	case PartitionHour:
+		return t.UTC().Format("15")
+	}
+	return ""
+}
@@ -0,0 +1,164 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/types"
+	"strings"
+	"time"
+)
+
+// Predicate selects the historical records to read. Zero values match all the records.
+//
+// Predicates are pushed down to the files: row-groups are skipped by the min/max statistics of the `fqn` and
+// `timestamp` columns, and by the bloom filter of the `keys` column.
+type Predicate struct {
+	// FQN is the fully qualified name of the feature.
+	FQN string
+	// Keys are the encoded keys (entities) to read.
+	Keys []string
+	// From is the (inclusive) lower bound of the records' timestamp.
+	From time.Time
+	// To is the (inclusive) upper bound of the records' timestamp.
+	To time.Time
+}
+
+// Match checks if a record matches the predicate.
+func (p Predicate) Match(hr HistoricalRecord) bool {
+	if p.FQN != "" && hr.FQN != p.FQN {
+		return false
+	}
+	if len(p.Keys) > 0 && !contains(p.Keys, hr.Keys) {
+		return false
+	}
+	if !p.From.IsZero() && hr.Timestamp < micros(p.From) {
+		return false
+	}
+	if !p.To.IsZero() && hr.Timestamp > micros(p.To) {
+		return false
+	}
+	return true
+}
+
+// RowGroups returns the row-groups of the file that might contain matching records.
+func (p Predicate) RowGroups(footer *parquet.FileMetaData) []*parquet.RowGroup {
+	var ret []*parquet.RowGroup
+	for i, rg := range footer.GetRowGroups() {
+		if p.matchRowGroup(rg) && p.matchBloomFilter(footer, i) {
+			ret = append(ret, rg)
+		}
+	}
+	return ret
+}
+
+func (p Predicate) matchRowGroup(rg *parquet.RowGroup) bool {
+	if p.FQN != "" {
+		if minV, maxV, ok := statistics(rg, "fqn"); ok {
+			if bytes.Compare([]byte(p.FQN), minV) < 0 || bytes.Compare([]byte(p.FQN), maxV) > 0 {
+				return false
+			}
+		}
+	}
+	if len(p.Keys) > 0 {
+		if minV, maxV, ok := statistics(rg, "keys"); ok {
+			inRange := false
+			for _, k := range p.Keys {
+				if bytes.Compare([]byte(k), minV) >= 0 && bytes.Compare([]byte(k), maxV) <= 0 {
+					inRange = true
+					break
+				}
+			}
+			if !inRange {
+				return false
+			}
+		}
+	}
+	if !p.From.IsZero() || !p.To.IsZero() {
+		if minV, maxV, ok := statistics(rg, "timestamp"); ok && len(minV) == 8 && len(maxV) == 8 {
+			minTs, maxTs := int64(binary.LittleEndian.Uint64(minV)), int64(binary.LittleEndian.Uint64(maxV))
+			if !p.From.IsZero() && maxTs < micros(p.From) {
+				return false
+			}
+			if !p.To.IsZero() && minTs > micros(p.To) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (p Predicate) matchBloomFilter(footer *parquet.FileMetaData, rowGroup int) bool {
+	if len(p.Keys) == 0 {
+		return true
+	}
+	key := bloomMetadataKey(rowGroup)
+	for _, kv := range footer.GetKeyValueMetadata() {
+		if kv.Key != key || kv.Value == nil {
+			continue
+		}
+		bf, err := parseBloomFilter(*kv.Value)
+		if err != nil {
+			return true
+		}
+		for _, k := range p.Keys {
+			if bf.Has(k) {
+				return true
+			}
+		}
+		return false
+	}
+	// files that were written without bloom filters can't be pruned
+	return true
+}
+
+// statistics returns the (plain encoded) min and max values of a top-level column of the row-group.
+func statistics(rg *parquet.RowGroup, column string) ([]byte, []byte, bool) {
+	for _, cc := range rg.GetColumns() {
+		md := cc.GetMetaData()
+		if md == nil || len(md.PathInSchema) != 1 || !strings.EqualFold(md.PathInSchema[0], column) {
+			continue
+		}
+		st := md.GetStatistics()
+		if st == nil {
+			return nil, nil, false
+		}
+		if st.MinValue != nil && st.MaxValue != nil {
+			return st.MinValue, st.MaxValue, true
+		}
+		if st.Min != nil && st.Max != nil {
+			return st.Min, st.Max, true
+		}
+		return nil, nil, false
+	}
+	return nil, nil, false
+}
+
+func micros(t time.Time) int64 {
+	return types.TimeToTIMESTAMP_MICROS(t, false)
+}
+
+func contains(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
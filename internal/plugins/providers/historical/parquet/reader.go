@@ -0,0 +1,138 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parquet
+
+import (
+	"context"
+	"fmt"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// readBatchSize is the number of records that are decoded at once.
+const readBatchSize = 1024
+
+// FileLister lists the parquet files that might contain records that match the predicate.
+type FileLister func(ctx context.Context, p Predicate) ([]string, error)
+
+// FileOpener opens a parquet file for reading.
+type FileOpener func(ctx context.Context, path string) (source.ParquetFile, error)
+
+// Reader reads the historical records that were written by the parquet HistoricalWriter.
+type Reader struct {
+	np   int64
+	list FileLister
+	open FileOpener
+}
+
+func BaseReader(np int64, list FileLister, open FileOpener) *Reader {
+	return &Reader{
+		np:   np,
+		list: list,
+		open: open,
+	}
+}
+
+// Read calls fn for every record that matches the predicate. Files and row-groups that can't contain matching
+// records are skipped without reading their data.
+func (r *Reader) Read(ctx context.Context, p Predicate, fn func(HistoricalRecord) error) error {
+	files, err := r.list(ctx, p)
+	if err != nil {
+		return fmt.Errorf("cannot list parquet files: %w", err)
+	}
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.readFile(ctx, f, p, fn); err != nil {
+			return fmt.Errorf("cannot read parquet file %s: %w", f, err)
+		}
+	}
+	return nil
+}
+
+func (r *Reader) readFile(ctx context.Context, path string, p Predicate, fn func(HistoricalRecord) error) error {
+	pf, err := r.open(ctx, path)
+	if err != nil {
+		return fmt.Errorf("cannot open parquet file: %w", err)
+	}
+	defer pf.Close()
+
+	pr, err := reader.NewParquetReader(pf, new(HistoricalRecord), r.np)
+	if err != nil {
+		return fmt.Errorf("cannot create parquet reader: %w", err)
+	}
+	defer pr.ReadStop()
+
+	groups := p.RowGroups(pr.Footer)
+	if len(groups) == 0 {
+		return nil
+	}
+	if len(groups) < len(pr.Footer.RowGroups) {
+		if err := prune(pr, pf, groups); err != nil {
+			return err
+		}
+	}
+
+	for remaining := int(pr.GetNumRows()); remaining > 0; remaining -= readBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n := readBatchSize
+		if remaining < n {
+			n = remaining
+		}
+		recs := make([]HistoricalRecord, n)
+		if err := pr.Read(&recs); err != nil {
+			return fmt.Errorf("cannot read records: %w", err)
+		}
+		for _, hr := range recs {
+			if !p.Match(hr) {
+				continue
+			}
+			if err := fn(hr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// prune limits the reader to the given row-groups. The column buffers are recreated over a footer that contains only
+// these row-groups, so the data of the other row-groups is never fetched.
+func prune(pr *reader.ParquetReader, pf source.ParquetFile, groups []*parquet.RowGroup) error {
+	footer := *pr.Footer
+	footer.RowGroups = groups
+	footer.NumRows = 0
+	for _, rg := range groups {
+		footer.NumRows += rg.NumRows
+	}
+	pr.Footer = &footer
+
+	for path, cb := range pr.ColumnBuffers {
+		if cb.PFile != nil {
+			_ = cb.PFile.Close()
+		}
+		ncb, err := reader.NewColumnBuffer(pf, pr.Footer, pr.SchemaHandler, path)
+		if err != nil {
+			return fmt.Errorf("cannot create column buffer for %s: %w", path, err)
+		}
+		pr.ColumnBuffers[path] = ncb
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parquet
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"path"
+	"time"
+)
+
+// Prune implements api.HistoricalPruner. It removes the files of the feature's partitions that ended before `before`,
+// so a partition is kept until all of its records are older than the retention horizon.
+func (bw *baseParquet) Prune(ctx context.Context, fqn string, before time.Time, dryRun bool) (api.PruneReport, error) {
+	report := api.PruneReport{}
+	if bw.opts.Storage == nil {
+		return report, fmt.Errorf("pruning the historical data isn't supported by the storage")
+	}
+	if !bw.opts.Partitioning.has(PartitionDate) {
+		return report, fmt.Errorf("pruning the historical data requires the `%s` partition key", PartitionDate)
+	}
+
+	files, err := bw.opts.Storage.List(ctx, bw.opts.Partitioning.Prefix(fqn))
+	if err != nil {
+		return report, fmt.Errorf("cannot list parquet files: %w", err)
+	}
+	partitions := make(map[string]struct{})
+	var paths []string
+	for _, f := range files {
+		if !bw.opts.Partitioning.Match(f.Path, fqn, time.Time{}) {
+			continue
+		}
+		if end, ok := bw.opts.Partitioning.End(f.Path); !ok || end.After(before) {
+			continue
+		}
+		partitions[path.Dir(f.Path)] = struct{}{}
+		paths = append(paths, f.Path)
+		report.Bytes += f.Size
+	}
+	report.Partitions = len(partitions)
+	report.Files = len(paths)
+
+	if dryRun || len(paths) == 0 {
+		return report, nil
+	}
+	if err := bw.opts.Storage.Remove(ctx, paths); err != nil {
+		return report, fmt.Errorf("cannot remove parquet files: %w", err)
+	}
+	return report, nil
+}
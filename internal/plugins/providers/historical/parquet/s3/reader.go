@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/raptor-ml/raptor/internal/plugins/providers/historical/parquet"
+	"github.com/spf13/viper"
+	"github.com/xitongsys/parquet-go-source/s3v2"
+	"github.com/xitongsys/parquet-go/source"
+	"strings"
+)
+
+// HistoricalReader creates a reader of the historical records that were written to S3.
+func HistoricalReader(viper *viper.Viper) (*parquet.Reader, error) {
+	client, bucket, err := newClient(viper)
+	if err != nil {
+		return nil, err
+	}
+	partitioning, err := parquet.ParsePartitioning(viper.GetStringSlice("s3-partition-by"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3-partition-by: %w", err)
+	}
+	basedir := viper.GetString("s3-basedir")
+	return parquet.BaseReader(4, fileLister(client, bucket, basedir, partitioning), fileOpener(client, bucket)), nil
+}
+
+// fileLister lists the files of the feature's partitions. Files are partitioned by the time they were written, so
+// partitions that were written before the predicate's lower bound can't contain matching records.
+func fileLister(client *s3.Client, bucket string, basedir string, partitioning parquet.Partitioning) parquet.FileLister {
+	return func(ctx context.Context, p parquet.Predicate) ([]string, error) {
+		if p.FQN == "" {
+			return nil, fmt.Errorf("fqn is required")
+		}
+		if basedir[len(basedir)-1] != '/' {
+			basedir += "/"
+		}
+		prefix := basedir + partitioning.Prefix(p.FQN)
+
+		var files []string
+		pager := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+			Bucket: aws.String(bucket),
+			Prefix: aws.String(prefix),
+		})
+		for pager.HasMorePages() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list s3 objects: %w", err)
+			}
+			for _, obj := range page.Contents {
+				key := aws.ToString(obj.Key)
+				rel := strings.TrimPrefix(key, basedir)
+				if !strings.HasSuffix(key, ".parquet") || !partitioning.Match(rel, p.FQN, p.From) {
+					continue
+				}
+				files = append(files, key)
+			}
+		}
+		return files, nil
+	}
+}
+
+func fileOpener(client *s3.Client, bucket string) parquet.FileOpener {
+	return func(ctx context.Context, path string) (source.ParquetFile, error) {
+		return s3v2.NewS3FileReaderWithClient(ctx, client, bucket, path)
+	}
+}
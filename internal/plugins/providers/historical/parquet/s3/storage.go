@@ -0,0 +1,98 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/raptor-ml/raptor/internal/plugins/providers/historical/parquet"
+	"github.com/xitongsys/parquet-go-source/s3v2"
+	"github.com/xitongsys/parquet-go/source"
+	"strings"
+)
+
+// maxDeleteObjects is the maximal number of objects of a DeleteObjects request.
+const maxDeleteObjects = 1000
+
+// storage implements parquet.Storage over the base directory of the historical data.
+type storage struct {
+	client  *s3.Client
+	bucket  string
+	basedir string
+}
+
+func (s *storage) List(ctx context.Context, prefix string) ([]parquet.FileInfo, error) {
+	var files []parquet.FileInfo
+	pager := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.basedir + prefix),
+	})
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list s3 objects: %w", err)
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			if !strings.HasSuffix(key, ".parquet") {
+				continue
+			}
+			files = append(files, parquet.FileInfo{
+				Path:     strings.TrimPrefix(key, s.basedir),
+				Size:     aws.ToInt64(obj.Size),
+				Modified: aws.ToTime(obj.LastModified),
+			})
+		}
+	}
+	return files, nil
+}
+
+func (s *storage) Open(ctx context.Context, path string) (source.ParquetFile, error) {
+	return s3v2.NewS3FileReaderWithClient(ctx, s.client, s.bucket, s.basedir+path)
+}
+
+func (s *storage) Create(ctx context.Context, partition string, alive bool) (string, source.ParquetFile, error) {
+	path := filename(partition, alive)
+	pf, err := s3v2.NewS3FileWriterWithClient(ctx, s.client, s.bucket, s.basedir+path, nil)
+	return path, pf, err
+}
+
+func (s *storage) Remove(ctx context.Context, paths []string) error {
+	for len(paths) > 0 {
+		n := min(len(paths), maxDeleteObjects)
+		objects := make([]types.ObjectIdentifier, n)
+		for i, p := range paths[:n] {
+			objects[i] = types.ObjectIdentifier{Key: aws.String(s.basedir + p)}
+		}
+		out, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete s3 objects: %w", err)
+		}
+		if len(out.Errors) > 0 {
+			return fmt.Errorf("failed to delete s3 object %s: %s", aws.ToString(out.Errors[0].Key),
+				aws.ToString(out.Errors[0].Message))
+		}
+		paths = paths[n:]
+	}
+	return nil
+}
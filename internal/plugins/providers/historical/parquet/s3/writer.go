@@ -25,11 +25,13 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/raptor-ml/raptor/api"
 	"github.com/raptor-ml/raptor/internal/plugins/providers/historical/parquet"
+	"github.com/raptor-ml/raptor/internal/plugins/providers/historical/parquet/duckdb"
 	"github.com/raptor-ml/raptor/pkg/plugins"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/xitongsys/parquet-go-source/s3v2"
 	"github.com/xitongsys/parquet-go/source"
+	"strings"
 	"time"
 )
 
@@ -45,11 +47,76 @@ func BindConfig(set *pflag.FlagSet) error {
 	set.String("aws-secret-key", "", "AWS Secret Key - for historical data")
 	set.String("aws-region", "", "AWS Region - for historical data")
 	set.String("s3-bucket", "", "S3 Bucket - for historical data")
+	set.String("s3-endpoint", "", "Custom S3-compatible endpoint (i.e. MinIO), addressed with path-style URLs - for historical data")
 	set.String("s3-basedir", "raptor/features/", "S3 Base directory for storing features - for historical data")
+	set.Bool("duckdb", false, "Query the historical data with an embedded DuckDB engine (requires a build with the `duckdb` tag)")
+	set.String("duckdb-path", "", "DuckDB database file for the features' views. Defaults to an in-memory database")
+	set.StringSlice("s3-partition-by", parquet.DefaultPartitioning, "The ordered partition keys of the historical "+
+		"data files: `namespace`, `feature`, `date` and `hour`. The `feature` key is required")
+	set.Int64("s3-target-file-size", 128*1024*1024, "The size (in bytes) the historical data files are written up "+
+		"to, and compacted to. Set to 0 to write a file on every flush")
+	set.Duration("s3-max-file-age", 10*time.Minute, "The maximal time a historical data file is written before it's "+
+		"completed, even if it's smaller than the target size. The records of the open files aren't queryable")
+	set.Duration("s3-compaction-min-age", time.Hour, "The time since the last file of a partition was written before "+
+		"its small files are compacted")
 	return nil
 }
 
 func HistoricalWriterFactory(viper *viper.Viper) (api.HistoricalWriter, error) {
+	client, bucket, err := newClient(viper)
+	if err != nil {
+		return nil, err
+	}
+
+	partitioning, err := parquet.ParsePartitioning(viper.GetStringSlice("s3-partition-by"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3-partition-by: %w", err)
+	}
+	basedir := viper.GetString("s3-basedir")
+	if !strings.HasSuffix(basedir, "/") {
+		basedir += "/"
+	}
+	factory := sourceFactory(client, bucket, basedir)
+
+	var binder parquet.FeatureBinder
+	if viper.GetBool("duckdb") {
+		engine, err := duckdb.New(duckdb.Config{
+			Path:      viper.GetString("duckdb-path"),
+			Source:    fmt.Sprintf("s3://%s/%s**/*.parquet", bucket, strings.TrimSuffix(basedir, "/")+"/"),
+			AccessKey: viper.GetString("aws-access-key"),
+			SecretKey: viper.GetString("aws-secret-key"),
+			Region:    viper.GetString("aws-region"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create duckdb engine: %w", err)
+		}
+		binder = engine
+	}
+
+	return parquet.BaseParquet(4, factory, binder, parquet.Options{
+		Partitioning:     partitioning,
+		TargetFileSize:   viper.GetInt64("s3-target-file-size"),
+		MaxFileAge:       viper.GetDuration("s3-max-file-age"),
+		Storage:          &storage{client: client, bucket: bucket, basedir: basedir},
+		CompactionMinAge: viper.GetDuration("s3-compaction-min-age"),
+	}), nil
+}
+func sourceFactory(client s3v2.S3API, bucket string, basedir string) parquet.SourceFactory {
+	return func(ctx context.Context, partition string, alive bool) (source.ParquetFile, error) {
+		return s3v2.NewS3FileWriterWithClient(ctx, client, bucket, basedir+filename(partition, alive), nil)
+	}
+}
+
+// filename returns a distinct name of a new file in the partition, so the files of the partition aren't overwritten.
+func filename(partition string, alive bool) string {
+	aliveTag := ""
+	if alive {
+		aliveTag = "-alive"
+	}
+	return fmt.Sprintf("%sdata-%d%s.snappy.parquet", partition, time.Now().UnixNano(), aliveTag)
+}
+
+func newClient(viper *viper.Viper) (*s3.Client, string, error) {
 	var opts []func(*config.LoadOptions) error
 	if viper.GetString("aws-access-key") != "" && viper.GetString("aws-secret-key") != "" {
 		opts = append(opts, config.WithCredentialsProvider(credentials.StaticCredentialsProvider{
@@ -64,36 +131,24 @@ func HistoricalWriterFactory(viper *viper.Viper) (api.HistoricalWriter, error) {
 	}
 	cfg, err := config.LoadDefaultConfig(context.TODO(), opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load aws config: %w", err)
+		return nil, "", fmt.Errorf("failed to load aws config: %w", err)
 	}
-	client := s3.NewFromConfig(cfg)
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if ep := viper.GetString("s3-endpoint"); ep != "" {
+			o.BaseEndpoint = aws.String(ep)
+			o.UsePathStyle = true
+		}
+	})
 
 	bucket := viper.GetString("s3-bucket")
 	if bucket == "" {
-		return nil, fmt.Errorf("s3-bucket is required")
+		return nil, "", fmt.Errorf("s3-bucket is required")
 	}
 	_, err = client.HeadBucket(context.TODO(), &s3.HeadBucketInput{
 		Bucket: aws.String(bucket),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to check s3 bucket: %w", err)
-	}
-
-	factory := sourceFactory(client, bucket, viper.GetString("s3-basedir"))
-
-	return parquet.BaseParquet(4, factory), nil
-}
-func sourceFactory(client s3v2.S3API, bucket string, basedir string) parquet.SourceFactory {
-	return func(ctx context.Context, fqn string, alive bool) (source.ParquetFile, error) {
-		if basedir[len(basedir)-1] != '/' {
-			basedir += "/"
-		}
-		d := time.Now().Format("2006-01-02")
-		aliveTag := ""
-		if alive {
-			aliveTag = "-alive"
-		}
-		filename := fmt.Sprintf("%sfqn=%s/timestamp=%s/data%s.snappy.parquet", basedir, fqn, d, aliveTag)
-		return s3v2.NewS3FileWriterWithClient(ctx, client, bucket, filename, nil)
+		return nil, "", fmt.Errorf("failed to check s3 bucket: %w", err)
 	}
+	return client, bucket, nil
 }
@@ -56,6 +56,10 @@ func NewHistoricalRecord(wn api.WriteNotification) HistoricalRecord {
 		Keys:      wn.EncodedKeys,
 		Timestamp: types.TimeToTIMESTAMP_MICROS(wn.Value.Timestamp, false),
 	}
+	if wn.Tombstone {
+		// tombstones have no value
+		return hr
+	}
 	if wn.Bucket != "" {
 		wrm := api.ToLowLevelValue[api.WindowResultMap](wn.Value.Value)
 
@@ -125,3 +129,34 @@ func NewHistoricalRecord(wn api.WriteNotification) HistoricalRecord {
 	}
 	return hr
 }
+
+// value returns the feature-value of the record, as it was written by NewHistoricalRecord.
+func (v *Value) value() any {
+	switch {
+	case v.String != nil:
+		return *v.String
+	case v.Int != nil:
+		return int(*v.Int)
+	case v.Double != nil:
+		return *v.Double
+	case v.Timestamp != nil:
+		return types.TIMESTAMP_MICROSToTime(*v.Timestamp, false)
+	case v.StringList != nil:
+		return *v.StringList
+	case v.IntList != nil:
+		l := make([]int, 0, len(*v.IntList))
+		for _, i := range *v.IntList {
+			l = append(l, int(i))
+		}
+		return l
+	case v.DoubleList != nil:
+		return *v.DoubleList
+	case v.TimestampList != nil:
+		l := make([]time.Time, 0, len(*v.TimestampList))
+		for _, t := range *v.TimestampList {
+			l = append(l, types.TIMESTAMP_MICROSToTime(t, false))
+		}
+		return l
+	}
+	return nil
+}
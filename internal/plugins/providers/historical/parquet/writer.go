@@ -21,39 +21,169 @@ import (
 	"fmt"
 	"github.com/raptor-ml/raptor/api"
 	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/xitongsys/parquet-go/parquet"
 	"github.com/xitongsys/parquet-go/source"
 	"github.com/xitongsys/parquet-go/writer"
+	"sort"
 	"sync"
+	"time"
 )
 
-type SourceFactory func(ctx context.Context, fqn string, alive bool) (source.ParquetFile, error)
+// tagMetadataPrefix prefixes the key-value metadata keys of the features' privacy tags.
+const tagMetadataPrefix = "raptor."
+
+// SourceFactory creates a new parquet file in the partition directory (see Partitioning.Dir). Every call should
+// create a distinct file.
+type SourceFactory func(ctx context.Context, partition string, alive bool) (source.ParquetFile, error)
+
+// Options are the options of the parquet HistoricalWriter.
+type Options struct {
+	// Partitioning is the partitioning of the files. Defaults to DefaultPartitioning.
+	Partitioning Partitioning
+	// TargetFileSize is the size the files are kept open until (0 to complete the files on every flush). The files are
+	// completed earlier when they're open for longer than MaxFileAge, or when their partition changes.
+	TargetFileSize int64
+	// MaxFileAge is the maximal time a file is kept open, since the records of the open files aren't visible to the
+	// readers (and are lost if the historian is killed).
+	MaxFileAge time.Duration
+	// Storage is optional. When it's set, the small files of the partitions are merged by Compact.
+	Storage Storage
+	// CompactionMinAge is the time since the last file of a partition was written before the partition is compacted.
+	CompactionMinAge time.Duration
+}
+
+// FeatureBinder creates the query views of the features over the parquet files. i.e. an embedded query engine.
+type FeatureBinder interface {
+	BindFeature(fd *api.FeatureDescriptor, model *manifests.ModelSpec, getter api.FeatureDescriptorGetter) error
+	Close() error
+}
+
 type baseParquet struct {
 	newParquetFile SourceFactory
 	np             int64
 	writers        map[string]*parquetWriter
+	writersMu      sync.Mutex
+	binder         FeatureBinder
+	opts           Options
+
+	// tags are the privacy tags of the bound features (see api.FeatureDescriptor.PrivacyTags), which are written to
+	// the key-value metadata of their files.
+	tags   map[string]map[string]string
+	tagsMu sync.RWMutex
 }
 
-func BaseParquet(np int64, newParquetFile SourceFactory) api.HistoricalWriter {
+// BaseParquet creates a HistoricalWriter that writes parquet files. The binder is optional.
+func BaseParquet(np int64, newParquetFile SourceFactory, binder FeatureBinder, opts Options) api.HistoricalWriter {
+	if len(opts.Partitioning) == 0 {
+		opts.Partitioning = DefaultPartitioning
+	}
 	return &baseParquet{
 		newParquetFile: newParquetFile,
 		np:             np,
 		writers:        make(map[string]*parquetWriter),
+		binder:         binder,
+		opts:           opts,
+		tags:           make(map[string]map[string]string),
 	}
 }
 
 type parquetWriter struct {
 	*writer.ParquetWriter
 	*sync.Mutex
+
+	// keys are the distinct keys of the current (unflushed) row-group.
+	keys map[string]struct{}
+
+	// partition and opened are the partition directory of the file and the time it was opened at.
+	partition string
+	opened    time.Time
+	closed    bool
 }
 
-func (bw *baseParquet) Commit(ctx context.Context, wn api.WriteNotification) error {
-	pw, err := bw.getWriter(ctx, wn.FQN, wn.ActiveBucket)
+// newParquetWriter creates a writer of historical records with the given key-value metadata.
+func newParquetWriter(pf source.ParquetFile, np int64, metadata []*parquet.KeyValue) (*parquetWriter, error) {
+	pw, err := writer.NewParquetWriter(pf, new(HistoricalRecord), np)
 	if err != nil {
+		return nil, fmt.Errorf("cannot create parquet writer: %w", err)
+	}
+	pw.PageSize = 1 * 1024 * 1024      // 1M
+	pw.RowGroupSize = 64 * 1024 * 1024 // 64M - smaller row-groups are pruned more effectively by the readers
+	createdBy := "raptor-historian version latest"
+	pw.Footer.CreatedBy = &createdBy
+	pw.Footer.KeyValueMetadata = append(pw.Footer.KeyValueMetadata, metadata...)
+	return &parquetWriter{
+		ParquetWriter: pw,
+		Mutex:         &sync.Mutex{},
+		keys:          make(map[string]struct{}),
+		opened:        time.Now(),
+	}, nil
+}
+
+// size estimates the size of the file: the size of the written row-groups, and the (uncompressed) size of the
+// buffered records.
+func (pw *parquetWriter) size() int64 {
+	return pw.Offset + pw.ObjsSize
+}
+
+// close writes the footer of the file, and closes it.
+func (pw *parquetWriter) close() error {
+	// the remaining records are flushed as the last row-group
+	pw.writeBloomFilter(len(pw.Footer.RowGroups))
+	if err := pw.WriteStop(); err != nil {
+		return fmt.Errorf("cannot write stop: %w", err)
+	}
+	if err := pw.PFile.Close(); err != nil {
+		return fmt.Errorf("cannot close parquet file: %w", err)
+	}
+	return nil
+}
+
+func (pw *parquetWriter) Write(hr HistoricalRecord) error {
+	pw.keys[hr.Keys] = struct{}{}
+	groups := len(pw.Footer.RowGroups)
+	if err := pw.ParquetWriter.Write(hr); err != nil {
 		return err
 	}
-	pw.Lock()
-	defer pw.Unlock()
-	return pw.Write(NewHistoricalRecord(wn))
+	// the record is included in the row-group that has been flushed by this write
+	if len(pw.Footer.RowGroups) > groups {
+		pw.writeBloomFilter(groups)
+	}
+	return nil
+}
+
+// writeBloomFilter stores the bloom filter of the current row-group's keys in the file's key-value metadata, so
+// readers can skip row-groups that don't contain the requested keys.
+func (pw *parquetWriter) writeBloomFilter(rowGroup int) {
+	if len(pw.keys) == 0 {
+		return
+	}
+	bf := newBloomFilter(len(pw.keys))
+	for k := range pw.keys {
+		bf.Add(k)
+	}
+	v := bf.String()
+	pw.Footer.KeyValueMetadata = append(pw.Footer.KeyValueMetadata, &parquet.KeyValue{
+		Key:   bloomMetadataKey(rowGroup),
+		Value: &v,
+	})
+	pw.keys = make(map[string]struct{})
+}
+
+func (bw *baseParquet) Commit(ctx context.Context, wn api.WriteNotification) error {
+	for {
+		pw, err := bw.getWriter(ctx, wn.FQN, wn.ActiveBucket)
+		if err != nil {
+			return err
+		}
+		pw.Lock()
+		if pw.closed {
+			// the file was completed meanwhile
+			pw.Unlock()
+			continue
+		}
+		defer pw.Unlock()
+		return pw.Write(NewHistoricalRecord(wn))
+	}
 }
 
 func (bw *baseParquet) getWriter(ctx context.Context, fqn string, alive bool) (*parquetWriter, error) {
@@ -61,70 +191,134 @@ func (bw *baseParquet) getWriter(ctx context.Context, fqn string, alive bool) (*
 	if alive {
 		idx = fmt.Sprintf("%s_alive", fqn)
 	}
+	partition := bw.opts.Partitioning.Dir(fqn, time.Now())
+
+	bw.writersMu.Lock()
+	defer bw.writersMu.Unlock()
+	if pw, ok := bw.writers[idx]; ok && pw.partition != partition {
+		// the partition has changed since the file was opened
+		if err := bw.complete(idx, pw); err != nil {
+			return nil, err
+		}
+	}
 	if _, ok := bw.writers[idx]; !ok {
-		pf, err := bw.newParquetFile(ctx, fqn, alive)
+		pf, err := bw.newParquetFile(ctx, partition, alive)
 		if err != nil {
 			return nil, fmt.Errorf("cannot create parquet file: %w", err)
 		}
-		pw, err := writer.NewParquetWriter(pf, new(HistoricalRecord), bw.np)
+		pw, err := newParquetWriter(pf, bw.np, bw.tagsMetadata(fqn))
 		if err != nil {
-			return nil, fmt.Errorf("cannot create parquet writer: %w", err)
-		}
-		pw.PageSize = 1 * 1024 * 1024       // 100M
-		pw.RowGroupSize = 256 * 1024 * 1024 // 256M
-		createdBy := "raptor-historian version latest"
-		pw.Footer.CreatedBy = &createdBy
-		bw.writers[fqn] = &parquetWriter{
-			ParquetWriter: pw,
-			Mutex:         &sync.Mutex{},
+			return nil, err
 		}
+		pw.partition = partition
+		bw.writers[idx] = pw
 	}
-	return bw.writers[fqn], nil
+	return bw.writers[idx], nil
 }
+
+// Flush completes the files of the feature, regardless of their size.
 func (bw *baseParquet) Flush(_ context.Context, fqn string) error {
-	err := bw.flush(fqn)
-	if err != nil {
-		return fmt.Errorf("cannot flush parquet file: %w", err)
-	}
-	err = bw.flush(fmt.Sprintf("%s_alive", fqn))
-	if err != nil {
-		return fmt.Errorf("cannot flush (alive) parquet file: %w", err)
+	bw.writersMu.Lock()
+	defer bw.writersMu.Unlock()
+	for _, idx := range []string{fqn, fmt.Sprintf("%s_alive", fqn)} {
+		if pw, ok := bw.writers[idx]; ok {
+			if err := bw.complete(idx, pw); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
-func (bw *baseParquet) flush(key string) error {
-	if pw, ok := bw.writers[key]; ok {
-		pw.Lock()
-		defer pw.Unlock()
 
-		err := pw.WriteStop()
-		if err != nil {
-			return fmt.Errorf("cannot write stop: %w", err)
+// FlushAll completes the files that reached the target size, or that were open for longer than the maximal age.
+// When there's no target size, all the files are completed.
+func (bw *baseParquet) FlushAll(_ context.Context) error {
+	return bw.flushAll(false)
+}
+
+func (bw *baseParquet) flushAll(force bool) error {
+	bw.writersMu.Lock()
+	defer bw.writersMu.Unlock()
+	for idx, pw := range bw.writers {
+		if !force && !bw.due(pw) {
+			continue
 		}
-		err = pw.PFile.Close()
-		if err != nil {
-			return fmt.Errorf("cannot close parquet file: %w", err)
+		if err := bw.complete(idx, pw); err != nil {
+			return err
 		}
-		delete(bw.writers, key)
 	}
 	return nil
 }
 
-func (bw *baseParquet) FlushAll(ctx context.Context) error {
-	for fqn := range bw.writers {
-		err := bw.Flush(ctx, fqn)
-		if err != nil {
-			return err
-		}
+// due returns true when the file should be completed.
+func (bw *baseParquet) due(pw *parquetWriter) bool {
+	if bw.opts.TargetFileSize <= 0 {
+		return true
+	}
+	if bw.opts.MaxFileAge > 0 && time.Since(pw.opened) >= bw.opts.MaxFileAge {
+		return true
+	}
+	pw.Lock()
+	defer pw.Unlock()
+	return pw.size() >= bw.opts.TargetFileSize
+}
+
+// complete closes the file, so it's visible to the readers. It should be called while holding the writers' lock.
+func (bw *baseParquet) complete(idx string, pw *parquetWriter) error {
+	pw.Lock()
+	defer pw.Unlock()
+	delete(bw.writers, idx)
+	pw.closed = true
+	if err := pw.close(); err != nil {
+		return fmt.Errorf("cannot flush parquet file: %w", err)
 	}
 	return nil
 }
 
-func (bw *baseParquet) Close(ctx context.Context) error {
-	return bw.FlushAll(ctx)
+func (bw *baseParquet) Close(_ context.Context) error {
+	err := bw.flushAll(true)
+	if bw.binder != nil {
+		if cerr := bw.binder.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Export implements api.HistoricalExporter, using the binder's query engine.
+func (bw *baseParquet) Export(ctx context.Context, fqn string, since, until time.Time, dest string) error {
+	e, ok := bw.binder.(api.HistoricalExporter)
+	if !ok {
+		return fmt.Errorf("exporting the historical data requires the embedded query engine (`--duckdb`)")
+	}
+	return e.Export(ctx, fqn, since, until, dest)
 }
 
 func (bw *baseParquet) BindFeature(fd *api.FeatureDescriptor, model *manifests.ModelSpec, getter api.FeatureDescriptorGetter) error {
-	// TODO implement
-	return nil
+	bw.tagsMu.Lock()
+	if tags := fd.PrivacyTags(); tags != nil {
+		bw.tags[fd.FQN] = tags
+	} else {
+		delete(bw.tags, fd.FQN)
+	}
+	bw.tagsMu.Unlock()
+
+	if bw.binder == nil {
+		return nil
+	}
+	return bw.binder.BindFeature(fd, model, getter)
+}
+
+// tagsMetadata returns the key-value metadata of the feature's privacy tags (i.e. `raptor.pii`), so the consumers of
+// the files can tell sensitive columns apart.
+func (bw *baseParquet) tagsMetadata(fqn string) []*parquet.KeyValue {
+	bw.tagsMu.RLock()
+	defer bw.tagsMu.RUnlock()
+
+	var ret []*parquet.KeyValue
+	for k, v := range bw.tags[fqn] {
+		ret = append(ret, &parquet.KeyValue{Key: tagMetadataPrefix + k, Value: &v})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Key < ret[j].Key })
+	return ret
 }
@@ -93,7 +93,10 @@ func (sw *snowflakeWriter) Commit(ctx context.Context, wn api.WriteNotification)
 	var val any
 	var bucket *string
 	var alive *bool
-	if wn.Bucket != "" {
+	switch {
+	case wn.Tombstone:
+		// tombstones have no value
+	case wn.Bucket != "":
 		bucket = &wn.Bucket
 		alive = &wn.ActiveBucket
 
@@ -108,7 +111,7 @@ func (sw *snowflakeWriter) Commit(ctx context.Context, wn api.WriteNotification)
 		}
 		q = fmt.Sprintf(q, "parse_json(%s)")
 		val = string(rawJSON)
-	} else {
+	default:
 		val = wn.Value.Value
 		if !api.TypeDetect(val).Scalar() {
 			rawJSON, err := json.Marshal(val)
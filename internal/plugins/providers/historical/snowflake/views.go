@@ -50,11 +50,17 @@ func (sw *snowflakeWriter) BindFeature(fd *api.FeatureDescriptor, model *manifes
 
 	const viewQuery = `SET (SINCE,UNTIL) = ('2020-12-01', '2022-12-31');
 CREATE OR REPLACE VIEW %s
-	COMMENT ='%s %s. Requires session variables $SINCE and $UNTIL.'
+	COMMENT ='%s %s.%s Requires session variables $SINCE and $UNTIL.'
 AS %s`
 
+	// the privacy tags are recorded in the comment, so the consumers of the view can tell sensitive features apart
+	var tags string
+	if t := fd.PrivacyTags(); t != nil {
+		tags = fmt.Sprintf(" Tags: %s.", api.FormatPrivacyTags(t))
+	}
+
 	ctx, _ := sf.WithMultiStatement(context.TODO(), 2)
-	_, err := sw.db.ExecContext(ctx, fmt.Sprintf(viewQuery, querybuilder.EscapeName(fd.FQN), fd.FQN, typ, query))
+	_, err := sw.db.ExecContext(ctx, fmt.Sprintf(viewQuery, querybuilder.EscapeName(fd.FQN), fd.FQN, typ, tags, query))
 	if err != nil {
 		return fmt.Errorf("failed to create %s view for %s: %w", typ, fd.FQN, err)
 	}
@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/crypto"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const pluginName = "local"
+
+func init() {
+	plugins.Configurers.Register("local-keys", BindConfig)
+	plugins.KeyManagerFactories.Register(pluginName, KeyManagerFactory)
+}
+
+// BindConfig adds the flags of the local key manager.
+func BindConfig(set *pflag.FlagSet) error {
+	set.String("encryption-local-keys-dir", "", "The directory of the key-encryption keys of the `local` key "+
+		"manager (i.e. a mounted Secret). Each file is a base64-encoded 32 bytes key, named by its key ID.")
+	return nil
+}
+
+// KeyManagerFactory creates a KeyManager that wraps the data keys by key-encryption keys that are read from files.
+// The keys must not be replaced, since the values that were sealed by them couldn't be decrypted anymore; rotate
+// them by adding a key with a new ID instead.
+func KeyManagerFactory(viper *viper.Viper) (api.KeyManager, error) {
+	dir := viper.GetString("encryption-local-keys-dir")
+	if dir == "" {
+		return nil, fmt.Errorf("the `encryption-local-keys-dir` flag is required for the local key manager")
+	}
+	return &keyManager{dir: dir}, nil
+}
+
+type keyManager struct {
+	dir string
+}
+
+func (m *keyManager) key(keyID string) ([]byte, error) {
+	if keyID == "" || strings.HasPrefix(keyID, ".") || strings.ContainsAny(keyID, `/\`) {
+		return nil, fmt.Errorf("invalid key ID `%s`", keyID)
+	}
+	b, err := os.ReadFile(filepath.Join(m.dir, keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key `%s`: %w", keyID, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key `%s`: %w", keyID, err)
+	}
+	if len(key) != crypto.Default().KeySize() {
+		return nil, fmt.Errorf("%w: key `%s` must be %d bytes long", crypto.ErrInvalidKey, keyID, crypto.Default().KeySize())
+	}
+	return key, nil
+}
+
+// WrapKey implements api.KeyManager
+func (m *keyManager) WrapKey(_ context.Context, keyID string, key []byte) ([]byte, error) {
+	kek, err := m.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Default().Encrypt(kek, key, []byte(keyID))
+}
+
+// UnwrapKey implements api.KeyManager
+func (m *keyManager) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	kek, err := m.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Default().Decrypt(kek, wrapped, []byte(keyID))
+}
@@ -0,0 +1,139 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const pluginName = "vault"
+
+func init() {
+	plugins.Configurers.Register("vault-keys", BindConfig)
+	plugins.KeyManagerFactories.Register(pluginName, KeyManagerFactory)
+}
+
+// BindConfig adds the flags of the Vault key manager.
+func BindConfig(set *pflag.FlagSet) error {
+	set.String("encryption-vault-address", "", "The address of the Vault server of the `vault` key manager "+
+		"(i.e. `https://vault.vault:8200`).")
+	set.String("encryption-vault-token-file", "", "The file of the Vault token of the `vault` key manager. "+
+		"The file is re-read on every request, so the token can be renewed by an agent.")
+	set.String("encryption-vault-transit-path", "transit", "The mount path of the Vault Transit secrets engine.")
+	return nil
+}
+
+// KeyManagerFactory creates a KeyManager that wraps the data keys by the keys of the Vault Transit secrets engine, so
+// the key-encryption keys never leave Vault. The key ID is the name of a Transit key.
+func KeyManagerFactory(viper *viper.Viper) (api.KeyManager, error) {
+	addr := viper.GetString("encryption-vault-address")
+	if addr == "" {
+		return nil, fmt.Errorf("the `encryption-vault-address` flag is required for the vault key manager")
+	}
+	u, err := url.Parse(addr)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid vault address `%s`", addr)
+	}
+	if viper.GetString("encryption-vault-token-file") == "" {
+		return nil, fmt.Errorf("the `encryption-vault-token-file` flag is required for the vault key manager")
+	}
+	return &keyManager{
+		address:   strings.TrimSuffix(u.String(), "/"),
+		tokenFile: viper.GetString("encryption-vault-token-file"),
+		path:      strings.Trim(viper.GetString("encryption-vault-transit-path"), "/"),
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+type keyManager struct {
+	address   string
+	tokenFile string
+	path      string
+	client    *http.Client
+}
+
+// call calls a Transit endpoint of the key, and decodes the `data` of the response.
+func (m *keyManager) call(ctx context.Context, op, keyID string, body map[string]string) (map[string]string, error) {
+	token, err := os.ReadFile(m.tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the vault token: %w", err)
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v1/%s/%s/%s", m.address, m.path, op, url.PathEscape(keyID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", strings.TrimSpace(string(token)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call vault: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("vault failed to %s with key `%s` (%d): %s", op, keyID, resp.StatusCode, msg)
+	}
+
+	var ret struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&ret); err != nil {
+		return nil, fmt.Errorf("failed to decode the vault response: %w", err)
+	}
+	return ret.Data, nil
+}
+
+// WrapKey implements api.KeyManager
+func (m *keyManager) WrapKey(ctx context.Context, keyID string, key []byte) ([]byte, error) {
+	data, err := m.call(ctx, "encrypt", keyID, map[string]string{"plaintext": base64.StdEncoding.EncodeToString(key)})
+	if err != nil {
+		return nil, err
+	}
+	if data["ciphertext"] == "" {
+		return nil, fmt.Errorf("vault returned no ciphertext")
+	}
+	return []byte(data["ciphertext"]), nil
+}
+
+// UnwrapKey implements api.KeyManager
+func (m *keyManager) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	data, err := m.call(ctx, "decrypt", keyID, map[string]string{"ciphertext": string(wrapped)})
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(data["plaintext"])
+}
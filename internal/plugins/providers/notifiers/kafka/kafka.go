@@ -0,0 +1,189 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kafka implements the notifiers of the historian pipeline over Kafka topics.
+//
+// Unlike the Redis notifier (which is a Pub/Sub channel), the notifications are kept by the brokers until they're
+// consumed, so they survive restarts of the state and of the historian. The historian replicas consume the topics as a
+// single consumer group, so each notification is handled by one replica, and the partitions are spread across them.
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"io"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"time"
+)
+
+const pluginName = "kafka"
+
+func init() {
+	plugins.Configurers.Register(pluginName, BindConfig)
+	plugins.CollectNotifierFactories.Register(pluginName, NotifierFactory[api.CollectNotification])
+	plugins.WriteNotifierFactories.Register(pluginName, NotifierFactory[api.WriteNotification])
+}
+
+// BindConfig adds the flags of the Kafka notifier.
+func BindConfig(set *pflag.FlagSet) error {
+	set.StringSlice("kafka-brokers", []string{}, "Kafka brokers of the notifications")
+	set.String("kafka-collect-topic", "raptor-notifications-collect", "The Kafka topic of the collect notifications")
+	set.String("kafka-write-topic", "raptor-notifications-write", "The Kafka topic of the write notifications")
+	set.String("kafka-consumer-group", "raptor-historian", "The Kafka consumer group of the historian replicas")
+	set.Duration("kafka-batch-timeout", 10*time.Millisecond, "The time to wait for more notifications before "+
+		"a batch is sent to the brokers")
+	set.Bool("kafka-tls", false, "Enable TLS for Kafka")
+	set.String("kafka-sasl-user", "", "Kafka SASL/PLAIN username")
+	set.String("kafka-sasl-pass", "", "Kafka SASL/PLAIN password")
+	return nil
+}
+
+// NotifierFactory creates a notifier that produces the notifications to a Kafka topic, and consumes them as a member
+// of the historian's consumer group.
+func NotifierFactory[T api.Notification](viper *viper.Viper) (api.Notifier[T], error) {
+	brokers := viper.GetStringSlice("kafka-brokers")
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("kafka-brokers is required")
+	}
+
+	var topic string
+	var t T
+	switch any(t).(type) {
+	case api.CollectNotification:
+		topic = viper.GetString("kafka-collect-topic")
+	case api.WriteNotification:
+		topic = viper.GetString("kafka-write-topic")
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("the kafka topic of the notifications is required")
+	}
+
+	transport := &kafka.Transport{}
+	dialer := &kafka.Dialer{Timeout: 10 * time.Second, DualStack: true}
+	if viper.GetBool("kafka-tls") {
+		transport.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+		dialer.TLS = transport.TLS
+	}
+	if user := viper.GetString("kafka-sasl-user"); user != "" {
+		mechanism := plain.Mechanism{Username: user, Password: viper.GetString("kafka-sasl-pass")}
+		transport.SASL = mechanism
+		dialer.SASLMechanism = mechanism
+	}
+
+	return &notifier[T]{
+		writer: &kafka.Writer{
+			Addr:  kafka.TCP(brokers...),
+			Topic: topic,
+			// the notifications of an entity are kept in order by producing them to the same partition
+			Balancer:     &kafka.Hash{},
+			BatchTimeout: viper.GetDuration("kafka-batch-timeout"),
+			RequiredAcks: kafka.RequireAll,
+			Transport:    transport,
+		},
+		reader: kafka.ReaderConfig{
+			Brokers: brokers,
+			GroupID: viper.GetString("kafka-consumer-group"),
+			Topic:   topic,
+			Dialer:  dialer,
+			// the offsets are committed periodically rather than per notification
+			CommitInterval: time.Second,
+		},
+	}, nil
+}
+
+type notifier[T api.Notification] struct {
+	writer *kafka.Writer
+	reader kafka.ReaderConfig
+}
+
+func (n *notifier[T]) Notify(ctx context.Context, notification T) error {
+	return n.NotifyBatch(ctx, []T{notification})
+}
+
+// NotifyBatch produces the notifications in a single request to the brokers.
+func (n *notifier[T]) NotifyBatch(ctx context.Context, notifications []T) error {
+	msgs := make([]kafka.Message, 0, len(notifications))
+	for _, notification := range notifications {
+		val, err := json.Marshal(notification)
+		if err != nil {
+			return fmt.Errorf("cannot marshal notification: %w", err)
+		}
+		msgs = append(msgs, kafka.Message{Key: key(notification), Value: val})
+	}
+	if err := n.writer.WriteMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("cannot produce notifications: %w", err)
+	}
+	return nil
+}
+
+// Subscribe consumes the notifications until the context is done. The offset of a notification is committed once it's
+// received from the channel, so the notifications that weren't delivered are consumed again by the next subscriber.
+func (n *notifier[T]) Subscribe(ctx context.Context) (<-chan T, error) {
+	r := kafka.NewReader(n.reader)
+	logger := ctrl.Log.WithName("kafka-notifier").WithValues("topic", n.reader.Topic)
+	c := make(chan T)
+	go func() {
+		defer close(c)
+		defer r.Close()
+		for {
+			msg, err := r.FetchMessage(ctx)
+			if err != nil {
+				if ctx.Err() == nil && !errors.Is(err, io.EOF) {
+					logger.Error(err, "failed to consume notifications")
+				}
+				return
+			}
+
+			var notification T
+			if err := json.Unmarshal(msg.Value, &notification); err != nil {
+				// a malformed notification would block the partition, so it's skipped
+				logger.Error(err, "couldn't unmarshal notification", "partition", msg.Partition, "offset", msg.Offset)
+			} else {
+				select {
+				case c <- notification:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err := r.CommitMessages(ctx, msg); err != nil {
+				if ctx.Err() == nil {
+					logger.Error(err, "failed to commit notifications")
+				}
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+func key[T api.Notification](notification T) []byte {
+	switch n := any(notification).(type) {
+	case api.CollectNotification:
+		return []byte(n.FQN + "/" + n.EncodedKeys)
+	case api.WriteNotification:
+		return []byte(n.FQN + "/" + n.EncodedKeys)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nats implements the notifiers of the historian pipeline over a NATS JetStream stream.
+//
+// The notifications are kept by the stream until they're acknowledged, and the historian replicas share a durable
+// consumer of each notification type, so each notification is handled by one replica. A notification that wasn't
+// acknowledged in time (i.e. when its replica was stopped) is redelivered.
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"time"
+)
+
+const pluginName = "nats"
+
+func init() {
+	plugins.Configurers.Register(pluginName, BindConfig)
+	plugins.CollectNotifierFactories.Register(pluginName, NotifierFactory[api.CollectNotification])
+	plugins.WriteNotifierFactories.Register(pluginName, NotifierFactory[api.WriteNotification])
+}
+
+// BindConfig adds the flags of the NATS notifier.
+func BindConfig(set *pflag.FlagSet) error {
+	set.String("nats-url", "", "NATS server URL(s), separated by commas")
+	set.String("nats-creds", "", "Path to a NATS user credentials file")
+	set.String("nats-user", "", "NATS username")
+	set.String("nats-pass", "", "NATS password")
+	set.String("nats-stream", "RAPTOR_NOTIFICATIONS", "The JetStream stream of the notifications. It's created when "+
+		"it doesn't exist")
+	set.String("nats-subject-prefix", "raptor.notifications", "The prefix of the subjects of the notifications")
+	set.String("nats-durable", "raptor-historian", "The prefix of the durable consumers of the historian replicas")
+	set.Duration("nats-max-age", 24*time.Hour, "The maximal age of the notifications that weren't acknowledged")
+	set.Duration("nats-ack-wait", 30*time.Second, "The time to wait for the acknowledgement of a notification "+
+		"before it's redelivered")
+	set.Int("nats-max-deliver", 5, "The maximal number of deliveries of a notification (-1 for unlimited)")
+	return nil
+}
+
+// NotifierFactory creates a notifier that publishes the notifications to a JetStream stream, and consumes them with a
+// durable consumer that is shared by the historian replicas.
+func NotifierFactory[T api.Notification](viper *viper.Viper) (api.Notifier[T], error) {
+	url := viper.GetString("nats-url")
+	if url == "" {
+		return nil, fmt.Errorf("nats-url is required")
+	}
+
+	var kind string
+	var t T
+	switch any(t).(type) {
+	case api.CollectNotification:
+		kind = "collect"
+	case api.WriteNotification:
+		kind = "write"
+	}
+
+	opts := []nats.Option{nats.Name("raptor"), nats.MaxReconnects(-1)}
+	if creds := viper.GetString("nats-creds"); creds != "" {
+		opts = append(opts, nats.UserCredentials(creds))
+	}
+	if user := viper.GetString("nats-user"); user != "" {
+		opts = append(opts, nats.UserInfo(user, viper.GetString("nats-pass")))
+	}
+	nc, err := nats.Connect(url, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	prefix := viper.GetString("nats-subject-prefix")
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     viper.GetString("nats-stream"),
+		Subjects: []string{prefix + ".>"},
+		MaxAge:   viper.GetDuration("nats-max-age"),
+		Storage:  jetstream.FileStorage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the notifications stream: %w", err)
+	}
+
+	return &notifier[T]{
+		js:      js,
+		stream:  stream,
+		subject: prefix + "." + kind,
+		consumer: jetstream.ConsumerConfig{
+			Durable:       viper.GetString("nats-durable") + "-" + kind,
+			FilterSubject: prefix + "." + kind,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			AckWait:       viper.GetDuration("nats-ack-wait"),
+			MaxDeliver:    viper.GetInt("nats-max-deliver"),
+		},
+	}, nil
+}
+
+type notifier[T api.Notification] struct {
+	js       jetstream.JetStream
+	stream   jetstream.Stream
+	subject  string
+	consumer jetstream.ConsumerConfig
+}
+
+func (n *notifier[T]) Notify(ctx context.Context, notification T) error {
+	msg, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("cannot marshal notification: %w", err)
+	}
+	if _, err := n.js.Publish(ctx, n.subject, msg); err != nil {
+		return fmt.Errorf("cannot publish notification: %w", err)
+	}
+	return nil
+}
+
+// NotifyBatch publishes the notifications asynchronously, and waits for the stream to acknowledge all of them.
+func (n *notifier[T]) NotifyBatch(ctx context.Context, notifications []T) error {
+	futures := make([]jetstream.PubAckFuture, 0, len(notifications))
+	for _, notification := range notifications {
+		msg, err := json.Marshal(notification)
+		if err != nil {
+			return fmt.Errorf("cannot marshal notification: %w", err)
+		}
+		f, err := n.js.PublishAsync(n.subject, msg)
+		if err != nil {
+			return fmt.Errorf("cannot publish notification: %w", err)
+		}
+		futures = append(futures, f)
+	}
+	for _, f := range futures {
+		select {
+		case <-f.Ok():
+		case err := <-f.Err():
+			return fmt.Errorf("cannot publish notification: %w", err)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe consumes the notifications until the context is done. A notification is acknowledged once it's received
+// from the channel, and the notifications that weren't delivered are negatively acknowledged, so they're redelivered
+// right away (possibly to another replica).
+func (n *notifier[T]) Subscribe(ctx context.Context) (<-chan T, error) {
+	cons, err := n.stream.CreateOrUpdateConsumer(ctx, n.consumer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the notifications consumer: %w", err)
+	}
+	it, err := cons.Messages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume notifications: %w", err)
+	}
+	go func() {
+		<-ctx.Done()
+		it.Stop()
+	}()
+
+	logger := ctrl.Log.WithName("nats-notifier").WithValues("consumer", n.consumer.Durable)
+	c := make(chan T)
+	go func() {
+		defer close(c)
+		for {
+			msg, err := it.Next()
+			if err != nil {
+				if !errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+					logger.Error(err, "failed to consume notifications")
+				}
+				return
+			}
+
+			var notification T
+			if err := json.Unmarshal(msg.Data(), &notification); err != nil {
+				// a malformed notification can't be handled by any replica, so it's not redelivered
+				logger.Error(err, "couldn't unmarshal notification")
+				_ = msg.Term()
+				continue
+			}
+			select {
+			case c <- notification:
+				if err := msg.Ack(); err != nil {
+					logger.Error(err, "failed to acknowledge notification")
+				}
+			case <-ctx.Done():
+				_ = msg.Nak()
+				return
+			}
+		}
+	}()
+	return c, nil
+}
@@ -0,0 +1,303 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sqs implements the notifiers of the historian pipeline over AWS SQS queues.
+//
+// The notifications are sent to the queues directly, or published to SNS topics that are subscribed by the queues
+// (i.e. to fan them out to other consumers as well). The historian replicas share the queues, and a notification is
+// deleted once it's received, so one that wasn't received is consumed again when its visibility timeout expires.
+//
+// FIFO queues (and topics) are supported: the notifications of an entity are kept in order by using the feature and
+// the entity as the message group.
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snsTypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/google/uuid"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const pluginName = "sqs"
+
+// maxBatchSize is the maximal number of messages of an SQS (or SNS) batch request.
+const maxBatchSize = 10
+
+func init() {
+	plugins.Configurers.Register(pluginName, BindConfig)
+	plugins.CollectNotifierFactories.Register(pluginName, NotifierFactory[api.CollectNotification])
+	plugins.WriteNotifierFactories.Register(pluginName, NotifierFactory[api.WriteNotification])
+}
+
+// BindConfig adds the flags of the SQS notifier.
+// The AWS credentials and region are shared with the `s3-parquet` historical provider.
+func BindConfig(set *pflag.FlagSet) error {
+	set.String("sqs-collect-queue", "raptor-notifications-collect", "The SQS queue (name or URL) of the collect "+
+		"notifications. FIFO queues should end with `.fifo`")
+	set.String("sqs-write-queue", "raptor-notifications-write", "The SQS queue (name or URL) of the write "+
+		"notifications. FIFO queues should end with `.fifo`")
+	set.String("sns-collect-topic-arn", "", "Publish the collect notifications to this SNS topic, which should be "+
+		"subscribed by the collect queue. The notifications are sent to the queue directly when empty")
+	set.String("sns-write-topic-arn", "", "Publish the write notifications to this SNS topic, which should be "+
+		"subscribed by the write queue. The notifications are sent to the queue directly when empty")
+	set.Duration("sqs-visibility-timeout", 30*time.Second, "The time a received notification is hidden from the "+
+		"other replicas before it's consumed again")
+	return nil
+}
+
+// NotifierFactory creates a notifier that sends the notifications to an SQS queue (or publishes them to an SNS
+// topic), and consumes them from the queue.
+func NotifierFactory[T api.Notification](viper *viper.Viper) (api.Notifier[T], error) {
+	var queue, topic string
+	var t T
+	switch any(t).(type) {
+	case api.CollectNotification:
+		queue, topic = viper.GetString("sqs-collect-queue"), viper.GetString("sns-collect-topic-arn")
+	case api.WriteNotification:
+		queue, topic = viper.GetString("sqs-write-queue"), viper.GetString("sns-write-topic-arn")
+	}
+	if queue == "" {
+		return nil, fmt.Errorf("the sqs queue of the notifications is required")
+	}
+
+	var opts []func(*config.LoadOptions) error
+	if viper.GetString("aws-access-key") != "" && viper.GetString("aws-secret-key") != "" {
+		opts = append(opts, config.WithCredentialsProvider(credentials.StaticCredentialsProvider{
+			Value: aws.Credentials{
+				AccessKeyID:     viper.GetString("aws-access-key"),
+				SecretAccessKey: viper.GetString("aws-secret-key"),
+			},
+		}))
+	}
+	if viper.GetString("aws-region") != "" {
+		opts = append(opts, config.WithRegion(viper.GetString("aws-region")))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	n := &notifier[T]{
+		sqs:        sqs.NewFromConfig(cfg),
+		queueURL:   queue,
+		fifo:       strings.HasSuffix(queue, ".fifo"),
+		topic:      topic,
+		visibility: viper.GetDuration("sqs-visibility-timeout"),
+	}
+	if !strings.Contains(queue, "://") {
+		out, err := n.sqs.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String(queue)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the url of the sqs queue %s: %w", queue, err)
+		}
+		n.queueURL = *out.QueueUrl
+	}
+	if topic != "" {
+		n.sns = sns.NewFromConfig(cfg)
+		n.fifo = strings.HasSuffix(topic, ".fifo")
+	}
+	return n, nil
+}
+
+type notifier[T api.Notification] struct {
+	sqs        *sqs.Client
+	sns        *sns.Client
+	queueURL   string
+	topic      string
+	fifo       bool
+	visibility time.Duration
+}
+
+func (n *notifier[T]) Notify(ctx context.Context, notification T) error {
+	return n.NotifyBatch(ctx, []T{notification})
+}
+
+// NotifyBatch sends the notifications in batches of up to 10 messages.
+func (n *notifier[T]) NotifyBatch(ctx context.Context, notifications []T) error {
+	for len(notifications) > 0 {
+		size := min(len(notifications), maxBatchSize)
+		if err := n.send(ctx, notifications[:size]); err != nil {
+			return err
+		}
+		notifications = notifications[size:]
+	}
+	return nil
+}
+
+func (n *notifier[T]) send(ctx context.Context, notifications []T) error {
+	var sqsEntries []types.SendMessageBatchRequestEntry
+	var snsEntries []snsTypes.PublishBatchRequestEntry
+	for i, notification := range notifications {
+		msg, err := json.Marshal(notification)
+		if err != nil {
+			return fmt.Errorf("cannot marshal notification: %w", err)
+		}
+		id := strconv.Itoa(i)
+		var group, dedup *string
+		if n.fifo {
+			// notifications may repeat, so they're not deduplicated by their content
+			group, dedup = aws.String(messageGroup(notification)), aws.String(uuid.NewString())
+		}
+		if n.sns != nil {
+			snsEntries = append(snsEntries, snsTypes.PublishBatchRequestEntry{
+				Id:                     aws.String(id),
+				Message:                aws.String(string(msg)),
+				MessageGroupId:         group,
+				MessageDeduplicationId: dedup,
+			})
+			continue
+		}
+		sqsEntries = append(sqsEntries, types.SendMessageBatchRequestEntry{
+			Id:                     aws.String(id),
+			MessageBody:            aws.String(string(msg)),
+			MessageGroupId:         group,
+			MessageDeduplicationId: dedup,
+		})
+	}
+
+	var failed []string
+	if n.sns != nil {
+		out, err := n.sns.PublishBatch(ctx, &sns.PublishBatchInput{
+			TopicArn:                   aws.String(n.topic),
+			PublishBatchRequestEntries: snsEntries,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot publish notifications: %w", err)
+		}
+		for _, f := range out.Failed {
+			failed = append(failed, aws.ToString(f.Message))
+		}
+	} else {
+		out, err := n.sqs.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+			QueueUrl: aws.String(n.queueURL),
+			Entries:  sqsEntries,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot send notifications: %w", err)
+		}
+		for _, f := range out.Failed {
+			failed = append(failed, aws.ToString(f.Message))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to send %d notifications: %s", len(failed), strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Subscribe consumes the notifications until the context is done. The received messages are deleted once they're
+// received from the channel.
+func (n *notifier[T]) Subscribe(ctx context.Context) (<-chan T, error) {
+	logger := ctrl.Log.WithName("sqs-notifier").WithValues("queue", n.queueURL)
+	c := make(chan T)
+	go func() {
+		defer close(c)
+		for ctx.Err() == nil {
+			out, err := n.sqs.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+				QueueUrl:            aws.String(n.queueURL),
+				MaxNumberOfMessages: maxBatchSize,
+				WaitTimeSeconds:     20,
+				VisibilityTimeout:   int32(n.visibility / time.Second),
+			})
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Error(err, "failed to receive notifications")
+					time.Sleep(time.Second)
+				}
+				continue
+			}
+
+			var received []types.DeleteMessageBatchRequestEntry
+			for i, msg := range out.Messages {
+				notification, err := unmarshal[T](aws.ToString(msg.Body))
+				if err != nil {
+					// a malformed notification can't be handled by any replica, so it's deleted
+					logger.Error(err, "couldn't unmarshal notification", "id", aws.ToString(msg.MessageId))
+				} else {
+					select {
+					case c <- notification:
+					case <-ctx.Done():
+						// the rest of the messages are consumed again once their visibility timeout expires
+					}
+					if ctx.Err() != nil {
+						break
+					}
+				}
+				received = append(received, types.DeleteMessageBatchRequestEntry{
+					Id:            aws.String(strconv.Itoa(i)),
+					ReceiptHandle: msg.ReceiptHandle,
+				})
+			}
+			if len(received) == 0 {
+				continue
+			}
+
+			// the received messages are deleted even when the subscription is closed
+			dctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_, err = n.sqs.DeleteMessageBatch(dctx, &sqs.DeleteMessageBatchInput{
+				QueueUrl: aws.String(n.queueURL),
+				Entries:  received,
+			})
+			cancel()
+			if err != nil {
+				logger.Error(err, "failed to delete notifications")
+			}
+		}
+	}()
+	return c, nil
+}
+
+// snsEnvelope is the envelope of the messages that are delivered from an SNS topic without raw message delivery.
+type snsEnvelope struct {
+	Type    string `json:"Type"`
+	Message string `json:"Message"`
+}
+
+func unmarshal[T api.Notification](body string) (T, error) {
+	var env snsEnvelope
+	if err := json.Unmarshal([]byte(body), &env); err == nil && env.Type == "Notification" {
+		body = env.Message
+	}
+	var notification T
+	err := json.Unmarshal([]byte(body), &notification)
+	return notification, err
+}
+
+func messageGroup[T api.Notification](notification T) string {
+	switch n := any(notification).(type) {
+	case api.CollectNotification:
+		return n.FQN + "/" + n.EncodedKeys
+	case api.WriteNotification:
+		return n.FQN + "/" + n.EncodedKeys
+	}
+	return ""
+}
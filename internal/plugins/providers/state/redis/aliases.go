@@ -0,0 +1,60 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/raptor-ml/raptor/api"
+)
+
+// aliasKey is the key of the mapping of an alternate ID. The mappings are hashes of the key and the canonical ID of the
+// entity, and they don't expire.
+func aliasKey(aliasType, aliasID string) string {
+	return fmt.Sprintf("alias:%s:%s", aliasType, aliasID)
+}
+
+// SetAlias implements api.AliasStore
+func (s *state) SetAlias(ctx context.Context, alias api.EntityAlias) error {
+	key := aliasKey(alias.AliasType, alias.AliasID)
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, "entity_type", alias.EntityType, "entity_id", alias.EntityID)
+		return nil
+	})
+	return err
+}
+
+// GetAlias implements api.AliasStore
+func (s *state) GetAlias(ctx context.Context, aliasType, aliasID string) (api.EntityAlias, error) {
+	ret := api.EntityAlias{AliasType: aliasType, AliasID: aliasID}
+	vals, err := s.client.HGetAll(ctx, aliasKey(aliasType, aliasID)).Result()
+	if err != nil {
+		return ret, err
+	}
+	ret.EntityType, ret.EntityID = vals["entity_type"], vals["entity_id"]
+	if ret.EntityType == "" || ret.EntityID == "" {
+		return ret, fmt.Errorf("%w: %s %s", api.ErrAliasNotFound, aliasType, aliasID)
+	}
+	return ret, nil
+}
+
+// DeleteAlias implements api.AliasStore
+func (s *state) DeleteAlias(ctx context.Context, aliasType, aliasID string) error {
+	return s.client.Del(ctx, aliasKey(aliasType, aliasID)).Err()
+}
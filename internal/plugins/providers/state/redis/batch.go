@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+)
+
+// WriteBatch implements api.BatchWriter
+// The writes are queued to a single pipeline, so the whole batch takes one round-trip. Writes that are invalid
+// (i.e. too old) are not queued, and fail alone.
+func (s *state) WriteBatch(ctx context.Context, writes []api.StateWrite) []error {
+	var errs []error
+	fail := func(i int, err error) {
+		if errs == nil {
+			errs = make([]error, len(writes))
+		}
+		errs[i] = err
+	}
+
+	tx := s.client.TxPipeline()
+	queued := make([]int, 0, len(writes))
+	for i, w := range writes {
+		if w.FeatureDescriptor.ValidWindow() {
+			fail(i, fmt.Errorf("windowed features can't be written in batches"))
+			continue
+		}
+		var err error
+		switch w.Method {
+		case api.StateMethodSet:
+			err = s.set(ctx, tx, w.FeatureDescriptor, w.Keys, w.Value, w.Timestamp)
+		case api.StateMethodAppend:
+			err = s.append(ctx, tx, w.FeatureDescriptor, w.Keys, w.Value, w.Timestamp)
+		case api.StateMethodIncr:
+			err = s.incr(ctx, tx, w.FeatureDescriptor, w.Keys, w.Value, w.Timestamp)
+		default:
+			err = fmt.Errorf("method %s can't be written in batches", w.Method)
+		}
+		if err != nil {
+			fail(i, err)
+			continue
+		}
+		queued = append(queued, i)
+	}
+	if len(queued) == 0 {
+		return errs
+	}
+	if _, err := tx.Exec(ctx); err != nil {
+		for _, i := range queued {
+			fail(i, err)
+		}
+	}
+	return errs
+}
@@ -23,10 +23,12 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/raptor-ml/raptor/api"
 	"github.com/raptor-ml/raptor/pkg/plugins"
+	"github.com/raptor-ml/raptor/pkg/tracing"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -40,13 +42,146 @@ func init() {
 type state struct {
 	client redis.UniversalClient
 	dbID   int
+
+	// replicas routes read-only commands to the lowest-latency healthy node. It's nil when reading from replicas is
+	// disabled.
+	replicas redis.UniversalClient
+	// replicaTolerance is the minimal freshness of a feature for its reads to be served by the replicas.
+	replicaTolerance time.Duration
 }
 
 func (s *state) Ping(ctx context.Context) error {
+	if s.replicas != nil {
+		if err := s.replicas.Ping(ctx).Err(); err != nil {
+			return err
+		}
+	}
 	return s.client.Ping(ctx).Err()
 }
 
+// reader returns the client to read the feature's values with.
+// Features that tolerate a replication lag (i.e. their freshness is at least the replica tolerance) are read from the
+// lowest-latency healthy replica, while staleness-sensitive features are always read from the primary.
+func (s *state) reader(fd api.FeatureDescriptor) redis.UniversalClient {
+	if s.replicas == nil || fd.Freshness < s.replicaTolerance {
+		return s.client
+	}
+	return s.replicas
+}
+
+// hashTags wraps the encoded keys of the entities in a hash tag (i.e. `{42}`), so all the keys of an entity (its
+// values, previous versions, window buckets and sketches) are stored in the same Redis Cluster slot, and can be used
+// together by transactions and scripts. It's set by the StateFactory, since the keys are built by package functions.
+var hashTags bool
+
+// entityTag returns the encoded keys of an entity as they're used in the keys.
+func entityTag(encodedKeys string) string {
+	if !hashTags || encodedKeys == "*" {
+		return encodedKeys
+	}
+	return "{" + encodedKeys + "}"
+}
+
+// entityUntag returns the encoded keys of an entity from their form in the keys (see entityTag).
+func entityUntag(tagged string) string {
+	if !hashTags {
+		return tagged
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(tagged, "{"), "}")
+}
+
+// scan calls fn with the keys that match the pattern (and the type, unless empty). The keys of all the masters are
+// scanned in a Redis Cluster, and fn is never called concurrently.
+func (s *state) scan(ctx context.Context, match, typ string, fn func(key string) error) error {
+	scan := func(ctx context.Context, c redis.Cmdable, fn func(key string) error) error {
+		var itr *redis.ScanIterator
+		if typ == "" {
+			itr = c.Scan(ctx, 0, match, MaxScanCount).Iterator()
+		} else {
+			itr = c.ScanType(ctx, 0, match, MaxScanCount, typ).Iterator()
+		}
+		for itr.Next(ctx) {
+			if err := fn(itr.Val()); err != nil {
+				return err
+			}
+		}
+		return itr.Err()
+	}
+
+	cc, ok := s.client.(*redis.ClusterClient)
+	if !ok {
+		return scan(ctx, s.client, fn)
+	}
+	var mu sync.Mutex
+	locked := func(key string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return fn(key)
+	}
+	return cc.ForEachMaster(ctx, func(ctx context.Context, c *redis.Client) error {
+		return scan(ctx, c, locked)
+	})
+}
+
+// unlink removes the keys. In a Redis Cluster, the keys are removed one by one (in a pipeline), since they may belong
+// to different slots.
+func (s *state) unlink(ctx context.Context, keys ...string) error {
+	if _, ok := s.client.(*redis.ClusterClient); !ok {
+		return s.client.Unlink(ctx, keys...).Err()
+	}
+	pipe := s.client.Pipeline()
+	for _, k := range keys {
+		pipe.Unlink(ctx, k)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// newUniversalClient creates a Sentinel client when a master name is given, a Cluster client when there are multiple
+// addresses (or the cluster mode is set, i.e. to discover a cluster by a single address), and a single node client
+// otherwise.
+func newUniversalClient(opts *redis.UniversalOptions, cluster bool) redis.UniversalClient {
+	if cluster && opts.MasterName == "" {
+		return redis.NewClusterClient(opts.Cluster())
+	}
+	return redis.NewUniversalClient(opts)
+}
+
 func redisClient(viper *viper.Viper, db int) (redis.UniversalClient, error) {
+	opts, err := redisOptions(viper, db)
+	if err != nil {
+		return nil, err
+	}
+	c := newUniversalClient(opts, viper.GetBool("redis-cluster"))
+	c.AddHook(tracing.RedisHook{})
+	return c, nil
+}
+
+// replicasClient returns a client that routes read-only commands to the lowest-latency healthy node, either a
+// primary or a replica.
+func replicasClient(viper *viper.Viper, db int) (redis.UniversalClient, error) {
+	opts, err := redisOptions(viper, db)
+	if err != nil {
+		return nil, err
+	}
+	opts.RouteByLatency = true
+
+	var c redis.UniversalClient
+	switch {
+	case opts.MasterName != "":
+		fo := opts.Failover()
+		fo.RouteByLatency = true
+		c = redis.NewFailoverClusterClient(fo)
+	case len(opts.Addrs) > 1 || viper.GetBool("redis-cluster"):
+		c = redis.NewClusterClient(opts.Cluster())
+	default:
+		return nil, fmt.Errorf("redis: reading from replicas requires a Sentinel or a Cluster deployment")
+	}
+	c.AddHook(tracing.RedisHook{})
+	return c, nil
+}
+
+func redisOptions(viper *viper.Viper, db int) (*redis.UniversalOptions, error) {
 	// Initialize redis client
 	var redisTLS *tls.Config = nil
 	if viper.GetBool("redis-tls") {
@@ -63,7 +198,12 @@ func redisClient(viper *viper.Viper, db int) (redis.UniversalClient, error) {
 		addrs[i] = strings.TrimSpace(addrs[i])
 	}
 
-	return redis.NewUniversalClient(&redis.UniversalOptions{
+	cluster := viper.GetString("redis-master") == "" && (len(addrs) > 1 || viper.GetBool("redis-cluster"))
+	if cluster && db != 0 {
+		return nil, fmt.Errorf("redis: Redis Cluster supports only the database 0")
+	}
+
+	return &redis.UniversalOptions{
 		Addrs:            addrs,
 		DB:               db,
 		Password:         viper.GetString("redis-pass"),
@@ -72,12 +212,19 @@ func redisClient(viper *viper.Viper, db int) (redis.UniversalClient, error) {
 		SentinelPassword: viper.GetString("redis-sentinel-pass"),
 		MasterName:       viper.GetString("redis-master"),
 		TLSConfig:        redisTLS,
-		MaxRetries:       3,
-	}), nil
+		MaxRetries:       viper.GetInt("redis-max-retries"),
+		PoolSize:         viper.GetInt("redis-pool-size"),
+		MinIdleConns:     viper.GetInt("redis-min-idle-conns"),
+		DialTimeout:      viper.GetDuration("redis-dial-timeout"),
+		ReadTimeout:      viper.GetDuration("redis-read-timeout"),
+		WriteTimeout:     viper.GetDuration("redis-write-timeout"),
+		PoolTimeout:      viper.GetDuration("redis-pool-timeout"),
+	}, nil
 }
 
 func StateFactory(viper *viper.Viper) (api.State, error) {
 	dbID := viper.GetInt("redis-db")
+	hashTags = viper.GetBool("redis-hash-tags")
 	rc, err := redisClient(viper, dbID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create redis client: %w", err)
@@ -88,10 +235,19 @@ func StateFactory(viper *viper.Viper) (api.State, error) {
 		return nil, fmt.Errorf("failed to load redis scripts: %w", err)
 	}
 
-	return &state{client: rc, dbID: dbID}, nil
+	s := &state{client: rc, dbID: dbID}
+	if viper.GetBool("redis-read-replicas") {
+		s.replicas, err = replicasClient(viper, dbID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create redis replicas client: %w", err)
+		}
+		s.replicaTolerance = viper.GetDuration("redis-replica-tolerance")
+	}
+	return s, nil
 }
 func BindConfig(set *pflag.FlagSet) error {
-	set.StringArrayP("redis", "r", []string{}, "Redis servers")
+	set.StringArrayP("redis", "r", []string{}, "Redis servers. Multiple servers are the seeds of a Redis Cluster, "+
+		"or the Sentinels when a master name is given")
 	set.String("redis-user", "", "Redis username")
 	set.String("redis-pass", "", "Redis password")
 	set.String("redis-sentinel-user", "", "Redis Sentinel username")
@@ -99,6 +255,21 @@ func BindConfig(set *pflag.FlagSet) error {
 	set.String("redis-master", "", "Redis Sentinel master name")
 	set.Bool("redis-tls", false, "Enable TLS for Redis")
 	set.Int("redis-db", 0, "Redis DB")
+	set.Bool("redis-cluster", false, "Connect to a Redis Cluster, even when a single server is given")
+	set.Bool("redis-hash-tags", false, "Hash-tag the keys by their entity, so all the keys of an entity are stored in "+
+		"the same Redis Cluster slot (required for windowed features and previous versions in a cluster). Changes the "+
+		"format of the keys, so the existing values are not read")
+	set.Int("redis-pool-size", 0, "The maximal number of connections to each Redis server (0 for 10 per CPU)")
+	set.Int("redis-min-idle-conns", 0, "The minimal number of idle connections to each Redis server")
+	set.Int("redis-max-retries", 3, "The maximal number of retries of a failed Redis command (-1 to disable)")
+	set.Duration("redis-dial-timeout", 5*time.Second, "The timeout of connecting to a Redis server")
+	set.Duration("redis-read-timeout", 3*time.Second, "The timeout of reading a Redis response (-1 to disable)")
+	set.Duration("redis-write-timeout", 3*time.Second, "The timeout of writing a Redis command (-1 to disable)")
+	set.Duration("redis-pool-timeout", 0, "The time to wait for a connection of the pool when all of them are busy "+
+		"(0 for the read timeout plus a second)")
+	set.Bool("redis-read-replicas", false, "Read freshness-tolerant features from the lowest-latency healthy Redis replica")
+	set.Duration("redis-replica-tolerance", time.Minute, "The minimal freshness of a feature for its reads to be served by Redis replicas")
+	set.Int64("redis-dlq-max-len", 100000, "The maximal number of dead-letters to keep in Redis")
 	return nil
 }
 
@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/raptor-ml/raptor/api"
+	"time"
+)
+
+// maxConditionalWriteAttempts is the number of times a conditional write is attempted, when the value is modified
+// concurrently.
+const maxConditionalWriteAttempts = 5
+
+// SetIf implements api.ConditionalWriter by an optimistic transaction: the value is watched while the condition is
+// checked, and the write is attempted again if the value was modified in the meanwhile.
+func (s *state) SetIf(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, value any, ts time.Time, cond api.WriteCondition) (bool, error) {
+	if fd.ValidWindow() {
+		return false, fmt.Errorf("conditional writes aren't supported for windowed features")
+	}
+	key, err := primitiveKey(fd, keys, 0)
+	if err != nil {
+		return false, err
+	}
+
+	for i := 0; i < maxConditionalWriteAttempts; i++ {
+		applied := false
+		err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+			stored, err := s.getPrimitive(ctx, tx, fd, keys, 0)
+			if err != nil {
+				return err
+			}
+			if !cond.Met(stored, ts) {
+				return nil
+			}
+			_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+				return s.set(ctx, p, fd, keys, value, ts)
+			})
+			applied = err == nil
+			return err
+		}, key, fmt.Sprintf("%s:ts", key))
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		return applied, err
+	}
+	return false, fmt.Errorf("the value was modified concurrently during %d attempts", maxConditionalWriteAttempts)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	"github.com/spf13/viper"
+)
+
+const deadLettersKey = "_raptor:dlq"
+
+// deadLettersPage is the number of dead-letters that are fetched at once when the dead-letters are filtered.
+const deadLettersPage = 1000
+
+func init() {
+	plugins.DeadLetterQueueFactories.Register(pluginName, DeadLetterQueueFactory)
+}
+
+// DeadLetterQueueFactory creates a DeadLetterQueue that keeps the dead-letters in a Redis stream, which is capped to
+// the latest `redis-dlq-max-len` dead-letters.
+func DeadLetterQueueFactory(viper *viper.Viper) (api.DeadLetterQueue, error) {
+	rc, err := redisClient(viper, viper.GetInt("redis-db"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create redis client: %w", err)
+	}
+	return &deadLetters{
+		client: rc,
+		maxLen: viper.GetInt64("redis-dlq-max-len"),
+	}, nil
+}
+
+type deadLetters struct {
+	client redis.UniversalClient
+	maxLen int64
+}
+
+// SendDeadLetter implements api.DeadLetterQueue
+func (d *deadLetters) SendDeadLetter(ctx context.Context, dl api.DeadLetter) error {
+	msg, err := json.Marshal(dl)
+	if err != nil {
+		return fmt.Errorf("cannot marshal dead-letter: %w", err)
+	}
+	return d.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: deadLettersKey,
+		MaxLen: d.maxLen,
+		Approx: true,
+		Values: map[string]any{"fqn": dl.FQN, "entry": msg},
+	}).Err()
+}
+
+// DeadLetters implements api.DeadLetterStore
+func (d *deadLetters) DeadLetters(ctx context.Context, fqn string, limit int) ([]api.DeadLetter, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var ret []api.DeadLetter
+	start := "+"
+	for len(ret) < limit {
+		msgs, err := d.client.XRevRangeN(ctx, deadLettersKey, start, "-", deadLettersPage).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list dead-letters: %w", err)
+		}
+		for _, msg := range msgs {
+			if fqn != "" && msg.Values["fqn"] != fqn {
+				continue
+			}
+			dl, err := decodeDeadLetter(msg)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, dl)
+			if len(ret) == limit {
+				break
+			}
+		}
+		if len(msgs) < deadLettersPage {
+			break
+		}
+		start = "(" + msgs[len(msgs)-1].ID
+	}
+	return ret, nil
+}
+
+// DeadLetter implements api.DeadLetterStore
+func (d *deadLetters) DeadLetter(ctx context.Context, id string) (api.DeadLetter, error) {
+	msgs, err := d.client.XRange(ctx, deadLettersKey, id, id).Result()
+	if err != nil {
+		return api.DeadLetter{}, fmt.Errorf("failed to get dead-letter: %w", err)
+	}
+	if len(msgs) == 0 {
+		return api.DeadLetter{}, fmt.Errorf("%w: %s", api.ErrDeadLetterNotFound, id)
+	}
+	return decodeDeadLetter(msgs[0])
+}
+
+// RemoveDeadLetter implements api.DeadLetterStore
+func (d *deadLetters) RemoveDeadLetter(ctx context.Context, id string) error {
+	return d.client.XDel(ctx, deadLettersKey, id).Err()
+}
+
+func decodeDeadLetter(msg redis.XMessage) (api.DeadLetter, error) {
+	entry, _ := msg.Values["entry"].(string)
+	var dl api.DeadLetter
+	if err := json.Unmarshal([]byte(entry), &dl); err != nil {
+		return dl, fmt.Errorf("couldn't unmarshal dead-letter %s: %w", msg.ID, err)
+	}
+	dl.ID = msg.ID
+	return dl, nil
+}
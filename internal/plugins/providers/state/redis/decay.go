@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/raptor-ml/raptor/api"
+	"math"
+	"strconv"
+	"time"
+)
+
+// decayBucket is the bucket name of decayed windows, which are kept per entity rather than in time buckets.
+const decayBucket = "decay"
+
+func (s *state) decayAdd(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, val float64, ts time.Time) error {
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return fmt.Errorf("failed to encode keys: %w", err)
+	}
+
+	key := windowKey(fd.FQN, decayBucket, encodedKeys)
+	args := []any{ts.UnixMilli(), fd.HalfLife.Milliseconds(), strconv.FormatFloat(val, 'g', -1, 64), fd.Staleness.Milliseconds()}
+	return luaDecayAdd.Run(ctx, s.client, []string{key}, args...).Err()
+}
+
+func (s *state) getDecay(ctx context.Context, c redis.UniversalClient, fd api.FeatureDescriptor, keys api.Keys) (*api.Value, error) {
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.HGetAll(ctx, windowKey(fd.FQN, decayBucket, encodedKeys)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to get decayed window: %w", err)
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+
+	raw := make(map[string]float64, len(res))
+	for k, v := range res {
+		vv, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse decayed window field %s: %w", k, err)
+		}
+		raw[k] = vv
+	}
+	last := time.UnixMilli(int64(raw["_last"]))
+
+	// decay the aggregations from the latest event to now
+	factor := 1.0
+	if since := time.Since(last); since > 0 {
+		factor = math.Exp2(-float64(since) / float64(fd.HalfLife))
+	}
+
+	ret := make(api.WindowResultMap)
+	for _, fn := range fd.Aggr {
+		switch fn {
+		case api.AggrFnSum:
+			ret[fn] = raw["sum"] * factor
+		case api.AggrFnCount:
+			ret[fn] = raw["count"] * factor
+		case api.AggrFnAvg:
+			if raw["count"] != 0 {
+				ret[fn] = raw["sum"] / raw["count"]
+			}
+		}
+	}
+
+	return &api.Value{
+		Value:     ret,
+		Timestamp: last,
+		Fresh:     true,
+	}, nil
+}
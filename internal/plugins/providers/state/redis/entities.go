@@ -0,0 +1,300 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/raptor-ml/raptor/api"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The activity of the entities is tracked in a Bloom filter (Bitmap) per day and keys, so the memory is bound no
+// matter how many entities there are. A false positive keeps an inactive entity until the next collection, and there
+// are no false negatives.
+const (
+	activityDay     = 24 * time.Hour
+	activityBits    = 1 << 23
+	activityHashes  = 4
+	activityMaxDays = 400
+)
+
+// activityKey is the key of the activity Bloom filter of the entities of the given keys in the given day.
+func activityKey(fd api.FeatureDescriptor, day string) string {
+	return fmt.Sprintf("activity:%s:%s", strings.Join(fd.Keys, ","), day)
+}
+
+func activityOffsets(encodedKeys string) []uint64 {
+	offsets := make([]uint64, activityHashes)
+	for i := range offsets {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte{byte(i)})
+		_, _ = h.Write([]byte(encodedKeys))
+		offsets[i] = h.Sum64() % activityBits
+	}
+	return offsets
+}
+
+// touchEntity records the activity of the entity in today's activity filter.
+func touchEntity(ctx context.Context, tx redis.Cmdable, fd api.FeatureDescriptor, keys api.Keys) error {
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return fmt.Errorf("failed to encode keys: %w", err)
+	}
+	key := activityKey(fd, api.BucketName(time.Now(), activityDay))
+	var args []any
+	for _, o := range activityOffsets(encodedKeys) {
+		args = append(args, "SET", "u1", o, 1)
+	}
+	tx.BitField(ctx, key, args...)
+	tx.Expire(ctx, key, activityMaxDays*activityDay)
+	return nil
+}
+
+// touchEntityNow is a convenience wrapper of touchEntity for the writes that are not done in a transaction.
+func (s *state) touchEntityNow(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys) error {
+	pipe := s.client.Pipeline()
+	if err := touchEntity(ctx, pipe, fd, keys); err != nil {
+		return err
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// activeEntities returns the entities that were active during the horizon.
+func (s *state) activeEntities(ctx context.Context, fd api.FeatureDescriptor, horizon time.Duration, entities []string) (map[string]bool, error) {
+	days := int(horizon/activityDay) + 1
+	now := time.Now()
+
+	pipe := s.client.Pipeline()
+	cmds := make(map[string][]*redis.IntSliceCmd, len(entities))
+	for _, e := range entities {
+		var args []any
+		for _, o := range activityOffsets(e) {
+			args = append(args, "GET", "u1", o)
+		}
+		for d := 0; d < days; d++ {
+			key := activityKey(fd, api.BucketName(now.Add(-activityDay*time.Duration(d)), activityDay))
+			cmds[e] = append(cmds[e], pipe.BitField(ctx, key, args...))
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to check the activity of the entities: %w", err)
+	}
+
+	ret := make(map[string]bool, len(entities))
+	for e, cc := range cmds {
+		for _, c := range cc {
+			// the entity was active in the day if all of its bits in the day's filter are set
+			active := true
+			for _, bit := range c.Val() {
+				if bit == 0 {
+					active = false
+					break
+				}
+			}
+			if active {
+				ret[e] = true
+				break
+			}
+		}
+	}
+	return ret, nil
+}
+
+// CollectInactiveEntities implements api.EntityCollector
+// Windowed features are skipped, since their buckets expire after the staleness anyway. A value is removed only if it
+// was written before the horizon as well, so values that were written before the activity was tracked are kept.
+func (s *state) CollectInactiveEntities(ctx context.Context, fd api.FeatureDescriptor, horizon time.Duration) (int, error) {
+	if fd.ValidWindow() {
+		return 0, nil
+	}
+	if horizon > (activityMaxDays-1)*activityDay {
+		return 0, fmt.Errorf("the horizon must be shorter than %d days", activityMaxDays-1)
+	}
+
+	collected := 0
+	prefix := fmt.Sprintf("%s:", fd.FQN)
+	seen := make(map[string]bool)
+	var batch []string
+	flush := func() error {
+		n, err := s.collectEntities(ctx, fd, horizon, batch)
+		collected += n
+		batch = batch[:0]
+		return err
+	}
+	err := s.scan(ctx, prefix+"*", "", func(key string) error {
+		e := strings.TrimSuffix(strings.TrimPrefix(key, prefix), ":ts")
+		if i := strings.LastIndexByte(e, '/'); i != -1 && fd.KeepPrevious != nil {
+			if _, err := strconv.ParseUint(e[i+1:], 10, 64); err == nil {
+				// previous versions
+				e = e[:i]
+			}
+		}
+		e = entityUntag(e)
+		if seen[e] {
+			return nil
+		}
+		seen[e] = true
+		batch = append(batch, e)
+		if len(batch) == MaxScanCount {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return collected, fmt.Errorf("failed to scan the keys of %s: %w", fd.FQN, err)
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return collected, err
+		}
+	}
+	return collected, nil
+}
+
+func (s *state) collectEntities(ctx context.Context, fd api.FeatureDescriptor, horizon time.Duration, entities []string) (int, error) {
+	active, err := s.activeEntities(ctx, fd, horizon, entities)
+	if err != nil {
+		return 0, err
+	}
+
+	pipe := s.client.Pipeline()
+	tsCmds := make(map[string]*redis.StringCmd)
+	for _, e := range entities {
+		if !active[e] {
+			tsCmds[e] = pipe.Get(ctx, fmt.Sprintf("%s:%s:ts", fd.FQN, entityTag(e)))
+		}
+	}
+	if len(tsCmds) == 0 {
+		return 0, nil
+	}
+	_, _ = pipe.Exec(ctx)
+
+	deadline := time.Now().Add(-horizon)
+	var keys []string
+	n := 0
+	for e, cmd := range tsCmds {
+		if v, err := cmd.Result(); err == nil {
+			ts, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || time.UnixMicro(ts).After(deadline) {
+				continue
+			}
+		}
+		n++
+		key := fmt.Sprintf("%s:%s", fd.FQN, entityTag(e))
+		keys = append(keys, key, key+":ts")
+		if fd.KeepPrevious != nil {
+			for v := uint(1); v <= fd.KeepPrevious.Versions; v++ {
+				vk := fmt.Sprintf("%s/%d", key, v)
+				keys = append(keys, vk, vk+":ts")
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	if err := s.unlink(ctx, keys...); err != nil {
+		return 0, fmt.Errorf("failed to remove the inactive entities of %s: %w", fd.FQN, err)
+	}
+	return n, nil
+}
+
+// DeleteEntityValues implements api.EntityValuesDeleter
+// The keys of the feature are scanned, since the entity may be a part of composite keys, and the buckets of its
+// windows are not known in advance.
+func (s *state) DeleteEntityValues(ctx context.Context, fd api.FeatureDescriptor, key, id string) ([]string, error) {
+	pos := -1
+	for i, k := range fd.Keys {
+		if k == key {
+			pos = i
+		}
+	}
+	if pos == -1 {
+		return nil, nil
+	}
+	matches := func(encodedKeys string) bool {
+		vals := api.DecodeKeyParts(encodedKeys)
+		return len(vals) == len(fd.Keys) && vals[pos] == id
+	}
+
+	// primitive values (and their previous versions), i.e. `<fqn>:<keys>:ts` or `<fqn>:<keys>/<version>`
+	primitive := func(k string) string {
+		e := strings.TrimSuffix(strings.TrimPrefix(k, fd.FQN+":"), ":ts")
+		if i := strings.LastIndexByte(e, '/'); i != -1 {
+			if _, err := strconv.ParseUint(e[i+1:], 10, 64); err == nil {
+				e = e[:i]
+			}
+		}
+		return entityUntag(e)
+	}
+	// window buckets and their sketches, i.e. `hll:<fqn>/<bucket>:<keys>`
+	window := func(prefix string) func(string) string {
+		return func(k string) string {
+			_, _, e := fromWindowKey(strings.TrimPrefix(k, prefix))
+			return e
+		}
+	}
+	patterns := map[string]func(string) string{
+		fd.FQN + ":*": primitive,
+		fd.FQN + "/*": window(""),
+	}
+	for _, prefix := range []string{"hll:", "dd:", "cms:", "topk:"} {
+		patterns[prefix+fd.FQN+"/*"] = window(prefix)
+	}
+
+	removed := make(map[string]struct{})
+	for pattern, entityOf := range patterns {
+		var keys []string
+		err := s.scan(ctx, pattern, "", func(key string) error {
+			e := entityOf(key)
+			if !matches(e) {
+				return nil
+			}
+			removed[e] = struct{}{}
+			keys = append(keys, key)
+			if len(keys) < MaxScanCount {
+				return nil
+			}
+			if err := s.unlink(ctx, keys...); err != nil {
+				return fmt.Errorf("failed to remove the values of %s: %w", fd.FQN, err)
+			}
+			keys = keys[:0]
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan the keys of %s: %w", fd.FQN, err)
+		}
+		if len(keys) > 0 {
+			if err := s.unlink(ctx, keys...); err != nil {
+				return nil, fmt.Errorf("failed to remove the values of %s: %w", fd.FQN, err)
+			}
+		}
+	}
+
+	ret := make([]string, 0, len(removed))
+	for e := range removed {
+		ret = append(ret, e)
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
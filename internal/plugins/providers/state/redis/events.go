@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"github.com/raptor-ml/raptor/api"
+	"sync"
+	"time"
+)
+
+// SetEventBus implements api.EventBusAware. It publishes an api.ProviderReconnectedEvent when Redis is reachable
+// again after a connectivity failure.
+func (s *state) SetEventBus(bus api.EventBus) {
+	s.client.AddHook(&connectivityHook{bus: bus})
+}
+
+// connectivityHook tracks the connectivity failures of the commands.
+type connectivityHook struct {
+	bus       api.EventBus
+	mu        sync.Mutex
+	downSince time.Time
+}
+
+func (h *connectivityHook) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *connectivityHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	h.observe(ctx, cmd.Err())
+	return nil
+}
+
+func (h *connectivityHook) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *connectivityHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if err = cmd.Err(); err != nil {
+			break
+		}
+	}
+	h.observe(ctx, err)
+	return nil
+}
+
+func (h *connectivityHook) observe(ctx context.Context, err error) {
+	var replyErr redis.Error
+	if errors.Is(err, redis.Nil) || errors.As(err, &replyErr) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		// replies of the server (and canceled requests) are not connectivity failures
+		err = nil
+	}
+
+	h.mu.Lock()
+	if err != nil {
+		if h.downSince.IsZero() {
+			h.downSince = time.Now()
+		}
+		h.mu.Unlock()
+		return
+	}
+	if h.downSince.IsZero() {
+		h.mu.Unlock()
+		return
+	}
+	downtime := time.Since(h.downSince)
+	h.downSince = time.Time{}
+	h.mu.Unlock()
+
+	h.bus.Publish(ctx, api.ProviderReconnectedEvent{Provider: pluginName, Downtime: downtime})
+}
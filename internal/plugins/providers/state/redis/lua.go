@@ -32,19 +32,19 @@ func (rs redisScripts) Load(scripter redis.Scripter) error {
 	return nil
 }
 
-var scripts = redisScripts{luaHMax, luaHMin, luaMax, luaMaxExpAt}
+var scripts = redisScripts{luaHMax, luaHMin, luaMax, luaMaxExpAt, luaHMerge, luaSessionAdd, luaTopKAdd, luaDecayAdd, luaListAppend}
 
 // luaHMin doing an atomic MIN operation on a given Hash's Field
 // Arguments:
 //   - KEYS[1] - Hash Key
-//   - KEYS[2] - Field key
-//   - ARGV[1] - Numeric Value
+//   - ARGV[1] - Field key
+//   - ARGV[2] - Numeric Value
 //
 // Returns 1 if there was a change or 0 if not
 var luaHMin = redis.NewScript(`
 local key = KEYS[1]
-local field = KEYS[2]
-local num = tonumber(ARGV[1])
+local field = ARGV[1]
+local num = tonumber(ARGV[2])
 
 local value = redis.call('HGET', key, field)
 if not value or num < tonumber(value) then
@@ -57,14 +57,14 @@ return 0
 // luaHMax doing an atomic MAX operation on a given Hash's Field
 // Arguments:
 //   - KEYS[1] - Hash Key
-//   - KEYS[2] - Field key
-//   - ARGV[1] - Numeric Value
+//   - ARGV[1] - Field key
+//   - ARGV[2] - Numeric Value
 //
 // Returns 1 if there was a change or 0 if not
 var luaHMax = redis.NewScript(`
 local key = KEYS[1]
-local field = KEYS[2]
-local num = tonumber(ARGV[1])
+local field = ARGV[1]
+local num = tonumber(ARGV[2])
 
 local value = redis.call('HGET', key, field)
 if not value or num > tonumber(value) then
@@ -119,3 +119,231 @@ end
 
 return 0
 `)
+
+// luaHMerge merges the given fields of window buckets (Hashes) on the server, so a window is fetched in a single
+// round-trip. `min` and `max` fields are merged by their minimum and maximum, and the rest are summed.
+// Arguments:
+//   - KEYS - Hash keys (the window buckets)
+//   - ARGV - Field keys
+//
+// Returns a flat list of the merged fields and values (i.e. {"sum", "12.5", "count", "3"}). Missing fields are omitted.
+var luaHMerge = redis.NewScript(`
+local acc = {}
+for _, key in ipairs(KEYS) do
+  local vals = redis.call('HMGET', key, unpack(ARGV))
+  for i = 1, #ARGV do
+    local v = vals[i]
+    if v then
+      local field = ARGV[i]
+      local num = tonumber(v)
+      local cur = acc[field]
+      if cur == nil then
+        acc[field] = num
+      elseif field == 'min' then
+        if num < cur then acc[field] = num end
+      elseif field == 'max' then
+        if num > cur then acc[field] = num end
+      else
+        acc[field] = cur + num
+      end
+    end
+  end
+end
+
+local ret = {}
+for i = 1, #ARGV do
+  local field = ARGV[i]
+  if acc[field] ~= nil then
+    table.insert(ret, field)
+    table.insert(ret, string.format('%.17g', acc[field]))
+  end
+end
+return ret
+`)
+
+// luaSessionAdd adds a value to the session window (Hash) of an entity. The session is closed when there is no event
+// for the session gap (by the events' time), and the next event starts a new session.
+// Arguments:
+//   - KEYS[1] - Session Key
+//   - KEYS[2] - Session Sketch Key (HyperLogLog of the distinct values)
+//   - ARGV[1] - Event time (unix milliseconds)
+//   - ARGV[2] - Session gap (milliseconds)
+//   - ARGV[3] - Current time (unix milliseconds)
+//   - ARGV[4] - Value
+//   - ARGV[5...] - Aggregation functions (sum, count, min, max, count_distinct)
+//
+// Returns 1 if the value was added to a session, or 0 if the session of the event is already closed.
+var luaSessionAdd = redis.NewScript(`
+local key = KEYS[1]
+local sketch = KEYS[2]
+local ts = tonumber(ARGV[1])
+local gap = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local val = ARGV[4]
+
+if ts + gap <= now then
+  return 0
+end
+
+local start = tonumber(redis.call('HGET', key, '_start'))
+local last = tonumber(redis.call('HGET', key, '_last'))
+if last and ts > last + gap then
+  redis.call('DEL', key, sketch)
+  start = nil
+  last = nil
+elseif start and ts < start - gap then
+  return 0
+end
+
+local num = tonumber(val)
+for i = 5, #ARGV do
+  local fn = ARGV[i]
+  if fn == 'sum' then
+    redis.call('HINCRBYFLOAT', key, 'sum', num)
+  elseif fn == 'count' then
+    redis.call('HINCRBY', key, 'count', 1)
+  elseif fn == 'min' or fn == 'max' then
+    local cur = tonumber(redis.call('HGET', key, fn))
+    if not cur or (fn == 'min' and num < cur) or (fn == 'max' and num > cur) then
+      redis.call('HSET', key, fn, val)
+    end
+  elseif fn == 'count_distinct' then
+    redis.call('PFADD', sketch, val)
+  end
+end
+
+if not start or ts < start then
+  redis.call('HSET', key, '_start', ts)
+end
+if not last or ts > last then
+  redis.call('HSET', key, '_last', ts)
+  last = ts
+end
+
+redis.call('PEXPIREAT', key, last + gap)
+if redis.call('EXISTS', sketch) == 1 then
+  redis.call('PEXPIREAT', sketch, last + gap)
+end
+return 1
+`)
+
+// luaTopKAdd adds a value to the count-min sketch (Hash) of a top-K window bucket, and updates the value's estimated
+// count in the bucket's heavy-hitters candidates (Sorted Set). The candidates are trimmed to the least frequent ones.
+// Arguments:
+//   - KEYS[1] - Count-min Sketch Key
+//   - KEYS[2] - Candidates Key
+//   - ARGV[1] - Value
+//   - ARGV[2] - Number of candidates to keep
+//   - ARGV[3] - Expire at (unix milliseconds)
+//   - ARGV[4...] - The value's counters in the sketch
+//
+// Returns the estimated count of the value in the bucket
+var luaTopKAdd = redis.NewScript(`
+local sketch = KEYS[1]
+local candidates = KEYS[2]
+local val = ARGV[1]
+local capacity = tonumber(ARGV[2])
+local expireAt = ARGV[3]
+
+local estimate
+for i = 4, #ARGV do
+  local n = redis.call('HINCRBY', sketch, ARGV[i], 1)
+  if not estimate or n < estimate then
+    estimate = n
+  end
+end
+
+redis.call('ZADD', candidates, estimate, val)
+local size = redis.call('ZCARD', candidates)
+if size > capacity then
+  redis.call('ZREMRANGEBYRANK', candidates, 0, size - capacity - 1)
+end
+
+redis.call('PEXPIREAT', sketch, expireAt)
+redis.call('PEXPIREAT', candidates, expireAt)
+return estimate
+`)
+
+// luaDecayAdd adds a value to the exponentially decayed window (Hash) of an entity. The sum and the count (the sum of
+// the weights) are decayed to the time of the latest event, and a value of an older event is decayed before it's added.
+// Arguments:
+//   - KEYS[1] - Decay Key
+//   - ARGV[1] - Event time (unix milliseconds)
+//   - ARGV[2] - Half-life (milliseconds)
+//   - ARGV[3] - Value
+//   - ARGV[4] - TTL (milliseconds)
+//
+// Returns the decayed sum
+var luaDecayAdd = redis.NewScript(`
+local key = KEYS[1]
+local ts = tonumber(ARGV[1])
+local halfLife = tonumber(ARGV[2])
+local val = tonumber(ARGV[3])
+
+local last = tonumber(redis.call('HGET', key, '_last'))
+local sum = tonumber(redis.call('HGET', key, 'sum')) or 0
+local count = tonumber(redis.call('HGET', key, 'count')) or 0
+
+local weight = 1
+if not last then
+  last = ts
+elseif ts > last then
+  local factor = 2 ^ (-(ts - last) / halfLife)
+  sum = sum * factor
+  count = count * factor
+  last = ts
+else
+  weight = 2 ^ (-(last - ts) / halfLife)
+end
+
+sum = sum + val * weight
+count = count + weight
+redis.call('HSET', key, 'sum', string.format('%.17g', sum), 'count', string.format('%.17g', count), '_last', last)
+redis.call('PEXPIRE', key, ARGV[4])
+return string.format('%.17g', sum)
+`)
+
+// luaListAppend appends elements to a list by the list policy of the feature: the previous occurrences of the elements
+// (or of their keys, for upserts) are removed, and the list is trimmed to its latest elements.
+// Arguments:
+//   - KEYS[1] - List Key
+//   - ARGV[1] - Max length (0 for unbounded)
+//   - ARGV[2] - Mode: `dedup`, `upsert` or empty for none
+//   - ARGV[3] - The delimiter of the keys of the elements, for upserts
+//   - ARGV[4...] - Elements
+//
+// Returns the length of the list
+var luaListAppend = redis.NewScript(`
+local key = KEYS[1]
+local maxLen = tonumber(ARGV[1])
+local mode = ARGV[2]
+local delim = ARGV[3]
+
+local function keyOf(v)
+  local i = string.find(v, delim, 1, true)
+  if i then
+    return string.sub(v, 1, i - 1)
+  end
+  return v
+end
+
+for i = 4, #ARGV do
+  local val = ARGV[i]
+  if mode == 'dedup' then
+    redis.call('LREM', key, 0, val)
+  elseif mode == 'upsert' then
+    local k = keyOf(val)
+    for _, e in ipairs(redis.call('LRANGE', key, 0, -1)) do
+      if keyOf(e) == k then
+        redis.call('LREM', key, 0, e)
+      end
+    end
+  end
+  redis.call('RPUSH', key, val)
+end
+
+if maxLen > 0 then
+  redis.call('LTRIM', key, -maxLen, -1)
+end
+return redis.call('LLEN', key)
+`)
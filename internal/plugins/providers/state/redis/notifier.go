@@ -24,6 +24,7 @@ import (
 	"github.com/raptor-ml/raptor/api"
 	"github.com/raptor-ml/raptor/pkg/plugins"
 	"github.com/spf13/viper"
+	"strings"
 )
 
 func init() {
@@ -56,7 +57,16 @@ func (n *notifier[T]) NotificationChannel() string {
 }
 
 func (n *notifier[T]) Notify(ctx context.Context, notification T) error {
-	msg, err := json.Marshal(notification)
+	return n.publish(ctx, notification)
+}
+
+// NotifyBatch publishes the notifications as a single message.
+func (n *notifier[T]) NotifyBatch(ctx context.Context, notifications []T) error {
+	return n.publish(ctx, notifications)
+}
+
+func (n *notifier[T]) publish(ctx context.Context, v any) error {
+	msg, err := json.Marshal(v)
 	if err != nil {
 		return fmt.Errorf("cannot marshal notification: %w", err)
 	}
@@ -79,6 +89,18 @@ func (n *notifier[T]) Subscribe(ctx context.Context) (<-chan T, error) {
 	}()
 	go func() {
 		for msg := range pubsub.Channel() {
+			if strings.HasPrefix(msg.Payload, "[") {
+				// a batch of notifications (see NotifyBatch)
+				var notifications []T
+				err := json.Unmarshal([]byte(msg.Payload), &notifications)
+				if err != nil {
+					panic(fmt.Errorf("couldn't unmarshal notifications: %w", err))
+				}
+				for _, notification := range notifications {
+					c <- notification
+				}
+				continue
+			}
 			var notification T
 			err := json.Unmarshal([]byte(msg.Payload), &notification)
 			if err != nil {
@@ -35,26 +35,45 @@ func primitiveKey(fd api.FeatureDescriptor, keys api.Keys, version uint) (string
 	if version > 0 {
 		ver = fmt.Sprintf("/%d", version)
 	}
-	return fmt.Sprintf("%s:%s%s", fd.Keys, e, ver), nil
+	return fmt.Sprintf("%s:%s%s", fd.FQN, entityTag(e), ver), nil
 }
 
 func (s *state) Get(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, version uint) (*api.Value, error) {
+	c := s.reader(fd)
+	val, err := s.get(ctx, c, fd, keys, version)
+	if err != nil && c != s.client {
+		// the replicas are unavailable, fall back to the primary
+		return s.get(ctx, s.client, fd, keys, version)
+	}
+	return val, err
+}
+
+func (s *state) get(ctx context.Context, c redis.UniversalClient, fd api.FeatureDescriptor, keys api.Keys, version uint) (*api.Value, error) {
 	if fd.ValidWindow() {
 		if version != 0 {
 			return nil, fmt.Errorf("version is not supported for windowed features")
 		}
-		return s.getWindow(ctx, fd, keys)
+		if fd.SessionWindow() {
+			return s.getSession(ctx, c, fd, keys)
+		}
+		if fd.DecayWindow() {
+			return s.getDecay(ctx, c, fd, keys)
+		}
+		if fd.TopKWindow() {
+			return s.getTopK(ctx, c, fd, keys)
+		}
+		return s.getWindow(ctx, c, fd, keys)
 	}
-	return s.getPrimitive(ctx, fd, keys, version)
+	return s.getPrimitive(ctx, c, fd, keys, version)
 }
 
-func (s *state) getPrimitive(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, version uint) (*api.Value, error) {
+func (s *state) getPrimitive(ctx context.Context, c redis.Cmdable, fd api.FeatureDescriptor, keys api.Keys, version uint) (*api.Value, error) {
 	key, err := primitiveKey(fd, keys, version)
 	if err != nil {
 		return nil, err
 	}
 
-	ts, err := getTimestamp(ctx, s.client, key)
+	ts, err := getTimestamp(ctx, c, key)
 	if errors.Is(err, redis.Nil) {
 		return nil, nil
 	} else if err != nil {
@@ -66,7 +85,7 @@ func (s *state) getPrimitive(ctx context.Context, fd api.FeatureDescriptor, keys
 
 	var val any
 	if fd.Primitive.Scalar() {
-		res, err := s.client.Get(ctx, key).Result()
+		res, err := c.Get(ctx, key).Result()
 		if err != nil {
 			return nil, err
 		}
@@ -76,7 +95,7 @@ func (s *state) getPrimitive(ctx context.Context, fd api.FeatureDescriptor, keys
 		}
 	} else {
 		var ret []any
-		res, err := s.client.LRange(ctx, key, 0, -1).Result()
+		res, err := c.LRange(ctx, key, 0, -1).Result()
 		if err != nil {
 			return nil, err
 		}
@@ -138,6 +157,14 @@ func (s *state) Set(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys
 	if fd.ValidWindow() {
 		return s.WindowAdd(ctx, fd, keys, value, ts)
 	}
+	tx := s.client.TxPipeline()
+	if err := s.set(ctx, tx, fd, keys, value, ts); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx)
+	return err
+}
+func (s *state) set(ctx context.Context, tx redis.Pipeliner, fd api.FeatureDescriptor, keys api.Keys, value any, ts time.Time) error {
 	if time.Since(ts) > fd.Staleness {
 		return fmt.Errorf("timestamp %s is too old", ts)
 	}
@@ -147,33 +174,45 @@ func (s *state) Set(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys
 		return err
 	}
 
-	tx := s.client.TxPipeline()
+	if err := touchEntity(ctx, tx, fd, keys); err != nil {
+		return err
+	}
 	if err := s.keepVersions(ctx, tx, fd, keys, ts); err != nil {
 		return fmt.Errorf("failed to keep versions while updating value: %w", err)
 	}
 
 	if fd.Primitive.Scalar() {
-		tx.Set(ctx, key, api.ScalarString(value), fd.Staleness)
+		tx.Set(ctx, key, api.ScalarString(value), fd.ValueTTL())
 	} else {
 		tx.Del(ctx, key)
 		var kv []any
 		for i := 0; i < reflect.ValueOf(value).Len(); i++ {
 			kv = append(kv, reflect.ValueOf(value).Index(i).Interface())
 		}
-		tx.RPush(ctx, key, kv...)
+		if fd.ListPolicy != nil {
+			listAppend(ctx, tx, fd, key, kv)
+		} else {
+			tx.RPush(ctx, key, kv...)
+		}
 		if fd.Staleness > 0 {
-			tx.PExpire(ctx, key, fd.Staleness)
+			tx.PExpire(ctx, key, fd.ValueTTL())
 		}
 	}
-	setTimestamp(ctx, tx, key, ts, fd.Staleness)
-
-	_, err = tx.Exec(ctx)
-	return err
+	setTimestamp(ctx, tx, key, ts, fd.ValueTTL())
+	return nil
 }
 func (s *state) Append(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, value any, ts time.Time) error {
 	if fd.ValidWindow() {
 		return fmt.Errorf("cannot append a windowed feature")
 	}
+	tx := s.client.TxPipeline()
+	if err := s.append(ctx, tx, fd, keys, value, ts); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx)
+	return err
+}
+func (s *state) append(ctx context.Context, tx redis.Pipeliner, fd api.FeatureDescriptor, keys api.Keys, value any, ts time.Time) error {
 	if time.Since(ts) > fd.Staleness {
 		return fmt.Errorf("timestamp %s is too old", ts)
 	}
@@ -186,39 +225,84 @@ func (s *state) Append(ctx context.Context, fd api.FeatureDescriptor, keys api.K
 		return err
 	}
 
-	tx := s.client.TxPipeline()
+	if err := touchEntity(ctx, tx, fd, keys); err != nil {
+		return err
+	}
 
 	if err := s.keepVersions(ctx, tx, fd, keys, ts); err != nil {
 		return fmt.Errorf("failed to keep versions while updating value: %w", err)
 	}
 
-	tx.RPush(ctx, key, value)
+	if fd.ListPolicy != nil {
+		listAppend(ctx, tx, fd, key, listElements(value))
+	} else {
+		tx.RPush(ctx, key, value)
+	}
 	if fd.Staleness > 0 {
-		tx.PExpire(ctx, key, fd.Staleness)
+		tx.PExpire(ctx, key, fd.ValueTTL())
 	}
-	setTimestamp(ctx, tx, key, ts, fd.Staleness)
+	setTimestamp(ctx, tx, key, ts, fd.ValueTTL())
+	return nil
+}
 
-	_, err = tx.Exec(ctx)
-	return err
+// listAppend appends the elements to the list by the list policy of the feature (see luaListAppend).
+func listAppend(ctx context.Context, tx redis.Cmdable, fd api.FeatureDescriptor, key string, elements []any) {
+	mode := ""
+	switch {
+	case fd.ListPolicy.UpsertKeyDelimiter != "":
+		mode = "upsert"
+	case fd.ListPolicy.Dedup:
+		mode = "dedup"
+	}
+	args := append([]any{fd.ListPolicy.MaxLength, mode, fd.ListPolicy.UpsertKeyDelimiter}, elements...)
+	luaListAppend.Run(ctx, tx, []string{key}, args...)
+}
+
+// listElements returns the elements of an appended value, which is either a single element or a list of them.
+func listElements(value any) []any {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []any{value}
+	}
+	ret := make([]any, v.Len())
+	for i := range ret {
+		ret[i] = v.Index(i).Interface()
+	}
+	return ret
 }
 
 func (s *state) Incr(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, value any, ts time.Time) error {
 	if fd.ValidWindow() {
 		return fmt.Errorf("cannot increment to a windowed feature")
 	}
+	tx := s.client.TxPipeline()
+	if err := s.incr(ctx, tx, fd, keys, value, ts); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx)
+	return err
+}
+func (s *state) incr(ctx context.Context, tx redis.Pipeliner, fd api.FeatureDescriptor, keys api.Keys, value any, ts time.Time) error {
 	if time.Since(ts) > fd.Staleness {
 		return fmt.Errorf("timestamp %s is too old", ts)
 	}
 	if !fd.Primitive.Scalar() {
 		return fmt.Errorf("`Ince` only supports sclars")
 	}
+	switch value.(type) {
+	case int, float64:
+	default:
+		return fmt.Errorf("`Incr` only supports scalar numberic values")
+	}
 
 	key, err := primitiveKey(fd, keys, 0)
 	if err != nil {
 		return err
 	}
 
-	tx := s.client.TxPipeline()
+	if err := touchEntity(ctx, tx, fd, keys); err != nil {
+		return err
+	}
 
 	if err := s.keepVersions(ctx, tx, fd, keys, ts); err != nil {
 		return fmt.Errorf("failed to keep versions while updating value: %w", err)
@@ -229,15 +313,11 @@ func (s *state) Incr(ctx context.Context, fd api.FeatureDescriptor, keys api.Key
 		tx.IncrBy(ctx, key, int64(v))
 	case float64:
 		tx.IncrByFloat(ctx, key, v)
-	default:
-		return fmt.Errorf("`Incr` only supports scalar numberic values")
 	}
 
 	if fd.Staleness > 0 {
-		tx.PExpire(ctx, key, fd.Staleness)
+		tx.PExpire(ctx, key, fd.ValueTTL())
 	}
-	setTimestamp(ctx, tx, key, ts, fd.Staleness)
-
-	_, err = tx.Exec(ctx)
-	return err
+	setTimestamp(ctx, tx, key, ts, fd.ValueTTL())
+	return nil
 }
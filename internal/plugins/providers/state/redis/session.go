@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/raptor-ml/raptor/api"
+	"strconv"
+	"time"
+)
+
+// sessionBucket is the bucket name of session windows, which are kept per entity rather than in time buckets.
+const sessionBucket = "session"
+
+func (s *state) sessionAdd(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, val float64, ts time.Time) error {
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return fmt.Errorf("failed to encode keys: %w", err)
+	}
+
+	args := []any{ts.UnixMilli(), fd.SessionGap.Milliseconds(), time.Now().UnixMilli(), strconv.FormatFloat(val, 'g', -1, 64)}
+	for _, fn := range []api.AggrFn{api.AggrFnSum, api.AggrFnCount, api.AggrFnMin, api.AggrFnMax, api.AggrFnCountDistinct} {
+		avgDep := (fn == api.AggrFnSum || fn == api.AggrFnCount) && hasAggrFn(fd.Aggr, api.AggrFnAvg)
+		if hasAggrFn(fd.Aggr, fn) || avgDep {
+			args = append(args, fn.String())
+		}
+	}
+
+	keyz := []string{windowKey(fd.FQN, sessionBucket, encodedKeys), sketchKey(fd.FQN, sessionBucket, encodedKeys)}
+	return luaSessionAdd.Run(ctx, s.client, keyz, args...).Err()
+}
+
+func (s *state) getSession(ctx context.Context, c redis.UniversalClient, fd api.FeatureDescriptor, keys api.Keys) (*api.Value, error) {
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := c.Pipeline()
+	hCmd := pipe.HGetAll(ctx, windowKey(fd.FQN, sessionBucket, encodedKeys))
+	pfCmd := pipe.PFCount(ctx, sketchKey(fd.FQN, sessionBucket, encodedKeys))
+	_, _ = pipe.Exec(ctx)
+
+	res, err := hCmd.Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+
+	raw := make(map[string]float64, len(res))
+	for k, v := range res {
+		vv, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse session field %s: %w", k, err)
+		}
+		raw[k] = vv
+	}
+	start := time.UnixMilli(int64(raw["_start"]))
+	last := time.UnixMilli(int64(raw["_last"]))
+
+	ret := make(api.WindowResultMap)
+	for _, fn := range fd.Aggr {
+		switch fn {
+		case api.AggrFnSum, api.AggrFnCount, api.AggrFnMin, api.AggrFnMax:
+			if v, ok := raw[fn.String()]; ok {
+				ret[fn] = v
+			}
+		case api.AggrFnAvg:
+			if raw["count"] != 0 {
+				ret[fn] = raw["sum"] / raw["count"]
+			}
+		case api.AggrFnDuration:
+			ret[fn] = last.Sub(start).Seconds()
+		case api.AggrFnCountDistinct:
+			n, _ := pfCmd.Result()
+			ret[fn] = float64(n)
+		}
+	}
+
+	return &api.Value{
+		Value:     ret,
+		Timestamp: last,
+		Fresh:     time.Since(last) < fd.SessionGap,
+	}, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/raptor-ml/raptor/api"
+	"time"
+)
+
+// SnapshotFeature implements api.StateSnapshotter
+// The keys of the feature are exported by DUMP, so they're restored as-is (including the window buckets and sketches)
+// by a Redis of the same (or a newer) version.
+func (s *state) SnapshotFeature(ctx context.Context, fqn string, fn func(api.StateSnapshotRecord) error) (int, error) {
+	count := 0
+	dump := func(keys []string) error {
+		pipe := s.client.Pipeline()
+		dumps := make([]*redis.StringCmd, len(keys))
+		ttls := make([]*redis.DurationCmd, len(keys))
+		for i, k := range keys {
+			dumps[i] = pipe.Dump(ctx, k)
+			ttls[i] = pipe.PTTL(ctx, k)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return fmt.Errorf("failed to dump the keys of %s: %w", fqn, err)
+		}
+		now := time.Now()
+		for i, k := range keys {
+			data, err := dumps[i].Bytes()
+			if errors.Is(err, redis.Nil) {
+				// the key expired during the scan
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to dump %s: %w", k, err)
+			}
+			rec := api.StateSnapshotRecord{FQN: fqn, Key: k, Data: data}
+			if ttl := ttls[i].Val(); ttl > 0 {
+				rec.ExpireAt = now.Add(ttl)
+			}
+			if err := fn(rec); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	}
+
+	for _, p := range featurePatterns(fqn) {
+		var keys []string
+		err := s.scan(ctx, p, "", func(key string) error {
+			keys = append(keys, key)
+			if len(keys) < MaxScanCount {
+				return nil
+			}
+			defer func() { keys = keys[:0] }()
+			return dump(keys)
+		})
+		if err != nil {
+			return count, fmt.Errorf("failed to scan the keys of %s: %w", fqn, err)
+		}
+		if len(keys) > 0 {
+			if err := dump(keys); err != nil {
+				return count, err
+			}
+		}
+	}
+	return count, dump([]string{lastReadKey(fqn), lastUpdateKey(fqn)})
+}
+
+// RestoreSnapshot implements api.StateSnapshotter
+func (s *state) RestoreSnapshot(ctx context.Context, records []api.StateSnapshotRecord) error {
+	now := time.Now()
+	pipe := s.client.Pipeline()
+	n := 0
+	for _, rec := range records {
+		var ttl time.Duration
+		if !rec.ExpireAt.IsZero() {
+			ttl = rec.ExpireAt.Sub(now)
+			if ttl < time.Millisecond {
+				continue
+			}
+		}
+		pipe.RestoreReplace(ctx, rec.Key, ttl, string(rec.Data))
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to restore the snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/raptor-ml/raptor/api"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// countMinKey is the key of the count-min sketch of a top-K window bucket.
+func countMinKey(FQN string, bucketName string, encodedKeys string) string {
+	return "cms:" + windowKey(FQN, bucketName, encodedKeys)
+}
+
+// topKCandidatesKey is the key of the heavy-hitters candidates of a top-K window bucket.
+func topKCandidatesKey(FQN string, bucketName string, encodedKeys string) string {
+	return "topk:" + windowKey(FQN, bucketName, encodedKeys)
+}
+
+func (s *state) topKAdd(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, value any, ts time.Time) error {
+	val, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("unsupported value type %T for a top-k window", value)
+	}
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return fmt.Errorf("failed to encode keys: %w", err)
+	}
+	bucket := api.BucketName(ts, fd.Freshness)
+
+	args := []any{val, api.TopKCandidates(fd.TopK), fd.BucketDeadTime(bucket).UnixMilli()}
+	for _, c := range api.CountMinCells(val) {
+		args = append(args, c)
+	}
+	keyz := []string{countMinKey(fd.FQN, bucket, encodedKeys), topKCandidatesKey(fd.FQN, bucket, encodedKeys)}
+	return luaTopKAdd.Run(ctx, s.client, keyz, args...).Err()
+}
+
+// getTopK returns the most frequent values of the window. The window's candidates are the union of the buckets'
+// candidates, and their counts are the sum of their estimated counts in each bucket. When a candidate was trimmed
+// from a bucket, its count in the bucket is estimated by the bucket's count-min sketch.
+func (s *state) getTopK(ctx context.Context, c redis.UniversalClient, fd api.FeatureDescriptor, keys api.Keys) (*api.Value, error) {
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return nil, err
+	}
+	bucketNames := fd.AliveWindowBuckets()
+
+	pipe := c.Pipeline()
+	cmds := make([]*redis.ZSliceCmd, len(bucketNames))
+	for i, b := range bucketNames {
+		cmds[i] = pipe.ZRangeWithScores(ctx, topKCandidatesKey(fd.FQN, b, encodedKeys), 0, -1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to get the top-k candidates: %w", err)
+	}
+
+	counts := make(map[string]float64)
+	found := make([]map[string]bool, len(bucketNames))
+	for i, cmd := range cmds {
+		found[i] = make(map[string]bool)
+		for _, z := range cmd.Val() {
+			member := z.Member.(string)
+			counts[member] += z.Score
+			found[i][member] = true
+		}
+	}
+	if len(counts) == 0 {
+		return nil, nil
+	}
+
+	// complete the counts of the candidates that were trimmed from some buckets
+	pipe = c.Pipeline()
+	type missing struct {
+		member string
+		cmd    *redis.SliceCmd
+	}
+	var missings []missing
+	for i, b := range bucketNames {
+		if len(found[i]) == 0 {
+			continue
+		}
+		for member := range counts {
+			if found[i][member] {
+				continue
+			}
+			cells := api.CountMinCells(member)
+			missings = append(missings, missing{member: member, cmd: pipe.HMGet(ctx, countMinKey(fd.FQN, b, encodedKeys), cells...)})
+		}
+	}
+	if len(missings) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("failed to get the top-k sketches: %w", err)
+		}
+		for _, m := range missings {
+			counts[m.member] += countMinEstimate(m.cmd.Val())
+		}
+	}
+
+	members := make([]string, 0, len(counts))
+	for member := range counts {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if counts[members[i]] == counts[members[j]] {
+			return members[i] < members[j]
+		}
+		return counts[members[i]] > counts[members[j]]
+	})
+	if len(members) > fd.TopK {
+		members = members[:fd.TopK]
+	}
+
+	return &api.Value{
+		Value:     members,
+		Timestamp: time.Now(),
+		Fresh:     true,
+	}, nil
+}
+
+// countMinEstimate returns the estimated count of a value by its counters, which is the minimum of them.
+func countMinEstimate(counters []any) float64 {
+	var ret float64
+	for i, c := range counters {
+		s, ok := c.(string)
+		if !ok {
+			// a missing counter means the value was never counted
+			return 0
+		}
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0
+		}
+		if i == 0 || n < ret {
+			ret = n
+		}
+	}
+	return ret
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/raptor-ml/raptor/api"
+)
+
+// WriteTransaction implements api.TransactionalWriter
+// The writes are validated (i.e. they're not too old) before any of them is queued, and they're applied by a single
+// MULTI/EXEC transaction, so no other client sees a part of them. Redis doesn't roll back the commands that fail while
+// they're executed (i.e. a key of the wrong type), so the rest of the writes are applied regardless.
+// The writes must be of a single entity, since a Redis Cluster splits a transaction by the slots of its keys. On a
+// Redis Cluster, transactions require hash tags (see entityTag), so all the values of the entity are in the same slot,
+// while the activity of the entity (see touchEntity) is in another slot, and it's recorded by a separate transaction.
+func (s *state) WriteTransaction(ctx context.Context, writes []api.StateWrite) error {
+	if err := s.checkTransaction(writes); err != nil {
+		return err
+	}
+
+	tx := s.client.TxPipeline()
+	for _, w := range writes {
+		if err := s.queueTransactionWrite(ctx, tx, w); err != nil {
+			tx.Discard()
+			return fmt.Errorf("failed to write feature %s: %w", w.FeatureDescriptor.FQN, err)
+		}
+	}
+	_, err := tx.Exec(ctx)
+	return err
+}
+
+func (s *state) queueTransactionWrite(ctx context.Context, tx redis.Pipeliner, w api.StateWrite) error {
+	fd := w.FeatureDescriptor
+	if fd.ValidWindow() {
+		if !fd.BucketedWindow() || w.Method != api.StateMethodWindowAdd {
+			return fmt.Errorf("only bucketed windows can be added to in transactions")
+		}
+		val, err := windowValue(w.Value)
+		if err != nil {
+			return err
+		}
+		return s.bucketAdd(ctx, tx, fd, w.Keys, val, w.Timestamp)
+	}
+	switch w.Method {
+	case api.StateMethodSet:
+		return s.set(ctx, tx, fd, w.Keys, w.Value, w.Timestamp)
+	case api.StateMethodAppend:
+		return s.append(ctx, tx, fd, w.Keys, w.Value, w.Timestamp)
+	case api.StateMethodIncr:
+		return s.incr(ctx, tx, fd, w.Keys, w.Value, w.Timestamp)
+	default:
+		return fmt.Errorf("method %s can't be written in transactions", w.Method)
+	}
+}
+
+// checkTransaction verifies that the writes are of a single entity, and can be applied by a single transaction.
+func (s *state) checkTransaction(writes []api.StateWrite) error {
+	if _, ok := s.client.(*redis.ClusterClient); ok && !hashTags && len(writes) > 1 {
+		return fmt.Errorf("%w: transactions of a Redis Cluster require hash tags", api.ErrTransactionsUnsupported)
+	}
+	entity := ""
+	for i, w := range writes {
+		encodedKeys, err := w.Keys.Encode(w.FeatureDescriptor)
+		if err != nil {
+			return fmt.Errorf("failed to encode keys: %w", err)
+		}
+		if i == 0 {
+			entity = encodedKeys
+		} else if encodedKeys != entity {
+			return fmt.Errorf("%w: %s is written for another entity", api.ErrTransactionSpansEntities, w.FeatureDescriptor.FQN)
+		}
+	}
+	return nil
+}
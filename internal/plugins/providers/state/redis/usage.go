@@ -0,0 +1,151 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"strconv"
+	"time"
+)
+
+// usageTTL is the time to keep the last-read (or last-update) timestamp of a feature after its last read (or update).
+const usageTTL = 90 * 24 * time.Hour
+
+func lastReadKey(fqn string) string {
+	return fmt.Sprintf("lastread:%s", fqn)
+}
+
+func lastUpdateKey(fqn string) string {
+	return fmt.Sprintf("lastupdate:%s", fqn)
+}
+
+// TouchFeature implements api.UsageTracker
+func (s *state) TouchFeature(ctx context.Context, fqn string, ts time.Time) error {
+	return luaMax.Run(ctx, s.client, []string{lastReadKey(fqn)}, ts.UnixMicro(), usageTTL.Milliseconds()).Err()
+}
+
+// FeatureLastRead implements api.UsageTracker
+func (s *state) FeatureLastRead(ctx context.Context, fqn string) (time.Time, error) {
+	return s.timestamp(ctx, lastReadKey(fqn))
+}
+
+// MarkFeatureUpdated implements api.FreshnessTracker
+func (s *state) MarkFeatureUpdated(ctx context.Context, fqn string, ts time.Time) error {
+	return luaMax.Run(ctx, s.client, []string{lastUpdateKey(fqn)}, ts.UnixMicro(), usageTTL.Milliseconds()).Err()
+}
+
+// FeatureLastUpdate implements api.FreshnessTracker
+func (s *state) FeatureLastUpdate(ctx context.Context, fqn string) (time.Time, error) {
+	return s.timestamp(ctx, lastUpdateKey(fqn))
+}
+
+func (s *state) timestamp(ctx context.Context, key string) (time.Time, error) {
+	v, err := s.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	ts, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse the timestamp of %s: %w", key, err)
+	}
+	return time.UnixMicro(ts), nil
+}
+
+// featurePatterns are the patterns of the keys of all the values, buckets and metadata of the feature.
+func featurePatterns(fqn string) []string {
+	return []string{
+		fmt.Sprintf("%s:*", fqn),
+		fmt.Sprintf("%s/*", fqn),
+		sketchKey(fqn, "*", "*"),
+		quantileSketchKey(fqn, "*", "*"),
+		countMinKey(fqn, "*", "*"),
+		topKCandidatesKey(fqn, "*", "*"),
+		eventKey(fqn, "*", "*"),
+	}
+}
+
+// PurgeFeature implements api.Purger
+func (s *state) PurgeFeature(ctx context.Context, fqn string) error {
+	for _, p := range featurePatterns(fqn) {
+		var keys []string
+		err := s.scan(ctx, p, "", func(key string) error {
+			keys = append(keys, key)
+			if len(keys) < MaxScanCount {
+				return nil
+			}
+			if err := s.unlink(ctx, keys...); err != nil {
+				return fmt.Errorf("failed to purge the keys of %s: %w", fqn, err)
+			}
+			keys = keys[:0]
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to scan the keys of %s: %w", fqn, err)
+		}
+		if len(keys) > 0 {
+			if err := s.unlink(ctx, keys...); err != nil {
+				return fmt.Errorf("failed to purge the keys of %s: %w", fqn, err)
+			}
+		}
+	}
+	return s.unlink(ctx, lastReadKey(fqn), lastUpdateKey(fqn))
+}
+
+// memorySamples is the number of keys of each pattern whose memory usage is measured. The usage of the rest of the keys
+// is estimated by the average of the measured ones.
+const memorySamples = 100
+
+// FeatureMemoryUsage implements api.MemoryUsageEstimator
+// The keys of the feature are scanned and counted, and the memory usage is measured only for a sample of them, so the
+// estimation doesn't load the server much more than the scan.
+func (s *state) FeatureMemoryUsage(ctx context.Context, fqn string) (int64, error) {
+	var total int64
+	for _, p := range featurePatterns(fqn) {
+		var count, sampled, measured int64
+		err := s.scan(ctx, p, "", func(key string) error {
+			count++
+			if sampled == memorySamples {
+				return nil
+			}
+			n, err := s.client.MemoryUsage(ctx, key).Result()
+			if errors.Is(err, redis.Nil) {
+				// the key expired during the scan
+				count--
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to measure the memory usage of %s: %w", fqn, err)
+			}
+			sampled++
+			measured += n
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to scan the keys of %s: %w", fqn, err)
+		}
+		if sampled > 0 {
+			total += measured * count / sampled
+		}
+	}
+	return total, nil
+}
@@ -22,7 +22,6 @@ import (
 	"fmt"
 	"github.com/go-redis/redis/v8"
 	"github.com/raptor-ml/raptor/api"
-	"math"
 	"strconv"
 	"strings"
 	"sync"
@@ -32,16 +31,27 @@ import (
 const MaxScanCount = 1000
 
 func windowKey(FQN string, bucketName string, encodedKeys string) string {
-	return fmt.Sprintf("%s/%s:%s", FQN, bucketName, encodedKeys)
+	return fmt.Sprintf("%s/%s:%s", FQN, bucketName, entityTag(encodedKeys))
+}
+
+// sketchKey is the key of the HyperLogLog sketch of a window bucket, which is used to calculate distinct counts.
+func sketchKey(FQN string, bucketName string, encodedKeys string) string {
+	return "hll:" + windowKey(FQN, bucketName, encodedKeys)
+}
+
+// quantileSketchKey is the key of the quantile sketch (see api.QuantileSketch) of a window bucket, which is used to
+// calculate percentiles.
+func quantileSketchKey(FQN string, bucketName string, encodedKeys string) string {
+	return "dd:" + windowKey(FQN, bucketName, encodedKeys)
 }
 func fromWindowKey(k string) (fqn string, bucketName string, encodedKeys string) {
 	firstSep := strings.Index(k, "/")
 	lastColon := strings.LastIndex(k, ":")
-	return k[:firstSep], k[firstSep+1 : lastColon], k[lastColon+1:]
+	return k[:firstSep], k[firstSep+1 : lastColon], entityUntag(k[lastColon+1:])
 }
 
 func (s *state) DeadWindowBuckets(ctx context.Context, fd api.FeatureDescriptor, ignore api.RawBuckets) (api.RawBuckets, error) {
-	bucketNames := api.DeadWindowBuckets(fd.Staleness, fd.Freshness)
+	bucketNames := fd.DeadWindowBuckets()
 
 	wg := &sync.WaitGroup{}
 	wg.Add(len(bucketNames))
@@ -54,12 +64,12 @@ func (s *state) DeadWindowBuckets(ctx context.Context, fd api.FeatureDescriptor,
 		go func(bucketName string, wg *sync.WaitGroup, cRes chan string, cErr chan error) {
 			defer wg.Done()
 
-			itr := s.client.ScanType(ctx, 0, windowKey(fd.FQN, bucketName, "*"), MaxScanCount, "hash").Iterator()
-			for itr.Next(ctx) {
-				cRes <- itr.Val()
-			}
-			if itr.Err() != nil {
-				cErr <- itr.Err()
+			err := s.scan(ctx, windowKey(fd.FQN, bucketName, "*"), "hash", func(key string) error {
+				cRes <- key
+				return nil
+			})
+			if err != nil {
+				cErr <- err
 			}
 		}(bucketName, wg, cRes, cErr)
 	}
@@ -92,7 +102,7 @@ loop:
 			buckets = append(buckets, b)
 		}
 	}
-	return s.windowBuckets(ctx, buckets)
+	return s.windowBuckets(ctx, fd.Aggr, buckets)
 }
 
 func ignoreKey(ignore api.RawBuckets, key string) bool {
@@ -104,7 +114,9 @@ func ignoreKey(ignore api.RawBuckets, key string) bool {
 	return false
 }
 
-func (s *state) windowBuckets(ctx context.Context, buckets []api.RawBucket) (api.RawBuckets, error) {
+func (s *state) windowBuckets(ctx context.Context, fns []api.AggrFn, buckets []api.RawBucket) (api.RawBuckets, error) {
+	percentiles := api.HasPercentiles(fns)
+
 	wg := &sync.WaitGroup{}
 	wg.Add(len(buckets))
 
@@ -114,12 +126,31 @@ func (s *state) windowBuckets(ctx context.Context, buckets []api.RawBucket) (api
 		go func(c chan api.RawBucket, wg *sync.WaitGroup, b api.RawBucket) {
 			defer wg.Done()
 
-			res, err := s.client.HGetAll(ctx, windowKey(b.FQN, b.Bucket, b.EncodedKeys)).Result()
+			pipe := s.client.Pipeline()
+			hCmd := pipe.HGetAll(ctx, windowKey(b.FQN, b.Bucket, b.EncodedKeys))
+			pfCmd := pipe.PFCount(ctx, sketchKey(b.FQN, b.Bucket, b.EncodedKeys))
+			var qCmd *redis.StringStringMapCmd
+			if percentiles {
+				qCmd = pipe.HGetAll(ctx, quantileSketchKey(b.FQN, b.Bucket, b.EncodedKeys))
+			}
+			_, _ = pipe.Exec(ctx)
+
+			res, err := hCmd.Result()
 			if err != nil && !errors.Is(err, redis.Nil) {
 				cErr <- err
 				return
 			}
-			if errors.Is(err, redis.Nil) || len(res) == 0 {
+			distinct, _ := pfCmd.Result()
+			qs := make(api.QuantileSketch)
+			if qCmd != nil {
+				if raw, err := qCmd.Result(); err == nil {
+					if err := qs.MergeRaw(raw); err != nil {
+						cErr <- err
+						return
+					}
+				}
+			}
+			if (errors.Is(err, redis.Nil) || len(res) == 0) && distinct == 0 && len(qs) == 0 {
 				return
 			}
 
@@ -131,6 +162,15 @@ func (s *state) windowBuckets(ctx context.Context, buckets []api.RawBucket) (api
 				}
 				rm[api.StringToAggrFn(k)] = vv
 			}
+			if distinct > 0 {
+				rm[api.AggrFnCountDistinct] = float64(distinct)
+			}
+			if len(qs) > 0 {
+				if err := setPercentiles(rm, fns, qs); err != nil {
+					cErr <- err
+					return
+				}
+			}
 			c <- api.RawBucket{
 				FQN:         b.FQN,
 				Bucket:      b.Bucket,
@@ -172,7 +212,7 @@ func (s *state) WindowBuckets(ctx context.Context, fd api.FeatureDescriptor, key
 			EncodedKeys: encodedKeys,
 		})
 	}
-	buckets, err = s.windowBuckets(ctx, buckets)
+	buckets, err = s.windowBuckets(ctx, fd.Aggr, buckets)
 	if err != nil {
 		return nil, err
 	}
@@ -180,39 +220,58 @@ func (s *state) WindowBuckets(ctx context.Context, fd api.FeatureDescriptor, key
 	return buckets, nil
 }
 
-func (s *state) getWindow(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys) (*api.Value, error) {
-	buckets, err := s.WindowBuckets(ctx, fd, keys, api.AliveWindowBuckets(fd.Staleness, fd.Freshness))
+func (s *state) getWindow(ctx context.Context, c redis.UniversalClient, fd api.FeatureDescriptor, keys api.Keys) (*api.Value, error) {
+	encodedKeys, err := keys.Encode(fd)
 	if err != nil {
 		return nil, err
 	}
+	bucketNames := fd.AliveWindowBuckets()
+	bucketKeys := make([]string, len(bucketNames))
+	for i, b := range bucketNames {
+		bucketKeys[i] = windowKey(fd.FQN, b, encodedKeys)
+	}
 
-	var avg bool
-	ret := make(api.WindowResultMap)
-	for _, b := range buckets {
-		for _, fn := range fd.Aggr {
-			switch fn {
-			case api.AggrFnCount, api.AggrFnSum:
-				ret[fn] += b.Data[fn]
-			case api.AggrFnMin:
-				if _, ok := ret[fn]; !ok {
-					ret[fn] = b.Data[fn]
-				} else {
-					ret[fn] = math.Min(ret[fn], b.Data[fn])
-				}
-			case api.AggrFnMax:
-				if _, ok := ret[fn]; !ok {
-					ret[fn] = b.Data[fn]
-				} else {
-					ret[fn] = math.Max(ret[fn], b.Data[fn])
-				}
-			case api.AggrFnAvg:
-				avg = true
-			}
+	ret, err := s.mergeBuckets(ctx, c, fd, bucketKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasAggrFn(fd.Aggr, api.AggrFnCountDistinct) {
+		// the distinct count of the window is the cardinality of the union of the buckets' sketches
+		sketches := make([]string, len(bucketNames))
+		for i, b := range bucketNames {
+			sketches[i] = sketchKey(fd.FQN, b, encodedKeys)
+		}
+		n, err := c.PFCount(ctx, sketches...).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to count distinct values: %w", err)
+		}
+		if n > 0 {
+			ret[api.AggrFnCountDistinct] = float64(n)
 		}
 	}
-	// Should be implicitly by the end
-	if avg {
-		ret[api.AggrFnAvg] = ret[api.AggrFnSum] / ret[api.AggrFnCount]
+
+	if api.HasPercentiles(fd.Aggr) {
+		// the percentiles of the window are calculated from the merge of the buckets' quantile sketches
+		pipe := c.Pipeline()
+		cmds := make([]*redis.StringStringMapCmd, len(bucketNames))
+		for i, b := range bucketNames {
+			cmds[i] = pipe.HGetAll(ctx, quantileSketchKey(fd.FQN, b, encodedKeys))
+		}
+		if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("failed to get the quantile sketches: %w", err)
+		}
+		qs := make(api.QuantileSketch)
+		for _, cmd := range cmds {
+			if err := qs.MergeRaw(cmd.Val()); err != nil {
+				return nil, err
+			}
+		}
+		if len(qs) > 0 {
+			if err := setPercentiles(ret, fd.Aggr, qs); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if len(ret) == 0 {
@@ -226,40 +285,159 @@ func (s *state) getWindow(ctx context.Context, fd api.FeatureDescriptor, keys ap
 	}, nil
 }
 
+// setPercentiles sets the percentile aggregations of a window result from its quantile sketch.
+func setPercentiles(rm api.WindowResultMap, fns []api.AggrFn, qs api.QuantileSketch) error {
+	for _, fn := range fns {
+		q, ok := fn.Quantile()
+		if !ok {
+			continue
+		}
+		v, err := qs.Quantile(q)
+		if err != nil {
+			return fmt.Errorf("failed to calculate %s: %w", fn, err)
+		}
+		rm[fn] = v
+	}
+	return nil
+}
+
+// mergeBuckets merges the window buckets on the server (see luaHMerge), rather than fetching each of them.
+func (s *state) mergeBuckets(ctx context.Context, c redis.UniversalClient, fd api.FeatureDescriptor, bucketKeys []string) (api.WindowResultMap, error) {
+	var fields []any
+	for _, fn := range fd.BucketFields() {
+		fields = append(fields, fn.String())
+	}
+
+	ret := make(api.WindowResultMap)
+	if len(fields) == 0 || len(bucketKeys) == 0 {
+		return ret, nil
+	}
+	res, err := luaHMerge.Run(ctx, c, bucketKeys, fields...).StringSlice()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to merge window buckets: %w", err)
+	}
+	for i := 0; i+1 < len(res); i += 2 {
+		v, err := strconv.ParseFloat(res[i+1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse merged window: %w", err)
+		}
+		ret[api.StringToAggrFn(res[i])] = v
+	}
+	if hasAggrFn(fd.Aggr, api.AggrFnAvg) && ret[api.AggrFnCount] != 0 {
+		ret[api.AggrFnAvg] = ret[api.AggrFnSum] / ret[api.AggrFnCount]
+	}
+	return ret, nil
+}
+
 func (s *state) WindowAdd(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, value any, ts time.Time) error {
-	bucket := api.BucketName(ts, fd.Freshness)
-	key, err := primitiveKey(fd, keys, 0)
+	if fd.TopKWindow() {
+		if err := s.touchEntityNow(ctx, fd, keys); err != nil {
+			return err
+		}
+		return s.topKAdd(ctx, fd, keys, value, ts)
+	}
+	val, err := windowValue(value)
 	if err != nil {
 		return err
 	}
+	if fd.SessionWindow() || fd.DecayWindow() {
+		if err := s.touchEntityNow(ctx, fd, keys); err != nil {
+			return err
+		}
+		if fd.SessionWindow() {
+			return s.sessionAdd(ctx, fd, keys, val, ts)
+		}
+		return s.decayAdd(ctx, fd, keys, val, ts)
+	}
+
+	tx := s.client.TxPipeline()
+	if err := s.bucketAdd(ctx, tx, fd, keys, val, ts); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx)
+	return err
+}
 
-	var val float64
+// windowValue returns the numeric value that is added to a window.
+func windowValue(value any) (float64, error) {
 	switch v := value.(type) {
 	case int:
-		val = float64(v)
+		return float64(v), nil
 	case float64:
-		val = v
+		return v, nil
 	default:
-		return fmt.Errorf("unsupported value type %T", value)
+		return 0, fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// bucketAdd queues the addition of the value to its bucket of a bucketed window.
+func (s *state) bucketAdd(ctx context.Context, tx redis.Pipeliner, fd api.FeatureDescriptor, keys api.Keys, val float64, ts time.Time) error {
+	bucket := api.BucketName(ts, fd.Freshness)
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return fmt.Errorf("failed to encode keys: %w", err)
 	}
+	key := windowKey(fd.FQN, bucket, encodedKeys)
 
-	tx := s.client.TxPipeline()
-	for _, fn := range fd.Aggr {
+	if err := touchEntity(ctx, tx, fd, keys); err != nil {
+		return err
+	}
+	if api.HasPercentiles(fd.Aggr) {
+		qk := quantileSketchKey(fd.FQN, bucket, encodedKeys)
+		tx.HIncrBy(ctx, qk, api.QuantileSketchBin(val), 1)
+		tx.PExpireAt(ctx, qk, fd.BucketDeadTime(bucket))
+	}
+	for _, fn := range fd.BucketFields() {
 		switch fn {
 		case api.AggrFnSum:
 			tx.HIncrByFloat(ctx, key, "sum", val)
 		case api.AggrFnCount:
 			tx.HIncrBy(ctx, key, "count", 1)
 		case api.AggrFnMin:
-			luaHMin.Run(ctx, tx, []string{key, "min"}, val)
+			luaHMin.Run(ctx, tx, []string{key}, "min", val)
 		case api.AggrFnMax:
-			luaHMax.Run(ctx, tx, []string{key, "max"}, val)
+			luaHMax.Run(ctx, tx, []string{key}, "max", val)
 		}
 	}
-	exp := api.BucketDeadTime(bucket, fd.Freshness, fd.Staleness)
+	if hasAggrFn(fd.Aggr, api.AggrFnCountDistinct) {
+		sk := sketchKey(fd.FQN, bucket, encodedKeys)
+		tx.PFAdd(ctx, sk, strconv.FormatFloat(val, 'g', -1, 64))
+		tx.PExpireAt(ctx, sk, fd.BucketDeadTime(bucket))
+	}
+	exp := fd.BucketDeadTime(bucket)
 	setTimestampExpireAt(ctx, tx, key, ts, exp)
 	tx.PExpireAt(ctx, key, exp)
+	return nil
+}
 
-	_, err = tx.Exec(ctx)
-	return err
+func hasAggrFn(fns []api.AggrFn, fn api.AggrFn) bool {
+	for _, f := range fns {
+		if f == fn {
+			return true
+		}
+	}
+	return false
+}
+
+// eventKey is the key of the mark of an event that was written to the entity of the encoded keys.
+func eventKey(fqn string, encodedKeys string, eventID string) string {
+	return fmt.Sprintf("dedup:%s:%s:%s", fqn, entityTag(encodedKeys), eventID)
+}
+
+// MarkEvent implements api.Deduplicator
+func (s *state) MarkEvent(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, eventID string, ttl time.Duration) (bool, error) {
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode keys: %w", err)
+	}
+	return s.client.SetNX(ctx, eventKey(fd.FQN, encodedKeys, eventID), 1, ttl).Result()
+}
+
+// UnmarkEvent implements api.Deduplicator
+func (s *state) UnmarkEvent(ctx context.Context, fd api.FeatureDescriptor, keys api.Keys, eventID string) error {
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return fmt.Errorf("failed to encode keys: %w", err)
+	}
+	return s.client.Del(ctx, eventKey(fd.FQN, encodedKeys, eventID)).Err()
 }
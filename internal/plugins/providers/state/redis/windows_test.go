@@ -0,0 +1,48 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"testing"
+
+	"github.com/raptor-ml/raptor/api"
+)
+
+func TestEventKey(t *testing.T) {
+	fd := api.FeatureDescriptor{FQN: "balance.default", Keys: []string{"account_id"}}
+	key := func(fd api.FeatureDescriptor, id, eventID string) string {
+		keys := api.Keys{"account_id": id}
+		encodedKeys, err := keys.Encode(fd)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return eventKey(fd.FQN, encodedKeys, eventID)
+	}
+
+	// the debit and the credit of a transfer share the event ID, but they're written to different accounts
+	debit, credit := key(fd, "1", "transfer-42"), key(fd, "2", "transfer-42")
+	if debit == credit {
+		t.Errorf("the writes of two entities share the event key %q", debit)
+	}
+	if replay := key(fd, "1", "transfer-42"); replay != debit {
+		t.Errorf("a replay of the event got the key %q, want %q", replay, debit)
+	}
+	other := api.FeatureDescriptor{FQN: "transfers.default", Keys: fd.Keys}
+	if key(other, "1", "transfer-42") == debit {
+		t.Errorf("the writes of two features share the event key %q", debit)
+	}
+}
@@ -17,19 +17,45 @@ limitations under the License.
 package plugins
 
 import (
+	_ "github.com/raptor-ml/raptor/internal/plugins/builders/aggregation"
+	_ "github.com/raptor-ml/raptor/internal/plugins/builders/batch"
+	_ "github.com/raptor-ml/raptor/internal/plugins/builders/cel"
 	_ "github.com/raptor-ml/raptor/internal/plugins/builders/model"
+	_ "github.com/raptor-ml/raptor/internal/plugins/builders/mqtt"
+	_ "github.com/raptor-ml/raptor/internal/plugins/builders/ondemand"
+	_ "github.com/raptor-ml/raptor/internal/plugins/builders/postgres"
+	_ "github.com/raptor-ml/raptor/internal/plugins/builders/remote"
 	_ "github.com/raptor-ml/raptor/internal/plugins/builders/rest"
+	_ "github.com/raptor-ml/raptor/internal/plugins/builders/snowflake"
 	// register all builder plugins
 	_ "github.com/raptor-ml/raptor/internal/plugins/builders/sourceless"
+	_ "github.com/raptor-ml/raptor/internal/plugins/builders/sql"
 	_ "github.com/raptor-ml/raptor/internal/plugins/builders/streaming"
+	_ "github.com/raptor-ml/raptor/internal/plugins/builders/wasm"
 
 	// register all model server plugins
 	_ "github.com/raptor-ml/raptor/internal/plugins/modelservers/sagemaker-ack"
 
+	// register all audit plugins
+	_ "github.com/raptor-ml/raptor/internal/plugins/providers/audit/logfile"
+	_ "github.com/raptor-ml/raptor/internal/plugins/providers/audit/s3"
+
+	// register all dead-letter queue plugins
+	_ "github.com/raptor-ml/raptor/internal/plugins/providers/deadletters/s3"
+
+	// register all key manager plugins
+	_ "github.com/raptor-ml/raptor/internal/plugins/providers/keys/local"
+	_ "github.com/raptor-ml/raptor/internal/plugins/providers/keys/vault"
+
 	// register all historical provider plugins
 	_ "github.com/raptor-ml/raptor/internal/plugins/providers/historical/parquet/s3"
 	_ "github.com/raptor-ml/raptor/internal/plugins/providers/historical/snowflake"
 
+	// register all notifier plugins
+	_ "github.com/raptor-ml/raptor/internal/plugins/providers/notifiers/kafka"
+	_ "github.com/raptor-ml/raptor/internal/plugins/providers/notifiers/nats"
+	_ "github.com/raptor-ml/raptor/internal/plugins/providers/notifiers/sqs"
+
 	// register all state provider plugins
 	_ "github.com/raptor-ml/raptor/internal/plugins/providers/state/redis"
 )
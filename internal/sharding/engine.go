@@ -0,0 +1,175 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/internal/access"
+	"github.com/raptor-ml/raptor/internal/stats"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+	"time"
+)
+
+// forwardedMetadataKey marks the requests that were forwarded by another member, so they're served by the receiver
+// even when the members don't agree on the owner of the entity (i.e. while one of them joins).
+const forwardedMetadataKey = "x-raptor-shard-forwarded"
+
+// credentialsMetadataKeys are the metadata keys of the caller's credentials. They're forwarded to the owner, which
+// authenticates and authorizes the original caller.
+var credentialsMetadataKeys = []string{"authorization", "x-api-key"}
+
+type shardedEngine struct {
+	api.Engine
+	sharder     *Sharder
+	descriptors api.FeatureDescriptorGetter
+}
+
+// Engine wraps the engine, so the requests of entities that are owned by another member are forwarded to it. It should
+// wrap the authorization and the privacy of the accessor, since they're applied by the owner. The feature descriptors
+// are used to find the entities of the requests, so they shouldn't be authorized.
+func (s *Sharder) Engine(e api.Engine, descriptors api.FeatureDescriptorGetter) api.Engine {
+	return &shardedEngine{Engine: e, sharder: s, descriptors: descriptors}
+}
+
+// route returns the engine of the member that owns the entity of the request, and the context to call it with. It
+// returns false when the request should be served locally.
+func (e *shardedEngine) route(ctx context.Context, selector string, keys api.Keys) (api.Engine, context.Context, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(md.Get(forwardedMetadataKey)) > 0 {
+		return nil, nil, false
+	}
+	// trusted callers (i.e. the sidecars over the unix socket) have no credentials to forward
+	if p, ok := access.PrincipalFromContext(ctx); ok && p.Trusted {
+		return nil, nil, false
+	}
+
+	entity, err := e.entity(ctx, selector, keys)
+	if err != nil {
+		return nil, nil, false
+	}
+	m, ok := e.sharder.owner(entity)
+	if !ok {
+		return nil, nil, false
+	}
+	peer, err := e.sharder.peerEngine(m)
+	if err != nil {
+		e.sharder.logger.Error(err, "failed to connect to the shard member", "member", m.ID)
+		return nil, nil, false
+	}
+
+	pairs := []string{forwardedMetadataKey, e.sharder.cfg.Identity}
+	for _, k := range credentialsMetadataKeys {
+		for _, v := range md.Get(k) {
+			pairs = append(pairs, k, v)
+		}
+	}
+	return peer, metadata.AppendToOutgoingContext(ctx, pairs...), true
+}
+
+// entity returns the entity of the request. The entity is identified by its keys, so all the features of an entity
+// are owned by the same member.
+func (e *shardedEngine) entity(ctx context.Context, selector string, keys api.Keys) (string, error) {
+	fd, err := e.descriptors(ctx, selector)
+	if err != nil {
+		return "", err
+	}
+	encodedKeys, err := keys.Encode(fd)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(fd.Keys, ",") + "=" + encodedKeys, nil
+}
+
+// do calls fn with the engine of the entity's owner, and falls back to the local engine when the owner is unavailable.
+func (e *shardedEngine) do(ctx context.Context, selector string, keys api.Keys, fn func(context.Context, api.Engine) error) error {
+	peer, fctx, ok := e.route(ctx, selector, keys)
+	if !ok {
+		return fn(ctx, e.Engine)
+	}
+	err := fn(fctx, peer)
+	if status.Code(err) != codes.Unavailable {
+		stats.IncrShardForwards("ok")
+		return err
+	}
+	stats.IncrShardForwards("fallback")
+	e.sharder.logger.V(1).Info("the shard member is unavailable, serving locally", "error", err.Error())
+	return fn(ctx, e.Engine)
+}
+
+func (e *shardedEngine) Get(ctx context.Context, selector string, keys api.Keys) (api.Value, api.FeatureDescriptor, error) {
+	var val api.Value
+	var fd api.FeatureDescriptor
+	err := e.do(ctx, selector, keys, func(ctx context.Context, eng api.Engine) error {
+		var err error
+		val, fd, err = eng.Get(ctx, selector, keys)
+		return err
+	})
+	return val, fd, err
+}
+func (e *shardedEngine) Set(ctx context.Context, fqn string, keys api.Keys, val any, ts time.Time) error {
+	return e.do(ctx, fqn, keys, func(ctx context.Context, eng api.Engine) error {
+		return eng.Set(ctx, fqn, keys, val, ts)
+	})
+}
+func (e *shardedEngine) Append(ctx context.Context, fqn string, keys api.Keys, val any, ts time.Time) error {
+	return e.do(ctx, fqn, keys, func(ctx context.Context, eng api.Engine) error {
+		return eng.Append(ctx, fqn, keys, val, ts)
+	})
+}
+func (e *shardedEngine) Incr(ctx context.Context, fqn string, keys api.Keys, by any, ts time.Time) error {
+	return e.do(ctx, fqn, keys, func(ctx context.Context, eng api.Engine) error {
+		return eng.Incr(ctx, fqn, keys, by, ts)
+	})
+}
+func (e *shardedEngine) Update(ctx context.Context, fqn string, keys api.Keys, val any, ts time.Time) error {
+	return e.do(ctx, fqn, keys, func(ctx context.Context, eng api.Engine) error {
+		return eng.Update(ctx, fqn, keys, val, ts)
+	})
+}
+
+// WriteTransaction implements api.Transactor, when the wrapped engine does. The writes of a transaction must be of the
+// same entity, so the transaction is routed to the owner of the entity.
+func (e *shardedEngine) WriteTransaction(ctx context.Context, writes []api.FeatureWrite) error {
+	if len(writes) == 0 {
+		return nil
+	}
+	first, err := e.entity(ctx, writes[0].FQN, writes[0].Keys)
+	if err != nil {
+		return err
+	}
+	for _, w := range writes[1:] {
+		entity, err := e.entity(ctx, w.FQN, w.Keys)
+		if err != nil {
+			return err
+		}
+		if entity != first {
+			return fmt.Errorf("%w: %s is written for another entity", api.ErrTransactionSpansEntities, w.FQN)
+		}
+	}
+	return e.do(ctx, writes[0].FQN, writes[0].Keys, func(ctx context.Context, eng api.Engine) error {
+		tx, ok := eng.(api.Transactor)
+		if !ok {
+			return api.ErrTransactionsUnsupported
+		}
+		return tx.WriteTransaction(ctx, writes)
+	})
+}
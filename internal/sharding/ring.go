@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// DefaultVirtualNodes is the default number of points of each member on the ring.
+const DefaultVirtualNodes = 128
+
+// Member is a Core replica that owns a share of the entities.
+type Member struct {
+	// ID is the identity of the replica (i.e. its pod name).
+	ID string
+	// Addr is the address of the replica's gRPC accessor.
+	Addr string
+}
+
+type point struct {
+	hash   uint64
+	member int
+}
+
+// Ring is a consistent-hashing ring of the members. Each member is placed on the ring multiple times (virtual nodes),
+// so the entities are spread evenly, and only the entities of a member that joins or leaves are moved.
+type Ring struct {
+	members []Member
+	points  []point
+}
+
+// NewRing creates a ring of the members, with vnodes points per member.
+func NewRing(members []Member, vnodes int) *Ring {
+	if vnodes <= 0 {
+		vnodes = DefaultVirtualNodes
+	}
+	ms := make([]Member, len(members))
+	copy(ms, members)
+	sort.Slice(ms, func(i, j int) bool { return ms[i].ID < ms[j].ID })
+
+	r := &Ring{members: ms, points: make([]point, 0, len(ms)*vnodes)}
+	for i, m := range ms {
+		for v := 0; v < vnodes; v++ {
+			r.points = append(r.points, point{hash: hash(m.ID + "#" + strconv.Itoa(v)), member: i})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i].hash < r.points[j].hash })
+	return r
+}
+
+// Owner returns the member that owns the key: the first member on the ring after the key's hash.
+func (r *Ring) Owner(key string) (Member, bool) {
+	if r == nil || len(r.points) == 0 {
+		return Member{}, false
+	}
+	h := hash(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.members[r.points[i].member], true
+}
+
+// Members returns the members of the ring, sorted by their ID.
+func (r *Ring) Members() []Member {
+	if r == nil {
+		return nil
+	}
+	return r.members
+}
+
+// equal checks if the rings have the same members.
+func (r *Ring) equal(other *Ring) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(r.members) != len(other.members) {
+		return false
+	}
+	for i := range r.members {
+		if r.members[i] != other.members[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	// fnv spreads similar strings poorly, so the hash is finalized by the mixer of splitmix64
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
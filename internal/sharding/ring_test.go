@@ -0,0 +1,79 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"fmt"
+	"testing"
+)
+
+func members(n int) []Member {
+	ms := make([]Member, n)
+	for i := range ms {
+		ms[i] = Member{ID: fmt.Sprintf("core-%d", i), Addr: fmt.Sprintf("10.0.0.%d:60000", i)}
+	}
+	return ms
+}
+
+func TestRing_Balance(t *testing.T) {
+	r := NewRing(members(4), DefaultVirtualNodes)
+	owned := make(map[string]int)
+	const entities = 100_000
+	for i := 0; i < entities; i++ {
+		m, ok := r.Owner(fmt.Sprintf("user_id=%d", i))
+		if !ok {
+			t.Fatal("expected an owner")
+		}
+		owned[m.ID]++
+	}
+	for id, n := range owned {
+		if share := float64(n) / entities; share < 0.15 || share > 0.35 {
+			t.Errorf("unbalanced share of %s: %.2f", id, share)
+		}
+	}
+}
+
+func TestRing_MinimalMovement(t *testing.T) {
+	before := NewRing(members(4), DefaultVirtualNodes)
+	after := NewRing(members(5), DefaultVirtualNodes)
+	moved := 0
+	const entities = 10_000
+	for i := 0; i < entities; i++ {
+		key := fmt.Sprintf("user_id=%d", i)
+		b, _ := before.Owner(key)
+		a, _ := after.Owner(key)
+		if a != b {
+			if a.ID != "core-4" {
+				t.Fatalf("%s moved from %s to %s rather than to the new member", key, b.ID, a.ID)
+			}
+			moved++
+		}
+	}
+	if share := float64(moved) / entities; share > 0.3 {
+		t.Errorf("too many entities moved: %.2f", share)
+	}
+}
+
+func TestRing_Empty(t *testing.T) {
+	if _, ok := NewRing(nil, 0).Owner("user_id=1"); ok {
+		t.Error("expected no owner of an empty ring")
+	}
+	var r *Ring
+	if _, ok := r.Owner("user_id=1"); ok {
+		t.Error("expected no owner of a nil ring")
+	}
+}
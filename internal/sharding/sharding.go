@@ -0,0 +1,285 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sharding spreads the entities across the Core replicas, so each entity is served by a single replica and
+// the in-process work (the read cache, the write batches and the async writes) isn't duplicated by every replica.
+//
+// Each replica claims its membership by renewing a Kubernetes Lease in the Core's namespace, and the entities are
+// assigned to the live members by consistent hashing. The requests of an entity that is owned by another replica are
+// forwarded to it by the accessor (see Sharder.Engine). The state is shared by all the replicas, so a request is
+// served locally when its owner is unavailable, or while the replicas don't agree on the members yet.
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/raptor-ml/raptor/api"
+	coreApi "github.com/raptor-ml/raptor/api/proto/gen/go/core/v1alpha1"
+	"github.com/raptor-ml/raptor/internal/stats"
+	"github.com/raptor-ml/raptor/pkg/sdk"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
+
+const (
+	// GroupLabel labels the Leases of the members of a shard group.
+	GroupLabel = "raptor.ml/shard-group"
+	// addressAnnotation holds the address of the member's gRPC accessor on its Lease.
+	addressAnnotation = "raptor.ml/shard-address"
+
+	// DefaultLeaseDuration is the default time a member is considered alive after it renewed its Lease.
+	DefaultLeaseDuration = 15 * time.Second
+)
+
+// Config is the configuration of the sharding.
+type Config struct {
+	// Namespace is the namespace of the Leases (the Core's namespace).
+	Namespace string
+	// Group is the name of the shard group. The replicas of the same Core deployment share the group.
+	Group string
+	// Identity is the identity of this replica (i.e. its pod name).
+	Identity string
+	// Address is the address of this replica's gRPC accessor, which the other members forward the requests to.
+	Address string
+	// Credentials are the transport credentials of the connections to the other members. The connections are insecure
+	// when nil.
+	Credentials credentials.TransportCredentials
+	// Authority is the server name of the other members' certificates, since they're reached by their pod address.
+	Authority string
+	// LeaseDuration is the time a member is considered alive after it renewed its Lease. The Leases are renewed every
+	// third of it.
+	LeaseDuration time.Duration
+	// VirtualNodes is the number of points of each member on the ring.
+	VirtualNodes int
+}
+
+type peer struct {
+	conn   *grpc.ClientConn
+	engine api.Engine
+}
+
+// Sharder maintains the membership of this replica in the shard group, and the ring of the live members.
+type Sharder struct {
+	cfg    Config
+	client client.Client
+	reader client.Reader
+	logger logr.Logger
+
+	ring atomic.Pointer[Ring]
+
+	mu    sync.Mutex
+	peers map[string]*peer
+}
+
+// New creates a new Sharder. The client is used to write the Lease of this replica, and the reader to list the Leases
+// of the group (so the Leases of the cluster aren't cached).
+func New(cfg Config, c client.Client, r client.Reader, logger logr.Logger) *Sharder {
+	if cfg.LeaseDuration <= 0 {
+		cfg.LeaseDuration = DefaultLeaseDuration
+	}
+	if cfg.Credentials == nil {
+		cfg.Credentials = insecure.NewCredentials()
+	}
+	return &Sharder{
+		cfg:    cfg,
+		client: c,
+		reader: r,
+		logger: logger,
+		peers:  make(map[string]*peer),
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every replica is a member.
+func (s *Sharder) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable. It renews the Lease of this replica and refreshes the ring until the context is
+// done, and then removes the Lease, so the entities of this replica are moved to the other members right away.
+func (s *Sharder) Start(ctx context.Context) error {
+	s.logger.WithValues("group", s.cfg.Group, "identity", s.cfg.Identity, "address", s.cfg.Address).
+		Info("Joining the shard group")
+
+	ticker := time.NewTicker(s.cfg.LeaseDuration / 3)
+	defer ticker.Stop()
+	for {
+		if err := s.sync(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error(err, "failed to sync the shard group")
+		}
+		select {
+		case <-ctx.Done():
+			s.leave()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Sharder) leaseName() string {
+	return fmt.Sprintf("raptor-shard-%s-%s", s.cfg.Group, s.cfg.Identity)
+}
+
+// sync renews the Lease of this replica, and rebuilds the ring of the members whose Leases didn't expire.
+func (s *Sharder) sync(ctx context.Context) error {
+	if err := s.renew(ctx); err != nil {
+		return fmt.Errorf("failed to renew the shard lease: %w", err)
+	}
+
+	leases := &coordinationv1.LeaseList{}
+	err := s.reader.List(ctx, leases, client.InNamespace(s.cfg.Namespace), client.MatchingLabels{GroupLabel: s.cfg.Group})
+	if err != nil {
+		return fmt.Errorf("failed to list the shard leases: %w", err)
+	}
+	now := time.Now()
+	var members []Member
+	for _, l := range leases.Items {
+		spec := l.Spec
+		if spec.HolderIdentity == nil || spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+			continue
+		}
+		if now.After(spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second)) {
+			continue
+		}
+		if addr := l.Annotations[addressAnnotation]; addr != "" {
+			members = append(members, Member{ID: *spec.HolderIdentity, Addr: addr})
+		}
+	}
+
+	ring := NewRing(members, s.cfg.VirtualNodes)
+	if ring.equal(s.ring.Load()) {
+		return nil
+	}
+	s.ring.Store(ring)
+	stats.SetShardMembers(len(members))
+	s.logger.Info("The shard group has changed", "members", len(members))
+	s.closeStalePeers(members)
+	return nil
+}
+
+func (s *Sharder) renew(ctx context.Context) error {
+	now := metav1.NewMicroTime(time.Now())
+	seconds := int32(s.cfg.LeaseDuration / time.Second)
+	identity := s.cfg.Identity
+
+	lease := &coordinationv1.Lease{}
+	err := s.reader.Get(ctx, client.ObjectKey{Namespace: s.cfg.Namespace, Name: s.leaseName()}, lease)
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        s.leaseName(),
+				Namespace:   s.cfg.Namespace,
+				Labels:      map[string]string{GroupLabel: s.cfg.Group},
+				Annotations: map[string]string{addressAnnotation: s.cfg.Address},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &identity,
+				LeaseDurationSeconds: &seconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		return s.client.Create(ctx, lease)
+	}
+	if err != nil {
+		return err
+	}
+
+	if lease.Labels == nil {
+		lease.Labels = map[string]string{}
+	}
+	lease.Labels[GroupLabel] = s.cfg.Group
+	if lease.Annotations == nil {
+		lease.Annotations = map[string]string{}
+	}
+	lease.Annotations[addressAnnotation] = s.cfg.Address
+	lease.Spec.HolderIdentity = &identity
+	lease.Spec.LeaseDurationSeconds = &seconds
+	lease.Spec.RenewTime = &now
+	return s.client.Update(ctx, lease)
+}
+
+// leave removes the Lease of this replica, and closes the connections to the other members.
+func (s *Sharder) leave() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	lease := &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: s.leaseName(), Namespace: s.cfg.Namespace}}
+	if err := s.client.Delete(ctx, lease); err != nil && !apierrors.IsNotFound(err) {
+		s.logger.Error(err, "failed to remove the shard lease")
+	}
+	s.ring.Store(nil)
+	s.closeStalePeers(nil)
+}
+
+// owner returns the member that owns the entity, unless it's this replica (or there are no members).
+func (s *Sharder) owner(entity string) (Member, bool) {
+	m, ok := s.ring.Load().Owner(entity)
+	if !ok || m.ID == s.cfg.Identity {
+		return Member{}, false
+	}
+	return m, true
+}
+
+// peerEngine returns an engine that forwards the requests to the member.
+func (s *Sharder) peerEngine(m Member) (api.Engine, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.peers[m.Addr]; ok {
+		return p.engine, nil
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(s.cfg.Credentials),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	}
+	if s.cfg.Authority != "" {
+		opts = append(opts, grpc.WithAuthority(s.cfg.Authority))
+	}
+	cc, err := grpc.Dial(m.Addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the shard member %s: %w", m.ID, err)
+	}
+	p := &peer{conn: cc, engine: sdk.NewGRPCEngine(coreApi.NewEngineServiceClient(cc))}
+	s.peers[m.Addr] = p
+	return p.engine, nil
+}
+
+// closeStalePeers closes the connections to the members that left the group.
+func (s *Sharder) closeStalePeers(members []Member) {
+	live := make(map[string]bool, len(members))
+	for _, m := range members {
+		live[m.Addr] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for addr, p := range s.peers {
+		if !live[addr] {
+			_ = p.conn.Close()
+			delete(s.peers, addr)
+		}
+	}
+}
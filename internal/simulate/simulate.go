@@ -0,0 +1,269 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulate computes features from sample (or recorded) events the way the runners of their DataSources would,
+// without persisting anything. It's used to debug builders, and to validate a modified builder against the events that
+// were recorded before it's deployed.
+//
+// The reads of the builders are served by the engine, and their writes are recorded. Python programs are loaded to the
+// runtime under a different name, so the program of the bound feature isn't replaced, and are executed in dry-run
+// mode.
+package simulate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-logr/logr/funcr"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/runner"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"time"
+)
+
+// Event is an event to compute the feature from.
+type Event struct {
+	// Row is the event, as it's consumed from the DataSource.
+	Row map[string]any `json:"event"`
+	// Timestamp is the timestamp of the event.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Operation is an operation on the state that the builder would have made.
+type Operation struct {
+	Op        string    `json:"op"`
+	FQN       string    `json:"fqn"`
+	Keys      api.Keys  `json:"keys,omitempty"`
+	Value     any       `json:"value,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Result is the computation of a single event.
+type Result struct {
+	FQN string `json:"fqn"`
+	// Value is the computed value, if the builder produced one.
+	Value     any        `json:"value,omitempty"`
+	Keys      api.Keys   `json:"keys,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	// Error is the error of the computation, if it failed.
+	Error string `json:"error,omitempty"`
+	// Operations are the state operations of the builder, in order. Reads are served by the engine, and writes are
+	// discarded.
+	Operations []Operation `json:"operations"`
+	// Logs are the (JSON) log lines of the computation, including its errors.
+	Logs []json.RawMessage `json:"logs"`
+}
+
+// Options of a simulation.
+type Options struct {
+	// Suffix is appended to the name of the feature when its program is loaded to the runtime. It should be unique per
+	// use case, so concurrent simulations of different use cases don't replace each other's programs.
+	Suffix string
+	// Mapped indicates that the events were already mapped by the DataSource (i.e. they were recorded by the dead-letter
+	// queue), so the DataSource's mapping isn't applied again.
+	Mapped bool
+}
+
+// Simulator computes features without persisting anything.
+type Simulator struct {
+	// Engine serves the reads of the builders, and executes their programs if it implements api.RuntimeManager.
+	Engine api.Engine
+	// Client reads the DataSources of the features, to apply their mapping. It can be nil when the events are mapped.
+	Client client.Reader
+}
+
+// Run computes the feature from each of the events, in order.
+func (s *Simulator) Run(ctx context.Context, ft *manifests.Feature, events []Event, opts Options) ([]Result, error) {
+	fd, err := api.FeatureDescriptorFromManifest(ft)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feature: %w", err)
+	}
+
+	sim := &simulation{engine: s.Engine, fd: *fd}
+	if ft.Spec.Builder.HasProgram() && fd.Builder != api.ModelBuilder && fd.Builder != api.RemoteBuilder {
+		if err := sim.loadProgram(ft, opts.Suffix); err != nil {
+			return nil, err
+		}
+	}
+
+	// the programs are executed in dry-run mode, and their values are written to the simulation by the executor
+	exec := &runner.Executor{
+		Client:         s.Client,
+		Engine:         sim,
+		Runtime:        sim,
+		Logger:         funcr.NewJSON(sim.log, funcr.Options{}),
+		HistoricalOnly: true,
+	}
+	if ref := ft.Spec.DataSource; ref != nil && !opts.Mapped {
+		exec.DataSource = ref.ObjectKey()
+	}
+	snapshot, err := exec.FeatureSnapshot(ctx, ft)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Result, len(events))
+	for i, ev := range events {
+		sim.res = &ret[i]
+		*sim.res = Result{FQN: fd.FQN, Operations: []Operation{}, Logs: []json.RawMessage{}}
+		exec.Execute(ctx, snapshot, ev.Row, ev.Timestamp)
+
+		for j := len(sim.res.Operations) - 1; j >= 0; j-- {
+			op := sim.res.Operations[j]
+			if op.FQN == fd.FQN && op.Op != "get" && op.Error == "" {
+				sim.res.Value, sim.res.Keys, sim.res.Timestamp = op.Value, op.Keys, &op.Timestamp
+				break
+			}
+		}
+	}
+	return ret, nil
+}
+
+// DecodeFeature decodes a single Feature manifest (YAML or JSON).
+func DecodeFeature(manifest string) (*manifests.Feature, error) {
+	m := map[string]any{}
+	if err := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096).Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to decode the manifest: %w", err)
+	}
+	u := &unstructured.Unstructured{Object: m}
+	if gvk := u.GroupVersionKind(); gvk.GroupKind() != manifests.GroupVersion.WithKind("Feature").GroupKind() {
+		return nil, fmt.Errorf("the manifest should be a Feature, got `%s`", gvk.GroupKind())
+	}
+	ft := &manifests.Feature{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, ft); err != nil {
+		return nil, fmt.Errorf("failed to decode the feature: %w", err)
+	}
+	return ft, nil
+}
+
+// simulation is the engine and the runtime of a simulated feature. Reads are served by the engine, and writes are
+// recorded to the result of the current event rather than persisted.
+type simulation struct {
+	engine     api.Engine
+	runtime    api.RuntimeManager
+	fd         api.FeatureDescriptor
+	programFQN string
+	res        *Result
+}
+
+func (s *simulation) loadProgram(ft *manifests.Feature, suffix string) error {
+	rm, ok := s.engine.(api.RuntimeManager)
+	if !ok {
+		return fmt.Errorf("the engine doesn't support executing programs")
+	}
+	ns, name, _, _, _, err := api.ParseSelector(s.fd.FQN)
+	if err != nil {
+		return err
+	}
+	if s.programFQN, err = api.NormalizeFQN(name+suffix, ns); err != nil {
+		return err
+	}
+	prog, err := rm.LoadProgram(s.fd.RuntimeEnv, s.programFQN, ft.Spec.Builder.Code, ft.Spec.Builder.Packages)
+	if err != nil {
+		return fmt.Errorf("failed to load the program: %w", err)
+	}
+	if prog.Primitive != s.fd.Primitive {
+		return fmt.Errorf("python primitive(%s) does not match declared primitive(%s)", prog.Primitive, s.fd.Primitive)
+	}
+	s.runtime = rm
+	return nil
+}
+
+func (s *simulation) log(obj string) {
+	s.res.Logs = append(s.res.Logs, json.RawMessage(obj))
+}
+
+func (s *simulation) record(op, fqn string, keys api.Keys, val any, ts time.Time, err error) {
+	rec := Operation{Op: op, FQN: fqn, Keys: keys, Value: val, Timestamp: ts}
+	if wr, ok := val.(api.WindowResultMap); ok {
+		rec.Value = wr.Readable()
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	s.res.Operations = append(s.res.Operations, rec)
+}
+
+func (s *simulation) FeatureDescriptor(ctx context.Context, selector string) (api.FeatureDescriptor, error) {
+	if fqn, err := api.NormalizeFQN(selector, ""); err == nil && fqn == s.fd.FQN {
+		return s.fd, nil
+	}
+	return s.engine.FeatureDescriptor(ctx, selector)
+}
+
+func (s *simulation) Get(ctx context.Context, selector string, keys api.Keys) (api.Value, api.FeatureDescriptor, error) {
+	val, fd, err := s.engine.Get(ctx, selector, keys)
+	s.record("get", selector, keys, val.Value, val.Timestamp, err)
+	return val, fd, err
+}
+
+func (s *simulation) Set(_ context.Context, fqn string, keys api.Keys, val any, ts time.Time) error {
+	s.record("set", fqn, keys, val, ts, nil)
+	return nil
+}
+
+func (s *simulation) Append(_ context.Context, fqn string, keys api.Keys, val any, ts time.Time) error {
+	s.record("append", fqn, keys, val, ts, nil)
+	return nil
+}
+
+func (s *simulation) Incr(_ context.Context, fqn string, keys api.Keys, by any, ts time.Time) error {
+	s.record("incr", fqn, keys, by, ts, nil)
+	return nil
+}
+
+func (s *simulation) Update(_ context.Context, fqn string, keys api.Keys, val any, ts time.Time) error {
+	s.record("update", fqn, keys, val, ts, nil)
+	return nil
+}
+
+// SendDeadLetter implements api.DeadLetterQueue, so the failures of the computation are reported by the result.
+func (s *simulation) SendDeadLetter(_ context.Context, dl api.DeadLetter) error {
+	if s.res.Error == "" {
+		s.res.Error = dl.Error
+	}
+	return nil
+}
+
+func (s *simulation) LoadProgram(env, fqn, program string, packages []string) (*api.ParsedProgram, error) {
+	return nil, fmt.Errorf("programs can't be loaded by a simulation")
+}
+
+// ExecuteProgram executes the simulated program in dry-run mode.
+func (s *simulation) ExecuteProgram(ctx context.Context, env string, fqn string, keys api.Keys, row map[string]any, ts time.Time, _ bool) (api.Value, api.Keys, error) {
+	if s.runtime == nil || fqn != s.fd.FQN {
+		return api.Value{}, nil, fmt.Errorf("the program of %s isn't loaded", fqn)
+	}
+	return s.runtime.ExecuteProgram(ctx, env, s.programFQN, keys, row, ts, true)
+}
+
+func (s *simulation) GetSidecars() []corev1.Container {
+	return nil
+}
+
+func (s *simulation) GetDefaultEnv() string {
+	if s.runtime == nil {
+		return ""
+	}
+	return s.runtime.GetDefaultEnv()
+}
@@ -0,0 +1,166 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// CacheResult is the result of a lookup of a feature-value in the state.
+type CacheResult string
+
+const (
+	CacheHit     CacheResult = "hit"
+	CacheMiss    CacheResult = "miss"
+	CacheExpired CacheResult = "expired"
+)
+
+// ReadCacheResult is the result of a lookup of a feature-value in the engine's read-through cache.
+type ReadCacheResult string
+
+const (
+	ReadCacheHit  ReadCacheResult = "hit"
+	ReadCacheMiss ReadCacheResult = "miss"
+	// ReadCacheShared is a miss that was served by a concurrent read of the same value from the state.
+	ReadCacheShared ReadCacheResult = "shared"
+	// ReadCacheNegativeHit is a hit of a value that is known to be missing from the state.
+	ReadCacheNegativeHit ReadCacheResult = "negative_hit"
+)
+
+var latencyBuckets = prometheus.ExponentialBuckets(0.0005, 2, 14) // 0.5ms - 4s
+
+var (
+	featureRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_request_duration_seconds",
+		Help:      "The latency of the requests of the feature, by method.",
+		Buckets:   latencyBuckets,
+	}, []string{"feature", "method"})
+	featureRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_request_errors_total",
+		Help:      "Number of failed requests of the feature, by method.",
+	}, []string{"feature", "method"})
+	featureCacheResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_cache_results_total",
+		Help:      "Number of lookups of the feature's values in the state, by result (hit, miss or expired).",
+	}, []string{"feature", "result"})
+	featureReadCacheResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_read_cache_results_total",
+		Help:      "Number of lookups of the feature's values in the engine's read-through cache, by result (hit, miss or shared).",
+	}, []string{"feature", "result"})
+	featureWriteBatchSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_write_batch_size",
+		Help:      "The number of entities in the flushed write batches of the feature.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12), // 1 - 2048
+	}, []string{"feature"})
+	featureCoalescedWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_coalesced_writes_total",
+		Help:      "Number of writes of the feature that were coalesced into a pending write of the same entity.",
+	}, []string{"feature"})
+	featureBuilderDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_builder_duration_seconds",
+		Help:      "The execution time of the feature's builder (i.e. its program).",
+		Buckets:   latencyBuckets,
+	}, []string{"feature", "builder"})
+	featureBuilderErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_builder_errors_total",
+		Help:      "Number of failed executions of the feature's builder.",
+	}, []string{"feature", "builder"})
+)
+
+func init() {
+	Registry.MustRegister(
+		featureRequestDuration,
+		featureRequestErrors,
+		featureCacheResults,
+		featureReadCacheResults,
+		featureWriteBatchSize,
+		featureCoalescedWrites,
+		featureBuilderDuration,
+		featureBuilderErrors,
+	)
+}
+
+// ObserveFeatureRequest records the latency of a request of the feature, and counts it if it failed.
+func ObserveFeatureRequest(ctx context.Context, fqn, method string, d time.Duration, err error) {
+	observe(ctx, featureRequestDuration.WithLabelValues(fqn, method), d)
+	if err != nil {
+		featureRequestErrors.WithLabelValues(fqn, method).Inc()
+	}
+}
+
+// ObserveBuilderExecution records the execution time of the feature's builder, and counts it if it failed.
+func ObserveBuilderExecution(ctx context.Context, fqn, builder string, d time.Duration, err error) {
+	observe(ctx, featureBuilderDuration.WithLabelValues(fqn, builder), d)
+	if err != nil {
+		featureBuilderErrors.WithLabelValues(fqn, builder).Inc()
+	}
+}
+
+// IncrCacheResult counts a lookup of the feature's value in the state.
+func IncrCacheResult(fqn string, result CacheResult) {
+	featureCacheResults.WithLabelValues(fqn, string(result)).Inc()
+}
+
+// IncrReadCacheResult counts a lookup of the feature's value in the engine's read-through cache.
+func IncrReadCacheResult(fqn string, result ReadCacheResult) {
+	featureReadCacheResults.WithLabelValues(fqn, string(result)).Inc()
+}
+
+// ObserveWriteBatch records the number of entities in a flushed write batch of the feature.
+func ObserveWriteBatch(fqn string, size int) {
+	featureWriteBatchSize.WithLabelValues(fqn).Observe(float64(size))
+}
+
+// IncrCoalescedWrites counts a write of the feature that was coalesced into a pending write of the same entity.
+func IncrCoalescedWrites(fqn string) {
+	featureCoalescedWrites.WithLabelValues(fqn).Inc()
+}
+
+// DeleteFeatureRequestStats removes the request metrics of a removed feature.
+func DeleteFeatureRequestStats(fqn string) {
+	l := prometheus.Labels{"feature": fqn}
+	featureRequestDuration.DeletePartialMatch(l)
+	featureRequestErrors.DeletePartialMatch(l)
+	featureCacheResults.DeletePartialMatch(l)
+	featureReadCacheResults.DeletePartialMatch(l)
+	featureWriteBatchSize.DeletePartialMatch(l)
+	featureCoalescedWrites.DeletePartialMatch(l)
+	featureBuilderDuration.DeletePartialMatch(l)
+	featureBuilderErrors.DeletePartialMatch(l)
+}
+
+// observe records the duration, with the trace of the request (if sampled) as an exemplar.
+func observe(ctx context.Context, o prometheus.Observer, d time.Duration) {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsSampled() {
+		if eo, ok := o.(prometheus.ExemplarObserver); ok {
+			eo.ObserveWithExemplar(d.Seconds(), prometheus.Labels{"trace_id": sc.TraceID().String()})
+			return
+		}
+	}
+	o.Observe(d.Seconds())
+}
@@ -18,8 +18,20 @@ package stats
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"time"
 )
 
+// Registry is the registry of the engine's metrics. It's served apart from the controller metrics (see Handler), so
+// the per-feature metrics are never included in the usage reports.
+var Registry = prometheus.NewRegistry()
+
+// Handler serves the engine's metrics. The OpenMetrics format is negotiated, so the exemplars are exposed.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
+
 var (
 	numOfFeatures = prometheus.NewGauge(prometheus.GaugeOpts{
 		Subsystem: coreSubsystemKey,
@@ -56,10 +68,95 @@ var (
 		Name:      "number_of_fd_reqs",
 		Help:      "Number of FeatureDescriptor requests.",
 	})
+	lateEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "number_of_late_events",
+		Help:      "Number of events of windowed features that were dropped since they arrived after the allowed lateness.",
+	}, []string{"feature"})
+	fallbacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "number_of_fallbacks",
+		Help:      "Number of gets that returned the fallback of the feature, since there was no fresh value.",
+	}, []string{"feature"})
+	validationViolations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "number_of_validation_violations",
+		Help:      "Number of written values that violated a validation rule of the feature, by the action that was taken.",
+	}, []string{"feature", "rule", "action"})
+	droppedAuditRecords = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "number_of_dropped_audit_records",
+		Help:      "Number of audit records that were dropped, since the audit sink failed or couldn't keep up.",
+	})
+	duplicateEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "number_of_duplicate_events",
+		Help:      "Number of writes that were dropped since their idempotency key was already written.",
+	}, []string{"feature"})
+	deadLetters = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "number_of_dead_letters",
+		Help:      "Number of events that failed to be computed, and were sent to the dead-letter queue.",
+	}, []string{"feature"})
+	walPendingWrites = prometheus.NewGauge(prometheus.GaugeOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "wal_pending_writes",
+		Help:      "Number of async writes in the write-ahead log that were not applied to the state yet.",
+	})
+	shardMembers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "shard_members",
+		Help:      "Number of Core replicas that the entities are sharded across, as seen by this instance.",
+	})
+	shardForwards = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "shard_forwarded_requests_total",
+		Help:      "Number of requests that were forwarded to the replica that owns their entity, by their result.",
+	}, []string{"result"})
+	featureLastUpdate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_last_update_timestamp_seconds",
+		Help:      "The last time the feature was updated by this instance, in seconds since the epoch.",
+	}, []string{"feature"})
+	featureStaleness = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_staleness_seconds",
+		Help:      "The time since the feature was last updated (by any instance), in seconds.",
+	}, []string{"feature"})
+	featureStale = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_stale",
+		Help:      "Whether the feature wasn't updated within its freshness SLO (1) or it was (0).",
+	}, []string{"feature"})
+	featureValueMean = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_value_mean",
+		Help:      "The mean of the sampled numeric values of the feature in the current monitoring window.",
+	}, []string{"feature"})
+	featureValueStdDev = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_value_stddev",
+		Help:      "The standard deviation of the sampled numeric values of the feature in the current monitoring window.",
+	}, []string{"feature"})
+	featureValueQuantile = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_value_quantile",
+		Help:      "The estimated quantiles of the sampled numeric values of the feature in the current monitoring window.",
+	}, []string{"feature", "quantile"})
+	featureValueNullRatio = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_value_null_ratio",
+		Help:      "The ratio of null values among the sampled values of the feature in the current monitoring window.",
+	}, []string{"feature"})
+	featureValueDrift = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "feature_value_drift",
+		Help:      "The Population Stability Index (PSI) of the feature's values in the current monitoring window relative to the previous one.",
+	}, []string{"feature"})
 )
 
 func init() {
-	prometheus.MustRegister(
+	Registry.MustRegister(
 		numOfFeatures,
 		featureGets,
 		featureSets,
@@ -67,6 +164,23 @@ func init() {
 		featureAppends,
 		featureIncrements,
 		fdReqs,
+		lateEvents,
+		validationViolations,
+		fallbacks,
+		droppedAuditRecords,
+		duplicateEvents,
+		deadLetters,
+		walPendingWrites,
+		shardMembers,
+		shardForwards,
+		featureLastUpdate,
+		featureStaleness,
+		featureStale,
+		featureValueMean,
+		featureValueStdDev,
+		featureValueQuantile,
+		featureValueNullRatio,
+		featureValueDrift,
 	)
 }
 
@@ -109,3 +223,92 @@ func IncrFeatureIncrements() {
 func IncrFeatureDescriptorReqs() {
 	fdReqs.Inc()
 }
+
+// IncrLateEvents increments the number of late events that were dropped for the feature.
+func IncrLateEvents(fqn string) {
+	lateEvents.WithLabelValues(fqn).Inc()
+}
+
+// IncrFallbacks increments the number of gets that returned the fallback of the feature.
+func IncrFallbacks(fqn string) {
+	fallbacks.WithLabelValues(fqn).Inc()
+}
+
+// IncrDroppedAuditRecords increments the number of audit records that were dropped.
+func IncrDroppedAuditRecords(n int) {
+	droppedAuditRecords.Add(float64(n))
+}
+
+// IncrValidationViolations increments the number of values that violated the validation rule of the feature.
+func IncrValidationViolations(fqn, rule, action string) {
+	validationViolations.WithLabelValues(fqn, rule, action).Inc()
+}
+
+// IncrDuplicateEvents increments the number of duplicate writes that were dropped for the feature.
+func IncrDuplicateEvents(fqn string) {
+	duplicateEvents.WithLabelValues(fqn).Inc()
+}
+
+// IncrDeadLetters increments the number of events of the feature that failed to be computed.
+func IncrDeadLetters(fqn string) {
+	deadLetters.WithLabelValues(fqn).Inc()
+}
+
+// SetWALPendingWrites records the number of async writes that were not applied to the state yet.
+func SetWALPendingWrites(n int) {
+	walPendingWrites.Set(float64(n))
+}
+
+// SetShardMembers records the number of replicas that the entities are sharded across.
+func SetShardMembers(n int) {
+	shardMembers.Set(float64(n))
+}
+
+// IncrShardForwards records a request that was forwarded to the replica that owns its entity. The result is `ok`,
+// or `fallback` when the owner was unavailable and the request was served locally.
+func IncrShardForwards(result string) {
+	shardForwards.WithLabelValues(result).Inc()
+}
+
+// SetFeatureLastUpdate records the last time the feature was updated by this instance.
+func SetFeatureLastUpdate(fqn string, ts time.Time) {
+	featureLastUpdate.WithLabelValues(fqn).Set(float64(ts.Unix()))
+}
+
+// SetFeatureStaleness records the time since the feature was last updated, and whether it violates its freshness SLO.
+func SetFeatureStaleness(fqn string, staleness time.Duration, stale bool) {
+	featureStaleness.WithLabelValues(fqn).Set(staleness.Seconds())
+	v := 0.0
+	if stale {
+		v = 1
+	}
+	featureStale.WithLabelValues(fqn).Set(v)
+}
+
+// DeleteFeatureStaleness removes the staleness metrics of a removed feature.
+func DeleteFeatureStaleness(fqn string) {
+	featureStaleness.DeleteLabelValues(fqn)
+	featureStale.DeleteLabelValues(fqn)
+}
+
+// SetValueStats records the distribution of the sampled values of the feature. The drift is recorded only if known.
+func SetValueStats(fqn string, mean, stddev, nullRatio float64, quantiles map[string]float64, drift *float64) {
+	featureValueMean.WithLabelValues(fqn).Set(mean)
+	featureValueStdDev.WithLabelValues(fqn).Set(stddev)
+	featureValueNullRatio.WithLabelValues(fqn).Set(nullRatio)
+	for q, v := range quantiles {
+		featureValueQuantile.WithLabelValues(fqn, q).Set(v)
+	}
+	if drift != nil {
+		featureValueDrift.WithLabelValues(fqn).Set(*drift)
+	}
+}
+
+// DeleteValueStats removes the value distribution metrics of a removed feature.
+func DeleteValueStats(fqn string) {
+	featureValueMean.DeleteLabelValues(fqn)
+	featureValueStdDev.DeleteLabelValues(fqn)
+	featureValueNullRatio.DeleteLabelValues(fqn)
+	featureValueDrift.DeleteLabelValues(fqn)
+	featureValueQuantile.DeletePartialMatch(prometheus.Labels{"feature": fqn})
+}
@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Quota is a resource that is limited by the quota of a tenant.
+type Quota string
+
+const (
+	QuotaFeatures    Quota = "features"
+	QuotaStateMemory Quota = "state_memory"
+)
+
+var (
+	tenantFeatures = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "tenant_features",
+		Help:      "Number of features in the namespaces of the tenant.",
+	}, []string{"tenant"})
+	tenantStateMemory = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "tenant_state_memory_bytes",
+		Help:      "The estimated memory the values of the tenant's features take in the state store, in bytes.",
+	}, []string{"tenant"})
+	tenantQuota = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "tenant_quota",
+		Help:      "The quota of the tenant, by resource (features, or state_memory in bytes). Unlimited resources are omitted.",
+	}, []string{"tenant", "quota"})
+	tenantQuotaRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: coreSubsystemKey,
+		Name:      "tenant_quota_rejections_total",
+		Help:      "Number of feature creations and writes that were rejected since the tenant exceeded its quota, by resource.",
+	}, []string{"tenant", "quota"})
+)
+
+func init() {
+	Registry.MustRegister(
+		tenantFeatures,
+		tenantStateMemory,
+		tenantQuota,
+		tenantQuotaRejections,
+	)
+}
+
+// SetTenantUsage records the usage of the tenant, and its quota. Zero quotas are unlimited.
+func SetTenantUsage(tenant string, features, stateMemory int64, maxFeatures, maxStateMemory int64) {
+	tenantFeatures.WithLabelValues(tenant).Set(float64(features))
+	tenantStateMemory.WithLabelValues(tenant).Set(float64(stateMemory))
+	for q, v := range map[Quota]int64{QuotaFeatures: maxFeatures, QuotaStateMemory: maxStateMemory} {
+		if v > 0 {
+			tenantQuota.WithLabelValues(tenant, string(q)).Set(float64(v))
+		} else {
+			tenantQuota.DeleteLabelValues(tenant, string(q))
+		}
+	}
+}
+
+// DeleteTenantUsage removes the metrics of a removed tenant.
+func DeleteTenantUsage(tenant string) {
+	tenantFeatures.DeleteLabelValues(tenant)
+	tenantStateMemory.DeleteLabelValues(tenant)
+	tenantQuota.DeletePartialMatch(prometheus.Labels{"tenant": tenant})
+	tenantQuotaRejections.DeletePartialMatch(prometheus.Labels{"tenant": tenant})
+}
+
+// IncrTenantQuotaRejections increments the number of rejections since the tenant exceeded its quota of the resource.
+func IncrTenantQuotaRejections(tenant string, quota Quota) {
+	tenantQuotaRejections.WithLabelValues(tenant, string(quota)).Inc()
+}
@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tenancy enforces the isolation of the namespaces of tenants (see manifests.Tenant): the DataSources and
+// Features of a tenant can be used only by the namespaces of the tenant, unless they are exported.
+package tenancy
+
+// +kubebuilder:rbac:groups=k8s.raptor.ml,resources=tenants,verbs=get;list;watch
+
+import (
+	"context"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrNotExported is returned when a resource of a tenant is used by a namespace of another tenant, but it isn't
+// exported by its tenant.
+var ErrNotExported = fmt.Errorf("the resource isn't exported by its tenant")
+
+func list(ctx context.Context, c client.Reader) ([]manifests.Tenant, error) {
+	tenants := manifests.TenantList{}
+	if err := c.List(ctx, &tenants); err != nil {
+		if meta.IsNoMatchError(err) {
+			// the Tenant CRD isn't installed, so there are no tenants
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	return tenants.Items, nil
+}
+
+// Of returns the tenant of the namespace, or nil if the namespace doesn't belong to a tenant.
+func Of(ctx context.Context, c client.Reader, namespace string) (*manifests.Tenant, error) {
+	tenants, err := list(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tenants {
+		if tenants[i].HasNamespace(namespace) {
+			return &tenants[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// CheckAccess checks if the resource (a DataSource or a Feature) can be used by the namespace.
+func CheckAccess(ctx context.Context, c client.Reader, namespace string, ref manifests.ResourceReference) error {
+	if ref.Namespace == "" || ref.Namespace == namespace {
+		return nil
+	}
+	owner, err := Of(ctx, c, ref.Namespace)
+	if err != nil || owner == nil {
+		return err
+	}
+	if owner.HasNamespace(namespace) || owner.Exported(ref.Namespace, ref.Name) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s/%s belongs to tenant %s, and can't be used by namespace %s", ErrNotExported,
+		ref.Namespace, ref.Name, owner.GetName(), namespace)
+}
+
+// CheckFeatureAccess checks if the feature with the given FQN can be used by the namespace. The namespace of the FQN
+// is resolved to the namespace of a tenant by the naming scheme.
+func CheckFeatureAccess(ctx context.Context, c client.Reader, namespace, fqn string) error {
+	ns, name, _, _, _, err := api.ParseSelector(fqn)
+	if err != nil || ns == "" {
+		return err
+	}
+	tenants, err := list(ctx, c)
+	if err != nil {
+		return err
+	}
+	for _, t := range tenants {
+		for _, tns := range t.Spec.Namespaces {
+			if fqnNamespace(tns) == ns {
+				return CheckAccess(ctx, c, namespace, manifests.ResourceReference{Namespace: tns, Name: name})
+			}
+		}
+	}
+	return nil
+}
+
+// fqnNamespace returns the namespace of the FQNs of the features of the Kubernetes namespace.
+func fqnNamespace(namespace string) string {
+	ns, _, _, _, _, err := api.ParseSelector(manifests.FQNFormatter(namespace, "x"))
+	if err != nil {
+		return namespace
+	}
+	return ns
+}
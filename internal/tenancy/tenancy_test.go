@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newClient(t *testing.T, tenants ...*manifests.Tenant) client.Reader {
+	scheme := runtime.NewScheme()
+	if err := manifests.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build the scheme: %v", err)
+	}
+	objs := make([]client.Object, len(tenants))
+	for i, t := range tenants {
+		objs[i] = t
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func tenant(name string, namespaces, exports []string) *manifests.Tenant {
+	return &manifests.Tenant{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       manifests.TenantSpec{Namespaces: namespaces, Exports: exports},
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	c := newClient(t,
+		tenant("fraud", []string{"fraud", "fraud-staging"}, []string{"fraud/risk_score", "fraud-staging/shared_*"}),
+		tenant("ads", []string{"ads"}, nil),
+	)
+	tests := []struct {
+		namespace string
+		ref       manifests.ResourceReference
+		allowed   bool
+	}{
+		{namespace: "ads", ref: manifests.ResourceReference{Name: "clicks"}, allowed: true},
+		{namespace: "ads", ref: manifests.ResourceReference{Namespace: "ads", Name: "clicks"}, allowed: true},
+		{namespace: "fraud-staging", ref: manifests.ResourceReference{Namespace: "fraud", Name: "payments"}, allowed: true},
+		{namespace: "ads", ref: manifests.ResourceReference{Namespace: "fraud", Name: "risk_score"}, allowed: true},
+		{namespace: "ads", ref: manifests.ResourceReference{Namespace: "fraud-staging", Name: "shared_src"}, allowed: true},
+		{namespace: "ads", ref: manifests.ResourceReference{Namespace: "default", Name: "clicks"}, allowed: true},
+		{namespace: "default", ref: manifests.ResourceReference{Namespace: "fraud", Name: "risk_score"}, allowed: true},
+		{namespace: "ads", ref: manifests.ResourceReference{Namespace: "fraud", Name: "payments"}, allowed: false},
+		{namespace: "default", ref: manifests.ResourceReference{Namespace: "ads", Name: "clicks"}, allowed: false},
+		{namespace: "fraud", ref: manifests.ResourceReference{Namespace: "ads", Name: "clicks"}, allowed: false},
+	}
+	for _, tt := range tests {
+		err := CheckAccess(context.Background(), c, tt.namespace, tt.ref)
+		if tt.allowed && err != nil {
+			t.Errorf("%s -> %s/%s: expected to be allowed, got %v", tt.namespace, tt.ref.Namespace, tt.ref.Name, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrNotExported) {
+			t.Errorf("%s -> %s/%s: expected to be denied, got %v", tt.namespace, tt.ref.Namespace, tt.ref.Name, err)
+		}
+	}
+}
+
+func TestCheckFeatureAccess(t *testing.T) {
+	c := newClient(t, tenant("fraud", []string{"fraud-staging"}, []string{"fraud-staging/risk_score"}))
+
+	if err := CheckFeatureAccess(context.Background(), c, "ads", "fraud_staging.risk_score+sum"); err != nil {
+		t.Errorf("expected an exported feature to be allowed, got %v", err)
+	}
+	if err := CheckFeatureAccess(context.Background(), c, "ads", "fraud_staging.payments"); !errors.Is(err, ErrNotExported) {
+		t.Errorf("expected a feature of another tenant to be denied, got %v", err)
+	}
+	if err := CheckFeatureAccess(context.Background(), c, "fraud-staging", "fraud_staging.payments"); err != nil {
+		t.Errorf("expected a feature of the same tenant to be allowed, got %v", err)
+	}
+	if err := CheckFeatureAccess(context.Background(), c, "ads", "payments"); err != nil {
+		t.Errorf("expected an unqualified feature to be allowed, got %v", err)
+	}
+}
@@ -0,0 +1,304 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wal is a local write-ahead log of the writes of feature-values that are applied to the state
+// asynchronously. The writes are appended to segment files as JSON lines, and the sequence of the last write that was
+// applied is kept in a checkpoint file, so the writes that were not applied yet are recovered on startup.
+package wal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// segmentSize is the size of a segment file, after which a new segment is started.
+const segmentSize = 64 << 20
+
+const (
+	segmentExt     = ".wal"
+	checkpointFile = "checkpoint"
+)
+
+// Entry is a logged write of a feature-value.
+type Entry struct {
+	Seq       uint64          `json:"seq"`
+	FQN       string          `json:"fqn"`
+	Method    api.StateMethod `json:"method"`
+	Keys      api.Keys        `json:"keys"`
+	Value     any             `json:"value"`
+	Timestamp time.Time       `json:"ts"`
+
+	// Recovered is set for the entries that were read from the segment files on startup. Their values are decoded
+	// from JSON, so they should be converted to the feature's primitive (see api.FromJSONValue).
+	Recovered bool `json:"-"`
+	// Attempts is the number of failed attempts to apply the entry.
+	Attempts int `json:"-"`
+	// Applied is set once the entry was applied (or dropped), so it's not applied again until it's committed.
+	Applied bool `json:"-"`
+}
+
+type segment struct {
+	first uint64
+	path  string
+}
+
+// Log is a write-ahead log in a local directory.
+type Log struct {
+	dir   string
+	fsync bool
+
+	mu       sync.Mutex
+	f        *os.File
+	size     int64
+	seq      uint64
+	pending  []*Entry
+	segments []segment
+	notify   chan struct{}
+}
+
+// Open opens the write-ahead log in the directory, and recovers the entries that were not committed. When fsync is
+// set, every entry is synced to the disk before it's acknowledged, so it survives a crash of the node rather than
+// only of the process.
+func Open(dir string, fsync bool) (*Log, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create the WAL directory: %w", err)
+	}
+	l := &Log{dir: dir, fsync: fsync, notify: make(chan struct{}, 1)}
+
+	committed, err := l.readCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+	l.seq = committed
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		first, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(p), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		l.segments = append(l.segments, segment{first: first, path: p})
+	}
+	sort.Slice(l.segments, func(i, j int) bool { return l.segments[i].first < l.segments[j].first })
+	for _, s := range l.segments {
+		if err := l.recover(s.path, committed); err != nil {
+			return nil, err
+		}
+	}
+
+	// a new segment is always started, so entries aren't appended after a torn write of a crash
+	if err := l.rotate(); err != nil {
+		return nil, err
+	}
+	if len(l.pending) > 0 {
+		l.notify <- struct{}{}
+	}
+	return l, nil
+}
+
+func (l *Log) readCheckpoint() (uint64, error) {
+	b, err := os.ReadFile(filepath.Join(l.dir, checkpointFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the WAL checkpoint: %w", err)
+	}
+	seq, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse the WAL checkpoint: %w", err)
+	}
+	return seq, nil
+}
+
+// recover reads the entries of the segment that were not committed. A corrupted line ends the segment, since it's
+// the torn tail of a write that was interrupted by a crash.
+func (l *Log) recover(path string, committed uint64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open the WAL segment: %w", err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), segmentSize)
+	for sc.Scan() {
+		e := &Entry{}
+		if err := json.Unmarshal(sc.Bytes(), e); err != nil {
+			break
+		}
+		if e.Seq > l.seq {
+			l.seq = e.Seq
+		}
+		if e.Seq <= committed {
+			continue
+		}
+		e.Recovered = true
+		l.pending = append(l.pending, e)
+	}
+	return nil
+}
+
+// rotate starts a new segment. It must be called with the lock held.
+func (l *Log) rotate() error {
+	if l.f != nil {
+		if err := l.f.Close(); err != nil {
+			return fmt.Errorf("failed to close the WAL segment: %w", err)
+		}
+	}
+	s := segment{first: l.seq + 1}
+	s.path = filepath.Join(l.dir, fmt.Sprintf("%020d%s", s.first, segmentExt))
+	// an existing segment of the same sequence has no valid entries (i.e. only a torn write), so it's truncated
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to create the WAL segment: %w", err)
+	}
+	l.f, l.size = f, 0
+	if n := len(l.segments); n == 0 || l.segments[n-1].path != s.path {
+		l.segments = append(l.segments, s)
+	}
+	return nil
+}
+
+// Append logs the entry, and returns once it's written (and synced, if fsync is enabled).
+func (l *Log) Append(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Seq = l.seq + 1
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode the WAL entry: %w", err)
+	}
+	n, err := l.f.Write(append(b, '\n'))
+	if err != nil {
+		return l.discard(fmt.Errorf("failed to write the WAL entry: %w", err))
+	}
+	if l.fsync {
+		if err := l.f.Sync(); err != nil {
+			return l.discard(fmt.Errorf("failed to sync the WAL segment: %w", err))
+		}
+	}
+	l.seq = e.Seq
+	l.size += int64(n)
+	l.pending = append(l.pending, &e)
+	if l.size >= segmentSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case l.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// discard removes a failed write (that might be partially written) from the end of the active segment, so the next
+// entries aren't appended after a torn line, which would end the segment when it's recovered. When the segment can't
+// be truncated, a new segment is started instead. It must be called with the lock held, and returns err.
+func (l *Log) discard(err error) error {
+	if terr := l.f.Truncate(l.size); terr == nil {
+		return err
+	}
+	if rerr := l.rotate(); rerr != nil {
+		return fmt.Errorf("%w (and failed to discard it: %v)", err, rerr)
+	}
+	return err
+}
+
+// Notify returns a channel that is signaled when entries are appended.
+func (l *Log) Notify() <-chan struct{} {
+	return l.notify
+}
+
+// Pending returns up to max of the oldest entries that were not committed.
+func (l *Log) Pending(max int) []*Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if max > len(l.pending) {
+		max = len(l.pending)
+	}
+	ret := make([]*Entry, max)
+	copy(ret, l.pending[:max])
+	return ret
+}
+
+// Seq returns the sequence of the last appended entry.
+func (l *Log) Seq() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.seq
+}
+
+// Len returns the number of entries that were not committed.
+func (l *Log) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.pending)
+}
+
+// Commit removes the n oldest entries, after they were applied. The segments whose entries were all committed are
+// removed.
+func (l *Log) Commit(n int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n <= 0 {
+		return nil
+	}
+	if n > len(l.pending) {
+		n = len(l.pending)
+	}
+	last := l.pending[n-1].Seq
+	l.pending = l.pending[n:]
+
+	tmp := filepath.Join(l.dir, checkpointFile+".tmp")
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(last, 10)), 0o640); err != nil {
+		return fmt.Errorf("failed to write the WAL checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(l.dir, checkpointFile)); err != nil {
+		return fmt.Errorf("failed to write the WAL checkpoint: %w", err)
+	}
+
+	// a segment is done once the next one starts after the checkpoint. The active (last) segment is never removed.
+	for len(l.segments) > 1 && l.segments[1].first <= last+1 {
+		if err := os.Remove(l.segments[0].path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove the WAL segment: %w", err)
+		}
+		l.segments = l.segments[1:]
+	}
+	return nil
+}
+
+// Close closes the active segment. The entries that were not committed are recovered when the log is opened again.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/raptor-ml/raptor/api"
+)
+
+func appendEntries(t *testing.T, l *Log, values ...int) {
+	for _, v := range values {
+		err := l.Append(Entry{
+			FQN:       "default.clicks",
+			Method:    api.StateMethodIncr,
+			Keys:      api.Keys{"user_id": "u1"},
+			Value:     v,
+			Timestamp: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("failed to append: %v", err)
+		}
+	}
+}
+
+func TestLog_Recover(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, false)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	appendEntries(t, l, 1, 2, 3)
+	if err := l.Commit(1); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	l, err = Open(dir, false)
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	pending := l.Pending(10)
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 recovered entries, got %d", len(pending))
+	}
+	if pending[0].Seq != 2 || pending[0].Value != float64(2) || !pending[0].Recovered {
+		t.Errorf("unexpected recovered entry: %+v", pending[0])
+	}
+
+	appendEntries(t, l, 4)
+	if seq := l.Seq(); seq != 4 {
+		t.Errorf("expected the sequence to continue from the recovered entries, got %d", seq)
+	}
+	if err := l.Commit(3); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if n := l.Len(); n != 0 {
+		t.Errorf("expected no pending entries, got %d", n)
+	}
+	segments, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if len(segments) != 1 {
+		t.Errorf("expected the committed segments to be removed, got %v", segments)
+	}
+}
+
+func TestLog_TornWrite(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, true)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	appendEntries(t, l, 1)
+	f, err := os.OpenFile(l.segments[len(l.segments)-1].path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("failed to open the segment: %v", err)
+	}
+	if _, err := f.WriteString(`{"seq":2,"fqn":"default.cli`); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	_ = f.Close()
+	_ = l.Close()
+
+	l, err = Open(dir, true)
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	if n := l.Len(); n != 1 {
+		t.Fatalf("expected the torn write to be ignored, got %d entries", n)
+	}
+	appendEntries(t, l, 2)
+	_ = l.Close()
+
+	l, err = Open(dir, true)
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	if n := l.Len(); n != 2 {
+		t.Errorf("expected the entries after the torn write to be recovered, got %d entries", n)
+	}
+}
+
+func TestLog_FailedWrite(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, false)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	appendEntries(t, l, 1)
+	// a write that fails midway leaves a partial line in the active segment
+	if _, err := l.f.WriteString(`{"seq":2,"fqn":"default.cli`); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	l.mu.Lock()
+	_ = l.discard(nil)
+	l.mu.Unlock()
+	appendEntries(t, l, 2, 3)
+	_ = l.Close()
+
+	l, err = Open(dir, false)
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	if n := l.Len(); n != 3 {
+		t.Errorf("expected the entries after the failed write to be recovered, got %d entries", n)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ml.raptor.client;
+
+import io.grpc.CallCredentials;
+import io.grpc.Metadata;
+import io.grpc.Status;
+
+import java.io.IOException;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.Files;
+import java.nio.file.Path;
+import java.util.concurrent.Executor;
+
+/**
+ * BearerToken authenticates the calls with a bearer token. A token that is read from a file (i.e. the token of the
+ * pod's ServiceAccount) is re-read on every call, since it's rotated.
+ */
+final class BearerToken extends CallCredentials {
+    private static final Metadata.Key<String> AUTHORIZATION =
+            Metadata.Key.of("authorization", Metadata.ASCII_STRING_MARSHALLER);
+
+    private final String token;
+    private final Path path;
+
+    private BearerToken(String token, Path path) {
+        this.token = token;
+        this.path = path;
+    }
+
+    static BearerToken of(String token) {
+        return new BearerToken(token, null);
+    }
+
+    static BearerToken fromFile(Path path) {
+        return new BearerToken(null, path);
+    }
+
+    @Override
+    public void applyRequestMetadata(RequestInfo requestInfo, Executor appExecutor, MetadataApplier applier) {
+        String t = token;
+        if (t == null) {
+            if (!Files.exists(path)) {
+                applier.apply(new Metadata());
+                return;
+            }
+            try {
+                t = Files.readString(path, StandardCharsets.UTF_8).trim();
+            } catch (IOException e) {
+                applier.fail(Status.UNAUTHENTICATED.withDescription("failed to read the token").withCause(e));
+                return;
+            }
+        }
+        Metadata md = new Metadata();
+        if (!t.isEmpty()) {
+            md.put(AUTHORIZATION, "Bearer " + t);
+        }
+        applier.apply(md);
+    }
+}
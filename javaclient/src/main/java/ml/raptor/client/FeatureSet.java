@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ml.raptor.client;
+
+import java.util.Collections;
+import java.util.List;
+import java.util.Map;
+import java.util.stream.Collectors;
+
+/**
+ * FeatureSet is the ordered list of features of a Model, that are read together as the model's input vector.
+ */
+public final class FeatureSet {
+    private final String name;
+    private final String namespace;
+    private final String fqn;
+    private final List<String> features;
+    private final List<String> keys;
+
+    /**
+     * @param name      the name of the feature set (i.e. the name of the Model).
+     * @param namespace the namespace of the feature set.
+     * @param features  the selectors of the features (i.e. {@code clicks+count}). Selectors without a namespace are in
+     *                  the namespace of the feature set.
+     * @param keys      the keys of the entities of the feature set.
+     */
+    public FeatureSet(String name, String namespace, List<String> features, List<String> keys) {
+        this.name = name;
+        this.namespace = namespace;
+        this.fqn = fqn(namespace, name);
+        this.features = features.stream()
+                .map(f -> normalizeSelector(f, namespace))
+                .collect(Collectors.toUnmodifiableList());
+        this.keys = List.copyOf(keys);
+    }
+
+    public String getName() {
+        return name;
+    }
+
+    public String getNamespace() {
+        return namespace;
+    }
+
+    public String getFqn() {
+        return fqn;
+    }
+
+    /** Returns the normalized selectors of the features, i.e. {@code default.clicks+count}. */
+    public List<String> getFeatures() {
+        return features;
+    }
+
+    public List<String> getKeys() {
+        return keys;
+    }
+
+    /**
+     * Returns the keys of the entity. An entity ID can be given as a string only if the feature set has a single key.
+     */
+    public Map<String, String> entityKeys(String entityId) {
+        if (keys.size() != 1) {
+            throw new IllegalArgumentException(String.format("%s has %d keys (%s), so the keys of the entity should "
+                    + "be given as a map", fqn, keys.size(), String.join(", ", keys)));
+        }
+        return Collections.singletonMap(keys.get(0), entityId);
+    }
+
+    static String fqn(String namespace, String name) {
+        return (namespace + "." + name).replace('-', '_');
+    }
+
+    /** Returns the selector with the namespace, i.e. {@code default.clicks+count} of {@code clicks+count}. */
+    public static String normalizeSelector(String selector, String namespace) {
+        int i = 0;
+        while (i < selector.length() && "+@[".indexOf(selector.charAt(i)) < 0) {
+            i++;
+        }
+        String base = selector.substring(0, i);
+        if (base.indexOf('.') < 0) {
+            base = fqn(namespace, base);
+        }
+        return base.replace('-', '_') + selector.substring(i);
+    }
+
+    @Override
+    public String toString() {
+        return "FeatureSet(" + fqn + ", features=" + features + ", keys=" + keys + ")";
+    }
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ml.raptor.client;
+
+import java.time.Duration;
+import java.time.Instant;
+import java.util.LinkedHashMap;
+import java.util.Map;
+import java.util.Objects;
+
+/**
+ * An in-process LRU cache of feature values.
+ * <p>
+ * A value is cached only while it's fresh: its TTL is the remaining freshness of the value (the time until it's
+ * {@code freshness} old), bounded by {@code maxTtl}. Values that aren't fresh anymore aren't cached, so they're read
+ * from the Core, which recomputes them if needed.
+ */
+final class FreshnessCache {
+    /** MISSING is cached for values that don't exist, since null marks values that aren't cached. */
+    static final Object MISSING = new Object();
+
+    private final Duration maxTtl;
+    private final LinkedHashMap<Key, Entry> entries;
+
+    FreshnessCache(Duration maxTtl, int maxSize) {
+        this.maxTtl = maxTtl;
+        this.entries = new LinkedHashMap<>(16, 0.75f, true) {
+            @Override
+            protected boolean removeEldestEntry(Map.Entry<Key, Entry> eldest) {
+                return size() > maxSize;
+            }
+        };
+    }
+
+    /** Returns the time a value can be cached for, by its timestamp and the freshness of its feature. */
+    Duration ttl(Instant timestamp, Duration freshness, boolean fresh) {
+        if (!fresh) {
+            return Duration.ZERO;
+        }
+        if (timestamp == null || freshness.isZero() || freshness.isNegative()) {
+            return maxTtl;
+        }
+        Duration remaining = Duration.between(Instant.now(), timestamp.plus(freshness));
+        if (remaining.isNegative()) {
+            return Duration.ZERO;
+        }
+        return remaining.compareTo(maxTtl) < 0 ? remaining : maxTtl;
+    }
+
+    /** Returns the cached value, or {@code null} if it's not cached. Missing values are cached as {@link #MISSING}. */
+    synchronized Object get(String selector, Map<String, String> keys) {
+        Key k = new Key(selector, keys);
+        Entry e = entries.get(k);
+        if (e == null) {
+            return null;
+        }
+        if (e.expires < System.nanoTime()) {
+            entries.remove(k);
+            return null;
+        }
+        return e.value;
+    }
+
+    synchronized void put(String selector, Map<String, String> keys, Object value, Duration ttl) {
+        if (ttl.isZero() || ttl.isNegative()) {
+            return;
+        }
+        Entry e = new Entry(System.nanoTime() + ttl.toNanos(), value == null ? MISSING : value);
+        entries.put(new Key(selector, keys), e);
+    }
+
+    synchronized void clear() {
+        entries.clear();
+    }
+
+    private static final class Key {
+        private final String selector;
+        private final Map<String, String> keys;
+
+        Key(String selector, Map<String, String> keys) {
+            this.selector = selector;
+            this.keys = Map.copyOf(keys);
+        }
+
+        @Override
+        public boolean equals(Object o) {
+            if (!(o instanceof Key)) {
+                return false;
+            }
+            Key other = (Key) o;
+            return selector.equals(other.selector) && keys.equals(other.keys);
+        }
+
+        @Override
+        public int hashCode() {
+            return Objects.hash(selector, keys);
+        }
+    }
+
+    private static final class Entry {
+        private final long expires;
+        private final Object value;
+
+        Entry(long expires, Object value) {
+            this.expires = expires;
+            this.value = value;
+        }
+    }
+}
@@ -0,0 +1,312 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ml.raptor.client;
+
+import com.google.common.util.concurrent.FutureCallback;
+import com.google.common.util.concurrent.Futures;
+import com.google.common.util.concurrent.ListenableFuture;
+import com.google.common.util.concurrent.MoreExecutors;
+import core.v1alpha1.Api;
+import core.v1alpha1.EngineServiceGrpc;
+import io.grpc.ManagedChannel;
+import io.grpc.ManagedChannelBuilder;
+
+import java.nio.file.Path;
+import java.nio.file.Paths;
+import java.time.Duration;
+import java.time.Instant;
+import java.util.ArrayList;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+import java.util.UUID;
+import java.util.concurrent.CompletableFuture;
+import java.util.concurrent.CompletionException;
+import java.util.concurrent.ConcurrentHashMap;
+import java.util.concurrent.TimeUnit;
+
+/**
+ * RaptorClient is a client of the Raptor Core, for reading feature values in production inference services.
+ * <p>
+ * The client is thread-safe, and should be reused for the lifetime of the service. Values are converted by
+ * {@link Values}.
+ *
+ * <pre>{@code
+ * RaptorClient client = RaptorClient.newBuilder().cacheTtl(Duration.ofSeconds(5)).build();
+ * client.registerFeatureSet("fraud-detection", List.of("amount+avg", "amount+max"), List.of("account_id"));
+ * Map<String, Object> vector = client.getVector("fraud-detection", "account-123");
+ * }</pre>
+ */
+public final class RaptorClient implements AutoCloseable {
+    public static final String DEFAULT_ADDRESS = "raptor-core-service.raptor-system:60000";
+    public static final Path SERVICE_ACCOUNT_TOKEN = Paths.get("/var/run/secrets/kubernetes.io/serviceaccount/token");
+
+    private final ManagedChannel channel;
+    private final EngineServiceGrpc.EngineServiceFutureStub stub;
+    private final String namespace;
+    private final Duration timeout;
+    private final FreshnessCache cache;
+    private final Map<String, FeatureSet> featureSets = new ConcurrentHashMap<>();
+
+    private RaptorClient(Builder b) {
+        ManagedChannelBuilder<?> cb = ManagedChannelBuilder.forTarget(b.address);
+        if (b.retries > 0) {
+            cb.defaultServiceConfig(serviceConfig(b.retries)).enableRetry();
+        } else {
+            cb.disableRetry();
+        }
+        if (!b.tls) {
+            cb.usePlaintext();
+        }
+        this.channel = cb.build();
+        BearerToken credentials = b.token != null ? BearerToken.of(b.token) : BearerToken.fromFile(b.tokenPath);
+        this.stub = EngineServiceGrpc.newFutureStub(channel).withCallCredentials(credentials);
+        this.namespace = b.namespace;
+        this.timeout = b.timeout;
+        this.cache = b.cacheTtl.isZero() ? null : new FreshnessCache(b.cacheTtl, b.cacheSize);
+    }
+
+    public static Builder newBuilder() {
+        return new Builder();
+    }
+
+    /** Registers the feature set, so its vectors can be read with {@code getVector}. */
+    public FeatureSet registerFeatureSet(String name, List<String> features, List<String> keys) {
+        return registerFeatureSet(new FeatureSet(name, namespace, features, keys));
+    }
+
+    /** Registers the feature set, so its vectors can be read with {@code getVector}. */
+    public FeatureSet registerFeatureSet(FeatureSet fs) {
+        featureSets.put(fs.getFqn(), fs);
+        featureSets.put(fs.getName(), fs);
+        return fs;
+    }
+
+    /** Returns the value of the feature (i.e. {@code namespace.name+aggr}) of the entity. */
+    public Object get(String selector, Map<String, String> keys) {
+        return join(getAsync(selector, keys));
+    }
+
+    /** Returns the value of the feature (i.e. {@code namespace.name+aggr}) of the entity. */
+    public CompletableFuture<Object> getAsync(String selector, Map<String, String> keys) {
+        return fetch(FeatureSet.normalizeSelector(selector, namespace), keys);
+    }
+
+    /**
+     * Returns the values of the features of the feature set, for the entity, ordered as the features of the feature
+     * set. The feature set must have a single key.
+     */
+    public Map<String, Object> getVector(String featureSet, String entityId) {
+        return join(getVectorAsync(featureSet, entityId));
+    }
+
+    /**
+     * Returns the values of the features of the feature set, for the entity with the given keys, ordered as the
+     * features of the feature set.
+     */
+    public Map<String, Object> getVector(String featureSet, Map<String, String> keys) {
+        return join(getVectorAsync(featureSet, keys));
+    }
+
+    /** The asynchronous counterpart of {@link #getVector(String, String)}. The features are read concurrently. */
+    public CompletableFuture<Map<String, Object>> getVectorAsync(String featureSet, String entityId) {
+        FeatureSet fs = featureSet(featureSet);
+        return getVectorAsync(fs, fs.entityKeys(entityId));
+    }
+
+    /** The asynchronous counterpart of {@link #getVector(String, Map)}. The features are read concurrently. */
+    public CompletableFuture<Map<String, Object>> getVectorAsync(String featureSet, Map<String, String> keys) {
+        return getVectorAsync(featureSet(featureSet), keys);
+    }
+
+    private CompletableFuture<Map<String, Object>> getVectorAsync(FeatureSet fs, Map<String, String> keys) {
+        List<CompletableFuture<Object>> futures = new ArrayList<>(fs.getFeatures().size());
+        for (String selector : fs.getFeatures()) {
+            futures.add(fetch(selector, keys));
+        }
+        return CompletableFuture.allOf(futures.toArray(new CompletableFuture[0])).thenApply(v -> {
+            Map<String, Object> ret = new LinkedHashMap<>();
+            for (int i = 0; i < futures.size(); i++) {
+                ret.put(fs.getFeatures().get(i), futures.get(i).join());
+            }
+            return ret;
+        });
+    }
+
+    private FeatureSet featureSet(String name) {
+        FeatureSet fs = featureSets.get(name);
+        if (fs == null) {
+            fs = featureSets.get(FeatureSet.normalizeSelector(name, namespace));
+        }
+        if (fs == null) {
+            throw new IllegalArgumentException("the feature set " + name + " is not registered");
+        }
+        return fs;
+    }
+
+    private CompletableFuture<Object> fetch(String selector, Map<String, String> keys) {
+        if (cache != null) {
+            Object cached = cache.get(selector, keys);
+            if (cached != null) {
+                return CompletableFuture.completedFuture(cached == FreshnessCache.MISSING ? null : cached);
+            }
+        }
+
+        Api.GetRequest req = Api.GetRequest.newBuilder()
+                .setUuid(UUID.randomUUID().toString())
+                .setSelector(selector)
+                .putAllKeys(keys)
+                .build();
+        ListenableFuture<Api.GetResponse> call = stub
+                .withDeadlineAfter(timeout.toNanos(), TimeUnit.NANOSECONDS)
+                .get(req);
+
+        CompletableFuture<Object> ret = new CompletableFuture<>();
+        Futures.addCallback(call, new FutureCallback<>() {
+            @Override
+            public void onSuccess(Api.GetResponse resp) {
+                ret.complete(result(selector, keys, resp));
+            }
+
+            @Override
+            public void onFailure(Throwable t) {
+                ret.completeExceptionally(t);
+            }
+        }, MoreExecutors.directExecutor());
+        return ret;
+    }
+
+    private Object result(String selector, Map<String, String> keys, Api.GetResponse resp) {
+        Object value = Values.toJava(resp.getValue().getValue());
+        if (cache != null) {
+            Instant ts = resp.getValue().hasTimestamp() ? Values.toInstant(resp.getValue().getTimestamp()) : null;
+            com.google.protobuf.Duration f = resp.getFeatureDescriptor().getFreshness();
+            Duration freshness = Duration.ofSeconds(f.getSeconds(), f.getNanos());
+            cache.put(selector, keys, value, cache.ttl(ts, freshness, resp.getValue().getFresh()));
+        }
+        return value;
+    }
+
+    private static <T> T join(CompletableFuture<T> f) {
+        try {
+            return f.join();
+        } catch (CompletionException e) {
+            if (e.getCause() instanceof RuntimeException) {
+                throw (RuntimeException) e.getCause();
+            }
+            throw e;
+        }
+    }
+
+    private static Map<String, Object> serviceConfig(int retries) {
+        Map<String, Object> policy = Map.of(
+                "maxAttempts", (double) (retries + 1),
+                "initialBackoff", "0.05s",
+                "maxBackoff", "1s",
+                "backoffMultiplier", 2.0,
+                "retryableStatusCodes", List.of("UNAVAILABLE", "RESOURCE_EXHAUSTED", "ABORTED"));
+        Map<String, Object> method = Map.of(
+                "name", List.of(Map.of("service", "core.v1alpha1.EngineService")),
+                "retryPolicy", policy);
+        return Map.of("methodConfig", List.of(method));
+    }
+
+    /** Closes the connection to the Core, waiting for in-flight calls for up to 5 seconds. */
+    @Override
+    public void close() throws InterruptedException {
+        channel.shutdown();
+        if (!channel.awaitTermination(5, TimeUnit.SECONDS)) {
+            channel.shutdownNow();
+        }
+    }
+
+    public static final class Builder {
+        private String address = DEFAULT_ADDRESS;
+        private String namespace = "default";
+        private Duration timeout = Duration.ofSeconds(1);
+        private int retries = 3;
+        private Duration cacheTtl = Duration.ZERO;
+        private int cacheSize = 10000;
+        private boolean tls;
+        private String token;
+        private Path tokenPath = SERVICE_ACCOUNT_TOKEN;
+
+        private Builder() {
+        }
+
+        /** The address of the Core's gRPC service. Defaults to {@value RaptorClient#DEFAULT_ADDRESS}. */
+        public Builder address(String address) {
+            this.address = address;
+            return this;
+        }
+
+        /** The namespace of the selectors and feature sets that don't specify one. Defaults to "default". */
+        public Builder namespace(String namespace) {
+            this.namespace = namespace;
+            return this;
+        }
+
+        /** The timeout of a request. Defaults to 1 second. */
+        public Builder timeout(Duration timeout) {
+            this.timeout = timeout;
+            return this;
+        }
+
+        /** The number of retries of failed (unavailable) requests. Defaults to 3. */
+        public Builder retries(int retries) {
+            this.retries = retries;
+            return this;
+        }
+
+        /**
+         * The maximal time a value is cached in-process. Values are cached only while they're fresh. Defaults to
+         * zero (no caching).
+         */
+        public Builder cacheTtl(Duration cacheTtl) {
+            this.cacheTtl = cacheTtl;
+            return this;
+        }
+
+        /** The maximal number of cached values. The least recently used values are evicted. Defaults to 10000. */
+        public Builder cacheSize(int cacheSize) {
+            this.cacheSize = cacheSize;
+            return this;
+        }
+
+        /** Connects with TLS rather than plaintext. */
+        public Builder useTransportSecurity() {
+            this.tls = true;
+            return this;
+        }
+
+        /** The bearer token to authenticate with. Defaults to the token of the pod's ServiceAccount. */
+        public Builder token(String token) {
+            this.token = token;
+            return this;
+        }
+
+        /** The file of the bearer token, when a token is not set. */
+        public Builder tokenPath(Path tokenPath) {
+            this.tokenPath = tokenPath;
+            return this;
+        }
+
+        public RaptorClient build() {
+            return new RaptorClient(this);
+        }
+    }
+}
@@ -0,0 +1,69 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ml.raptor.client;
+
+import com.google.protobuf.Timestamp;
+import core.v1alpha1.Types;
+
+import java.time.Instant;
+import java.util.ArrayList;
+import java.util.List;
+
+/**
+ * Values converts the values of the Core to Java values: {@code String}, {@code Integer}, {@code Double},
+ * {@code Boolean}, {@code Instant}, or a {@code List} of them. Missing values are converted to {@code null}.
+ */
+public final class Values {
+    private Values() {
+    }
+
+    public static Object toJava(Types.Value value) {
+        switch (value.getValueCase()) {
+            case SCALAR_VALUE:
+                return toJava(value.getScalarValue());
+            case LIST_VALUE:
+                List<Object> ret = new ArrayList<>(value.getListValue().getValuesCount());
+                for (Types.Scalar s : value.getListValue().getValuesList()) {
+                    ret.add(toJava(s));
+                }
+                return ret;
+            default:
+                return null;
+        }
+    }
+
+    public static Object toJava(Types.Scalar scalar) {
+        switch (scalar.getValueCase()) {
+            case STRING_VALUE:
+                return scalar.getStringValue();
+            case INT_VALUE:
+                return scalar.getIntValue();
+            case FLOAT_VALUE:
+                return scalar.getFloatValue();
+            case BOOL_VALUE:
+                return scalar.getBoolValue();
+            case TIMESTAMP_VALUE:
+                return toInstant(scalar.getTimestampValue());
+            default:
+                return null;
+        }
+    }
+
+    static Instant toInstant(Timestamp ts) {
+        return Instant.ofEpochSecond(ts.getSeconds(), ts.getNanos());
+    }
+}
@@ -0,0 +1,144 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ml.raptor.client.kafka;
+
+import ml.raptor.client.RaptorClient;
+import org.apache.kafka.streams.errors.StreamsException;
+import org.apache.kafka.streams.processor.Cancellable;
+import org.apache.kafka.streams.processor.PunctuationType;
+import org.apache.kafka.streams.processor.api.FixedKeyProcessor;
+import org.apache.kafka.streams.processor.api.FixedKeyProcessorContext;
+import org.apache.kafka.streams.processor.api.FixedKeyRecord;
+
+import java.time.Duration;
+import java.util.ArrayDeque;
+import java.util.Deque;
+import java.util.Map;
+import java.util.concurrent.CompletableFuture;
+import java.util.concurrent.CompletionException;
+import java.util.function.BiFunction;
+
+/**
+ * FeatureEnricher is a Kafka Streams processor that enriches the records with the feature vector of their entity.
+ * <p>
+ * The vectors are read asynchronously, so the stream thread isn't blocked by every lookup: up to
+ * {@code maxInFlight} records are read concurrently, and the records are forwarded in their original order as their
+ * vectors arrive. The processor blocks only when {@code maxInFlight} records are pending, and on every punctuation
+ * (every {@code flushInterval} of wall-clock time), when it waits for all the pending records to be forwarded.
+ * <p>
+ * A commit may include the offsets of records that are still pending. They're forwarded by the next punctuation at the
+ * latest, so keep {@code flushInterval} well below {@code commit.interval.ms} to narrow that window.
+ *
+ * <pre>{@code
+ * transactions.processValues(() -> new FeatureEnricher<String, Transaction, ScoringRequest>(client,
+ *         "fraud-detection", (key, tx) -> Map.of("account_id", tx.getAccountId()), ScoringRequest::new));
+ * }</pre>
+ *
+ * @param <K>  the type of the record keys.
+ * @param <V>  the type of the input values.
+ * @param <VR> the type of the enriched values.
+ */
+public final class FeatureEnricher<K, V, VR> implements FixedKeyProcessor<K, V, VR> {
+    public static final int DEFAULT_MAX_IN_FLIGHT = 256;
+    public static final Duration DEFAULT_FLUSH_INTERVAL = Duration.ofMillis(50);
+
+    private final RaptorClient client;
+    private final String featureSet;
+    private final BiFunction<K, V, Map<String, String>> keys;
+    private final BiFunction<V, Map<String, Object>, VR> joiner;
+    private final int maxInFlight;
+    private final Duration flushInterval;
+    private final Deque<Pending> pending = new ArrayDeque<>();
+
+    private FixedKeyProcessorContext<K, VR> context;
+    private Cancellable punctuator;
+
+    /**
+     * @param client     the client to read the vectors with. It's shared by the processors, and isn't closed by them.
+     * @param featureSet the name of a feature set that is registered in the client.
+     * @param keys       extracts the keys of the entity of a record.
+     * @param joiner     joins the value of a record with the vector of its entity.
+     */
+    public FeatureEnricher(RaptorClient client, String featureSet, BiFunction<K, V, Map<String, String>> keys,
+                           BiFunction<V, Map<String, Object>, VR> joiner) {
+        this(client, featureSet, keys, joiner, DEFAULT_MAX_IN_FLIGHT, DEFAULT_FLUSH_INTERVAL);
+    }
+
+    public FeatureEnricher(RaptorClient client, String featureSet, BiFunction<K, V, Map<String, String>> keys,
+                           BiFunction<V, Map<String, Object>, VR> joiner, int maxInFlight, Duration flushInterval) {
+        if (maxInFlight < 1) {
+            throw new IllegalArgumentException("maxInFlight must be positive");
+        }
+        this.client = client;
+        this.featureSet = featureSet;
+        this.keys = keys;
+        this.joiner = joiner;
+        this.maxInFlight = maxInFlight;
+        this.flushInterval = flushInterval;
+    }
+
+    @Override
+    public void init(FixedKeyProcessorContext<K, VR> context) {
+        this.context = context;
+        this.punctuator = context.schedule(flushInterval, PunctuationType.WALL_CLOCK_TIME, ts -> flush(true));
+    }
+
+    @Override
+    public void process(FixedKeyRecord<K, V> record) {
+        Map<String, String> k = keys.apply(record.key(), record.value());
+        pending.addLast(new Pending(record, client.getVectorAsync(featureSet, k)));
+        flush(false);
+    }
+
+    /** Forwards the pending records whose vectors arrived. If all is set, it waits for all the pending records. */
+    private void flush(boolean all) {
+        while (!pending.isEmpty()) {
+            Pending head = pending.peekFirst();
+            if (!all && !head.vector.isDone() && pending.size() < maxInFlight) {
+                return;
+            }
+            Map<String, Object> vector;
+            try {
+                vector = head.vector.join();
+            } catch (CompletionException e) {
+                throw new StreamsException("failed to read the features of " + featureSet, e.getCause());
+            }
+            pending.removeFirst();
+            context.forward(head.record.withValue(joiner.apply(head.record.value(), vector)));
+        }
+    }
+
+    @Override
+    public void close() {
+        if (punctuator != null) {
+            punctuator.cancel();
+        }
+        // records can't be forwarded while closing, so the pending ones are dropped
+        pending.forEach(p -> p.vector.cancel(false));
+        pending.clear();
+    }
+
+    private final class Pending {
+        private final FixedKeyRecord<K, V> record;
+        private final CompletableFuture<Map<String, Object>> vector;
+
+        Pending(FixedKeyRecord<K, V> record, CompletableFuture<Map<String, Object>> vector) {
+            this.record = record;
+            this.vector = vector;
+        }
+    }
+}
@@ -0,0 +1,64 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ml.raptor.client;
+
+import org.junit.jupiter.api.Test;
+
+import java.time.Duration;
+import java.time.Instant;
+import java.util.List;
+import java.util.Map;
+
+import static org.junit.jupiter.api.Assertions.assertEquals;
+import static org.junit.jupiter.api.Assertions.assertNull;
+import static org.junit.jupiter.api.Assertions.assertSame;
+import static org.junit.jupiter.api.Assertions.assertThrows;
+import static org.junit.jupiter.api.Assertions.assertTrue;
+
+class ClientTest {
+    @Test
+    void normalizesSelectors() {
+        FeatureSet fs = new FeatureSet("fraud-detection", "default",
+                List.of("amount+avg", "auth.last-login@-1", "my-feature[utf8]"), List.of("account_id"));
+        assertEquals("default.fraud_detection", fs.getFqn());
+        assertEquals(List.of("default.amount+avg", "auth.last_login@-1", "default.my_feature[utf8]"),
+                fs.getFeatures());
+        assertEquals(Map.of("account_id", "a1"), fs.entityKeys("a1"));
+    }
+
+    @Test
+    void entityIdRequiresASingleKey() {
+        FeatureSet fs = new FeatureSet("recs", "default", List.of("clicks"), List.of("user_id", "item_id"));
+        assertThrows(IllegalArgumentException.class, () -> fs.entityKeys("u1"));
+    }
+
+    @Test
+    void cachesFreshValues() {
+        FreshnessCache cache = new FreshnessCache(Duration.ofSeconds(5), 2);
+        Duration ttl = cache.ttl(Instant.now(), Duration.ofSeconds(2), true);
+        assertTrue(ttl.compareTo(Duration.ofSeconds(2)) <= 0 && !ttl.isZero());
+        assertEquals(Duration.ZERO, cache.ttl(Instant.now(), Duration.ofSeconds(2), false));
+        assertEquals(Duration.ofSeconds(5), cache.ttl(null, Duration.ZERO, true));
+
+        cache.put("a", Map.of(), 1, Duration.ofSeconds(1));
+        cache.put("b", Map.of(), null, Duration.ofSeconds(1));
+        cache.put("c", Map.of(), 3, Duration.ofSeconds(1));
+        assertNull(cache.get("a", Map.of()));
+        assertSame(FreshnessCache.MISSING, cache.get("b", Map.of()));
+        assertEquals(3, cache.get("c", Map.of()));
+    }
+}
@@ -0,0 +1,112 @@
+# -*- coding: utf-8 -*-
+#  Copyright (c) 2022 RaptorML authors.
+#
+#  Licensed under the Apache License, Version 2.0 (the "License");
+#  you may not use this file except in compliance with the License.
+#  You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+#  Unless required by applicable law or agreed to in writing, software
+#  distributed under the License is distributed on an "AS IS" BASIS,
+#  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#  See the License for the specific language governing permissions and
+#  limitations under the License.
+
+# Runs the shared conformance spec of the state semantics (api/testdata/state-conformance.yaml) against the local state
+# of the LabSDK, so the features that are replayed locally match the ones that are computed in production.
+#
+# Usage: python -m labsdk._test.conformance (from the root of the repository)
+
+import os
+import sys
+from datetime import datetime
+
+import yaml
+
+from labsdk.raptor.local_state import LocalState
+from labsdk.raptor.types.feature import FeatureSpec, AggrSpec, AggregationFunction
+from labsdk.raptor.types.primitives import Primitive
+
+spec_file = os.path.join(os.path.dirname(__file__), '..', '..', 'api', 'testdata', 'state-conformance.yaml')
+
+_converters = {
+    Primitive.String: str,
+    Primitive.Integer: int,
+    Primitive.Float: float,
+    Primitive.Boolean: bool,
+    Primitive.Timestamp: lambda v: parse_time(v),
+}
+
+
+def parse_time(v) -> datetime:
+    if isinstance(v, datetime):
+        return v
+    return datetime.fromisoformat(v.replace('Z', '+00:00'))
+
+
+def convert(value, primitive: Primitive, op: str):
+    if primitive.is_scalar():
+        return _converters[primitive](value)
+    singular = Primitive(primitive.value[2:])
+    if op != 'set':
+        return _converters[singular](value)
+    return [_converters[singular](v) for v in value]
+
+
+def new_spec(case: dict) -> FeatureSpec:
+    ft = case['feature']
+    spec = FeatureSpec(name=case['name'].replace(' ', '_'), keys=['id'])
+    spec.primitive = ft['primitive']
+    spec.freshness = ft['freshness']
+    spec.staleness = ft['staleness']
+    if 'aggr' in ft:
+        spec.aggr = AggrSpec([AggregationFunction(fn) for fn in ft['aggr']], over=spec.staleness,
+                             granularity=ft['freshness'], slide=ft.get('slide'))
+    return spec
+
+
+def run(case: dict) -> list:
+    spec = new_spec(case)
+    state = LocalState()
+    errors = []
+    for i, step in enumerate(case['steps']):
+        op, at = step['op'], parse_time(step['at'])
+        if op == 'buckets':
+            got = state.window_buckets(spec, at)
+            if got != step['expect']:
+                errors.append(f'step #{i}: expected the buckets {step["expect"]}, got {got}')
+        elif op == 'get':
+            got = state.get(spec, '1', at)
+            if got != step['expect']:
+                errors.append(f'step #{i}: expected {step["expect"]}, got {got}')
+        else:
+            try:
+                getattr(state, op)(spec, '1', convert(step['value'], spec.primitive, op), at)
+                if step.get('error', False):
+                    errors.append(f'step #{i}: expected {op} of {step["value"]} to fail')
+            except Exception as e:
+                if not step.get('error', False):
+                    errors.append(f'step #{i}: {e}')
+    return errors
+
+
+def main():
+    with open(spec_file) as f:
+        cases = yaml.safe_load(f)['cases']
+
+    failed = 0
+    for case in cases:
+        errors = run(case)
+        print(f'{"FAIL" if errors else "ok"}\t{case["name"]}')
+        for e in errors:
+            print(f'\t{e}')
+        failed += 1 if errors else 0
+
+    if failed > 0:
+        print(f'{failed} of {len(cases)} cases failed')
+        sys.exit(1)
+
+
+if __name__ == '__main__':
+    main()
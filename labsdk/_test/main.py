@@ -248,7 +248,7 @@ closed_deals_annually.replay()
 @feature(keys='salesman_id', data_source=CrmRecord)
 @freshness(max_age='24h', max_stale='8760h')
 def salesperson_deals_closes_rate(this_row: CrmRecord, ctx: Context) -> int:
-    udia, _ = ctx.get_feature('unique_deals_involvement_annually+distinct_count')
+    udia, _ = ctx.get_feature('unique_deals_involvement_annually+count_distinct')
     cda, _ = ctx.get_feature('closed_deals_annually+count')
     if udia is None or cda is None:
         return None
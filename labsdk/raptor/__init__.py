@@ -17,6 +17,9 @@ from .config import *
 from .decorators import *
 from .local_state import manifests
 from .program import Context
+from .project import export_project
+from .replay import training_set
+from .session import Session, connect
 from .types.model import TrainingContext
 from .types.feature import AggregationFunction
 from .types.primitives import Primitive
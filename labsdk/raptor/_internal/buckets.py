@@ -0,0 +1,162 @@
+# -*- coding: utf-8 -*-
+#  Copyright (c) 2022 RaptorML authors.
+#
+#  Licensed under the Apache License, Version 2.0 (the "License");
+#  you may not use this file except in compliance with the License.
+#  You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+#  Unless required by applicable law or agreed to in writing, software
+#  distributed under the License is distributed on an "AS IS" BASIS,
+#  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#  See the License for the specific language governing permissions and
+#  limitations under the License.
+
+"""
+The time buckets of windowed features, as they are calculated by the core (see `api/windows.go`). Buckets are
+truncated relative to Go's zero time (January 1, year 1 UTC) rather than to the Unix epoch, so for bucket sizes that
+don't divide a day (i.e. weeks) the buckets begin at a different time than the ones of `pd.Grouper`.
+"""
+
+from collections import Counter
+from datetime import datetime, timedelta, timezone
+from typing import List
+
+# nanoseconds between Go's zero time and the Unix epoch
+_go_zero_time_offset = 62135596800 * 10 ** 9
+_epoch = datetime(1970, 1, 1, tzinfo=timezone.utc)
+_digits = '0123456789abcdefghijklmnopqrstuvwxyz'
+
+_percentiles = {'p50': 0.5, 'median': 0.5, 'p90': 0.9, 'p95': 0.95, 'p99': 0.99}
+
+
+def to_ns(ts) -> int:
+    """
+    Returns the Unix time of the timestamp in nanoseconds. Naive timestamps are considered to be in UTC.
+    """
+    if isinstance(getattr(ts, 'value', None), int):
+        # pd.Timestamp
+        return ts.value
+    if ts.tzinfo is None:
+        ts = ts.replace(tzinfo=timezone.utc)
+    return duration_ns(ts - _epoch)
+
+
+def duration_ns(d: timedelta) -> int:
+    if isinstance(getattr(d, 'value', None), int):
+        # pd.Timedelta
+        return d.value
+    return (d.days * 86400 + d.seconds) * 10 ** 9 + d.microseconds * 1000
+
+
+def _trunc_div(a: int, b: int) -> int:
+    # Go's integer division truncates toward zero
+    q = abs(a) // abs(b)
+    return q if (a >= 0) == (b >= 0) else -q
+
+
+def _truncate(ts_ns: int, d_ns: int) -> int:
+    # time.Time.Truncate rounds down to a multiple of d since the zero time
+    return ts_ns - (ts_ns + _go_zero_time_offset) % d_ns
+
+
+def bucket_id(ts, bucket_size: timedelta) -> int:
+    """
+    Returns the bucket of the timestamp (see `api.BucketName`).
+    """
+    size = duration_ns(bucket_size)
+    return _trunc_div(_truncate(to_ns(ts), size), size)
+
+
+def bucket_name(bid: int) -> str:
+    """
+    Returns the name of the bucket, as it's stored by the core (base-34).
+    """
+    if bid == 0:
+        return '0'
+    ret = ''
+    n = abs(bid)
+    while n > 0:
+        n, r = divmod(n, 34)
+        ret = _digits[r] + ret
+    return '-' + ret if bid < 0 else ret
+
+
+def alive_buckets(staleness: timedelta, bucket_size: timedelta, at) -> List[int]:
+    """
+    Returns the buckets of the window at the given time, from the newest (see `api.AliveWindowBucketsAt`).
+    """
+    size, at_ns = duration_ns(bucket_size), to_ns(at)
+    n = -(-duration_ns(staleness) // size)
+    return [_trunc_div(_truncate(at_ns - size * i, size), size) for i in range(n)]
+
+
+def sliding_buckets(staleness: timedelta, bucket_size: timedelta, slide: timedelta, at) -> List[int]:
+    """
+    Returns the buckets of the last complete sliding window at the given time, which ends at the last slide boundary
+    (see `api.SlidingWindowBucketsAt`).
+    """
+    size = duration_ns(bucket_size)
+    end = _truncate(to_ns(at), duration_ns(slide))
+    n = duration_ns(staleness) // size
+    return [_trunc_div(_truncate(end - size * (i + 1), size), size) for i in range(n)]
+
+
+def window_buckets(spec, at) -> List[int]:
+    """
+    Returns the buckets of the window of the feature at the given time.
+    """
+    if spec.aggr.slide is not None and spec.aggr.slide.total_seconds() > 0:
+        return sliding_buckets(spec.staleness, spec.freshness, spec.aggr.slide, at)
+    return alive_buckets(spec.staleness, spec.freshness, at)
+
+
+def is_windowed(spec) -> bool:
+    """
+    Checks if the feature is aggregated in time buckets (see `api.FeatureDescriptor.ValidWindow`).
+    """
+    if spec.aggr is None or spec.freshness is None or spec.staleness is None:
+        return False
+    return spec.freshness.total_seconds() > 0 and spec.staleness >= spec.freshness
+
+
+def merge(values: list, funcs: list, top_k: int = 10) -> dict:
+    """
+    Merges the values of the alive buckets of a window to the result of each of the aggregation functions. Avg is
+    calculated from the sum and the count. Aggregations that have no result (i.e. of an empty window) are omitted.
+
+    In production, the distinct count, the percentiles and the top-k values are approximated by sketches, so they might
+    differ slightly from the exact values that are calculated here.
+    """
+    ret = {}
+    if len(values) == 0:
+        return ret
+    for fn in funcs:
+        fn = getattr(fn, 'value', fn)
+        if fn == 'sum':
+            ret[fn] = float(sum(values))
+        elif fn == 'count':
+            ret[fn] = float(len(values))
+        elif fn == 'min':
+            ret[fn] = float(min(values))
+        elif fn == 'max':
+            ret[fn] = float(max(values))
+        elif fn == 'avg':
+            ret[fn] = float(sum(values)) / len(values)
+        elif fn in ('count_distinct', 'approx_distinct'):
+            ret[fn] = float(len(set(values)))
+        elif fn in _percentiles:
+            ret[fn] = _quantile(sorted(values), _percentiles[fn])
+        elif fn == 'top_k':
+            ret[fn] = [v for v, _ in Counter(values).most_common(top_k)]
+        else:
+            raise Exception(f'the `{fn}` aggregation is not supported by time buckets')
+    return ret
+
+
+def _quantile(values: list, q: float) -> float:
+    pos = (len(values) - 1) * q
+    lo = int(pos)
+    hi = min(lo + 1, len(values) - 1)
+    return float(values[lo] + (values[hi] - values[lo]) * (pos - lo))
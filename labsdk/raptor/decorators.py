@@ -22,7 +22,7 @@ import inspect
 import sys
 import types
 from datetime import timedelta
-from typing import Union, List, Dict, Optional, Callable
+from typing import Union, List, Dict, Optional, Callable, Any
 from warnings import warn
 
 from pandas import DataFrame
@@ -34,7 +34,7 @@ from ._internal import durpy
 from .program import Program
 from .program import normalize_selector
 from .types import FeatureSpec, AggrSpec, AggregationFunction, Primitive, DataSourceSpec, ModelFramework, ModelServer, \
-    KeepPreviousSpec, ModelImpl
+    KeepPreviousSpec, WriteSamplingSpec, ListPolicySpec, ValidationsSpec, FallbackSpec, EncryptionSpec, ModelImpl
 from .types.dsrc_config_stubs.protocol import SourceProductionConfig
 from .types.dsrc_config_stubs.rest import RestConfig
 
@@ -94,6 +94,7 @@ def namespace(namespace: str):
 def runtime(
     packages: Optional[List[str]],  # list of PIP installable packages
     env_name: Optional[str],  # the Raptor virtual environment name
+    isolated: bool = False,  # whether env_name is a RuntimeEnvironment of the namespace
 ):
     """
     Register the runtime environment for the asset.
@@ -103,16 +104,23 @@ def runtime(
     :type env_name: str
     :param env_name: the name of the runtime virtual environment name. The environment should be pre-configured in
         the Raptor Core installation by your DevOps. Defaults to the 'default' runtime if not specified.
+    :type isolated: bool
+    :param isolated: whether env_name is a RuntimeEnvironment of the feature's namespace. The requirements of
+        RuntimeEnvironments are baked into their image, so the feature function can import dataset and modeling
+        packages (i.e. pandas or numpy), and the packages must be part of the environment's requirements.
 
     **Example**:
 
     >>> @runtime(packages=['numpy==1.21.1', 'phonenumbers'], env_name='default')
+
+    >>> @runtime(packages=['pandas'], env_name='pandas-env', isolated=True)
     """
 
     def decorator(func):
         return _opts(func, {'runtime': {
             'packages': packages,
             'env_name': env_name,
+            'isolated': isolated,
         }})
 
     return decorator
@@ -180,6 +188,28 @@ def labels(labels: Dict[str, str]):
     return decorator
 
 
+def test_only():
+    """
+    Mark the asset as test-only. Test-only assets can be used locally (i.e. to test or replay other assets), but they
+    are excluded from the exported project and from `manifests()`.
+
+    **Example**:
+
+    ```python
+    @test_only()
+    ```
+    """
+
+    def decorator(func):
+        return _opts(func, {'test_only': True})
+
+    return decorator
+
+
+# not a test, for pytest's sake
+test_only.__test__ = False
+
+
 # ** Data Source **
 
 def data_source(
@@ -268,6 +298,9 @@ def data_source(
         if 'namespace' in options:
             spec.namespace = options['namespace']
 
+        if 'test_only' in options:
+            spec.test_only = options['test_only']
+
         # convert cls to json schema
         spec.schema = TypeAdapter(cls).json_schema()
 
@@ -291,6 +324,10 @@ def aggregation(
     function: Union[AggregationFunction, List[AggregationFunction], str, List[str]],
     over: Union[str, timedelta, None],
     granularity: Union[str, timedelta, None],
+    slide: Union[str, timedelta, None] = None,
+    session_gap: Union[str, timedelta, None] = None,
+    top_k: Optional[int] = None,
+    half_life: Union[str, timedelta, None] = None,
 ):
     """
     Registers aggregations for the Feature Definition.
@@ -300,6 +337,21 @@ def aggregation(
     :param over: the time period over which to aggregate.
     :type granularity: str or timedelta in the form '2h 3m 4s'
     :param granularity: the granularity of the aggregation (this is overriding the freshness' `max_age`).
+    :type slide: str or timedelta in the form '2h 3m 4s'
+    :param slide: turns the aggregation into a sliding window that advances every `slide` (i.e. "the last 30 minutes,
+      updated every minute"). It must be a multiple of the granularity, and `over` must be a multiple of it.
+    :type session_gap: str or timedelta in the form '2h 3m 4s'
+    :param session_gap: turns the aggregation into a session window, which is closed after `session_gap` without
+      events of the entity (i.e. "events in the current session"). The `duration` function returns the session's length
+      in seconds. It can't be used with `slide`, and `over` must be at least `session_gap`.
+    :type top_k: int
+    :param top_k: the number of values the `top_k` function returns (default 10). `top_k` returns the most frequent
+      string values of the window (i.e. "the top 5 categories viewed in the last 7 days"), and can't be combined with
+      other functions.
+    :type half_life: str or timedelta in the form '2h 3m 4s'
+    :param half_life: turns the aggregation into an exponentially decayed window, where the weight of an event is
+      halved every `half_life` (i.e. "the sum of the amounts, where an amount of yesterday counts as half"). Only
+      `sum`, `count` and `avg` are supported, and it can't be used with `slide` or `session_gap`.
 
     **Example**:
 
@@ -326,12 +378,29 @@ def aggregation(
         over = durpy.from_str(over)
     if isinstance(granularity, str):
         granularity = durpy.from_str(granularity)
+    if isinstance(slide, str):
+        slide = durpy.from_str(slide)
+    if isinstance(session_gap, str):
+        session_gap = durpy.from_str(session_gap)
+    if isinstance(half_life, str):
+        half_life = durpy.from_str(half_life)
 
     def decorator(func):
         for fn in function:
             if fn == AggregationFunction.Unknown:
                 raise Exception('Unknown aggr function')
-        return _opts(func, {'aggr': AggrSpec(function, over, granularity)})
+        if AggregationFunction.TopK in function:
+            if len(function) > 1:
+                raise Exception('top_k can\'t be combined with other aggr functions')
+            if session_gap is not None:
+                raise Exception('top_k can\'t be used with session windows')
+        if half_life is not None:
+            if slide is not None or session_gap is not None:
+                raise Exception('half_life can\'t be used with slide or session_gap')
+            for fn in function:
+                if fn not in (AggregationFunction.Sum, AggregationFunction.Count, AggregationFunction.Avg):
+                    raise Exception(f'{fn} is not supported for decayed windows (half_life)')
+        return _opts(func, {'aggr': AggrSpec(function, over, granularity, slide, session_gap, top_k, half_life)})
 
     return decorator
 
@@ -361,6 +430,201 @@ def keep_previous(versions: int, over: Union[str, timedelta]):
     return decorator
 
 
+def write_sampling(interval: Union[str, timedelta], keep: str = 'latest'):
+    """
+    Limit the writes of the feature-values of each entity to one per interval, to reduce the volume of very chatty
+    sources. It can't be used with aggregations.
+    :type interval: str or timedelta in the form '2h 3m 4s'
+    :param interval: the minimal time between two writes of the feature-value of an entity.
+    :type keep: str
+    :param keep: `latest` to write the latest value of the interval at its end, or `first` to write the first value
+                    immediately and drop the rest.
+
+    **Example**:
+
+    ```python
+    @write_sampling(interval='10s')
+    ```
+    """
+
+    if isinstance(interval, str):
+        interval = durpy.from_str(interval)
+
+    def decorator(func):
+        return _opts(func, {'write_sampling': WriteSamplingSpec(interval, keep)})
+
+    return decorator
+
+
+def list_policy(max_length: int = 0, dedup: bool = False, upsert_key_delimiter: Optional[str] = None):
+    """
+    Bound the values of a list feature, and define how the appended elements are merged into them. It's enforced by
+    the state store on every write of the list.
+    :type max_length: int
+    :param max_length: the maximal number of elements of the list, of which the latest are kept. Defaults to unbounded.
+    :type dedup: bool
+    :param dedup: remove the previous occurrences of an appended element.
+    :type upsert_key_delimiter: str
+    :param upsert_key_delimiter: the delimiter of the keys of the elements, for lists of strings in the form of
+                    `<key><delimiter><value>`. An appended element replaces the elements with the same key.
+
+    **Example**:
+
+    ```python
+    @list_policy(max_length=50, upsert_key_delimiter=':')
+    ```
+    """
+
+    def decorator(func):
+        return _opts(func, {'list_policy': ListPolicySpec(max_length, dedup, upsert_key_delimiter)})
+
+    return decorator
+
+
+def validations(min: Optional[float] = None, max: Optional[float] = None, regex: Optional[str] = None,
+                not_null: bool = False, allowed: Optional[List[Any]] = None, on_violation: str = 'drop'):
+    """
+    Define data-quality rules the feature-values must satisfy when they are written. The elements of list values are
+    validated individually, and violations are counted by the `number_of_validation_violations` metric.
+    :type min: float
+    :param min: the minimal numeric value.
+    :type max: float
+    :param max: the maximal numeric value.
+    :type regex: str
+    :param regex: a regular expression (RE2 syntax) that string values must match.
+    :type not_null: bool
+    :param not_null: whether null values are invalid.
+    :type allowed: list
+    :param allowed: the set of the allowed values, compared by their string representation.
+    :type on_violation: str
+    :param on_violation: `drop` to drop invalid values, `clamp` to clamp numeric values to the min/max range (and drop
+                    values that violate other rules), or `warn` to write them as-is.
+
+    **Example**:
+
+    ```python
+    @validations(min=0, max=10000, on_violation='clamp')
+    ```
+    """
+
+    def decorator(func):
+        return _opts(func, {'validations': ValidationsSpec(min, max, regex, not_null, allowed, on_violation)})
+
+    return decorator
+
+
+def fallback(default: Any = None, last_known: Optional[Union[str, timedelta]] = None):
+    """
+    Define the value that is returned when there is no fresh value for the entity. Fallback responses are marked, so
+    clients can tell them apart. It can't be used with aggregations.
+    :type default: Any
+    :param default: the value that is returned when there is no value for the entity. It must match the primitive of
+                    the feature.
+    :type last_known: str or timedelta in the form '2h 3m 4s'
+    :param last_known: for how long after it went stale the last known value is returned, before falling back to the
+                    default.
+
+    **Example**:
+
+    ```python
+    @fallback(default='bronze', last_known='72h')
+    ```
+    """
+
+    if isinstance(last_known, str):
+        last_known = durpy.from_str(last_known)
+
+    def decorator(func):
+        return _opts(func, {'fallback': FallbackSpec(default, last_known)})
+
+    return decorator
+
+
+def encrypted(provider: str, key_id: str):
+    """
+    Encrypt the feature-values. The values are envelope-encrypted before they're stored, and are decrypted only for
+    callers that are granted with the `decrypt` verb. It can't be used with aggregations.
+    :type provider: str
+    :param provider: the key manager that wraps the data keys of the values (i.e. `local` or `vault`).
+    :type key_id: str
+    :param key_id: the key-encryption key of the provider (i.e. the name of a Vault transit key).
+
+    **Example**:
+
+    ```python
+    @encrypted(provider='vault', key_id='pii')
+    ```
+    """
+
+    def decorator(func):
+        return _opts(func, {'encryption': EncryptionSpec(provider, key_id)})
+
+    return decorator
+
+
+def privacy(pii: bool = False, classification: Optional[str] = None, masking: Optional[str] = None):
+    """
+    Tag the feature-values with their privacy metadata. The values of PII features, and of `confidential` or
+    `restricted` features, are masked for callers that aren't granted with the `unmask` verb.
+    :type pii: bool
+    :param pii: whether the values are personally identifiable information.
+    :type classification: Optional[str]
+    :param classification: the data classification of the values: `public`, `internal`, `confidential` or
+        `restricted`.
+    :type masking: Optional[str]
+    :param masking: how the values are masked: `hash` (default) serves a keyed hash of the value, and `redact`
+        serves no value.
+
+    **Example**:
+
+    ```python
+    @privacy(pii=True, classification='restricted', masking='redact')
+    ```
+    """
+
+    if classification is not None and classification not in ('public', 'internal', 'confidential', 'restricted'):
+        raise Exception(f'unknown classification `{classification}`')
+    if masking is not None and masking not in ('hash', 'redact'):
+        raise Exception(f'unknown masking `{masking}`')
+    if masking is not None and not pii and classification not in ('confidential', 'restricted'):
+        raise Exception('masking can be used only with PII, confidential or restricted features')
+
+    def decorator(func):
+        return _opts(func, {'privacy': {'pii': pii, 'classification': classification, 'masking': masking}})
+
+    return decorator
+
+
+def catalog(owner: Optional[str] = None, tags: Optional[List[str]] = None, links: Optional[Dict[str, str]] = None):
+    """
+    Describe the feature in the catalog, so other teams can discover it instead of re-creating it. The description
+    of the feature is taken from its docstring.
+    :type owner: Optional[str]
+    :param owner: the team or the person that owns the feature (i.e. a team handle or an email).
+    :type tags: Optional[List[str]]
+    :param tags: tags that categorize the feature (i.e. `fraud` or `payments`).
+    :type links: Optional[Dict[str, str]]
+    :param links: references to external resources about the feature (i.e. dashboards, docs or runbooks), by their
+        names.
+
+    **Example**:
+
+    ```python
+    @catalog(owner='risk-team', tags=['fraud'], links={'dashboard': 'https://grafana.example.com/d/refunds'})
+    ```
+    """
+
+    links = links or {}
+    for name, url in links.items():
+        if not name or not url.startswith(('http://', 'https://')):
+            raise Exception(f'invalid link `{name}`: links must have a name and an absolute URL')
+
+    def decorator(func):
+        return _opts(func, {'catalog': {'owner': owner, 'tags': tags or [], 'links': links}})
+
+    return decorator
+
+
 def feature(
     keys: Union[str, List[str]],
     name: Optional[str] = None,  # set to function name if not provided
@@ -399,6 +663,10 @@ def feature(
     It returns a wrapped function with a few additional methods/properties:
         * `raptor_spec` - The Raptor specification of the feature.
         * `replay()` - A function that can be used to replay the feature calculation using the training sata of the source.
+        * `deps` - The features (selectors or feature functions) that are the inputs of the feature. Their values at
+            the time of the request are injected to `this_row` by their selector (i.e. `default.total_purchases+sum`).
+        * `training_set(spine, timestamp_col='timestamp')` - A function that returns the point-in-time values of the
+            replayed feature at the timestamps and keys of the spine DataFrame.
         * `manifest(to_file=False)` - A function that returns the manifest of the feature.
         * `export(with_dependent_source=True)` - A function that exports the feature to `out` directory.
 
@@ -454,6 +722,9 @@ def feature(
         if 'namespace' in options:
             spec.namespace = options['namespace']
 
+        if 'test_only' in options:
+            spec.test_only = options['test_only']
+
         if 'aggr' in options:
             spec.freshness = options['aggr'].granularity
             spec.staleness = options['aggr'].over
@@ -469,6 +740,39 @@ def feature(
         if 'keep_previous' in options:
             spec.keep_previous = options['keep_previous']
 
+        if 'write_sampling' in options:
+            if 'aggr' in options:
+                raise Exception('write_sampling can\'t be used with aggregations')
+            spec.write_sampling = options['write_sampling']
+
+        if 'list_policy' in options:
+            if 'aggr' in options:
+                raise Exception('list_policy can\'t be used with aggregations')
+            spec.list_policy = options['list_policy']
+
+        if 'validations' in options:
+            spec.validations = options['validations']
+
+        if 'fallback' in options:
+            if 'aggr' in options:
+                raise Exception('fallback can\'t be used with aggregations')
+            spec.fallback = options['fallback']
+
+        if 'encryption' in options:
+            if 'aggr' in options:
+                raise Exception('encryption can\'t be used with aggregations')
+            spec.encryption = options['encryption']
+
+        if 'privacy' in options:
+            spec.pii = options['privacy']['pii']
+            spec.classification = options['privacy']['classification']
+            spec.masking = options['privacy']['masking']
+
+        if 'catalog' in options:
+            spec.owner = options['catalog']['owner']
+            spec.tags = options['catalog']['tags']
+            spec.links = options['catalog']['links']
+
         if spec.freshness is None or spec.staleness is None:
             raise Exception('You must specify freshness or aggregation for a feature')
 
@@ -501,7 +805,8 @@ def feature(
 
             return feat.fqn()
 
-        spec.program = Program(func, feature_obj_resolver)
+        spec.program = Program(func, feature_obj_resolver,
+                               isolated=options.get('runtime', {}).get('isolated', False))
         spec.primitive = Primitive.parse(spec.program.primitive)
 
         # aggr parsing should be after program parsing
@@ -515,6 +820,9 @@ def feature(
         # register
         func.raptor_spec = spec
         func.replay = replay.new_replay(spec)
+        func.training_set = replay.new_training_set(spec)
+        func.deps = []
+        spec.deps_getter = lambda: func.deps
         func.manifest = spec.manifest
         func.export = spec.manifest
         local_state.register_spec(spec)
@@ -668,6 +976,8 @@ def model(
             spec.namespace = options['namespace']
         if 'labels' in options:
             spec.labels = options['labels']
+        if 'test_only' in options:
+            spec.test_only = options['test_only']
 
         if 'freshness' in options:
             spec.freshness = options['freshness']['max_age']
@@ -15,11 +15,13 @@
 
 from __future__ import annotations
 
-from typing import List, Union
+from datetime import datetime
+from typing import Any, Dict, List, Optional, Tuple, Union
 
 import pandas as pd
 
 from . import config
+from ._internal import buckets
 from .program import selector_regex, normalize_fqn
 from .types.dsrc import DataSourceSpec
 from .types.feature import FeatureSpec, AggregationFunction
@@ -102,20 +104,128 @@ def feature_values():
     return __feature_values.copy()
 
 
-def manifests(save_to_tmp=False, print_manifests=False):
+# Time buckets of windowed features, as they are written to the historical storage
+__bucket_values = pd.DataFrame()
+
+
+def store_bucket_values(values):
+    global __bucket_values
+    __bucket_values = pd.concat([__bucket_values, values])
+
+
+def bucket_values():
+    global __bucket_values
+    return __bucket_values.copy()
+
+
+class LocalState:
+    """
+    LocalState is an in-memory state of feature values, with the semantics of the state of the core (the Redis plugin):
+
+    - `set` replaces the value, `append` appends to a list, and `incr` increments a numeric scalar.
+    - `update` adds the value to the window of windowed features, sets scalars, and appends to lists.
+    - windowed features are aggregated in time buckets of their freshness, and read from the buckets that are alive at
+      the time of the read rather than from an exact time range (see `buckets.window_buckets`).
+
+    The semantics are verified against the core by a shared conformance spec (see `_test/conformance.py`).
+    Values are kept per feature and encoded keys (see `Keys.encode`).
+    """
+
+    def __init__(self):
+        self._values: Dict[Tuple[str, str], Tuple[Any, datetime]] = {}
+        self._buckets: Dict[Tuple[str, str, int], list] = {}
+
+    def set(self, spec: FeatureSpec, keys: str, value, ts: datetime):
+        if buckets.is_windowed(spec):
+            return self.window_add(spec, keys, value, ts)
+        if not spec.primitive.is_scalar():
+            value = list(value)
+        self._values[(spec.fqn(), keys)] = (value, ts)
+
+    def append(self, spec: FeatureSpec, keys: str, value, ts: datetime):
+        if buckets.is_windowed(spec):
+            raise Exception('cannot append a windowed feature')
+        if spec.primitive.is_scalar():
+            raise Exception('`append` only supports lists')
+        cur, _ = self._values.get((spec.fqn(), keys), ([], None))
+        self._values[(spec.fqn(), keys)] = (cur + [value], ts)
+
+    def incr(self, spec: FeatureSpec, keys: str, by, ts: datetime):
+        if buckets.is_windowed(spec):
+            raise Exception('cannot increment a windowed feature')
+        if not spec.primitive.is_scalar():
+            raise Exception('`incr` only supports scalars')
+        if isinstance(by, bool) or not isinstance(by, (int, float)):
+            raise Exception('`incr` only supports numeric values')
+        cur, _ = self._values.get((spec.fqn(), keys), (0, None))
+        self._values[(spec.fqn(), keys)] = (cur + by, ts)
+
+    def update(self, spec: FeatureSpec, keys: str, value, ts: datetime):
+        if buckets.is_windowed(spec):
+            return self.window_add(spec, keys, value, ts)
+        if spec.primitive.is_scalar():
+            return self.set(spec, keys, value, ts)
+        return self.append(spec, keys, value, ts)
+
+    def window_add(self, spec: FeatureSpec, keys: str, value, ts: datetime):
+        if not buckets.is_windowed(spec):
+            raise Exception(f'feature `{spec.fqn()}` is not windowed')
+        if spec.aggr.session_gap is not None or spec.aggr.half_life is not None:
+            raise Exception('session and decayed windows are kept per entity rather than in time buckets')
+        if AggregationFunction.TopK not in spec.aggr.funcs:
+            if isinstance(value, bool) or not isinstance(value, (int, float)):
+                raise Exception(f'unsupported value type {type(value).__name__}')
+            value = float(value)
+        self._buckets.setdefault((spec.fqn(), keys, buckets.bucket_id(ts, spec.freshness)), []).append(value)
+
+    def get(self, spec: FeatureSpec, keys: str, at: Optional[datetime] = None):
+        """
+        Returns the value of the feature at the given time. Windowed features return the result of each of their
+        aggregations, by the name of the aggregation function.
+        """
+        if not buckets.is_windowed(spec):
+            value, _ = self._values.get((spec.fqn(), keys), (None, None))
+            return value
+
+        values = []
+        for b in buckets.window_buckets(spec, at or datetime.utcnow()):
+            values += self._buckets.get((spec.fqn(), keys, b), [])
+        return buckets.merge(values, spec.aggr.funcs, spec.aggr.top_k or 10)
+
+    def window_buckets(self, spec: FeatureSpec, at: Optional[datetime] = None) -> List[str]:
+        """
+        Returns the names of the buckets of the window of the feature at the given time.
+        """
+        return [buckets.bucket_name(b) for b in buckets.window_buckets(spec, at or datetime.utcnow())]
+
+
+def exported_specs(include_test_only=False) -> List[Spec]:
+    """
+    Returns the registered specs that are exported to the cluster, in their order of registration.
+
+    :type include_test_only: bool
+    :param include_test_only: if True, the test-only specs are included as well
+    """
+    global spec_registry
+    return [s for s in spec_registry if include_test_only or not s.test_only]
+
+
+def manifests(save_to_tmp=False, print_manifests=False, include_test_only=False):
     """
     manifests will create a list of registered Raptor manifests ready to install for your kubernetes cluster
 
     If save_to_tmp is True, it will save the manifests to a temporary file and return the path to the file.
     Otherwise, it will print the manifests.
 
+    Prefer exporting the objects individually (i.e. `feature.manifest()`), or exporting the whole project with
+    `export_project()`.
+
     :type save_to_tmp: if True, save the manifests to a temporary file and return the path to the file
     :type print_manifests: if True, print the manifests
+    :type include_test_only: if True, the test-only objects are included as well
     """
-    global spec_registry
-
     mfts = []
-    for spec in spec_registry:
+    for spec in exported_specs(include_test_only):
         mfts.append(spec.manifest())
 
     if len(mfts) == 0:
@@ -123,6 +123,19 @@ def secure_importer(name, globals=None, locals=None, fromlist=(), level=0):
     return importlib.__import__(name, globals, locals, fromlist, level)
 
 
+def isolated_importer(name, globals=None, locals=None, fromlist=(), level=0):
+    """
+    The importer of programs of isolated runtime environments. The dataset and modeling packages of the environment's
+    requirements can be imported, but i/o packages are restricted anyway.
+    """
+    if name in _blocked_io_packages:
+        raise builtins.ImportError("module '%s' is restricted." % name)
+
+    return importlib.__import__(name, globals, locals, fromlist, level)
+
+
+isolated_builtins = dict(safe_builtins)
+isolated_builtins['__import__'] = isolated_importer
 safe_builtins['__import__'] = secure_importer
 
 _side_effect_ctx_functions = ['get_feature', 'get_prediction']
@@ -221,7 +234,16 @@ class Program:
     src_file: Optional[str] = None
     src_line: Optional[int] = None
 
-    def __init__(self, code, feature_obj_resolver: Callable[[str], str] = None):
+    def __init__(self, code, feature_obj_resolver: Callable[[str], str] = None, isolated: bool = False):
+        """
+        Parse a Feature function.
+        :param code: the source code of the function, or the function itself.
+        :param feature_obj_resolver: resolves the feature objects the function refers to, to their FQN.
+        :param isolated: whether the program runs in an isolated runtime environment (i.e. a RuntimeEnvironment), so
+            it can import the dataset and modeling packages of the environment.
+        """
+        # the side effects are per instance, since a program may be compiled more than once (i.e. in a pool)
+        self.side_effects = []
         if isinstance(code, Callable):
             self.src_file = getsourcefile(code)
             self.src_line = getsourcelines(code)[1]
@@ -255,12 +277,12 @@ class Program:
 
         for imp in (node.find_all('import') + node.find_all('fromimport')):
             iname = imp.name.value
-            if iname in _blocked_dataset_packages:
+            if iname in _blocked_dataset_packages and not isolated:
                 raise SyntaxError(
                     '🛑 You should not use dataset packages(e.g. Pandas) in a Feature function. '
                     "Remember: use the reactive mindset - \"work on a row level, but you always have a state\"")
 
-            if iname in _blocked_modeling_packages:
+            if iname in _blocked_modeling_packages and not isolated:
                 raise SyntaxError("🛑 You shouldn't use modeling packages here. Feature functions are made for "
                                   'calculating the data toward a dataset for the model.')
             if iname in _blocked_io_packages:
@@ -310,7 +332,8 @@ class Program:
         else:
             compiled = compile(self.code, f'<{self.name}>', 'exec')
 
-        glob, loc = {'__builtins__': safe_builtins, 'datetime': dt_pkg, 'List': List}, {}
+        glob, loc = {'__builtins__': isolated_builtins if isolated else safe_builtins, 'datetime': dt_pkg,
+                     'List': List}, {}
         exec(compiled, glob, loc)
 
         self.handler = loc[self.name]
@@ -0,0 +1,140 @@
+# -*- coding: utf-8 -*-
+# Copyright (c) 2022 RaptorML authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+from typing import Dict, List, Optional, Union
+
+import yaml
+
+from . import local_state
+from .types.common import _k8s_name
+
+# the label that is added to the objects of each overlay
+environment_label = 'raptor.ml/environment'
+
+
+def _write_yaml(filename: str, data: dict):
+    with open(filename, 'w') as f:
+        f.write("# Generated by Raptor's LabSDK\n")
+        yaml.safe_dump(data, f, sort_keys=False)
+
+
+def _labels(labels: Dict[str, str]) -> list:
+    # labels are added to the metadata only, since the selectors of existing objects are immutable
+    return [{'pairs': labels, 'includeSelectors': False}]
+
+
+def export_project(path: str = 'out', labels: Optional[Dict[str, str]] = None,
+                   overlays: Optional[Union[List[str], Dict[str, Dict[str, str]]]] = None,
+                   create_namespaces: bool = True, include_test_only: bool = False) -> str:
+    """
+    Export the registered objects to a Kustomize-ready directory, so the project can be deployed with GitOps.
+
+    Each object is written to its own file, in a directory per namespace:
+
+    ```
+    <path>/
+      base/
+        kustomization.yaml
+        <namespace>/
+          kustomization.yaml
+          namespace.yaml
+          datasource.<name>.yaml
+          feature.<name>.yaml
+          model.<name>.yaml
+      overlays/
+        <overlay>/
+          kustomization.yaml
+    ```
+
+    The manifests of the namespace directories can be used as the templates of a Helm chart as well.
+
+    Test-only objects (see `@test_only()`) are excluded, unless `include_test_only` is True. The files of objects that
+    are no longer registered aren't removed, but they're no longer included by the kustomizations.
+
+    :type path: str
+    :param path: the directory to export the project to. Defaults to `out`.
+    :type labels: dict<str,str>
+    :param labels: labels to add to all the exported objects.
+    :type overlays: list of str or dict<str,dict<str,str>>
+    :param overlays: the overlays (i.e. environments) to generate on top of the base. If it's a dict, the values are
+        additional labels of the overlay.
+    :type create_namespaces: bool
+    :param create_namespaces: if True, a Namespace manifest is generated for each namespace.
+    :type include_test_only: bool
+    :param include_test_only: if True, the test-only objects are exported as well.
+    :return: the path of the project directory
+
+    **Example**:
+
+    ```python
+    export_project('deploy', labels={'team': 'search'}, overlays=['staging', 'production'])
+    ```
+    """
+    if isinstance(overlays, list):
+        overlays = {o: {} for o in overlays}
+
+    by_namespace: Dict[str, list] = {}
+    for spec in local_state.exported_specs(include_test_only):
+        by_namespace.setdefault(spec.namespace, []).append(spec)
+
+    base_dir = os.path.join(path, 'base')
+    for ns, specs in by_namespace.items():
+        ns_dir = os.path.join(base_dir, ns)
+        os.makedirs(ns_dir, exist_ok=True)
+
+        resources = []
+        if create_namespaces:
+            _write_yaml(os.path.join(ns_dir, 'namespace.yaml'), {
+                'apiVersion': 'v1',
+                'kind': 'Namespace',
+                'metadata': {'name': ns},
+            })
+            resources.append('namespace.yaml')
+
+        for spec in specs:
+            filename = f'{spec.kind()}.{_k8s_name(spec.name)}.yaml'
+            with open(os.path.join(ns_dir, filename), 'w') as f:
+                f.write(spec.manifest())
+            resources.append(filename)
+
+        _write_yaml(os.path.join(ns_dir, 'kustomization.yaml'), {
+            'apiVersion': 'kustomize.config.k8s.io/v1beta1',
+            'kind': 'Kustomization',
+            'namespace': ns,
+            'resources': resources,
+        })
+
+    base = {
+        'apiVersion': 'kustomize.config.k8s.io/v1beta1',
+        'kind': 'Kustomization',
+        'resources': sorted(by_namespace.keys()),
+    }
+    if labels:
+        base['labels'] = _labels(labels)
+    os.makedirs(base_dir, exist_ok=True)
+    _write_yaml(os.path.join(base_dir, 'kustomization.yaml'), base)
+
+    for name, overlay_labels in (overlays or {}).items():
+        overlay_dir = os.path.join(path, 'overlays', name)
+        os.makedirs(overlay_dir, exist_ok=True)
+        _write_yaml(os.path.join(overlay_dir, 'kustomization.yaml'), {
+            'apiVersion': 'kustomize.config.k8s.io/v1beta1',
+            'kind': 'Kustomization',
+            'resources': ['../../base'],
+            'labels': _labels({environment_label: name, **(overlay_labels or {})}),
+        })
+
+    return path
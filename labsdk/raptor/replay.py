@@ -14,16 +14,17 @@
 # limitations under the License.
 import os.path
 import types as pytypes
-from datetime import datetime, timezone
-from typing import Tuple, Optional, Union, Callable
+from datetime import datetime, timezone, timedelta
+from typing import Tuple, Optional, Union, Callable, List
 
 import bentoml
+import numpy as np
 import pandas as pd
-from pandas.tseries.frequencies import to_offset
 
-from . import local_state
-from .program import Context, primitive, selector_regex, normalize_fqn
-from .types.feature import FeatureSpec, Keys
+from . import config, local_state
+from ._internal import buckets
+from .program import Context, primitive, selector_regex, normalize_fqn, normalize_selector
+from .types.feature import FeatureSpec, Keys, AggregationFunction
 from .types.model import ModelSpec
 from .types.primitives import Primitive
 
@@ -107,7 +108,9 @@ def new_replay(spec: FeatureSpec):
         for k in spec.keys:
             df[k] = df[k].astype(str)
 
-        df['__raptor.ret__'] = df.apply(__replay_map(spec, timestamp_field), axis=1)
+        deps = spec.dependencies()
+        _replay_dependencies(spec, deps)
+        df['__raptor.ret__'] = df.apply(__replay_map(spec, timestamp_field, deps), axis=1)
         df = df.dropna(subset=['__raptor.ret__'])
         if df.empty:
             raise Exception('No data returned from the feature spec.')
@@ -128,7 +131,6 @@ def new_replay(spec: FeatureSpec):
 
         # aggregations
         feature_values = feature_values.set_index('timestamp').sort_index()
-        win = to_offset(spec.staleness)
         fields = []
 
         val_field = 'value'
@@ -136,15 +138,32 @@ def new_replay(spec: FeatureSpec):
             feature_values['f_value'] = feature_values['value'].factorize()[0]
             val_field = 'f_value'
 
-        # TODO: refactor this to use bucketing
-        fvg = feature_values.groupby(['keys']).rolling(win)[val_field]
+        session_gap = spec.aggr.session_gap
+        fvg = None
+        if session_gap is not None:
+            # session windows: a new session begins after `session_gap` of inactivity of the entity
+            ts = feature_values.index.to_series()
+            new_session = ts.groupby(feature_values['keys']).diff() > session_gap
+            feature_values['__raptor.session__'] = new_session.astype(int).groupby(feature_values['keys']).cumsum()
+            fvg = feature_values.groupby(['keys', '__raptor.session__']).expanding()[val_field]
 
         for aggr in spec.aggr.funcs:
             f = f'{spec.fqn()}+{aggr.value}'
-            result = aggr.apply(fvg).reset_index(0).rename(columns={val_field: f})
+            if spec.aggr.half_life is not None:
+                result = _decayed(feature_values, val_field, spec.aggr.half_life, aggr, f)
+            elif fvg is not None:
+                result = aggr.apply(fvg).reset_index([0, 1]).drop(columns=['__raptor.session__'])
+            else:
+                # top-k windows count the values themselves
+                result = _bucketed(feature_values, 'value' if aggr == AggregationFunction.TopK else val_field, spec,
+                                   aggr, f)
+            result = result.rename(columns={val_field: f})
             feature_values = feature_values.merge(result, on=['timestamp', 'keys'], how='left')
             fields.append(f)
 
+        if store_locally and session_gap is None and spec.aggr.half_life is None:
+            local_state.store_bucket_values(_buckets(feature_values, val_field, spec))
+
         feature_values = feature_values.reset_index().drop(columns=['value']). \
             melt(id_vars=['timestamp', 'keys'], value_vars=fields, var_name='fqn', value_name='value')
         if store_locally:
@@ -160,6 +179,7 @@ def new_replay(spec: FeatureSpec):
         """
 
         try:
+            _replaying.add(spec.fqn())
             return _replay(store_locally)
 
         except Exception as e:
@@ -171,10 +191,30 @@ def new_replay(spec: FeatureSpec):
                                        tb_lasti=back_frame.f_lasti,
                                        tb_lineno=back_frame.f_lineno)
             raise Exception(f'{spec.program.name}: {str(e)}').with_traceback(tb)
+        finally:
+            _replaying.discard(spec.fqn())
 
     return replay
 
 
+# the features that are being replayed, to detect circular dependencies
+_replaying = set()
+
+
+def _replay_dependencies(spec: FeatureSpec, deps: List[str]):
+    """
+    Replays the declared dependencies of the feature that weren't replayed yet, so their values can be injected to
+    its requests.
+    """
+    values = local_state.feature_values()
+    for dep in deps:
+        dep_spec = local_state.feature_spec_by_selector(dep)
+        if dep_spec.fqn() in _replaying:
+            raise Exception(f'circular dependency between `{spec.fqn()}` and `{dep_spec.fqn()}`')
+        if values.empty or not values['fqn'].str.split('+').str[0].eq(dep_spec.fqn()).any():
+            new_replay(dep_spec)()
+
+
 def _prediction_getter(owner_spec: FeatureSpec) -> Callable[[str, Keys, datetime], Tuple[primitive, datetime]]:
     def get(selector: str, keys: Keys, timestamp: datetime) -> Tuple[primitive, datetime]:
         spec = local_state.spec_by_selector(selector)
@@ -273,7 +313,87 @@ def _feature_getter(owner_spec: FeatureSpec) -> Callable[
     return get
 
 
-def __replay_map(spec: FeatureSpec, timestamp_field: str):
+def _bucketed(feature_values: pd.DataFrame, val_field: str, spec: FeatureSpec, aggr: AggregationFunction,
+              field: str) -> pd.DataFrame:
+    """
+    Calculates the aggregation of each entity at the time of each of its values, the way the state does in production:
+    the values are aggregated in time buckets, and the result is merged from the buckets that are alive at that time
+    (see `LocalState`).
+    :param feature_values: the feature values, indexed by their timestamp.
+    :param val_field: the field of the values.
+    :param spec: the windowed feature.
+    :param aggr: the aggregation function.
+    :param field: the name of the result field.
+    :return: pd.DataFrame of the aggregation, indexed by the timestamp, with the `keys` and the result field.
+    """
+    state = local_state.LocalState()
+    rows = []
+    for keys, group in feature_values.groupby('keys'):
+        for ts, val in group[val_field].items():
+            if isinstance(val, (np.integer, np.floating)):
+                val = val.item()
+            state.window_add(spec, keys, val, ts)
+            rows.append({'timestamp': ts, 'keys': keys, field: state.get(spec, keys, ts).get(aggr.value)})
+    return pd.DataFrame(rows, columns=['timestamp', 'keys', field]).set_index('timestamp')
+
+
+def _buckets(feature_values: pd.DataFrame, val_field: str, spec: FeatureSpec) -> pd.DataFrame:
+    """
+    Aggregates the values of a windowed feature to its time buckets, the way the historian writes them to the
+    historical storage: each bucket is timestamped by its start time, and holds the fields of the aggregations that are
+    stored by the state (see `api.FeatureDescriptor.BucketFields`).
+    :param feature_values: the feature values, indexed by their timestamp.
+    :param val_field: the field of the values.
+    :param spec: the windowed feature.
+    :return: pd.DataFrame with the `fqn`, `keys`, `timestamp` and the `count`, `sum`, `min` and `max` of each bucket.
+    """
+    funcs = [f.value for f in spec.aggr.funcs]
+    fields = [f for f in ('sum', 'count', 'min', 'max') if f in funcs or (f in ('sum', 'count') and 'avg' in funcs)]
+
+    columns = ['fqn', 'keys', 'timestamp', 'count', 'sum', 'min', 'max']
+    if len(fields) == 0:
+        return pd.DataFrame(columns=columns)
+
+    size = buckets.duration_ns(spec.freshness)
+    values = feature_values.reset_index()
+    values['bucket'] = values['timestamp'].map(lambda ts: buckets.bucket_id(ts, spec.freshness))
+    ret = values.groupby(['keys', 'bucket'])[val_field].agg(fields).reset_index()
+    # `api.BucketTime` is relative to the Unix epoch
+    ret['timestamp'] = pd.to_datetime(ret['bucket'] * size, utc=True)
+    ret.insert(0, 'fqn', spec.fqn())
+    return ret.drop(columns=['bucket']).reindex(columns=columns)
+
+
+def _decayed(feature_values: pd.DataFrame, val_field: str, half_life: timedelta, aggr: AggregationFunction,
+             field: str) -> pd.DataFrame:
+    """
+    Calculates the exponentially decayed aggregation of each entity at the time of each of its values.
+    :param feature_values: the feature values, indexed by their timestamp.
+    :param val_field: the field of the values.
+    :param half_life: the time it takes for the weight of a value to decay by half.
+    :param aggr: the aggregation function (sum, count or avg).
+    :param field: the name of the result field.
+    :return: pd.DataFrame of the decayed aggregation, indexed by the timestamp, with the `keys` and the result field.
+    """
+    rows = []
+    for keys, group in feature_values.groupby('keys'):
+        s, c, last = 0.0, 0.0, None
+        for ts, val in group[val_field].items():
+            if last is not None:
+                factor = 2 ** (-(ts - last) / half_life)
+                s, c = s * factor, c * factor
+            s, c, last = s + val, c + 1, ts
+            if aggr == AggregationFunction.Sum:
+                res = s
+            elif aggr == AggregationFunction.Count:
+                res = c
+            else:
+                res = s / c
+            rows.append({'timestamp': ts, 'keys': keys, field: res})
+    return pd.DataFrame(rows, columns=['timestamp', 'keys', field]).set_index('timestamp')
+
+
+def __replay_map(spec: FeatureSpec, timestamp_field: str, deps: List[str]):
     def map(row: pd.Series):
         ts = row[timestamp_field]
         row = row.drop(timestamp_field)
@@ -286,6 +406,10 @@ def __replay_map(spec: FeatureSpec, timestamp_field: str):
         data = {}
         for k, v in row.items():
             data[str(k)] = v
+
+        # inject the values of the declared dependencies at the time of the request
+        for dep in deps:
+            data[dep], _ = _feature_getter(spec)(dep, keys, ts)
         return spec.program.call(
             data=data,
             context=Context(
@@ -386,3 +510,112 @@ def new_historical_get(spec: ModelSpec):
             raise Exception(f'{spec.fqn()}: {str(e)}').with_traceback(tb)
 
     return historical_get
+
+
+# the fields of the windows that are kept by the historical storage
+_window_fields = ('count', 'sum', 'min', 'max', 'avg')
+
+
+def _windows(bucket_values: pd.DataFrame, staleness: timedelta) -> pd.DataFrame:
+    """
+    Calculates the window of a feature at the start of each of its buckets, the way the historical storage does: the
+    window aggregates the buckets of the same keys that started within the staleness before it (see
+    `featureset.tmpl.sql`).
+    :param bucket_values: the buckets of the feature (see `_buckets`).
+    :param staleness: the staleness of the feature.
+    :return: pd.DataFrame with the `keys`, the `timestamp` and the window `value` of each bucket.
+    """
+    rows = []
+    for keys, group in bucket_values.sort_values('timestamp').groupby('keys'):
+        for ts in group['timestamp']:
+            b = group.loc[(group['timestamp'] > ts - staleness) & (group['timestamp'] <= ts)]
+            count = b['count'].sum(min_count=1)
+            total = b['sum'].sum(min_count=1)
+            val = {
+                'count': None if pd.isna(count) else int(count),
+                'sum': None if pd.isna(total) else float(total),
+                'min': None if b['min'].isna().all() else float(b['min'].min()),
+                'max': None if b['max'].isna().all() else float(b['max'].max()),
+                'avg': None if pd.isna(total) or pd.isna(count) or count == 0 else float(total) / count,
+            }
+            rows.append({'keys': keys, 'timestamp': ts, 'value': val})
+    return pd.DataFrame(rows, columns=['keys', 'timestamp', 'value'])
+
+
+def training_set(spine: pd.DataFrame, features: List[str], timestamp_col: str = 'timestamp') -> pd.DataFrame:
+    """
+    Builds a point-in-time correct training set of the replayed features, the way the historical storage of the core
+    does for a Model's features: each row of the spine is joined with the latest value of each feature at its
+    timestamp, and values that are stale by then are omitted.
+
+    Windowed features are read from their time buckets, so a value holds the `count`, `sum`, `min`, `max` and `avg` of
+    the window at the beginning of the bucket, and a selector of an aggregation (i.e. `name+sum`) picks its field.
+
+    :param pd.DataFrame spine: the timestamps and the keys to build the training set for. It should have a column for
+        each of the keys of the features.
+    :param List[str] features: the selectors of the features.
+    :param str timestamp_col: the timestamp column of the spine.
+    :return: pd.DataFrame of the spine, ordered by the timestamp, with a column of each of the features.
+    """
+    if timestamp_col not in spine.columns:
+        raise Exception(f'the spine has no `{timestamp_col}` column')
+
+    ret = spine.copy()
+    ret['__raptor.ts__'] = pd.to_datetime(ret[timestamp_col], utc=True)
+    ret = ret.sort_values('__raptor.ts__', kind='stable').reset_index(drop=True)
+
+    fvs = local_state.feature_values()
+    bvs = local_state.bucket_values()
+    for f in features:
+        selector = normalize_selector(f, config.default_namespace)
+        spec = local_state.feature_spec_by_selector(selector)
+        aggr_fn = selector_regex.match(selector).group('aggrFn')
+        for k in spec.keys:
+            if k not in ret.columns:
+                raise Exception(f'the spine has no `{k}` column of the keys of `{selector}`')
+        staleness = spec.staleness or timedelta(0)
+
+        if buckets.is_windowed(spec) and spec.aggr.session_gap is None and spec.aggr.half_life is None:
+            if aggr_fn is not None and aggr_fn not in _window_fields:
+                raise Exception(f'the `{aggr_fn}` aggregation of `{selector}` is not kept by the historical storage')
+            values = _windows(bvs.loc[bvs['fqn'] == spec.fqn()], staleness) if not bvs.empty else None
+            if values is not None and aggr_fn is not None:
+                values['value'] = values['value'].map(lambda v: v.get(aggr_fn))
+        else:
+            # features that aren't aggregated in buckets are read from their replayed values
+            values = fvs.loc[fvs['fqn'] == selector] if not fvs.empty else None
+        if values is None or values.empty:
+            raise Exception(f'No data found for `{selector}`. Have you Replayed on your data?')
+
+        values = values.filter(['keys', 'timestamp', 'value']).rename(columns={
+            'keys': '__raptor.keys__',
+            'timestamp': '__raptor.fts__',
+            'value': selector,
+        })
+        values['__raptor.fts__'] = pd.to_datetime(values['__raptor.fts__'], utc=True)
+        ret['__raptor.keys__'] = ret.apply(lambda row: Keys({k: str(row[k]) for k in spec.keys}).encode(spec), axis=1)
+        ret = pd.merge_asof(ret, values.sort_values('__raptor.fts__'), left_on='__raptor.ts__',
+                            right_on='__raptor.fts__', by='__raptor.keys__', direction='backward')
+
+        # values that are stale at the timestamp of the row are omitted
+        stale = ret['__raptor.fts__'].isna() | (ret['__raptor.fts__'] < ret['__raptor.ts__'] - staleness)
+        ret[selector] = ret[selector].astype(object).where(~stale, None)
+        ret = ret.drop(columns=['__raptor.keys__', '__raptor.fts__'])
+
+    return ret.drop(columns=['__raptor.ts__'])
+
+
+def new_training_set(spec: FeatureSpec):
+    def _training_set(spine: pd.DataFrame, timestamp_col: str = 'timestamp') -> pd.DataFrame:
+        """
+        Builds a point-in-time correct training set of the replayed feature (see `raptor.training_set`). Aggregated
+        features have a column for each of their aggregations.
+        :param pd.DataFrame spine: the timestamps and the keys to build the training set for.
+        :param str timestamp_col: the timestamp column of the spine.
+        :return: pd.DataFrame of the spine, ordered by the timestamp, with the feature columns.
+        """
+        if spec.aggr is None:
+            return training_set(spine, [spec.fqn()], timestamp_col)
+        return training_set(spine, [f'{spec.fqn()}+{f.value}' for f in spec.aggr.funcs], timestamp_col)
+
+    return _training_set
@@ -0,0 +1,257 @@
+# -*- coding: utf-8 -*-
+# Copyright (c) 2022 RaptorML authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""
+Session to a Raptor cluster, using short-lived credentials.
+
+A session exchanges the Kubernetes identity of the notebook (by default, the token of its ServiceAccount) for a
+session token that is scoped to namespaces and capabilities, so notebooks can read and write the online values of
+features, inspect their metadata and logs, and push manifests to the sandbox namespace without a kubeconfig.
+"""
+
+import json
+import os
+import time
+import urllib.error
+import urllib.parse
+import urllib.request
+from datetime import datetime
+from typing import Any, Dict, Iterator, List, Optional, Union
+
+from . import config
+from .local_state import feature_spec_by_selector, manifests
+
+_sa_token_path = '/var/run/secrets/kubernetes.io/serviceaccount/token'
+
+
+class Session:
+    """
+    Session is an authenticated session to the Raptor Core's HTTP accessor.
+
+    Example:
+        >>> s = Session('http://raptor-core-service.raptor-system:60001/api', namespaces=['default'])
+        >>> s.sample('default.total_purchases', ['alice', 'bob'])
+        >>> s.feature_descriptor('default.total_purchases')
+        >>> s.plan()
+        >>> s.simulate('default.total_purchases', {'user_id': 'alice', 'amount': 20})
+        >>> s.push(dry_run=True)
+    """
+
+    def __init__(self, url: str, namespaces: Optional[List[str]] = None, scopes: Optional[List[str]] = None,
+                 ttl: Optional[str] = None, kubernetes_token: Optional[str] = None):
+        """
+        :param url: the URL of the HTTP accessor, including its prefix. i.e. `http://localhost:60001/api`
+        :param namespaces: the namespaces to read from. Defaults to the `default_namespace` of the config.
+        :param scopes: the requested scopes: `read`, `write` and/or `dryrun`. Defaults to `read` and `dryrun`.
+        :param ttl: the requested lifetime of the session (i.e. `30m`). Defaults to the cluster's configuration.
+        :param kubernetes_token: the Kubernetes bearer token to authenticate with. Defaults to the ServiceAccount's.
+        """
+        self.url = url.rstrip('/') + '/'
+        self.namespaces = namespaces or [config.default_namespace]
+        self.scopes = scopes or ['read', 'dryrun']
+        self.ttl = ttl
+        self.sandbox_namespace = None
+        self._kubernetes_token = kubernetes_token
+        self._token = None
+        self._expires_at = 0.0
+
+    def _k8s_token(self) -> str:
+        if self._kubernetes_token is not None:
+            return self._kubernetes_token
+        if not os.path.exists(_sa_token_path):
+            raise Exception('`kubernetes_token` is required when not running in a Kubernetes pod')
+        with open(_sa_token_path) as f:
+            return f.read().strip()
+
+    def _request(self, method: str, path: str, token: str, body: Optional[bytes] = None,
+                 content_type: str = 'application/json'):
+        req = urllib.request.Request(self.url + path, data=body, method=method)
+        req.add_header('Authorization', f'Bearer {token}')
+        if body is not None:
+            req.add_header('Content-Type', content_type)
+        try:
+            with urllib.request.urlopen(req) as resp:
+                return json.loads(resp.read())
+        except urllib.error.HTTPError as e:
+            raise Exception(f'Raptor responded with {e.code}: {e.read().decode().strip()}') from None
+
+    def login(self):
+        """
+        Exchanges the Kubernetes identity for a new session token.
+        """
+        req = {'namespaces': self.namespaces, 'scopes': self.scopes}
+        if self.ttl is not None:
+            req['ttl'] = self.ttl
+        resp = self._request('POST', 'lab/token', self._k8s_token(), json.dumps(req).encode())
+        self._token = resp['token']
+        self._expires_at = datetime.fromisoformat(resp['expiresAt'].replace('Z', '+00:00')).timestamp()
+        self.sandbox_namespace = resp.get('sandboxNamespace')
+
+    def token(self) -> str:
+        """
+        Returns a valid session token. The session is renewed when the token is about to expire.
+        """
+        if self._token is None or time.time() > self._expires_at - 30:
+            self.login()
+        return self._token
+
+    def sample(self, fqn: str, entity_ids: Union[List[str], Dict[str, str]]) -> List[dict]:
+        """
+        Reads the online values of a feature for a sample of entities.
+
+        :param fqn: the feature's selector. i.e. `default.total_purchases+sum`
+        :param entity_ids: the ids of the entities (up to 100), or the keys of a single entity for multi-keyed features
+        :return: the values of the entities
+        """
+        if isinstance(entity_ids, dict):
+            params = [('fqn', fqn)] + list(entity_ids.items())
+        else:
+            params = [('fqn', fqn)] + [('entity_id', e) for e in entity_ids]
+        resp = self._request('GET', 'lab/values?' + urllib.parse.urlencode(params), self.token())
+        return resp['values']
+
+    def get(self, selector: str, keys: Dict[str, str]) -> dict:
+        """
+        Reads the online value of a feature for a single entity.
+
+        :param selector: the feature's selector. i.e. `default.total_purchases+sum`
+        :param keys: the keys of the entity. i.e. `{'user_id': 'alice'}`
+        :return: the `value` of the entity, with its `timestamp` and whether it's `fresh`
+        """
+        return self.sample(selector, keys)[0]
+
+    def set(self, fqn: str, keys: Dict[str, str], value: Any, timestamp: Optional[datetime] = None) -> dict:
+        """
+        Sets the online value of a feature for an entity. Requires the `write` scope.
+
+        :param fqn: the feature's FQN. i.e. `default.last_purchase`
+        :param keys: the keys of the entity. i.e. `{'user_id': 'alice'}`
+        :param value: the value, of the feature's primitive
+        :param timestamp: the timestamp of the value. Defaults to now.
+        """
+        return self._write('set', fqn, keys, value, timestamp)
+
+    def append(self, fqn: str, keys: Dict[str, str], value: Any, timestamp: Optional[datetime] = None) -> dict:
+        """
+        Appends a value to the online list of a feature for an entity. Requires the `write` scope.
+        """
+        return self._write('append', fqn, keys, value, timestamp)
+
+    def incr(self, fqn: str, keys: Dict[str, str], by: Union[int, float], timestamp: Optional[datetime] = None) -> dict:
+        """
+        Increments the online value of a feature for an entity. Requires the `write` scope.
+        """
+        return self._write('incr', fqn, keys, by, timestamp)
+
+    def update(self, fqn: str, keys: Dict[str, str], value: Any, timestamp: Optional[datetime] = None) -> dict:
+        """
+        Updates the online value of a feature for an entity, the way the feature's builder does: the value is added to
+        the window of windowed features, set to scalars, and appended to lists. Requires the `write` scope.
+        """
+        return self._write('update', fqn, keys, value, timestamp)
+
+    def _write(self, op: str, fqn: str, keys: Dict[str, str], value: Any, timestamp: Optional[datetime]) -> dict:
+        req = {'fqn': fqn, 'keys': keys, 'op': op, 'value': value}
+        if timestamp is not None:
+            req['timestamp'] = timestamp.astimezone().isoformat()
+        return self._request('POST', 'lab/values', self.token(), json.dumps(req, default=str).encode())
+
+    def feature_descriptor(self, fqn: str) -> dict:
+        """
+        Returns the metadata of a feature, as it's bound to the cluster: its primitive, keys, aggregations, freshness
+        and staleness (in nanoseconds), builder, etc.
+
+        :param fqn: the feature's FQN. i.e. `default.total_purchases`
+        """
+        return self._request('GET', 'lab/features?' + urllib.parse.urlencode({'fqn': fqn}), self.token())
+
+    def logs(self, fqn: str) -> Iterator[dict]:
+        """
+        Streams the log lines of the cluster that mention a feature (i.e. the errors of its computations), as they are
+        logged. The stream is served by a single replica of the Core, and is open until the iteration is stopped.
+
+        Example:
+            >>> for line in s.logs('default.total_purchases'):
+            ...     print(line['time'], line['msg'], line.get('error', ''))
+
+        :param fqn: the feature's FQN. i.e. `default.total_purchases`
+        :return: an iterator of the log lines, with their `time`, `level`, `msg`, `error` and `values`
+        """
+        req = urllib.request.Request(self.url + 'lab/logs?' + urllib.parse.urlencode({'fqn': fqn}))
+        req.add_header('Authorization', f'Bearer {self.token()}')
+        try:
+            resp = urllib.request.urlopen(req)
+        except urllib.error.HTTPError as e:
+            raise Exception(f'Raptor responded with {e.code}: {e.read().decode().strip()}') from None
+        with resp:
+            for line in resp:
+                if line.strip():
+                    yield json.loads(line)
+
+    def push(self, dry_run: bool = True) -> List[dict]:
+        """
+        Pushes the registered manifests to the sandbox namespace of the cluster.
+
+        :param dry_run: if True, the manifests are only validated by the cluster
+        :return: the pushed manifests
+        """
+        body = manifests()
+        if body == '':
+            raise Exception('no manifests are registered')
+        path = 'lab/manifests' + ('?dryRun=true' if dry_run else '')
+        return self._request('POST', path, self.token(), body.encode(), 'application/yaml')
+
+    def plan(self) -> dict:
+        """
+        Reports what would change in the cluster if the registered manifests were applied: the features that would be
+        rebound, the windows and values that would be reset, and the Models and features that are affected.
+
+        :return: the plan report. `blocked` is True if any of the manifests would be rejected.
+        """
+        body = manifests()
+        if body == '':
+            raise Exception('no manifests are registered')
+        return self._request('POST', 'admin/plan', self.token(), body.encode(), 'application/yaml')
+
+    def simulate(self, selector: str, event: dict, timestamp: Optional[datetime] = None) -> dict:
+        """
+        Computes a registered feature from a sample event in the cluster, the way its DataSource's runner would,
+        without persisting anything. It's useful for debugging the builder against the cluster's DataSource and state.
+
+        :param selector: the selector of the registered feature. i.e. `default.total_purchases`
+        :param event: the sample event, as it's consumed from the DataSource (before its mapping)
+        :param timestamp: the timestamp of the event. Defaults to now.
+        :return: the computed `value` (with its `keys` and `timestamp`), the state `operations` of the builder, and the
+                 `logs` of the computation
+        """
+        req = {'manifest': feature_spec_by_selector(selector).manifest(), 'event': event}
+        if timestamp is not None:
+            req['timestamp'] = timestamp.astimezone().isoformat()
+        return self._request('POST', 'lab/simulate', self.token(), json.dumps(req, default=str).encode())
+
+
+def connect(url: str, namespaces: Optional[List[str]] = None, scopes: Optional[List[str]] = None,
+            ttl: Optional[str] = None, kubernetes_token: Optional[str] = None) -> Session:
+    """
+    Connects to a running Raptor cluster, and returns an authenticated session (see `Session`).
+
+    Example:
+        >>> s = raptor.connect('http://raptor-core-service.raptor-system:60001/api', scopes=['read', 'write'])
+        >>> s.set('default.last_purchase', {'user_id': 'alice'}, 20.5)
+        >>> s.get('default.last_purchase', {'user_id': 'alice'})
+    """
+    s = Session(url, namespaces=namespaces, scopes=scopes, ttl=ttl, kubernetes_token=kubernetes_token)
+    s.login()
+    return s
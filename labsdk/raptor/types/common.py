@@ -37,6 +37,8 @@ class RaptorSpec(yaml.YAMLObject):
     description: str = None
     labels: dict = {}
     annotations: dict = {}
+    # test-only objects are used by the local tests and the replays, and aren't exported to the project
+    test_only: bool = False
 
     def __init__(self, name, namespace=None, description=None, labels=None, annotations=None):
         self.name = name
@@ -44,6 +46,7 @@ class RaptorSpec(yaml.YAMLObject):
         self.description = description or ''
         self.labels = labels or {}
         self.annotations = annotations or {}
+        self.test_only = False
 
     @classmethod
     def yaml_tag(cls):
@@ -66,14 +69,19 @@ class RaptorSpec(yaml.YAMLObject):
     def __repr__(self):
         return f'{self.__class__.name}({self.fqn()})'
 
+    def kind(self):
+        """
+        Returns the (lowercase) kind of the object, i.e. `feature`, `datasource` or `model`
+        """
+        return self.__class__.__name__.replace('Spec', '').replace('Impl', '').lower()
+
     def manifest_filename(self):
         """
         Returns the filename of the manifest file exportation
         :return: the filename
         """
-        typ = self.__class__.__name__.replace('Spec', '').replace('Impl', '').lower()
         base_dir = os.path.join(os.getcwd(), 'out')
-        return os.path.join(base_dir, f'{typ}.{self.fqn().lower()}.yaml')
+        return os.path.join(base_dir, f'{self.kind()}.{self.fqn().lower()}.yaml')
 
     def manifest(self, to_file: bool = False):
         """
@@ -14,7 +14,7 @@
 #  limitations under the License.
 
 from datetime import timedelta
-from typing import Optional, List, Dict
+from typing import Optional, List, Dict, Callable, Any
 from warnings import warn
 
 import pandas as pd
@@ -28,7 +28,7 @@ from .primitives import Primitive
 from .. import local_state
 from .._internal import durpy
 from .._internal.exporter.general import GeneralExporter
-from ..program import Program
+from ..program import Program, normalize_fqn, normalize_selector
 
 
 class AggregationFunction(EnumSpec):
@@ -41,8 +41,15 @@ class AggregationFunction(EnumSpec):
     Max = 'max'
     Min = 'min'
     Count = 'count'
-    DistinctCount = 'distinct_count'
-    ApproxDistinctCount = 'approx_distinct_count'
+    DistinctCount = 'count_distinct'
+    ApproxDistinctCount = 'approx_distinct'
+    Duration = 'duration'
+    P50 = 'p50'
+    Median = 'median'
+    P90 = 'p90'
+    P95 = 'p95'
+    P99 = 'p99'
+    TopK = 'top_k'
 
     @staticmethod
     def parse(a):
@@ -55,7 +62,11 @@ class AggregationFunction(EnumSpec):
     def supports(self, typ):
         if self == AggregationFunction.Unknown:
             return False
-        if self in (AggregationFunction.Sum, AggregationFunction.Avg, AggregationFunction.Max, AggregationFunction.Min):
+        if self == AggregationFunction.TopK:
+            return typ == Primitive.String
+        if self in (AggregationFunction.Sum, AggregationFunction.Avg, AggregationFunction.Max, AggregationFunction.Min,
+                    AggregationFunction.P50, AggregationFunction.Median, AggregationFunction.P90,
+                    AggregationFunction.P95, AggregationFunction.P99):
             return typ in (Primitive.Integer, Primitive.Float)
         return True
 
@@ -72,6 +83,16 @@ class AggregationFunction(EnumSpec):
             return rgb.count()
         if self == AggregationFunction.DistinctCount or self == AggregationFunction.ApproxDistinctCount:
             return rgb.apply(lambda x: pd.Series(x).nunique())
+        if self == AggregationFunction.P50 or self == AggregationFunction.Median:
+            return rgb.quantile(0.5)
+        if self == AggregationFunction.P90:
+            return rgb.quantile(0.9)
+        if self == AggregationFunction.P95:
+            return rgb.quantile(0.95)
+        if self == AggregationFunction.P99:
+            return rgb.quantile(0.99)
+        if self == AggregationFunction.Duration:
+            return rgb.apply(lambda x: (x.index.max() - x.index.min()).total_seconds(), raw=False)
         raise Exception(f'Unknown AggrFn {self}')
 
 
@@ -93,14 +114,24 @@ class AggrSpec(yaml.YAMLObject):
     funcs: [AggregationFunction] = None
     over: timedelta = None
     granularity: timedelta = None
-
-    def __init__(self, fns: List[AggregationFunction], over: timedelta, granularity: timedelta):
+    slide: timedelta = None
+    session_gap: timedelta = None
+    top_k: int = None
+    half_life: timedelta = None
+
+    def __init__(self, fns: List[AggregationFunction], over: timedelta, granularity: timedelta,
+                 slide: Optional[timedelta] = None, session_gap: Optional[timedelta] = None,
+                 top_k: Optional[int] = None, half_life: Optional[timedelta] = None):
         self.funcs = fns
         self.over = over
         self.granularity = granularity
+        self.slide = slide
+        self.session_gap = session_gap
+        self.top_k = top_k
+        self.half_life = half_life
 
     def __setattr__(self, key, value):
-        if key == 'granularity':
+        if key in ('granularity', 'slide', 'session_gap', 'half_life'):
             if value == '' or value is None:
                 value = None
             elif isinstance(value, str):
@@ -129,6 +160,100 @@ class KeepPreviousSpec(yaml.YAMLObject):
         self.over = over
 
 
+class WriteSamplingSpec(yaml.YAMLObject):
+    """
+    WriteSamplingSpec is the specification for limiting the writes of the feature-values of each entity.
+    """
+    interval: timedelta = None
+    keep: str = None
+
+    def __init__(self, interval: timedelta, keep: str = 'latest'):
+        if keep not in ('latest', 'first'):
+            raise Exception(f'keep must be `latest` or `first`, got {keep}')
+        if interval.total_seconds() <= 0:
+            raise Exception('interval must be positive')
+        self.interval = interval
+        self.keep = keep
+
+
+class ListPolicySpec(yaml.YAMLObject):
+    """
+    ListPolicySpec is the specification for bounding the values of list features, and merging the appended elements.
+    """
+
+    def __init__(self, max_length: int = 0, dedup: bool = False, upsert_key_delimiter: Optional[str] = None):
+        if max_length < 0:
+            raise Exception('max_length must not be negative')
+        if dedup and upsert_key_delimiter:
+            raise Exception('dedup and upsert_key_delimiter are mutually exclusive')
+        if max_length == 0 and not dedup and not upsert_key_delimiter:
+            raise Exception('list_policy must specify max_length, dedup or upsert_key_delimiter')
+
+        # only the specified options are exported, to keep the manifest minimal
+        if max_length > 0:
+            self.maxLength = max_length
+        if dedup:
+            self.dedup = True
+        if upsert_key_delimiter:
+            self.upsertKeyDelimiter = upsert_key_delimiter
+
+
+class ValidationsSpec(yaml.YAMLObject):
+    """
+    ValidationsSpec is the specification of the data-quality rules the feature-values must satisfy.
+    """
+
+    def __init__(self, min: Optional[float] = None, max: Optional[float] = None, regex: Optional[str] = None,
+                 not_null: bool = False, allowed: Optional[List[str]] = None, on_violation: str = 'drop'):
+        if on_violation not in ('drop', 'clamp', 'warn'):
+            raise Exception(f'on_violation must be `drop`, `clamp` or `warn`, got {on_violation}')
+        if min is not None and max is not None and min > max:
+            raise Exception('min must not be greater than max')
+        if on_violation == 'clamp' and min is None and max is None:
+            raise Exception('on_violation `clamp` requires min or max')
+
+        # only the specified rules are exported, to keep the manifest minimal
+        if min is not None:
+            self.min = str(min)
+        if max is not None:
+            self.max = str(max)
+        if regex is not None:
+            self.regex = regex
+        if not_null:
+            self.notNull = True
+        if allowed:
+            self.allowed = [str(v) for v in allowed]
+        self.onViolation = on_violation
+
+
+class FallbackSpec(yaml.YAMLObject):
+    """
+    FallbackSpec is the specification of the value that is returned when there is no fresh value for the entity.
+    """
+
+    def __init__(self, default=None, last_known: Optional[timedelta] = None):
+        if default is None and last_known is None:
+            raise Exception('fallback must specify a default or last_known')
+        if last_known is not None:
+            if last_known.total_seconds() < 0:
+                raise Exception('last_known must not be negative')
+            self.lastKnown = last_known
+        if default is not None:
+            self.default = default
+
+
+class EncryptionSpec(yaml.YAMLObject):
+    """
+    EncryptionSpec is the specification of the field-level encryption of the feature-values.
+    """
+
+    def __init__(self, provider: str, key_id: str):
+        if not provider or not key_id:
+            raise Exception('encryption must specify a provider and a key_id')
+        self.provider = provider
+        self.keyId = key_id
+
+
 class FeatureSpec(RaptorSpec):
     """
     FeatureSpec is the specification for a feature.
@@ -138,6 +263,17 @@ class FeatureSpec(RaptorSpec):
     staleness: timedelta = None
     timeout: timedelta = None
     keep_previous: Optional[KeepPreviousSpec] = None
+    write_sampling: Optional[WriteSamplingSpec] = None
+    list_policy: Optional[ListPolicySpec] = None
+    validations: Optional[ValidationsSpec] = None
+    fallback: Optional[FallbackSpec] = None
+    encryption: Optional[EncryptionSpec] = None
+    pii: bool = False
+    classification: Optional[str] = None
+    masking: Optional[str] = None
+    owner: Optional[str] = None
+    tags: Optional[List[str]] = None
+    links: Optional[Dict[str, str]] = None
     keys: [str] = None
 
     data_source: Optional[ResourceReference] = None
@@ -147,10 +283,37 @@ class FeatureSpec(RaptorSpec):
     aggr: AggrSpec = None
 
     program: Program = None
+    # returns the declared inputs of the feature (i.e. `f.deps`)
+    deps_getter: Callable[[], List[Any]] = None
 
     def __init__(self, keys=None, *args, **kwargs):
         super().__init__(*args, **kwargs)
         self.keys = keys or []
+        self.builder = BuilderSpec()
+
+    def dependencies(self) -> List[str]:
+        """
+        Returns the selectors of the features that are declared as the inputs of the feature. Their values are injected
+        to the request (`this_row`) by their selector, the way the (proposed) derived-feature builder of the core does.
+        """
+        if self.deps_getter is None:
+            return []
+        ret = []
+        for dep in self.deps_getter() or []:
+            if hasattr(dep, 'raptor_spec'):
+                dep = dep.raptor_spec
+            if isinstance(dep, FeatureSpec):
+                if dep.aggr is not None:
+                    raise Exception(f'You must specify a Feature Selector with AggrFn(i.e. `{dep.fqn()}+sum`) for '
+                                    'aggregated features')
+                dep = dep.fqn()
+            if not isinstance(dep, str):
+                raise Exception(f'Invalid dependency of `{self.fqn()}`: {dep}')
+            selector = normalize_selector(dep, self.namespace)
+            if normalize_fqn(selector) == self.fqn():
+                raise Exception(f'`{self.fqn()}` cannot depend on itself')
+            ret.append(selector)
+        return ret
 
     def export(self, with_dependent_source=True):
         GeneralExporter.add_feature(self, with_dependent_source=with_dependent_source)
@@ -224,7 +387,18 @@ class FeatureSpec(RaptorSpec):
         if data.aggr is not None:
             data.builder.aggr = data.aggr.funcs
             data.builder.aggrGranularity = data.aggr.granularity
+            if data.aggr.slide is not None:
+                data.builder.aggrSlide = data.aggr.slide
+            if data.aggr.session_gap is not None:
+                data.builder.aggrSessionGap = data.aggr.session_gap
+            if data.aggr.top_k is not None:
+                data.builder.aggrTopK = data.aggr.top_k
+            if data.aggr.half_life is not None:
+                data.builder.aggrHalfLife = data.aggr.half_life
         data.builder.code = data.program.code
+        deps = data.dependencies()
+        if len(deps) > 0:
+            data.builder.dependencies = deps
 
         data.annotations['a8r.io/description'] = data.description
 
@@ -238,7 +412,9 @@ class FeatureSpec(RaptorSpec):
                 'annotations': data.annotations
             },
             'spec': {
-                'primitive': data.primitive.value,
+                # top-k windows count string values, and return the most frequent of them
+                'primitive': Primitive.StringList.value if data.aggr is not None and AggregationFunction.TopK in
+                data.aggr.funcs else data.primitive.value,
                 'freshness': data.freshness,
                 'staleness': data.staleness,
                 'timeout': data.timeout,
@@ -246,6 +422,17 @@ class FeatureSpec(RaptorSpec):
                 'keys': data.keys,
                 'dataSource': None if data.data_source is None else data.data_source.__dict__,
                 'builder': data.builder,
+                'writeSampling': data.write_sampling,
+                'listPolicy': data.list_policy,
+                'validations': data.validations,
+                'fallback': data.fallback,
+                'encryption': data.encryption,
+                'pii': data.pii or None,
+                'classification': data.classification,
+                'masking': data.masking,
+                'owner': data.owner,
+                'tags': data.tags or None,
+                'links': [{'name': name, 'url': url} for name, url in (data.links or {}).items()] or None,
             }
         }
 
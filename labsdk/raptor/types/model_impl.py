@@ -43,6 +43,13 @@ class ModelImpl(ModelSpec):
     def features_and_labels(self, since: Optional[Union[datetime, str]] = None, until: Optional[Union[datetime, str]] = None):
         return self._features_and_labels(since, until)
 
+    def training_set(self, spine, timestamp_col: str = 'timestamp'):
+        """
+        Builds a point-in-time correct training set of the replayed features and labels of the model at the timestamps
+        and keys of the spine (see `raptor.training_set`).
+        """
+        return replay.training_set(spine, self.features + self.label_features, timestamp_col)
+
     def train(self):
         for f in (self.features + self.label_features + ([self.key_feature] if self.key_feature is not None else [])):
             s = local_state.feature_spec_by_selector(f)
@@ -35,7 +35,7 @@ class Primitive(EnumSpec):
     TimestampList = '[]timestamp'
 
     def is_scalar(self):
-        return self in (Primitive.String, Primitive.Integer, Primitive.Float, Primitive.Timestamp)
+        return self in (Primitive.String, Primitive.Integer, Primitive.Float, Primitive.Boolean, Primitive.Timestamp)
 
     @staticmethod
     def parse(p):
@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import { Keys, Value } from './values';
+
+/**
+ * FreshnessCache is an in-process LRU cache of feature values.
+ *
+ * A value is cached only while it's fresh: its TTL is the remaining freshness of the value (the time until it's
+ * `freshness` old), bounded by `maxTtl`. Values that aren't fresh anymore aren't cached, so they're read from the
+ * Core, which recomputes them if needed.
+ */
+export class FreshnessCache {
+  private readonly entries = new Map<string, { expires: number; value: Value }>();
+
+  /**
+   * @param maxTtl the maximal time (in milliseconds) a value is cached.
+   * @param maxSize the maximal number of cached values. The least recently used values are evicted.
+   */
+  constructor(
+    readonly maxTtl: number,
+    readonly maxSize = 10000,
+  ) {}
+
+  /** ttl returns the time (in milliseconds) a value can be cached for. */
+  ttl(timestamp: Date | null, freshness: number, fresh = true): number {
+    if (!fresh || this.maxTtl <= 0) {
+      return 0;
+    }
+    if (timestamp === null || freshness <= 0) {
+      return this.maxTtl;
+    }
+    const remaining = timestamp.getTime() + freshness - Date.now();
+    return Math.max(0, Math.min(this.maxTtl, remaining));
+  }
+
+  /** get returns the cached value, or undefined if it's not cached. */
+  get(selector: string, keys: Keys): Value | undefined {
+    const k = key(selector, keys);
+    const e = this.entries.get(k);
+    if (e === undefined) {
+      return undefined;
+    }
+    this.entries.delete(k);
+    if (e.expires < Date.now()) {
+      return undefined;
+    }
+    this.entries.set(k, e);
+    return e.value;
+  }
+
+  set(selector: string, keys: Keys, value: Value, ttl: number): void {
+    if (ttl <= 0) {
+      return;
+    }
+    const k = key(selector, keys);
+    this.entries.delete(k);
+    this.entries.set(k, { expires: Date.now() + ttl, value });
+    while (this.entries.size > this.maxSize) {
+      this.entries.delete(this.entries.keys().next().value as string);
+    }
+  }
+
+  clear(): void {
+    this.entries.clear();
+  }
+}
+
+function key(selector: string, keys: Keys): string {
+  return JSON.stringify([selector, Object.keys(keys).sort().map((k) => [k, keys[k]])]);
+}
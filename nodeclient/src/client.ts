@@ -0,0 +1,207 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import { randomUUID } from 'node:crypto';
+import { existsSync, readFileSync } from 'node:fs';
+
+import { FreshnessCache } from './cache';
+import { FeatureSet, normalizeSelector } from './featureset';
+import { GrpcWebTransport, RestTransport, Transport } from './transport';
+import { Code, Keys, RaptorError, retryable, Value } from './values';
+
+export const DEFAULT_BASE_URL = 'http://raptor-core-service.raptor-system:60001/api';
+export const SERVICE_ACCOUNT_TOKEN = '/var/run/secrets/kubernetes.io/serviceaccount/token';
+
+export interface ClientOptions {
+  /** The base URL of the Core's HTTP accessor, including its prefix. */
+  baseUrl?: string;
+  /** The transport of the calls: `rest` (the default), `grpc-web`, or a custom Transport. */
+  transport?: 'rest' | 'grpc-web' | Transport;
+  /** The namespace of the selectors and feature sets that don't specify one. Defaults to `default`. */
+  namespace?: string;
+  /** The timeout (in milliseconds) of a request. Defaults to 1000. */
+  timeout?: number;
+  /** The number of retries of failed (unavailable) requests. Defaults to 3. */
+  retries?: number;
+  /** The bearer token to authenticate with. Defaults to the token of the pod's ServiceAccount. */
+  token?: string;
+  /** The file of the bearer token, when `token` is not set. It's re-read on every call, since it's rotated. */
+  tokenPath?: string;
+  /** An API key to authenticate with, instead of a bearer token. */
+  apiKey?: string;
+  /**
+   * The maximal time (in milliseconds) a value is cached in-process. Values are cached only while they're fresh.
+   * Defaults to 0 (no caching).
+   */
+  cacheTtl?: number;
+  /** The maximal number of cached values. Defaults to 10000. */
+  cacheSize?: number;
+  /** The fetch implementation. Defaults to the global fetch. */
+  fetch?: typeof fetch;
+}
+
+export interface GetOptions {
+  /** The context of the request, passed to features that use it. Values of such requests aren't cached. */
+  requestContext?: Record<string, unknown>;
+  signal?: AbortSignal;
+}
+
+/**
+ * Client is a client of the Raptor Core, for reading feature values in Node backends.
+ *
+ * ```ts
+ * const client = new Client({ cacheTtl: 5000 });
+ * const vector = await client.getVector(FraudDetection, 'account-123');
+ * ```
+ */
+export class Client {
+  readonly namespace: string;
+  private readonly transport: Transport;
+  private readonly timeout: number;
+  private readonly retries: number;
+  private readonly cache: FreshnessCache | null;
+  private readonly featureSets = new Map<string, FeatureSet>();
+
+  constructor(private readonly options: ClientOptions = {}) {
+    const baseUrl = options.baseUrl ?? DEFAULT_BASE_URL;
+    if (typeof options.transport === 'object') {
+      this.transport = options.transport;
+    } else if (options.transport === 'grpc-web') {
+      this.transport = new GrpcWebTransport(baseUrl, options.fetch);
+    } else {
+      this.transport = new RestTransport(baseUrl, options.fetch);
+    }
+    this.namespace = options.namespace ?? 'default';
+    this.timeout = options.timeout ?? 1000;
+    this.retries = options.retries ?? 3;
+    this.cache = options.cacheTtl ? new FreshnessCache(options.cacheTtl, options.cacheSize) : null;
+  }
+
+  /** registerFeatureSet registers an untyped feature set, so its vectors can be read by its name. */
+  registerFeatureSet(name: string, features: string[], keys: string[], namespace?: string): FeatureSet {
+    const fs = FeatureSet.of(name, features, keys, namespace ?? this.namespace);
+    this.featureSets.set(fs.fqn, fs);
+    this.featureSets.set(name, fs);
+    return fs;
+  }
+
+  /** get returns the value of the feature (i.e. `namespace.name+aggr`) of the entity. */
+  async get(selector: string, keys: Keys, opts: GetOptions = {}): Promise<Value> {
+    return this.fetch(normalizeSelector(selector, this.namespace), keys, this.headers(opts), opts);
+  }
+
+  /**
+   * getVector returns the values of the features of the feature set, for the entity. The entity is given by its ID if
+   * the feature set has a single key, or by its keys. The features are read concurrently.
+   */
+  getVector<T>(fs: FeatureSet<T>, entity: string | Keys, opts?: GetOptions): Promise<T>;
+  getVector(name: string, entity: string | Keys, opts?: GetOptions): Promise<Record<string, Value>>;
+  async getVector(fs: FeatureSet<any> | string, entity: string | Keys, opts: GetOptions = {}): Promise<any> {
+    const set = typeof fs === 'string' ? this.featureSet(fs) : fs;
+    const keys = set.entityKeys(entity);
+    const headers = this.headers(opts);
+    const props = Object.keys(set.fields);
+    const values = await Promise.all(props.map((p) => this.fetch(set.fields[p], keys, headers, opts)));
+    const ret: Record<string, Value> = {};
+    props.forEach((p, i) => (ret[p] = values[i]));
+    return ret;
+  }
+
+  private featureSet(name: string): FeatureSet {
+    const fs = this.featureSets.get(name) ?? this.featureSets.get(normalizeSelector(name, this.namespace));
+    if (fs === undefined) {
+      throw new Error(`the feature set ${name} is not registered`);
+    }
+    return fs;
+  }
+
+  private headers(opts: GetOptions): Record<string, string> {
+    const ret: Record<string, string> = {};
+    let token = this.options.token;
+    const tokenPath = this.options.tokenPath ?? SERVICE_ACCOUNT_TOKEN;
+    if (token === undefined && !this.options.apiKey && existsSync(tokenPath)) {
+      token = readFileSync(tokenPath, 'utf8').trim();
+    }
+    if (this.options.apiKey) {
+      ret['x-api-key'] = this.options.apiKey;
+    } else if (token) {
+      ret['authorization'] = `Bearer ${token}`;
+    }
+    if (opts.requestContext) {
+      ret['x-raptor-request-context'] = JSON.stringify(opts.requestContext);
+    }
+    return ret;
+  }
+
+  private async fetch(selector: string, keys: Keys, headers: Record<string, string>, opts: GetOptions): Promise<Value> {
+    const cacheable = this.cache !== null && !opts.requestContext;
+    if (cacheable) {
+      const cached = this.cache!.get(selector, keys);
+      if (cached !== undefined) {
+        return cached;
+      }
+    }
+
+    const req = { uuid: randomUUID(), selector, keys };
+    for (let attempt = 0; ; attempt++) {
+      const signals = [AbortSignal.timeout(this.timeout)];
+      if (opts.signal) signals.push(opts.signal);
+      try {
+        const resp = await this.transport.get(req, headers, anySignal(signals));
+        if (cacheable) {
+          this.cache!.set(selector, keys, resp.value, this.cache!.ttl(resp.timestamp, resp.freshness, resp.fresh));
+        }
+        return resp.value;
+      } catch (e) {
+        const err = toRaptorError(e);
+        if (attempt >= this.retries || !retryable(err.code) || opts.signal?.aborted) {
+          throw err;
+        }
+        await sleep(Math.min(1000, 50 * 2 ** attempt));
+      }
+    }
+  }
+}
+
+function toRaptorError(e: unknown): RaptorError {
+  if (e instanceof RaptorError) {
+    return e;
+  }
+  if (e instanceof Error && (e.name === 'TimeoutError' || e.name === 'AbortError')) {
+    return new RaptorError(e.message, Code.DeadlineExceeded);
+  }
+  // network failures (i.e. refused connections) are thrown by fetch as TypeErrors
+  return new RaptorError(e instanceof Error ? e.message : String(e), Code.Unavailable);
+}
+
+function anySignal(signals: AbortSignal[]): AbortSignal {
+  if (signals.length === 1) {
+    return signals[0];
+  }
+  const controller = new AbortController();
+  for (const s of signals) {
+    if (s.aborted) {
+      controller.abort(s.reason);
+      break;
+    }
+    s.addEventListener('abort', () => controller.abort(s.reason), { once: true });
+  }
+  return controller.signal;
+}
+
+function sleep(ms: number): Promise<void> {
+  return new Promise((resolve) => setTimeout(resolve, ms));
+}
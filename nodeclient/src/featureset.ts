@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import { Keys, Value } from './values';
+
+const suffix = /[+@[]/;
+
+export function fqn(namespace: string, name: string): string {
+  return `${namespace}.${name}`.replace(/-/g, '_');
+}
+
+/** normalizeSelector returns the selector with the namespace, i.e. `default.clicks+count` of `clicks+count`. */
+export function normalizeSelector(selector: string, namespace: string): string {
+  const m = suffix.exec(selector);
+  let base = m ? selector.slice(0, m.index) : selector;
+  const rest = m ? selector.slice(m.index) : '';
+  if (!base.includes('.')) {
+    base = fqn(namespace, base);
+  }
+  return base.replace(/-/g, '_') + rest;
+}
+
+/**
+ * FeatureSet is the ordered list of features of a Model, that are read together as the model's input vector.
+ *
+ * The type parameter is the type of the vector, and `fields` maps its properties to the selectors of the features.
+ * Typed feature sets are generated by `raptorctl codegen --lang typescript`.
+ */
+export class FeatureSet<T = Record<string, Value>> {
+  readonly fields: Readonly<Record<keyof T & string, string>>;
+
+  constructor(
+    readonly fqn: string,
+    fields: Record<keyof T & string, string>,
+    readonly keys: readonly string[],
+  ) {
+    const namespace = fqn.includes('.') ? fqn.slice(0, fqn.indexOf('.')) : 'default';
+    const normalized = {} as Record<keyof T & string, string>;
+    for (const k of Object.keys(fields) as (keyof T & string)[]) {
+      normalized[k] = normalizeSelector(fields[k], namespace);
+    }
+    this.fields = Object.freeze(normalized);
+  }
+
+  /** of creates an untyped feature set, whose vectors are keyed by the selectors of the features. */
+  static of(name: string, features: string[], keys: string[], namespace = 'default'): FeatureSet {
+    const fields: Record<string, string> = {};
+    for (const f of features) {
+      const s = normalizeSelector(f, namespace);
+      fields[s] = s;
+    }
+    return new FeatureSet<Record<string, Value>>(fqn(namespace, name), fields, keys);
+  }
+
+  /** entityKeys returns the keys of the entity. An ID can be given only if the feature set has a single key. */
+  entityKeys(entity: string | Keys): Keys {
+    if (typeof entity !== 'string') {
+      return entity;
+    }
+    if (this.keys.length !== 1) {
+      throw new Error(
+        `${this.fqn} has ${this.keys.length} keys (${this.keys.join(', ')}), so the keys of the entity should be given`,
+      );
+    }
+    return { [this.keys[0]]: entity };
+  }
+}
@@ -0,0 +1,24 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+export { Client, DEFAULT_BASE_URL, SERVICE_ACCOUNT_TOKEN } from './client';
+export type { ClientOptions, GetOptions } from './client';
+export { FeatureSet, normalizeSelector } from './featureset';
+export { FreshnessCache } from './cache';
+export { GrpcWebTransport, RestTransport } from './transport';
+export type { Transport } from './transport';
+export { Code, RaptorError } from './values';
+export type { Keys, Scalar, Value } from './values';
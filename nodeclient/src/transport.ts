@@ -0,0 +1,154 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import { decodeGetResponse, encodeGetRequest, GetRequest, GetResponse } from './wire';
+import { Code, codeOfHTTPStatus, RaptorError, Scalar, Value } from './values';
+
+/** Transport sends the calls of the client to the Core. */
+export interface Transport {
+  get(req: GetRequest, headers: Record<string, string>, signal: AbortSignal): Promise<GetResponse>;
+}
+
+type Fetch = typeof fetch;
+
+const ENGINE_SERVICE = 'core.v1alpha1.EngineService';
+
+/**
+ * RestTransport calls the HTTP accessor of the Core (i.e. `http://raptor-core-service.raptor-system:60001/api`).
+ */
+export class RestTransport implements Transport {
+  constructor(
+    private readonly baseUrl: string,
+    private readonly fetchFn: Fetch = fetch,
+  ) {}
+
+  async get(req: GetRequest, headers: Record<string, string>, signal: AbortSignal): Promise<GetResponse> {
+    const url = new URL(`${this.baseUrl.replace(/\/$/, '')}/${encodeURIComponent(req.selector)}`);
+    for (const [k, v] of Object.entries(req.keys)) {
+      url.searchParams.set(`keys[${k}]`, v);
+    }
+    url.searchParams.set('uuid', req.uuid);
+
+    // the gateway passes the `Grpc-Metadata-` headers as the metadata of the call
+    const h: Record<string, string> = { Accept: 'application/json' };
+    for (const [k, v] of Object.entries(headers)) {
+      h[k.toLowerCase() === 'authorization' || k.toLowerCase() === 'x-api-key' ? k : `Grpc-Metadata-${k}`] = v;
+    }
+    const resp = await this.fetchFn(url, { headers: h, signal });
+    const body = await resp.json().catch(() => ({}));
+    if (!resp.ok) {
+      throw new RaptorError(body.message ?? resp.statusText, body.code ?? codeOfHTTPStatus(resp.status));
+    }
+    return restResponse(body);
+  }
+}
+
+// restResponse converts the (protojson) GetResponse of the gateway.
+export function restResponse(body: any): GetResponse {
+  const fv = body.value ?? {};
+  return {
+    uuid: body.uuid ?? '',
+    fqn: fv.fqn ?? '',
+    value: restValue(fv.value),
+    timestamp: fv.timestamp ? new Date(fv.timestamp) : null,
+    fresh: fv.fresh === true,
+    freshness: parseDuration(body.featureDescriptor?.freshness),
+  };
+}
+
+function restScalar(s: any): Scalar | null {
+  if (s == null) return null;
+  if (s.stringValue !== undefined) return s.stringValue;
+  if (s.intValue !== undefined) return Number(s.intValue);
+  if (s.floatValue !== undefined) return Number(s.floatValue);
+  if (s.boolValue !== undefined) return s.boolValue;
+  if (s.timestampValue !== undefined) return new Date(s.timestampValue);
+  return null;
+}
+
+function restValue(v: any): Value {
+  if (v == null) return null;
+  if (v.listValue != null) return (v.listValue.values ?? []).map(restScalar);
+  return restScalar(v.scalarValue);
+}
+
+// parseDuration parses a protojson Duration (i.e. `1.5s`) to milliseconds.
+function parseDuration(d: string | undefined): number {
+  if (!d) return 0;
+  return Number(d.replace(/s$/, '')) * 1000;
+}
+
+/**
+ * GrpcWebTransport calls the Core over gRPC-web, which is served by the HTTP accessor (or by a gRPC-web proxy) at the
+ * base URL (i.e. `http://raptor-core-service.raptor-system:60001/api`).
+ */
+export class GrpcWebTransport implements Transport {
+  constructor(
+    private readonly baseUrl: string,
+    private readonly fetchFn: Fetch = fetch,
+  ) {}
+
+  async get(req: GetRequest, headers: Record<string, string>, signal: AbortSignal): Promise<GetResponse> {
+    const msg = encodeGetRequest(req);
+    const body = new Uint8Array(5 + msg.length);
+    new DataView(body.buffer).setUint32(1, msg.length);
+    body.set(msg, 5);
+
+    const resp = await this.fetchFn(`${this.baseUrl.replace(/\/$/, '')}/${ENGINE_SERVICE}/Get`, {
+      method: 'POST',
+      headers: { ...headers, 'Content-Type': 'application/grpc-web+proto', 'X-Grpc-Web': '1' },
+      body,
+      signal,
+    });
+    if (!resp.ok) {
+      throw new RaptorError(resp.statusText, codeOfHTTPStatus(resp.status));
+    }
+
+    const frames = new Uint8Array(await resp.arrayBuffer());
+    let message: Uint8Array | null = null;
+    // trailers-only responses carry the status in the headers
+    let code = resp.headers.get('grpc-status');
+    let errMessage = resp.headers.get('grpc-message') ?? '';
+    for (let pos = 0; pos + 5 <= frames.length; ) {
+      const flag = frames[pos];
+      const size = new DataView(frames.buffer, frames.byteOffset + pos + 1, 4).getUint32(0);
+      const frame = frames.subarray(pos + 5, pos + 5 + size);
+      pos += 5 + size;
+      if ((flag & 0x80) === 0) {
+        message = frame;
+        continue;
+      }
+      for (const line of new TextDecoder().decode(frame).split('\r\n')) {
+        const i = line.indexOf(':');
+        if (i < 0) continue;
+        const k = line.slice(0, i).trim().toLowerCase();
+        const v = line.slice(i + 1).trim();
+        if (k === 'grpc-status') code = v;
+        if (k === 'grpc-message') errMessage = decodeURIComponent(v);
+      }
+    }
+    if (code === null) {
+      throw new RaptorError('the response is missing the grpc-status trailer', Code.Internal);
+    }
+    if (Number(code) !== Code.OK) {
+      throw new RaptorError(errMessage, Number(code));
+    }
+    if (message === null) {
+      throw new RaptorError('the response is missing the message', Code.Internal);
+    }
+    return decodeGetResponse(message);
+  }
+}
@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/** Value is a feature value. Missing values are null. */
+export type Scalar = string | number | boolean | Date;
+export type Value = Scalar | Scalar[] | null;
+
+/** Keys are the keys of an entity. */
+export type Keys = Record<string, string>;
+
+/** RaptorError is an error of a call to the Core. `code` is the gRPC status code of the error. */
+export class RaptorError extends Error {
+  constructor(
+    message: string,
+    readonly code: number,
+  ) {
+    super(message);
+    this.name = 'RaptorError';
+  }
+}
+
+// The gRPC status codes the client handles.
+export const Code = {
+  OK: 0,
+  Unknown: 2,
+  InvalidArgument: 3,
+  DeadlineExceeded: 4,
+  NotFound: 5,
+  PermissionDenied: 7,
+  ResourceExhausted: 8,
+  Aborted: 10,
+  Unimplemented: 12,
+  Internal: 13,
+  Unavailable: 14,
+  Unauthenticated: 16,
+} as const;
+
+/** retryable reports whether a call that failed with the code can be retried. */
+export function retryable(code: number): boolean {
+  return code === Code.Unavailable || code === Code.ResourceExhausted || code === Code.Aborted;
+}
+
+/** codeOfHTTPStatus maps the HTTP status of a REST call to a gRPC status code. */
+export function codeOfHTTPStatus(status: number): number {
+  switch (status) {
+    case 400:
+      return Code.InvalidArgument;
+    case 401:
+      return Code.Unauthenticated;
+    case 403:
+      return Code.PermissionDenied;
+    case 404:
+      return Code.NotFound;
+    case 409:
+      return Code.Aborted;
+    case 429:
+      return Code.ResourceExhausted;
+    case 501:
+      return Code.Unimplemented;
+    case 503:
+      return Code.Unavailable;
+    case 504:
+      return Code.DeadlineExceeded;
+    default:
+      return status >= 500 ? Code.Internal : Code.Unknown;
+  }
+}
@@ -0,0 +1,262 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// A minimal protobuf codec of the messages of EngineService.Get, so the gRPC-web transport doesn't depend on a
+// protobuf runtime. See api/proto/core/v1alpha1 for the messages.
+
+import { Keys, Scalar, Value } from './values';
+
+export interface GetRequest {
+  uuid: string;
+  selector: string;
+  keys: Keys;
+}
+
+export interface GetResponse {
+  uuid: string;
+  fqn: string;
+  value: Value;
+  timestamp: Date | null;
+  fresh: boolean;
+  /** freshness is the freshness of the feature, in milliseconds. */
+  freshness: number;
+}
+
+const WireVarint = 0;
+const WireFixed64 = 1;
+const WireBytes = 2;
+const WireFixed32 = 5;
+
+class Writer {
+  private readonly chunks: Uint8Array[] = [];
+  private size = 0;
+
+  private push(b: Uint8Array): void {
+    this.chunks.push(b);
+    this.size += b.length;
+  }
+
+  varint(v: number): void {
+    const b: number[] = [];
+    while (v > 0x7f) {
+      b.push((v & 0x7f) | 0x80);
+      v >>>= 7;
+    }
+    b.push(v);
+    this.push(Uint8Array.from(b));
+  }
+
+  bytes(field: number, b: Uint8Array): void {
+    this.varint((field << 3) | WireBytes);
+    this.varint(b.length);
+    this.push(b);
+  }
+
+  string(field: number, s: string): void {
+    if (s !== '') {
+      this.bytes(field, new TextEncoder().encode(s));
+    }
+  }
+
+  finish(): Uint8Array {
+    const ret = new Uint8Array(this.size);
+    let offset = 0;
+    for (const c of this.chunks) {
+      ret.set(c, offset);
+      offset += c.length;
+    }
+    return ret;
+  }
+}
+
+export function encodeGetRequest(req: GetRequest): Uint8Array {
+  const w = new Writer();
+  w.string(1, req.uuid);
+  w.string(2, req.selector);
+  for (const [k, v] of Object.entries(req.keys)) {
+    const entry = new Writer();
+    entry.string(1, k);
+    entry.string(2, v);
+    w.bytes(3, entry.finish());
+  }
+  return w.finish();
+}
+
+type Field =
+  | { field: number; wire: typeof WireVarint; lo: number; hi: number }
+  | { field: number; wire: typeof WireFixed64; view: DataView }
+  | { field: number; wire: typeof WireBytes; bytes: Uint8Array }
+  | { field: number; wire: typeof WireFixed32; view: DataView };
+
+function* fields(b: Uint8Array): Generator<Field> {
+  let pos = 0;
+  const varint = (): [number, number] => {
+    let lo = 0;
+    let hi = 0;
+    for (let shift = 0; shift < 70; shift += 7) {
+      if (pos >= b.length) {
+        throw new Error('truncated message');
+      }
+      const byte = b[pos++];
+      if (shift < 28) {
+        lo |= (byte & 0x7f) << shift;
+      } else if (shift === 28) {
+        lo |= (byte & 0x0f) << 28;
+        hi |= (byte & 0x7f) >> 4;
+      } else {
+        hi |= (byte & 0x7f) << (shift - 32);
+      }
+      if ((byte & 0x80) === 0) {
+        return [lo >>> 0, hi >>> 0];
+      }
+    }
+    throw new Error('malformed varint');
+  };
+  const slice = (n: number): Uint8Array => {
+    if (pos + n > b.length) {
+      throw new Error('truncated message');
+    }
+    const ret = b.subarray(pos, pos + n);
+    pos += n;
+    return ret;
+  };
+
+  while (pos < b.length) {
+    const [tag] = varint();
+    const field = tag >>> 3;
+    const wire = tag & 7;
+    switch (wire) {
+      case WireVarint: {
+        const [lo, hi] = varint();
+        yield { field, wire: WireVarint, lo, hi };
+        break;
+      }
+      case WireFixed64: {
+        const s = slice(8);
+        yield { field, wire: WireFixed64, view: new DataView(s.buffer, s.byteOffset, 8) };
+        break;
+      }
+      case WireBytes: {
+        const [n] = varint();
+        yield { field, wire: WireBytes, bytes: slice(n) };
+        break;
+      }
+      case WireFixed32: {
+        const s = slice(4);
+        yield { field, wire: WireFixed32, view: new DataView(s.buffer, s.byteOffset, 4) };
+        break;
+      }
+      default:
+        throw new Error(`unsupported wire type ${wire}`);
+    }
+  }
+}
+
+const utf8 = new TextDecoder();
+
+function int64(f: { lo: number; hi: number }): number {
+  return (f.hi | 0) * 0x100000000 + f.lo;
+}
+
+// decodeSeconds decodes a google.protobuf.Timestamp or Duration to milliseconds.
+function decodeSeconds(b: Uint8Array): number {
+  let seconds = 0;
+  let nanos = 0;
+  for (const f of fields(b)) {
+    if (f.wire !== WireVarint) {
+      continue;
+    }
+    if (f.field === 1) {
+      seconds = int64(f);
+    } else if (f.field === 2) {
+      nanos = f.lo | 0;
+    }
+  }
+  return seconds * 1000 + nanos / 1e6;
+}
+
+function decodeScalar(b: Uint8Array): Scalar | null {
+  let ret: Scalar | null = null;
+  for (const f of fields(b)) {
+    switch (f.field) {
+      case 1:
+        if (f.wire === WireBytes) ret = utf8.decode(f.bytes);
+        break;
+      case 2:
+        if (f.wire === WireVarint) ret = f.lo | 0;
+        break;
+      case 3:
+        if (f.wire === WireFixed64) ret = f.view.getFloat64(0, true);
+        break;
+      case 4:
+        if (f.wire === WireVarint) ret = f.lo !== 0;
+        break;
+      case 5:
+        if (f.wire === WireBytes) ret = new Date(decodeSeconds(f.bytes));
+        break;
+    }
+  }
+  return ret;
+}
+
+function decodeValue(b: Uint8Array): Value {
+  let ret: Value = null;
+  for (const f of fields(b)) {
+    if (f.wire !== WireBytes) {
+      continue;
+    }
+    if (f.field === 1) {
+      ret = decodeScalar(f.bytes);
+    } else if (f.field === 2) {
+      const list: Scalar[] = [];
+      for (const item of fields(f.bytes)) {
+        if (item.field === 1 && item.wire === WireBytes) {
+          list.push(decodeScalar(item.bytes) as Scalar);
+        }
+      }
+      ret = list;
+    }
+  }
+  return ret;
+}
+
+export function decodeGetResponse(b: Uint8Array): GetResponse {
+  const ret: GetResponse = { uuid: '', fqn: '', value: null, timestamp: null, fresh: false, freshness: 0 };
+  for (const f of fields(b)) {
+    if (f.wire !== WireBytes) {
+      continue;
+    }
+    switch (f.field) {
+      case 1:
+        ret.uuid = utf8.decode(f.bytes);
+        break;
+      case 2: // FeatureValue
+        for (const v of fields(f.bytes)) {
+          if (v.field === 1 && v.wire === WireBytes) ret.fqn = utf8.decode(v.bytes);
+          if (v.field === 3 && v.wire === WireBytes) ret.value = decodeValue(v.bytes);
+          if (v.field === 4 && v.wire === WireBytes) ret.timestamp = new Date(decodeSeconds(v.bytes));
+          if (v.field === 5 && v.wire === WireVarint) ret.fresh = v.lo !== 0;
+        }
+        break;
+      case 3: // FeatureDescriptor
+        for (const d of fields(f.bytes)) {
+          if (d.field === 4 && d.wire === WireBytes) ret.freshness = decodeSeconds(d.bytes);
+        }
+        break;
+    }
+  }
+  return ret;
+}
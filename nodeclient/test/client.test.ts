@@ -0,0 +1,69 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import assert from 'node:assert/strict';
+import { test } from 'node:test';
+
+import { Client, FeatureSet, Transport } from '../src';
+import { decodeGetResponse, encodeGetRequest, GetRequest, GetResponse } from '../src/wire';
+
+test('encodes requests', () => {
+  const b = encodeGetRequest({ uuid: 'u', selector: 'a.b', keys: { k: 'v' } });
+  assert.deepEqual(
+    Array.from(b),
+    [0x0a, 1, 0x75, 0x12, 3, 0x61, 0x2e, 0x62, 0x1a, 6, 0x0a, 1, 0x6b, 0x12, 1, 0x76],
+  );
+});
+
+test('decodes responses', () => {
+  // GetResponse{value: FeatureValue{fqn: "a.b", value: {scalar_value: {int_value: -2}}, fresh: true},
+  //   feature_descriptor: {freshness: 10s}}
+  const scalar = [0x10, 0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01];
+  const value = [0x0a, scalar.length, ...scalar];
+  const fv = [0x0a, 3, 0x61, 0x2e, 0x62, 0x1a, value.length, ...value, 0x28, 1];
+  const fd = [0x22, 2, 0x08, 10];
+  const resp = decodeGetResponse(Uint8Array.from([0x12, fv.length, ...fv, 0x1a, fd.length, ...fd]));
+  assert.equal(resp.fqn, 'a.b');
+  assert.equal(resp.value, -2);
+  assert.equal(resp.fresh, true);
+  assert.equal(resp.freshness, 10000);
+});
+
+test('reads typed vectors and caches fresh values', async () => {
+  interface Vector {
+    clicks: number | null;
+    name: string | null;
+  }
+  const Fraud = new FeatureSet<Vector>('default.fraud', { clicks: 'clicks+count', name: 'auth.name' }, ['id']);
+  assert.deepEqual(Fraud.fields, { clicks: 'default.clicks+count', name: 'auth.name' });
+
+  const calls: GetRequest[] = [];
+  const transport: Transport = {
+    async get(req: GetRequest): Promise<GetResponse> {
+      calls.push(req);
+      const value = req.selector === 'auth.name' ? 'x' : 3;
+      return { uuid: req.uuid, fqn: req.selector, value, timestamp: new Date(), fresh: true, freshness: 60000 };
+    },
+  };
+  const client = new Client({ transport, cacheTtl: 1000, tokenPath: '/nonexistent' });
+  assert.deepEqual(await client.getVector(Fraud, 'e1'), { clicks: 3, name: 'x' });
+  assert.deepEqual(await client.getVector(Fraud, { id: 'e1' }), { clicks: 3, name: 'x' });
+  assert.equal(calls.length, 2);
+  assert.deepEqual(calls[0].keys, { id: 'e1' });
+
+  client.registerFeatureSet('recs', ['clicks+count'], ['user_id', 'item_id']);
+  await assert.rejects(client.getVector('recs', 'u1'));
+});
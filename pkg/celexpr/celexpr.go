@@ -0,0 +1,227 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package celexpr compiles and evaluates the CEL (Common Expression Language) expressions of the `cel` builder.
+//
+// An expression calculates the feature value from each incoming event:
+//
+//	row.amount * 1.17
+//	row.status == 'approved' ? row.amount : null
+//
+// The expressions can access the following variables:
+//   - `row` is the (mapped) event. i.e. `row.user.country`
+//   - `keys` are the keys of the feature, extracted from the event.
+//   - `timestamp` is the timestamp of the event.
+//
+// Expressions that evaluate to `null` are skipped, so they can be used to filter events.
+// The evaluation is sandboxed, and its cost is limited.
+package celexpr
+
+import (
+	"fmt"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/ext"
+	"github.com/raptor-ml/raptor/api"
+	"sync"
+	"time"
+)
+
+// costLimit limits the evaluation cost of a single expression.
+const costLimit = 100_000
+
+var env = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("row", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("keys", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("timestamp", cel.TimestampType),
+		ext.Strings(),
+		ext.Math(),
+		ext.Lists(),
+	)
+})
+
+// Program is a compiled expression.
+type Program struct {
+	expr string
+	typ  api.PrimitiveType
+	prg  cel.Program
+}
+
+// Compile compiles and type-checks an expression.
+func Compile(expr string) (*Program, error) {
+	e, err := env()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cel environment: %w", err)
+	}
+	ast, iss := e.Compile(expr)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("failed to compile cel: %w", iss.Err())
+	}
+	prg, err := e.Program(ast, cel.CostLimit(costLimit), cel.EvalOptions(cel.OptOptimize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile cel: %w", err)
+	}
+	return &Program{expr: expr, typ: primitiveOf(ast.OutputType()), prg: prg}, nil
+}
+
+// String returns the original expression.
+func (p *Program) String() string {
+	return p.expr
+}
+
+// Type returns the statically inferred type of the expression, or PrimitiveTypeUnknown if it depends on the types of
+// the event's fields.
+func (p *Program) Type() api.PrimitiveType {
+	return p.typ
+}
+
+// Validate checks that the expression can build a feature with the given descriptor.
+func (p *Program) Validate(fd api.FeatureDescriptor) error {
+	t := p.typ
+	if t == api.PrimitiveTypeUnknown {
+		return nil
+	}
+	target := fd.Primitive
+	if t == target ||
+		(t == api.PrimitiveTypeInteger && target == api.PrimitiveTypeFloat) ||
+		(t == api.PrimitiveTypeIntegerList && target == api.PrimitiveTypeFloatList) {
+		return nil
+	}
+	return fmt.Errorf("expression type `%s` is incompatible with the feature primitive `%s`", t, target)
+}
+
+// Eval evaluates the expression on an event. It returns false if the expression evaluates to `null`.
+func (p *Program) Eval(row map[string]any, keys api.Keys, ts time.Time) (any, bool, error) {
+	out, _, err := p.prg.Eval(map[string]any{
+		"row":       row,
+		"keys":      map[string]string(keys),
+		"timestamp": ts,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to evaluate cel: %w", err)
+	}
+	if out.Type() == types.NullType {
+		return nil, false, nil
+	}
+	return native(out), true, nil
+}
+
+// Convert converts an evaluated value to the given primitive.
+func Convert(v any, primitive api.PrimitiveType) (any, error) {
+	if primitive.Scalar() {
+		return convertScalar(v, primitive)
+	}
+
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a list for primitive `%s`, got `%T`", primitive, v)
+	}
+	if len(items) == 0 {
+		return primitive.Interface(), nil
+	}
+	ret := make([]any, len(items))
+	for i, item := range items {
+		s, err := convertScalar(item, primitive.Singular())
+		if err != nil {
+			return nil, fmt.Errorf("item #%d: %w", i, err)
+		}
+		ret[i] = s
+	}
+	return api.NormalizeAny(ret)
+}
+
+func convertScalar(v any, primitive api.PrimitiveType) (any, error) {
+	switch primitive {
+	case api.PrimitiveTypeFloat:
+		switch v := v.(type) {
+		case int:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+	case api.PrimitiveTypeInteger:
+		switch v := v.(type) {
+		case int:
+			return v, nil
+		case float64:
+			if v == float64(int(v)) {
+				return int(v), nil
+			}
+			return nil, fmt.Errorf("value `%v` is not an integer", v)
+		}
+	case api.PrimitiveTypeString:
+		return api.ScalarString(v), nil
+	}
+
+	if s, ok := v.(string); ok {
+		return api.ScalarFromString(s, primitive)
+	}
+	if api.TypeDetect(v) == primitive {
+		return v, nil
+	}
+	return nil, fmt.Errorf("value `%v` cannot be converted to `%s`", v, primitive)
+}
+
+// native converts a CEL value to a Go value that is supported by the runtime.
+func native(v ref.Val) any {
+	switch v := v.(type) {
+	case types.Int:
+		return int(v)
+	case types.Uint:
+		return int(v)
+	case types.Double:
+		return float64(v)
+	case types.String:
+		return string(v)
+	case types.Bool:
+		return bool(v)
+	case types.Timestamp:
+		return v.Time
+	case types.Duration:
+		return v.Duration.String()
+	case traits.Lister:
+		var ret []any
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			ret = append(ret, native(it.Next()))
+		}
+		return ret
+	default:
+		return v.Value()
+	}
+}
+
+func primitiveOf(t *cel.Type) api.PrimitiveType {
+	switch {
+	case t.IsExactType(cel.IntType), t.IsExactType(cel.UintType):
+		return api.PrimitiveTypeInteger
+	case t.IsExactType(cel.DoubleType):
+		return api.PrimitiveTypeFloat
+	case t.IsExactType(cel.StringType):
+		return api.PrimitiveTypeString
+	case t.IsExactType(cel.BoolType):
+		return api.PrimitiveTypeBoolean
+	case t.IsExactType(cel.TimestampType):
+		return api.PrimitiveTypeTimestamp
+	case t.Kind() == types.ListKind && len(t.Parameters()) == 1:
+		if p := primitiveOf(t.Parameters()[0]); p != api.PrimitiveTypeUnknown && p.Scalar() {
+			return p.Plural()
+		}
+	}
+	return api.PrimitiveTypeUnknown
+}
@@ -0,0 +1,95 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"github.com/raptor-ml/raptor/api"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	value   api.Value
+	expires time.Time
+}
+
+// cache is a local cache of values with a TTL. When it's full, the expired entries are evicted, and if none has
+// expired, an arbitrary entry is.
+type cache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	size    int
+	entries map[string]cacheEntry
+}
+
+func newCache(ttl time.Duration, size int) *cache {
+	return &cache{ttl: ttl, size: size, entries: make(map[string]cacheEntry, size)}
+}
+
+func cacheKey(selector string, keys api.Keys) string {
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	sb := strings.Builder{}
+	sb.WriteString(selector)
+	for _, k := range names {
+		sb.WriteByte(0)
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(keys[k])
+	}
+	return sb.String()
+}
+
+func (c *cache) get(selector string, keys api.Keys) (api.Value, bool) {
+	c.mu.RLock()
+	e, ok := c.entries[cacheKey(selector, keys)]
+	c.mu.RUnlock()
+	if !ok || time.Now().After(e.expires) {
+		return api.Value{}, false
+	}
+	return e.value, true
+}
+
+func (c *cache) set(selector string, keys api.Keys, v api.Value) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.size {
+		c.evict(now)
+	}
+	c.entries[cacheKey(selector, keys)] = cacheEntry{value: v, expires: now.Add(c.ttl)}
+}
+
+func (c *cache) evict(now time.Time) {
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	for k := range c.entries {
+		if len(c.entries) < c.size {
+			return
+		}
+		delete(c.entries, k)
+	}
+}
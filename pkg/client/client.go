@@ -0,0 +1,220 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package client is a Go client of the Core's serving API, for model servers (and other Go services) that read the
+// values of features.
+//
+// The requests are spread over a pool of gRPC connections, retried when the Core is unavailable, and their values
+// can be cached locally for a short time. Typed structs of the feature sets of Models, and functions to fetch them,
+// are generated from the manifests by `raptorctl codegen` (see the gen package).
+package client
+
+import (
+	"context"
+	"fmt"
+	grpcMiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpcRetry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
+	"github.com/raptor-ml/raptor/api"
+	coreApi "github.com/raptor-ml/raptor/api/proto/gen/go/core/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/protocol"
+	"github.com/raptor-ml/raptor/pkg/sdk"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultAddress is the address of the Core's gRPC accessor within the cluster.
+const DefaultAddress = "raptor-core-service.raptor-system:60000"
+
+type config struct {
+	poolSize    int
+	retries     uint
+	backoff     time.Duration
+	cacheTTL    time.Duration
+	cacheSize   int
+	creds       credentials.TransportCredentials
+	perRPCCreds credentials.PerRPCCredentials
+	dialOptions []grpc.DialOption
+}
+
+// Option configures the Client.
+type Option func(*config)
+
+// WithPoolSize sets the number of connections to the Core. Defaults to 4.
+func WithPoolSize(n int) Option {
+	return func(c *config) {
+		c.poolSize = n
+	}
+}
+
+// WithRetries sets the number of retries of a request when the Core is unavailable, and the (exponential) backoff
+// between them. Defaults to 3 retries with a 50ms backoff.
+func WithRetries(n uint, backoff time.Duration) Option {
+	return func(c *config) {
+		c.retries = n
+		c.backoff = backoff
+	}
+}
+
+// WithCache caches up to `size` values locally for the TTL, so frequent reads of the same entity don't reach the
+// Core. The TTL should be shorter than the freshness of the features. Disabled by default.
+func WithCache(ttl time.Duration, size int) Option {
+	return func(c *config) {
+		c.cacheTTL = ttl
+		c.cacheSize = size
+	}
+}
+
+// WithTransportCredentials secures the connections to the Core (i.e. with mTLS). Defaults to insecure connections.
+func WithTransportCredentials(creds credentials.TransportCredentials) Option {
+	return func(c *config) {
+		c.creds = creds
+	}
+}
+
+// WithPerRPCCredentials authenticates the requests to the Core, i.e. with sdk.ServiceAccountCredentials when the
+// Core's authentication is enabled.
+func WithPerRPCCredentials(creds credentials.PerRPCCredentials) Option {
+	return func(c *config) {
+		c.perRPCCreds = creds
+	}
+}
+
+// WithDialOptions appends gRPC dial options to the connections to the Core.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *config) {
+		c.dialOptions = append(c.dialOptions, opts...)
+	}
+}
+
+// Client reads the values of features from the Core. It's safe for concurrent use.
+type Client struct {
+	conns   []*grpc.ClientConn
+	engines []api.Engine
+	next    atomic.Uint64
+	cache   *cache
+}
+
+// New connects to the Core's gRPC accessor (i.e. DefaultAddress).
+func New(addr string, opts ...Option) (*Client, error) {
+	cfg := &config{
+		poolSize: 4,
+		retries:  3,
+		backoff:  50 * time.Millisecond,
+		creds:    insecure.NewCredentials(),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.poolSize < 1 {
+		return nil, fmt.Errorf("the pool size must be positive")
+	}
+
+	session := protocol.NewSession(protocol.Local())
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(cfg.creds),
+		grpc.WithUnaryInterceptor(grpcMiddleware.ChainUnaryClient(
+			session.UnaryClientInterceptor(),
+			grpcRetry.UnaryClientInterceptor(
+				grpcRetry.WithMax(cfg.retries),
+				grpcRetry.WithBackoff(grpcRetry.BackoffExponentialWithJitter(cfg.backoff, 0.1)),
+				grpcRetry.WithCodes(codes.Unavailable, codes.ResourceExhausted, codes.Aborted),
+			),
+		)),
+	}
+	if cfg.perRPCCreds != nil {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(cfg.perRPCCreds))
+	}
+	dialOpts = append(dialOpts, cfg.dialOptions...)
+
+	c := &Client{}
+	for i := 0; i < cfg.poolSize; i++ {
+		cc, err := grpc.Dial(addr, dialOpts...)
+		if err != nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("failed to connect to the Core: %w", err)
+		}
+		c.conns = append(c.conns, cc)
+		c.engines = append(c.engines, sdk.NewGRPCEngine(coreApi.NewEngineServiceClient(cc)))
+	}
+	if cfg.cacheTTL > 0 && cfg.cacheSize > 0 {
+		c.cache = newCache(cfg.cacheTTL, cfg.cacheSize)
+	}
+	return c, nil
+}
+
+// Close closes the connections to the Core.
+func (c *Client) Close() error {
+	var err error
+	for _, cc := range c.conns {
+		if cerr := cc.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// engine returns the engine of the next connection of the pool.
+func (c *Client) engine() api.Engine {
+	return c.engines[c.next.Add(1)%uint64(len(c.engines))]
+}
+
+// Get returns the value of the feature (or the prediction of the model) for the entity. The selector can include an
+// aggregation function of a windowed feature (i.e. `name.namespace+sum`). On-demand features are computed from the
+// request context of ctx (see api.WithRequestContext).
+func (c *Client) Get(ctx context.Context, selector string, keys api.Keys) (api.Value, error) {
+	// values that are computed from the request context aren't cached, since they depend on it
+	cacheable := c.cache != nil && len(api.RequestContextFromContext(ctx)) == 0
+	if cacheable {
+		if v, ok := c.cache.get(selector, keys); ok {
+			return v, nil
+		}
+	}
+	v, _, err := c.engine().Get(ctx, selector, keys)
+	if err != nil {
+		return api.Value{}, fmt.Errorf("failed to get %s: %w", selector, err)
+	}
+	if cacheable {
+		c.cache.set(selector, keys, v)
+	}
+	return v, nil
+}
+
+// GetMany returns the values of the features for the entity, in the order of the selectors. The values are read
+// concurrently.
+func (c *Client) GetMany(ctx context.Context, selectors []string, keys api.Keys) ([]api.Value, error) {
+	ret := make([]api.Value, len(selectors))
+	errs := make([]error, len(selectors))
+	wg := sync.WaitGroup{}
+	for i, selector := range selectors {
+		wg.Add(1)
+		go func(i int, selector string) {
+			defer wg.Done()
+			ret[i], errs[i] = c.Get(ctx, selector, keys)
+		}(i, selector)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/raptor-ml/raptor/api"
+)
+
+type fakeEngine struct {
+	api.Engine
+	gets atomic.Int32
+}
+
+func (e *fakeEngine) Get(_ context.Context, selector string, _ api.Keys) (api.Value, api.FeatureDescriptor, error) {
+	e.gets.Add(1)
+	switch selector {
+	case "default.tags":
+		return api.Value{Value: []any{"a", "b"}}, api.FeatureDescriptor{}, nil
+	case "default.clicks+count":
+		return api.Value{Value: 3}, api.FeatureDescriptor{}, nil
+	default:
+		return api.Value{Value: selector}, api.FeatureDescriptor{}, nil
+	}
+}
+
+func TestAs(t *testing.T) {
+	if v, err := As[[]string]([]any{"a", "b"}); err != nil || !reflect.DeepEqual(v, []string{"a", "b"}) {
+		t.Errorf("unexpected list conversion: %v, %v", v, err)
+	}
+	if v, err := As[float64](3); err != nil || v != 3 {
+		t.Errorf("unexpected int to float conversion: %v, %v", v, err)
+	}
+	if v, err := As[time.Time](nil); err != nil || !v.IsZero() {
+		t.Errorf("expected the zero value for a missing value: %v, %v", v, err)
+	}
+	if _, err := As[int]("3"); err == nil {
+		t.Error("expected an error for a string value of an int field")
+	}
+	if _, err := As[[]int]([]any{1, "2"}); err == nil {
+		t.Error("expected an error for a list with a string item")
+	}
+}
+
+func TestClient_GetMany(t *testing.T) {
+	e := &fakeEngine{}
+	c := &Client{engines: []api.Engine{e, e}, cache: newCache(time.Minute, 10)}
+
+	selectors := []string{"default.name", "default.tags", "default.clicks+count"}
+	for i := 0; i < 2; i++ {
+		vals, err := c.GetMany(context.Background(), selectors, api.Keys{"user_id": "1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tags []string
+		var clicks float64
+		if err := Assign(&tags, selectors[1], vals[1]); err != nil || len(tags) != 2 {
+			t.Errorf("unexpected tags: %v, %v", tags, err)
+		}
+		if err := Assign(&clicks, selectors[2], vals[2]); err != nil || clicks != 3 {
+			t.Errorf("unexpected clicks: %v, %v", clicks, err)
+		}
+	}
+	if n := e.gets.Load(); n != 3 {
+		t.Errorf("expected the second read to be cached, got %d requests", n)
+	}
+
+	// values of other entities and of requests with a request context aren't cached
+	if _, err := c.Get(context.Background(), "default.name", api.Keys{"user_id": "2"}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := api.WithRequestContext(context.Background(), map[string]any{"cart": 1})
+	if _, err := c.Get(ctx, "default.name", api.Keys{"user_id": "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := e.gets.Load(); n != 5 {
+		t.Errorf("expected 5 requests, got %d", n)
+	}
+}
+
+func TestCache_Evict(t *testing.T) {
+	c := newCache(time.Minute, 2)
+	for _, id := range []string{"1", "2", "3"} {
+		c.set("default.name", api.Keys{"user_id": id}, api.Value{Value: id})
+	}
+	if len(c.entries) != 2 {
+		t.Errorf("expected the cache to be bounded to 2 entries, got %d", len(c.entries))
+	}
+	if _, ok := c.get("default.name", api.Keys{"user_id": "3"}); !ok {
+		t.Error("expected the latest value to be cached")
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"time"
+)
+
+// Primitive is the Go type of a feature value, by the feature's PrimitiveType.
+type Primitive interface {
+	string | int | float64 | bool | time.Time | []string | []int | []float64 | []bool | []time.Time
+}
+
+// As converts a value that was read from the Core to the Go type of the feature. Lists are read as `[]any`, and are
+// converted to a typed slice. Missing values are converted to the zero value.
+func As[T Primitive](v any) (T, error) {
+	var ret T
+	if v == nil {
+		return ret, nil
+	}
+	if t, ok := v.(T); ok {
+		return t, nil
+	}
+
+	switch p := any(&ret).(type) {
+	case *float64:
+		// integer values of float features (i.e. counts of windows)
+		if i, ok := v.(int); ok {
+			*p = float64(i)
+			return ret, nil
+		}
+	case *[]string:
+		return ret, convertList(v, p)
+	case *[]int:
+		return ret, convertList(v, p)
+	case *[]float64:
+		return ret, convertList(v, p)
+	case *[]bool:
+		return ret, convertList(v, p)
+	case *[]time.Time:
+		return ret, convertList(v, p)
+	}
+	return ret, fmt.Errorf("%w: %T can't be read as %T", api.ErrUnsupportedPrimitiveError, v, ret)
+}
+
+func convertList[E string | int | float64 | bool | time.Time](v any, dst *[]E) error {
+	list, ok := v.([]any)
+	if !ok {
+		return fmt.Errorf("%w: %T can't be read as %T", api.ErrUnsupportedPrimitiveError, v, *dst)
+	}
+	ret := make([]E, len(list))
+	for i, item := range list {
+		e, err := As[E](item)
+		if err != nil {
+			return err
+		}
+		ret[i] = e
+	}
+	*dst = ret
+	return nil
+}
+
+// Assign converts the value of the selector to the Go type of the field, and assigns it. It's used by the generated
+// feature sets.
+func Assign[T Primitive](field *T, selector string, v api.Value) error {
+	t, err := As[T](v.Value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", selector, err)
+	}
+	*field = t
+	return nil
+}
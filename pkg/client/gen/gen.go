@@ -0,0 +1,407 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gen generates typed Go structs of the feature sets of Models, and functions to fetch them with the client,
+// or their TypeScript bindings for the Node client.
+//
+// Each field of a feature set is typed by the PrimitiveType of its feature: windowed features are read per
+// aggregation function (as float64, or []string for top-K windows), and a windowed feature that is referenced without
+// an aggregation function is expanded to a field per aggregation function. The features are resolved from the
+// Feature manifests that are generated with the Models.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"go/format"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// DefaultNamespace is the namespace of manifests without a namespace.
+const DefaultNamespace = "default"
+
+// Field is a field of a generated feature set.
+type Field struct {
+	Name     string
+	Type     string
+	TSType   string
+	Selector string
+	// Primitive is false for fields that can't be typed (i.e. their feature's manifest wasn't given).
+	Primitive bool
+}
+
+// FeatureSet is a generated feature set of a Model.
+type FeatureSet struct {
+	Name   string
+	FQN    string
+	Keys   []string
+	Fields []Field
+}
+
+// Generate generates the Go source of the feature sets of the Models among the manifests, in the package.
+func Generate(pkg string, objs []*unstructured.Unstructured) ([]byte, error) {
+	sets, err := featureSets(objs)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.Buffer{}
+	if err := tpl.Execute(&buf, map[string]any{"Package": pkg, "FeatureSets": sets}); err != nil {
+		return nil, fmt.Errorf("failed to execute the template: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the generated source: %w", err)
+	}
+	return src, nil
+}
+
+// GenerateTypeScript generates the TypeScript bindings of the feature sets of the Models among the manifests, for the
+// Node client (@raptor-ml/client).
+func GenerateTypeScript(objs []*unstructured.Unstructured) ([]byte, error) {
+	sets, err := featureSets(objs)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.Buffer{}
+	if err := tsTpl.Execute(&buf, sets); err != nil {
+		return nil, fmt.Errorf("failed to execute the template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// featureSets returns the feature sets of the Models among the manifests, ordered by their FQN.
+func featureSets(objs []*unstructured.Unstructured) ([]FeatureSet, error) {
+	var models []*manifests.Model
+	features := make(map[string]*manifests.Feature)
+	for _, u := range objs {
+		if u.GetNamespace() == "" {
+			u.SetNamespace(DefaultNamespace)
+		}
+		switch u.GroupVersionKind().GroupKind() {
+		case manifests.GroupVersion.WithKind("Model").GroupKind():
+			m := &manifests.Model{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, m); err != nil {
+				return nil, fmt.Errorf("invalid Model %s/%s: %w", u.GetNamespace(), u.GetName(), err)
+			}
+			models = append(models, m)
+		case manifests.GroupVersion.WithKind("Feature").GroupKind():
+			ft := &manifests.Feature{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, ft); err != nil {
+				return nil, fmt.Errorf("invalid Feature %s/%s: %w", u.GetNamespace(), u.GetName(), err)
+			}
+			features[ft.FQN()] = ft
+		}
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no Models were found in the manifests")
+	}
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].FQN() < models[j].FQN()
+	})
+
+	names := map[string]bool{}
+	sets := make([]FeatureSet, 0, len(models))
+	for _, m := range models {
+		fs, err := featureSet(m, features)
+		if err != nil {
+			return nil, fmt.Errorf("model %s: %w", m.FQN(), err)
+		}
+		fs.Name = unique(names, identifier(m.GetName()), identifier(m.GetNamespace())+identifier(m.GetName()))
+		sets = append(sets, fs)
+	}
+	return sets, nil
+}
+
+func featureSet(m *manifests.Model, features map[string]*manifests.Feature) (FeatureSet, error) {
+	fs := FeatureSet{FQN: m.FQN(), Keys: m.Spec.Keys}
+	names := map[string]bool{}
+	for _, sel := range m.Spec.Features {
+		ns, name, aggrFn, _, _, err := api.ParseSelector(sel)
+		if err != nil {
+			return fs, err
+		}
+		if ns == "" {
+			ns = m.GetNamespace()
+		}
+		fqn, err := api.NormalizeFQN(sel, m.GetNamespace())
+		if err != nil {
+			return fs, err
+		}
+		selector, err := api.NormalizeSelector(sel, m.GetNamespace())
+		if err != nil {
+			return fs, err
+		}
+
+		field := func(fn api.AggrFn, typ, tsType string, primitive bool) Field {
+			suffix, s := "", selector
+			if fn != api.AggrFnUnknown {
+				suffix = identifier(fn.String())
+				s = strings.SplitN(selector, "+", 2)[0] + "+" + fn.String()
+			}
+			return Field{
+				Name:      unique(names, identifier(name)+suffix, identifier(ns)+identifier(name)+suffix),
+				Type:      typ,
+				TSType:    tsType,
+				Selector:  s,
+				Primitive: primitive,
+			}
+		}
+
+		ft, ok := features[fqn]
+		if !ok {
+			// the feature is defined elsewhere (i.e. it already exists in the cluster), so its type is unknown
+			fs.Fields = append(fs.Fields, field(aggrFn, "any", "unknown", false))
+			continue
+		}
+		aggrs, err := api.StringsToAggrFns(aggrNames(ft.Spec.Builder.Aggr))
+		if err != nil {
+			return fs, fmt.Errorf("feature %s: %w", fqn, err)
+		}
+		switch {
+		case aggrFn != api.AggrFnUnknown:
+			fs.Fields = append(fs.Fields, field(aggrFn, aggrType(aggrFn), tsAggrType(aggrFn), true))
+		case len(aggrs) > 0:
+			for _, fn := range aggrs {
+				fs.Fields = append(fs.Fields, field(fn, aggrType(fn), tsAggrType(fn), true))
+			}
+		default:
+			p := api.StringToPrimitiveType(string(ft.Spec.Primitive))
+			typ, err := goType(p)
+			if err != nil {
+				return fs, fmt.Errorf("feature %s: %w", fqn, err)
+			}
+			fs.Fields = append(fs.Fields, field(api.AggrFnUnknown, typ, tsType(p), true))
+		}
+	}
+	return fs, nil
+}
+
+func aggrNames(fns []manifests.AggrFn) []string {
+	ret := make([]string, len(fns))
+	for i, fn := range fns {
+		ret[i] = string(fn)
+	}
+	return ret
+}
+
+// aggrType returns the Go type of an aggregated value of a window.
+func aggrType(fn api.AggrFn) string {
+	if fn == api.AggrFnTopK {
+		return "[]string"
+	}
+	return "float64"
+}
+
+// goType returns the Go type of the PrimitiveType, as it's read by the client.
+func goType(p api.PrimitiveType) (string, error) {
+	switch p {
+	case api.PrimitiveTypeString, api.PrimitiveTypeInteger, api.PrimitiveTypeBoolean,
+		api.PrimitiveTypeStringList, api.PrimitiveTypeIntegerList, api.PrimitiveTypeBooleanList:
+		return p.String(), nil
+	case api.PrimitiveTypeFloat:
+		return "float64", nil
+	case api.PrimitiveTypeTimestamp:
+		return "time.Time", nil
+	case api.PrimitiveTypeFloatList:
+		return "[]float64", nil
+	case api.PrimitiveTypeTimestampList:
+		return "[]time.Time", nil
+	default:
+		return "", api.ErrUnsupportedPrimitiveError
+	}
+}
+
+// tsAggrType returns the TypeScript type of an aggregated value of a window.
+func tsAggrType(fn api.AggrFn) string {
+	if fn == api.AggrFnTopK {
+		return "string[]"
+	}
+	return "number"
+}
+
+// tsType returns the TypeScript type of the PrimitiveType, as it's read by the Node client.
+func tsType(p api.PrimitiveType) string {
+	var scalar string
+	switch p.Singular() {
+	case api.PrimitiveTypeString:
+		scalar = "string"
+	case api.PrimitiveTypeInteger, api.PrimitiveTypeFloat:
+		scalar = "number"
+	case api.PrimitiveTypeBoolean:
+		scalar = "boolean"
+	case api.PrimitiveTypeTimestamp:
+		scalar = "Date"
+	default:
+		return "unknown"
+	}
+	if p.Scalar() {
+		return scalar
+	}
+	return scalar + "[]"
+}
+
+// identifier returns an exported Go identifier of a (snake-case or kebab-case) name.
+func identifier(name string) string {
+	sb := strings.Builder{}
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	ret := sb.String()
+	if ret == "" || unicode.IsDigit(rune(ret[0])) {
+		ret = "F" + ret
+	}
+	return ret
+}
+
+// unique returns the first candidate that wasn't used yet, or the last one with a numeric suffix.
+func unique(used map[string]bool, candidates ...string) string {
+	for _, c := range candidates {
+		if !used[c] {
+			used[c] = true
+			return c
+		}
+	}
+	last := candidates[len(candidates)-1]
+	for i := 2; ; i++ {
+		c := fmt.Sprintf("%s%d", last, i)
+		if !used[c] {
+			used[c] = true
+			return c
+		}
+	}
+}
+
+func (fs FeatureSet) usesTime() bool {
+	for _, f := range fs.Fields {
+		if strings.Contains(f.Type, "time.Time") {
+			return true
+		}
+	}
+	return false
+}
+
+// tsIdentifier returns the lower camel-case identifier of TypeScript properties of an exported Go identifier.
+func tsIdentifier(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+var tpl = template.Must(template.New("featuresets").Funcs(template.FuncMap{
+	"usesTime": func(sets []FeatureSet) bool {
+		for _, fs := range sets {
+			if fs.usesTime() {
+				return true
+			}
+		}
+		return false
+	},
+}).Parse(`// Code generated by raptorctl codegen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"context"
+	"errors"
+{{- if usesTime .FeatureSets }}
+	"time"
+{{- end }}
+
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/client"
+)
+{{ range .FeatureSets }}
+// {{ .Name }} is the feature set of the ` + "`{{ .FQN }}`" + ` Model.
+type {{ .Name }} struct {
+{{- range .Fields }}
+	// {{ .Name }} is the value of ` + "`{{ .Selector }}`" + `.
+	{{ .Name }} {{ .Type }}
+{{- end }}
+}
+
+// {{ .Name }}Selectors are the selectors of the fields of {{ .Name }}.
+var {{ .Name }}Selectors = []string{
+{{- range .Fields }}
+	{{ printf "%q" .Selector }},
+{{- end }}
+}
+
+// {{ .Name }}Keys are the keys of the entities of {{ .Name }}.
+var {{ .Name }}Keys = []string{ {{- range $i, $k := .Keys }}{{ if $i }}, {{ end }}{{ printf "%q" $k }}{{ end -}} }
+
+// Get{{ .Name }} fetches the feature set {{ .Name }} of the entity.
+func Get{{ .Name }}(ctx context.Context, c *client.Client, keys api.Keys) (*{{ .Name }}, error) {
+	vals, err := c.GetMany(ctx, {{ .Name }}Selectors, keys)
+	if err != nil {
+		return nil, err
+	}
+	ret := &{{ .Name }}{}
+{{- range $i, $f := .Fields }}
+{{- if not $f.Primitive }}
+	ret.{{ $f.Name }} = vals[{{ $i }}].Value
+{{- end }}
+{{- end }}
+	return ret, errors.Join(
+{{- range $i, $f := .Fields }}
+{{- if $f.Primitive }}
+		client.Assign(&ret.{{ $f.Name }}, {{ printf "%q" $f.Selector }}, vals[{{ $i }}]),
+{{- end }}
+{{- end }}
+	)
+}
+{{ end }}`))
+
+var tsTpl = template.Must(template.New("featuresets.ts").Funcs(template.FuncMap{
+	"prop":  tsIdentifier,
+	"quote": func(s string) string { return "'" + strings.ReplaceAll(s, "'", "\\'") + "'" },
+}).Parse(`// Code generated by raptorctl codegen. DO NOT EDIT.
+
+import { FeatureSet } from '@raptor-ml/client';
+{{ range . }}
+/** {{ .Name }} is the feature set of the ` + "`{{ .FQN }}`" + ` Model. */
+export interface {{ .Name }} {
+{{- range .Fields }}
+  /** The value of ` + "`{{ .Selector }}`" + `. */
+  {{ prop .Name }}: {{ .TSType }}{{ if .Primitive }} | null{{ end }};
+{{- end }}
+}
+
+export const {{ .Name }} = new FeatureSet<{{ .Name }}>(
+  {{ quote .FQN }},
+  {
+{{- range .Fields }}
+    {{ prop .Name }}: {{ quote .Selector }},
+{{- end }}
+  },
+  [{{ range $i, $k := .Keys }}{{ if $i }}, {{ end }}{{ quote $k }}{{ end }}],
+);
+{{ end }}`))
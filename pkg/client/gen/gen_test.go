@@ -0,0 +1,150 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/raptor-ml/raptor/internal/plan"
+)
+
+const manifestsYAML = `
+apiVersion: k8s.raptor.ml/v1alpha1
+kind: Feature
+metadata:
+  name: hello-world
+spec:
+  primitive: string
+  freshness: 1h
+  staleness: 2h
+  keys:
+    - name
+  builder:
+    code: |-
+      def handler(row, ctx) -> str:
+         return "Hello world " + ctx.keys["name"]
+---
+apiVersion: k8s.raptor.ml/v1alpha1
+kind: Feature
+metadata:
+  name: simple-aggr
+spec:
+  primitive: int
+  freshness: 10s
+  staleness: 1m
+  keys:
+    - client_id
+  builder:
+    aggrGranularity: 10s
+    aggr:
+      - sum
+      - count
+    code: |
+      def handler(data, ctx) -> int:
+        return 1
+---
+apiVersion: k8s.raptor.ml/v1alpha1
+kind: Model
+metadata:
+  name: model-basic
+spec:
+  freshness: 1h
+  staleness: 1h
+  features:
+    - hello_world
+    - simple_aggr
+    - simple_aggr+sum
+    - auth.last_login
+  keys:
+    - name
+    - client_id
+  modelServer: sagemaker-ack
+  modelFramework: sklearn
+  modelFrameworkVersion: 1.0-1
+`
+
+func TestGenerate(t *testing.T) {
+	objs, err := plan.DecodeManifests(strings.NewReader(manifestsYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := Generate("features", objs)
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+
+	for _, want := range []string{
+		"package features",
+		"type ModelBasic struct {",
+		"\tHelloWorld string\n",
+		"\tSimpleAggrSum float64\n",
+		"\tSimpleAggrCount float64\n",
+		// the explicit selector collides with the expanded field
+		"\tDefaultSimpleAggrSum float64\n",
+		"\tLastLogin any\n",
+		`"default.hello_world",`,
+		`"default.simple_aggr+count",`,
+		`var ModelBasicKeys = []string{"name", "client_id"}`,
+		`client.Assign(&ret.HelloWorld, "default.hello_world", vals[0]),`,
+		"ret.LastLogin = vals[4].Value",
+		"func GetModelBasic(ctx context.Context, c *client.Client, keys api.Keys) (*ModelBasic, error) {",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("the generated source doesn't contain %q:\n%s", want, src)
+		}
+	}
+	if strings.Contains(string(src), `"time"`) {
+		t.Errorf("the generated source imports time without timestamp fields:\n%s", src)
+	}
+}
+
+func TestGenerate_NoModels(t *testing.T) {
+	objs, err := plan.DecodeManifests(strings.NewReader(strings.SplitN(manifestsYAML, "---", 2)[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate("features", objs); err == nil {
+		t.Fatal("expected an error for manifests without Models")
+	}
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	objs, err := plan.DecodeManifests(strings.NewReader(manifestsYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := GenerateTypeScript(objs)
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+
+	for _, want := range []string{
+		"import { FeatureSet } from '@raptor-ml/client';",
+		"export interface ModelBasic {",
+		"  helloWorld: string | null;\n",
+		"  simpleAggrCount: number | null;\n",
+		"  lastLogin: unknown;\n",
+		"export const ModelBasic = new FeatureSet<ModelBasic>(\n  'default.model_basic',",
+		"    simpleAggrSum: 'default.simple_aggr+sum',\n",
+		"  ['name', 'client_id'],\n",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("the generated source doesn't contain %q:\n%s", want, src)
+		}
+	}
+}
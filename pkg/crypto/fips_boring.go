@@ -0,0 +1,33 @@
+//go:build boringcrypto
+
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import (
+	"crypto/boring"
+	// restrict TLS to FIPS-approved settings
+	_ "crypto/tls/fipsonly"
+)
+
+func init() {
+	if !boring.Enabled() {
+		return
+	}
+	// With BoringCrypto enabled, the standard library primitives are served by the FIPS 140-validated module.
+	Register(stdProvider{name: FIPSProviderName, fips: true})
+}
@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package crypto abstracts the cryptographic primitives that are used by Raptor (signatures and encryption-at-rest).
+// Code that needs to sign or encrypt data should use the Default() Provider instead of using the standard library
+// directly, so deployments can switch to a FIPS-compliant implementation.
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// FIPSProviderName is the name of the FIPS-compliant provider.
+// It's available only in builds that were built with `GOEXPERIMENT=boringcrypto` (see `make build-fips`).
+const FIPSProviderName = "fips"
+
+// ErrDecryption is returned when a ciphertext cannot be decrypted (wrong key, tampered or truncated data).
+var ErrDecryption = errors.New("failed to decrypt")
+
+// ErrInvalidKey is returned when the given key doesn't match the key size required by the provider.
+var ErrInvalidKey = errors.New("invalid key")
+
+// Provider implements the cryptographic primitives.
+type Provider interface {
+	// Name is the name of the provider.
+	Name() string
+	// FIPS indicates if the provider is backed by a FIPS 140-validated module.
+	FIPS() bool
+
+	// Sign returns the HMAC signature of the message.
+	Sign(key, msg []byte) []byte
+	// Verify checks (in constant time) that the signature matches the message.
+	Verify(key, msg, sig []byte) bool
+
+	// KeySize is the size (in bytes) of the keys for Encrypt and Decrypt.
+	KeySize() int
+	// Encrypt encrypts and authenticates the plaintext and the additional data.
+	// The nonce is prepended to the returned ciphertext.
+	Encrypt(key, plaintext, additionalData []byte) ([]byte, error)
+	// Decrypt decrypts a ciphertext that was produced by Encrypt.
+	Decrypt(key, ciphertext, additionalData []byte) ([]byte, error)
+}
+
+var (
+	mu        sync.RWMutex
+	providers = make(map[string]Provider)
+	current   Provider
+)
+
+// Register registers a Provider. It's expected to be called from `init()`.
+func Register(p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[p.Name()] = p
+}
+
+// Providers returns the names of the available providers.
+func Providers() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var ret []string
+	for name := range providers {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Use sets the Default provider by its name.
+func Use(name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	p, ok := providers[name]
+	if !ok {
+		if name == FIPSProviderName {
+			return fmt.Errorf("crypto provider `%s` is not available in this build. build with `make build-fips`", name)
+		}
+		return fmt.Errorf("unknown crypto provider `%s`", name)
+	}
+	current = p
+	return nil
+}
+
+// Default returns the provider that is currently in use.
+func Default() Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if current == nil {
+		return providers[StdProviderName]
+	}
+	return current
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+)
+
+// StdProviderName is the name of the provider that uses the Go standard library.
+const StdProviderName = "std"
+
+func init() {
+	Register(stdProvider{name: StdProviderName})
+}
+
+// stdProvider implements HMAC-SHA256 signatures and AES-256-GCM encryption using the Go standard library.
+// Its implementation is pure Go (with assembly acceleration where available), so it behaves the same on amd64 and arm64.
+// When Raptor is built with `GOEXPERIMENT=boringcrypto`, the standard library delegates these primitives to BoringCrypto.
+type stdProvider struct {
+	name string
+	fips bool
+}
+
+func (p stdProvider) Name() string {
+	return p.name
+}
+
+func (p stdProvider) FIPS() bool {
+	return p.fips
+}
+
+func (p stdProvider) Sign(key, msg []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
+
+func (p stdProvider) Verify(key, msg, sig []byte) bool {
+	return hmac.Equal(p.Sign(key, msg), sig)
+}
+
+func (p stdProvider) KeySize() int {
+	return 32
+}
+
+func (p stdProvider) aead(key []byte) (cipher.AEAD, error) {
+	if len(key) != p.KeySize() {
+		return nil, fmt.Errorf("%w: expected a %d bytes key, got %d", ErrInvalidKey, p.KeySize(), len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func (p stdProvider) Encrypt(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := p.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func (p stdProvider) Decrypt(key, ciphertext, additionalData []byte) ([]byte, error) {
+	gcm, err := p.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, fmt.Errorf("%w: ciphertext is too short", ErrDecryption)
+	}
+	nonce, data := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, data, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecryption, err)
+	}
+	return plaintext, nil
+}
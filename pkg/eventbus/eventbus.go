@@ -0,0 +1,98 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package eventbus implements an in-process api.EventBus.
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/raptor-ml/raptor/api"
+	"sync"
+)
+
+type subscription struct {
+	id      uint64
+	types   map[api.EventType]struct{}
+	handler api.EventHandler
+}
+
+// Bus is a synchronous api.EventBus. Handlers are called in the publisher's goroutine, in the order they subscribed.
+// A panicking handler is recovered and logged, so it doesn't affect the publisher or the other handlers.
+type Bus struct {
+	mu     sync.RWMutex
+	nextID uint64
+	subs   []subscription
+	logger logr.Logger
+}
+
+// New creates a new Bus.
+func New(logger logr.Logger) *Bus {
+	return &Bus{logger: logger}
+}
+
+// Subscribe implements api.EventBus
+func (b *Bus) Subscribe(handler api.EventHandler, types ...api.EventType) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	sub := subscription{id: b.nextID, handler: handler}
+	if len(types) > 0 {
+		sub.types = make(map[api.EventType]struct{}, len(types))
+		for _, t := range types {
+			sub.types[t] = struct{}{}
+		}
+	}
+	b.subs = append(b.subs, sub)
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, s := range b.subs {
+			if s.id == sub.id {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish implements api.EventBus
+func (b *Bus) Publish(ctx context.Context, ev api.Event) {
+	b.mu.RLock()
+	subs := b.subs
+	b.mu.RUnlock()
+
+	for _, s := range subs {
+		if s.types != nil {
+			if _, ok := s.types[ev.EventType()]; !ok {
+				continue
+			}
+		}
+		b.call(ctx, s.handler, ev)
+	}
+}
+
+func (b *Bus) call(ctx context.Context, handler api.EventHandler, ev api.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error(fmt.Errorf("%v", r), "event handler panicked", "event", ev.EventType())
+		}
+	}()
+	handler(ctx, ev)
+}
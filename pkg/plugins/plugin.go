@@ -17,8 +17,11 @@ limitations under the License.
 package plugins
 
 import (
+	"context"
 	"fmt"
 	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/protocol"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
@@ -33,6 +36,11 @@ var StateFactories = make(registry[api.StateFactory])
 var CollectNotifierFactories = make(registry[api.CollectNotifierFactory])
 var WriteNotifierFactories = make(registry[api.WriteNotifierFactory])
 var HistoricalWriterFactories = make(registry[api.HistoricalWriterFactory])
+var DeadLetterQueueFactories = make(registry[api.DeadLetterQueueFactory])
+var BackfillSourceFactories = make(registry[api.BackfillSourceFactory])
+var AuditSinkFactories = make(registry[api.AuditSinkFactory])
+var KeyManagerFactories = make(registry[api.KeyManagerFactory])
+var RunnerCapabilities = make(capabilitiesRegistry)
 
 // # Plugin Registry
 
@@ -95,6 +103,38 @@ func NewHistoricalWriter(provider string, viper *viper.Viper) (api.HistoricalWri
 	return nil, fmt.Errorf("historical writer provider `%s` is not registered", provider)
 }
 
+// NewDeadLetterQueue creates a new DeadLetterQueue for a dead-letter queue provider.
+func NewDeadLetterQueue(provider string, viper *viper.Viper) (api.DeadLetterQueue, error) {
+	if p := DeadLetterQueueFactories.Get(provider); p != nil {
+		return p(viper)
+	}
+	return nil, fmt.Errorf("dead-letter queue provider `%s` is not registered", provider)
+}
+
+// NewAuditSink creates a new AuditSink for an audit provider.
+func NewAuditSink(provider string, viper *viper.Viper) (api.AuditSink, error) {
+	if p := AuditSinkFactories.Get(provider); p != nil {
+		return p(viper)
+	}
+	return nil, fmt.Errorf("audit provider `%s` is not registered", provider)
+}
+
+// NewKeyManager creates a new KeyManager for a key manager provider.
+func NewKeyManager(provider string, viper *viper.Viper) (api.KeyManager, error) {
+	if p := KeyManagerFactories.Get(provider); p != nil {
+		return p(viper)
+	}
+	return nil, fmt.Errorf("key manager provider `%s` is not registered", provider)
+}
+
+// NewBackfillSource creates a new BackfillSource for a source kind.
+func NewBackfillSource(ctx context.Context, kind string, cfg manifests.ParsedConfig) (api.BackfillSource, error) {
+	if p := BackfillSourceFactories.Get(kind); p != nil {
+		return p(ctx, cfg)
+	}
+	return nil, fmt.Errorf("backfill source `%s` is not registered", kind)
+}
+
 type modelServerRegistry map[string]api.ModelServer
 
 func (r modelServerRegistry) Register(name string, p api.ModelServer) {
@@ -106,3 +146,41 @@ func (r modelServerRegistry) Register(name string, p api.ModelServer) {
 func (r modelServerRegistry) Get(name string) api.ModelServer {
 	return r[name]
 }
+
+// capabilitiesRegistry holds the protocol capabilities of the runners of the DataSource kinds.
+type capabilitiesRegistry map[string][]protocol.Capability
+
+func (r capabilitiesRegistry) Register(kind string, caps ...protocol.Capability) {
+	if _, ok := r[kind]; ok {
+		panic(fmt.Errorf("capabilities of runner `%s` are already registered", kind))
+	}
+	r[kind] = caps
+}
+
+// Has checks if the runner of the DataSource kind supports the capability.
+func (r capabilitiesRegistry) Has(kind string, c protocol.Capability) bool {
+	return protocol.Peer{Capabilities: r[kind]}.Has(c)
+}
+
+// Require checks that the runner of the DataSource can build the feature: that it applies the DataSource's mapping,
+// and supports the feature's builder (field mapping, `sql` or `cel`).
+func (r capabilitiesRegistry) Require(src api.DataSource, builder manifests.FeatureBuilder) error {
+	var required []protocol.Capability
+	if src.Mapping != nil {
+		required = append(required, protocol.CapabilityDataSourceMapping)
+	}
+	switch {
+	case builder.SQL != "":
+		required = append(required, protocol.CapabilitySQLFeatures)
+	case builder.CEL != "":
+		required = append(required, protocol.CapabilityCELFeatures)
+	case !builder.HasProgram() && builder.Wasm == nil:
+		required = append(required, protocol.CapabilityFieldFeatures)
+	}
+	for _, c := range required {
+		if !r.Has(src.Kind, c) {
+			return fmt.Errorf("the runner of DataSource `%s` (of type `%s`) doesn't support the `%s` capability", src.FQN, src.Kind, c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocol
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"sync"
+)
+
+type peerCtxKey struct{}
+
+// FromContext returns the negotiated protocol of the remote peer of a server call.
+func FromContext(ctx context.Context) (Peer, bool) {
+	p, ok := ctx.Value(peerCtxKey{}).(Peer)
+	return p, ok
+}
+
+func (p Peer) serverHandshake(ctx context.Context) (context.Context, error) {
+	// the response always carries the handshake, so the client can negotiate even if the call is rejected
+	_ = grpc.SetHeader(ctx, metadata.Pairs(p.Pairs()...))
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	remote, err := FromMetadata(md)
+	if err != nil {
+		return ctx, status.Error(codes.InvalidArgument, err.Error())
+	}
+	negotiated, err := p.Negotiate(remote)
+	if err != nil {
+		return ctx, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return context.WithValue(ctx, peerCtxKey{}, negotiated), nil
+}
+
+// UnaryServerInterceptor performs the server side of the handshake. Calls from incompatible peers are rejected with
+// `FailedPrecondition`, and the negotiated protocol is available to the handlers via FromContext.
+func (p Peer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := p.serverHandshake(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+func (p Peer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := p.serverHandshake(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// Session holds the result of the client side of the handshake with a remote server.
+type Session struct {
+	Local Peer
+
+	mu     sync.RWMutex
+	remote *Peer
+	err    error
+}
+
+// NewSession creates a new client Session for the local Peer.
+func NewSession(local Peer) *Session {
+	return &Session{Local: local}
+}
+
+// Negotiated returns the negotiated protocol. It returns false if no call has completed yet.
+func (s *Session) Negotiated() (Peer, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.remote == nil {
+		return Peer{}, false
+	}
+	return *s.remote, true
+}
+
+// Has checks if the capability was negotiated. Before the first call completes, only the legacy capabilities are
+// assumed, so new functionality degrades gracefully until the remote peer is known.
+func (s *Session) Has(c Capability) bool {
+	if p, ok := s.Negotiated(); ok {
+		return p.Has(c)
+	}
+	return s.Local.Has(c) && Legacy().Has(c)
+}
+
+// Err returns the error of the last handshake, if the remote peer is incompatible.
+func (s *Session) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
+
+func (s *Session) update(md metadata.MD) error {
+	remote, err := FromMetadata(md)
+	if err == nil {
+		var negotiated Peer
+		negotiated, err = s.Local.Negotiate(remote)
+		if err == nil {
+			s.mu.Lock()
+			s.remote, s.err = &negotiated, nil
+			s.mu.Unlock()
+			return nil
+		}
+	}
+	s.mu.Lock()
+	s.remote, s.err = nil, err
+	s.mu.Unlock()
+	return err
+}
+
+// UnaryClientInterceptor sends the local handshake with every call, and negotiates the protocol with the server's
+// response. Calls to incompatible servers fail with ErrIncompatible.
+func (s *Session) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, s.Local.Pairs()...)
+
+		var header metadata.MD
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&header))...)
+		if status.Code(err) == codes.Unavailable {
+			// no response was received from the server
+			return err
+		}
+		if herr := s.update(header); herr != nil {
+			return herr
+		}
+		return err
+	}
+}
+
+// StreamClientInterceptor sends the local handshake with every stream.
+// The protocol is negotiated only by unary calls, since waiting for the headers of a stream may block it.
+func (s *Session) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(metadata.AppendToOutgoingContext(ctx, s.Local.Pairs()...), desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package protocol implements the versioning and capability negotiation of the wire protocol between the core and
+// its peers (runners and runtime sidecars).
+//
+// The handshake is carried over gRPC metadata, so it doesn't require changes to the messages: every request carries
+// the protocol version range and the capabilities of the caller, and every response carries the ones of the server.
+// Peers that don't send the handshake (older releases) are treated as speaking LegacyVersion with LegacyCapabilities.
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/metadata"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Version is the current version of the wire protocol.
+	// It should be bumped whenever a change is not backward compatible.
+	Version = 1
+	// MinVersion is the oldest protocol version this build can still talk to.
+	MinVersion = 1
+	// LegacyVersion is the version that is assumed for peers that don't send the handshake.
+	LegacyVersion = 1
+)
+
+// Metadata keys of the handshake.
+const (
+	VersionHeader      = "x-raptor-protocol-version"
+	MinVersionHeader   = "x-raptor-protocol-min-version"
+	CapabilitiesHeader = "x-raptor-capabilities"
+)
+
+// Capability is an optional feature of the protocol. New functionality should be guarded by a capability, so it can
+// be skipped (or rejected explicitly) when talking to peers that don't support it.
+type Capability string
+
+const (
+	// CapabilityDryRun indicates that the runtime supports executing programs without side effects.
+	CapabilityDryRun Capability = "dry-run"
+	// CapabilityKeysOverride indicates that the runtime may return the keys of the result in the response.
+	CapabilityKeysOverride Capability = "keys-override"
+	// CapabilityWindowInspection indicates that the core can inspect the state of windowed features.
+	CapabilityWindowInspection Capability = "window-inspection"
+	// CapabilityDataSourceMapping indicates that the runner applies the DataSource's declarative mapping.
+	CapabilityDataSourceMapping Capability = "datasource-mapping"
+	// CapabilityFieldFeatures indicates that the runner updates features that are mapped to a field without a program.
+	CapabilityFieldFeatures Capability = "field-features"
+	// CapabilitySQLFeatures indicates that the runner evaluates the expressions of `sql` features.
+	CapabilitySQLFeatures Capability = "sql-features"
+	// CapabilityCELFeatures indicates that the runner evaluates the expressions of `cel` features.
+	CapabilityCELFeatures Capability = "cel-features"
+	// CapabilityRequestContext indicates that the core computes on-demand features from the request context of a Get.
+	CapabilityRequestContext Capability = "request-context"
+)
+
+// LegacyCapabilities are the capabilities that are assumed for peers that don't send the handshake.
+var LegacyCapabilities = []Capability{CapabilityDryRun, CapabilityKeysOverride}
+
+// ErrIncompatible is returned when the protocol versions of the peers don't overlap.
+var ErrIncompatible = errors.New("incompatible protocol version")
+
+// Peer describes the protocol that is spoken by a side of the connection.
+type Peer struct {
+	Version      int
+	MinVersion   int
+	Capabilities []Capability
+}
+
+// Local returns the Peer of this build with the given capabilities.
+func Local(caps ...Capability) Peer {
+	return Peer{Version: Version, MinVersion: MinVersion, Capabilities: caps}
+}
+
+// Legacy returns the Peer that is assumed for peers that don't send the handshake.
+func Legacy() Peer {
+	return Peer{Version: LegacyVersion, MinVersion: LegacyVersion, Capabilities: LegacyCapabilities}
+}
+
+// Has checks if the peer supports the capability.
+func (p Peer) Has(c Capability) bool {
+	for _, pc := range p.Capabilities {
+		if pc == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Compatible checks that the version ranges of the peers overlap.
+func (p Peer) Compatible(remote Peer) error {
+	if remote.Version < p.MinVersion || p.Version < remote.MinVersion {
+		return fmt.Errorf("%w: local supports %d-%d, remote supports %d-%d",
+			ErrIncompatible, p.MinVersion, p.Version, remote.MinVersion, remote.Version)
+	}
+	return nil
+}
+
+// Negotiate returns the protocol that should be used with the remote peer: the highest common version, and the
+// capabilities that are supported by both sides.
+func (p Peer) Negotiate(remote Peer) (Peer, error) {
+	if err := p.Compatible(remote); err != nil {
+		return Peer{}, err
+	}
+	ret := Peer{Version: p.Version, MinVersion: p.MinVersion}
+	if remote.Version < ret.Version {
+		ret.Version = remote.Version
+	}
+	if remote.MinVersion > ret.MinVersion {
+		ret.MinVersion = remote.MinVersion
+	}
+	for _, c := range p.Capabilities {
+		if remote.Has(c) {
+			ret.Capabilities = append(ret.Capabilities, c)
+		}
+	}
+	return ret, nil
+}
+
+// String returns a human-readable representation of the peer.
+func (p Peer) String() string {
+	return fmt.Sprintf("v%d (min v%d) [%s]", p.Version, p.MinVersion, joinCapabilities(p.Capabilities))
+}
+
+// Pairs returns the metadata pairs of the handshake.
+func (p Peer) Pairs() []string {
+	return []string{
+		VersionHeader, strconv.Itoa(p.Version),
+		MinVersionHeader, strconv.Itoa(p.MinVersion),
+		CapabilitiesHeader, joinCapabilities(p.Capabilities),
+	}
+}
+
+// FromMetadata parses the handshake of the remote peer. Metadata without a handshake is parsed as Legacy.
+func FromMetadata(md metadata.MD) (Peer, error) {
+	vals := md.Get(VersionHeader)
+	if len(vals) == 0 {
+		return Legacy(), nil
+	}
+
+	p := Peer{}
+	v, err := strconv.Atoi(vals[0])
+	if err != nil {
+		return Peer{}, fmt.Errorf("invalid protocol version `%s`: %w", vals[0], err)
+	}
+	p.Version = v
+	p.MinVersion = v
+	if vals := md.Get(MinVersionHeader); len(vals) > 0 {
+		mv, err := strconv.Atoi(vals[0])
+		if err != nil {
+			return Peer{}, fmt.Errorf("invalid protocol min version `%s`: %w", vals[0], err)
+		}
+		p.MinVersion = mv
+	}
+	for _, val := range md.Get(CapabilitiesHeader) {
+		for _, c := range strings.Split(val, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				p.Capabilities = append(p.Capabilities, Capability(c))
+			}
+		}
+	}
+	return p, nil
+}
+
+func joinCapabilities(caps []Capability) string {
+	s := make([]string, len(caps))
+	for i, c := range caps {
+		s[i] = string(c)
+	}
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
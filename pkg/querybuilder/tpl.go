@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"github.com/raptor-ml/raptor/api"
 	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"io/fs"
 	"strings"
 	"text/template"
 	"time"
@@ -48,6 +49,8 @@ type QueryBuilder interface {
 type queryBuilder struct {
 	tpls         *template.Template
 	featureTable string
+	since        string
+	until        string
 }
 
 type Config struct {
@@ -61,6 +64,14 @@ type Config struct {
 	CastFeature func(ft api.FeatureDescriptor) string
 	// TmpName is used to generate a temporary table name.
 	TmpName func(s string) string
+
+	// Templates overrides the query templates for SQL flavors that are incompatible with the default ones.
+	// It must contain `primitive.tmpl.sql`, `windowed.tmpl.sql` and `featureset.tmpl.sql`.
+	Templates fs.FS
+	// Since is the expression of the query's lower time bound. Defaults to `$SINCE`.
+	Since string
+	// Until is the expression of the query's upper time bound. Defaults to `$UNTIL`.
+	Until string
 }
 
 func New(config Config) QueryBuilder {
@@ -79,17 +90,28 @@ func New(config Config) QueryBuilder {
 	if config.EscapeName == nil {
 		config.EscapeName = EscapeName
 	}
+	if config.Templates == nil {
+		config.Templates = tplFiles
+	}
+	if config.Since == "" {
+		config.Since = "$SINCE"
+	}
+	if config.Until == "" {
+		config.Until = "$UNTIL"
+	}
 	tpls := template.New("").Funcs(template.FuncMap{
 		"escapeName":       config.EscapeName,
 		"subtractDuration": config.SubtractDuration,
 		"castFeature":      config.CastFeature,
 		"tmpName":          config.TmpName,
 	})
-	tpls = template.Must(tpls.ParseFS(tplFiles, "*.sql"))
+	tpls = template.Must(tpls.ParseFS(config.Templates, "*.sql"))
 
 	return &queryBuilder{
 		featureTable: config.FeaturesTable,
 		tpls:         tpls,
+		since:        config.Since,
+		until:        config.Until,
 	}
 }
 
@@ -77,6 +77,9 @@ const (
 	udsVolumeName      = "grpc-uds"
 	udsVolumeMountPath = "/tmp/raptor"
 	coreGrpcEnvName    = "CORE_GRPC_URL"
+	tlsVolumeName      = "core-tls"
+	tlsVolumeMountPath = "/etc/raptor/tls"
+	coreTLSEnvName     = "CORE_TLS_DIR"
 )
 
 func (r BaseRunner) updateDeployment(deploy *appsv1.Deployment, req api.DataSourceReconcileRequest) {
@@ -141,6 +144,7 @@ func (r BaseRunner) updateDeployment(deploy *appsv1.Deployment, req api.DataSour
 				MountPath: udsVolumeMountPath,
 			})
 		}
+		withCoreTLS(&sidecars[i], req.CoreTLSSecret)
 	}
 	found := false
 	for n, v := range deploy.Spec.Template.Spec.Volumes {
@@ -157,38 +161,82 @@ func (r BaseRunner) updateDeployment(deploy *appsv1.Deployment, req api.DataSour
 			},
 		})
 	}
-	deploy.Spec.Template.Spec.Containers = append([]corev1.Container{
-		containerWithDefaults(corev1.Container{
-			Image: r.Image,
-			Name:  "runner",
-			Command: append(r.Command, []string{
-				"--data-source-resource", req.DataSource.Name,
-				"--data-source-namespace", req.DataSource.Namespace}...),
-			Env: []corev1.EnvVar{
-				{
-					Name:  "DEFAULT_RUNTIME",
-					Value: req.RuntimeManager.GetDefaultEnv(),
-				},
-				{
-					Name:  coreGrpcEnvName,
-					Value: req.CoreAddress,
+	// the client certificate of the Core's gRPC accessor (when mTLS is enabled)
+	volumes := deploy.Spec.Template.Spec.Volumes[:0]
+	for _, v := range deploy.Spec.Template.Spec.Volumes {
+		if v.Name != tlsVolumeName {
+			volumes = append(volumes, v)
+		}
+	}
+	if req.CoreTLSSecret != "" {
+		// the API server's default, so the deployment is not updated on every reconciliation
+		mode := corev1.SecretVolumeSourceDefaultMode
+		volumes = append(volumes, corev1.Volume{
+			Name: tlsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  req.CoreTLSSecret, //pragma: allowlist secret
+					DefaultMode: &mode,
 				},
 			},
-			VolumeMounts: []corev1.VolumeMount{
-				{
-					Name:      udsVolumeName,
-					MountPath: udsVolumeMountPath,
-				},
+		})
+	}
+	deploy.Spec.Template.Spec.Volumes = volumes
+
+	runner := corev1.Container{
+		Image: r.Image,
+		Name:  "runner",
+		Command: append(r.Command, []string{
+			"--data-source-resource", req.DataSource.Name,
+			"--data-source-namespace", req.DataSource.Namespace}...),
+		Env: []corev1.EnvVar{
+			{
+				Name:  "DEFAULT_RUNTIME",
+				Value: req.RuntimeManager.GetDefaultEnv(),
 			},
-			Resources: corev1.ResourceRequirements{
-				Limits: req.DataSource.Spec.Resources.Limits,
+			{
+				Name:  coreGrpcEnvName,
+				Value: req.CoreAddress,
 			},
-			SecurityContext: &corev1.SecurityContext{
-				RunAsUser:    &distrolessNoRootUser,
-				RunAsNonRoot: &t,
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      udsVolumeName,
+				MountPath: udsVolumeMountPath,
 			},
-		}),
-	}, sidecars...)
+		},
+		Resources: corev1.ResourceRequirements{
+			Limits: req.DataSource.Spec.Resources.Limits,
+		},
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:    &distrolessNoRootUser,
+			RunAsNonRoot: &t,
+		},
+	}
+	withCoreTLS(&runner, req.CoreTLSSecret)
+	deploy.Spec.Template.Spec.Containers = append([]corev1.Container{containerWithDefaults(runner)}, sidecars...)
+}
+
+// withCoreTLS mounts the client certificate of the Core's gRPC accessor to the container when the secret isn't empty,
+// and removes it otherwise.
+func withCoreTLS(c *corev1.Container, secret string) {
+	env := make([]corev1.EnvVar, 0, len(c.Env)+1)
+	for _, e := range c.Env {
+		if e.Name != coreTLSEnvName {
+			env = append(env, e)
+		}
+	}
+	mounts := make([]corev1.VolumeMount, 0, len(c.VolumeMounts)+1)
+	for _, v := range c.VolumeMounts {
+		if v.Name != tlsVolumeName {
+			mounts = append(mounts, v)
+		}
+	}
+	if secret != "" {
+		env = append(env, corev1.EnvVar{Name: coreTLSEnvName, Value: tlsVolumeMountPath})
+		mounts = append(mounts, corev1.VolumeMount{Name: tlsVolumeName, MountPath: tlsVolumeMountPath, ReadOnly: true})
+	}
+	c.Env, c.VolumeMounts = env, mounts
 }
 
 func containerWithDefaults(container corev1.Container) corev1.Container {
@@ -0,0 +1,405 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/celexpr"
+	"github.com/raptor-ml/raptor/pkg/runtimemanager"
+	"github.com/raptor-ml/raptor/pkg/sqlexpr"
+	"hash/fnv"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"time"
+)
+
+// Executor executes the programs of the Features that are attached to a DataSource.
+// It is used by the runners to feed the rows they consume to the runtime.
+type Executor struct {
+	DataSource client.ObjectKey
+	Client     client.Reader
+	Engine     api.Engine
+	Runtime    api.RuntimeManager
+	Logger     logr.Logger
+
+	// HistoricalOnly writes the values only to the historical storage, rather than to the online store (i.e. for
+	// backfills). Programs are executed in dry-run mode, and their values are written by the Executor.
+	HistoricalOnly bool
+
+	guards runtimemanager.Guards
+}
+
+// Feature is a Feature that is attached to the DataSource.
+type Feature struct {
+	api.FeatureDescriptor
+
+	// Field is the (mapped) field of the row that is used as the Feature value when the Feature has no program.
+	Field string
+	// EventID is the (mapped) field of the row that uniquely identifies the event, for deduplication.
+	EventID string
+	// Program indicates if the Feature has a program to execute.
+	Program bool
+	// Query is the compiled SQL expression of `sql` Features.
+	Query *sqlexpr.Query
+	// Expression is the compiled CEL expression of `cel` Features.
+	Expression *celexpr.Program
+	// TimestampPolicy is the timestamp policy of the Feature, or of the DataSource if the Feature has none.
+	TimestampPolicy *api.TimestampPolicy
+}
+
+// Snapshot is the state of the DataSource and its attached Features at a given point in time.
+type Snapshot struct {
+	Features []Feature
+	Mapping  *api.Mapping
+	// Transactional writes the values of each row in a single transaction (see the DataSource's `transactional`).
+	Transactional bool
+}
+
+// Snapshot returns the Features that are currently attached to the DataSource and its compiled mapping.
+func (e *Executor) Snapshot(ctx context.Context) (*Snapshot, error) {
+	src, ret, tsPolicy, err := e.dataSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range src.Status.Features {
+		ns := ref.Namespace
+		if ns == "" {
+			ns = src.Namespace
+		}
+		ft := &manifests.Feature{}
+		if err := e.Client.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: ns}, ft); err != nil {
+			return nil, fmt.Errorf("failed to get Feature %s/%s: %w", ns, ref.Name, err)
+		}
+		f, err := e.feature(ctx, ft, tsPolicy)
+		if err != nil {
+			var ce compileError
+			if errors.As(err, &ce) {
+				// invalid expressions are reported in the Feature's status, so they shouldn't block the other features
+				e.Logger.Error(err, "skipping feature with an invalid expression", "feature", ft.FQN())
+				continue
+			}
+			return nil, err
+		}
+		ret.Features = append(ret.Features, f)
+	}
+	return ret, nil
+}
+
+// FeatureSnapshot returns a Snapshot of a single Feature, i.e. to replay historical rows through it.
+// The Executor's DataSource should be the Feature's DataSource (if it has one), so its mapping is applied.
+func (e *Executor) FeatureSnapshot(ctx context.Context, ft *manifests.Feature) (*Snapshot, error) {
+	ret := &Snapshot{}
+	var tsPolicy *api.TimestampPolicy
+	if e.DataSource.Name != "" {
+		var err error
+		if _, ret, tsPolicy, err = e.dataSource(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := e.feature(ctx, ft, tsPolicy)
+	if err != nil {
+		return nil, err
+	}
+	ret.Features = []Feature{f}
+	return ret, nil
+}
+
+func (e *Executor) dataSource(ctx context.Context) (*manifests.DataSource, *Snapshot, *api.TimestampPolicy, error) {
+	src := &manifests.DataSource{}
+	if err := e.Client.Get(ctx, e.DataSource, src); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get DataSource: %w", err)
+	}
+
+	mapping, err := api.MappingFromManifest(src.Spec.Mapping)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tsPolicy, err := api.TimestampPolicyFromManifest(src.Spec.TimestampPolicy)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return src, &Snapshot{Mapping: mapping, Transactional: src.Spec.Transactional}, tsPolicy, nil
+}
+
+// compileError is an error of compiling the expression of a Feature.
+type compileError struct {
+	error
+}
+
+func (e compileError) Unwrap() error {
+	return e.error
+}
+
+// feature returns the executable Feature of the manifest. The DataSource's timestamp policy is used if the Feature
+// has none.
+func (e *Executor) feature(ctx context.Context, ft *manifests.Feature, tsPolicy *api.TimestampPolicy) (Feature, error) {
+	fd, err := e.Engine.FeatureDescriptor(ctx, ft.FQN())
+	if err != nil {
+		return Feature{}, fmt.Errorf("failed to get FeatureDescriptor of %s: %w", ft.FQN(), err)
+	}
+	f := Feature{
+		FeatureDescriptor: fd,
+		Field:             ft.Spec.Builder.Field,
+		EventID:           ft.Spec.Builder.EventID,
+		Program:           ft.Spec.Builder.HasProgram(),
+		TimestampPolicy:   fd.TimestampPolicy,
+	}
+	if f.TimestampPolicy == nil {
+		f.TimestampPolicy = tsPolicy
+	}
+	// the guards aren't part of the FeatureDescriptor that is served to the runners
+	f.ExecutionTimeout = ft.Spec.Builder.ExecutionTimeout.Duration
+	f.CircuitBreaker = ft.Spec.Builder.CircuitBreaker
+	e.guards.Sync(f.FQN, strconv.FormatInt(ft.Generation, 10))
+	if ft.Spec.Builder.SQL != "" {
+		q, err := sqlexpr.Compile(ft.Spec.Builder.SQL)
+		if err != nil {
+			return Feature{}, compileError{fmt.Errorf("invalid sql expression: %w", err)}
+		}
+		f.Query = q
+	}
+	if ft.Spec.Builder.CEL != "" {
+		p, err := celexpr.Compile(ft.Spec.Builder.CEL)
+		if err != nil {
+			return Feature{}, compileError{fmt.Errorf("invalid cel expression: %w", err)}
+		}
+		f.Expression = p
+	}
+	return f, nil
+}
+
+// Execute applies the DataSource mapping to the row and updates the Features of the snapshot.
+// Features with a program are executed with the row, `sql` and `cel` Features are updated with the result of their
+// expression, and field-mapped Features are updated with the field's value.
+// The keys of each Feature are extracted from the row's fields, and its timestamp is determined by its timestamp policy.
+// Failures are logged and sent to the dead-letter queue, so a single bad row won't block the rest of the stream.
+// When the snapshot is transactional, the values of the row are written in a single transaction once all the Features
+// are executed.
+func (e *Executor) Execute(ctx context.Context, s *Snapshot, row map[string]any, ts time.Time) {
+	row, rowTS := s.Mapping.Apply(row, ts)
+	if e.HistoricalOnly {
+		ctx = context.WithValue(ctx, api.ContextKeyHistoricalOnly, true)
+	}
+	// backfills write only to the historical storage, so there's nothing to write transactionally
+	w := &rowWriter{Executor: e, tx: s.Transactional && !e.HistoricalOnly}
+	defer w.commit(ctx, row)
+
+	for _, ft := range s.Features {
+		ts := ft.TimestampPolicy.Timestamp(row, rowTS)
+		keys := api.Keys{}
+		for _, k := range ft.Keys {
+			if v, ok := row[k]; ok && v != nil {
+				keys[k] = fmt.Sprint(v)
+			}
+		}
+
+		if ft.Query != nil {
+			e.executeQuery(ctx, w, ft, keys, row, ts)
+			continue
+		}
+		if ft.Expression != nil {
+			e.executeExpression(ctx, w, ft, keys, row, ts)
+			continue
+		}
+		if !ft.Program {
+			val, ok := row[ft.Field]
+			if !ok || val == nil {
+				continue
+			}
+			fctx := ctx
+			if ft.EventID != "" {
+				if id, ok := row[ft.EventID]; ok && id != nil {
+					fctx = context.WithValue(ctx, api.ContextKeyEventID, fmt.Sprint(id))
+				}
+			}
+			if s, ok := val.(string); ok && distinctOnly(ft.Aggr) {
+				val = hashString(s)
+			}
+			w.update(fctx, ft, keys, row, val, ts)
+			continue
+		}
+		e.executeProgram(ctx, w, ft, keys, row, ts)
+	}
+}
+
+func (e *Executor) executeProgram(ctx context.Context, w *rowWriter, ft Feature, keys api.Keys, row map[string]any, ts time.Time) {
+	val, keyz, err := e.guards.Execute(ctx, e.Runtime, ft.FeatureDescriptor, keys, row, ts, e.HistoricalOnly)
+	if errors.Is(err, runtimemanager.ErrCircuitOpen) {
+		e.Logger.V(1).Info("skipped the program of a feature with an open circuit breaker", "feature", ft.FQN)
+		e.deadLetter(ctx, ft, keys, row, nil, ts, err)
+		return
+	}
+	if err != nil {
+		e.Logger.Error(err, "failed to execute program", "feature", ft.FQN)
+		e.deadLetter(ctx, ft, keys, row, nil, ts, err)
+		return
+	}
+	if !e.HistoricalOnly || val.Value == nil {
+		return
+	}
+
+	// programs don't write their results in dry-run mode
+	if !val.Timestamp.IsZero() {
+		ts = val.Timestamp
+	}
+	if keyz == nil {
+		keyz = keys
+	}
+	w.update(ctx, ft, keyz, row, val.Value, ts)
+}
+
+func (e *Executor) executeQuery(ctx context.Context, w *rowWriter, ft Feature, keys api.Keys, row map[string]any, ts time.Time) {
+	val, ok, err := ft.Query.Eval(row)
+	if err != nil {
+		e.Logger.Error(err, "failed to evaluate sql expression", "feature", ft.FQN)
+		e.deadLetter(ctx, ft, keys, row, nil, ts, err)
+		return
+	}
+	if !ok {
+		return
+	}
+	if val, err = sqlexpr.Convert(val, ft.ValuePrimitive()); err != nil {
+		e.Logger.Error(err, "failed to convert sql result", "feature", ft.FQN)
+		e.deadLetter(ctx, ft, keys, row, nil, ts, err)
+		return
+	}
+	w.update(ctx, ft, keys, row, val, ts)
+}
+
+func (e *Executor) executeExpression(ctx context.Context, w *rowWriter, ft Feature, keys api.Keys, row map[string]any, ts time.Time) {
+	val, ok, err := ft.Expression.Eval(row, keys, ts)
+	if err != nil {
+		e.Logger.Error(err, "failed to evaluate cel expression", "feature", ft.FQN)
+		e.deadLetter(ctx, ft, keys, row, nil, ts, err)
+		return
+	}
+	if !ok {
+		return
+	}
+	if val, err = celexpr.Convert(val, ft.ValuePrimitive()); err != nil {
+		e.Logger.Error(err, "failed to convert cel result", "feature", ft.FQN)
+		e.deadLetter(ctx, ft, keys, row, nil, ts, err)
+		return
+	}
+	w.update(ctx, ft, keys, row, val, ts)
+}
+
+// rowWriter writes the values that are computed from a row, either one by one or in a single transaction.
+type rowWriter struct {
+	*Executor
+	tx      bool
+	pending []pendingWrite
+}
+
+// pendingWrite is a write of a transactional row, that is written when the whole row is executed.
+type pendingWrite struct {
+	api.FeatureWrite
+	ft Feature
+}
+
+func (w *rowWriter) update(ctx context.Context, ft Feature, keys api.Keys, row map[string]any, val any, ts time.Time) {
+	if !w.tx {
+		if err := w.Engine.Update(ctx, ft.FQN, keys, val, ts); err != nil {
+			w.Logger.Error(err, "failed to update feature", "feature", ft.FQN)
+			w.deadLetter(ctx, ft, keys, row, val, ts, err)
+		}
+		return
+	}
+
+	id, _ := ctx.Value(api.ContextKeyEventID).(string)
+	w.pending = append(w.pending, pendingWrite{
+		FeatureWrite: api.FeatureWrite{
+			FQN:       ft.FQN,
+			Method:    api.StateMethodUpdate,
+			Keys:      keys,
+			Value:     val,
+			Timestamp: ts,
+			EventID:   id,
+		},
+		ft: ft,
+	})
+}
+
+// commit writes the pending writes of the row in a single transaction. When the transaction fails, all the values of
+// the row are sent to the dead-letter queue.
+func (w *rowWriter) commit(ctx context.Context, row map[string]any) {
+	if len(w.pending) == 0 {
+		return
+	}
+
+	writes := make([]api.FeatureWrite, len(w.pending))
+	for i, p := range w.pending {
+		writes[i] = p.FeatureWrite
+	}
+	err := api.ErrTransactionsUnsupported
+	if tx, ok := w.Engine.(api.Transactor); ok {
+		err = tx.WriteTransaction(ctx, writes)
+	}
+	if err == nil {
+		return
+	}
+	w.Logger.Error(err, "failed to write the transaction of a row", "features", len(writes))
+	for _, p := range w.pending {
+		w.deadLetter(ctx, p.ft, p.Keys, row, p.Value, p.Timestamp, err)
+	}
+}
+
+// deadLetter sends an event that failed to be computed to the engine's dead-letter queue (if supported), so it's not
+// lost and can be replayed.
+func (e *Executor) deadLetter(ctx context.Context, ft Feature, keys api.Keys, row map[string]any, val any, ts time.Time, err error) {
+	dlq, ok := e.Engine.(api.DeadLetterQueue)
+	if !ok {
+		return
+	}
+	dl := api.DeadLetter{
+		FQN:        ft.FQN,
+		DataSource: fmt.Sprintf("%s.%s", e.DataSource.Name, e.DataSource.Namespace),
+		Keys:       keys,
+		Row:        row,
+		Value:      val,
+		Timestamp:  ts,
+		Error:      err.Error(),
+		FailedAt:   time.Now(),
+	}
+	if err := dlq.SendDeadLetter(ctx, dl); err != nil {
+		e.Logger.Error(err, "failed to send dead-letter", "feature", ft.FQN)
+	}
+}
+
+// distinctOnly checks if the window only counts the values, so non-numeric values can be aggregated by their hash.
+func distinctOnly(fns []api.AggrFn) bool {
+	for _, fn := range fns {
+		if fn != api.AggrFnCountDistinct && fn != api.AggrFnCount {
+			return false
+		}
+	}
+	return len(fns) > 0
+}
+
+func hashString(s string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return int(h.Sum32())
+}
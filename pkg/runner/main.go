@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	grpcMiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpcRetry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/raptor-ml/raptor/api"
+	coreApi "github.com/raptor-ml/raptor/api/proto/gen/go/core/v1alpha1"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/internal/mtls"
+	"github.com/raptor-ml/raptor/internal/version"
+	"github.com/raptor-ml/raptor/pkg/protocol"
+	"github.com/raptor-ml/raptor/pkg/runtimemanager"
+	"github.com/raptor-ml/raptor/pkg/sdk"
+)
+
+// Capabilities are the protocol capabilities of the runners that are built with Main.
+var Capabilities = []protocol.Capability{
+	protocol.CapabilityDataSourceMapping,
+	protocol.CapabilityFieldFeatures,
+	protocol.CapabilitySQLFeatures,
+	protocol.CapabilityCELFeatures,
+}
+
+// Runnable is a runner of a DataSource.
+type Runnable interface {
+	Run(ctx context.Context) error
+}
+
+// Factory creates the runner of a DataSource.
+type Factory[R Runnable] func(ctx context.Context, src *manifests.DataSource, k8s client.Reader, engine api.Engine, runtime api.RuntimeManager, logger logr.Logger) (R, error)
+
+// Main is the entrypoint of the runners' binaries: it parses the flags, connects to the Core and runs the runner of
+// the DataSource that was created by the factory.
+func Main[R Runnable](name string, factory Factory[R]) {
+	pflag.String("data-source-resource", "", "The name of the DataSource resource.")
+	pflag.String("data-source-namespace", "", "The namespace of the DataSource resource.")
+	pflag.String("core-grpc-url", "unix:///tmp/raptor/core.sock", "The address of the Core gRPC server.")
+	pflag.String("core-tls-dir", "", "The directory of the mTLS client certificate for the Core gRPC server. "+
+		"The connection is insecure when empty.")
+	pflag.Bool("dev", false, "Set as production")
+
+	zapOpts := zap.Options{}
+	zapOpts.BindFlags(flag.CommandLine)
+
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+	orFail(viper.BindPFlags(pflag.CommandLine), "failed to bind flags")
+
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
+	viper.AutomaticEnv()
+
+	zapOpts.Development = viper.GetBool("dev")
+	logger := zap.New(zap.UseFlagOptions(&zapOpts))
+	ctrl.SetLogger(logger)
+
+	setupLog.WithValues("version", version.Version).Info(fmt.Sprintf("Initializing %s Runner...", name))
+
+	ctx := ctrl.SetupSignalHandler()
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(manifests.AddToScheme(scheme))
+
+	k8s, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	orFail(err, "failed to create kubernetes client")
+
+	src := &manifests.DataSource{}
+	err = k8s.Get(ctx, client.ObjectKey{
+		Name:      viper.GetString("data-source-resource"),
+		Namespace: viper.GetString("data-source-namespace"),
+	}, src)
+	orFail(err, "failed to get DataSource")
+
+	session := protocol.NewSession(protocol.Local(Capabilities...))
+	creds, err := mtls.TransportCredentials(viper.GetString("core-tls-dir"))
+	orFail(err, "failed to load the mTLS certificate")
+	cc, err := grpc.Dial(
+		viper.GetString("core-grpc-url"),
+		grpc.WithUnaryInterceptor(grpcMiddleware.ChainUnaryClient(
+			session.UnaryClientInterceptor(),
+			grpcRetry.UnaryClientInterceptor(),
+		)),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithPerRPCCredentials(sdk.ServiceAccountCredentials()),
+	)
+	orFail(err, "failed to dial core")
+	defer cc.Close()
+
+	rm, err := runtimemanager.New(nil, "", "")
+	orFail(err, "failed to create runtime manager")
+
+	name = strings.ToLower(name)
+	runner, err := factory(ctx, src, k8s, sdk.NewGRPCEngine(coreApi.NewEngineServiceClient(cc)), rm, logger.WithName(name))
+	orFail(err, fmt.Sprintf("failed to create %s runner", name))
+
+	setupLog.Info(fmt.Sprintf("starting %s runner", name))
+	orFail(runner.Run(ctx), fmt.Sprintf("problem running %s runner", name))
+}
+
+var setupLog = ctrl.Log.WithName("setup")
+
+func orFail(err error, message string, keyAndValues ...any) {
+	if err != nil {
+		if setupLog.GetSink() == nil {
+			_, _ = fmt.Fprint(os.Stderr, append([]any{"error", err, "message", message}, keyAndValues...)...)
+		} else {
+			setupLog.Error(err, message, keyAndValues...)
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2022 RaptorML authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtimemanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for the skipped invocations of a Feature whose circuit breaker is open.
+var ErrCircuitOpen = errors.New("the circuit breaker of the feature is open")
+
+// CircuitCooldown is the time an open circuit breaker skips the invocations, before an invocation is let through to
+// probe the program.
+const CircuitCooldown = time.Minute
+
+// Guards guards the invocations of the Features' programs: each invocation is bounded by the Feature's
+// ExecutionTimeout, and the invocations are skipped once the Feature's circuit breaker is opened by consecutive
+// failures. The zero value is ready to use.
+type Guards struct {
+	circuits sync.Map // fqn -> *circuit
+}
+
+type circuit struct {
+	mu       sync.Mutex
+	version  string
+	failures int
+	openedAt time.Time
+	probing  bool
+	err      error
+}
+
+func (g *Guards) circuit(fqn string) *circuit {
+	c, _ := g.circuits.LoadOrStore(fqn, &circuit{})
+	return c.(*circuit)
+}
+
+// Execute invokes the Feature's program with its guards.
+func (g *Guards) Execute(ctx context.Context, rm api.RuntimeManager, fd api.FeatureDescriptor, keys api.Keys, row map[string]any, ts time.Time, dryRun bool) (api.Value, api.Keys, error) {
+	if fd.CircuitBreaker > 0 && !g.circuit(fd.FQN).allow() {
+		return api.Value{}, keys, fmt.Errorf("%w: %s", ErrCircuitOpen, fd.FQN)
+	}
+	if fd.ExecutionTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, fd.ExecutionTimeout)
+		defer cancel()
+	}
+
+	val, keys, err := rm.ExecuteProgram(ctx, fd.RuntimeEnv, fd.FQN, keys, row, ts, dryRun)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && err != nil {
+		err = fmt.Errorf("the program exceeded its execution timeout (%s): %w", fd.ExecutionTimeout, err)
+	}
+	g.Record(fd, err)
+	return val, keys, err
+}
+
+// Record records the result of an invocation of the Feature's program (or of its builder).
+func (g *Guards) Record(fd api.FeatureDescriptor, err error) {
+	if fd.CircuitBreaker <= 0 {
+		return
+	}
+	c := g.circuit(fd.FQN)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.probing = false
+	if err == nil {
+		c.failures = 0
+		c.openedAt = time.Time{}
+		c.err = nil
+		return
+	}
+	c.failures++
+	c.err = err
+	if c.failures >= fd.CircuitBreaker {
+		c.openedAt = time.Now()
+	}
+}
+
+// Sync closes the circuit breaker of the Feature when its version (i.e. its checksum or generation) is changed.
+func (g *Guards) Sync(fqn, version string) {
+	c := g.circuit(fqn)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version == version {
+		return
+	}
+	c.version = version
+	c.failures = 0
+	c.openedAt = time.Time{}
+	c.probing = false
+	c.err = nil
+}
+
+// Forget removes the circuit breaker of the Feature.
+func (g *Guards) Forget(fqn string) {
+	g.circuits.Delete(fqn)
+}
+
+// Open returns the error that opened the circuit breaker of the Feature, or nil if it's closed.
+func (g *Guards) Open(fqn string) error {
+	c, ok := g.circuits.Load(fqn)
+	if !ok {
+		return nil
+	}
+	cc := c.(*circuit)
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.openedAt.IsZero() {
+		return nil
+	}
+	return fmt.Errorf("%d consecutive failures, the latest: %w", cc.failures, cc.err)
+}
+
+// allow checks if an invocation is allowed. When the circuit is open, a single invocation is let through once in
+// CircuitCooldown to probe the program.
+func (c *circuit) allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.openedAt.IsZero() {
+		return true
+	}
+	if c.probing || time.Since(c.openedAt) < CircuitCooldown {
+		return false
+	}
+	c.probing = true
+	return true
+}
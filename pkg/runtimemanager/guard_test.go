@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimemanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/raptor-ml/raptor/api"
+)
+
+type fakeRuntime struct {
+	api.RuntimeManager
+	calls int
+	err   error
+	delay time.Duration
+}
+
+func (f *fakeRuntime) ExecuteProgram(ctx context.Context, _ string, _ string, keys api.Keys, _ map[string]any, _ time.Time, _ bool) (api.Value, api.Keys, error) {
+	f.calls++
+	if f.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return api.Value{}, keys, ctx.Err()
+		case <-time.After(f.delay):
+		}
+	}
+	return api.Value{Value: 1}, keys, f.err
+}
+
+func TestGuards_CircuitBreaker(t *testing.T) {
+	g := &Guards{}
+	rm := &fakeRuntime{err: errors.New("boom")}
+	fd := api.FeatureDescriptor{FQN: "test.default", CircuitBreaker: 3}
+	g.Sync(fd.FQN, "1")
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := g.Execute(context.Background(), rm, fd, nil, nil, time.Now(), true); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("invocation %d was skipped before the circuit was opened", i)
+		}
+	}
+	if err := g.Open(fd.FQN); err == nil {
+		t.Fatalf("the circuit isn't open after %d failures", fd.CircuitBreaker)
+	}
+	if _, _, err := g.Execute(context.Background(), rm, fd, nil, nil, time.Now(), true); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the invocation to be skipped, got %v", err)
+	}
+	if rm.calls != 3 {
+		t.Fatalf("expected 3 invocations of the program, got %d", rm.calls)
+	}
+
+	// the probe is let through after the cooldown, and closes the circuit when it succeeds
+	c := g.circuit(fd.FQN)
+	c.openedAt = time.Now().Add(-CircuitCooldown)
+	rm.err = nil
+	if _, _, err := g.Execute(context.Background(), rm, fd, nil, nil, time.Now(), true); err != nil {
+		t.Fatalf("expected the probe to succeed, got %v", err)
+	}
+	if err := g.Open(fd.FQN); err != nil {
+		t.Fatalf("the circuit isn't closed after a successful probe: %v", err)
+	}
+
+	// a changed feature closes the circuit
+	rm.err = errors.New("boom")
+	for i := 0; i < 3; i++ {
+		_, _, _ = g.Execute(context.Background(), rm, fd, nil, nil, time.Now(), true)
+	}
+	g.Sync(fd.FQN, "2")
+	if err := g.Open(fd.FQN); err != nil {
+		t.Fatalf("the circuit isn't closed after the feature was changed: %v", err)
+	}
+}
+
+func TestGuards_ExecutionTimeout(t *testing.T) {
+	g := &Guards{}
+	rm := &fakeRuntime{delay: time.Second}
+	fd := api.FeatureDescriptor{FQN: "test.default", ExecutionTimeout: 10 * time.Millisecond}
+
+	_, _, err := g.Execute(context.Background(), rm, fd, nil, nil, time.Now(), true)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the invocation to exceed its timeout, got %v", err)
+	}
+}
@@ -25,7 +25,12 @@ import (
 	"github.com/raptor-ml/raptor/api"
 	coreApi "github.com/raptor-ml/raptor/api/proto/gen/go/core/v1alpha1"
 	runtimeApi "github.com/raptor-ml/raptor/api/proto/gen/go/py_runtime/v1alpha1"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/pkg/protocol"
 	"github.com/raptor-ml/raptor/pkg/sdk"
+	"github.com/raptor-ml/raptor/pkg/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/local"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -47,6 +52,7 @@ type runtime struct {
 	environments map[string]v1.Container
 	defaultEnv   string
 	conns        sync.Map
+	sessions     sync.Map
 }
 
 const serviceAccountPath = "/var/run/secrets/kubernetes.io/serviceaccount"
@@ -170,7 +176,7 @@ func New(mgr manager.Manager, namespace string, podname string) (api.RuntimeMana
 }
 
 func (r *runtime) LoadProgram(env, fqn, program string, packages []string) (*api.ParsedProgram, error) {
-	rt, err := r.getRuntime(env)
+	rt, err := r.getRuntime(r.environment(env, fqn))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get runtime: %w", err)
 	}
@@ -212,10 +218,25 @@ func (r *runtime) LoadProgram(env, fqn, program string, packages []string) (*api
 }
 
 func (r *runtime) ExecuteProgram(ctx context.Context, env string, fqn string, keys api.Keys, row map[string]any, ts time.Time, dryRun bool) (api.Value, api.Keys, error) {
+	ctx, span := tracing.Start(ctx, "runtime.ExecuteProgram", tracing.Feature(fqn),
+		attribute.String("raptor.runtime", env),
+		attribute.Bool("raptor.dry_run", dryRun),
+	)
+	val, keys, err := r.executeProgram(ctx, env, fqn, keys, row, ts, dryRun)
+	tracing.End(span, err)
+	return val, keys, err
+}
+
+func (r *runtime) executeProgram(ctx context.Context, env string, fqn string, keys api.Keys, row map[string]any, ts time.Time, dryRun bool) (api.Value, api.Keys, error) {
+	env = r.environment(env, fqn)
 	rt, err := r.getRuntime(env)
 	if err != nil {
 		return api.Value{}, keys, fmt.Errorf("failed to get runtime: %w", err)
 	}
+	session := r.session(env)
+	if dryRun && !session.Has(protocol.CapabilityDryRun) {
+		return api.Value{}, keys, fmt.Errorf("runtime %s doesn't support dry-run executions", env)
+	}
 
 	data := make(map[string]*coreApi.Value)
 	for k, v := range row {
@@ -240,7 +261,7 @@ func (r *runtime) ExecuteProgram(ctx context.Context, env string, fqn string, ke
 	if resp.Timestamp.CheckValid() == nil && !resp.Timestamp.AsTime().IsZero() {
 		ts = resp.Timestamp.AsTime()
 	}
-	if resp.Keys != nil && len(resp.Keys) > 0 {
+	if resp.Keys != nil && len(resp.Keys) > 0 && session.Has(protocol.CapabilityKeysOverride) {
 		keys = resp.Keys
 	}
 
@@ -251,6 +272,36 @@ func (r *runtime) ExecuteProgram(ctx context.Context, env string, fqn string, ke
 	}, keys, nil
 }
 
+// environment returns the runtime of a feature's environment. The runtimes of the RuntimeEnvironments of the
+// feature's namespace take precedence over the runtimes of the Core with the same name.
+func (r *runtime) environment(env, fqn string) string {
+	if env == "" {
+		return env
+	}
+	ns, _, _, _, _, err := api.ParseSelector(fqn)
+	if err != nil || ns == "" {
+		return env
+	}
+	if name := manifests.RuntimeEnvironmentName(ns, env); r.has(name) {
+		return name
+	}
+	return env
+}
+
+func (r *runtime) has(name string) bool {
+	_, ok := r.environments[name]
+	return ok
+}
+
+// session returns the protocol session with the runtime.
+func (r *runtime) session(name string) *protocol.Session {
+	if name == "" {
+		name = r.defaultEnv
+	}
+	s, _ := r.sessions.LoadOrStore(name, protocol.NewSession(protocol.Local(protocol.CapabilityDryRun, protocol.CapabilityKeysOverride)))
+	return s.(*protocol.Session)
+}
+
 func (r *runtime) getRuntime(name string) (runtimeApi.RuntimeServiceClient, error) {
 	if name == "" {
 		name = r.defaultEnv
@@ -271,12 +322,16 @@ func (r *runtime) getRuntime(name string) (runtimeApi.RuntimeServiceClient, erro
 	cc, err := grpc.Dial(
 		fmt.Sprintf("unix://%s", socket),
 		grpc.WithStreamInterceptor(grpcMiddleware.ChainStreamClient(
+			r.session(name).StreamClientInterceptor(),
 			grpcRetry.StreamClientInterceptor(),
 		)),
 		grpc.WithUnaryInterceptor(grpcMiddleware.ChainUnaryClient(
+			r.session(name).UnaryClientInterceptor(),
 			grpcRetry.UnaryClientInterceptor(),
 		)),
 		grpc.WithTransportCredentials(local.NewCredentials()),
+		// propagates the trace context to the runtime
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial socket: %w", err)
@@ -0,0 +1,170 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schemaregistry is a minimal client for the Confluent Schema Registry.
+// It's used to resolve the schemas of Avro/Protobuf encoded messages, and to validate them against the Features.
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSubjectNotFound is returned when the subject is not registered.
+var ErrSubjectNotFound = errors.New("subject not found")
+
+// SchemaType is the type of schema that is registered in the registry.
+type SchemaType string
+
+const (
+	SchemaTypeAvro     SchemaType = "AVRO"
+	SchemaTypeProtobuf SchemaType = "PROTOBUF"
+	SchemaTypeJSON     SchemaType = "JSON"
+)
+
+// Schema is a registered schema.
+type Schema struct {
+	ID      int        `json:"id"`
+	Subject string     `json:"subject"`
+	Version int        `json:"version"`
+	Type    SchemaType `json:"schemaType"`
+	Schema  string     `json:"schema"`
+}
+
+// LatestTTL is the duration that the latest schemas of the subjects are cached for.
+const LatestTTL = 5 * time.Minute
+
+// Client is a Schema Registry client. Schemas that are fetched by ID are cached, since they are immutable, and the
+// latest schemas of the subjects are cached for LatestTTL.
+type Client struct {
+	url      string
+	username string
+	password string
+	http     *http.Client
+
+	cache    sync.Map
+	latest   sync.Map
+	decoders sync.Map
+}
+
+type latestEntry struct {
+	schema  *Schema
+	expires time.Time
+}
+
+// New creates a new Schema Registry client.
+func New(registryURL, username, password string) (*Client, error) {
+	u, err := url.Parse(registryURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema registry url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported schema registry url scheme `%s`", u.Scheme)
+	}
+	return &Client{
+		url:      strings.TrimSuffix(registryURL, "/"),
+		username: username,
+		password: password,
+		http:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Latest returns the latest version of the subject's schema.
+func (c *Client) Latest(ctx context.Context, subject string) (*Schema, error) {
+	if e, ok := c.latest.Load(subject); ok && time.Now().Before(e.(latestEntry).expires) {
+		return e.(latestEntry).schema, nil
+	}
+
+	s := &Schema{}
+	if err := c.get(ctx, fmt.Sprintf("/subjects/%s/versions/latest", url.PathEscape(subject)), s); err != nil {
+		return nil, err
+	}
+	if s.Type == "" {
+		s.Type = SchemaTypeAvro
+	}
+	c.latest.Store(subject, latestEntry{schema: s, expires: time.Now().Add(LatestTTL)})
+	return s, nil
+}
+
+// ByID returns the schema with the given ID.
+func (c *Client) ByID(ctx context.Context, id int) (*Schema, error) {
+	if s, ok := c.cache.Load(id); ok {
+		return s.(*Schema), nil
+	}
+
+	s := &Schema{}
+	if err := c.get(ctx, fmt.Sprintf("/schemas/ids/%d", id), s); err != nil {
+		return nil, err
+	}
+	s.ID = id
+	if s.Type == "" {
+		s.Type = SchemaTypeAvro
+	}
+	c.cache.Store(id, s)
+	return s, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query schema registry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrSubjectNotFound, path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("schema registry returned %s: %s", resp.Status, string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode schema registry response: %w", err)
+	}
+	return nil
+}
+
+// TopicSubject returns the subject name of a topic's values according to the default (TopicNameStrategy) strategy.
+func TopicSubject(topic string) string {
+	return fmt.Sprintf("%s-value", topic)
+}
+
+// SplitMessage splits a message that is encoded with the Schema Registry wire format to the schema ID and the payload.
+// For Protobuf messages, the payload is prefixed with the message indexes.
+func SplitMessage(msg []byte) (int, []byte, error) {
+	if len(msg) < 5 || msg[0] != 0 {
+		return 0, nil, fmt.Errorf("message is not encoded with the schema registry wire format")
+	}
+	return int(binary.BigEndian.Uint32(msg[1:5])), msg[5:], nil
+}
@@ -0,0 +1,484 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"github.com/raptor-ml/raptor/pkg/protoregistry"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"math"
+	"time"
+)
+
+// ErrMalformedMessage is returned when a message can't be decoded by its schema.
+var ErrMalformedMessage = errors.New("malformed message")
+
+// Decoder decodes the payloads of a schema to rows.
+type Decoder interface {
+	Decode(payload []byte) (map[string]any, error)
+}
+
+// Decode decodes a message that is encoded with the Schema Registry wire format to a row, by the schema that is
+// referenced by the message. The decoders of the schemas are cached, since the schemas are immutable.
+func (c *Client) Decode(ctx context.Context, msg []byte) (map[string]any, error) {
+	id, payload, err := SplitMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if d, ok := c.decoders.Load(id); ok {
+		return d.(Decoder).Decode(payload)
+	}
+	s, err := c.ByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema %d: %w", id, err)
+	}
+	d, err := s.Decoder()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema %d: %w", id, err)
+	}
+	c.decoders.Store(id, d)
+	return d.Decode(payload)
+}
+
+// Decoder compiles the schema to a Decoder of its payloads.
+func (s *Schema) Decoder() (Decoder, error) {
+	switch s.Type {
+	case SchemaTypeAvro, "":
+		var schema any
+		if err := json.Unmarshal([]byte(s.Schema), &schema); err != nil {
+			return nil, fmt.Errorf("failed to parse avro schema: %w", err)
+		}
+		d := &avroDecoder{schema: schema, named: make(map[string]any)}
+		d.register(schema, "")
+		if t, ok := schema.(map[string]any); !ok || t["type"] != "record" {
+			return nil, fmt.Errorf("avro schema must be a record")
+		}
+		return d, nil
+	case SchemaTypeProtobuf:
+		fds, filename, err := protoregistry.SchemaToFDs(s.Schema)
+		if err != nil {
+			return nil, err
+		}
+		for _, fd := range fds {
+			if fd.GetName() == filename {
+				return &protobufDecoder{file: fd.UnwrapFile()}, nil
+			}
+		}
+		return nil, fmt.Errorf("no file descriptor found for protobuf schema")
+	case SchemaTypeJSON:
+		return jsonDecoder{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported schema type `%s`", s.Type)
+	}
+}
+
+type jsonDecoder struct{}
+
+func (jsonDecoder) Decode(payload []byte) (map[string]any, error) {
+	row := make(map[string]any)
+	if err := json.Unmarshal(payload, &row); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+	for k, v := range row {
+		row[k] = api.NormalizeValue(v)
+	}
+	return row, nil
+}
+
+// avroDecoder decodes Avro binary encoded records.
+// Nested records, maps and fixed values are decoded to their JSON representation.
+type avroDecoder struct {
+	schema any
+	named  map[string]any
+}
+
+// register indexes the named types of the schema, so they can be referenced by name.
+func (d *avroDecoder) register(schema any, namespace string) {
+	switch t := schema.(type) {
+	case []any:
+		for _, u := range t {
+			d.register(u, namespace)
+		}
+	case map[string]any:
+		switch t["type"] {
+		case "record", "enum", "fixed":
+			name, _ := t["name"].(string)
+			if ns, ok := t["namespace"].(string); ok {
+				namespace = ns
+			}
+			d.named[name] = t
+			if namespace != "" {
+				d.named[namespace+"."+name] = t
+			}
+			if fields, ok := t["fields"].([]any); ok {
+				for _, f := range fields {
+					if f, ok := f.(map[string]any); ok {
+						d.register(f["type"], namespace)
+					}
+				}
+			}
+		case "array":
+			d.register(t["items"], namespace)
+		case "map":
+			d.register(t["values"], namespace)
+		}
+	}
+}
+
+func (d *avroDecoder) Decode(payload []byte) (map[string]any, error) {
+	r := &avroReader{buf: payload}
+	v, err := d.read(r, d.schema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+	row := v.(map[string]any)
+	for k, v := range row {
+		row[k] = normalizeAvro(v)
+	}
+	return row, nil
+}
+
+// normalizeAvro converts the decoded values to values that are supported by the runtime.
+func normalizeAvro(v any) any {
+	switch v := v.(type) {
+	case int64:
+		return int(v)
+	case float32:
+		return float64(v)
+	case []byte:
+		return string(v)
+	case []any:
+		vals := make([]any, len(v))
+		for i, item := range v {
+			vals[i] = normalizeAvro(item)
+		}
+		return api.NormalizeList(vals)
+	case map[string]any:
+		return api.NormalizeValue(v)
+	default:
+		return v
+	}
+}
+
+func (d *avroDecoder) read(r *avroReader, schema any) (any, error) {
+	switch t := schema.(type) {
+	case string:
+		return d.readPrimitive(r, t)
+	case []any:
+		i, err := r.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || int(i) >= len(t) {
+			return nil, fmt.Errorf("union index %d is out of range", i)
+		}
+		return d.read(r, t[i])
+	case map[string]any:
+		return d.readComplex(r, t)
+	default:
+		return nil, fmt.Errorf("invalid avro type `%v`", schema)
+	}
+}
+
+func (d *avroDecoder) readPrimitive(r *avroReader, t string) (any, error) {
+	switch t {
+	case "null":
+		return nil, nil
+	case "boolean":
+		b, err := r.next(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+	case "int", "long":
+		return r.long()
+	case "float":
+		b, err := r.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
+	case "double":
+		b, err := r.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case "bytes":
+		return r.bytes()
+	case "string":
+		b, err := r.bytes()
+		return string(b), err
+	}
+	if named, ok := d.named[t]; ok {
+		return d.read(r, named)
+	}
+	return nil, fmt.Errorf("unknown avro type `%s`", t)
+}
+
+func (d *avroDecoder) readComplex(r *avroReader, t map[string]any) (any, error) {
+	switch t["type"] {
+	case "record":
+		fields, _ := t["fields"].([]any)
+		ret := make(map[string]any, len(fields))
+		for _, f := range fields {
+			f, ok := f.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("invalid record field `%v`", f)
+			}
+			v, err := d.read(r, f["type"])
+			if err != nil {
+				return nil, err
+			}
+			name, _ := f["name"].(string)
+			ret[name] = v
+		}
+		return ret, nil
+	case "enum":
+		i, err := r.long()
+		if err != nil {
+			return nil, err
+		}
+		symbols, _ := t["symbols"].([]any)
+		if i < 0 || int(i) >= len(symbols) {
+			return nil, fmt.Errorf("enum index %d is out of range", i)
+		}
+		return symbols[i], nil
+	case "array":
+		var ret []any
+		err := r.blocks(func() error {
+			v, err := d.read(r, t["items"])
+			ret = append(ret, v)
+			return err
+		})
+		return ret, err
+	case "map":
+		ret := make(map[string]any)
+		err := r.blocks(func() error {
+			k, err := r.bytes()
+			if err != nil {
+				return err
+			}
+			v, err := d.read(r, t["values"])
+			ret[string(k)] = v
+			return err
+		})
+		return ret, err
+	case "fixed":
+		size, _ := t["size"].(float64)
+		return r.next(int(size))
+	}
+
+	v, err := d.read(r, t["type"])
+	if err != nil {
+		return nil, err
+	}
+	if i, ok := v.(int64); ok {
+		switch t["logicalType"] {
+		case "timestamp-millis", "local-timestamp-millis":
+			return time.UnixMilli(i).UTC(), nil
+		case "timestamp-micros", "local-timestamp-micros":
+			return time.UnixMicro(i).UTC(), nil
+		}
+	}
+	return v, nil
+}
+
+type avroReader struct {
+	buf []byte
+}
+
+func (r *avroReader) next(n int) ([]byte, error) {
+	if n < 0 || n > len(r.buf) {
+		return nil, fmt.Errorf("unexpected end of message")
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b, nil
+}
+
+// long reads a zig-zag encoded variable-length integer.
+func (r *avroReader) long() (int64, error) {
+	v, n := binary.Varint(r.buf)
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid variable-length integer")
+	}
+	r.buf = r.buf[n:]
+	return v, nil
+}
+
+func (r *avroReader) bytes() ([]byte, error) {
+	n, err := r.long()
+	if err != nil {
+		return nil, err
+	}
+	if n > int64(len(r.buf)) {
+		return nil, fmt.Errorf("unexpected end of message")
+	}
+	return r.next(int(n))
+}
+
+// blocks reads the blocks of an array or a map, and calls fn for each of their items.
+func (r *avroReader) blocks(fn func() error) error {
+	for {
+		n, err := r.long()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if n < 0 {
+			// negative counts are followed by the size of the block in bytes
+			n = -n
+			if _, err := r.long(); err != nil {
+				return err
+			}
+		}
+		if n > int64(len(r.buf)) {
+			return fmt.Errorf("block of %d items exceeds the message", n)
+		}
+		for i := int64(0); i < n; i++ {
+			if err := fn(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// protobufDecoder decodes Protobuf messages of a `.proto` schema.
+// Nested messages are decoded to their JSON representation, except for Timestamps.
+type protobufDecoder struct {
+	file protoreflect.FileDescriptor
+}
+
+func (d *protobufDecoder) Decode(payload []byte) (map[string]any, error) {
+	md, payload, err := d.message(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+	msg := dynamicpb.NewMessage(md)
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+
+	row := make(map[string]any)
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.IsList() {
+			l := v.List()
+			vals := make([]any, l.Len())
+			for i := 0; i < l.Len(); i++ {
+				vals[i] = protobufValue(fd, l.Get(i))
+			}
+			row[string(fd.Name())] = api.NormalizeList(vals)
+			return true
+		}
+		row[string(fd.Name())] = protobufValue(fd, v)
+		return true
+	})
+	return row, nil
+}
+
+// message reads the message indexes that prefix the payload, and returns the descriptor of the indexed message.
+func (d *protobufDecoder) message(payload []byte) (protoreflect.MessageDescriptor, []byte, error) {
+	count, n := binary.Varint(payload)
+	if n <= 0 {
+		return nil, nil, fmt.Errorf("invalid message indexes")
+	}
+	payload = payload[n:]
+
+	// a count of zero is a shortcut for the first message
+	indexes := []int64{0}
+	if count > 0 {
+		if count > int64(len(payload)) {
+			return nil, nil, fmt.Errorf("invalid message indexes")
+		}
+		indexes = make([]int64, count)
+		for i := range indexes {
+			indexes[i], n = binary.Varint(payload)
+			if n <= 0 {
+				return nil, nil, fmt.Errorf("invalid message indexes")
+			}
+			payload = payload[n:]
+		}
+	}
+
+	msgs := d.file.Messages()
+	var md protoreflect.MessageDescriptor
+	for _, i := range indexes {
+		if i < 0 || int(i) >= msgs.Len() {
+			return nil, nil, fmt.Errorf("message index %d is out of range", i)
+		}
+		md = msgs.Get(int(i))
+		msgs = md.Messages()
+	}
+	return md, payload, nil
+}
+
+func protobufValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return int(v.Int())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int(v.Uint())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float()
+	case protoreflect.BoolKind:
+		return v.Bool()
+	case protoreflect.StringKind:
+		return v.String()
+	case protoreflect.BytesKind:
+		return string(v.Bytes())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int(v.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if fd.IsMap() {
+			break
+		}
+		msg := v.Message()
+		if msg.Descriptor().FullName() == "google.protobuf.Timestamp" {
+			fields := msg.Descriptor().Fields()
+			sec := msg.Get(fields.ByName("seconds")).Int()
+			nsec := msg.Get(fields.ByName("nanos")).Int()
+			return time.Unix(sec, nsec).UTC()
+		}
+		buf, _ := protojson.Marshal(msg.Interface())
+		return string(buf)
+	}
+	if fd.IsMap() {
+		ret := make(map[string]any)
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			ret[k.String()] = protobufValue(fd.MapValue(), v)
+			return true
+		})
+		return api.NormalizeValue(ret)
+	}
+	return v.Interface()
+}
@@ -0,0 +1,229 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const avroSchema = `{
+	"type": "record",
+	"name": "Click",
+	"namespace": "raptor.test",
+	"fields": [
+		{"name": "user_id", "type": "string"},
+		{"name": "count", "type": "long"},
+		{"name": "score", "type": ["null", "double"]},
+		{"name": "tags", "type": {"type": "array", "items": "string"}},
+		{"name": "at", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+		{"name": "color", "type": {"type": "enum", "name": "Color", "symbols": ["RED", "GREEN"]}},
+		{"name": "secondary", "type": ["null", "Color"]}
+	]
+}`
+
+const protobufSchema = `syntax = "proto3";
+package raptor.test;
+
+message Click {
+  string user_id = 1;
+  int64 count = 2;
+  repeated string tags = 3;
+  Color color = 4;
+}
+
+enum Color {
+  RED = 0;
+  GREEN = 1;
+}
+`
+
+func avroString(b []byte, s string) []byte {
+	return append(binary.AppendVarint(b, int64(len(s))), s...)
+}
+
+// avroClick encodes a record of avroSchema.
+func avroClick() []byte {
+	b := avroString(nil, "u1")
+	b = binary.AppendVarint(b, 42)
+	b = binary.AppendVarint(b, 1) // union index of `double`
+	b = binary.LittleEndian.AppendUint64(b, math.Float64bits(0.5))
+	b = binary.AppendVarint(b, 2) // block of 2 items
+	b = avroString(b, "a")
+	b = avroString(b, "b")
+	b = binary.AppendVarint(b, 0) // end of the array
+	b = binary.AppendVarint(b, 1700000000000)
+	b = binary.AppendVarint(b, 1) // GREEN
+	b = binary.AppendVarint(b, 0) // null
+	return b
+}
+
+func TestAvroDecoder(t *testing.T) {
+	d, err := (&Schema{Type: SchemaTypeAvro, Schema: avroSchema}).Decoder()
+	if err != nil {
+		t.Fatalf("failed to compile the schema: %v", err)
+	}
+
+	row, err := d.Decode(avroClick())
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	want := map[string]any{
+		"user_id":   "u1",
+		"count":     42,
+		"score":     0.5,
+		"tags":      []string{"a", "b"},
+		"at":        time.UnixMilli(1700000000000).UTC(),
+		"color":     "GREEN",
+		"secondary": nil,
+	}
+	if !reflect.DeepEqual(row, want) {
+		t.Errorf("unexpected row:\n got: %#v\nwant: %#v", row, want)
+	}
+}
+
+func TestAvroDecoder_Malformed(t *testing.T) {
+	d, err := (&Schema{Type: SchemaTypeAvro, Schema: avroSchema}).Decoder()
+	if err != nil {
+		t.Fatalf("failed to compile the schema: %v", err)
+	}
+
+	msg := avroClick()
+	for i := 0; i < len(msg); i++ {
+		if _, err := d.Decode(msg[:i]); !errors.Is(err, ErrMalformedMessage) {
+			t.Errorf("expected a malformed message error for %d bytes, got %v", i, err)
+		}
+	}
+
+	// a huge block count must not allocate or loop over the (missing) items
+	b := avroString(nil, "u1")
+	b = binary.AppendVarint(b, 42)
+	b = binary.AppendVarint(b, 0)
+	b = binary.AppendVarint(b, math.MaxInt32)
+	if _, err := d.Decode(b); !errors.Is(err, ErrMalformedMessage) {
+		t.Errorf("expected a malformed message error, got %v", err)
+	}
+}
+
+func TestAvroDecoder_InvalidSchema(t *testing.T) {
+	for _, schema := range []string{`not json`, `"string"`, `{"type": "enum", "name": "E", "symbols": []}`} {
+		if _, err := (&Schema{Type: SchemaTypeAvro, Schema: schema}).Decoder(); err == nil {
+			t.Errorf("expected an error for schema %s", schema)
+		}
+	}
+}
+
+func protobufClick(t *testing.T, d Decoder) []byte {
+	md := d.(*protobufDecoder).file.Messages().ByName("Click")
+	msg := dynamicpb.NewMessage(md)
+	fields := md.Fields()
+	msg.Set(fields.ByName("user_id"), protoreflect.ValueOfString("u1"))
+	msg.Set(fields.ByName("count"), protoreflect.ValueOfInt64(42))
+	tags := msg.Mutable(fields.ByName("tags")).List()
+	tags.Append(protoreflect.ValueOfString("a"))
+	tags.Append(protoreflect.ValueOfString("b"))
+	msg.Set(fields.ByName("color"), protoreflect.ValueOfEnum(1))
+
+	buf, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	// the message indexes of the first message
+	return append([]byte{0}, buf...)
+}
+
+func TestProtobufDecoder(t *testing.T) {
+	d, err := (&Schema{Type: SchemaTypeProtobuf, Schema: protobufSchema}).Decoder()
+	if err != nil {
+		t.Fatalf("failed to compile the schema: %v", err)
+	}
+
+	row, err := d.Decode(protobufClick(t, d))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	want := map[string]any{
+		"user_id": "u1",
+		"count":   42,
+		"tags":    []string{"a", "b"},
+		"color":   "GREEN",
+	}
+	if !reflect.DeepEqual(row, want) {
+		t.Errorf("unexpected row:\n got: %#v\nwant: %#v", row, want)
+	}
+
+	for _, msg := range [][]byte{nil, {4, 2}, {2, 0xff}} {
+		if _, err := d.Decode(msg); !errors.Is(err, ErrMalformedMessage) {
+			t.Errorf("expected a malformed message error for %v, got %v", msg, err)
+		}
+	}
+}
+
+func TestClient_Decode(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/schemas/ids/7":
+			_ = json.NewEncoder(w).Encode(Schema{Schema: avroSchema})
+		case "/subjects/clicks-value/versions/latest":
+			_ = json.NewEncoder(w).Encode(Schema{ID: 7, Subject: "clicks-value", Version: 1, Schema: avroSchema})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	msg := append([]byte{0, 0, 0, 0, 7}, avroClick()...)
+	for i := 0; i < 3; i++ {
+		row, err := c.Decode(context.Background(), msg)
+		if err != nil {
+			t.Fatalf("failed to decode: %v", err)
+		}
+		if row["user_id"] != "u1" {
+			t.Errorf("unexpected row: %v", row)
+		}
+		if _, err := c.Latest(context.Background(), TopicSubject("clicks")); err != nil {
+			t.Fatalf("failed to get the latest schema: %v", err)
+		}
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("expected the schemas to be cached, got %d requests", n)
+	}
+
+	if _, err := c.Decode(context.Background(), []byte{1, 2}); err == nil {
+		t.Errorf("expected an error for a message without the wire format")
+	}
+}
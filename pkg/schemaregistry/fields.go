@@ -0,0 +1,253 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"regexp"
+	"strings"
+)
+
+// Fields returns the top-level fields of the schema and their primitive types.
+// Fields with types that cannot be represented as a primitive (i.e. nested records) are returned as PrimitiveTypeUnknown.
+func (s *Schema) Fields() (map[string]api.PrimitiveType, error) {
+	switch s.Type {
+	case SchemaTypeAvro, "":
+		return avroFields(s.Schema)
+	case SchemaTypeProtobuf:
+		return protobufFields(s.Schema)
+	case SchemaTypeJSON:
+		return jsonSchemaFields(s.Schema)
+	default:
+		return nil, fmt.Errorf("unsupported schema type `%s`", s.Type)
+	}
+}
+
+// Compatible checks if a field of the given type can be used as a value of a feature with the given primitive.
+func Compatible(field, feature api.PrimitiveType) bool {
+	if field == api.PrimitiveTypeUnknown {
+		return false
+	}
+	if field == feature {
+		return true
+	}
+	// integers are safely widened to floats
+	return field.Singular() == api.PrimitiveTypeInteger && feature.Singular() == api.PrimitiveTypeFloat &&
+		field.Scalar() == feature.Scalar()
+}
+
+func avroFields(schema string) (map[string]api.PrimitiveType, error) {
+	var record struct {
+		Type   any `json:"type"`
+		Fields []struct {
+			Name string `json:"name"`
+			Type any    `json:"type"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(schema), &record); err != nil {
+		return nil, fmt.Errorf("failed to parse avro schema: %w", err)
+	}
+	if record.Type != "record" {
+		return nil, fmt.Errorf("avro schema must be a record, got `%v`", record.Type)
+	}
+
+	ret := make(map[string]api.PrimitiveType, len(record.Fields))
+	for _, f := range record.Fields {
+		ret[f.Name] = avroType(f.Type)
+	}
+	return ret, nil
+}
+
+func avroType(t any) api.PrimitiveType {
+	switch t := t.(type) {
+	case string:
+		switch t {
+		case "int", "long":
+			return api.PrimitiveTypeInteger
+		case "float", "double":
+			return api.PrimitiveTypeFloat
+		case "string":
+			return api.PrimitiveTypeString
+		case "boolean":
+			return api.PrimitiveTypeBoolean
+		}
+	case []any:
+		// unions are supported only for optional values. i.e. ["null", "string"]
+		var ret []api.PrimitiveType
+		for _, u := range t {
+			if u != "null" {
+				ret = append(ret, avroType(u))
+			}
+		}
+		if len(ret) == 1 {
+			return ret[0]
+		}
+	case map[string]any:
+		switch t["logicalType"] {
+		case "timestamp-millis", "timestamp-micros", "local-timestamp-millis", "local-timestamp-micros":
+			return api.PrimitiveTypeTimestamp
+		}
+		switch t["type"] {
+		case "array":
+			if items := avroType(t["items"]); items.Scalar() && items != api.PrimitiveTypeUnknown {
+				return items.Plural()
+			}
+		case "enum":
+			return api.PrimitiveTypeString
+		case "record", "map", "fixed":
+		default:
+			return avroType(t["type"])
+		}
+	}
+	return api.PrimitiveTypeUnknown
+}
+
+var (
+	protoMessageRe = regexp.MustCompile(`^message\s+(\w+)\s*\{`)
+	protoEnumRe    = regexp.MustCompile(`^enum\s+(\w+)\s*\{`)
+	protoFieldRe   = regexp.MustCompile(`^(repeated\s+|optional\s+)?([\w.]+)\s+(\w+)\s*=\s*\d+`)
+)
+
+// protobufFields extracts the fields of the first message of a `.proto` schema.
+// This is the message that is used by the default serializers when the message indexes are omitted.
+func protobufFields(schema string) (map[string]api.PrimitiveType, error) {
+	enums := make(map[string]bool)
+	for _, line := range strings.Split(schema, "\n") {
+		if m := protoEnumRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			enums[m[1]] = true
+		}
+	}
+
+	ret := make(map[string]api.PrimitiveType)
+	depth := 0
+	inMessage := false
+	// oneof blocks are part of the message, so their fields are considered as top-level fields
+	oneofDepth := 0
+	for _, line := range strings.Split(schema, "\n") {
+		line = strings.TrimSpace(line)
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case !inMessage && depth == 0 && protoMessageRe.MatchString(line):
+			inMessage = true
+		case inMessage && (depth == 1 || (oneofDepth > 0 && depth == oneofDepth)):
+			if strings.HasPrefix(line, "oneof ") {
+				oneofDepth = depth + 1
+			} else if m := protoFieldRe.FindStringSubmatch(line); m != nil {
+				pt := protoType(m[2], enums)
+				if strings.TrimSpace(m[1]) == "repeated" {
+					if pt.Scalar() && pt != api.PrimitiveTypeUnknown {
+						pt = pt.Plural()
+					} else {
+						pt = api.PrimitiveTypeUnknown
+					}
+				}
+				ret[m[3]] = pt
+			}
+		}
+
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if oneofDepth > 0 && depth < oneofDepth {
+			oneofDepth = 0
+		}
+		if inMessage && depth == 0 {
+			break
+		}
+	}
+	if !inMessage {
+		return nil, fmt.Errorf("no message found in protobuf schema")
+	}
+	return ret, nil
+}
+
+func protoType(t string, enums map[string]bool) api.PrimitiveType {
+	switch t {
+	case "int32", "int64", "uint32", "uint64", "sint32", "sint64", "fixed32", "fixed64", "sfixed32", "sfixed64":
+		return api.PrimitiveTypeInteger
+	case "float", "double":
+		return api.PrimitiveTypeFloat
+	case "string":
+		return api.PrimitiveTypeString
+	case "bool":
+		return api.PrimitiveTypeBoolean
+	case "google.protobuf.Timestamp":
+		return api.PrimitiveTypeTimestamp
+	}
+	if enums[t] {
+		return api.PrimitiveTypeString
+	}
+	return api.PrimitiveTypeUnknown
+}
+
+func jsonSchemaFields(schema string) (map[string]api.PrimitiveType, error) {
+	var s struct {
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(schema), &s); err != nil {
+		return nil, fmt.Errorf("failed to parse json schema: %w", err)
+	}
+
+	ret := make(map[string]api.PrimitiveType, len(s.Properties))
+	for name, prop := range s.Properties {
+		ret[name] = jsonSchemaType(prop)
+	}
+	return ret, nil
+}
+
+func jsonSchemaType(prop map[string]any) api.PrimitiveType {
+	t := prop["type"]
+	if types, ok := t.([]any); ok {
+		// i.e. ["null", "integer"]
+		t = nil
+		for _, v := range types {
+			if v != "null" {
+				if t != nil {
+					return api.PrimitiveTypeUnknown
+				}
+				t = v
+			}
+		}
+	}
+
+	switch t {
+	case "integer":
+		return api.PrimitiveTypeInteger
+	case "number":
+		return api.PrimitiveTypeFloat
+	case "boolean":
+		return api.PrimitiveTypeBoolean
+	case "string":
+		if prop["format"] == "date-time" {
+			return api.PrimitiveTypeTimestamp
+		}
+		return api.PrimitiveTypeString
+	case "array":
+		if items, ok := prop["items"].(map[string]any); ok {
+			if it := jsonSchemaType(items); it.Scalar() && it != api.PrimitiveTypeUnknown {
+				return it.Plural()
+			}
+		}
+	}
+	return api.PrimitiveTypeUnknown
+}
@@ -18,11 +18,14 @@ package sdk
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/raptor-ml/raptor/api"
 	coreApi "github.com/raptor-ml/raptor/api/proto/gen/go/core/v1alpha1"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"strings"
@@ -61,7 +64,12 @@ func (e *grpcEngine) Get(ctx context.Context, selector string, keys api.Keys) (a
 		Keys:     keys,
 	}
 	ret := api.Value{}
-	resp, err := e.client.Get(ctx, &req)
+	ctx, err := outgoingRequestContext(outgoingCaller(ctx))
+	if err != nil {
+		return ret, api.FeatureDescriptor{}, err
+	}
+	var header metadata.MD
+	resp, err := e.client.Get(ctx, &req, grpc.Header(&header))
 	if err != nil {
 		return ret, api.FeatureDescriptor{}, fmt.Errorf("failed to get feature: %w", normalizeError(err))
 	}
@@ -73,6 +81,9 @@ func (e *grpcEngine) Get(ctx context.Context, selector string, keys api.Keys) (a
 	ret.Value = FromValue(resp.Value.Value)
 	ret.Timestamp = resp.Value.Timestamp.AsTime()
 	ret.Fresh = resp.Value.Fresh
+	ret.Source = FromAPIValueSource(resp.Value.Source)
+	ret.Fallback = len(header.Get(fallbackMetadataKey)) > 0 && header.Get(fallbackMetadataKey)[0] == "true"
+	ret.Masked = len(header.Get(maskedMetadataKey)) > 0 && header.Get(maskedMetadataKey)[0] == "true"
 	return ret, FromAPIFeatureDescriptor(resp.FeatureDescriptor), nil
 }
 func (e *grpcEngine) Set(ctx context.Context, fqn string, keys api.Keys, val any, ts time.Time) error {
@@ -83,7 +94,7 @@ func (e *grpcEngine) Set(ctx context.Context, fqn string, keys api.Keys, val any
 		Value:     ToAPIValue(val),
 		Timestamp: timestamppb.New(ts),
 	}
-	resp, err := e.client.Set(ctx, &req)
+	resp, err := e.client.Set(outgoingEventID(outgoingCaller(ctx)), &req)
 	if err != nil {
 		return normalizeError(err)
 	}
@@ -100,7 +111,7 @@ func (e *grpcEngine) Append(ctx context.Context, fqn string, keys api.Keys, val
 		Value:     ToAPIScalar(val),
 		Timestamp: timestamppb.New(ts),
 	}
-	resp, err := e.client.Append(ctx, &req)
+	resp, err := e.client.Append(outgoingEventID(outgoingCaller(ctx)), &req)
 	if err != nil {
 		return normalizeError(err)
 	}
@@ -117,7 +128,7 @@ func (e *grpcEngine) Incr(ctx context.Context, fqn string, keys api.Keys, by any
 		Value:     ToAPIScalar(by),
 		Timestamp: timestamppb.New(ts),
 	}
-	resp, err := e.client.Incr(ctx, &req)
+	resp, err := e.client.Incr(outgoingEventID(outgoingCaller(ctx)), &req)
 	if err != nil {
 		return normalizeError(err)
 	}
@@ -134,7 +145,33 @@ func (e *grpcEngine) Update(ctx context.Context, fqn string, keys api.Keys, val
 		Value:     ToAPIValue(val),
 		Timestamp: timestamppb.New(ts),
 	}
-	resp, err := e.client.Update(ctx, &req)
+	resp, err := e.client.Update(outgoingEventID(outgoingCaller(ctx)), &req)
+	if err != nil {
+		return normalizeError(err)
+	}
+	if resp.Uuid != req.Uuid {
+		return fmt.Errorf("got %s uuid but requested with %s", resp.Uuid, req.Uuid)
+	}
+	return nil
+}
+
+// WriteTransaction implements api.Transactor
+func (e *grpcEngine) WriteTransaction(ctx context.Context, writes []api.FeatureWrite) error {
+	req := coreApi.TransactionRequest{
+		Uuid:   uuid.NewString(),
+		Writes: make([]*coreApi.TransactionWrite, 0, len(writes)),
+	}
+	for _, w := range writes {
+		req.Writes = append(req.Writes, &coreApi.TransactionWrite{
+			Method:    ToAPIWriteMethod(w.Method),
+			Selector:  w.FQN,
+			Keys:      w.Keys,
+			Value:     ToAPIValue(w.Value),
+			Timestamp: timestamppb.New(w.Timestamp),
+			EventId:   w.EventID,
+		})
+	}
+	resp, err := e.client.Transaction(outgoingEventID(outgoingCaller(ctx)), &req)
 	if err != nil {
 		return normalizeError(err)
 	}
@@ -144,6 +181,54 @@ func (e *grpcEngine) Update(ctx context.Context, fqn string, keys api.Keys, val
 	return nil
 }
 
+// eventIDMetadataKey is the gRPC metadata key that carries the ID of the event that caused a write.
+const eventIDMetadataKey = "x-raptor-event-id"
+
+// outgoingEventID propagates the event ID of the context (if any) to the engine, so it can deduplicate the event.
+func outgoingEventID(ctx context.Context) context.Context {
+	if id, ok := ctx.Value(api.ContextKeyEventID).(string); ok && id != "" {
+		return metadata.AppendToOutgoingContext(ctx, eventIDMetadataKey, id)
+	}
+	return ctx
+}
+
+// callerMetadataKey is the gRPC metadata key that carries the identity of the caller, which is recorded in the
+// audit log. Over HTTP, it can be sent as the `Grpc-Metadata-X-Raptor-Caller` header.
+const callerMetadataKey = "x-raptor-caller"
+
+// outgoingCaller propagates the identity of the caller of the context (if any) to the engine.
+func outgoingCaller(ctx context.Context) context.Context {
+	if caller := api.CallerFromContext(ctx); caller != "" {
+		return metadata.AppendToOutgoingContext(ctx, callerMetadataKey, caller)
+	}
+	return ctx
+}
+
+// requestContextMetadataKey is the gRPC metadata key that carries the (JSON encoded) request context of a Get.
+// Over HTTP, it can be sent as the `Grpc-Metadata-X-Raptor-Request-Context` header.
+const requestContextMetadataKey = "x-raptor-request-context"
+
+// outgoingRequestContext propagates the request context (if any) to the engine, so it can compute on-demand features.
+func outgoingRequestContext(ctx context.Context) (context.Context, error) {
+	rc := api.RequestContextFromContext(ctx)
+	if len(rc) == 0 {
+		return ctx, nil
+	}
+	b, err := json.Marshal(rc)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to encode the request context: %w", err)
+	}
+	return metadata.AppendToOutgoingContext(ctx, requestContextMetadataKey, string(b)), nil
+}
+
+// fallbackMetadataKey is the gRPC header key that marks the value of a Get as the fallback of the feature.
+// Over HTTP, it's sent as the `Grpc-Metadata-X-Raptor-Fallback` header.
+const fallbackMetadataKey = "x-raptor-fallback"
+
+// maskedMetadataKey is the gRPC header key that marks the value of a Get as masked, since the caller isn't allowed to
+// read the plain value of the sensitive feature. Over HTTP, it's sent as the `Grpc-Metadata-X-Raptor-Masked` header.
+const maskedMetadataKey = "x-raptor-masked"
+
 func normalizeError(err error) error {
 	if err == nil {
 		return nil
@@ -159,6 +244,12 @@ func normalizeError(err error) error {
 	if e.Code() == codes.NotFound {
 		return api.ErrFeatureNotFound
 	}
+	if e.Code() == codes.PermissionDenied {
+		return fmt.Errorf("%w: %s", api.ErrPermissionDenied, e.Message())
+	}
+	if e.Code() == codes.Unimplemented && strings.HasPrefix(e.Message(), api.ErrTransactionsUnsupported.Error()) {
+		return fmt.Errorf("%w: %s", api.ErrTransactionsUnsupported, e.Message())
+	}
 	if strings.HasSuffix(e.Err().Error(), api.ErrUnsupportedPrimitiveError.Error()) {
 		return api.ErrUnsupportedPrimitiveError
 	}
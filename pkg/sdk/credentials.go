@@ -0,0 +1,54 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc/credentials"
+	"os"
+	"strings"
+)
+
+// serviceAccountTokenPath is the path of the projected token of the pod's Kubernetes ServiceAccount.
+const serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token" //nolint:gosec //pragma: allowlist secret
+
+type serviceAccountCredentials struct{}
+
+// ServiceAccountCredentials authenticates the requests to the Core with the token of the pod's Kubernetes
+// ServiceAccount, so the requests are authorized by the AccessPolicies when the Core's authentication is enabled.
+// The token is read on every request, since it's rotated by the kubelet. No credentials are sent outside a pod.
+func ServiceAccountCredentials() credentials.PerRPCCredentials {
+	return serviceAccountCredentials{}
+}
+
+// GetRequestMetadata implements credentials.PerRPCCredentials
+func (serviceAccountCredentials) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	token, err := os.ReadFile(serviceAccountTokenPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read the ServiceAccount token: %w", err)
+	}
+	return map[string]string{"authorization": "Bearer " + strings.TrimSpace(string(token))}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials
+func (serviceAccountCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -18,10 +18,15 @@ package sdk
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/raptor-ml/raptor/api"
 	coreApi "github.com/raptor-ml/raptor/api/proto/gen/go/core/v1alpha1"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"strings"
@@ -45,6 +50,9 @@ func (s *serviceServer) FeatureDescriptor(ctx context.Context, req *coreApi.Feat
 		if errors.Is(err, api.ErrFeatureNotFound) {
 			return nil, status.Errorf(codes.NotFound, "feature not found")
 		}
+		if errors.Is(err, api.ErrPermissionDenied) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
 		return nil, status.Errorf(codes.Internal, "failed to get FeatureDescriptor: %s", err)
 	}
 	return &coreApi.FeatureDescriptorResponse{
@@ -53,11 +61,21 @@ func (s *serviceServer) FeatureDescriptor(ctx context.Context, req *coreApi.Feat
 	}, nil
 }
 func (s *serviceServer) Get(ctx context.Context, req *coreApi.GetRequest) (*coreApi.GetResponse, error) {
+	ctx, err := incomingRequestContext(incomingCaller(ctx))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid request context: %s", err)
+	}
 	resp, fd, err := s.engine.Get(ctx, req.GetSelector(), req.GetKeys())
 	if err != nil {
 		if errors.Is(err, api.ErrFeatureNotFound) {
 			return nil, status.Errorf(codes.NotFound, "feature not found")
 		}
+		if errors.Is(err, api.ErrPermissionDenied) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+		if errors.Is(err, api.ErrInvalidEntityID) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
 		return nil, status.Errorf(codes.Internal, "failed to get value: %s", err)
 	}
 
@@ -88,19 +106,34 @@ func (s *serviceServer) Get(ctx context.Context, req *coreApi.GetRequest) (*core
 			Keys:      req.GetKeys(),
 			Value:     ToAPIValue(val),
 			Timestamp: timestamppb.New(resp.Timestamp),
+			Fresh:     resp.Fresh,
+			Stale:     resp.Stale(),
+			Source:    ToAPIValueSource(resp.Source),
 		},
 		FeatureDescriptor: ToAPIFeatureDescriptor(fd),
 	}
+	if resp.Fallback {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(fallbackMetadataKey, "true"))
+	}
+	if resp.Masked {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(maskedMetadataKey, "true"))
+	}
 
 	return ret, nil
 }
 
 func (s *serviceServer) Set(ctx context.Context, req *coreApi.SetRequest) (*coreApi.SetResponse, error) {
-	err := s.engine.Set(ctx, req.GetSelector(), req.GetKeys(), FromValue(req.Value), req.Timestamp.AsTime())
+	err := s.engine.Set(incomingEventID(incomingCaller(ctx)), req.GetSelector(), req.GetKeys(), FromValue(req.Value), req.Timestamp.AsTime())
 	if err != nil {
 		if errors.Is(err, api.ErrFeatureNotFound) {
 			return nil, status.Errorf(codes.NotFound, "feature not found")
 		}
+		if errors.Is(err, api.ErrPermissionDenied) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+		if errors.Is(err, api.ErrInvalidEntityID) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
 		return nil, status.Errorf(codes.Internal, "failed to set value: %s", err)
 	}
 	return &coreApi.SetResponse{
@@ -109,11 +142,17 @@ func (s *serviceServer) Set(ctx context.Context, req *coreApi.SetRequest) (*core
 	}, nil
 }
 func (s *serviceServer) Append(ctx context.Context, req *coreApi.AppendRequest) (*coreApi.AppendResponse, error) {
-	err := s.engine.Append(ctx, req.GetFqn(), req.GetKeys(), fromScalar(req.Value), req.Timestamp.AsTime())
+	err := s.engine.Append(incomingEventID(incomingCaller(ctx)), req.GetFqn(), req.GetKeys(), fromScalar(req.Value), req.Timestamp.AsTime())
 	if err != nil {
 		if errors.Is(err, api.ErrFeatureNotFound) {
 			return nil, status.Errorf(codes.NotFound, "feature not found")
 		}
+		if errors.Is(err, api.ErrPermissionDenied) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+		if errors.Is(err, api.ErrInvalidEntityID) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
 		return nil, status.Errorf(codes.Internal, "failed to append value: %s", err)
 	}
 	return &coreApi.AppendResponse{
@@ -122,11 +161,17 @@ func (s *serviceServer) Append(ctx context.Context, req *coreApi.AppendRequest)
 	}, nil
 }
 func (s *serviceServer) Incr(ctx context.Context, req *coreApi.IncrRequest) (*coreApi.IncrResponse, error) {
-	err := s.engine.Incr(ctx, req.GetFqn(), req.GetKeys(), fromScalar(req.Value), req.Timestamp.AsTime())
+	err := s.engine.Incr(incomingEventID(incomingCaller(ctx)), req.GetFqn(), req.GetKeys(), fromScalar(req.Value), req.Timestamp.AsTime())
 	if err != nil {
 		if errors.Is(err, api.ErrFeatureNotFound) {
 			return nil, status.Errorf(codes.NotFound, "feature not found")
 		}
+		if errors.Is(err, api.ErrPermissionDenied) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+		if errors.Is(err, api.ErrInvalidEntityID) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
 		return nil, status.Errorf(codes.Internal, "failed to incr value: %s", err)
 	}
 	return &coreApi.IncrResponse{
@@ -135,11 +180,17 @@ func (s *serviceServer) Incr(ctx context.Context, req *coreApi.IncrRequest) (*co
 	}, nil
 }
 func (s *serviceServer) Update(ctx context.Context, req *coreApi.UpdateRequest) (*coreApi.UpdateResponse, error) {
-	err := s.engine.Update(ctx, req.GetSelector(), req.GetKeys(), FromValue(req.Value), req.Timestamp.AsTime())
+	err := s.engine.Update(incomingEventID(incomingCaller(ctx)), req.GetSelector(), req.GetKeys(), FromValue(req.Value), req.Timestamp.AsTime())
 	if err != nil {
 		if errors.Is(err, api.ErrFeatureNotFound) {
 			return nil, status.Errorf(codes.NotFound, "feature not found")
 		}
+		if errors.Is(err, api.ErrPermissionDenied) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+		if errors.Is(err, api.ErrInvalidEntityID) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
 		return nil, status.Errorf(codes.Internal, "failed to update value: %s", err)
 	}
 	return &coreApi.UpdateResponse{
@@ -147,3 +198,99 @@ func (s *serviceServer) Update(ctx context.Context, req *coreApi.UpdateRequest)
 		Timestamp: timestamppb.Now(),
 	}, nil
 }
+
+func (s *serviceServer) Transaction(ctx context.Context, req *coreApi.TransactionRequest) (*coreApi.TransactionResponse, error) {
+	tx, ok := s.engine.(api.Transactor)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "%s", api.ErrTransactionsUnsupported)
+	}
+
+	writes := make([]api.FeatureWrite, 0, len(req.GetWrites()))
+	for _, w := range req.GetWrites() {
+		writes = append(writes, api.FeatureWrite{
+			FQN:       w.GetSelector(),
+			Method:    FromAPIWriteMethod(w.GetMethod()),
+			Keys:      w.GetKeys(),
+			Value:     FromValue(w.Value),
+			Timestamp: w.Timestamp.AsTime(),
+			EventID:   w.GetEventId(),
+		})
+	}
+	if err := tx.WriteTransaction(incomingEventID(incomingCaller(ctx)), writes); err != nil {
+		if errors.Is(err, api.ErrFeatureNotFound) {
+			return nil, status.Errorf(codes.NotFound, "feature not found")
+		}
+		if errors.Is(err, api.ErrPermissionDenied) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+		if errors.Is(err, api.ErrInvalidEntityID) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		if errors.Is(err, api.ErrTransactionsUnsupported) {
+			return nil, status.Errorf(codes.Unimplemented, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to write transaction: %s", err)
+	}
+	return &coreApi.TransactionResponse{
+		Uuid:      req.GetUuid(),
+		Timestamp: timestamppb.Now(),
+	}, nil
+}
+
+// incomingEventID extracts the event ID from the request metadata (if any) into the context.
+func incomingEventID(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	if ids := md.Get(eventIDMetadataKey); len(ids) > 0 && ids[0] != "" {
+		return context.WithValue(ctx, api.ContextKeyEventID, ids[0])
+	}
+	return ctx
+}
+
+// incomingCaller extracts the identity of the caller from the request metadata into the context. It falls back to
+// the address of the client when the caller didn't identify itself. An authenticated caller (that was already
+// attached to the context by the accessor) always takes precedence.
+func incomingCaller(ctx context.Context) context.Context {
+	if api.CallerFromContext(ctx) != "" {
+		return ctx
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if callers := md.Get(callerMetadataKey); len(callers) > 0 && callers[0] != "" {
+		return api.WithCaller(ctx, callers[0])
+	}
+	// requests of the HTTP gateway are served in-process, so the client address is forwarded by the gateway
+	if addrs := md.Get("x-forwarded-for"); len(addrs) > 0 && addrs[0] != "" {
+		return api.WithCaller(ctx, addrs[0])
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return api.WithCaller(ctx, p.Addr.String())
+	}
+	return ctx
+}
+
+// incomingRequestContext extracts the request context of a Get from the request metadata (if any) into the context.
+func incomingRequestContext(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+	vals := md.Get(requestContextMetadataKey)
+	if len(vals) == 0 || vals[0] == "" {
+		return ctx, nil
+	}
+
+	rc := make(map[string]any)
+	if err := json.Unmarshal([]byte(vals[0]), &rc); err != nil {
+		return ctx, err
+	}
+	for k, v := range rc {
+		nv, err := api.NormalizeAny(v)
+		if err != nil {
+			return ctx, fmt.Errorf("failed to normalize `%s`: %w", k, err)
+		}
+		rc[k] = nv
+	}
+	return api.WithRequestContext(ctx, rc), nil
+}
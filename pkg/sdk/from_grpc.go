@@ -70,6 +70,32 @@ func FromAPIAggrFuncs(fs []coreApi.AggrFn) []api.AggrFn {
 	}
 	return afs
 }
+func FromAPIValueSource(src coreApi.ValueSource) api.ValueSource {
+	switch src {
+	default:
+		return ""
+	case coreApi.ValueSource_VALUE_SOURCE_STATE:
+		return api.ValueSourceState
+	case coreApi.ValueSource_VALUE_SOURCE_CACHE:
+		return api.ValueSourceCache
+	case coreApi.ValueSource_VALUE_SOURCE_FALLBACK:
+		return api.ValueSourceFallback
+	case coreApi.ValueSource_VALUE_SOURCE_COMPUTED:
+		return api.ValueSourceComputed
+	}
+}
+func FromAPIWriteMethod(m coreApi.WriteMethod) api.StateMethod {
+	switch m {
+	default:
+		return api.StateMethodUpdate
+	case coreApi.WriteMethod_WRITE_METHOD_SET:
+		return api.StateMethodSet
+	case coreApi.WriteMethod_WRITE_METHOD_APPEND:
+		return api.StateMethodAppend
+	case coreApi.WriteMethod_WRITE_METHOD_INCR:
+		return api.StateMethodIncr
+	}
+}
 func FromAPIFeatureDescriptor(m *coreApi.FeatureDescriptor) api.FeatureDescriptor {
 	var kp *api.KeepPrevious
 	if m.KeepPrevious != nil {
@@ -86,8 +112,10 @@ func FromAPIFeatureDescriptor(m *coreApi.FeatureDescriptor) api.FeatureDescripto
 		Staleness:    m.Staleness.AsDuration(),
 		Timeout:      m.Timeout.AsDuration(),
 		KeepPrevious: kp,
+		Keys:         m.Keys,
 		Builder:      m.Builder,
 		DataSource:   m.DataSource,
+		RuntimeEnv:   m.RuntimeEnv,
 	}
 }
 
@@ -39,6 +39,7 @@ func ToAPIScalar(val any) *coreApi.Scalar {
 	case api.PrimitiveTypeBoolean:
 		return &coreApi.Scalar{Value: &coreApi.Scalar_BoolValue{BoolValue: val.(bool)}}
 	case api.PrimitiveTypeTimestamp:
+		// gRPC Timestamps have no zone, so the offset of `original` normalized timestamps is dropped
 		return &coreApi.Scalar{Value: &coreApi.Scalar_TimestampValue{TimestampValue: timestamppb.New(val.(time.Time))}}
 	default:
 		panic(fmt.Sprintf("unsupported type - is it scalar? (%v)", primitive.Scalar()))
@@ -119,6 +120,33 @@ func ToAPIAggrFns(fs []api.AggrFn) []coreApi.AggrFn {
 	}
 	return ret
 }
+func ToAPIValueSource(src api.ValueSource) coreApi.ValueSource {
+	switch src {
+	default:
+		return coreApi.ValueSource_VALUE_SOURCE_UNSPECIFIED
+	case api.ValueSourceState:
+		return coreApi.ValueSource_VALUE_SOURCE_STATE
+	case api.ValueSourceCache:
+		return coreApi.ValueSource_VALUE_SOURCE_CACHE
+	case api.ValueSourceFallback:
+		return coreApi.ValueSource_VALUE_SOURCE_FALLBACK
+	case api.ValueSourceComputed:
+		return coreApi.ValueSource_VALUE_SOURCE_COMPUTED
+	}
+}
+
+func ToAPIWriteMethod(m api.StateMethod) coreApi.WriteMethod {
+	switch m {
+	default:
+		return coreApi.WriteMethod_WRITE_METHOD_UPDATE
+	case api.StateMethodSet:
+		return coreApi.WriteMethod_WRITE_METHOD_SET
+	case api.StateMethodAppend:
+		return coreApi.WriteMethod_WRITE_METHOD_APPEND
+	case api.StateMethodIncr:
+		return coreApi.WriteMethod_WRITE_METHOD_INCR
+	}
+}
 
 func ToAPIFeatureDescriptor(fd api.FeatureDescriptor) *coreApi.FeatureDescriptor {
 	var kp *coreApi.KeepPrevious
@@ -136,8 +164,10 @@ func ToAPIFeatureDescriptor(fd api.FeatureDescriptor) *coreApi.FeatureDescriptor
 		Staleness:    durationpb.New(fd.Staleness),
 		Timeout:      durationpb.New(fd.Timeout),
 		KeepPrevious: kp,
+		Keys:         fd.Keys,
 		Builder:      fd.Builder,
 		DataSource:   fd.DataSource,
+		RuntimeEnv:   fd.RuntimeEnv,
 	}
 
 	return ret
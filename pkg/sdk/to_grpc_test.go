@@ -0,0 +1,35 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"testing"
+	"time"
+)
+
+// gRPC Timestamps have no zone: the instant survives the round-trip, but the offset is dropped.
+func TestScalar_TimestampDropsOffset(t *testing.T) {
+	ts := time.Date(2022, 10, 1, 12, 0, 0, 500, time.FixedZone("IST", 2*60*60))
+
+	got := fromScalar(ToAPIScalar(ts)).(time.Time)
+	if !got.Equal(ts) {
+		t.Errorf("got %s, want %s", got, ts)
+	}
+	if _, off := got.Zone(); off != 0 {
+		t.Errorf("got an offset of %ds, want the offset to be dropped", off)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlexpr
+
+import (
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"math"
+	"strings"
+)
+
+type function struct {
+	minArgs int
+	// maxArgs is -1 for variadic functions
+	maxArgs int
+	// nullable functions are called with NULL arguments. Otherwise, a NULL argument results in NULL.
+	nullable bool
+	typ      func(args []node) api.PrimitiveType
+	eval     func(args []any) (any, error)
+}
+
+func returns(t api.PrimitiveType) func([]node) api.PrimitiveType {
+	return func([]node) api.PrimitiveType { return t }
+}
+
+func numeric(name string, fn func(float64) float64) function {
+	return function{
+		minArgs: 1,
+		maxArgs: 1,
+		typ: func(args []node) api.PrimitiveType {
+			if t := args[0].typ(); t == api.PrimitiveTypeInteger {
+				return t
+			}
+			return api.PrimitiveTypeFloat
+		},
+		eval: func(args []any) (any, error) {
+			if i, ok := args[0].(int); ok {
+				return int(fn(float64(i))), nil
+			}
+			f, ok := toFloat(args[0])
+			if !ok {
+				return nil, fmt.Errorf("`%s` requires a number, got `%v`", name, args[0])
+			}
+			return fn(f), nil
+		},
+	}
+}
+
+var functions = map[string]function{
+	"lower": {minArgs: 1, maxArgs: 1, typ: returns(api.PrimitiveTypeString), eval: func(args []any) (any, error) {
+		return strings.ToLower(fmt.Sprint(args[0])), nil
+	}},
+	"upper": {minArgs: 1, maxArgs: 1, typ: returns(api.PrimitiveTypeString), eval: func(args []any) (any, error) {
+		return strings.ToUpper(fmt.Sprint(args[0])), nil
+	}},
+	"trim": {minArgs: 1, maxArgs: 1, typ: returns(api.PrimitiveTypeString), eval: func(args []any) (any, error) {
+		return strings.TrimSpace(fmt.Sprint(args[0])), nil
+	}},
+	"length": {minArgs: 1, maxArgs: 1, typ: returns(api.PrimitiveTypeInteger), eval: func(args []any) (any, error) {
+		return len([]rune(fmt.Sprint(args[0]))), nil
+	}},
+	"concat": {minArgs: 1, maxArgs: -1, typ: returns(api.PrimitiveTypeString), eval: func(args []any) (any, error) {
+		var sb strings.Builder
+		for _, a := range args {
+			sb.WriteString(fmt.Sprint(a))
+		}
+		return sb.String(), nil
+	}},
+	"coalesce": {minArgs: 1, maxArgs: -1, nullable: true, typ: commonType, eval: func(args []any) (any, error) {
+		for _, a := range args {
+			if a != nil {
+				return a, nil
+			}
+		}
+		return nil, nil
+	}},
+	"abs":   numeric("abs", math.Abs),
+	"floor": numeric("floor", math.Floor),
+	"ceil":  numeric("ceil", math.Ceil),
+	"round": {minArgs: 1, maxArgs: 2, typ: returns(api.PrimitiveTypeFloat), eval: func(args []any) (any, error) {
+		f, ok := toFloat(args[0])
+		if !ok {
+			return nil, fmt.Errorf("`round` requires a number, got `%v`", args[0])
+		}
+		places := 0.0
+		if len(args) > 1 {
+			p, ok := toFloat(args[1])
+			if !ok {
+				return nil, fmt.Errorf("`round` requires a number of decimal places, got `%v`", args[1])
+			}
+			places = p
+		}
+		pow := math.Pow(10, places)
+		return math.Round(f*pow) / pow, nil
+	}},
+}
+
+type call struct {
+	name string
+	args []node
+	fn   function
+}
+
+func (n call) eval(row map[string]any) (any, error) {
+	args := make([]any, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(row)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil && !n.fn.nullable {
+			return nil, nil
+		}
+		args[i] = v
+	}
+	return n.fn.eval(args)
+}
+func (n call) typ() api.PrimitiveType {
+	return n.fn.typ(n.args)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlexpr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokKeyword
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("`%s`", t.val)
+}
+
+var keywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "AS": true,
+	"AND": true, "OR": true, "NOT": true, "IS": true, "NULL": true, "IN": true, "LIKE": true,
+	"TRUE": true, "FALSE": true, "CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true,
+}
+
+func lex(src string) ([]token, error) {
+	var ret []token
+	i := 0
+	for i < len(src) {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			// comment until the end of the line
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])) || src[i] == '_' || src[i] == '.') {
+				i++
+			}
+			word := src[start:i]
+			if keywords[strings.ToUpper(word)] {
+				ret = append(ret, token{kind: tokKeyword, val: strings.ToUpper(word), pos: start})
+			} else {
+				ret = append(ret, token{kind: tokIdent, val: word, pos: start})
+			}
+		case c == '"' || c == '`':
+			// quoted identifier
+			start := i
+			end := strings.IndexByte(src[i+1:], src[i])
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated identifier at position %d", start)
+			}
+			ret = append(ret, token{kind: tokIdent, val: src[i+1 : i+1+end], pos: start})
+			i += end + 2
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.' || src[i] == 'e' || src[i] == 'E' ||
+				((src[i] == '-' || src[i] == '+') && (src[i-1] == 'e' || src[i-1] == 'E'))) {
+				i++
+			}
+			ret = append(ret, token{kind: tokNumber, val: src[start:i], pos: start})
+		case c == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(src) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if src[i] == '\'' {
+					// '' is an escaped quote
+					if i+1 < len(src) && src[i+1] == '\'' {
+						sb.WriteByte('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteByte(src[i])
+				i++
+			}
+			ret = append(ret, token{kind: tokString, val: sb.String(), pos: start})
+		default:
+			start := i
+			if i+1 < len(src) {
+				switch two := src[i : i+2]; two {
+				case "<=", ">=", "<>", "!=", "||":
+					ret = append(ret, token{kind: tokOp, val: two, pos: start})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("+-*/%=<>(),", c) {
+				return nil, fmt.Errorf("unexpected character `%c` at position %d", c, start)
+			}
+			ret = append(ret, token{kind: tokOp, val: string(c), pos: start})
+			i++
+		}
+	}
+	return append(ret, token{kind: tokEOF, pos: len(src)}), nil
+}
@@ -0,0 +1,433 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlexpr
+
+import (
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// node is a compiled expression. NULL values are represented by nil.
+type node interface {
+	eval(row map[string]any) (any, error)
+	// typ is the statically inferred type of the node. It is PrimitiveTypeUnknown when it depends on the row.
+	typ() api.PrimitiveType
+}
+
+type literal struct {
+	val any
+}
+
+func (n literal) eval(map[string]any) (any, error) {
+	return n.val, nil
+}
+func (n literal) typ() api.PrimitiveType {
+	if n.val == nil {
+		return api.PrimitiveTypeUnknown
+	}
+	return api.TypeDetect(n.val)
+}
+
+type column struct {
+	name string
+	path *api.JSONPath
+}
+
+func (n column) eval(row map[string]any) (any, error) {
+	if v, ok := row[n.name]; ok {
+		return v, nil
+	}
+	return n.path.Get(row), nil
+}
+func (n column) typ() api.PrimitiveType {
+	return api.PrimitiveTypeUnknown
+}
+
+type logical struct {
+	op          string
+	left, right node
+}
+
+func (n logical) eval(row map[string]any) (any, error) {
+	l, err := n.left.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	// short-circuit
+	if n.op == "AND" && !truthy(l) {
+		return false, nil
+	}
+	if n.op == "OR" && truthy(l) {
+		return true, nil
+	}
+	r, err := n.right.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(r), nil
+}
+func (n logical) typ() api.PrimitiveType {
+	return api.PrimitiveTypeBoolean
+}
+
+type negate struct {
+	n node
+}
+
+func (n negate) eval(row map[string]any) (any, error) {
+	v, err := n.n.eval(row)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+func (n negate) typ() api.PrimitiveType {
+	return api.PrimitiveTypeBoolean
+}
+
+type compare struct {
+	op          string
+	left, right node
+}
+
+func (n compare) eval(row map[string]any) (any, error) {
+	l, err := n.left.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil || r == nil {
+		return nil, nil
+	}
+
+	c, err := compareValues(l, r)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "=":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator `%s`", n.op)
+	}
+}
+func (n compare) typ() api.PrimitiveType {
+	return api.PrimitiveTypeBoolean
+}
+
+type isNull struct {
+	n   node
+	not bool
+}
+
+func (n isNull) eval(row map[string]any) (any, error) {
+	v, err := n.n.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	return (v == nil) != n.not, nil
+}
+func (n isNull) typ() api.PrimitiveType {
+	return api.PrimitiveTypeBoolean
+}
+
+type in struct {
+	n    node
+	list []node
+}
+
+func (n in) eval(row map[string]any) (any, error) {
+	v, err := n.n.eval(row)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	for _, item := range n.list {
+		iv, err := item.eval(row)
+		if err != nil {
+			return nil, err
+		}
+		if iv == nil {
+			continue
+		}
+		if c, err := compareValues(v, iv); err == nil && c == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+func (n in) typ() api.PrimitiveType {
+	return api.PrimitiveTypeBoolean
+}
+
+var likeCache sync.Map
+
+type like struct {
+	n, pattern node
+}
+
+func (n like) eval(row map[string]any) (any, error) {
+	v, err := n.n.eval(row)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	p, err := n.pattern.eval(row)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	pattern := fmt.Sprint(p)
+
+	var re *regexp.Regexp
+	if cached, ok := likeCache.Load(pattern); ok {
+		re = cached.(*regexp.Regexp)
+	} else {
+		var sb strings.Builder
+		sb.WriteString("(?s)^")
+		for _, c := range pattern {
+			switch c {
+			case '%':
+				sb.WriteString(".*")
+			case '_':
+				sb.WriteString(".")
+			default:
+				sb.WriteString(regexp.QuoteMeta(string(c)))
+			}
+		}
+		sb.WriteString("$")
+		re, err = regexp.Compile(sb.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid LIKE pattern `%s`: %w", pattern, err)
+		}
+		likeCache.Store(pattern, re)
+	}
+	return re.MatchString(fmt.Sprint(v)), nil
+}
+func (n like) typ() api.PrimitiveType {
+	return api.PrimitiveTypeBoolean
+}
+
+type arithmetic struct {
+	op          string
+	left, right node
+}
+
+func (n arithmetic) eval(row map[string]any) (any, error) {
+	l, err := n.left.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil || r == nil {
+		return nil, nil
+	}
+
+	li, lInt := l.(int)
+	ri, rInt := r.(int)
+	if lInt && rInt {
+		switch n.op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		case "/", "%":
+			if ri == 0 {
+				return nil, nil
+			}
+			if n.op == "/" {
+				return li / ri, nil
+			}
+			return li % ri, nil
+		}
+	}
+
+	lf, lok := toFloat(l)
+	rf, rok := toFloat(r)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator `%s` requires numbers, got `%v` and `%v`", n.op, l, r)
+	}
+	switch n.op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, nil
+		}
+		return lf / rf, nil
+	case "%":
+		if rf == 0 {
+			return nil, nil
+		}
+		return math.Mod(lf, rf), nil
+	default:
+		return nil, fmt.Errorf("unsupported operator `%s`", n.op)
+	}
+}
+func (n arithmetic) typ() api.PrimitiveType {
+	l, r := n.left.typ(), n.right.typ()
+	if l == api.PrimitiveTypeUnknown || r == api.PrimitiveTypeUnknown {
+		return api.PrimitiveTypeUnknown
+	}
+	if l == api.PrimitiveTypeInteger && r == api.PrimitiveTypeInteger {
+		return api.PrimitiveTypeInteger
+	}
+	return api.PrimitiveTypeFloat
+}
+
+type caseWhen struct {
+	conds     []node
+	vals      []node
+	otherwise node
+}
+
+func (n caseWhen) eval(row map[string]any) (any, error) {
+	for i, cond := range n.conds {
+		c, err := cond.eval(row)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(c) {
+			return n.vals[i].eval(row)
+		}
+	}
+	if n.otherwise != nil {
+		return n.otherwise.eval(row)
+	}
+	return nil, nil
+}
+func (n caseWhen) typ() api.PrimitiveType {
+	vals := n.vals
+	if n.otherwise != nil {
+		vals = append(vals[:len(vals):len(vals)], n.otherwise)
+	}
+	return commonType(vals)
+}
+
+// countOf counts the non-NULL values of an expression.
+type countOf struct {
+	n node
+}
+
+func (n countOf) eval(row map[string]any) (any, error) {
+	v, err := n.n.eval(row)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return 1, nil
+}
+func (n countOf) typ() api.PrimitiveType {
+	return api.PrimitiveTypeInteger
+}
+
+func truthy(v any) bool {
+	b, ok := v.(bool)
+	return ok && b
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// compareValues compares two non-NULL values. Numbers are compared numerically, other values by their string form.
+func compareValues(l, r any) (int, error) {
+	_, lStr := l.(string)
+	_, rStr := r.(string)
+	if !lStr || !rStr {
+		lf, lok := toFloat(l)
+		rf, rok := toFloat(r)
+		if lok && rok {
+			switch {
+			case lf < rf:
+				return -1, nil
+			case lf > rf:
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+	}
+	lb, lBool := l.(bool)
+	rb, rBool := r.(bool)
+	if lBool || rBool {
+		if !lBool || !rBool {
+			return 0, fmt.Errorf("cannot compare `%v` with `%v`", l, r)
+		}
+		if lb == rb {
+			return 0, nil
+		}
+		if !lb {
+			return -1, nil
+		}
+		return 1, nil
+	}
+	return strings.Compare(fmt.Sprint(l), fmt.Sprint(r)), nil
+}
+
+func commonType(nodes []node) api.PrimitiveType {
+	ret := api.PrimitiveTypeUnknown
+	for i, n := range nodes {
+		t := n.typ()
+		if t == api.PrimitiveTypeUnknown {
+			return api.PrimitiveTypeUnknown
+		}
+		switch {
+		case i == 0 || t == ret:
+			ret = t
+		case (t == api.PrimitiveTypeFloat && ret == api.PrimitiveTypeInteger) ||
+			(t == api.PrimitiveTypeInteger && ret == api.PrimitiveTypeFloat):
+			ret = api.PrimitiveTypeFloat
+		default:
+			return api.PrimitiveTypeUnknown
+		}
+	}
+	return ret
+}
@@ -0,0 +1,468 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlexpr
+
+import (
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"strconv"
+	"strings"
+)
+
+// maxDepth is the maximum nesting of the expressions, which bounds the recursion of the parser and the evaluation.
+const maxDepth = 128
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+	cols   map[string]bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(kw string) bool {
+	t := p.peek()
+	return t.kind == tokKeyword && t.val == kw
+}
+
+func (p *parser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.val == op
+}
+
+func (p *parser) acceptKeyword(kw string) bool {
+	if p.isKeyword(kw) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) acceptOp(op string) bool {
+	if p.isOp(op) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	if !p.acceptKeyword(kw) {
+		return p.errorf("expected `%s`, got %s", kw, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) expectOp(op string) error {
+	if !p.acceptOp(op) {
+		return p.errorf("expected `%s`, got %s", op, p.peek())
+	}
+	return nil
+}
+
+// enter is called on every nesting level of the recursive rules (sub-expressions, NOT and unary minus). The caller
+// must call leave when it returns.
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > maxDepth {
+		return p.errorf("the expression is nested too deeply")
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("position %d: %s", p.peek().pos, fmt.Sprintf(format, args...))
+}
+
+// query := SELECT select [AS alias] [FROM source] [WHERE expr]
+func (p *parser) query(q *Query) error {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return err
+	}
+	if err := p.selection(q); err != nil {
+		return err
+	}
+	if p.acceptKeyword("AS") {
+		t := p.next()
+		if t.kind != tokIdent {
+			return p.errorf("expected an alias, got %s", t)
+		}
+		q.Alias = t.val
+	}
+	if p.acceptKeyword("FROM") {
+		t := p.next()
+		if t.kind != tokIdent {
+			return p.errorf("expected a source name, got %s", t)
+		}
+		q.From = t.val
+	}
+	if p.acceptKeyword("WHERE") {
+		where, err := p.expr()
+		if err != nil {
+			return err
+		}
+		q.where = where
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return p.errorf("unexpected %s", t)
+	}
+	return nil
+}
+
+// selection parses the selected expression. Top-level aggregations are extracted to the Query's Aggr, since they are
+// calculated by the feature's window rather than by the expression.
+func (p *parser) selection(q *Query) error {
+	if t := p.peek(); t.kind == tokIdent && p.tokens[p.pos+1].kind == tokOp && p.tokens[p.pos+1].val == "(" {
+		if fn := api.StringToAggrFn(strings.ToLower(t.val)); fn != api.AggrFnUnknown {
+			p.next()
+			p.next()
+			q.Aggr = fn
+			if fn == api.AggrFnCount && p.acceptOp("*") {
+				q.value = literal{val: 1}
+			} else {
+				arg, err := p.expr()
+				if err != nil {
+					return err
+				}
+				if fn == api.AggrFnCount {
+					arg = countOf{arg}
+				}
+				q.value = arg
+			}
+			return p.expectOp(")")
+		}
+	}
+
+	val, err := p.expr()
+	if err != nil {
+		return err
+	}
+	q.value = val
+	return nil
+}
+
+// expr := and (OR and)*
+func (p *parser) expr() (node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+// and := not (AND not)*
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+// not := NOT not | comparison
+func (p *parser) not() (node, error) {
+	if p.acceptKeyword("NOT") {
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+
+		n, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return negate{n}, nil
+	}
+	return p.comparison()
+}
+
+// comparison := additive [(=|!=|<>|<|<=|>|>=) additive | IS [NOT] NULL | [NOT] IN (list) | [NOT] LIKE additive]
+func (p *parser) comparison() (node, error) {
+	left, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind == tokOp {
+		switch t.val {
+		case "=", "!=", "<>", "<", "<=", ">", ">=":
+			p.next()
+			right, err := p.additive()
+			if err != nil {
+				return nil, err
+			}
+			op := t.val
+			if op == "<>" {
+				op = "!="
+			}
+			return compare{op: op, left: left, right: right}, nil
+		}
+	}
+
+	if p.acceptKeyword("IS") {
+		not := p.acceptKeyword("NOT")
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return isNull{n: left, not: not}, nil
+	}
+
+	not := p.acceptKeyword("NOT")
+	switch {
+	case p.acceptKeyword("IN"):
+		if err := p.expectOp("("); err != nil {
+			return nil, err
+		}
+		var list []node
+		for {
+			n, err := p.additive()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, n)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		var ret node = in{n: left, list: list}
+		if not {
+			ret = negate{ret}
+		}
+		return ret, nil
+	case p.acceptKeyword("LIKE"):
+		pattern, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		var ret node = like{n: left, pattern: pattern}
+		if not {
+			ret = negate{ret}
+		}
+		return ret, nil
+	case not:
+		return nil, p.errorf("expected `IN` or `LIKE` after `NOT`, got %s", p.peek())
+	}
+	return left, nil
+}
+
+// additive := multiplicative ((+|-|'||') multiplicative)*
+func (p *parser) additive() (node, error) {
+	left, err := p.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp || (t.val != "+" && t.val != "-" && t.val != "||") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		if t.val == "||" {
+			left = call{name: "concat", args: []node{left, right}, fn: functions["concat"]}
+		} else {
+			left = arithmetic{op: t.val, left: left, right: right}
+		}
+	}
+}
+
+// multiplicative := unary ((*|/|%) unary)*
+func (p *parser) multiplicative() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp || (t.val != "*" && t.val != "/" && t.val != "%") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmetic{op: t.val, left: left, right: right}
+	}
+}
+
+// unary := -unary | primary
+func (p *parser) unary() (node, error) {
+	if p.acceptOp("-") {
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return arithmetic{op: "-", left: literal{val: 0}, right: n}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		if i, err := strconv.Atoi(t.val); err == nil {
+			return literal{val: i}, nil
+		}
+		f, err := strconv.ParseFloat(t.val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("position %d: invalid number `%s`", t.pos, t.val)
+		}
+		return literal{val: f}, nil
+	case tokString:
+		return literal{val: t.val}, nil
+	case tokKeyword:
+		switch t.val {
+		case "TRUE":
+			return literal{val: true}, nil
+		case "FALSE":
+			return literal{val: false}, nil
+		case "NULL":
+			return literal{val: nil}, nil
+		case "CASE":
+			return p.caseExpr()
+		}
+	case tokOp:
+		if t.val == "(" {
+			n, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expectOp(")")
+		}
+	case tokIdent:
+		if p.acceptOp("(") {
+			return p.call(t)
+		}
+		path, err := api.CompileJSONPath("$." + t.val)
+		if err != nil {
+			return nil, fmt.Errorf("position %d: invalid column `%s`: %w", t.pos, t.val, err)
+		}
+		p.cols[t.val] = true
+		return column{name: t.val, path: path}, nil
+	}
+	return nil, fmt.Errorf("position %d: unexpected %s", t.pos, t)
+}
+
+func (p *parser) call(name token) (node, error) {
+	fname := strings.ToLower(name.val)
+	if api.StringToAggrFn(fname) != api.AggrFnUnknown {
+		return nil, fmt.Errorf("position %d: aggregation `%s` is allowed only as the selected expression", name.pos, fname)
+	}
+	fn, ok := functions[fname]
+	if !ok {
+		return nil, fmt.Errorf("position %d: unknown function `%s`", name.pos, name.val)
+	}
+
+	var args []node
+	if !p.acceptOp(")") {
+		for {
+			n, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, n)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("position %d: wrong number of arguments for `%s`", name.pos, fname)
+	}
+	return call{name: fname, args: args, fn: fn}, nil
+}
+
+// caseExpr := CASE (WHEN expr THEN expr)+ [ELSE expr] END
+func (p *parser) caseExpr() (node, error) {
+	c := caseWhen{}
+	for p.acceptKeyword("WHEN") {
+		cond, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+		val, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		c.conds = append(c.conds, cond)
+		c.vals = append(c.vals, val)
+	}
+	if len(c.conds) == 0 {
+		return nil, p.errorf("expected `WHEN`, got %s", p.peek())
+	}
+	if p.acceptKeyword("ELSE") {
+		val, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		c.otherwise = val
+	}
+	return c, p.expectKeyword("END")
+}
@@ -0,0 +1,186 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sqlexpr compiles and evaluates the SQL-like expressions of the `sql` builder.
+//
+// An expression selects a single value from each incoming event:
+//
+//	SELECT sum(amount) FROM payments WHERE status = 'approved' AND currency IN ('USD', 'EUR')
+//
+// A top-level aggregation (sum, avg, min, max, count) is not calculated by the expression; it declares the window
+// aggregation of the feature, and the selected argument is the value that is added to the window for each event.
+// The supported syntax includes arithmetic, comparisons, AND/OR/NOT, IS [NOT] NULL, [NOT] IN, [NOT] LIKE, CASE WHEN,
+// and the functions: lower, upper, trim, length, concat, coalesce, abs, floor, ceil and round.
+// Columns are the fields of the event, and nested fields can be selected with dots (i.e. `user.country`).
+package sqlexpr
+
+import (
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	"sort"
+	"strings"
+)
+
+// Query is a compiled expression.
+type Query struct {
+	// Aggr is the aggregation of the selected value, or AggrFnUnknown if the expression is not aggregated.
+	Aggr api.AggrFn
+	// Alias is the optional alias of the selected value.
+	Alias string
+	// From is the optional name of the source. It's informational only, since the events are always the rows of the
+	// Feature's DataSource.
+	From string
+
+	expr    string
+	value   node
+	where   node
+	columns []string
+}
+
+// Compile compiles an expression.
+func Compile(expr string) (*Query, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile sql: %w", err)
+	}
+
+	p := &parser{tokens: tokens, cols: make(map[string]bool)}
+	q := &Query{expr: expr}
+	if err := p.query(q); err != nil {
+		return nil, fmt.Errorf("failed to compile sql: %w", err)
+	}
+	for c := range p.cols {
+		q.columns = append(q.columns, c)
+	}
+	sort.Strings(q.columns)
+	return q, nil
+}
+
+// String returns the original expression.
+func (q *Query) String() string {
+	return q.expr
+}
+
+// Columns returns the columns that are referenced by the expression.
+func (q *Query) Columns() []string {
+	return q.columns
+}
+
+// Type returns the statically inferred type of the selected value, or PrimitiveTypeUnknown if it depends on the
+// types of the event's fields.
+func (q *Query) Type() api.PrimitiveType {
+	if q.Aggr == api.AggrFnCount {
+		return api.PrimitiveTypeInteger
+	}
+	return q.value.typ()
+}
+
+// Eval evaluates the expression on an event. It returns false if the event is filtered out by the WHERE clause, or
+// if the selected value is NULL.
+func (q *Query) Eval(row map[string]any) (any, bool, error) {
+	if q.where != nil {
+		ok, err := q.where.eval(row)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to evaluate WHERE: %w", err)
+		}
+		if !truthy(ok) {
+			return nil, false, nil
+		}
+	}
+	v, err := q.value.eval(row)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to evaluate SELECT: %w", err)
+	}
+	return v, v != nil, nil
+}
+
+// Validate checks that the expression can build a feature with the given descriptor.
+func (q *Query) Validate(fd api.FeatureDescriptor) error {
+	if q.Aggr != api.AggrFnUnknown {
+		found := false
+		for _, fn := range fd.Aggr {
+			if fn == q.Aggr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("aggregation `%s` must be declared in the feature's `aggr` (got [%s])", q.Aggr, aggrString(fd.Aggr))
+		}
+	} else if len(fd.Aggr) > 0 {
+		return fmt.Errorf("windowed features must select an aggregation (i.e. `SELECT %s(...)`)", fd.Aggr[0])
+	}
+
+	t := q.Type()
+	if t == api.PrimitiveTypeUnknown {
+		return nil
+	}
+	target := fd.Primitive
+	if t == target || (t == api.PrimitiveTypeInteger && target == api.PrimitiveTypeFloat) {
+		return nil
+	}
+	return fmt.Errorf("expression type `%s` is incompatible with the feature primitive `%s`", t, target)
+}
+
+// Convert converts an evaluated value to the given primitive.
+func Convert(v any, primitive api.PrimitiveType) (any, error) {
+	if !primitive.Scalar() {
+		return nil, fmt.Errorf("sql expressions don't support list primitives")
+	}
+	switch primitive {
+	case api.PrimitiveTypeFloat:
+		if f, ok := toFloat(v); ok {
+			return f, nil
+		}
+	case api.PrimitiveTypeInteger:
+		switch v := v.(type) {
+		case int:
+			return v, nil
+		case float64:
+			if v == float64(int(v)) {
+				return int(v), nil
+			}
+			return nil, fmt.Errorf("value `%v` is not an integer", v)
+		}
+	case api.PrimitiveTypeString:
+		return api.ScalarString(normalize(v)), nil
+	}
+
+	if s, ok := v.(string); ok {
+		return api.ScalarFromString(s, primitive)
+	}
+	if api.TypeDetect(v) == primitive {
+		return v, nil
+	}
+	return nil, fmt.Errorf("value `%v` cannot be converted to `%s`", v, primitive)
+}
+
+func normalize(v any) any {
+	switch v := v.(type) {
+	case string, int, float64, bool:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func aggrString(fns []api.AggrFn) string {
+	s := make([]string, len(fns))
+	for i, fn := range fns {
+		s[i] = fn.String()
+	}
+	return strings.Join(s, ", ")
+}
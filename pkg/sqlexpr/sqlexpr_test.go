@@ -0,0 +1,330 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlexpr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/raptor-ml/raptor/api"
+)
+
+var row = map[string]any{
+	"amount":   20,
+	"price":    2.5,
+	"name":     "John",
+	"status":   "approved",
+	"currency": "EUR",
+	"active":   true,
+	"user":     map[string]any{"country": "IL"},
+}
+
+func TestQuery_Eval(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want any
+	}{
+		{name: "multiplication before addition", expr: "SELECT 1 + 2 * 3", want: 7},
+		{name: "parentheses", expr: "SELECT (1 + 2) * 3", want: 9},
+		{name: "left associative subtraction", expr: "SELECT 10 - 4 - 3", want: 3},
+		{name: "integer division", expr: "SELECT 7 / 2", want: 3},
+		{name: "float division", expr: "SELECT 7.0 / 2", want: 3.5},
+		{name: "modulo", expr: "SELECT 7 % 4", want: 3},
+		{name: "unary minus", expr: "SELECT -2 * 3", want: -6},
+		{name: "arithmetic before comparison", expr: "SELECT 1 + 2 = 3", want: true},
+		{name: "AND before OR", expr: "SELECT 1 = 1 OR 1 = 2 AND 1 = 2", want: true},
+		{name: "NOT before AND", expr: "SELECT NOT 1 = 2 AND 1 = 1", want: true},
+		{name: "concatenation", expr: "SELECT 'a' || 'b' || 1", want: "ab1"},
+		{name: "escaped quote", expr: "SELECT 'it''s'", want: "it's"},
+		{name: "columns", expr: "SELECT amount * price", want: 50.0},
+		{name: "nested column", expr: "SELECT user.country", want: "IL"},
+		{name: "quoted column", expr: "SELECT `name`", want: "John"},
+		{name: "numeric string comparison", expr: "SELECT '10' > 9", want: true},
+		{name: "string comparison", expr: "SELECT name < 'Kate'", want: true},
+		{name: "boolean comparison", expr: "SELECT active = TRUE", want: true},
+		{name: "in", expr: "SELECT currency IN ('USD', 'EUR')", want: true},
+		{name: "not in", expr: "SELECT currency NOT IN ('USD', 'EUR')", want: false},
+		{name: "like", expr: "SELECT name LIKE 'J_h%'", want: true},
+		{name: "not like", expr: "SELECT name NOT LIKE '%x%'", want: true},
+		{name: "case", expr: "SELECT CASE WHEN amount > 100 THEN 'big' WHEN amount > 10 THEN 'medium' ELSE 'small' END", want: "medium"},
+		{name: "functions", expr: "SELECT upper(trim(' a ')) || lower('B')", want: "Ab"},
+		{name: "length of unicode", expr: "SELECT length('héllo')", want: 5},
+		{name: "abs of integer", expr: "SELECT abs(-3)", want: 3},
+		{name: "floor of float", expr: "SELECT floor(2.7)", want: 2.0},
+		{name: "round", expr: "SELECT round(2.5)", want: 3.0},
+		{name: "coalesce", expr: "SELECT coalesce(missing, 'x')", want: "x"},
+		{name: "aggregated", expr: "SELECT sum(amount) FROM payments WHERE status = 'approved'", want: 20},
+		{name: "count", expr: "SELECT count(*)", want: 1},
+		{name: "comment", expr: "SELECT 1 -- the answer\n + 1", want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("failed to compile: %v", err)
+			}
+			got, ok, err := q.Eval(row)
+			if err != nil {
+				t.Fatalf("failed to evaluate: %v", err)
+			}
+			if !ok {
+				t.Fatalf("expected a value")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery_Eval_Null(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		// want is the expected value, or nil if the result is expected to be NULL
+		want any
+	}{
+		{name: "arithmetic", expr: "SELECT missing + 1"},
+		{name: "comparison", expr: "SELECT missing = 1"},
+		{name: "negated comparison", expr: "SELECT NOT missing = 1"},
+		{name: "division by zero", expr: "SELECT 1 / 0"},
+		{name: "float modulo by zero", expr: "SELECT 1.5 % 0"},
+		{name: "function", expr: "SELECT upper(missing)"},
+		{name: "in", expr: "SELECT missing IN (1, 2)"},
+		{name: "like", expr: "SELECT missing LIKE '%'"},
+		{name: "case without else", expr: "SELECT CASE WHEN missing = 1 THEN 1 END"},
+		{name: "coalesce of NULLs", expr: "SELECT coalesce(missing, NULL)"},
+		{name: "count of NULL", expr: "SELECT count(missing)"},
+		{name: "null literal", expr: "SELECT NULL"},
+		{name: "is null", expr: "SELECT missing IS NULL", want: true},
+		{name: "is not null", expr: "SELECT missing IS NOT NULL", want: false},
+		{name: "in with NULL items", expr: "SELECT 1 IN (NULL, 1)", want: true},
+		{name: "OR with NULL", expr: "SELECT missing = 1 OR 1 = 1", want: true},
+		{name: "AND with NULL", expr: "SELECT missing = 1 AND 1 = 1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("failed to compile: %v", err)
+			}
+			got, ok, err := q.Eval(row)
+			if err != nil {
+				t.Fatalf("failed to evaluate: %v", err)
+			}
+			if ok != (tt.want != nil) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v (%v), want %#v", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery_Eval_Where(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "SELECT amount WHERE status = 'approved' AND currency IN ('USD', 'EUR')", want: true},
+		{expr: "SELECT amount WHERE status = 'declined'", want: false},
+		{expr: "SELECT amount WHERE missing = 1", want: false},
+		{expr: "SELECT amount WHERE amount", want: false},
+		{expr: "SELECT missing WHERE active"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			q, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("failed to compile: %v", err)
+			}
+			_, ok, err := q.Eval(row)
+			if err != nil {
+				t.Fatalf("failed to evaluate: %v", err)
+			}
+			if ok != tt.want {
+				t.Errorf("got %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery_Eval_TypeErrors(t *testing.T) {
+	for _, expr := range []string{
+		"SELECT 'a' + 1",
+		"SELECT name * 2",
+		"SELECT active - 1",
+		"SELECT active < 1",
+		"SELECT abs('x')",
+		"SELECT round(1, 'x')",
+		"SELECT amount WHERE 'a' + 1 = 1",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			q, err := Compile(expr)
+			if err != nil {
+				t.Fatalf("failed to compile: %v", err)
+			}
+			if _, _, err := q.Eval(row); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"SELECT",
+		"1 + 1",
+		"SELECT 1 +",
+		"SELECT 1 2",
+		"SELECT (1",
+		"SELECT 1)",
+		"SELECT 'abc",
+		"SELECT `abc",
+		"SELECT #",
+		"SELECT 1e",
+		"SELECT 1.2.3",
+		"SELECT a..b",
+		"SELECT a.",
+		"SELECT unknown(1)",
+		"SELECT lower()",
+		"SELECT lower(1, 2)",
+		"SELECT lower(1",
+		"SELECT sum(sum(amount))",
+		"SELECT lower(sum(amount))",
+		"SELECT sum(amount",
+		"SELECT a NOT 1",
+		"SELECT 1 IS 2",
+		"SELECT 1 IN ()",
+		"SELECT 1 IN (1",
+		"SELECT CASE END",
+		"SELECT CASE WHEN 1 THEN 2",
+		"SELECT CASE WHEN 1 2 END",
+		"SELECT a AS",
+		"SELECT a AS 1",
+		"SELECT a FROM",
+		"SELECT a WHERE",
+		"SELECT a WHERE b = 1 c",
+		"SELECT " + strings.Repeat("(", maxDepth) + "1" + strings.Repeat(")", maxDepth),
+		"SELECT " + strings.Repeat("NOT ", maxDepth+1) + "TRUE",
+		"SELECT " + strings.Repeat("-", maxDepth+1) + "1",
+	} {
+		name := expr
+		if len(name) > 40 {
+			name = name[:40]
+		}
+		t.Run(name, func(t *testing.T) {
+			if _, err := Compile(expr); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+// TestCompile_NoPanic compiles and evaluates every prefix of the expressions, to make sure that malformed expressions
+// are rejected rather than crashing the runner.
+func TestCompile_NoPanic(t *testing.T) {
+	exprs := []string{
+		"SELECT sum(amount * 2) AS total FROM payments WHERE status = 'approved' AND currency NOT IN ('USD', 'EUR')",
+		"SELECT CASE WHEN name LIKE 'J%' THEN coalesce(user.country, 'x') || '-' ELSE NULL END WHERE NOT active IS NULL",
+		"SELECT count(*) WHERE round(price, 1) >= -1.5e3 OR `name` <> 'it''s' -- comment",
+		"SELECT abs(-(1 - 2)) % 0 / 0.0",
+		"SELECT \"user.country\" IN (1, 'IL', TRUE, NULL)",
+		"SELECT héllo",
+	}
+	for _, expr := range exprs {
+		for i := 0; i <= len(expr); i++ {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Errorf("panic on `%s`: %v", expr[:i], r)
+					}
+				}()
+				q, err := Compile(expr[:i])
+				if err != nil {
+					return
+				}
+				_, _, _ = q.Eval(row)
+				_, _, _ = q.Eval(nil)
+				_ = q.Type()
+			}()
+		}
+	}
+}
+
+func TestQuery_Validate(t *testing.T) {
+	tests := []struct {
+		expr      string
+		primitive api.PrimitiveType
+		aggr      []api.AggrFn
+		wantErr   bool
+	}{
+		{expr: "SELECT sum(amount)", primitive: api.PrimitiveTypeFloat, aggr: []api.AggrFn{api.AggrFnSum, api.AggrFnCount}},
+		{expr: "SELECT count(*)", primitive: api.PrimitiveTypeInteger, aggr: []api.AggrFn{api.AggrFnCount}},
+		{expr: "SELECT 1", primitive: api.PrimitiveTypeFloat},
+		{expr: "SELECT lower(name)", primitive: api.PrimitiveTypeString},
+		{expr: "SELECT amount", primitive: api.PrimitiveTypeBoolean},
+		{expr: "SELECT CASE WHEN active THEN 1 ELSE 2.5 END", primitive: api.PrimitiveTypeFloat},
+		{expr: "SELECT max(amount)", primitive: api.PrimitiveTypeFloat, aggr: []api.AggrFn{api.AggrFnSum}, wantErr: true},
+		{expr: "SELECT amount", primitive: api.PrimitiveTypeFloat, aggr: []api.AggrFn{api.AggrFnSum}, wantErr: true},
+		{expr: "SELECT 'a'", primitive: api.PrimitiveTypeInteger, wantErr: true},
+		{expr: "SELECT 1.5", primitive: api.PrimitiveTypeInteger, wantErr: true},
+		{expr: "SELECT amount > 1", primitive: api.PrimitiveTypeString, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			q, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("failed to compile: %v", err)
+			}
+			err = q.Validate(api.FeatureDescriptor{Primitive: tt.primitive, Aggr: tt.aggr})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		val       any
+		primitive api.PrimitiveType
+		want      any
+		wantErr   bool
+	}{
+		{val: 3, primitive: api.PrimitiveTypeFloat, want: 3.0},
+		{val: "2.5", primitive: api.PrimitiveTypeFloat, want: 2.5},
+		{val: 3.0, primitive: api.PrimitiveTypeInteger, want: 3},
+		{val: "4", primitive: api.PrimitiveTypeInteger, want: 4},
+		{val: 2.5, primitive: api.PrimitiveTypeString, want: "2.5"},
+		{val: true, primitive: api.PrimitiveTypeBoolean, want: true},
+		{val: 3.5, primitive: api.PrimitiveTypeInteger, wantErr: true},
+		{val: "x", primitive: api.PrimitiveTypeFloat, wantErr: true},
+		{val: 1, primitive: api.PrimitiveTypeIntegerList, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Convert(tt.val, tt.primitive)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Convert(%#v, %s): unexpected error: %v", tt.val, tt.primitive, err)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Convert(%#v, %s) = %#v, want %#v", tt.val, tt.primitive, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validator validates manifests of features and DataSources offline, without a cluster or a running engine,
+// so they can be gated (i.e. by a CI pipeline) before they're applied.
+//
+// The features are defaulted and validated the way the admission webhook does: their spec, their `sql` or `cel`
+// expressions, and their builder against the DataSource they use. DataSources are looked up in the validated
+// manifests, and the values of Secrets that aren't part of the manifests are replaced by placeholders.
+// Python programs are validated only when a RuntimeManager is provided.
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/raptor-ml/raptor/api"
+	manifests "github.com/raptor-ml/raptor/api/v1alpha1"
+	"github.com/raptor-ml/raptor/internal/engine"
+	"github.com/raptor-ml/raptor/internal/operator"
+	"github.com/raptor-ml/raptor/internal/plan"
+	"github.com/raptor-ml/raptor/pkg/plugins"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// the builders are registered by the plugins
+	_ "github.com/raptor-ml/raptor/internal/plugins"
+)
+
+// DefaultNamespace is the namespace of manifests without a namespace.
+const DefaultNamespace = "default"
+
+// Result is the validation result of a single manifest.
+type Result struct {
+	// File is the file of the manifest (if it was read from a file).
+	File      string
+	Kind      string
+	Name      string
+	Namespace string
+	// Err is set when the manifest is invalid.
+	Err error
+}
+
+func (r Result) String() string {
+	prefix := ""
+	if r.File != "" {
+		prefix = r.File + ": "
+	}
+	if r.Err != nil {
+		return fmt.Sprintf("%s%s %s/%s is invalid: %s", prefix, r.Kind, r.Namespace, r.Name, r.Err)
+	}
+	return fmt.Sprintf("%s%s %s/%s is valid", prefix, r.Kind, r.Namespace, r.Name)
+}
+
+// Validator validates manifests offline.
+type Validator struct {
+	// RuntimeManager validates the Python programs of the features. When it's nil, the programs aren't validated.
+	RuntimeManager api.RuntimeManager
+	// AllowMissingDataSources validates features whose DataSource isn't in the manifests (i.e. it already exists in
+	// the cluster) without validating their builder against it. By default, they're invalid.
+	AllowMissingDataSources bool
+}
+
+// ValidatePaths validates the manifests (YAML or JSON) in the files. Directories are walked recursively for `.yaml`,
+// `.yml` and `.json` files.
+func (v *Validator) ValidatePaths(paths ...string) ([]Result, error) {
+	var files []string
+	for _, p := range paths {
+		err := filepath.WalkDir(p, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".yaml", ".yml", ".json":
+				files = append(files, path)
+			default:
+				if path == p {
+					// files that are given explicitly are validated regardless of their extension
+					files = append(files, path)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		objs    []*unstructured.Unstructured
+		origins []string
+	)
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		decoded, err := plan.DecodeManifests(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", file, err)
+		}
+		for range decoded {
+			origins = append(origins, file)
+		}
+		objs = append(objs, decoded...)
+	}
+	return v.validate(objs, origins), nil
+}
+
+// Validate validates the Features and DataSources among the objects. Other objects are ignored, except of Secrets,
+// that are used to resolve the config of the DataSources.
+func (v *Validator) Validate(objs []*unstructured.Unstructured) []Result {
+	return v.validate(objs, nil)
+}
+
+func (v *Validator) validate(objs []*unstructured.Unstructured, origins []string) []Result {
+	ctx := context.Background()
+	secrets := make(map[string]*corev1.Secret)
+	dataSources := make(map[string]*manifests.DataSource)
+	for _, u := range objs {
+		if u.GetNamespace() == "" {
+			u.SetNamespace(DefaultNamespace)
+		}
+		switch u.GroupVersionKind().GroupKind() {
+		case corev1.SchemeGroupVersion.WithKind("Secret").GroupKind():
+			s := &corev1.Secret{}
+			if runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, s) == nil {
+				secrets[s.GetNamespace()+"/"+s.GetName()] = s
+			}
+		case manifests.GroupVersion.WithKind("DataSource").GroupKind():
+			src := &manifests.DataSource{}
+			if runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, src) == nil {
+				dataSources[src.FQN()] = src
+			}
+		}
+	}
+
+	var results []Result
+	for i, u := range objs {
+		res := Result{Kind: u.GetKind(), Name: u.GetName(), Namespace: u.GetNamespace()}
+		if origins != nil {
+			res.File = origins[i]
+		}
+		switch u.GroupVersionKind().GroupKind() {
+		case manifests.GroupVersion.WithKind("DataSource").GroupKind():
+			src := &manifests.DataSource{}
+			if res.Err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, src); res.Err == nil {
+				_, res.Err = dataSourceFromManifest(ctx, src, secrets)
+			}
+		case manifests.GroupVersion.WithKind("Feature").GroupKind():
+			ft := &manifests.Feature{}
+			if res.Err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, ft); res.Err == nil {
+				res.Err = v.validateFeature(ctx, ft, dataSources, secrets)
+			}
+		default:
+			continue
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// ValidateFeature validates a single feature. The DataSource of the feature (if it has one) should be given.
+func (v *Validator) ValidateFeature(ft *manifests.Feature, src *manifests.DataSource) error {
+	dataSources := make(map[string]*manifests.DataSource)
+	if src != nil {
+		dataSources[src.FQN()] = src
+	}
+	return v.validateFeature(context.Background(), ft, dataSources, nil)
+}
+
+func (v *Validator) validateFeature(ctx context.Context, ft *manifests.Feature, dataSources map[string]*manifests.DataSource, secrets map[string]*corev1.Secret) error {
+	if ft.GetNamespace() == "" {
+		ft.SetNamespace(DefaultNamespace)
+	}
+	if err := api.ValidateFQN(ft.FQN()); err != nil {
+		return fmt.Errorf("the feature's name doesn't conform to the naming scheme: %w", err)
+	}
+
+	var src *api.DataSource
+	if ref := ft.Spec.DataSource; ref != nil {
+		if ref.Namespace == "" {
+			ref.Namespace = ft.GetNamespace()
+		}
+		if m, ok := dataSources[ref.FQN()]; ok {
+			dsrc, err := dataSourceFromManifest(ctx, m, secrets)
+			if err != nil {
+				return fmt.Errorf("failed to parse DataSource %s: %w", ref.FQN(), err)
+			}
+			src = &dsrc
+		} else if !v.AllowMissingDataSources {
+			return fmt.Errorf("DataSource %s/%s isn't in the manifests", ref.Namespace, ref.Name)
+		}
+	}
+	defaultBuilder(ft, src)
+
+	fd, err := api.FeatureDescriptorFromManifest(ft)
+	if err != nil {
+		return err
+	}
+	if err := operator.ValidateExpression(ft); err != nil {
+		return fmt.Errorf("the builder's expression is invalid: %w", err)
+	}
+	if ft.Spec.DataSource != nil && src == nil {
+		// the builder can't be validated without its DataSource
+		return nil
+	}
+
+	e := &offlineEngine{runtimeManager: v.RuntimeManager, primitive: fd.Primitive}
+	if src != nil {
+		e.Dummy.DataSource = *src
+	}
+	_, err = engine.FeatureWithEngine(e, ft)
+	return err
+}
+
+// defaultBuilder defaults the builder's kind the way the admission webhook does.
+func defaultBuilder(ft *manifests.Feature, src *api.DataSource) {
+	b := &ft.Spec.Builder
+	switch {
+	case b.Kind != "":
+		return
+	case b.SQL != "":
+		b.Kind = api.SQLBuilder
+	case b.CEL != "":
+		b.Kind = api.CELBuilder
+	case b.Wasm != nil:
+		b.Kind = api.WasmBuilder
+	case b.EventID != "":
+		b.Kind = api.AggregationBuilder
+	case src != nil && plugins.FeatureAppliers[src.Kind] != nil:
+		b.Kind = src.Kind
+	default:
+		b.Kind = api.SourcelessBuilder
+	}
+	if b.AggrGranularity.Milliseconds() > 0 && len(b.Aggr) > 0 {
+		ft.Spec.Freshness = b.AggrGranularity
+	}
+}
+
+// dataSourceFromManifest parses the DataSource. Its secrets are resolved from the given Secrets, or replaced by
+// placeholders.
+func dataSourceFromManifest(ctx context.Context, src *manifests.DataSource, secrets map[string]*corev1.Secret) (api.DataSource, error) {
+	src = src.DeepCopy()
+	if src.GetNamespace() == "" {
+		src.SetNamespace(DefaultNamespace)
+	}
+	for i, cv := range src.Spec.Config {
+		ref := cv.SecretKeyRef
+		if cv.Value != "" || ref == nil {
+			continue
+		}
+		val := fmt.Sprintf("<secret %s/%s>", ref.Name, ref.Key)
+		if s, ok := secrets[src.GetNamespace()+"/"+ref.Name]; ok {
+			if b, ok := s.Data[ref.Key]; ok {
+				val = string(b)
+			} else if str, ok := s.StringData[ref.Key]; ok {
+				val = str
+			} else {
+				return api.DataSource{}, fmt.Errorf("secret %s does not have key %s", ref.Name, ref.Key)
+			}
+		}
+		src.Spec.Config[i].Value = val
+		src.Spec.Config[i].SecretKeyRef = nil
+	}
+	// all the secrets are resolved, so a reader isn't needed
+	return api.DataSourceFromManifest(ctx, src, nil)
+}
+
+// offlineEngine is a Dummy engine that serves the feature's DataSource, and skips loading the Python programs when
+// there's no RuntimeManager.
+type offlineEngine struct {
+	engine.Dummy
+	runtimeManager api.RuntimeManager
+	primitive      api.PrimitiveType
+}
+
+func (e *offlineEngine) LoadProgram(env, fqn, program string, packages []string) (*api.ParsedProgram, error) {
+	if e.runtimeManager == nil {
+		return &api.ParsedProgram{Primitive: e.primitive}, nil
+	}
+	e.Dummy.RuntimeManager = e.runtimeManager
+	return e.Dummy.LoadProgram(env, fqn, program, packages)
+}
+
+func (e *offlineEngine) GetDataSource(fqn string) (api.DataSource, error) {
+	if e.Dummy.DataSource.FQN != fqn {
+		return api.DataSource{}, errors.New("DataSource not found")
+	}
+	return e.Dummy.DataSource, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2022 RaptorML authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const helloWorld = `
+apiVersion: k8s.raptor.ml/v1alpha1
+kind: Feature
+metadata:
+  name: hello-world
+spec:
+  primitive: string
+  freshness: 1h
+  staleness: 2h
+  keys:
+    - name
+  builder:
+    code: |-
+      def handler(row, ctx) -> str:
+         return "Hello world " + ctx.keys["name"]
+`
+
+const invalidCEL = `
+apiVersion: k8s.raptor.ml/v1alpha1
+kind: Feature
+metadata:
+  name: amount-with-vat
+spec:
+  primitive: float
+  freshness: 1m
+  staleness: 1h
+  keys:
+    - user_id
+  dataSource:
+    name: payments
+  builder:
+    cel: row.amount *
+`
+
+const validCEL = `
+apiVersion: k8s.raptor.ml/v1alpha1
+kind: Feature
+metadata:
+  name: amount-with-vat
+spec:
+  primitive: float
+  freshness: 1m
+  staleness: 1h
+  keys:
+    - user_id
+  dataSource:
+    name: payments
+  builder:
+    cel: double(row.amount) * 1.17
+`
+
+func validatePaths(t *testing.T, v *Validator, manifests ...string) []Result {
+	t.Helper()
+	dir := t.TempDir()
+	for i, m := range manifests {
+		if err := os.WriteFile(filepath.Join(dir, string(rune('a'+i))+".yaml"), []byte(m), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, err := v.ValidatePaths(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return results
+}
+
+func TestValidator_ValidatePaths(t *testing.T) {
+	results := validatePaths(t, &Validator{}, helloWorld, invalidCEL)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if err := results[0].Err; err != nil {
+		t.Errorf("expected %s to be valid, got %s", results[0].Name, err)
+	}
+	if results[0].Namespace != DefaultNamespace {
+		t.Errorf("expected the namespace to be defaulted, got %q", results[0].Namespace)
+	}
+	if err := results[1].Err; err == nil || !strings.Contains(err.Error(), "payments") {
+		t.Errorf("expected %s to be invalid because of its missing DataSource, got %v", results[1].Name, err)
+	}
+}
+
+func TestValidator_AllowMissingDataSources(t *testing.T) {
+	v := &Validator{AllowMissingDataSources: true}
+	results := validatePaths(t, v, validCEL, invalidCEL)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if err := results[0].Err; err != nil {
+		t.Errorf("expected the valid expression to pass, got %s", err)
+	}
+	if err := results[1].Err; err == nil || !strings.Contains(err.Error(), "expression") {
+		t.Errorf("expected the invalid expression to fail, got %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+# -*- coding: utf-8 -*-
+#  Copyright (c) 2022 RaptorML authors.
+#
+#  Licensed under the Apache License, Version 2.0 (the "License");
+#  you may not use this file except in compliance with the License.
+#  You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+#  Unless required by applicable law or agreed to in writing, software
+#  distributed under the License is distributed on an "AS IS" BASIS,
+#  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#  See the License for the specific language governing permissions and
+#  limitations under the License.
+
+from .cache import FreshnessCache
+from .client import AsyncClient, Client, DEFAULT_ADDRESS
+from .featureset import FeatureSet, load_featuresets
+from .values import FeatureValue
+
+__all__ = ["Client", "AsyncClient", "FeatureSet", "FeatureValue", "FreshnessCache", "load_featuresets", "DEFAULT_ADDRESS"]
@@ -0,0 +1,85 @@
+# -*- coding: utf-8 -*-
+#  Copyright (c) 2022 RaptorML authors.
+#
+#  Licensed under the Apache License, Version 2.0 (the "License");
+#  you may not use this file except in compliance with the License.
+#  You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+#  Unless required by applicable law or agreed to in writing, software
+#  distributed under the License is distributed on an "AS IS" BASIS,
+#  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#  See the License for the specific language governing permissions and
+#  limitations under the License.
+
+import threading
+import time
+from collections import OrderedDict
+from datetime import datetime
+from typing import Any, Dict, Optional, Tuple
+
+# missing is returned for values that aren't cached (since None is a valid value)
+missing = object()
+
+
+class FreshnessCache:
+    """
+    An in-process LRU cache of feature values.
+
+    A value is cached only while it's fresh: its TTL is the remaining freshness of the value (the time until it's
+    `freshness` old), bounded by `max_ttl`. Values that aren't fresh anymore aren't cached, so they're read from the
+    Core, which recomputes them if needed.
+    """
+
+    def __init__(self, max_ttl: float, max_size: int = 10000):
+        """
+        :param max_ttl: the maximal time (in seconds) a value is cached.
+        :param max_size: the maximal number of cached values. The least recently used values are evicted.
+        """
+        self.max_ttl = max_ttl
+        self.max_size = max_size
+        self._entries: 'OrderedDict[Tuple, Tuple[float, Any]]' = OrderedDict()
+        self._lock = threading.Lock()
+
+    @staticmethod
+    def key(selector: str, keys: Dict[str, str]) -> Tuple:
+        return (selector,) + tuple(sorted(keys.items()))
+
+    def ttl(self, timestamp: Optional[datetime], freshness: float, fresh: bool = True) -> float:
+        """Returns the time a value can be cached for, by its timestamp and the freshness (in seconds) of its feature."""
+        if not fresh or self.max_ttl <= 0:
+            return 0
+        if timestamp is None or freshness <= 0:
+            return self.max_ttl
+        remaining = timestamp.timestamp() + freshness - time.time()
+        return max(0.0, min(self.max_ttl, remaining))
+
+    def get(self, selector: str, keys: Dict[str, str]) -> Any:
+        """Returns the cached value, or `missing` if it's not cached."""
+        k = self.key(selector, keys)
+        with self._lock:
+            entry = self._entries.get(k)
+            if entry is None:
+                return missing
+            expires, value = entry
+            if expires < time.monotonic():
+                del self._entries[k]
+                return missing
+            self._entries.move_to_end(k)
+            return value
+
+    def set(self, selector: str, keys: Dict[str, str], value: Any, ttl: float):
+        if ttl <= 0:
+            return
+        k = self.key(selector, keys)
+        with self._lock:
+            self._entries[k] = (time.monotonic() + ttl, value)
+            self._entries.move_to_end(k)
+            while len(self._entries) > self.max_size:
+                self._entries.popitem(last=False)
+
+    def clear(self):
+        with self._lock:
+            self._entries.clear()
+
@@ -0,0 +1,272 @@
+# -*- coding: utf-8 -*-
+#  Copyright (c) 2022 RaptorML authors.
+#
+#  Licensed under the Apache License, Version 2.0 (the "License");
+#  you may not use this file except in compliance with the License.
+#  You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+#  Unless required by applicable law or agreed to in writing, software
+#  distributed under the License is distributed on an "AS IS" BASIS,
+#  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#  See the License for the specific language governing permissions and
+#  limitations under the License.
+
+import asyncio
+import json
+import os
+import uuid
+from datetime import timezone
+from typing import Dict, List, Optional, Union
+
+import grpc
+from grpc import aio
+
+from .cache import FreshnessCache, missing
+from .featureset import FeatureSet, load_featuresets, normalize_selector
+from .values import FeatureValue, feature_value_to_py, primitive, value_to_py
+
+from core.v1alpha1 import api_pb2, api_pb2_grpc  # noqa: E402 (the proto path is set by .values)
+
+DEFAULT_ADDRESS = 'raptor-core-service.raptor-system:60000'
+SERVICE_ACCOUNT_TOKEN = '/var/run/secrets/kubernetes.io/serviceaccount/token'
+
+EntityID = Union[str, Dict[str, str]]
+
+
+def _service_config(retries: int) -> str:
+    return json.dumps({
+        'methodConfig': [{
+            'name': [{'service': 'core.v1alpha1.EngineService'}],
+            'retryPolicy': {
+                'maxAttempts': retries + 1,
+                'initialBackoff': '0.05s',
+                'maxBackoff': '1s',
+                'backoffMultiplier': 2,
+                'retryableStatusCodes': ['UNAVAILABLE', 'RESOURCE_EXHAUSTED', 'ABORTED'],
+            },
+        }],
+    })
+
+
+class _Base:
+    def __init__(self, address: str = DEFAULT_ADDRESS, credentials: Optional[grpc.ChannelCredentials] = None,
+                 token: Optional[str] = None, token_path: Optional[str] = SERVICE_ACCOUNT_TOKEN,
+                 namespace: str = 'default', timeout: Optional[float] = 1.0, retries: int = 3,
+                 cache_ttl: float = 0, cache_size: int = 10000, options: Optional[List] = None):
+        self.address = address
+        self.namespace = namespace
+        self.timeout = timeout
+        self.featuresets: Dict[str, FeatureSet] = {}
+        self.cache = FreshnessCache(cache_ttl, cache_size) if cache_ttl > 0 else None
+        self._credentials = credentials
+        self._token = token
+        self._token_path = token_path
+        self._options = [
+            ('grpc.enable_retries', 1 if retries > 0 else 0),
+            ('grpc.service_config', _service_config(retries)),
+        ] + (options or [])
+
+    def register_featureset(self, name: str, features: List[str], keys: List[str],
+                            namespace: Optional[str] = None) -> FeatureSet:
+        """Registers the feature set, so its vectors can be read with `get_vector`."""
+        return self._register(FeatureSet(name, features, keys, namespace or self.namespace))
+
+    def load_manifests(self, path: str) -> List[FeatureSet]:
+        """Registers the feature sets of the Model manifests in the file."""
+        with open(path) as f:
+            return [self._register(fs) for fs in load_featuresets(f)]
+
+    def _register(self, fs: FeatureSet) -> FeatureSet:
+        self.featuresets[fs.fqn] = fs
+        self.featuresets[fs.name] = fs
+        return fs
+
+    def _featureset(self, featureset: Union[str, FeatureSet]) -> FeatureSet:
+        if isinstance(featureset, FeatureSet):
+            return featureset
+        fs = self.featuresets.get(featureset) or self.featuresets.get(normalize_selector(featureset, self.namespace))
+        if fs is None:
+            raise KeyError(f'the feature set {featureset} is not registered')
+        return fs
+
+    def _metadata(self, request_context: Optional[dict]) -> List:
+        md = []
+        token = self._token
+        # the token of the service account is rotated, so it's read on every call
+        if token is None and self._token_path and os.path.exists(self._token_path):
+            with open(self._token_path) as f:
+                token = f.read().strip()
+        if token:
+            md.append(('authorization', f'Bearer {token}'))
+        if request_context:
+            md.append(('x-raptor-request-context', json.dumps(request_context, default=str)))
+        return md
+
+    def _request(self, selector: str, keys: Dict[str, str]) -> api_pb2.GetRequest:
+        return api_pb2.GetRequest(uuid=str(uuid.uuid4()), selector=selector, keys=keys)
+
+    def _cached(self, selector: str, keys: Dict[str, str], request_context: Optional[dict]):
+        # values that depend on the request context aren't cached
+        if self.cache is None or request_context:
+            return missing
+        return self.cache.get(selector, keys)
+
+    def _metadata_result(self, selector: str, keys: Dict[str, str], resp: api_pb2.GetResponse,
+                         request_context: Optional[dict]) -> FeatureValue:
+        self._result(selector, keys, resp, request_context)
+        return feature_value_to_py(resp.value)
+
+    def _result(self, selector: str, keys: Dict[str, str], resp: api_pb2.GetResponse,
+                request_context: Optional[dict]) -> primitive:
+        value = value_to_py(resp.value.value)
+        if self.cache is not None and not request_context:
+            ts = resp.value.timestamp.ToDatetime(tzinfo=timezone.utc) if resp.value.HasField('timestamp') else None
+            freshness = resp.feature_descriptor.freshness.ToTimedelta().total_seconds()
+            self.cache.set(selector, keys, value, self.cache.ttl(ts, freshness, resp.value.fresh))
+        return value
+
+
+class Client(_Base):
+    """
+    Client is a client of the Raptor Core, for reading feature values in production inference services.
+
+    The client is safe for concurrent use, and should be reused for the lifetime of the service.
+
+    :param address: the address of the Core's gRPC service.
+    :param credentials: the channel credentials. Defaults to an insecure channel.
+    :param token: the bearer token to authenticate with. Defaults to the token of the pod's service account.
+    :param token_path: the file of the bearer token, when `token` is not set.
+    :param namespace: the namespace of the selectors and feature sets that don't specify one.
+    :param timeout: the timeout (in seconds) of a request.
+    :param retries: the number of retries of failed (unavailable) requests.
+    :param cache_ttl: the maximal time (in seconds) a value is cached in-process. Values are cached only while they're
+                      fresh. Defaults to 0 (no caching).
+    :param cache_size: the maximal number of cached values.
+    :param options: additional gRPC channel options.
+    """
+
+    def __init__(self, address: str = DEFAULT_ADDRESS, **kwargs):
+        super().__init__(address, **kwargs)
+        if self._credentials is None:
+            self._channel = grpc.insecure_channel(address, options=self._options)
+        else:
+            self._channel = grpc.secure_channel(address, self._credentials, options=self._options)
+        self._stub = api_pb2_grpc.EngineServiceStub(self._channel)
+
+    def get(self, selector: str, keys: Dict[str, str], request_context: Optional[dict] = None) -> primitive:
+        """Returns the value of the feature (i.e. `namespace.name+aggr`) of the entity."""
+        selector = normalize_selector(selector, self.namespace)
+        cached = self._cached(selector, keys, request_context)
+        if cached is not missing:
+            return cached
+        resp = self._stub.Get(self._request(selector, keys), timeout=self.timeout,
+                              metadata=self._metadata(request_context))
+        return self._result(selector, keys, resp, request_context)
+
+    def get_with_metadata(self, selector: str, keys: Dict[str, str],
+                          request_context: Optional[dict] = None) -> FeatureValue:
+        """
+        Returns the value of the feature of the entity with its metadata: its timestamp, whether it's stale, and the
+        path that produced it. The value is always read from the Core, so its metadata is accurate.
+        """
+        selector = normalize_selector(selector, self.namespace)
+        resp = self._stub.Get(self._request(selector, keys), timeout=self.timeout,
+                              metadata=self._metadata(request_context))
+        return self._metadata_result(selector, keys, resp, request_context)
+
+    def get_vector(self, featureset: Union[str, FeatureSet], entity_id: EntityID,
+                   request_context: Optional[dict] = None) -> Dict[str, primitive]:
+        """
+        Returns the values of the features of the feature set, for the entity, ordered as the features of the feature
+        set. The features are read concurrently.
+
+        :param featureset: the name (or FQN) of a registered feature set, or a FeatureSet.
+        :param entity_id: the ID of the entity, or a dict of its keys if the feature set has multiple keys.
+        :param request_context: the context of the request, passed to features that use it.
+        """
+        fs = self._featureset(featureset)
+        keys = fs.entity_keys(entity_id)
+        md = self._metadata(request_context)
+
+        ret: Dict[str, primitive] = {}
+        futures = {}
+        for selector in fs.features:
+            cached = self._cached(selector, keys, request_context)
+            if cached is not missing:
+                ret[selector] = cached
+                continue
+            ret[selector] = None  # keep the order of the feature set
+            futures[selector] = self._stub.Get.future(self._request(selector, keys), timeout=self.timeout,
+                                                      metadata=md)
+        for selector, future in futures.items():
+            ret[selector] = self._result(selector, keys, future.result(), request_context)
+        return ret
+
+    def close(self):
+        self._channel.close()
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, *args):
+        self.close()
+
+
+class AsyncClient(_Base):
+    """
+    AsyncClient is the asyncio counterpart of Client. It accepts the same parameters.
+    """
+
+    def __init__(self, address: str = DEFAULT_ADDRESS, **kwargs):
+        super().__init__(address, **kwargs)
+        if self._credentials is None:
+            self._channel = aio.insecure_channel(address, options=self._options)
+        else:
+            self._channel = aio.secure_channel(address, self._credentials, options=self._options)
+        self._stub = api_pb2_grpc.EngineServiceStub(self._channel)
+
+    async def get(self, selector: str, keys: Dict[str, str], request_context: Optional[dict] = None) -> primitive:
+        """Returns the value of the feature (i.e. `namespace.name+aggr`) of the entity."""
+        selector = normalize_selector(selector, self.namespace)
+        return await self._get(selector, keys, request_context, self._metadata(request_context))
+
+    async def _get(self, selector: str, keys: Dict[str, str], request_context: Optional[dict], md: List):
+        cached = self._cached(selector, keys, request_context)
+        if cached is not missing:
+            return cached
+        resp = await self._stub.Get(self._request(selector, keys), timeout=self.timeout, metadata=md)
+        return self._result(selector, keys, resp, request_context)
+
+    async def get_with_metadata(self, selector: str, keys: Dict[str, str],
+                                request_context: Optional[dict] = None) -> FeatureValue:
+        """
+        Returns the value of the feature of the entity with its metadata: its timestamp, whether it's stale, and the
+        path that produced it. The value is always read from the Core, so its metadata is accurate.
+        """
+        selector = normalize_selector(selector, self.namespace)
+        resp = await self._stub.Get(self._request(selector, keys), timeout=self.timeout,
+                                    metadata=self._metadata(request_context))
+        return self._metadata_result(selector, keys, resp, request_context)
+
+    async def get_vector(self, featureset: Union[str, FeatureSet], entity_id: EntityID,
+                         request_context: Optional[dict] = None) -> Dict[str, primitive]:
+        """
+        Returns the values of the features of the feature set, for the entity, ordered as the features of the feature
+        set. The features are read concurrently.
+        """
+        fs = self._featureset(featureset)
+        keys = fs.entity_keys(entity_id)
+        md = self._metadata(request_context)
+        values = await asyncio.gather(*[self._get(s, keys, request_context, md) for s in fs.features])
+        return dict(zip(fs.features, values))
+
+    async def close(self):
+        await self._channel.close()
+
+    async def __aenter__(self):
+        return self
+
+    async def __aexit__(self, *args):
+        await self.close()
@@ -0,0 +1,83 @@
+# -*- coding: utf-8 -*-
+#  Copyright (c) 2022 RaptorML authors.
+#
+#  Licensed under the Apache License, Version 2.0 (the "License");
+#  you may not use this file except in compliance with the License.
+#  You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+#  Unless required by applicable law or agreed to in writing, software
+#  distributed under the License is distributed on an "AS IS" BASIS,
+#  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#  See the License for the specific language governing permissions and
+#  limitations under the License.
+
+import re
+from typing import Dict, IO, List, Union
+
+_suffix = re.compile(r'[+@\[]')
+
+
+def fqn(namespace: str, name: str) -> str:
+    return f'{namespace}.{name}'.replace('-', '_')
+
+
+def normalize_selector(selector: str, namespace: str) -> str:
+    """Returns the selector with the namespace, i.e. `default.clicks+count` of `clicks+count`."""
+    m = _suffix.search(selector)
+    base, suffix = (selector[:m.start()], selector[m.start():]) if m else (selector, '')
+    if '.' not in base:
+        base = fqn(namespace, base)
+    return base.replace('-', '_') + suffix
+
+
+class FeatureSet:
+    """
+    FeatureSet is the ordered list of features of a Model, that are read together as the model's input vector.
+    """
+
+    def __init__(self, name: str, features: List[str], keys: List[str], namespace: str = 'default'):
+        """
+        :param name: the name of the feature set (i.e. the name of the Model).
+        :param features: the selectors of the features (i.e. `clicks+count`). Selectors without a namespace are in
+                         the namespace of the feature set.
+        :param keys: the keys of the entities of the feature set.
+        :param namespace: the namespace of the feature set.
+        """
+        self.name = name
+        self.namespace = namespace
+        self.fqn = fqn(namespace, name)
+        self.features = [normalize_selector(f, namespace) for f in features]
+        self.keys = list(keys)
+
+    @staticmethod
+    def from_manifest(manifest: dict) -> 'FeatureSet':
+        """Creates the FeatureSet of a Model manifest."""
+        if manifest.get('kind') != 'Model':
+            raise ValueError(f'expected a Model manifest, got {manifest.get("kind")}')
+        metadata = manifest.get('metadata', {})
+        spec = manifest.get('spec', {})
+        return FeatureSet(metadata['name'], spec.get('features', []), spec.get('keys', []),
+                          metadata.get('namespace') or 'default')
+
+    def entity_keys(self, entity_id: Union[str, Dict[str, str]]) -> Dict[str, str]:
+        """
+        Returns the keys of the entity. An entity ID can be given as a string only if the feature set has a single key.
+        """
+        if isinstance(entity_id, dict):
+            return {k: str(v) for k, v in entity_id.items()}
+        if len(self.keys) != 1:
+            raise ValueError(f'{self.fqn} has {len(self.keys)} keys ({", ".join(self.keys)}), so the keys of the '
+                             f'entity should be given as a dict')
+        return {self.keys[0]: str(entity_id)}
+
+    def __repr__(self):
+        return f'FeatureSet({self.fqn}, features={self.features}, keys={self.keys})'
+
+
+def load_featuresets(stream: Union[str, IO]) -> List[FeatureSet]:
+    """Loads the FeatureSets of the Model manifests among the (YAML or JSON) manifests."""
+    import yaml
+
+    return [FeatureSet.from_manifest(m) for m in yaml.safe_load_all(stream) if m and m.get('kind') == 'Model']
@@ -0,0 +1,78 @@
+# -*- coding: utf-8 -*-
+#  Copyright (c) 2022 RaptorML authors.
+#
+#  Licensed under the Apache License, Version 2.0 (the "License");
+#  you may not use this file except in compliance with the License.
+#  You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+#  Unless required by applicable law or agreed to in writing, software
+#  distributed under the License is distributed on an "AS IS" BASIS,
+#  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#  See the License for the specific language governing permissions and
+#  limitations under the License.
+
+import os
+import sys
+from dataclasses import dataclass
+from datetime import datetime, timezone
+from typing import List, Optional, Union
+
+# the generated protos import each other by their package (i.e. `core.v1alpha1`)
+sys.path.append(os.path.join(os.path.dirname(__file__), 'proto'))
+
+from core.v1alpha1 import types_pb2  # noqa: E402
+
+primitive = Union[str, int, float, bool, datetime, List[str], List[int], List[float], List[bool], List[datetime], None]
+
+
+def scalar_to_py(scalar: types_pb2.Scalar) -> primitive:
+    kind = scalar.WhichOneof('value')
+    if kind is None:
+        return None
+    if kind == 'timestamp_value':
+        return scalar.timestamp_value.ToDatetime(tzinfo=timezone.utc)
+    return getattr(scalar, kind)
+
+
+def value_to_py(value: types_pb2.Value) -> primitive:
+    """Converts a value of the Core to a Python value. Missing values are converted to None."""
+    kind = value.WhichOneof('value')
+    if kind == 'scalar_value':
+        return scalar_to_py(value.scalar_value)
+    if kind == 'list_value':
+        return [scalar_to_py(v) for v in value.list_value.values]
+    return None
+
+
+@dataclass
+class FeatureValue:
+    """
+    FeatureValue is a value of a feature, with its metadata.
+
+    :param value: the value, or None if the feature has no value for the entity.
+    :param timestamp: the event time of the value.
+    :param fresh: whether the value is within the freshness of the feature.
+    :param stale: whether the value is older than the freshness of the feature.
+    :param source: the path that produced the value: `state`, `cache`, `fallback` or `computed`.
+    """
+    value: primitive
+    timestamp: Optional[datetime]
+    fresh: bool
+    stale: bool
+    source: Optional[str]
+
+
+def feature_value_to_py(value: types_pb2.FeatureValue) -> FeatureValue:
+    """Converts a feature value of the Core, with its metadata, to a FeatureValue."""
+    source = None
+    if value.source != types_pb2.VALUE_SOURCE_UNSPECIFIED:
+        source = types_pb2.ValueSource.Name(value.source)[len('VALUE_SOURCE_'):].lower()
+    return FeatureValue(
+        value=value_to_py(value.value),
+        timestamp=value.timestamp.ToDatetime(tzinfo=timezone.utc) if value.HasField('timestamp') else None,
+        fresh=value.fresh,
+        stale=value.stale,
+        source=source,
+    )
@@ -20,6 +20,7 @@ import os
 import re
 import sys
 import warnings
+from collections import namedtuple
 from typing import Union
 
 import grpc
@@ -33,6 +34,77 @@ server: Union[grpc.aio.Server, None] = None
 uds_path: Union[str, None] = None
 
 
+SERVICE_ACCOUNT_TOKEN_PATH = '/var/run/secrets/kubernetes.io/serviceaccount/token'
+
+
+class _ClientCallDetails(
+    namedtuple('_ClientCallDetails', ('method', 'timeout', 'metadata', 'credentials', 'wait_for_ready', 'compression')),
+    grpc.ClientCallDetails):
+    pass
+
+
+class ServiceAccountAuth(grpc.UnaryUnaryClientInterceptor):
+    """
+    Authenticates the requests to the core with the token of the pod's ServiceAccount, for when the core's
+    authentication is enabled. The token is read on every request, since it's rotated by the kubelet.
+    """
+
+    def intercept_unary_unary(self, continuation, client_call_details, request):
+        try:
+            with open(SERVICE_ACCOUNT_TOKEN_PATH) as f:
+                token = f.read().strip()
+        except FileNotFoundError:
+            return continuation(client_call_details, request)
+
+        metadata = list(client_call_details.metadata or [])
+        metadata.append(('authorization', f'Bearer {token}'))
+        details = _ClientCallDetails(client_call_details.method, client_call_details.timeout, metadata,
+                                     client_call_details.credentials, client_call_details.wait_for_ready,
+                                     client_call_details.compression)
+        return continuation(details, request)
+
+
+def core_channel(core_grpc_url: str, tls_dir: Union[str, None]) -> grpc.Channel:
+    """
+    Connect to the core, with the mTLS client certificate of `tls_dir` (if set). The certificate is loaded once, so
+    the runtime should be restarted when it's rotated.
+    """
+    if not tls_dir or core_grpc_url.startswith(('unix:', '/')):
+        return grpc.insecure_channel(core_grpc_url)
+
+    def read(name: str) -> bytes:
+        with open(os.path.join(tls_dir, name), 'rb') as f:
+            return f.read()
+
+    creds = grpc.ssl_channel_credentials(root_certificates=read('ca.crt'), private_key=read('tls.key'),
+                                         certificate_chain=read('tls.crt'))
+    return grpc.secure_channel(core_grpc_url, creds)
+
+
+def setup_tracing(runtime_name: str):
+    """
+    Export the traces to the OTLP collector of `OTEL_EXPORTER_OTLP_ENDPOINT` (if set), continuing the traces of the
+    core's requests.
+    """
+    if os.environ.get('OTEL_EXPORTER_OTLP_ENDPOINT') is None:
+        return
+
+    from opentelemetry import trace
+    from opentelemetry.exporter.otlp.proto.grpc.trace_exporter import OTLPSpanExporter
+    from opentelemetry.instrumentation.grpc import GrpcAioInstrumentorServer, GrpcInstrumentorClient
+    from opentelemetry.sdk.resources import Resource
+    from opentelemetry.sdk.trace import TracerProvider
+    from opentelemetry.sdk.trace.export import BatchSpanProcessor
+
+    provider = TracerProvider(resource=Resource.create({'service.name': f'raptor-runtime-{runtime_name}'}))
+    provider.add_span_processor(BatchSpanProcessor(OTLPSpanExporter()))
+    trace.set_tracer_provider(provider)
+
+    # the instrumentation must precede the creation of the server and the channels
+    GrpcAioInstrumentorServer().instrument()
+    GrpcInstrumentorClient().instrument()
+
+
 async def main():
     runtime_name = 'default' if os.environ.get('RUNTIME_NAME') is None else os.environ.get('RUNTIME_NAME')
 
@@ -40,11 +112,18 @@ async def main():
     if not re.match(legal_name, runtime_name):
         raise ValueError('RUNTIME_NAME is illegal')
 
+    setup_tracing(runtime_name)
+
     core_grpc_url = '/tmp/raptor/core.sock' if os.environ.get('CORE_GRPC_URL') is None else os.environ.get(
         'CORE_GRPC_URL')
-    engine_channel = grpc.insecure_channel(core_grpc_url)
-
-    svc = RuntimeServicer(engine_channel=engine_channel)
+    engine_channel = core_channel(core_grpc_url, os.environ.get('CORE_TLS_DIR'))
+    # requests over the local unix socket are trusted by the core
+    if not core_grpc_url.startswith(('unix:', '/')):
+        engine_channel = grpc.intercept_channel(engine_channel, ServiceAccountAuth())
+
+    svc = RuntimeServicer(engine_channel=engine_channel,
+                          pool_size=int(os.environ.get('RUNTIME_POOL_SIZE', '1')),
+                          max_in_flight=int(os.environ.get('RUNTIME_MAX_IN_FLIGHT', '8')))
     server = grpc.aio.server()
     svc.attach_to_server(server)
     health_pb2_grpc.add_HealthServicer_to_server(health.HealthServicer(), server)
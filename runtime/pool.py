@@ -0,0 +1,103 @@
+# -*- coding: utf-8 -*-
+#  Copyright (c) 2022 RaptorML authors.
+#
+#  Licensed under the Apache License, Version 2.0 (the "License");
+#  you may not use this file except in compliance with the License.
+#  You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+#  Unless required by applicable law or agreed to in writing, software
+#  distributed under the License is distributed on an "AS IS" BASIS,
+#  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#  See the License for the specific language governing permissions and
+#  limitations under the License.
+
+import asyncio
+import contextvars
+import functools
+import hashlib
+from concurrent.futures import Executor
+from typing import Dict, List, Optional
+
+from program import Program, Context, primitive
+
+
+def checksum(code: str) -> bytes:
+    m = hashlib.sha256()
+    m.update(code.encode('utf-8'))
+    return m.digest()
+
+
+async def run_blocking(executor: Executor, fn, *args):
+    """
+    Run a blocking function on the executor. The context is copied, so the trace of the request is continued by the
+    function's requests to the core.
+    """
+    call = functools.partial(contextvars.copy_context().run, fn, *args)
+    return await asyncio.get_running_loop().run_in_executor(executor, call)
+
+
+class ProgramPool:
+    """
+    A pool of warm instances of a feature's program.
+
+    `size` instances are compiled when the program is loaded, and are reused across the executions. When all of them
+    are busy, more instances are compiled (and kept warm) up to `max_in_flight`, and further executions wait for an
+    instance to be released. The programs run on the executor's threads, so they (and their requests to the core)
+    don't block the server.
+
+    A pool is immutable: a changed program is compiled into a new pool that replaces it, while the in-flight executions
+    of the replaced pool are completed gracefully.
+    """
+
+    def __init__(self, code: str, executor: Executor, size: int = 1, max_in_flight: int = 1, isolated: bool = False):
+        self.code = code
+        self.checksum = checksum(code)
+        self.executor = executor
+        self.isolated = isolated
+        self.max_in_flight = max(size, max_in_flight, 1)
+
+        self.idle: List[Program] = [self._compile() for _ in range(max(size, 1))]
+        self.program = self.idle[0]
+        self.created = len(self.idle)
+        self.available = asyncio.Condition()
+
+    def _compile(self) -> Program:
+        return Program(self.code, isolated=self.isolated)
+
+    async def acquire(self) -> Program:
+        async with self.available:
+            await self.available.wait_for(lambda: self.idle or self.created < self.max_in_flight)
+            if self.idle:
+                return self.idle.pop()
+            self.created += 1
+        try:
+            return await run_blocking(self.executor, self._compile)
+        except Exception:
+            async with self.available:
+                self.created -= 1
+                self.available.notify()
+            raise
+
+    async def release(self, instance: Program):
+        async with self.available:
+            self.idle.append(instance)
+            self.available.notify()
+
+    async def call(self, data: Dict[str, primitive], context: Context, timeout: Optional[float] = None):
+        """
+        Execute the program on a warm instance. An `asyncio.TimeoutError` is raised if the execution exceeds the
+        timeout (in seconds). Since threads can't be interrupted, the instance is released only once its execution is
+        completed.
+        """
+        instance = await self.acquire()
+        execution = asyncio.ensure_future(run_blocking(self.executor, instance.call, data, context))
+
+        def done(f: asyncio.Future):
+            if not f.cancelled():
+                f.exception()  # the exception of a timed-out execution is retrieved, so it's not reported
+            asyncio.ensure_future(self.release(instance))
+
+        execution.add_done_callback(done)
+        return await asyncio.wait_for(asyncio.shield(execution), timeout)
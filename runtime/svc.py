@@ -12,11 +12,16 @@
 #  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 #  See the License for the specific language governing permissions and
 #  limitations under the License.
-import hashlib
+import asyncio
+import gc
+import importlib.metadata
 import logging
+import os
+import re
 import subprocess
 import sys
 import warnings
+from concurrent.futures import Executor, ThreadPoolExecutor
 from datetime import datetime
 from typing import Dict, List, Tuple, Union
 from uuid import uuid4
@@ -24,8 +29,10 @@ from uuid import uuid4
 import grpc
 from google.protobuf.internal.containers import MessageMap
 from grpc import ServicerContext
+from opentelemetry import trace
 
-from program import Program, Context, SideEffect, primitive, normalize_selector, selector_regex
+from pool import ProgramPool, checksum, run_blocking
+from program import Context, SideEffect, primitive, normalize_selector, selector_regex
 
 sys.path.append('./proto')
 
@@ -36,12 +43,65 @@ from proto.py_runtime.v1alpha1 import api_pb2
 from proto.py_runtime.v1alpha1 import api_pb2_grpc
 
 
+tracer = trace.get_tracer(__name__)
+
+# The runtimes of RuntimeEnvironments are isolated: their requirements are baked into their image by the operator, so
+# their programs can import dataset and modeling packages, and packages aren't installed on load.
+isolated = os.environ.get('RUNTIME_ISOLATED', '').lower() == 'true'
+
+
+def parse_quantity(quantity: str) -> int:
+    """Returns the bytes of a memory quantity (i.e. `512Mi`), or 0 if it's empty."""
+    quantity = quantity.strip()
+    for suffix, multiplier in (('Ki', 1 << 10), ('Mi', 1 << 20), ('Gi', 1 << 30)):
+        if quantity.endswith(suffix):
+            return int(float(quantity[:-len(suffix)]) * multiplier)
+    return int(quantity) if quantity else 0
+
+
+# The memory ceiling of the runtime: programs aren't executed while the runtime's memory exceeds it, so a leaking (or
+# greedy) program fails its invocations rather than having the runtime OOM-killed with the programs of all the
+# features it serves. It should be lower than the memory limit of the runtime's container.
+memory_limit = parse_quantity(os.environ.get('RUNTIME_MEMORY_LIMIT', ''))
+
+
+def memory_usage() -> int:
+    """Returns the resident memory of the runtime in bytes."""
+    with open('/proc/self/statm') as f:
+        return int(f.read().split()[1]) * os.sysconf('SC_PAGE_SIZE')
+
+
+def memory_exceeded() -> bool:
+    if memory_limit <= 0:
+        return False
+    if memory_usage() <= memory_limit:
+        return False
+    gc.collect()
+    return memory_usage() > memory_limit
+
+
+def requirement_name(requirement: str) -> str:
+    """Returns the distribution name of a pip requirement (i.e. `pandas` of `pandas[parquet]>=2.2`)"""
+    return re.split(r'[\s\[<>=!~;@]', requirement.strip(), 1)[0]
+
+
 class RuntimeServicer(api_pb2_grpc.RuntimeServiceServicer):
-    programs: Dict[str, Program] = {}
+    pools: Dict[str, ProgramPool]
     engine: core_grpc.EngineServiceStub
-
-    def __init__(self, engine_channel: grpc.aio.Channel):
+    executor: Executor
+
+    def __init__(self, engine_channel: grpc.aio.Channel, pool_size: int = 1, max_in_flight: int = 1,
+                 executor: Executor = None):
+        """
+        :param pool_size: the number of warm instances that are compiled for each program.
+        :param max_in_flight: the maximal number of concurrent executions of each program.
+        :param executor: the executor the programs run on. Defaults to a thread pool.
+        """
         self.engine = core_grpc.EngineServiceStub(engine_channel)
+        self.pools = {}
+        self.pool_size = pool_size
+        self.max_in_flight = max_in_flight
+        self.executor = executor if executor is not None else ThreadPoolExecutor(thread_name_prefix='program')
 
     def attach_to_server(self, server):
         api_pb2_grpc.add_RuntimeServiceServicer_to_server(self, server)
@@ -52,26 +112,32 @@ class RuntimeServicer(api_pb2_grpc.RuntimeServiceServicer):
 
     async def LoadProgram(self, request: api_pb2.LoadProgramRequest, context: ServicerContext):
         try:
-            if request.fqn in self.programs:
-                program = self.programs[request.fqn]
-                m = hashlib.sha256()
-                m.update(request.program.encode('utf-8'))
-                if m.hexdigest() == program.checksum:
-                    return api_pb2.LoadProgramResponse(
-                        uuid=request.uuid,
-                        primitive=RuntimeServicer.py_to_proto_primitive(program.primitive),
-                        side_effects=self.py_to_proto_side_effects(program.side_effects)
-                    )
+            pool = self.pools.get(request.fqn)
+            if pool is not None and pool.checksum == checksum(request.program):
+                return api_pb2.LoadProgramResponse(
+                    uuid=request.uuid,
+                    primitive=RuntimeServicer.py_to_proto_primitive(pool.program.primitive),
+                    side_effects=self.py_to_proto_side_effects(pool.program.side_effects)
+                )
 
             for pkg in request.packages:
-                subprocess.run([sys.executable, '-m', 'pip', 'install', pkg], check=True)
-
-            program = Program(request.program)
-            self.programs[request.fqn] = program
+                if not isolated:
+                    subprocess.run([sys.executable, '-m', 'pip', 'install', pkg], check=True)
+                    continue
+                try:
+                    importlib.metadata.version(requirement_name(pkg))
+                except importlib.metadata.PackageNotFoundError:
+                    raise Exception(f'package `{pkg}` is not one of the requirements of the runtime environment')
+
+            # the in-flight executions of a replaced program are completed by its previous pool
+            pool = await run_blocking(self.executor, lambda: ProgramPool(
+                request.program, self.executor, size=self.pool_size, max_in_flight=self.max_in_flight,
+                isolated=isolated))
+            self.pools[request.fqn] = pool
             return api_pb2.LoadProgramResponse(
                 uuid=request.uuid,
-                primitive=self.py_to_proto_primitive(program.primitive),
-                side_effects=self.py_to_proto_side_effects(program.side_effects)
+                primitive=self.py_to_proto_primitive(pool.program.primitive),
+                side_effects=self.py_to_proto_side_effects(pool.program.side_effects)
             )
         except Exception as e:
             logging.error(f'{request.fqn}: Failed to load program', e)
@@ -79,11 +145,13 @@ class RuntimeServicer(api_pb2_grpc.RuntimeServiceServicer):
             return
 
     async def ExecuteProgram(self, request: api_pb2.ExecuteProgramRequest, context: ServicerContext):
-        if request.fqn not in self.programs:
+        pool = self.pools.get(request.fqn)
+        if pool is None:
             context.abort(grpc.StatusCode.NOT_FOUND, 'Program not found')
             return
-
-        program: Program = self.programs[request.fqn]
+        if memory_exceeded():
+            logging.error(f'{request.fqn}: The runtime exceeded its memory limit')
+            await context.abort(grpc.StatusCode.RESOURCE_EXHAUSTED, 'The runtime exceeded its memory limit')
 
         matches = selector_regex.match(request.fqn)
         namespace = matches.group('namespace')
@@ -128,7 +196,9 @@ class RuntimeServicer(api_pb2_grpc.RuntimeServiceServicer):
         )
 
         try:
-            resp = program.call(data, program_ctx)
+            with tracer.start_as_current_span('program.call', attributes={'raptor.feature': request.fqn}):
+                # the deadline of the request is the execution timeout of the feature
+                resp = await pool.call(data, program_ctx, context.time_remaining())
             if isinstance(resp, tuple) and len(resp) == 3:
                 if not isinstance(resp[2], datetime):
                     raise Exception('Timestamp must be a datetime object')
@@ -149,11 +219,14 @@ class RuntimeServicer(api_pb2_grpc.RuntimeServiceServicer):
                     value=ret.result,
                 )
                 ur.timestamp.FromDatetime(ts)
-                uresp = self.engine.Update(ur)
+                uresp = await run_blocking(self.executor, self.engine.Update, ur)
                 if uresp.uuid != ur.uuid:
                     raise Exception('UUID mismatch')
 
             return ret
+        except asyncio.TimeoutError:
+            logging.error(f'{request.fqn}: The program exceeded its execution timeout')
+            await context.abort(grpc.StatusCode.DEADLINE_EXCEEDED, 'The program exceeded its execution timeout')
         except Exception as e:
             logging.error(f'{request.fqn}: Failed to execute program', e)
             context.abort(grpc.StatusCode.INVALID_ARGUMENT, str(e))